@@ -0,0 +1,73 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// stripComments converts a relaxed JSON config into strict JSON:
+// it removes // line comments, /* */ block comments and trailing commas
+// before closing brackets. Strings are preserved as is.
+// Removed comments are replaced with spaces (newlines are preserved),
+// so that offsets in JSON parsing errors still point to the right line.
+func stripComments(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	// Position in out of the last comma that is not yet followed by a value.
+	lastComma := -1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(data) {
+				return nil, fmt.Errorf("unterminated string at offset %v", start)
+			}
+			out = append(out, data[start:i+1]...)
+			lastComma = -1
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for ; i < len(data) && data[i] != '\n'; i++ {
+				out = append(out, ' ')
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment at offset %v", i)
+			}
+			end += i + 4
+			for ; i < end; i++ {
+				if data[i] == '\n' {
+					out = append(out, '\n')
+				} else {
+					out = append(out, ' ')
+				}
+			}
+			i--
+		case c == ',':
+			lastComma = len(out)
+			out = append(out, c)
+		case c == '}' || c == ']':
+			if lastComma != -1 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+			out = append(out, c)
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			out = append(out, c)
+		default:
+			lastComma = -1
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
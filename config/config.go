@@ -4,15 +4,20 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/mail"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/google/syzkaller/fileutil"
+	"github.com/google/syzkaller/prog"
 	"github.com/google/syzkaller/sys"
 	"github.com/google/syzkaller/vm"
 )
@@ -23,19 +28,38 @@ type Config struct {
 	Vmlinux string
 	Kernel  string // e.g. arch/x86/boot/bzImage
 	Cmdline string // kernel command line
-	Image   string // linux image for VMs
+	Image   string // linux image for VMs (container image name for docker)
+	Initrd  string // linux initial ramdisk (e.g. cpio initramfs), allows to boot qemu without Image
 	Cpu     int    // number of VM CPUs
 	Mem     int    // amount of VM memory in MBs
 	Sshkey  string // root ssh key for the image
 	Port    int    // VM ssh port to use
-	Bin     string // qemu/lkvm binary name
+	Bin     string // qemu/lkvm/docker binary name
+	Virtfs  bool   // export syzkaller bin dir into qemu VMs via 9p instead of copying binaries over scp
 	Debug   bool   // dump all VM output to console
 	Output  string // one of stdout/dmesg/file (useful only for local VM)
 
+	Additional_Disks []string // template images of scratch disks attached to qemu VMs (copied fresh on every boot)
+
+	Image_Overlay   bool // boot qemu VMs from per-instance qcow2 overlays on top of read-only image (instead of -snapshot)
+	Save_Crash_Disk bool // move overlay of a crashed instance to the crash dir (requires image_overlay)
+
+	Net_Model string // qemu NIC model (e.g. e1000, virtio-net-pci, rtl8139), default: e1000
+	Net_Fwd   []int  // additional guest TCP ports forwarded to free host ports (qemu)
+	Net_Tap   string // host tap device attached to qemu VMs as a second NIC, %v is replaced with VM index
+	// More NICs of qemu VMs for multi-interface topologies (routing, bridging, bonding).
+	// Ifname of tap NICs must contain %v (replaced with VM index) when count > 1,
+	// socket NICs with the same mcast address form a shared L2 segment (default: 230.0.0.1:1234+N).
+	Nics []vm.NIC
+
+	Qemu_Args string // additional qemu command line arguments (e.g. "-machine q35 -cpu host,+smap")
+
 	Syzkaller string // path to syzkaller checkout (syz-manager will look for binaries in bin subdir)
-	Type      string // VM type (qemu, kvm, local)
+	Type      string // VM type (qemu, kvm, local, adb, odroid, docker, emulator, uml)
 	Count     int    // number of VMs
+	Min_Count int    // minimal number of VMs when throttling by host load (0: no throttling)
 	Procs     int    // number of parallel processes inside of every VM
+	Rpc_Key   string // key fuzzers must present to the manager RPC (default: random, generated on every start)
 
 	Sandbox string // type of sandbox to use during fuzzing:
 	// "none": don't do anything special (has false positives, e.g. due to killing init)
@@ -43,14 +67,173 @@ type Config struct {
 	// "namespace": create a new namespace for fuzzer using CLONE_NEWNS/CLONE_NEWNET/CLONE_NEWPID/etc,
 	//	requires building kernel with CONFIG_NAMESPACES, CONFIG_UTS_NS, CONFIG_USER_NS, CONFIG_PID_NS and CONFIG_NET_NS.
 
+	// Capability matrix: before each program the fuzzer picks a random row and drops
+	// the listed capabilities (e.g. [[], ["CAP_SYS_ADMIN"], ["CAP_NET_ADMIN", "CAP_NET_RAW"]]),
+	// so that EPERM paths of permission checks are exercised too. An empty row drops nothing.
+	Drop_Caps [][]string
+
+	// Don't block syscalls that can destroy the test environment (reboot, kexec, module loading,
+	// setting time, mounts outside of the namespace sandbox, etc), useful only on throwaway targets.
+	// By default the executor fails them with EPERM regardless of descriptions.
+	Dangerous_Calls bool
+
 	Cover bool // use kcov coverage (default: true)
 	Leak  bool // do memory leak checking
 
-	ConsoleDev string // console device for adb vm
+	Leak_Period int // period of kmemleak scans in seconds (default: 60)
+
+	// Lockdep reports only the first locking bug per boot and turns itself off,
+	// restart VMs once it happens so that locking bugs are found systematically.
+	Lockdep_Reboot bool
+
+	Pressure     int // percent of VMs that run in memory pressure mode (tiny RAM and a memory hog)
+	Pressure_Mem int // amount of memory in MBs for memory pressure VMs (default: 256)
+
+	// Programs are killed after that many seconds (default: 5), programs that can't be killed
+	// are saved to workdir/hangs (see manager/hang.go) and the VM keeps fuzzing.
+	Program_Timeout int
+
+	Disk_Min int // free guest disk space in MBs below which the fuzzer cleans up temp files and logs (default: 64)
+
+	Pm        string // power management cycling between program batches: none/freezer/suspend (default: none)
+	Pm_Period int    // period of power management cycles in seconds (default: 600)
+
+	Strategy string // name of the program mutation strategy (default: "default")
+
+	// Relative weights of mutations of the default strategy: "insert" (a new call, default: 20),
+	// "mutate_arg" (change args of a call, default: 10), "remove" (a call, default: 1),
+	// "splice" (insert calls of another corpus program, default: 0); and "collide":
+	// percent of programs re-executed in collide mode to provoke races (default: 100).
+	Mutation_Weights map[string]int
+
+	// Number of additional executions of programs with nondeterministic coverage during triage.
+	// If set, coverage of such programs is the union of all runs rather than the intersection
+	// (0: disabled, only coverage that is reproduced on every run is accounted).
+	Flaky_Repeat int
+
+	// Errno feedback: a call that succeeds for the first time is new signal even without
+	// new coverage, helps to drive through ioctl state machines where coverage plateaus.
+	Errno_Feedback bool
+
+	// Collect operands of kernel comparisons (KCOV_TRACE_CMP) for new inputs and mutate
+	// arguments equal to one operand into the other one, helps to get past magic numbers.
+	// Requires a kernel built with CONFIG_KCOV_ENABLE_COMPARISONS.
+	Comparisons bool
+
+	// Re-execute new inputs injecting faults (failslab, fail_page_alloc, etc) into the 0th, 1st, ...
+	// fault site of the new call to exercise error-handling paths, at most Fault_Max sites per call
+	// (default: 100). Requires a kernel built with CONFIG_FAULT_INJECTION and CONFIG_FAILSLAB etc.
+	Fault_Injection bool
+	Fault_Max       int
+
+	Reproduce   bool // automatically reproduce crashes (default: true)
+	Repro_Count int  // number of additional VMs used for crash reproduction (default: min(count, 4))
+	// Max number of reproduction attempts per crash title (default: 4). A failed attempt is retried
+	// when the crash happens again, with a fresh log and twice as many suspected programs.
+	Repro_Attempts int
+
+	// What to do when a single crash dominates recent crashes (see manager/storm.go):
+	// "none": only report it (default), "disable": temporarily disable syscalls of its reproducer,
+	// "deprioritize": temporarily make syscalls of its reproducer less likely to be chosen.
+	Crash_Storm string
+
+	Ftrace string // ftrace function filter (e.g. "tcp_*" or ":mod:ext4") to trace programs with on request from web UI
+
+	Max_Run_Time int    // stop fuzzing and exit after that many seconds (0: unlimited)
+	Max_Execs    uint64 // stop fuzzing and exit after executing that many programs (0: unlimited)
+
+	Experiment *Experiment // A/B test of fuzzing engine flags
+
+	Kernels []KernelVariant // several kernels fuzzed by the same manager (e.g. with and without a patch series)
+
+	ConsoleDev string      // console device for adb/odroid vm
+	Devices    []vm.Device // pool of adb devices to use instead of a single ConsoleDev
+	Reflash    string      // host command to reflash a dead adb device, %v is replaced with device serial
+
+	Avd      string // name of the Android Virtual Device for emulator vm
+	Snapshot string // AVD snapshot to restore on emulator boot (default: cold boot)
+
+	Board_Addr  string // ssh address of the board for odroid vm
+	Power_Cycle string // host command to hard power-cycle the board for odroid vm (e.g. toggles a GPIO/USB relay)
+
+	Webhook     string // URL to POST JSON notifications about new crashes to
+	Quiet_Hours string // local time window during which notifications are batched into a digest, e.g. "22:00-08:00"
+	Export      string // file to append crash and stats records to (newline-delimited JSON, for offline loading into BigQuery/SQL)
+
+	Email_Addrs []string // addresses to email reports about new unique crashes to
+	Email_From  string   // sender address of crash emails (default: syzkaller@localhost)
+	Smtp_Addr   string   // SMTP server to send crash emails through (default: localhost:25)
+
+	Kernel_Src string // kernel source checkout, used to tag artifacts with kernel git commit
+
+	Kernel_Config    string // kernel .config location (path or URL) referenced in bug reports, a local file also guides candidate prioritization
+	Report_Templates string // dir with NAME.txt text/template bug report templates (see manager/reporting.go)
+
+	Name     string // manager name, identifies the manager on syz-hub
+	Hub_Addr string // syz-hub RPC address to exchange corpus and reproducers with other managers
+	Hub_Key  string // key of this manager in syz-hub config
+
+	// RPC address of a concolic/symbolic execution service (see rpctype.ConcolicSolveArgs).
+	// Programs blocked on comparisons that hints can't satisfy are sent to the service,
+	// returned programs are triaged as candidates. Requires comparisons.
+	Concolic_Addr string
+	Concolic_Key  string
+
+	Dashboard_Addr string // syz-dash RPC address to upload crashes and reproducers to
+	Dashboard_Key  string // key of this manager in syz-dash config
+
+	Http_Auth      string   // web UI authentication: none (default), basic or proxy (behind an OAuth proxy)
+	Http_Users     []string // basic: "user:password" pairs; proxy: allowed users (any if empty)
+	Http_Read_Only bool     // reject all state-changing web UI requests (restarts, uploads, traces)
+
+	Seeds string // directory with programs (or C reproducers with embedded programs) to triage on startup
+
+	Fresh_Corpus int // number of the most recent corpus programs sent to a freshly started VM (0: all)
+
+	// Corpus namespace: if set, the corpus is stored in workdir/corpus-NAME.db instead of corpus.db.
+	// Allows to fuzz a focus area (e.g. narrow enable_syscalls) in the same workdir
+	// without mixing its programs into the main corpus; use syz-db merge to combine corpora.
+	Corpus_Namespace string
+
+	// Triage is a command run on every new crash. It receives the crash report as JSON on stdin
+	// and can print a JSON verdict adjusting Title/Severity or setting Ignore/Suppress.
+	Triage string
+
+	// Named setup actions: shell commands run in the VM once before the first program
+	// that uses one of the Calls (e.g. "modprobe gadgetfs" for gadgetfs calls).
+	Setup []SetupAction
 
 	Enable_Syscalls  []string
 	Disable_Syscalls []string
-	Suppressions     []string
+	Suppressions     []string // regexps matched against console output, "title:REGEXP" is matched against crash titles
+}
+
+// Experiment describes treatment group of an A/B experiment.
+// Empty fields are inherited from the main config (control group).
+type Experiment struct {
+	Name     string
+	Share    int    // percent of VMs in treatment group (default: 50)
+	Strategy string // mutation strategy
+	Procs    int
+	Sandbox  string
+}
+
+// KernelVariant is one of the kernels booted in VMs.
+// Empty fields are inherited from the main config.
+type KernelVariant struct {
+	Name    string
+	Weight  int // relative share of VMs that boot this kernel (default: 1)
+	Vmlinux string
+	Kernel  string
+	Cmdline string
+	Image   string
+	Initrd  string
+}
+
+type SetupAction struct {
+	Name    string
+	Command string
+	Calls   []string // syscalls that require the action (same syntax as in enable_syscalls)
 }
 
 func Parse(filename string) (*Config, map[int]bool, []*regexp.Regexp, error) {
@@ -65,6 +248,11 @@ func Parse(filename string) (*Config, map[int]bool, []*regexp.Regexp, error) {
 }
 
 func parse(data []byte) (*Config, map[int]bool, []*regexp.Regexp, error) {
+	// Configs may contain comments and trailing commas.
+	data, err := stripComments(data)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
 	unknown, err := checkUnknownFields(data)
 	if err != nil {
 		return nil, nil, nil, err
@@ -74,30 +262,122 @@ func parse(data []byte) (*Config, map[int]bool, []*regexp.Regexp, error) {
 	}
 	cfg := new(Config)
 	cfg.Cover = true
+	cfg.Reproduce = true
 	cfg.Sandbox = "setuid"
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to parse config file: %v", err)
 	}
+	var errs Errors
+	errorf := func(msg string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(msg, args...))
+	}
 	if _, err := os.Stat(filepath.Join(cfg.Syzkaller, "bin/syz-fuzzer")); err != nil {
-		return nil, nil, nil, fmt.Errorf("bad config syzkaller param: can't find bin/syz-fuzzer")
+		errorf("bad config syzkaller param: can't find bin/syz-fuzzer")
 	}
 	if _, err := os.Stat(filepath.Join(cfg.Syzkaller, "bin/syz-executor")); err != nil {
-		return nil, nil, nil, fmt.Errorf("bad config syzkaller param: can't find bin/syz-executor")
+		errorf("bad config syzkaller param: can't find bin/syz-executor")
 	}
 	if cfg.Http == "" {
-		return nil, nil, nil, fmt.Errorf("config param http is empty")
+		errorf("config param http is empty")
 	}
 	if cfg.Workdir == "" {
-		return nil, nil, nil, fmt.Errorf("config param workdir is empty")
+		errorf("config param workdir is empty")
 	}
 	if cfg.Vmlinux == "" {
-		return nil, nil, nil, fmt.Errorf("config param vmlinux is empty")
+		errorf("config param vmlinux is empty")
 	}
 	if cfg.Type == "" {
-		return nil, nil, nil, fmt.Errorf("config param type is empty")
+		errorf("config param type is empty")
 	}
 	if cfg.Count <= 0 || cfg.Count > 1000 {
-		return nil, nil, nil, fmt.Errorf("invalid config param count: %v, want (1, 1000]", cfg.Count)
+		errorf("invalid config param count: %v, want (1, 1000]", cfg.Count)
+	}
+	if cfg.Min_Count < 0 || cfg.Min_Count > cfg.Count {
+		errorf("invalid config param min_count: %v, want [0, %v]", cfg.Min_Count, cfg.Count)
+	}
+	if cfg.Max_Run_Time < 0 {
+		errorf("invalid config param max_run_time: %v", cfg.Max_Run_Time)
+	}
+	checkFile := func(name, file string) {
+		if file == "" {
+			return
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			errorf("bad config param %v: %v", name, err)
+			return
+		}
+		f.Close()
+	}
+	checkFile("kernel", cfg.Kernel)
+	if cfg.Type != "docker" {
+		checkFile("image", cfg.Image)
+	}
+	checkFile("initrd", cfg.Initrd)
+	checkFile("sshkey", cfg.Sshkey)
+	if cfg.Hub_Addr != "" {
+		if cfg.Name == "" {
+			errorf("config param name is empty (required for hub)")
+		}
+		if cfg.Hub_Key == "" {
+			errorf("config param hub_key is empty")
+		}
+	}
+	if cfg.Concolic_Addr != "" && (!cfg.Comparisons || !cfg.Cover) {
+		errorf("config param concolic_addr requires comparisons and cover")
+	}
+	if cfg.Dashboard_Addr != "" {
+		if cfg.Name == "" {
+			errorf("config param name is empty (required for dashboard)")
+		}
+		if cfg.Dashboard_Key == "" {
+			errorf("config param dashboard_key is empty")
+		}
+	}
+	if cfg.Report_Templates != "" {
+		if fi, err := os.Stat(cfg.Report_Templates); err != nil || !fi.IsDir() {
+			errorf("bad config param report_templates: %v is not a directory", cfg.Report_Templates)
+		}
+	}
+	if cfg.Seeds != "" {
+		if fi, err := os.Stat(cfg.Seeds); err != nil || !fi.IsDir() {
+			errorf("bad config param seeds: %v is not a directory", cfg.Seeds)
+		}
+	}
+	if cfg.Fresh_Corpus < 0 {
+		errorf("bad config param fresh_corpus: %v, want >= 0", cfg.Fresh_Corpus)
+	}
+	if cfg.Corpus_Namespace != "" && !corpusNamespaceRe.MatchString(cfg.Corpus_Namespace) {
+		errorf("bad config param corpus_namespace: %q, want [a-zA-Z0-9_-]+", cfg.Corpus_Namespace)
+	}
+	for _, disk := range cfg.Additional_Disks {
+		checkFile("additional_disks", disk)
+	}
+	if len(cfg.Additional_Disks) != 0 && cfg.Type != "qemu" {
+		errorf("config param additional_disks is supported only for qemu VMs")
+	}
+	if cfg.Image_Overlay && (cfg.Type != "qemu" || cfg.Image == "") {
+		errorf("config param image_overlay is supported only for qemu VMs with image")
+	}
+	if cfg.Save_Crash_Disk && !cfg.Image_Overlay {
+		errorf("config param save_crash_disk requires image_overlay")
+	}
+	if (cfg.Net_Model != "" || len(cfg.Net_Fwd) != 0 || cfg.Net_Tap != "") && cfg.Type != "qemu" {
+		errorf("config params net_model/net_fwd/net_tap are supported only for qemu VMs")
+	}
+	if cfg.Qemu_Args != "" && cfg.Type != "qemu" {
+		errorf("config param qemu_args is supported only for qemu VMs")
+	}
+	for _, port := range cfg.Net_Fwd {
+		if port <= 0 || port >= 64<<10 || port == 22 {
+			errorf("bad config param net_fwd: port %v", port)
+		}
+	}
+	if cfg.Net_Tap != "" && cfg.Count > 1 && !strings.Contains(cfg.Net_Tap, "%v") {
+		errorf("config param net_tap must contain %%v when count > 1")
+	}
+	if err := checkNics(cfg); err != nil {
+		errorf("bad config param nics: %v", err)
 	}
 	if cfg.Procs <= 0 {
 		cfg.Procs = 1
@@ -112,69 +392,436 @@ func parse(data []byte) (*Config, map[int]bool, []*regexp.Regexp, error) {
 	switch cfg.Output {
 	case "none", "stdout", "dmesg", "file":
 	default:
-		return nil, nil, nil, fmt.Errorf("config param output must contain one of none/stdout/dmesg/file")
+		errorf("config param output must contain one of none/stdout/dmesg/file")
+	}
+	if len(cfg.Devices) != 0 {
+		if cfg.Type != "adb" {
+			errorf("config param devices is supported only for adb VMs")
+		}
+		if cfg.Count > len(cfg.Devices) {
+			errorf("config param count (%v) is larger than number of devices (%v)", cfg.Count, len(cfg.Devices))
+		}
+		for i, dev := range cfg.Devices {
+			if dev.Serial == "" || dev.ConsoleDev == "" {
+				errorf("config param devices: device #%v must have both serial and consoledev", i)
+			}
+		}
+	}
+	if cfg.Type == "emulator" {
+		if cfg.Avd == "" {
+			errorf("config param avd is empty")
+		}
+	} else if cfg.Avd != "" || cfg.Snapshot != "" {
+		errorf("config params avd/snapshot are supported only for emulator VMs")
+	}
+	if strings.ContainsAny(cfg.Ftrace, "'\n") {
+		errorf("config param ftrace must not contain quotes or new lines")
+	}
+	if cfg.Repro_Count < 0 {
+		errorf("invalid config param repro_count: %v", cfg.Repro_Count)
+	}
+	if cfg.Repro_Count == 0 {
+		cfg.Repro_Count = cfg.Count
+		if cfg.Repro_Count > 4 {
+			cfg.Repro_Count = 4
+		}
+	}
+	if cfg.Repro_Attempts < 0 {
+		errorf("invalid config param repro_attempts: %v", cfg.Repro_Attempts)
+	}
+	if cfg.Repro_Attempts == 0 {
+		cfg.Repro_Attempts = 4
+	}
+	if cfg.Type == "odroid" {
+		if cfg.Count != 1 {
+			errorf("config param count must be 1 for odroid VMs")
+		}
+		// There are no spare boards to reproduce crashes on.
+		cfg.Reproduce = false
+		if cfg.Board_Addr == "" {
+			errorf("config param board_addr is empty")
+		}
+		if cfg.Power_Cycle == "" {
+			errorf("config param power_cycle is empty")
+		}
+	}
+	if cfg.Pressure < 0 || cfg.Pressure > 100 {
+		errorf("invalid config param pressure: %v, want [0, 100]", cfg.Pressure)
+	}
+	if cfg.Pressure_Mem == 0 {
+		cfg.Pressure_Mem = 256
+	}
+	switch cfg.Http_Auth {
+	case "":
+		cfg.Http_Auth = "none"
+	case "none", "proxy":
+	case "basic":
+		if len(cfg.Http_Users) == 0 {
+			errorf("config param http_users is empty")
+		}
+		for i, u := range cfg.Http_Users {
+			if colon := strings.IndexByte(u, ':'); colon <= 0 || colon == len(u)-1 {
+				errorf("bad http_users entry #%v, want user:password", i)
+			}
+		}
+	default:
+		errorf("config param http_auth must contain one of none/basic/proxy")
+	}
+	if cfg.Http_Auth == "none" && len(cfg.Http_Users) != 0 {
+		errorf("config param http_users requires http_auth")
+	}
+	switch cfg.Crash_Storm {
+	case "":
+		cfg.Crash_Storm = "none"
+	case "none", "disable", "deprioritize":
+	default:
+		errorf("config param crash_storm must contain one of none/disable/deprioritize")
+	}
+	switch cfg.Pm {
+	case "":
+		cfg.Pm = "none"
+	case "none", "freezer", "suspend":
+	default:
+		errorf("config param pm must contain one of none/freezer/suspend")
+	}
+	if cfg.Flaky_Repeat < 0 || cfg.Flaky_Repeat > 100 {
+		errorf("invalid config param flaky_repeat: %v, want [0, 100]", cfg.Flaky_Repeat)
+	}
+	if cfg.Pm_Period <= 0 {
+		cfg.Pm_Period = 600
+	}
+	if cfg.Leak_Period <= 0 {
+		cfg.Leak_Period = 60
+	}
+	if cfg.Program_Timeout <= 0 {
+		cfg.Program_Timeout = 5
+	}
+	if cfg.Disk_Min <= 0 {
+		cfg.Disk_Min = 64
+	}
+	if cfg.Virtfs && cfg.Type != "qemu" {
+		errorf("config param virtfs is supported only for qemu VMs")
 	}
 	switch cfg.Sandbox {
 	case "none", "setuid", "namespace":
 	default:
-		return nil, nil, nil, fmt.Errorf("config param sandbox must contain one of none/setuid/namespace")
+		errorf("config param sandbox must contain one of none/setuid/namespace")
+	}
+	if cfg.Fault_Max < 0 {
+		errorf("config param fault_max must not be negative")
+	}
+	if cfg.Fault_Max == 0 {
+		cfg.Fault_Max = 100
+	}
+	if _, err := DropCapsMasks(cfg); err != nil {
+		errorf("bad config param drop_caps: %v", err)
+	}
+	if cfg.Quiet_Hours != "" {
+		if _, _, err := ParseQuietHours(cfg.Quiet_Hours); err != nil {
+			errorf("bad config param quiet_hours: %v", err)
+		}
+	}
+	for _, addr := range cfg.Email_Addrs {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			errorf("bad config param email_addrs: %v", err)
+		}
+	}
+	if cfg.Email_From == "" {
+		cfg.Email_From = "syzkaller@localhost"
+	}
+	if _, err := mail.ParseAddress(cfg.Email_From); err != nil {
+		errorf("bad config param email_from: %v", err)
+	}
+	if cfg.Smtp_Addr == "" {
+		cfg.Smtp_Addr = "localhost:25"
+	}
+	if cfg.Strategy == "" {
+		cfg.Strategy = prog.DefaultStrategy
+	}
+	if _, err := prog.LookupStrategy(cfg.Strategy); err != nil {
+		errorf("bad config param strategy: %v", err)
+	}
+	if _, _, err := MutationWeights(cfg); err != nil {
+		errorf("bad config param mutation_weights: %v", err)
+	}
+
+	if e := cfg.Experiment; e != nil {
+		if e.Share == 0 {
+			e.Share = 50
+		}
+		if e.Share < 0 || e.Share > 100 {
+			errorf("invalid config param experiment.share: %v, want [0, 100]", e.Share)
+		}
+		if e.Strategy == "" {
+			e.Strategy = cfg.Strategy
+		}
+		if _, err := prog.LookupStrategy(e.Strategy); err != nil {
+			errorf("bad config param experiment.strategy: %v", err)
+		}
+		if e.Procs <= 0 {
+			e.Procs = cfg.Procs
+		}
+		switch e.Sandbox {
+		case "":
+			e.Sandbox = cfg.Sandbox
+		case "none", "setuid", "namespace":
+		default:
+			errorf("config param experiment.sandbox must contain one of none/setuid/namespace")
+		}
+	}
+
+	kernelNames := make(map[string]bool)
+	for i := range cfg.Kernels {
+		k := &cfg.Kernels[i]
+		if !corpusNamespaceRe.MatchString(k.Name) {
+			errorf("bad config param kernels: kernel #%v name %q, want [a-zA-Z0-9_-]+", i, k.Name)
+		}
+		if kernelNames[k.Name] {
+			errorf("config param kernels: duplicate kernel %v", k.Name)
+		}
+		kernelNames[k.Name] = true
+		if k.Weight < 0 {
+			errorf("invalid config param kernels: kernel %v weight %v", k.Name, k.Weight)
+		}
+		if k.Weight == 0 {
+			k.Weight = 1
+		}
+		checkFile("kernels.vmlinux", k.Vmlinux)
+		checkFile("kernels.kernel", k.Kernel)
+		if cfg.Type != "docker" {
+			checkFile("kernels.image", k.Image)
+		}
+		checkFile("kernels.initrd", k.Initrd)
+	}
+	if len(cfg.Kernels) > cfg.Count {
+		errorf("config param kernels: %v kernels need at least as many VMs, count is %v", len(cfg.Kernels), cfg.Count)
+	}
+
+	setupNames := make(map[string]bool)
+	for i, a := range cfg.Setup {
+		if a.Name == "" || a.Command == "" {
+			errorf("config param setup: action #%v must have both name and command", i)
+		}
+		if setupNames[a.Name] {
+			errorf("config param setup: duplicate action %v", a.Name)
+		}
+		setupNames[a.Name] = true
+		if _, err := MatchSyscalls(a.Calls); err != nil {
+			errorf("config param setup: action %v: %v", a.Name, err)
+		}
 	}
 
 	syscalls, err := parseSyscalls(cfg)
 	if err != nil {
-		return nil, nil, nil, err
+		errs = append(errs, err)
 	}
 
 	suppressions, err := parseSuppressions(cfg)
 	if err != nil {
-		return nil, nil, nil, err
+		errs = append(errs, err)
 	}
 
+	if len(errs) != 0 {
+		return nil, nil, nil, errs
+	}
 	return cfg, syscalls, suppressions, nil
 }
 
-func parseSyscalls(cfg *Config) (map[int]bool, error) {
-	match := func(call *sys.Call, str string) bool {
-		if str == call.CallName || str == call.Name {
-			return true
-		}
-		if len(str) > 1 && str[len(str)-1] == '*' && strings.HasPrefix(call.Name, str[:len(str)-1]) {
-			return true
+// Override returns contents of config file filename with the given top-level params replaced.
+// Param names are matched case-insensitively (as json.Unmarshal does), comments are dropped.
+func Override(filename string, params map[string]interface{}) ([]byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	return override(data, params)
+}
+
+func override(data []byte, params map[string]interface{}) ([]byte, error) {
+	data, err := stripComments(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
+	for name, val := range params {
+		for f := range fields {
+			if strings.EqualFold(f, name) {
+				delete(fields, f)
+			}
 		}
-		return false
+		fields[name] = val
 	}
+	return json.MarshalIndent(fields, "", "\t")
+}
 
-	syscalls := make(map[int]bool)
-	if len(cfg.Enable_Syscalls) != 0 {
-		for _, c := range cfg.Enable_Syscalls {
-			n := 0
-			for _, call := range sys.Calls {
-				if match(call, c) {
-					syscalls[call.ID] = true
-					n++
-				}
+// Errors is a list of config validation errors.
+// Parse checks all params and returns all detected problems at once.
+type Errors []error
+
+func (errs Errors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%v errors in config:", len(errs))
+	for _, err := range errs {
+		fmt.Fprintf(buf, "\n\t%v", err)
+	}
+	return buf.String()
+}
+
+// capabilities maps capability names to numbers (include/uapi/linux/capability.h).
+var capabilities = map[string]uint{
+	"CAP_CHOWN":            0,
+	"CAP_DAC_OVERRIDE":     1,
+	"CAP_DAC_READ_SEARCH":  2,
+	"CAP_FOWNER":           3,
+	"CAP_FSETID":           4,
+	"CAP_KILL":             5,
+	"CAP_SETGID":           6,
+	"CAP_SETUID":           7,
+	"CAP_SETPCAP":          8,
+	"CAP_LINUX_IMMUTABLE":  9,
+	"CAP_NET_BIND_SERVICE": 10,
+	"CAP_NET_BROADCAST":    11,
+	"CAP_NET_ADMIN":        12,
+	"CAP_NET_RAW":          13,
+	"CAP_IPC_LOCK":         14,
+	"CAP_IPC_OWNER":        15,
+	"CAP_SYS_MODULE":       16,
+	"CAP_SYS_RAWIO":        17,
+	"CAP_SYS_CHROOT":       18,
+	"CAP_SYS_PTRACE":       19,
+	"CAP_SYS_PACCT":        20,
+	"CAP_SYS_ADMIN":        21,
+	"CAP_SYS_BOOT":         22,
+	"CAP_SYS_NICE":         23,
+	"CAP_SYS_RESOURCE":     24,
+	"CAP_SYS_TIME":         25,
+	"CAP_SYS_TTY_CONFIG":   26,
+	"CAP_MKNOD":            27,
+	"CAP_LEASE":            28,
+	"CAP_AUDIT_WRITE":      29,
+	"CAP_AUDIT_CONTROL":    30,
+	"CAP_SETFCAP":          31,
+	"CAP_MAC_OVERRIDE":     32,
+	"CAP_MAC_ADMIN":        33,
+	"CAP_SYSLOG":           34,
+	"CAP_WAKE_ALARM":       35,
+	"CAP_BLOCK_SUSPEND":    36,
+	"CAP_AUDIT_READ":       37,
+}
+
+// DropCapsMasks returns rows of cfg.Drop_Caps as capability bit masks.
+func DropCapsMasks(cfg *Config) ([]uint64, error) {
+	var masks []uint64
+	for _, row := range cfg.Drop_Caps {
+		mask := uint64(0)
+		for _, name := range row {
+			c, ok := capabilities[strings.ToUpper(name)]
+			if !ok {
+				return nil, fmt.Errorf("unknown capability '%v'", name)
 			}
-			if n == 0 {
-				return nil, fmt.Errorf("unknown enabled syscall: %v", c)
+			mask |= 1 << c
+		}
+		masks = append(masks, mask)
+	}
+	return masks, nil
+}
+
+// MutationWeights returns weights of mutations and collide percent set in cfg.Mutation_Weights.
+func MutationWeights(cfg *Config) (prog.MutationWeights, int, error) {
+	collide := 100
+	weights := make(map[string]int)
+	for name, v := range cfg.Mutation_Weights {
+		if name == "collide" {
+			if v < 0 || v > 100 {
+				return prog.MutationWeights{}, 0, fmt.Errorf("invalid collide percent %v, want [0, 100]", v)
 			}
+			collide = v
+			continue
 		}
-	} else {
-		for _, call := range sys.Calls {
-			syscalls[call.ID] = true
+		weights[name] = v
+	}
+	w, err := prog.ParseMutationWeights(weights)
+	if err != nil {
+		return prog.MutationWeights{}, 0, err
+	}
+	return w, collide, nil
+}
+
+// ParseQuietHours parses "HH:MM-HH:MM" time window and returns
+// its start and end as offsets from midnight. The window can wrap around midnight.
+func ParseQuietHours(s string) (start, end time.Duration, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("want HH:MM-HH:MM, got '%v'", s)
+	}
+	var res [2]time.Duration
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("want HH:MM-HH:MM, got '%v'", s)
 		}
+		res[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if res[0] == res[1] {
+		return 0, 0, fmt.Errorf("empty time window '%v'", s)
+	}
+	return res[0], res[1], nil
+}
+
+func matchSyscall(call *sys.Call, str string) bool {
+	if str == call.CallName || str == call.Name {
+		return true
+	}
+	if len(str) > 1 && str[len(str)-1] == '*' && strings.HasPrefix(call.Name, str[:len(str)-1]) {
+		return true
 	}
-	for _, c := range cfg.Disable_Syscalls {
+	return false
+}
+
+// MatchSyscalls returns IDs of syscalls matching any of the patterns
+// (same syntax as in enable_syscalls).
+func MatchSyscalls(patterns []string) (map[int]bool, error) {
+	syscalls := make(map[int]bool)
+	for _, c := range patterns {
 		n := 0
 		for _, call := range sys.Calls {
-			if match(call, c) {
-				delete(syscalls, call.ID)
+			if matchSyscall(call, c) {
+				syscalls[call.ID] = true
 				n++
 			}
 		}
 		if n == 0 {
-			return nil, fmt.Errorf("unknown disabled syscall: %v", c)
+			return nil, fmt.Errorf("unknown syscall: %v", c)
+		}
+	}
+	return syscalls, nil
+}
+
+func parseSyscalls(cfg *Config) (map[int]bool, error) {
+	syscalls := make(map[int]bool)
+	if len(cfg.Enable_Syscalls) != 0 {
+		var err error
+		if syscalls, err = MatchSyscalls(cfg.Enable_Syscalls); err != nil {
+			return nil, fmt.Errorf("enable_syscalls: %v", err)
+		}
+	} else {
+		for _, call := range sys.Calls {
+			syscalls[call.ID] = true
 		}
 	}
+	disabled, err := MatchSyscalls(cfg.Disable_Syscalls)
+	if err != nil {
+		return nil, fmt.Errorf("disable_syscalls: %v", err)
+	}
+	for id := range disabled {
+		delete(syscalls, id)
+	}
 	// They will be generated anyway.
 	syscalls[sys.CallMap["mmap"].ID] = true
 	syscalls[sys.CallMap["clock_gettime"].ID] = true
@@ -182,6 +829,8 @@ func parseSyscalls(cfg *Config) (map[int]bool, error) {
 	return syscalls, nil
 }
 
+var corpusNamespaceRe = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
 func parseSuppressions(cfg *Config) ([]*regexp.Regexp, error) {
 	// Add some builtin suppressions.
 	supp := append(cfg.Suppressions, []string{
@@ -195,16 +844,93 @@ func parseSuppressions(cfg *Config) ([]*regexp.Regexp, error) {
 	}...)
 	var suppressions []*regexp.Regexp
 	for _, s := range supp {
+		if strings.HasPrefix(s, titleSuppressionPrefix) {
+			continue
+		}
 		re, err := regexp.Compile(s)
 		if err != nil {
 			return nil, fmt.Errorf("failed to compile suppression '%v': %v", s, err)
 		}
 		suppressions = append(suppressions, re)
 	}
+	if _, err := TitleSuppressions(cfg); err != nil {
+		return nil, err
+	}
+
+	return suppressions, nil
+}
 
+// titleSuppressionPrefix marks suppressions that are matched against crash titles
+// (e.g. "title:^WARNING in foo$") instead of the raw console output.
+const titleSuppressionPrefix = "title:"
+
+// TitleSuppressions returns title suppressions from cfg.Suppressions.
+// Crashes with matching titles are known bugs: the manager counts them,
+// but does not save logs, VM state or try to reproduce them.
+func TitleSuppressions(cfg *Config) ([]*regexp.Regexp, error) {
+	var suppressions []*regexp.Regexp
+	for _, s := range cfg.Suppressions {
+		if !strings.HasPrefix(s, titleSuppressionPrefix) {
+			continue
+		}
+		re, err := regexp.Compile(s[len(titleSuppressionPrefix):])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile suppression '%v': %v", s, err)
+		}
+		suppressions = append(suppressions, re)
+	}
 	return suppressions, nil
 }
 
+// KernelConfig returns a copy of cfg that boots kernel variant k.
+func KernelConfig(cfg *Config, k *KernelVariant) *Config {
+	kcfg := *cfg
+	kcfg.Kernels = nil
+	if k.Vmlinux != "" {
+		kcfg.Vmlinux = k.Vmlinux
+	}
+	if k.Kernel != "" {
+		kcfg.Kernel = k.Kernel
+	}
+	if k.Cmdline != "" {
+		kcfg.Cmdline = k.Cmdline
+	}
+	if k.Image != "" {
+		kcfg.Image = k.Image
+	}
+	if k.Initrd != "" {
+		kcfg.Initrd = k.Initrd
+	}
+	return &kcfg
+}
+
+// AssignKernels returns index of the kernel variant for every VM slot, or nil if cfg.Kernels is empty.
+// Slots are distributed according to weights with smooth weighted round-robin,
+// so that variants are interleaved (e.g. memory pressure slots are the last ones).
+func AssignKernels(cfg *Config) []int {
+	if len(cfg.Kernels) == 0 {
+		return nil
+	}
+	total := 0
+	for _, k := range cfg.Kernels {
+		total += k.Weight
+	}
+	current := make([]int, len(cfg.Kernels))
+	slots := make([]int, cfg.Count)
+	for i := range slots {
+		best := 0
+		for j, k := range cfg.Kernels {
+			current[j] += k.Weight
+			if current[best] < current[j] {
+				best = j
+			}
+		}
+		current[best] -= total
+		slots[i] = best
+	}
+	return slots
+}
+
 func CreateVMConfig(cfg *Config) (*vm.Config, error) {
 	workdir, index, err := fileutil.ProcessTempDir(cfg.Workdir)
 	if err != nil {
@@ -218,16 +944,87 @@ func CreateVMConfig(cfg *Config) (*vm.Config, error) {
 		Kernel:     cfg.Kernel,
 		Cmdline:    cfg.Cmdline,
 		Image:      cfg.Image,
+		Initrd:     cfg.Initrd,
+		Disks:      cfg.Additional_Disks,
+		Overlay:    cfg.Image_Overlay,
 		Sshkey:     cfg.Sshkey,
 		Executor:   filepath.Join(cfg.Syzkaller, "bin", "syz-executor"),
 		ConsoleDev: cfg.ConsoleDev,
+		Devices:    cfg.Devices,
+		Reflash:    cfg.Reflash,
+		Avd:        cfg.Avd,
+		Snapshot:   cfg.Snapshot,
+		Addr:       cfg.Board_Addr,
+		PowerCycle: cfg.Power_Cycle,
 		Cpu:        cfg.Cpu,
 		Mem:        cfg.Mem,
 		Debug:      cfg.Debug,
 	}
+	if cfg.Virtfs {
+		vmCfg.Sharedir = filepath.Join(cfg.Syzkaller, "bin")
+	}
+	vmCfg.NetModel = cfg.Net_Model
+	vmCfg.QemuArgs = cfg.Qemu_Args
+	vmCfg.NetFwd = cfg.Net_Fwd
+	if cfg.Net_Tap != "" {
+		vmCfg.NetTap = strings.Replace(cfg.Net_Tap, "%v", fmt.Sprint(index), -1)
+	}
+	for _, nic := range cfg.Nics {
+		nic.Ifname = strings.Replace(nic.Ifname, "%v", fmt.Sprint(index), -1)
+		vmCfg.Nics = append(vmCfg.Nics, nic)
+	}
 	return vmCfg, nil
 }
 
+// checkNics validates cfg.Nics and fills in defaults.
+func checkNics(cfg *Config) error {
+	if len(cfg.Nics) != 0 && cfg.Type != "qemu" {
+		return fmt.Errorf("nics are supported only for qemu VMs")
+	}
+	for i := range cfg.Nics {
+		nic := &cfg.Nics[i]
+		if vm.NicTypes[nic.Type] == "" {
+			return fmt.Errorf("nic #%v: unknown type '%v' (want user/tap/bridge/socket)", i, nic.Type)
+		}
+		if nic.Model == "" {
+			nic.Model = cfg.Net_Model
+		}
+		if nic.Net != "" && nic.Type != "user" {
+			return fmt.Errorf("nic #%v: net is supported only for user NICs", i)
+		}
+		if nic.Net != "" {
+			if _, _, err := net.ParseCIDR(nic.Net); err != nil {
+				return fmt.Errorf("nic #%v: bad net: %v", i, err)
+			}
+		}
+		if (nic.Ifname != "") != (nic.Type == "tap") {
+			return fmt.Errorf("nic #%v: ifname must be specified for (only) tap NICs", i)
+		}
+		if nic.Type == "tap" && cfg.Count > 1 && !strings.Contains(nic.Ifname, "%v") {
+			return fmt.Errorf("nic #%v: ifname must contain %%v when count > 1", i)
+		}
+		if (nic.Bridge != "") != (nic.Type == "bridge") {
+			return fmt.Errorf("nic #%v: bridge must be specified for (only) bridge NICs", i)
+		}
+		if nic.Mcast != "" && nic.Type != "socket" {
+			return fmt.Errorf("nic #%v: mcast is supported only for socket NICs", i)
+		}
+		if nic.Type == "socket" {
+			if nic.Mcast == "" {
+				nic.Mcast = fmt.Sprintf("230.0.0.1:%v", 1234+i)
+			}
+			host, _, err := net.SplitHostPort(nic.Mcast)
+			if err != nil {
+				return fmt.Errorf("nic #%v: bad mcast: %v", i, err)
+			}
+			if ip := net.ParseIP(host); ip == nil || !ip.IsMulticast() {
+				return fmt.Errorf("nic #%v: mcast address %v is not multicast", i, host)
+			}
+		}
+	}
+	return nil
+}
+
 func checkUnknownFields(data []byte) (string, error) {
 	// While https://github.com/golang/go/issues/15314 is not resolved
 	// we don't have a better way than to enumerate all known fields.
@@ -235,24 +1032,92 @@ func checkUnknownFields(data []byte) (string, error) {
 		"Http",
 		"Workdir",
 		"Vmlinux",
+		"Kernel_Src",
+		"Name",
+		"Hub_Addr",
+		"Hub_Key",
+		"Concolic_Addr",
+		"Concolic_Key",
+		"Dashboard_Addr",
+		"Dashboard_Key",
+		"Http_Auth",
+		"Http_Users",
+		"Http_Read_Only",
+		"Seeds",
+		"Rpc_Key",
+		"Kernel_Config",
+		"Report_Templates",
+		"Corpus_Namespace",
+		"Fresh_Corpus",
 		"Kernel",
 		"Cmdline",
 		"Image",
+		"Initrd",
+		"Additional_Disks",
+		"Image_Overlay",
+		"Save_Crash_Disk",
 		"Cpu",
 		"Mem",
 		"Sshkey",
 		"Port",
 		"Bin",
+		"Virtfs",
+		"Net_Model",
+		"Net_Fwd",
+		"Net_Tap",
+		"Nics",
+		"Qemu_Args",
 		"Debug",
 		"Output",
 		"Syzkaller",
 		"Type",
 		"Count",
+		"Min_Count",
 		"Procs",
 		"Cover",
 		"Sandbox",
+		"Drop_Caps",
+		"Dangerous_Calls",
 		"Leak",
+		"Leak_Period",
+		"Lockdep_Reboot",
+		"Pressure",
+		"Pressure_Mem",
+		"Program_Timeout",
+		"Disk_Min",
+		"Pm",
+		"Pm_Period",
+		"Strategy",
+		"Mutation_Weights",
+		"Flaky_Repeat",
+		"Errno_Feedback",
+		"Comparisons",
+		"Fault_Injection",
+		"Fault_Max",
+		"Reproduce",
+		"Repro_Count",
+		"Repro_Attempts",
+		"Crash_Storm",
+		"Ftrace",
+		"Max_Run_Time",
+		"Max_Execs",
+		"Experiment",
+		"Kernels",
 		"ConsoleDev",
+		"Devices",
+		"Reflash",
+		"Avd",
+		"Snapshot",
+		"Board_Addr",
+		"Power_Cycle",
+		"Webhook",
+		"Quiet_Hours",
+		"Email_Addrs",
+		"Email_From",
+		"Smtp_Addr",
+		"Export",
+		"Triage",
+		"Setup",
 		"Enable_Syscalls",
 		"Disable_Syscalls",
 		"Suppressions",
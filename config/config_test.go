@@ -4,7 +4,15 @@
 package config
 
 import (
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/vm"
 )
 
 func TestUnknown(t *testing.T) {
@@ -14,3 +22,254 @@ func TestUnknown(t *testing.T) {
 		t.Fatalf("unknown field is not detected (%v)", err)
 	}
 }
+
+func TestStripComments(t *testing.T) {
+	tests := map[string]string{
+		`{"a": 1}`:                       `{"a": 1}`,
+		"{\"a\": 1, // comment\n}":       "{\"a\": 1            \n}",
+		`{"a": "//not a comment", }`:     `{"a": "//not a comment"  }`,
+		`{/* ,} */"a": [1, 2,], "b": 3}`: `{        "a": [1, 2 ], "b": 3}`,
+		`{"a": "\"/*", "b": 1}`:          `{"a": "\"/*", "b": 1}`,
+	}
+	for data, want := range tests {
+		got, err := stripComments([]byte(data))
+		if err != nil {
+			t.Fatalf("failed to strip comments in %q: %v", data, err)
+		}
+		if string(got) != want {
+			t.Fatalf("bad result for %q:\ngot:  %q\nwant: %q", data, got, want)
+		}
+	}
+	for _, data := range []string{`{"a": "foo}`, `{"a": 1 /* comment}`} {
+		if _, err := stripComments([]byte(data)); err == nil {
+			t.Fatalf("no error for malformed %q", data)
+		}
+	}
+}
+
+func TestUnknownWithComments(t *testing.T) {
+	data := `{
+		// comment
+		"foo": "bar",
+	}`
+	_, _, _, err := parse([]byte(data))
+	if err == nil || err.Error() != "unknown field 'foo' in config" {
+		t.Fatalf("unknown field is not detected (%v)", err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	data := `{
+		"Workdir": "/old", // comment
+		"procs": 4,
+	}`
+	res, err := override([]byte(data), map[string]interface{}{"workdir": "/new", "reproduce": true})
+	if err != nil {
+		t.Fatalf("failed to override: %v", err)
+	}
+	cfg := new(Config)
+	if err := json.Unmarshal(res, cfg); err != nil {
+		t.Fatalf("failed to parse result: %v\n%s", err, res)
+	}
+	if cfg.Workdir != "/new" || cfg.Procs != 4 || !cfg.Reproduce {
+		t.Fatalf("bad result: %s", res)
+	}
+	if strings.Contains(string(res), "/old") {
+		t.Fatalf("old param is not removed: %s", res)
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	start, end, err := ParseQuietHours("22:30-08:00")
+	if err != nil {
+		t.Fatalf("failed to parse quiet hours: %v", err)
+	}
+	if start != 22*time.Hour+30*time.Minute || end != 8*time.Hour {
+		t.Fatalf("bad quiet hours: %v-%v", start, end)
+	}
+	for _, s := range []string{"", "22:00", "22:00-25:00", "8-9", "10:00-10:00"} {
+		if _, _, err := ParseQuietHours(s); err == nil {
+			t.Fatalf("no error for bad quiet hours '%v'", s)
+		}
+	}
+}
+
+func TestMultipleErrors(t *testing.T) {
+	data := `{"count": 0, "sandbox": "foo", "sshkey": "/non/existent/key"}`
+	_, _, _, err := parse([]byte(data))
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+	want := []string{
+		"invalid config param count",
+		"config param sandbox must contain",
+		"bad config param sshkey",
+		"config param http is empty",
+	}
+	for _, w := range want {
+		found := false
+		for _, e := range errs {
+			if strings.HasPrefix(e.Error(), w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("error '%v' is not reported in:\n%v", w, err)
+		}
+	}
+}
+
+func TestMatchSyscalls(t *testing.T) {
+	calls, err := MatchSyscalls([]string{"open", "ioctl$KVM*"})
+	if err != nil {
+		t.Fatalf("failed to match syscalls: %v", err)
+	}
+	for id := range calls {
+		name := sys.Calls[id].Name
+		if name != "open" && !strings.HasPrefix(name, "open$") && !strings.HasPrefix(name, "ioctl$KVM") {
+			t.Fatalf("unexpected matched syscall %v", name)
+		}
+	}
+	if !calls[sys.CallMap["open"].ID] {
+		t.Fatalf("open is not matched")
+	}
+	if _, err := MatchSyscalls([]string{"foo$bar"}); err == nil {
+		t.Fatalf("unknown syscall is matched")
+	}
+}
+
+func TestTitleSuppressions(t *testing.T) {
+	cfg := &Config{Suppressions: []string{"foo bar", "title:^WARNING in (foo|bar)$"}}
+	output, err := parseSuppressions(cfg)
+	if err != nil {
+		t.Fatalf("failed to parse suppressions: %v", err)
+	}
+	for _, re := range output {
+		if strings.Contains(re.String(), "WARNING in") {
+			t.Fatalf("title suppression is matched against output: %v", re)
+		}
+	}
+	titles, err := TitleSuppressions(cfg)
+	if err != nil {
+		t.Fatalf("failed to parse title suppressions: %v", err)
+	}
+	if len(titles) != 1 || !titles[0].MatchString("WARNING in bar") || titles[0].MatchString("WARNING in baz") {
+		t.Fatalf("bad title suppressions: %v", titles)
+	}
+	cfg.Suppressions = []string{"title:(foo"}
+	if _, err := parseSuppressions(cfg); err == nil {
+		t.Fatalf("bad title suppression is not detected")
+	}
+}
+
+func TestDropCapsMasks(t *testing.T) {
+	cfg := &Config{Drop_Caps: [][]string{{}, {"CAP_SYS_ADMIN"}, {"cap_net_admin", "CAP_NET_RAW"}}}
+	masks, err := DropCapsMasks(cfg)
+	if err != nil {
+		t.Fatalf("failed to parse drop_caps: %v", err)
+	}
+	want := []uint64{0, 1 << 21, 1<<12 | 1<<13}
+	if !reflect.DeepEqual(masks, want) {
+		t.Fatalf("bad masks: got %x, want %x", masks, want)
+	}
+	cfg.Drop_Caps = [][]string{{"CAP_FOO"}}
+	if _, err := DropCapsMasks(cfg); err == nil {
+		t.Fatalf("unknown capability is not detected")
+	}
+}
+
+func TestMutationWeights(t *testing.T) {
+	w, collide, err := MutationWeights(&Config{})
+	if err != nil || w != prog.DefaultMutationWeights || collide != 100 {
+		t.Fatalf("bad default weights: %+v, %v, %v", w, collide, err)
+	}
+	cfg := &Config{Mutation_Weights: map[string]int{"splice": 5, "remove": 0, "collide": 30}}
+	w, collide, err = MutationWeights(cfg)
+	if err != nil {
+		t.Fatalf("failed to parse mutation_weights: %v", err)
+	}
+	want := prog.DefaultMutationWeights
+	want.Splice = 5
+	want.Remove = 0
+	if w != want || collide != 30 {
+		t.Fatalf("bad weights: got %+v/%v, want %+v/30", w, collide, want)
+	}
+	for _, weights := range []map[string]int{{"collide": 101}, {"foo": 1}} {
+		if _, _, err := MutationWeights(&Config{Mutation_Weights: weights}); err == nil {
+			t.Fatalf("weights %v are accepted", weights)
+		}
+	}
+}
+
+func TestNics(t *testing.T) {
+	cfg := &Config{Type: "qemu", Count: 2, Net_Model: "virtio-net-pci", Nics: []vm.NIC{
+		{Type: "user", Net: "10.0.3.0/24"},
+		{Type: "tap", Ifname: "tap%v", Model: "e1000"},
+		{Type: "socket"},
+	}}
+	if err := checkNics(cfg); err != nil {
+		t.Fatalf("failed to check nics: %v", err)
+	}
+	if cfg.Nics[0].Model != "virtio-net-pci" || cfg.Nics[1].Model != "e1000" || cfg.Nics[2].Mcast != "230.0.0.1:1236" {
+		t.Fatalf("bad defaults: %+v", cfg.Nics)
+	}
+	for _, nic := range []vm.NIC{
+		{Type: "foo"},
+		{Type: "user", Net: "10.0.3.0"},
+		{Type: "tap"},
+		{Type: "tap", Ifname: "tap0"},
+		{Type: "bridge"},
+		{Type: "user", Bridge: "br0"},
+		{Type: "socket", Mcast: "10.0.0.1:1234"},
+	} {
+		if err := checkNics(&Config{Type: "qemu", Count: 2, Nics: []vm.NIC{nic}}); err == nil {
+			t.Fatalf("bad nic %+v is accepted", nic)
+		}
+	}
+	if err := checkNics(&Config{Type: "kvm", Nics: []vm.NIC{{Type: "user"}}}); err == nil {
+		t.Fatalf("nics are accepted for kvm VMs")
+	}
+}
+
+func TestAssignKernels(t *testing.T) {
+	cfg := &Config{
+		Count:   6,
+		Kernels: []KernelVariant{{Name: "base", Weight: 2}, {Name: "patched", Weight: 1}},
+	}
+	slots := AssignKernels(cfg)
+	want := []int{0, 1, 0, 0, 1, 0}
+	for i := range want {
+		if slots[i] != want[i] {
+			t.Fatalf("bad kernel slots: got %v, want %v", slots, want)
+		}
+	}
+	if AssignKernels(&Config{Count: 6}) != nil {
+		t.Fatalf("kernel slots without kernels")
+	}
+	kcfg := KernelConfig(&Config{Kernel: "bzImage", Image: "image", Kernels: cfg.Kernels},
+		&KernelVariant{Name: "patched", Kernel: "bzImage.patched"})
+	if kcfg.Kernel != "bzImage.patched" || kcfg.Image != "image" || kcfg.Kernels != nil {
+		t.Fatalf("bad kernel config: %+v", kcfg)
+	}
+}
+
+func TestManagerKeys(t *testing.T) {
+	keys, err := MakeManagerKeys([]ManagerKey{{"a", "key-a"}, {"b", "key-b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Auth("a", "key-a"); err != nil {
+		t.Fatalf("good key is rejected: %v", err)
+	}
+	if keys.Auth("a", "key-b") == nil || keys.Auth("c", "key-a") == nil || keys.Auth("b", "") == nil {
+		t.Fatalf("bad key is accepted")
+	}
+	if _, err := MakeManagerKeys([]ManagerKey{{"a", "key-a"}, {"a", "key-b"}}); err == nil {
+		t.Fatalf("duplicate manager is accepted")
+	}
+	if _, err := MakeManagerKeys([]ManagerKey{{"a", ""}}); err == nil {
+		t.Fatalf("empty key is accepted")
+	}
+}
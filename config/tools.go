@@ -0,0 +1,67 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+)
+
+// LoadFile reads JSON config of a tool other than syz-manager (syz-hub, syz-dash, syz-ci)
+// into cfg. As in the manager config, comments and trailing commas are allowed.
+// Validation of the params is up to the tool.
+func LoadFile(filename string, cfg interface{}) error {
+	if filename == "" {
+		return fmt.Errorf("supply config in -config flag")
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+	if data, err = stripComments(data); err != nil {
+		return fmt.Errorf("failed to parse config file: %v", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file: %v", err)
+	}
+	return nil
+}
+
+// ManagerKey is a manager listed in syz-hub/syz-dash config, the manager
+// presents the key in every RPC (cfg.Hub_Key/Dashboard_Key in the manager config).
+type ManagerKey struct {
+	Name string
+	Key  string
+}
+
+// ManagerKeys maps manager names to their keys.
+type ManagerKeys map[string]string
+
+// MakeManagerKeys checks the managers config param and indexes it by manager name.
+func MakeManagerKeys(managers []ManagerKey) (ManagerKeys, error) {
+	keys := make(ManagerKeys)
+	for _, mgr := range managers {
+		if mgr.Name == "" || mgr.Key == "" {
+			return nil, fmt.Errorf("config param managers: manager name and key must not be empty")
+		}
+		if _, ok := keys[mgr.Name]; ok {
+			return nil, fmt.Errorf("config param managers: duplicate manager %v", mgr.Name)
+		}
+		keys[mgr.Name] = mgr.Key
+	}
+	return keys, nil
+}
+
+// Auth checks the key presented by manager name.
+func (keys ManagerKeys) Auth(name, key string) error {
+	expected, ok := keys[name]
+	if !ok || subtle.ConstantTimeCompare([]byte(key), []byte(expected)) != 1 {
+		log.Printf("bad key from %v", name)
+		return fmt.Errorf("unauthorized manager")
+	}
+	return nil
+}
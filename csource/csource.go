@@ -19,15 +19,26 @@ import (
 type Options struct {
 	Threaded bool
 	Collide  bool
+	Repeat   bool // execute the program in a loop in fresh child processes
+	Procs    int  // number of parallel processes executing the loop (with Repeat), 0 means 1
 }
 
 func Write(p *prog.Prog, opts Options) []byte {
 	exec := p.SerializeForExec()
 	w := new(bytes.Buffer)
 
-	fmt.Fprintf(w, `// autogenerated by syzkaller (http://github.com/google/syzkaller)
+	fmt.Fprintf(w, "// autogenerated by syzkaller (http://github.com/google/syzkaller)\n")
+	// Embed the original program, so that the source is self-contained
+	// and can be fed back to syzkaller (e.g. as a seed program).
+	for _, line := range strings.Split(strings.TrimSpace(string(p.Serialize())), "\n") {
+		fmt.Fprintf(w, "// %v\n", line)
+	}
+	fmt.Fprintf(w, `
 #include <unistd.h>
 #include <sys/syscall.h>
+#include <sys/types.h>
+#include <sys/wait.h>
+#include <signal.h>
 #include <string.h>
 #include <stdint.h>
 #include <pthread.h>
@@ -50,8 +61,12 @@ func Write(p *prog.Prog, opts Options) []byte {
 	calls, nvar := generateCalls(exec)
 	fmt.Fprintf(w, "long r[%v];\n\n", nvar)
 
+	testFunc := "main"
+	if opts.Repeat {
+		testFunc = "test"
+	}
 	if !opts.Threaded && !opts.Collide {
-		fmt.Fprintf(w, "int main()\n{\n")
+		fmt.Fprintf(w, "int %v()\n{\n", testFunc)
 		fmt.Fprintf(w, "\tmemset(r, -1, sizeof(r));\n")
 		for _, c := range calls {
 			fmt.Fprintf(w, "%s", c)
@@ -68,7 +83,7 @@ func Write(p *prog.Prog, opts Options) []byte {
 		fmt.Fprintf(w, "\t}\n")
 		fmt.Fprintf(w, "\treturn 0;\n}\n\n")
 
-		fmt.Fprintf(w, "int main()\n{\n")
+		fmt.Fprintf(w, "int %v()\n{\n", testFunc)
 		fmt.Fprintf(w, "\tlong i;\n")
 		fmt.Fprintf(w, "\tpthread_t th[%v];\n", len(calls))
 		fmt.Fprintf(w, "\n")
@@ -87,9 +102,67 @@ func Write(p *prog.Prog, opts Options) []byte {
 		fmt.Fprintf(w, "\tusleep(100000);\n")
 		fmt.Fprintf(w, "\treturn 0;\n}\n")
 	}
+	if opts.Repeat {
+		fmt.Fprintf(w, "\n%s", repeatLoop)
+		if opts.Procs > 1 {
+			fmt.Fprintf(w, "\n"+procsMain, opts.Procs)
+		} else {
+			fmt.Fprintf(w, "\n%s", repeatMain)
+		}
+	}
 	return w.Bytes()
 }
 
+// repeatLoop executes test in a loop, every time in a fresh child process
+// that is killed if it does not finish within 5 seconds.
+const repeatLoop = `void loop()
+{
+	int pid, status, i;
+
+	for (;;) {
+		pid = fork();
+		if (pid < 0)
+			_exit(1);
+		if (pid == 0) {
+			test();
+			_exit(0);
+		}
+		for (i = 0; i < 100; i++) {
+			if (waitpid(pid, &status, WNOHANG) == pid)
+				break;
+			usleep(50000);
+		}
+		if (i == 100) {
+			kill(pid, SIGKILL);
+			waitpid(pid, &status, 0);
+		}
+	}
+}
+`
+
+const repeatMain = `int main()
+{
+	loop();
+	return 0;
+}
+`
+
+// procsMain runs the loop in several processes in parallel, like syz-execprog -procs does.
+const procsMain = `int main()
+{
+	int i;
+
+	for (i = 0; i < %v; i++) {
+		if (fork() == 0) {
+			loop();
+			return 0;
+		}
+	}
+	sleep(1000000);
+	return 0;
+}
+`
+
 func generateCalls(exec []byte) ([]string, int) {
 	read := func() uintptr {
 		if len(exec) < 8 {
@@ -4,8 +4,10 @@
 package csource
 
 import (
+	"bytes"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -30,12 +32,14 @@ func Test(t *testing.T) {
 		Options{},
 		Options{Threaded: true},
 		Options{Threaded: true, Collide: true},
+		Options{Repeat: true},
+		Options{Threaded: true, Collide: true, Repeat: true, Procs: 4},
 	}
+	// Building every program with all options takes too long,
+	// so programs are built with one set of options each in turn.
 	for i := 0; i < iters; i++ {
 		p := prog.Generate(rs, 10, nil)
-		for _, opts := range options {
-			testOne(t, p, opts)
-		}
+		testOne(t, p, options[i%len(options)])
 	}
 }
 
@@ -54,3 +58,20 @@ func testOne(t *testing.T, p *prog.Prog, opts Options) {
 	}
 	defer os.Remove(bin)
 }
+
+func TestEmbeddedProgram(t *testing.T) {
+	rs, iters := initTest(t)
+	for i := 0; i < iters; i++ {
+		p := prog.Generate(rs, 10, nil)
+		data := p.Serialize()
+		buf := new(bytes.Buffer)
+		for _, line := range strings.Split(string(Write(p, Options{})), "\n") {
+			if strings.HasPrefix(line, "// ") && !strings.HasPrefix(line, "// autogenerated") {
+				buf.WriteString(line[3:] + "\n")
+			}
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("embedded program differs:\n%s\nwant:\n%s", buf.Bytes(), data)
+		}
+	}
+}
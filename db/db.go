@@ -0,0 +1,481 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package db implements a simple append-only key-value database used to store corpus.
+// The database is a single file with a header followed by a log of records.
+// Every write appends a record, so a crash can lose at most the last record,
+// and a truncated or corrupted tail is discarded on open. Compact rewrites
+// the file atomically leaving only live records (the new file and its directory
+// are fsync-ed before and after rename, so a crash leaves either the old or the new file).
+// Only one writer can open the database at a time (the file is flock-ed),
+// but any number of readers can read it concurrently with the writer.
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/google/syzkaller/fileutil"
+)
+
+// Record is a database value with metadata.
+type Record struct {
+	Val    []byte
+	Seq    uint64    // database version at which the record was written
+	Time   time.Time // time the record was first saved
+	Signal int       // size of coverage signal of the input
+	Desc   string    // free-form description (e.g. kernel the input was found on)
+	// Succeeded are indices of resource-producing calls that succeeded when the input was triaged.
+	Succeeded []int
+}
+
+type DB struct {
+	Version uint64            // arbitrary user version, bumped with BumpVersion
+	Records map[string]Record // live records
+
+	filename string
+	f        *os.File
+	stale    int // number of overwritten or deleted records in the file
+}
+
+const (
+	magic         = uint32(0x5a53db01)
+	formatVersion = uint32(1)
+	headerSize    = 16
+
+	opSave    = 1
+	opDelete  = 2
+	opVersion = 3
+)
+
+// Open opens the database for writing, creating it if it does not exist.
+func Open(filename string) (*DB, error) {
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	if err := fileutil.LockFile(f, false); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("database %v is locked by another process: %v", filename, err)
+	}
+	db := &DB{
+		filename: filename,
+		f:        f,
+	}
+	size, err := db.load(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if size == 0 {
+		// New database or a crash before the header has reached the disk.
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to truncate database: %v", err)
+		}
+		if _, err := f.Seek(0, 0); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to seek database: %v", err)
+		}
+		if err := db.writeHeader(f, db.Version); err != nil {
+			f.Close()
+			return nil, err
+		}
+		size = headerSize
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat database: %v", err)
+	}
+	if fi.Size() != size {
+		log.Printf("database %v is corrupted, discarding %v bytes", filename, fi.Size()-size)
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to truncate database: %v", err)
+		}
+	}
+	if _, err := f.Seek(size, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to seek database: %v", err)
+	}
+	return db, nil
+}
+
+// ReadRecords reads the database without locking it (e.g. while it is open by syz-manager).
+func ReadRecords(filename string) (*DB, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer f.Close()
+	db := &DB{filename: filename}
+	if _, err := db.load(f); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Create creates a new database with the given records, replacing existing file.
+func Create(filename string, version uint64, records map[string]Record) error {
+	db := &DB{
+		Version:  version,
+		Records:  records,
+		filename: filename,
+	}
+	return db.rewrite()
+}
+
+// Save adds or replaces the record. If the key already exists,
+// Time of the old record is preserved.
+func (db *DB) Save(key string, rec Record) error {
+	if old, ok := db.Records[key]; ok {
+		db.stale++
+		if !old.Time.IsZero() {
+			rec.Time = old.Time
+		}
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	rec.Seq = db.Version
+	db.Records[key] = rec
+	return db.append(encodeRecord(opSave, key, rec))
+}
+
+func (db *DB) Delete(key string) error {
+	if _, ok := db.Records[key]; !ok {
+		return nil
+	}
+	delete(db.Records, key)
+	db.stale += 2 // both the save and the delete records
+	return db.append(encodeRecord(opDelete, key, Record{}))
+}
+
+// BumpVersion sets database version that is recorded in all subsequently saved records.
+func (db *DB) BumpVersion(version uint64) error {
+	if db.Version == version {
+		return nil
+	}
+	db.Version = version
+	db.stale++
+	return db.append(encodeRecord(opVersion, "", Record{Seq: version}))
+}
+
+// Stale returns number of dead records in the file, can be used to decide when to Compact.
+func (db *DB) Stale() int {
+	return db.stale
+}
+
+// Compact atomically rewrites the database file leaving only live records.
+func (db *DB) Compact() error {
+	if err := db.rewrite(); err != nil {
+		return err
+	}
+	db.stale = 0
+	return nil
+}
+
+func (db *DB) Close() error {
+	return db.f.Close()
+}
+
+// append writes a record with a single write, so that concurrent readers
+// see either nothing or the whole record.
+func (db *DB) append(data []byte) error {
+	if _, err := db.f.Write(data); err != nil {
+		return fmt.Errorf("failed to write database: %v", err)
+	}
+	return nil
+}
+
+// rewrite writes all live records into a temp file and renames it over the database file.
+// If the database is open for writing, it switches to the new file.
+func (db *DB) rewrite() error {
+	tmp := db.filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to create database: %v", err)
+	}
+	if db.f != nil {
+		// Lock the new file before it becomes visible.
+		if err := fileutil.LockFile(f, false); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("failed to lock database: %v", err)
+		}
+	}
+	if err := db.writeHeader(f, db.Version); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for key, rec := range db.Records {
+		if _, err := w.Write(encodeRecord(opSave, key, rec)); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("failed to write database: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write database: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to sync database: %v", err)
+	}
+	if runtime.GOOS == "windows" && db.f != nil {
+		// Windows does not allow to rename over an open file.
+		db.f.Close()
+	}
+	if err := os.Rename(tmp, db.filename); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		if runtime.GOOS == "windows" && db.f != nil {
+			var err1 error
+			if db.f, err1 = os.OpenFile(db.filename, os.O_RDWR|os.O_APPEND, 0640); err1 != nil {
+				return fmt.Errorf("failed to reopen database: %v", err1)
+			}
+		}
+		return fmt.Errorf("failed to rename database: %v", err)
+	}
+	fileutil.SyncDir(filepath.Dir(db.filename))
+	if db.f == nil {
+		return f.Close()
+	}
+	db.f.Close()
+	db.f = f
+	return nil
+}
+
+func (db *DB) writeHeader(f *os.File, version uint64) error {
+	var hdr [headerSize]byte
+	binary.LittleEndian.PutUint32(hdr[0:], magic)
+	binary.LittleEndian.PutUint32(hdr[4:], formatVersion)
+	binary.LittleEndian.PutUint64(hdr[8:], version)
+	if _, err := f.Write(hdr[:]); err != nil {
+		return fmt.Errorf("failed to write database header: %v", err)
+	}
+	return nil
+}
+
+// load reads the database from f and returns size of the valid prefix of the file.
+func (db *DB) load(f *os.File) (int64, error) {
+	db.Records = make(map[string]Record)
+	r := bufio.NewReader(f)
+	var hdr [headerSize]byte
+	n, err := io.ReadFull(r, hdr[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, fmt.Errorf("failed to read database header: %v", err)
+	}
+	if lostHeader(hdr[:n]) {
+		// The header is written once when the file is created,
+		// so a torn header means that the database is empty.
+		if n != 0 {
+			log.Printf("database %v has a partially written header, starting from scratch", db.filename)
+		}
+		return 0, nil
+	}
+	if n != headerSize {
+		return 0, fmt.Errorf("failed to read database header: %v", err)
+	}
+	if binary.LittleEndian.Uint32(hdr[0:]) != magic {
+		return 0, fmt.Errorf("%v is not a database file", db.filename)
+	}
+	if v := binary.LittleEndian.Uint32(hdr[4:]); v != formatVersion {
+		return 0, fmt.Errorf("unsupported database format version %v", v)
+	}
+	db.Version = binary.LittleEndian.Uint64(hdr[8:])
+	size := int64(headerSize)
+	for {
+		op, key, rec, n, err := decodeRecord(r)
+		if err != nil {
+			// Truncated or corrupted tail (e.g. crash in the middle of write
+			// or a concurrent writer), everything before is valid.
+			break
+		}
+		size += int64(n)
+		switch op {
+		case opSave:
+			if _, ok := db.Records[key]; ok {
+				db.stale++
+			}
+			db.Records[key] = rec
+		case opDelete:
+			delete(db.Records, key)
+			db.stale += 2
+		case opVersion:
+			db.Version = rec.Seq
+			db.stale++
+		}
+	}
+	return size, nil
+}
+
+// lostHeader returns whether hdr is a header that was not completely written to disk,
+// i.e. a proper prefix of a valid header. A zeroed header is not treated as lost:
+// it can't be told apart from a damaged database with records, which must not be
+// silently overwritten.
+func lostHeader(hdr []byte) bool {
+	if len(hdr) == headerSize {
+		return false
+	}
+	var valid [8]byte
+	binary.LittleEndian.PutUint32(valid[0:], magic)
+	binary.LittleEndian.PutUint32(valid[4:], formatVersion)
+	n := len(hdr)
+	if n > len(valid) {
+		n = len(valid)
+	}
+	return bytes.Equal(hdr[:n], valid[:n])
+}
+
+// Record layout on disk:
+//
+//	uint32 payload size
+//	uint32 payload crc32
+//	payload: op byte, key, seq, time, signal, desc, val, succeeded
+//
+// Strings and byte slices are prefixed with uvarint length, val is compressed with flate.
+// succeeded is a uvarint count followed by uvarint call indices, it is optional
+// (missing in records written before it was added).
+func encodeRecord(op byte, key string, rec Record) []byte {
+	payload := new(bytes.Buffer)
+	payload.WriteByte(op)
+	writeBytes(payload, []byte(key))
+	writeUvarint(payload, rec.Seq)
+	var nsec uint64
+	if !rec.Time.IsZero() {
+		nsec = uint64(rec.Time.UnixNano())
+	}
+	writeUvarint(payload, nsec)
+	writeUvarint(payload, uint64(rec.Signal))
+	writeBytes(payload, []byte(rec.Desc))
+	compressed := new(bytes.Buffer)
+	fw, _ := flate.NewWriter(compressed, flate.BestCompression)
+	fw.Write(rec.Val)
+	fw.Close()
+	writeBytes(payload, compressed.Bytes())
+	if len(rec.Succeeded) != 0 {
+		writeUvarint(payload, uint64(len(rec.Succeeded)))
+		for _, idx := range rec.Succeeded {
+			writeUvarint(payload, uint64(idx))
+		}
+	}
+
+	buf := make([]byte, 8, 8+payload.Len())
+	binary.LittleEndian.PutUint32(buf[0:], uint32(payload.Len()))
+	binary.LittleEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(payload.Bytes()))
+	return append(buf, payload.Bytes()...)
+}
+
+func decodeRecord(r io.Reader) (op byte, key string, rec Record, n int, err error) {
+	var hdr [8]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+	size := binary.LittleEndian.Uint32(hdr[0:])
+	if size > 64<<20 {
+		err = fmt.Errorf("record is too large: %v", size)
+		return
+	}
+	payload := make([]byte, size)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(hdr[4:]) {
+		err = fmt.Errorf("bad record checksum")
+		return
+	}
+	n = len(hdr) + len(payload)
+	pr := bytes.NewReader(payload)
+	if op, err = pr.ReadByte(); err != nil {
+		return
+	}
+	var keyData, desc, val []byte
+	var nsec, signal uint64
+	if keyData, err = readBytes(pr); err != nil {
+		return
+	}
+	if rec.Seq, err = binary.ReadUvarint(pr); err != nil {
+		return
+	}
+	if nsec, err = binary.ReadUvarint(pr); err != nil {
+		return
+	}
+	if signal, err = binary.ReadUvarint(pr); err != nil {
+		return
+	}
+	if desc, err = readBytes(pr); err != nil {
+		return
+	}
+	if val, err = readBytes(pr); err != nil {
+		return
+	}
+	if rec.Val, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(val))); err != nil {
+		return
+	}
+	if pr.Len() != 0 {
+		var count, idx uint64
+		if count, err = binary.ReadUvarint(pr); err != nil {
+			return
+		}
+		if count > uint64(pr.Len()) {
+			err = fmt.Errorf("bad succeeded calls count: %v", count)
+			return
+		}
+		for i := uint64(0); i < count; i++ {
+			if idx, err = binary.ReadUvarint(pr); err != nil {
+				return
+			}
+			rec.Succeeded = append(rec.Succeeded, int(idx))
+		}
+	}
+	key = string(keyData)
+	if nsec != 0 {
+		rec.Time = time.Unix(0, int64(nsec))
+	}
+	rec.Signal = int(signal)
+	rec.Desc = string(desc)
+	return
+}
+
+func writeUvarint(w *bytes.Buffer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func writeBytes(w *bytes.Buffer, data []byte) {
+	writeUvarint(w, uint64(len(data)))
+	w.Write(data)
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("bad length %v", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tempFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "syz-db")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+	return filepath.Join(dir, "corpus.db"), func() { os.RemoveAll(dir) }
+}
+
+func TestBasic(t *testing.T) {
+	fn, cleanup := tempFile(t)
+	defer cleanup()
+	db, err := Open(fn)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if len(db.Records) != 0 {
+		t.Fatalf("new db has %v records", len(db.Records))
+	}
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("key%v", i)
+		if err := db.Save(key, Record{Val: []byte(key), Signal: i, Desc: "desc"}); err != nil {
+			t.Fatalf("failed to save: %v", err)
+		}
+	}
+	if err := db.BumpVersion(2); err != nil {
+		t.Fatalf("failed to bump version: %v", err)
+	}
+	if err := db.Save("key0", Record{Val: []byte("new"), Signal: 100, Succeeded: []int{0, 300}}); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	for i := 5; i < 10; i++ {
+		if err := db.Delete(fmt.Sprintf("key%v", i)); err != nil {
+			t.Fatalf("failed to delete: %v", err)
+		}
+	}
+	if _, err := Open(fn); err == nil {
+		t.Fatalf("opened locked db")
+	}
+	check := func(db *DB) {
+		if db.Version != 2 {
+			t.Fatalf("bad version: %v", db.Version)
+		}
+		if len(db.Records) != 5 {
+			t.Fatalf("want 5 records, got %v", len(db.Records))
+		}
+		for i := 0; i < 5; i++ {
+			key := fmt.Sprintf("key%v", i)
+			rec, ok := db.Records[key]
+			if !ok {
+				t.Fatalf("record %v is missing", key)
+			}
+			if i == 0 {
+				if string(rec.Val) != "new" || rec.Signal != 100 || rec.Seq != 2 ||
+					fmt.Sprint(rec.Succeeded) != "[0 300]" {
+					t.Fatalf("bad record %v: %+v", key, rec)
+				}
+				continue
+			}
+			if string(rec.Val) != key || rec.Signal != i || rec.Desc != "desc" || rec.Seq != 0 || rec.Time.IsZero() {
+				t.Fatalf("bad record %v: %+v", key, rec)
+			}
+		}
+	}
+	check(db)
+	ro, err := ReadRecords(fn)
+	if err != nil {
+		t.Fatalf("failed to read db: %v", err)
+	}
+	check(ro)
+	if ro.Stale() != 12 {
+		t.Fatalf("want 12 stale records, got %v", ro.Stale())
+	}
+
+	if err := db.Compact(); err != nil {
+		t.Fatalf("failed to compact: %v", err)
+	}
+	if db.Stale() != 0 {
+		t.Fatalf("%v stale records after compaction", db.Stale())
+	}
+	if err := db.Save("key5", Record{Val: []byte("key5")}); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	if err := db.Delete("key5"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err := Open(fn); err == nil {
+		t.Fatalf("opened locked db after compaction")
+	}
+	db.Close()
+	db, err = Open(fn)
+	if err != nil {
+		t.Fatalf("failed to reopen db: %v", err)
+	}
+	defer db.Close()
+	check(db)
+	if db.Stale() != 2 {
+		t.Fatalf("want 2 stale records, got %v", db.Stale())
+	}
+}
+
+func TestCorruptedTail(t *testing.T) {
+	fn, cleanup := tempFile(t)
+	defer cleanup()
+	records := map[string]Record{
+		"a": {Val: []byte("aaa")},
+		"b": {Val: bytes.Repeat([]byte("b"), 1000)},
+	}
+	if err := Create(fn, 1, records); err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatalf("failed to read db: %v", err)
+	}
+	// Append a partially written record.
+	rec := encodeRecord(opSave, "c", Record{Val: []byte("ccc")})
+	if err := ioutil.WriteFile(fn, append(data, rec[:len(rec)-3]...), 0640); err != nil {
+		t.Fatalf("failed to write db: %v", err)
+	}
+	db, err := Open(fn)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if db.Version != 1 || len(db.Records) != 2 || !bytes.Equal(db.Records["b"].Val, records["b"].Val) {
+		t.Fatalf("bad db contents: version %v, %v records", db.Version, len(db.Records))
+	}
+	if err := db.Save("c", Record{Val: []byte("ccc")}); err != nil {
+		t.Fatalf("failed to save: %v", err)
+	}
+	db.Close()
+	db, err = Open(fn)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	if len(db.Records) != 3 || string(db.Records["c"].Val) != "ccc" {
+		t.Fatalf("bad db contents after recovery: %+v", db.Records)
+	}
+}
+
+func TestBadFile(t *testing.T) {
+	fn, cleanup := tempFile(t)
+	defer cleanup()
+	if err := ioutil.WriteFile(fn, []byte("not a database file"), 0640); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := Open(fn); err == nil {
+		t.Fatalf("opened bad file")
+	}
+}
+
+func TestTornHeader(t *testing.T) {
+	fn, cleanup := tempFile(t)
+	defer cleanup()
+	db, err := Open(fn)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	db.Close()
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatalf("failed to read db: %v", err)
+	}
+	// Crash while the new database header was being written out.
+	for _, hdr := range [][]byte{data[:5], data[:headerSize-1]} {
+		if err := ioutil.WriteFile(fn, hdr, 0640); err != nil {
+			t.Fatalf("failed to write db: %v", err)
+		}
+		db, err := Open(fn)
+		if err != nil {
+			t.Fatalf("failed to open db with header %x: %v", hdr, err)
+		}
+		if err := db.Save("a", Record{Val: []byte("aaa")}); err != nil {
+			t.Fatalf("failed to save: %v", err)
+		}
+		db.Close()
+		db, err = Open(fn)
+		if err != nil {
+			t.Fatalf("failed to reopen db: %v", err)
+		}
+		if len(db.Records) != 1 || string(db.Records["a"].Val) != "aaa" {
+			t.Fatalf("bad db contents after recovery: %+v", db.Records)
+		}
+		db.Close()
+	}
+	// Zeroed header (e.g. the file was extended but its data did not hit the disk)
+	// may hide records, so it's an error rather than an empty database.
+	for _, hdr := range [][]byte{make([]byte, headerSize), make([]byte, 100)} {
+		if err := ioutil.WriteFile(fn, hdr, 0640); err != nil {
+			t.Fatalf("failed to write db: %v", err)
+		}
+		if db, err := Open(fn); err == nil {
+			db.Close()
+			t.Fatalf("opened db with zeroed header %x", hdr)
+		}
+	}
+}
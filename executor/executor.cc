@@ -9,6 +9,7 @@
 #include <limits.h>
 #include <linux/capability.h>
 #include <linux/futex.h>
+#include <linux/loop.h>
 #include <linux/reboot.h>
 #include <pthread.h>
 #include <signal.h>
@@ -24,6 +25,7 @@
 #include <sys/prctl.h>
 #include <sys/reboot.h>
 #include <sys/resource.h>
+#include <sys/socket.h>
 #include <sys/stat.h>
 #include <sys/syscall.h>
 #include <sys/time.h>
@@ -33,10 +35,15 @@
 #include <unistd.h>
 
 #include "syscalls.h"
+#include "kvm.h"
+#include "usb.h"
+#include "tun.h"
 
 #define KCOV_INIT_TRACE _IOR('c', 1, unsigned long long)
 #define KCOV_INIT_TABLE _IOR('c', 2, unsigned long long)
 #define KCOV_ENABLE _IO('c', 100)
+#define KCOV_TRACE_PC 0
+#define KCOV_TRACE_CMP 1
 #define KCOV_DISABLE _IO('c', 101)
 
 const int kInFd = 3;
@@ -49,6 +56,9 @@ const int kMaxArgs = 9;
 const int kMaxThreads = 16;
 const int kMaxCommands = 4 << 10;
 const int kCoverSize = 16 << 10;
+const int kMaxRelayFds = 64;
+const uint64_t kDefaultProgramTimeout = 5 * 1000; // ms
+const uint64_t kKillTimeout = 5 * 1000; // ms, a test process that did not die after SIGKILL is a hang
 
 const uint64_t instr_eof = -1;
 const uint64_t instr_copyin = -2;
@@ -80,6 +90,14 @@ bool flag_collide;
 bool flag_deduplicate;
 bool flag_sandbox_privs;
 sandbox_type flag_sandbox;
+bool flag_dangerous;
+bool flag_enable_tun;
+bool flag_collect_comps; // per-program: collect comparison operands instead of coverage
+bool flag_inject_fault; // per-program: fail flag_fault_nth fault site in call flag_fault_call
+bool flag_no_collide; // per-program: don't re-execute the program in collide mode
+int flag_fault_call;
+int flag_fault_nth;
+uint64_t flag_program_timeout; // per-program: kill the test process after that many ms
 
 __attribute__((aligned(64 << 10))) char input_data[kMaxInput];
 __attribute__((aligned(64 << 10))) char output_data[kMaxOutput];
@@ -115,9 +133,17 @@ struct thread_t {
 	uint64_t reserrno;
 	uint64_t cover_size;
 	int cover_fd;
+	bool fault_injected;
 };
 
 thread_t threads[kMaxThreads];
+
+// Layout of fs_image_segment from sys/fsimage.txt.
+struct fs_image_segment {
+	void* data;
+	uintptr_t size;
+	uintptr_t offset;
+};
 char sandbox_stack[1 << 20];
 
 __attribute__((noreturn)) void fail(const char* msg, ...);
@@ -129,6 +155,9 @@ int do_sandbox_none();
 int do_sandbox_setuid();
 int do_sandbox_namespace();
 void sandbox_common();
+void drop_capabilities(uint64_t caps);
+int inject_fault(int nth);
+bool fault_injected(int fail_fd);
 void loop();
 void execute_one();
 uint64_t read_input(uint64_t** input_posp, bool peek = false);
@@ -139,6 +168,10 @@ void copyin(char* addr, uint64_t val, uint64_t size);
 uint64_t copyout(char* addr, uint64_t size);
 thread_t* schedule_call(int n, int call_index, int call_num, uint64_t num_args, uint64_t* args, uint64_t* pos);
 void execute_call(thread_t* th);
+bool dangerous_call(int sys_nr);
+int unix_relay(int fd, uint64_t mode);
+int mount_image(const char* fs, const char* dir, uint64_t size, uint64_t nsegs, fs_image_segment* segs, uint64_t flags);
+int attach_loop(int fd, char* loopname);
 void handle_completion(thread_t* th);
 void thread_create(thread_t* th, int id);
 void* worker_thread(void* arg);
@@ -181,6 +214,8 @@ int main(int argc, char** argv)
 		flag_sandbox = sandbox_setuid;
 	else if (flags & (1 << 6))
 		flag_sandbox = sandbox_namespace;
+	flag_dangerous = flags & (1 << 7);
+	flag_enable_tun = flags & (1 << 8);
 	if (!flag_threaded)
 		flag_collide = false;
 
@@ -241,8 +276,18 @@ void loop()
 		if (mkdir(cwdbuf, 0777))
 			fail("failed to mkdir");
 
-		if (read(kInPipeFd, &tmp, 1) != 1)
+		// The request is a mask of capabilities to drop for this program,
+		// per-program execution flags, fault injection parameters and the program timeout.
+		uint64_t req[5] = {};
+		if (read(kInPipeFd, &req, sizeof(req)) != sizeof(req))
 			fail("control pipe read failed");
+		uint64_t drop_caps = req[0];
+		flag_collect_comps = flag_cover && (req[1] & (1 << 0));
+		flag_inject_fault = req[1] & (1 << 1);
+		flag_no_collide = req[1] & (1 << 2);
+		flag_fault_call = req[2];
+		flag_fault_nth = req[3];
+		flag_program_timeout = req[4] ? req[4] : kDefaultProgramTimeout;
 
 		int pid = fork();
 		if (pid < 0)
@@ -254,6 +299,9 @@ void loop()
 				fail("failed to chdir");
 			close(kInPipeFd);
 			close(kOutPipeFd);
+			flush_tun();
+			if (drop_caps)
+				drop_capabilities(drop_caps);
 			execute_one();
 			debug("worker exiting\n");
 			exit(0);
@@ -267,6 +315,8 @@ void loop()
 		// should be as efficient as sigtimedwait.
 		int status = 0;
 		uint64_t start = current_time_ms();
+		uint64_t killed = 0;
+		bool hanged = false;
 		for (;;) {
 			int res = waitpid(pid, &status, __WALL | WNOHANG);
 			int errno0 = errno;
@@ -275,25 +325,33 @@ void loop()
 				break;
 			}
 			usleep(1000);
-			if (current_time_ms() - start > 5 * 1000) {
+			if (!killed && current_time_ms() - start > flag_program_timeout) {
 				debug("waitpid(%d)=%d (%d)\n", pid, res, errno0);
 				debug("killing\n");
 				kill(-pid, SIGKILL);
 				kill(pid, SIGKILL);
-				int res = waitpid(pid, &status, __WALL);
-				debug("waitpid(%d)=%d (%d)\n", pid, res, errno);
-				if (res != pid)
-					fail("waitpid failed");
+				killed = current_time_ms();
+			}
+			if (killed && current_time_ms() - killed > kKillTimeout) {
+				// The test process is stuck in kernel and can't be killed (e.g. waits on
+				// a lock that is never released). Abandon it and continue with the next program,
+				// the parent reports the program as a hang.
+				debug("worker pid %d does not die\n", pid);
+				hanged = true;
 				break;
 			}
 		}
-		status = WEXITSTATUS(status);
-		if (status == kFailStatus)
-			fail("child failed");
-		if (status == kErrorStatus)
-			error("child errored");
-		remove_dir(cwdbuf);
-		if (write(kOutPipeFd, &tmp, 1) != 1)
+		if (!hanged) {
+			status = WEXITSTATUS(status);
+			if (status == kFailStatus)
+				fail("child failed");
+			if (status == kErrorStatus)
+				error("child errored");
+			remove_dir(cwdbuf);
+		}
+		// The reply is 1 if the program hanged.
+		char reply = hanged;
+		if (write(kOutPipeFd, &reply, 1) != 1)
 			fail("control pipe write failed");
 	}
 }
@@ -303,6 +361,8 @@ int do_sandbox_none()
 	int pid = fork();
 	if (pid)
 		return pid;
+	if (flag_enable_tun)
+		setup_tun();
 	loop();
 	exit(1);
 }
@@ -314,6 +374,9 @@ int do_sandbox_setuid()
 		return pid;
 
 	sandbox_common();
+	// The interface needs to be set up before we drop privileges.
+	if (flag_enable_tun)
+		setup_tun();
 
 	const int nobody = 65534;
 	if (setgroups(0, NULL))
@@ -359,6 +422,33 @@ void sandbox_common()
 	unshare(CLONE_IO);
 }
 
+// drop_capabilities drops caps from the bounding, effective, permitted and inheritable sets,
+// so that programs exercise EPERM paths of permission checks.
+void drop_capabilities(uint64_t caps)
+{
+	// The bounding set goes first because dropping from it requires CAP_SETPCAP.
+	// Errors are ignored: the cap may be unknown to the kernel or already dropped.
+	for (int cap = 0; cap < 64; cap++) {
+		if (caps & (1ull << cap))
+			prctl(PR_CAPBSET_DROP, cap, 0, 0, 0);
+	}
+	__user_cap_header_struct cap_hdr = {};
+	__user_cap_data_struct cap_data[2] = {};
+	cap_hdr.version = _LINUX_CAPABILITY_VERSION_3;
+	cap_hdr.pid = getpid();
+	if (syscall(SYS_capget, &cap_hdr, &cap_data))
+		fail("capget failed");
+	for (int i = 0; i < 2; i++) {
+		uint32_t mask = ~(uint32_t)(caps >> (32 * i));
+		cap_data[i].effective &= mask;
+		cap_data[i].permitted &= mask;
+		cap_data[i].inheritable &= mask;
+	}
+	if (syscall(SYS_capset, &cap_hdr, &cap_data))
+		fail("capset failed");
+	debug("dropped capabilities 0x%llx\n", (unsigned long long)caps);
+}
+
 int sandbox_proc(void* arg)
 {
 	sandbox_common();
@@ -370,6 +460,10 @@ int sandbox_proc(void* arg)
 	if (!write_file("/proc/self/gid_map", "0 %d 1\n", real_gid))
 		fail("write of /proc/self/gid_map failed");
 
+	// Each test process gets its own network namespace, so the interface is not shared.
+	if (flag_enable_tun)
+		setup_tun();
+
 	if (mkdir("./syz-tmp", 0777))
 		fail("mkdir(syz-tmp) failed");
 	if (mount("", "./syz-tmp", "tmpfs", 0, NULL))
@@ -524,7 +618,8 @@ retry:
 		}
 	}
 
-	if (flag_collide && !collide) {
+	// Collider duplicates calls, so the fault would be injected in a random one of them.
+	if (flag_collide && !collide && !flag_inject_fault && !flag_no_collide) {
 		debug("enabling collider\n");
 		collide = true;
 		goto retry;
@@ -598,11 +693,24 @@ void handle_completion(thread_t* th)
 		write_output(th->call_index);
 		write_output(th->call_num);
 		write_output(th->res != (uint64_t)-1 ? 0 : th->reserrno);
+		write_output(th->fault_injected);
 		write_output(th->cover_size);
-		// Truncate PCs to uint32_t assuming that they fit into 32-bits.
-		// True for x86_64 and arm64 without KASLR.
-		for (uint64_t i = 0; i < th->cover_size; i++)
-			write_output((uint32_t)th->cover_data[i + 1]);
+		if (flag_collect_comps) {
+			// Comparison records are {type, arg1, arg2, pc}, pc is not needed.
+			for (uint64_t i = 0; i < th->cover_size; i++) {
+				uint64_t* comp = &th->cover_data[1 + i * 4];
+				write_output((uint32_t)comp[0]);
+				write_output((uint32_t)comp[1]);
+				write_output((uint32_t)(comp[1] >> 32));
+				write_output((uint32_t)comp[2]);
+				write_output((uint32_t)(comp[2] >> 32));
+			}
+		} else {
+			// Truncate PCs to uint32_t assuming that they fit into 32-bits.
+			// True for x86_64 and arm64 without KASLR.
+			for (uint64_t i = 0; i < th->cover_size; i++)
+				write_output((uint32_t)th->cover_data[i + 1]);
+		}
 		completed++;
 		__atomic_store_n((uint32_t*)&output_data[0], completed, __ATOMIC_RELEASE);
 	}
@@ -651,11 +759,25 @@ void execute_call(thread_t* th)
 	}
 	debug(")\n");
 
+	int fail_fd = -1;
+	if (flag_inject_fault && th->call_index == flag_fault_call) {
+		if (collide)
+			fail("both collide and fault injection are enabled");
+		debug("injecting fault into %d-th operation\n", flag_fault_nth);
+		fail_fd = inject_fault(flag_fault_nth);
+	}
+
 	cover_reset(th);
 	switch (call->sys_nr) {
 	default: {
 		if (th->num_args > 6)
 			fail("bad number of arguments");
+		if (!flag_dangerous && dangerous_call(call->sys_nr)) {
+			debug("#%d: %s is blocked as dangerous\n", th->id, call->name);
+			th->res = -1;
+			errno = EPERM;
+			break;
+		}
 		th->res = syscall(call->sys_nr, th->args[0], th->args[1], th->args[2], th->args[3], th->args[4], th->args[5]);
 		break;
 	}
@@ -690,7 +812,9 @@ void execute_call(thread_t* th)
 	}
 	case __NR_syz_open_pts: {
 		// syz_openpts(fd fd[tty], flags flags[open_flags]) fd[tty]
-		int ptyno = 0;
+		// The slave is locked after open of /dev/ptmx, unlock it (unlockpt) so that open succeeds.
+		int ptyno = 0, unlock = 0;
+		ioctl(th->args[0], TIOCSPTLCK, &unlock);
 		if (ioctl(th->args[0], TIOCGPTN, &ptyno) == 0) {
 			char buf[128];
 			sprintf(buf, "/dev/pts/%d", ptyno);
@@ -702,6 +826,12 @@ void execute_call(thread_t* th)
 	}
 	case __NR_syz_fuse_mount: {
 		// syz_fuse_mount(target filename, mode flags[fuse_mode], uid uid, gid gid, maxread intptr, flags flags[mount_flags]) fd[fuse]
+		if (!flag_dangerous && dangerous_call(SYS_mount)) {
+			debug("#%d: %s is blocked as dangerous\n", th->id, call->name);
+			th->res = -1;
+			errno = EPERM;
+			break;
+		}
 		uint64_t target = th->args[0];
 		uint64_t mode = th->args[1];
 		uint64_t uid = th->args[2];
@@ -727,6 +857,12 @@ void execute_call(thread_t* th)
 	}
 	case __NR_syz_fuseblk_mount: {
 		// syz_fuseblk_mount(target filename, blkdev filename, mode flags[fuse_mode], uid uid, gid gid, maxread intptr, blksize intptr, flags flags[mount_flags]) fd[fuse]
+		if (!flag_dangerous && dangerous_call(SYS_mount)) {
+			debug("#%d: %s is blocked as dangerous\n", th->id, call->name);
+			th->res = -1;
+			errno = EPERM;
+			break;
+		}
 		uint64_t target = th->args[0];
 		uint64_t blkdev = th->args[1];
 		uint64_t mode = th->args[2];
@@ -756,9 +892,59 @@ void execute_call(thread_t* th)
 		th->res = fd;
 		break;
 	}
+	case __NR_syz_unix_relay: {
+		// syz_unix_relay(fd fd[unix], mode flags[unix_relay_mode]) pid
+		th->res = unix_relay(th->args[0], th->args[1]);
+		break;
+	}
+	case __NR_syz_mount_image: {
+		// syz_mount_image(fs strconst, dir filename, size intptr, nsegs len[segs], segs ptr[in, array[fs_image_segment]], flags flags[mount_flags])
+		if (!flag_dangerous && dangerous_call(SYS_mount)) {
+			debug("#%d: %s is blocked as dangerous\n", th->id, call->name);
+			th->res = -1;
+			errno = EPERM;
+			break;
+		}
+		th->res = mount_image((const char*)th->args[0], (const char*)th->args[1], th->args[2], th->args[3], (fs_image_segment*)th->args[4], th->args[5]);
+		break;
+	}
+	case __NR_syz_usb_connect: {
+		// syz_usb_connect(dev ptr[in, usb_device_descriptor], conf ptr[in, usb_config_descriptor], conflen len[conf]) fd[usb]
+		if (!flag_dangerous && dangerous_call(SYS_mount)) {
+			debug("#%d: %s is blocked as dangerous\n", th->id, call->name);
+			th->res = -1;
+			errno = EPERM;
+			break;
+		}
+		th->res = usb_connect((const char*)th->args[0], (const char*)th->args[1], th->args[2]);
+		break;
+	}
+	case __NR_syz_usb_control_io: {
+		// syz_usb_control_io(fd fd[usb], resp ptr[in, usb_control_response], len bytesize[resp])
+		th->res = usb_control_io(th->args[0], (const char*)th->args[1], th->args[2]);
+		break;
+	}
+	case __NR_syz_emit_ethernet: {
+		// syz_emit_ethernet(len len[packet], packet ptr[in, eth_packet])
+		th->res = emit_ethernet(th->args[0], (const char*)th->args[1]);
+		break;
+	}
+	case __NR_syz_extract_tcp_res: {
+		// syz_extract_tcp_res(res ptr[out, tcp_resources], seq_inc int32, ack_inc int32)
+		th->res = extract_tcp_res((uint32_t*)th->args[0], th->args[1], th->args[2]);
+		break;
+	}
+	case __NR_syz_kvm_setup_cpu: {
+		// syz_kvm_setup_cpu(fd fd[kvmvm], cpufd fd[kvmcpu], mode flags[kvm_guest_mode], text ptr[in, array[kvm_guest_insn]], ntext bytesize[text], flags flags[kvm_setup_flags])
+		th->res = kvm_setup_cpu(th->args[0], th->args[1], th->args[2], (const char*)th->args[3], th->args[4], th->args[5]);
+		break;
+	}
 	}
 	th->reserrno = errno;
 	th->cover_size = cover_read(th);
+	th->fault_injected = false;
+	if (fail_fd != -1)
+		th->fault_injected = fault_injected(fail_fd);
 
 	if (th->res == (uint64_t)-1)
 		debug("#%d: %s = errno(%d)\n", th->id, call->name, th->reserrno);
@@ -768,6 +954,229 @@ void execute_call(thread_t* th)
 	syscall(SYS_futex, &th->done, FUTEX_WAKE);
 }
 
+// inject_fault arms fault injection (CONFIG_FAULT_INJECTION) for the nth (0-based)
+// fault site the current thread hits and returns fd of /proc/thread-self/fail-nth.
+// Which fault sites are considered is configured in /sys/kernel/debug/fail*.
+int inject_fault(int nth)
+{
+	int fd = open("/proc/thread-self/fail-nth", O_RDWR);
+	if (fd == -1)
+		fail("failed to open /proc/thread-self/fail-nth");
+	char buf[16];
+	sprintf(buf, "%d", nth + 1);
+	if (write(fd, buf, strlen(buf)) != (ssize_t)strlen(buf))
+		fail("failed to write /proc/thread-self/fail-nth");
+	return fd;
+}
+
+// fault_injected returns true if the fault armed by inject_fault was injected
+// (the counter reached 0) and disarms it.
+bool fault_injected(int fail_fd)
+{
+	char buf[16];
+	int n = pread(fail_fd, buf, sizeof(buf) - 1, 0);
+	if (n <= 0)
+		fail("failed to read /proc/thread-self/fail-nth");
+	bool res = n == 2 && buf[0] == '0' && buf[1] == '\n';
+	buf[0] = '0';
+	if (pwrite(fail_fd, buf, 1, 0) != 1)
+		fail("failed to write /proc/thread-self/fail-nth");
+	close(fail_fd);
+	return res;
+}
+
+// dangerous_call returns true for syscalls that can destroy the test environment
+// regardless of arguments (reboot the machine, replace the kernel, load/unload modules,
+// break the clock used by ssh timeouts, etc). These are blocked unless FlagDangerous is set.
+// Mount operations are dangerous only outside of the namespace sandbox
+// (with the namespace sandbox they happen in a private mount namespace).
+bool dangerous_call(int sys_nr)
+{
+	switch (sys_nr) {
+	case SYS_reboot:
+	case SYS_kexec_load:
+#ifdef SYS_kexec_file_load
+	case SYS_kexec_file_load:
+#endif
+	case SYS_init_module:
+#ifdef SYS_finit_module
+	case SYS_finit_module:
+#endif
+	case SYS_delete_module:
+	case SYS_swapoff:
+	case SYS_settimeofday:
+	case SYS_clock_settime:
+	case SYS_adjtimex:
+#ifdef SYS_iopl
+	case SYS_iopl:
+#endif
+#ifdef SYS_ioperm
+	case SYS_ioperm:
+#endif
+		return true;
+	case SYS_mount:
+	case SYS_umount2:
+	case SYS_pivot_root:
+		return flag_sandbox != sandbox_namespace;
+	}
+	return false;
+}
+
+const uint64_t kMaxImageSize = 128 << 20;
+
+// mount_image creates a sparse image file of the given size in memory (memfd),
+// writes the segments into it, attaches it to a free loop device and mounts the device on dir.
+// The loop device is detached automatically when the filesystem is unmounted
+// (that happens in remove_dir at the latest).
+int mount_image(const char* fs, const char* dir, uint64_t size, uint64_t nsegs, fs_image_segment* segs, uint64_t flags)
+{
+	if (size > kMaxImageSize)
+		size = kMaxImageSize;
+	int memfd = syscall(__NR_memfd_create, "syzkaller", 0);
+	if (memfd == -1)
+		return -1;
+	int res = -1;
+	if (ftruncate(memfd, size) == 0) {
+		for (uint64_t i = 0; i < nsegs; i++) {
+			uint64_t offset = segs[i].offset;
+			uint64_t n = segs[i].size;
+			if (offset >= size)
+				continue;
+			if (n > size - offset)
+				n = size - offset;
+			// Ignore errors, data can point to unmapped memory.
+			pwrite(memfd, segs[i].data, n, offset);
+		}
+		char loopname[64];
+		int loopfd = attach_loop(memfd, loopname);
+		if (loopfd != -1) {
+			mkdir(dir, 0777);
+			debug("mount(\"%s\", \"%s\", \"%s\", 0x%lx)\n", loopname, dir, fs, flags);
+			res = syscall(SYS_mount, loopname, dir, fs, flags, NULL);
+			int err = errno;
+			if (res == -1)
+				ioctl(loopfd, LOOP_CLR_FD, 0);
+			close(loopfd);
+			errno = err;
+		}
+	}
+	int err = errno;
+	close(memfd);
+	errno = err;
+	return res;
+}
+
+// attach_loop attaches fd to a free loop device and returns fd of the loop device.
+// The device is configured to be detached on last close/unmount.
+int attach_loop(int fd, char* loopname)
+{
+	for (int i = 0;; i++) {
+		int ctl = open("/dev/loop-control", O_RDWR);
+		if (ctl == -1)
+			return -1;
+		int loopno = ioctl(ctl, LOOP_CTL_GET_FREE);
+		close(ctl);
+		if (loopno < 0)
+			return -1;
+		sprintf(loopname, "/dev/loop%d", loopno);
+		int loopfd = open(loopname, O_RDWR);
+		if (loopfd == -1)
+			return -1;
+		if (ioctl(loopfd, LOOP_SET_FD, fd) == 0) {
+			struct loop_info64 info;
+			memset(&info, 0, sizeof(info));
+			info.lo_flags = LO_FLAGS_AUTOCLEAR;
+			ioctl(loopfd, LOOP_SET_STATUS64, &info);
+			return loopfd;
+		}
+		// Another process has grabbed the same device in between, retry.
+		int err = errno;
+		close(loopfd);
+		if (err != EBUSY || i > 100) {
+			errno = err;
+			return -1;
+		}
+	}
+}
+
+// Modes of syz_unix_relay.
+const uint64_t unix_relay_bounce = 1; // send the received fds back over the same socket
+const uint64_t unix_relay_hold = 2; // don't close the received fds until the relay dies
+const uint64_t unix_relay_cred = 4; // receive and send SCM_CREDENTIALS
+
+// unix_relay forks a process that receives messages with SCM_RIGHTS over fd
+// and closes, holds or bounces the received fds depending on mode.
+// The relay exits when all other ends of fd are closed, or it's killed together
+// with the test process group. Returns pid of the relay.
+int unix_relay(int fd, uint64_t mode)
+{
+	if (mode & unix_relay_cred) {
+		int one = 1;
+		setsockopt(fd, SOL_SOCKET, SO_PASSCRED, &one, sizeof(one));
+	}
+	int pid = fork();
+	if (pid != 0)
+		return pid;
+	prctl(PR_SET_PDEATHSIG, SIGKILL, 0, 0, 0);
+	char data[128];
+	char ctrl[CMSG_SPACE(kMaxRelayFds * sizeof(int)) + CMSG_SPACE(sizeof(struct ucred))];
+	for (;;) {
+		struct iovec iov = {data, sizeof(data)};
+		struct msghdr msg = {};
+		msg.msg_iov = &iov;
+		msg.msg_iovlen = 1;
+		msg.msg_control = ctrl;
+		msg.msg_controllen = sizeof(ctrl);
+		ssize_t n = recvmsg(fd, &msg, 0);
+		if (n < 0 && errno == EINTR)
+			continue;
+		if (n < 0 || (n == 0 && msg.msg_controllen == 0))
+			_exit(0);
+		int fds[kMaxRelayFds];
+		int nfds = 0;
+		for (struct cmsghdr* cmsg = CMSG_FIRSTHDR(&msg); cmsg; cmsg = CMSG_NXTHDR(&msg, cmsg)) {
+			if (cmsg->cmsg_level != SOL_SOCKET || cmsg->cmsg_type != SCM_RIGHTS)
+				continue;
+			int* rfds = (int*)CMSG_DATA(cmsg);
+			for (size_t i = 0; i < (cmsg->cmsg_len - CMSG_LEN(0)) / sizeof(int); i++) {
+				if (nfds < kMaxRelayFds)
+					fds[nfds++] = rfds[i];
+				else
+					close(rfds[i]);
+			}
+		}
+		if (mode & unix_relay_bounce) {
+			memset(ctrl, 0, sizeof(ctrl));
+			msg.msg_controllen = 0;
+			iov.iov_len = n > 0 ? n : 1;
+			struct cmsghdr* cmsg = (struct cmsghdr*)ctrl;
+			if (nfds != 0) {
+				cmsg->cmsg_level = SOL_SOCKET;
+				cmsg->cmsg_type = SCM_RIGHTS;
+				cmsg->cmsg_len = CMSG_LEN(nfds * sizeof(int));
+				memcpy(CMSG_DATA(cmsg), fds, nfds * sizeof(int));
+				msg.msg_controllen += CMSG_SPACE(nfds * sizeof(int));
+				cmsg = (struct cmsghdr*)(ctrl + msg.msg_controllen);
+			}
+			if (mode & unix_relay_cred) {
+				struct ucred cred = {getpid(), getuid(), getgid()};
+				cmsg->cmsg_level = SOL_SOCKET;
+				cmsg->cmsg_type = SCM_CREDENTIALS;
+				cmsg->cmsg_len = CMSG_LEN(sizeof(cred));
+				memcpy(CMSG_DATA(cmsg), &cred, sizeof(cred));
+				msg.msg_controllen += CMSG_SPACE(sizeof(cred));
+			}
+			if (msg.msg_controllen == 0)
+				msg.msg_control = 0;
+			sendmsg(fd, &msg, MSG_DONTWAIT);
+		}
+		if (!(mode & unix_relay_hold)) {
+			for (int i = 0; i < nfds; i++)
+				close(fds[i]);
+		}
+	}
+}
+
 void cover_open()
 {
 	if (!flag_cover)
@@ -790,8 +1199,13 @@ void cover_enable(thread_t* th)
 	if (!flag_cover)
 		return;
 	debug("#%d: enabling /sys/kernel/debug/kcov\n", th->id);
-	if (ioctl(th->cover_fd, KCOV_ENABLE, 0))
-		fail("cover enable write failed");
+	if (flag_collect_comps) {
+		if (ioctl(th->cover_fd, KCOV_ENABLE, KCOV_TRACE_CMP))
+			fail("cover enable write failed (comparisons require CONFIG_KCOV_ENABLE_COMPARISONS)");
+	} else {
+		if (ioctl(th->cover_fd, KCOV_ENABLE, KCOV_TRACE_PC))
+			fail("cover enable write failed");
+	}
 	debug("#%d: enabled /sys/kernel/debug/kcov\n", th->id);
 }
 
@@ -808,6 +1222,12 @@ uint64_t cover_read(thread_t* th)
 		return 0;
 	uint64_t n = __atomic_load_n(&th->cover_data[0], __ATOMIC_RELAXED);
 	debug("#%d: read cover = %d\n", th->id, n);
+	if (flag_collect_comps) {
+		// Each comparison takes 4 words.
+		if (n * 4 >= kCoverSize)
+			fail("#%d: too many comparisons %d", th->id, n);
+		return n;
+	}
 	if (n >= kCoverSize)
 		fail("#%d: too much cover %d", th->id, n);
 	if (flag_deduplicate) {
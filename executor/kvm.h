@@ -0,0 +1,136 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Guest setup for syz_kvm_setup_cpu: creates guest memory with page tables, GDT and guest code,
+// and switches the vcpu into the requested mode, so that KVM_RUN actually runs the code.
+
+#include <linux/kvm.h>
+
+// Layout of guest physical memory.
+const uint64_t kKvmGuestMemSize = 16 << 12;
+const uint64_t kKvmAddrGdt = 0x800; // below is real mode IVT
+const uint64_t kKvmAddrPageTables = 0x1000; // PML4/PDPT/PD for long mode, PD for 32-bit paging
+const uint64_t kKvmAddrText = 0x4000;
+const uint64_t kKvmMaxText = 0x4000 - 1; // leave space for the trailing hlt
+const uint64_t kKvmAddrStack = 0xf000;
+
+// Modes and flags of syz_kvm_setup_cpu.
+const uint64_t kvm_mode_real = 16;
+const uint64_t kvm_mode_protected = 32;
+const uint64_t kvm_setup_paging = 1; // enable paging in protected mode (always enabled in long mode)
+const uint64_t kvm_setup_vm86 = 2; // run protected mode code in virtual-8086 mode
+
+// GDT selectors.
+const uint16_t kKvmSelCode32 = 0x8;
+const uint16_t kKvmSelData = 0x10;
+const uint16_t kKvmSelCode64 = 0x18;
+
+const uint64_t kX86CR0PE = 1ull << 0;
+const uint64_t kX86CR0PG = 1ull << 31;
+const uint64_t kX86CR4PSE = 1ull << 4;
+const uint64_t kX86CR4PAE = 1ull << 5;
+const uint64_t kX86EferLME = 1ull << 8;
+const uint64_t kX86EferLMA = 1ull << 10;
+const uint64_t kX86PteP = 1ull << 0;
+const uint64_t kX86PteRW = 1ull << 1;
+const uint64_t kX86PtePS = 1ull << 7;
+const uint64_t kX86EflagsVM = 1ull << 17;
+
+#if defined(__x86_64__)
+void kvm_set_segment(kvm_segment* seg, uint16_t selector, uint8_t type, bool code64)
+{
+	memset(seg, 0, sizeof(*seg));
+	seg->base = 0;
+	seg->limit = 0xffffffff;
+	seg->selector = selector;
+	seg->type = type;
+	seg->present = 1;
+	seg->s = 1;
+	seg->g = 1;
+	seg->l = code64;
+	seg->db = !code64;
+}
+
+// kvm_setup_cpu sets up guest memory for vm vmfd and registers of vcpu cpufd
+// to run text in the given mode (16, 32 or 64).
+int kvm_setup_cpu(int vmfd, int cpufd, uint64_t mode, const char* text, uint64_t ntext, uint64_t flags)
+{
+	char* mem = (char*)mmap(NULL, kKvmGuestMemSize, PROT_READ | PROT_WRITE, MAP_PRIVATE | MAP_ANONYMOUS, -1, 0);
+	if (mem == MAP_FAILED)
+		return -1;
+	kvm_userspace_memory_region region;
+	memset(&region, 0, sizeof(region));
+	region.slot = 0;
+	region.guest_phys_addr = 0;
+	region.memory_size = kKvmGuestMemSize;
+	region.userspace_addr = (uint64_t)mem;
+	// The program could have set up its own memory, so ignore errors.
+	ioctl(vmfd, KVM_SET_USER_MEMORY_REGION, &region);
+
+	uint64_t* gdt = (uint64_t*)(mem + kKvmAddrGdt);
+	gdt[kKvmSelCode32 / 8] = 0x00cf9b000000ffffull;
+	gdt[kKvmSelData / 8] = 0x00cf93000000ffffull;
+	gdt[kKvmSelCode64 / 8] = 0x00af9b000000ffffull;
+	if (ntext > kKvmMaxText)
+		ntext = kKvmMaxText;
+	memcpy(mem + kKvmAddrText, text, ntext);
+	mem[kKvmAddrText + ntext] = 0xf4; // hlt
+
+	kvm_sregs sregs;
+	if (ioctl(cpufd, KVM_GET_SREGS, &sregs))
+		return -1;
+	kvm_regs regs;
+	memset(&regs, 0, sizeof(regs));
+	regs.rip = kKvmAddrText;
+	regs.rsp = kKvmAddrStack;
+	regs.rflags = 1 << 1; // reserved, always set
+	sregs.gdt.base = kKvmAddrGdt;
+	sregs.gdt.limit = kKvmSelCode64 + 7;
+	if (mode == kvm_mode_real) {
+		sregs.cr0 &= ~(kX86CR0PE | kX86CR0PG);
+		sregs.cs.base = 0;
+		sregs.cs.selector = 0;
+	} else if (mode == kvm_mode_protected) {
+		kvm_set_segment(&sregs.cs, kKvmSelCode32, 11, false);
+		kvm_set_segment(&sregs.ds, kKvmSelData, 3, false);
+		sregs.es = sregs.fs = sregs.gs = sregs.ss = sregs.ds;
+		sregs.cr0 |= kX86CR0PE;
+		if (flags & kvm_setup_paging) {
+			// Identity map the first 4MB with a large page.
+			uint32_t* pd = (uint32_t*)(mem + kKvmAddrPageTables);
+			pd[0] = kX86PteP | kX86PteRW | kX86PtePS;
+			sregs.cr3 = kKvmAddrPageTables;
+			sregs.cr4 |= kX86CR4PSE;
+			sregs.cr0 |= kX86CR0PG;
+		}
+		if (flags & kvm_setup_vm86)
+			regs.rflags |= kX86EflagsVM;
+	} else {
+		// Identity map the first 2MB with a large page.
+		uint64_t* pml4 = (uint64_t*)(mem + kKvmAddrPageTables);
+		uint64_t* pdpt = (uint64_t*)(mem + kKvmAddrPageTables + 0x1000);
+		uint64_t* pd = (uint64_t*)(mem + kKvmAddrPageTables + 0x2000);
+		pml4[0] = kX86PteP | kX86PteRW | (kKvmAddrPageTables + 0x1000);
+		pdpt[0] = kX86PteP | kX86PteRW | (kKvmAddrPageTables + 0x2000);
+		pd[0] = kX86PteP | kX86PteRW | kX86PtePS;
+		kvm_set_segment(&sregs.cs, kKvmSelCode64, 11, true);
+		kvm_set_segment(&sregs.ds, kKvmSelData, 3, false);
+		sregs.es = sregs.fs = sregs.gs = sregs.ss = sregs.ds;
+		sregs.cr3 = kKvmAddrPageTables;
+		sregs.cr4 |= kX86CR4PAE;
+		sregs.cr0 |= kX86CR0PE | kX86CR0PG;
+		sregs.efer |= kX86EferLME | kX86EferLMA;
+	}
+	if (ioctl(cpufd, KVM_SET_SREGS, &sregs))
+		return -1;
+	if (ioctl(cpufd, KVM_SET_REGS, &regs))
+		return -1;
+	return 0;
+}
+#else
+int kvm_setup_cpu(int vmfd, int cpufd, uint64_t mode, const char* text, uint64_t ntext, uint64_t flags)
+{
+	errno = ENOSYS;
+	return -1;
+}
+#endif
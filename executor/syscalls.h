@@ -1,9 +1,16 @@
 // AUTOGENERATED FILE
 
+#define __NR_syz_emit_ethernet	1000010
+#define __NR_syz_extract_tcp_res	1000011
 #define __NR_syz_fuse_mount	1000003
 #define __NR_syz_fuseblk_mount	1000004
+#define __NR_syz_kvm_setup_cpu	1000007
+#define __NR_syz_mount_image	1000006
 #define __NR_syz_open_dev	1000001
 #define __NR_syz_open_pts	1000002
+#define __NR_syz_unix_relay	1000005
+#define __NR_syz_usb_connect	1000008
+#define __NR_syz_usb_control_io	1000009
 
 
 struct call_t {
@@ -454,8 +461,11 @@ call_t syscalls[] = {
 	{"sendmsg$unix", 46},
 	{"sendmmsg$unix", 307},
 	{"recvfrom$unix", 45},
+	{"recvmsg$unix", 47},
+	{"setsockopt$unix_passcred", 54},
 	{"getsockname$unix", 51},
 	{"getpeername$unix", 52},
+	{"syz_unix_relay", 1000005},
 	{"socket$alg", 41},
 	{"bind$alg", 49},
 	{"setsockopt$ALG_SET_KEY", 54},
@@ -536,6 +546,16 @@ call_t syscalls[] = {
 	{"getsockopt$BT_RCVMTU", 55},
 	{"open$ptmx", 2},
 	{"syz_open_pts", 1000002},
+	{"syz_open_dev$tty", 1000001},
+	{"syz_open_dev$tty1", 1000001},
+	{"ioctl$TIOCGPTN", 16},
+	{"ioctl$TIOCSPTLCK", 16},
+	{"ioctl$TIOCGPTLCK", 16},
+	{"ioctl$TIOCGPKT", 16},
+	{"ioctl$TIOCGEXCL", 16},
+	{"ioctl$TIOCSIG", 16},
+	{"ioctl$TIOCVHANGUP", 16},
+	{"ioctl$TIOCGDEV", 16},
 	{"ioctl$TCGETS", 16},
 	{"ioctl$TCSETS", 16},
 	{"ioctl$TCSETSW", 16},
@@ -575,6 +595,16 @@ call_t syscalls[] = {
 	{"ioctl$TIOCGSOFTCAR", 16},
 	{"ioctl$TIOCSSOFTCAR", 16},
 	{"ioctl$TIOCTTYGSTRUCT", 16},
+	{"ioctl$HCIUARTSETPROTO", 16},
+	{"ioctl$HCIUARTGETPROTO", 16},
+	{"ioctl$HCIUARTGETDEVICE", 16},
+	{"ioctl$HCIUARTSETFLAGS", 16},
+	{"ioctl$HCIUARTGETFLAGS", 16},
+	{"ioctl$GSMIOC_GETCONF", 16},
+	{"ioctl$GSMIOC_SETCONF", 16},
+	{"ioctl$PPPIOCGCHAN", 16},
+	{"ioctl$PPPIOCGUNIT", 16},
+	{"ioctl$SIOCGIFNAME_tty", 16},
 	{"ioctl$KDGETLED", 16},
 	{"ioctl$KDSETLED", 16},
 	{"ioctl$KDGKBLED", 16},
@@ -846,6 +876,7 @@ call_t syscalls[] = {
 	{"getsockopt$SCTP_RECVNXTINFO", 55},
 	{"ioctl$SCTP_SIOCINQ", 16},
 	{"syz_open_dev$kvm", 1000001},
+	{"syz_kvm_setup_cpu$x86", 1000007},
 	{"ioctl$KVM_CREATE_VM", 16},
 	{"ioctl$KVM_GET_MSR_INDEX_LIST", 16},
 	{"ioctl$KVM_CHECK_EXTENSION", 16},
@@ -1060,6 +1091,10 @@ call_t syscalls[] = {
 	{"setsockopt$NETLINK_LISTEN_ALL_NSID", 54},
 	{"setsockopt$NETLINK_CAP_ACK", 54},
 	{"getsockopt$netlink", 55},
+	{"socket$nl_route", 41},
+	{"sendmsg$nl_route", 46},
+	{"socket$nl_generic", 41},
+	{"sendmsg$nl_generic", 46},
 	{"syz_open_dev$tun", 1000001},
 	{"write$tun", 1},
 	{"ioctl$TUNGETFEATURES", 16},
@@ -1121,6 +1156,38 @@ call_t syscalls[] = {
 	{"ioctl$NETROM_SIOCGSTAMP", 16},
 	{"ioctl$NETROM_SIOCGSTAMPNS", 16},
 	{"ioctl$NETROM_SIOCADDRT", 16},
+	{"socket$inet6", 41},
+	{"socket$inet6_icmp", 41},
+	{"bind$inet6", 49},
+	{"connect$inet6", 42},
+	{"sendto$inet6", 44},
+	{"sendmsg$inet6", 46},
+	{"sendmmsg$inet6", 307},
+	{"setsockopt$inet6_pktinfo", 54},
+	{"getsockopt$inet6_pktinfo", 55},
+	{"setsockopt$inet6_recv", 54},
+	{"setsockopt$inet6_tclass", 54},
+	{"setsockopt$inet6_exthdr", 54},
+	{"getsockopt$inet6_exthdr", 55},
+	{"setsockopt$inet6_icmp_filter", 54},
+	{"getsockopt$inet6_icmp_filter", 55},
+	{"setsockopt$inet6_group", 54},
+	{"setsockopt$inet6_group_source", 54},
+	{"setsockopt$inet6_msfilter", 54},
+	{"getsockopt$inet6_msfilter", 55},
+	{"openat$pseudofs", 257},
+	{"write$pseudofs", 1},
+	{"pwrite64$pseudofs", 18},
+	{"read$pseudofs", 0},
+	{"lseek$pseudofs", 8},
+	{"truncate$pseudofs", 76},
+	{"syz_mount_image$ext4", 1000006},
+	{"syz_mount_image$vfat", 1000006},
+	{"syz_mount_image$btrfs", 1000006},
+	{"syz_usb_connect", 1000008},
+	{"syz_usb_control_io", 1000009},
+	{"syz_emit_ethernet", 1000010},
+	{"syz_extract_tcp_res", 1000011},
 
 };
 #endif
@@ -1567,8 +1634,11 @@ call_t syscalls[] = {
 	{"sendmsg$unix", 370},
 	{"sendmmsg$unix", 345},
 	{"recvfrom$unix", 371},
+	{"recvmsg$unix", 372},
+	{"setsockopt$unix_passcred", 366},
 	{"getsockname$unix", 367},
 	{"getpeername$unix", 368},
+	{"syz_unix_relay", 1000005},
 	{"socket$alg", 359},
 	{"bind$alg", 361},
 	{"setsockopt$ALG_SET_KEY", 366},
@@ -1649,6 +1719,16 @@ call_t syscalls[] = {
 	{"getsockopt$BT_RCVMTU", 365},
 	{"open$ptmx", 5},
 	{"syz_open_pts", 1000002},
+	{"syz_open_dev$tty", 1000001},
+	{"syz_open_dev$tty1", 1000001},
+	{"ioctl$TIOCGPTN", 54},
+	{"ioctl$TIOCSPTLCK", 54},
+	{"ioctl$TIOCGPTLCK", 54},
+	{"ioctl$TIOCGPKT", 54},
+	{"ioctl$TIOCGEXCL", 54},
+	{"ioctl$TIOCSIG", 54},
+	{"ioctl$TIOCVHANGUP", 54},
+	{"ioctl$TIOCGDEV", 54},
 	{"ioctl$TCGETS", 54},
 	{"ioctl$TCSETS", 54},
 	{"ioctl$TCSETSW", 54},
@@ -1688,6 +1768,16 @@ call_t syscalls[] = {
 	{"ioctl$TIOCGSOFTCAR", 54},
 	{"ioctl$TIOCSSOFTCAR", 54},
 	{"ioctl$TIOCTTYGSTRUCT", 54},
+	{"ioctl$HCIUARTSETPROTO", 54},
+	{"ioctl$HCIUARTGETPROTO", 54},
+	{"ioctl$HCIUARTGETDEVICE", 54},
+	{"ioctl$HCIUARTSETFLAGS", 54},
+	{"ioctl$HCIUARTGETFLAGS", 54},
+	{"ioctl$GSMIOC_GETCONF", 54},
+	{"ioctl$GSMIOC_SETCONF", 54},
+	{"ioctl$PPPIOCGCHAN", 54},
+	{"ioctl$PPPIOCGUNIT", 54},
+	{"ioctl$SIOCGIFNAME_tty", 54},
 	{"ioctl$KDGETLED", 54},
 	{"ioctl$KDSETLED", 54},
 	{"ioctl$KDGKBLED", 54},
@@ -1959,6 +2049,7 @@ call_t syscalls[] = {
 	{"getsockopt$SCTP_RECVNXTINFO", 365},
 	{"ioctl$SCTP_SIOCINQ", 54},
 	{"syz_open_dev$kvm", 1000001},
+	{"syz_kvm_setup_cpu$x86", 1000007},
 	{"ioctl$KVM_CREATE_VM", 54},
 	{"ioctl$KVM_GET_MSR_INDEX_LIST", 54},
 	{"ioctl$KVM_CHECK_EXTENSION", 54},
@@ -2173,6 +2264,10 @@ call_t syscalls[] = {
 	{"setsockopt$NETLINK_LISTEN_ALL_NSID", 366},
 	{"setsockopt$NETLINK_CAP_ACK", 366},
 	{"getsockopt$netlink", 365},
+	{"socket$nl_route", 359},
+	{"sendmsg$nl_route", 370},
+	{"socket$nl_generic", 359},
+	{"sendmsg$nl_generic", 370},
 	{"syz_open_dev$tun", 1000001},
 	{"write$tun", 4},
 	{"ioctl$TUNGETFEATURES", 54},
@@ -2234,6 +2329,38 @@ call_t syscalls[] = {
 	{"ioctl$NETROM_SIOCGSTAMP", 54},
 	{"ioctl$NETROM_SIOCGSTAMPNS", 54},
 	{"ioctl$NETROM_SIOCADDRT", 54},
+	{"socket$inet6", 359},
+	{"socket$inet6_icmp", 359},
+	{"bind$inet6", 361},
+	{"connect$inet6", 362},
+	{"sendto$inet6", 369},
+	{"sendmsg$inet6", 370},
+	{"sendmmsg$inet6", 345},
+	{"setsockopt$inet6_pktinfo", 366},
+	{"getsockopt$inet6_pktinfo", 365},
+	{"setsockopt$inet6_recv", 366},
+	{"setsockopt$inet6_tclass", 366},
+	{"setsockopt$inet6_exthdr", 366},
+	{"getsockopt$inet6_exthdr", 365},
+	{"setsockopt$inet6_icmp_filter", 366},
+	{"getsockopt$inet6_icmp_filter", 365},
+	{"setsockopt$inet6_group", 366},
+	{"setsockopt$inet6_group_source", 366},
+	{"setsockopt$inet6_msfilter", 366},
+	{"getsockopt$inet6_msfilter", 365},
+	{"openat$pseudofs", 295},
+	{"write$pseudofs", 4},
+	{"pwrite64$pseudofs", 181},
+	{"read$pseudofs", 3},
+	{"lseek$pseudofs", 19},
+	{"truncate$pseudofs", 92},
+	{"syz_mount_image$ext4", 1000006},
+	{"syz_mount_image$vfat", 1000006},
+	{"syz_mount_image$btrfs", 1000006},
+	{"syz_usb_connect", 1000008},
+	{"syz_usb_control_io", 1000009},
+	{"syz_emit_ethernet", 1000010},
+	{"syz_extract_tcp_res", 1000011},
 
 };
 #endif
@@ -2680,8 +2807,11 @@ call_t syscalls[] = {
 	{"sendmsg$unix", 211},
 	{"sendmmsg$unix", 269},
 	{"recvfrom$unix", 207},
+	{"recvmsg$unix", 212},
+	{"setsockopt$unix_passcred", 208},
 	{"getsockname$unix", 204},
 	{"getpeername$unix", 205},
+	{"syz_unix_relay", 1000005},
 	{"socket$alg", 198},
 	{"bind$alg", 200},
 	{"setsockopt$ALG_SET_KEY", 208},
@@ -2762,6 +2892,16 @@ call_t syscalls[] = {
 	{"getsockopt$BT_RCVMTU", 209},
 	{"open$ptmx", -1},
 	{"syz_open_pts", 1000002},
+	{"syz_open_dev$tty", 1000001},
+	{"syz_open_dev$tty1", 1000001},
+	{"ioctl$TIOCGPTN", 29},
+	{"ioctl$TIOCSPTLCK", 29},
+	{"ioctl$TIOCGPTLCK", 29},
+	{"ioctl$TIOCGPKT", 29},
+	{"ioctl$TIOCGEXCL", 29},
+	{"ioctl$TIOCSIG", 29},
+	{"ioctl$TIOCVHANGUP", 29},
+	{"ioctl$TIOCGDEV", 29},
 	{"ioctl$TCGETS", 29},
 	{"ioctl$TCSETS", 29},
 	{"ioctl$TCSETSW", 29},
@@ -2801,6 +2941,16 @@ call_t syscalls[] = {
 	{"ioctl$TIOCGSOFTCAR", 29},
 	{"ioctl$TIOCSSOFTCAR", 29},
 	{"ioctl$TIOCTTYGSTRUCT", 29},
+	{"ioctl$HCIUARTSETPROTO", 29},
+	{"ioctl$HCIUARTGETPROTO", 29},
+	{"ioctl$HCIUARTGETDEVICE", 29},
+	{"ioctl$HCIUARTSETFLAGS", 29},
+	{"ioctl$HCIUARTGETFLAGS", 29},
+	{"ioctl$GSMIOC_GETCONF", 29},
+	{"ioctl$GSMIOC_SETCONF", 29},
+	{"ioctl$PPPIOCGCHAN", 29},
+	{"ioctl$PPPIOCGUNIT", 29},
+	{"ioctl$SIOCGIFNAME_tty", 29},
 	{"ioctl$KDGETLED", 29},
 	{"ioctl$KDSETLED", 29},
 	{"ioctl$KDGKBLED", 29},
@@ -3072,6 +3222,7 @@ call_t syscalls[] = {
 	{"getsockopt$SCTP_RECVNXTINFO", 209},
 	{"ioctl$SCTP_SIOCINQ", 29},
 	{"syz_open_dev$kvm", 1000001},
+	{"syz_kvm_setup_cpu$x86", 1000007},
 	{"ioctl$KVM_CREATE_VM", 29},
 	{"ioctl$KVM_GET_MSR_INDEX_LIST", 29},
 	{"ioctl$KVM_CHECK_EXTENSION", 29},
@@ -3286,6 +3437,10 @@ call_t syscalls[] = {
 	{"setsockopt$NETLINK_LISTEN_ALL_NSID", 208},
 	{"setsockopt$NETLINK_CAP_ACK", 208},
 	{"getsockopt$netlink", 209},
+	{"socket$nl_route", 198},
+	{"sendmsg$nl_route", 211},
+	{"socket$nl_generic", 198},
+	{"sendmsg$nl_generic", 211},
 	{"syz_open_dev$tun", 1000001},
 	{"write$tun", 64},
 	{"ioctl$TUNGETFEATURES", 29},
@@ -3347,6 +3502,38 @@ call_t syscalls[] = {
 	{"ioctl$NETROM_SIOCGSTAMP", 29},
 	{"ioctl$NETROM_SIOCGSTAMPNS", 29},
 	{"ioctl$NETROM_SIOCADDRT", 29},
+	{"socket$inet6", 198},
+	{"socket$inet6_icmp", 198},
+	{"bind$inet6", 200},
+	{"connect$inet6", 203},
+	{"sendto$inet6", 206},
+	{"sendmsg$inet6", 211},
+	{"sendmmsg$inet6", 269},
+	{"setsockopt$inet6_pktinfo", 208},
+	{"getsockopt$inet6_pktinfo", 209},
+	{"setsockopt$inet6_recv", 208},
+	{"setsockopt$inet6_tclass", 208},
+	{"setsockopt$inet6_exthdr", 208},
+	{"getsockopt$inet6_exthdr", 209},
+	{"setsockopt$inet6_icmp_filter", 208},
+	{"getsockopt$inet6_icmp_filter", 209},
+	{"setsockopt$inet6_group", 208},
+	{"setsockopt$inet6_group_source", 208},
+	{"setsockopt$inet6_msfilter", 208},
+	{"getsockopt$inet6_msfilter", 209},
+	{"openat$pseudofs", 56},
+	{"write$pseudofs", 64},
+	{"pwrite64$pseudofs", 68},
+	{"read$pseudofs", 63},
+	{"lseek$pseudofs", 62},
+	{"truncate$pseudofs", 45},
+	{"syz_mount_image$ext4", 1000006},
+	{"syz_mount_image$vfat", 1000006},
+	{"syz_mount_image$btrfs", 1000006},
+	{"syz_usb_connect", 1000008},
+	{"syz_usb_control_io", 1000009},
+	{"syz_emit_ethernet", 1000010},
+	{"syz_extract_tcp_res", 1000011},
 
 };
 #endif
@@ -3793,8 +3980,11 @@ call_t syscalls[] = {
 	{"sendmsg$unix", 341},
 	{"sendmmsg$unix", 349},
 	{"recvfrom$unix", 337},
+	{"recvmsg$unix", 342},
+	{"setsockopt$unix_passcred", 339},
 	{"getsockname$unix", 331},
 	{"getpeername$unix", 332},
+	{"syz_unix_relay", 1000005},
 	{"socket$alg", 326},
 	{"bind$alg", 327},
 	{"setsockopt$ALG_SET_KEY", 339},
@@ -3875,6 +4065,16 @@ call_t syscalls[] = {
 	{"getsockopt$BT_RCVMTU", 340},
 	{"open$ptmx", 5},
 	{"syz_open_pts", 1000002},
+	{"syz_open_dev$tty", 1000001},
+	{"syz_open_dev$tty1", 1000001},
+	{"ioctl$TIOCGPTN", 54},
+	{"ioctl$TIOCSPTLCK", 54},
+	{"ioctl$TIOCGPTLCK", 54},
+	{"ioctl$TIOCGPKT", 54},
+	{"ioctl$TIOCGEXCL", 54},
+	{"ioctl$TIOCSIG", 54},
+	{"ioctl$TIOCVHANGUP", 54},
+	{"ioctl$TIOCGDEV", 54},
 	{"ioctl$TCGETS", 54},
 	{"ioctl$TCSETS", 54},
 	{"ioctl$TCSETSW", 54},
@@ -3914,6 +4114,16 @@ call_t syscalls[] = {
 	{"ioctl$TIOCGSOFTCAR", 54},
 	{"ioctl$TIOCSSOFTCAR", 54},
 	{"ioctl$TIOCTTYGSTRUCT", 54},
+	{"ioctl$HCIUARTSETPROTO", 54},
+	{"ioctl$HCIUARTGETPROTO", 54},
+	{"ioctl$HCIUARTGETDEVICE", 54},
+	{"ioctl$HCIUARTSETFLAGS", 54},
+	{"ioctl$HCIUARTGETFLAGS", 54},
+	{"ioctl$GSMIOC_GETCONF", 54},
+	{"ioctl$GSMIOC_SETCONF", 54},
+	{"ioctl$PPPIOCGCHAN", 54},
+	{"ioctl$PPPIOCGUNIT", 54},
+	{"ioctl$SIOCGIFNAME_tty", 54},
 	{"ioctl$KDGETLED", 54},
 	{"ioctl$KDSETLED", 54},
 	{"ioctl$KDGKBLED", 54},
@@ -4185,6 +4395,7 @@ call_t syscalls[] = {
 	{"getsockopt$SCTP_RECVNXTINFO", 340},
 	{"ioctl$SCTP_SIOCINQ", 54},
 	{"syz_open_dev$kvm", 1000001},
+	{"syz_kvm_setup_cpu$x86", 1000007},
 	{"ioctl$KVM_CREATE_VM", 54},
 	{"ioctl$KVM_GET_MSR_INDEX_LIST", 54},
 	{"ioctl$KVM_CHECK_EXTENSION", 54},
@@ -4399,6 +4610,10 @@ call_t syscalls[] = {
 	{"setsockopt$NETLINK_LISTEN_ALL_NSID", 339},
 	{"setsockopt$NETLINK_CAP_ACK", 339},
 	{"getsockopt$netlink", 340},
+	{"socket$nl_route", 326},
+	{"sendmsg$nl_route", 341},
+	{"socket$nl_generic", 326},
+	{"sendmsg$nl_generic", 341},
 	{"syz_open_dev$tun", 1000001},
 	{"write$tun", 4},
 	{"ioctl$TUNGETFEATURES", 54},
@@ -4460,6 +4675,38 @@ call_t syscalls[] = {
 	{"ioctl$NETROM_SIOCGSTAMP", 54},
 	{"ioctl$NETROM_SIOCGSTAMPNS", 54},
 	{"ioctl$NETROM_SIOCADDRT", 54},
+	{"socket$inet6", 326},
+	{"socket$inet6_icmp", 326},
+	{"bind$inet6", 327},
+	{"connect$inet6", 328},
+	{"sendto$inet6", 335},
+	{"sendmsg$inet6", 341},
+	{"sendmmsg$inet6", 349},
+	{"setsockopt$inet6_pktinfo", 339},
+	{"getsockopt$inet6_pktinfo", 340},
+	{"setsockopt$inet6_recv", 339},
+	{"setsockopt$inet6_tclass", 339},
+	{"setsockopt$inet6_exthdr", 339},
+	{"getsockopt$inet6_exthdr", 340},
+	{"setsockopt$inet6_icmp_filter", 339},
+	{"getsockopt$inet6_icmp_filter", 340},
+	{"setsockopt$inet6_group", 339},
+	{"setsockopt$inet6_group_source", 339},
+	{"setsockopt$inet6_msfilter", 339},
+	{"getsockopt$inet6_msfilter", 340},
+	{"openat$pseudofs", 286},
+	{"write$pseudofs", 4},
+	{"pwrite64$pseudofs", 180},
+	{"read$pseudofs", 3},
+	{"lseek$pseudofs", 19},
+	{"truncate$pseudofs", 92},
+	{"syz_mount_image$ext4", 1000006},
+	{"syz_mount_image$vfat", 1000006},
+	{"syz_mount_image$btrfs", 1000006},
+	{"syz_usb_connect", 1000008},
+	{"syz_usb_control_io", 1000009},
+	{"syz_emit_ethernet", 1000010},
+	{"syz_extract_tcp_res", 1000011},
 
 };
 #endif
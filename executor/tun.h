@@ -0,0 +1,243 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Packet injection for syz_emit_ethernet/syz_extract_tcp_res. Every executor sets up a tap
+// interface (CONFIG_TUN) with local addresses 172.20.0.170 and aa:aa:aa:aa:aa:aa, and a static
+// neighbour 172.20.0.187 with bb:bb:bb:bb:bb:bb that plays the remote side. Packets written
+// to the tap fd are received by the network stack as if they came from the remote side,
+// and responses of the stack can be read back from the fd.
+// In none/setuid sandboxes all interfaces share the network namespace and the addresses,
+// so responses can go to an interface of another executor; namespace sandbox doesn't have this problem.
+
+#include <linux/if.h>
+#include <linux/if_arp.h>
+#include <linux/if_ether.h>
+#include <linux/if_tun.h>
+#include <netinet/in.h>
+
+const int kTunFd = 252;
+const int kTunMaxPacket = 64 << 10;
+const uint8_t kTunLocalIPv4[4] = {172, 20, 0, 170};
+const uint8_t kTunRemoteIPv4[4] = {172, 20, 0, 187};
+const uint8_t kTunLocalMac[6] = {0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa};
+const uint8_t kTunRemoteMac[6] = {0xbb, 0xbb, 0xbb, 0xbb, 0xbb, 0xbb};
+
+bool tun_enabled;
+
+__attribute__((noreturn)) void fail(const char* msg, ...);
+void debug(const char* msg, ...);
+
+void tun_set_ipv4(sockaddr* addr, const uint8_t* ip)
+{
+	sockaddr_in* sin = (sockaddr_in*)addr;
+	memset(sin, 0, sizeof(*sin));
+	sin->sin_family = AF_INET;
+	memcpy(&sin->sin_addr, ip, 4);
+}
+
+// setup_tun creates the tap interface syz<pid> on kTunFd. Failures are not fatal,
+// syz_emit_ethernet just fails if there is no interface.
+void setup_tun()
+{
+	int id = getpid();
+	int fd = open("/dev/net/tun", O_RDWR | O_NONBLOCK);
+	if (fd == -1) {
+		debug("tun: can't open /dev/net/tun (errno %d)\n", errno);
+		return;
+	}
+	if (dup2(fd, kTunFd) < 0)
+		fail("dup2(tun) failed");
+	close(fd);
+	ifreq ifr;
+	memset(&ifr, 0, sizeof(ifr));
+	snprintf(ifr.ifr_name, IFNAMSIZ, "syz%d", id);
+	ifr.ifr_flags = IFF_TAP | IFF_NO_PI;
+	if (ioctl(kTunFd, TUNSETIFF, &ifr)) {
+		debug("tun: TUNSETIFF failed (errno %d)\n", errno);
+		close(kTunFd);
+		return;
+	}
+	int sock = socket(AF_INET, SOCK_DGRAM, 0);
+	if (sock == -1)
+		fail("socket(AF_INET) failed");
+	ifr.ifr_hwaddr.sa_family = ARPHRD_ETHER;
+	memcpy(ifr.ifr_hwaddr.sa_data, kTunLocalMac, 6);
+	if (ioctl(sock, SIOCSIFHWADDR, &ifr))
+		fail("tun: SIOCSIFHWADDR failed");
+	tun_set_ipv4(&ifr.ifr_addr, kTunLocalIPv4);
+	if (ioctl(sock, SIOCSIFADDR, &ifr))
+		fail("tun: SIOCSIFADDR failed");
+	const uint8_t mask[4] = {255, 255, 255, 0};
+	tun_set_ipv4(&ifr.ifr_netmask, mask);
+	if (ioctl(sock, SIOCSIFNETMASK, &ifr))
+		fail("tun: SIOCSIFNETMASK failed");
+	if (ioctl(sock, SIOCGIFFLAGS, &ifr))
+		fail("tun: SIOCGIFFLAGS failed");
+	ifr.ifr_flags |= IFF_UP | IFF_RUNNING;
+	if (ioctl(sock, SIOCSIFFLAGS, &ifr))
+		fail("tun: SIOCSIFFLAGS failed");
+	// Static neighbour, so that the stack responds without ARP resolution.
+	arpreq arp;
+	memset(&arp, 0, sizeof(arp));
+	tun_set_ipv4(&arp.arp_pa, kTunRemoteIPv4);
+	arp.arp_ha.sa_family = ARPHRD_ETHER;
+	memcpy(arp.arp_ha.sa_data, kTunRemoteMac, 6);
+	arp.arp_flags = ATF_COM | ATF_PERM;
+	snprintf(arp.arp_dev, sizeof(arp.arp_dev), "syz%d", id);
+	if (ioctl(sock, SIOCSARP, &arp))
+		fail("tun: SIOCSARP failed");
+	close(sock);
+	tun_enabled = true;
+	debug("tun: set up syz%d\n", id);
+}
+
+// flush_tun drops packets left from previous programs.
+void flush_tun()
+{
+	if (!tun_enabled)
+		return;
+	char buf[kTunMaxPacket];
+	while (read(kTunFd, buf, sizeof(buf)) > 0) {
+	}
+}
+
+uint32_t csum_inet_add(uint32_t acc, const uint8_t* data, size_t len)
+{
+	for (size_t i = 0; i + 1 < len; i += 2)
+		acc += (data[i] << 8) | data[i + 1];
+	if (len & 1)
+		acc += data[len - 1] << 8;
+	return acc;
+}
+
+uint16_t csum_inet_digest(uint32_t acc)
+{
+	while (acc >> 16)
+		acc = (acc & 0xffff) + (acc >> 16);
+	return htons(~acc);
+}
+
+uint16_t get_be16(const uint8_t* p)
+{
+	return (p[0] << 8) | p[1];
+}
+
+void put_be16(uint8_t* p, uint16_t v)
+{
+	p[0] = v >> 8;
+	p[1] = v;
+}
+
+// fixup_l4 fills in zero length/checksum of the TCP/UDP/ICMP header in data,
+// pseudo is the checksum of the IP pseudo-header without the length.
+void fixup_l4(uint8_t proto, uint8_t* data, size_t len, uint32_t pseudo, bool ipv6)
+{
+	int csum_off = -1;
+	switch (proto) {
+	case IPPROTO_TCP:
+		csum_off = 16;
+		break;
+	case IPPROTO_UDP:
+		if (len >= 8 && get_be16(data + 4) == 0)
+			put_be16(data + 4, len);
+		csum_off = 6;
+		break;
+	case IPPROTO_ICMP:
+		csum_off = 2;
+		pseudo = 0; // ICMP checksum does not cover the pseudo-header.
+		break;
+	case IPPROTO_ICMPV6:
+		csum_off = 2;
+		break;
+	}
+	if (csum_off < 0 || len < (size_t)csum_off + 2 || get_be16(data + csum_off) != 0)
+		return;
+	uint32_t acc = pseudo;
+	if (proto != IPPROTO_ICMP || ipv6)
+		acc += len;
+	put_be16(data + csum_off, ntohs(csum_inet_digest(csum_inet_add(acc, data, len))));
+}
+
+// fixup_packet fills in IPv4/IPv6 and transport header lengths and checksums that are left zero
+// in the ethernet frame, the rest of the packet is sent as is.
+void fixup_packet(uint8_t* data, size_t len)
+{
+	size_t off = 12;
+	if (len < off + 2)
+		return;
+	uint16_t type = get_be16(data + off);
+	off += 2;
+	if (type == ETH_P_8021Q && len >= off + 4) {
+		type = get_be16(data + off + 2);
+		off += 4;
+	}
+	uint8_t* ip = data + off;
+	size_t iplen = len - off;
+	if (type == ETH_P_IP && iplen >= 20) {
+		size_t ihl = (ip[0] & 0xf) * 4;
+		if (ihl < 20 || ihl > iplen)
+			return;
+		if (get_be16(ip + 2) == 0)
+			put_be16(ip + 2, iplen);
+		uint8_t proto = ip[9];
+		uint32_t pseudo = csum_inet_add(proto, ip + 12, 8);
+		fixup_l4(proto, ip + ihl, iplen - ihl, pseudo, false);
+		if (get_be16(ip + 10) == 0)
+			put_be16(ip + 10, ntohs(csum_inet_digest(csum_inet_add(0, ip, ihl))));
+	} else if (type == ETH_P_IPV6 && iplen >= 40) {
+		if (get_be16(ip + 4) == 0)
+			put_be16(ip + 4, iplen - 40);
+		uint8_t proto = ip[6];
+		uint32_t pseudo = csum_inet_add(proto, ip + 8, 32);
+		fixup_l4(proto, ip + 40, iplen - 40, pseudo, true);
+	}
+}
+
+// emit_ethernet sends an ethernet frame to the stack via the tap interface.
+int emit_ethernet(uint64_t len, const char* data)
+{
+	if (!tun_enabled) {
+		errno = ENODEV;
+		return -1;
+	}
+	uint8_t buf[kTunMaxPacket];
+	if (len > sizeof(buf))
+		len = sizeof(buf);
+	memcpy(buf, data, len);
+	fixup_packet(buf, len);
+	return write(kTunFd, buf, len);
+}
+
+// extract_tcp_res reads packets sent by the stack until a TCP packet and stores its sequence
+// and acknowledgement numbers incremented by seq_inc/ack_inc into res[0]/res[1].
+// The numbers are stored in network byte order, so that they can be copied into TCP headers as is.
+int extract_tcp_res(uint32_t* res, uint32_t seq_inc, uint32_t ack_inc)
+{
+	if (!tun_enabled) {
+		errno = ENODEV;
+		return -1;
+	}
+	uint8_t buf[kTunMaxPacket];
+	for (;;) {
+		int n = read(kTunFd, buf, sizeof(buf));
+		if (n <= 0)
+			return -1;
+		if (n < 14)
+			continue;
+		uint16_t type = get_be16(buf + 12);
+		uint8_t* tcp = NULL;
+		if (type == ETH_P_IP && n >= 14 + 20 && buf[14 + 9] == IPPROTO_TCP)
+			tcp = buf + 14 + (buf[14] & 0xf) * 4;
+		else if (type == ETH_P_IPV6 && n >= 14 + 40 && buf[14 + 6] == IPPROTO_TCP)
+			tcp = buf + 14 + 40;
+		if (tcp == NULL || tcp + 12 > buf + n)
+			continue;
+		uint32_t seq, ack;
+		memcpy(&seq, tcp + 4, 4);
+		memcpy(&ack, tcp + 8, 4);
+		res[0] = htonl(ntohl(seq) + seq_inc);
+		res[1] = htonl(ntohl(ack) + ack_inc);
+		debug("extract_tcp_res: seq=0x%x ack=0x%x\n", ntohl(res[0]), ntohl(res[1]));
+		return 0;
+	}
+}
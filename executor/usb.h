@@ -0,0 +1,158 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// USB device emulation for syz_usb_connect/syz_usb_control_io. The device is emulated with gadgetfs
+// on top of a dummy_hcd UDC (CONFIG_USB_GADGETFS, CONFIG_USB_DUMMY_HCD), so the device appears
+// on the host side of the same kernel and host USB drivers probe it.
+// gadgetfs supports a single device per UDC, so only one test process at a time can connect a device.
+
+#include <linux/usb/ch9.h>
+#include <linux/usb/gadgetfs.h>
+#include <poll.h>
+
+const char* kUsbGadgetDir = "./gadget";
+const int kUsbMaxDescriptors = 4096;
+const int kUsbEnumerateTimeout = 1000; // ms
+const int kUsbControlTimeout = 100; // ms
+
+uint64_t current_time_ms();
+void debug(const char* msg, ...);
+
+bool usb_find_udc(char* udc, int size)
+{
+	DIR* dp = opendir(kUsbGadgetDir);
+	if (dp == NULL)
+		return false;
+	bool found = false;
+	// Besides the UDC file (e.g. dummy_udc.0) there are only endpoint files.
+	while (dirent* ep = readdir(dp)) {
+		if (ep->d_name[0] == '.' || strncmp(ep->d_name, "ep", 2) == 0)
+			continue;
+		snprintf(udc, size, "%s/%s", kUsbGadgetDir, ep->d_name);
+		found = true;
+		break;
+	}
+	closedir(dp);
+	if (!found)
+		errno = ENODEV;
+	return found;
+}
+
+// usb_wait_setup skips non-SETUP events and returns the next control request.
+bool usb_wait_setup(int fd, usb_ctrlrequest* ctrl, int timeout)
+{
+	uint64_t deadline = current_time_ms() + timeout;
+	for (;;) {
+		uint64_t now = current_time_ms();
+		if (now >= deadline)
+			return false;
+		pollfd pfd = {fd, POLLIN, 0};
+		if (poll(&pfd, 1, deadline - now) <= 0)
+			return false;
+		usb_gadgetfs_event events[4];
+		int n = read(fd, events, sizeof(events));
+		if (n <= 0)
+			return false;
+		for (int i = 0; i < n / (int)sizeof(events[0]); i++) {
+			if (events[i].type == GADGETFS_SETUP) {
+				*ctrl = events[i].u.setup;
+				return true;
+			}
+		}
+	}
+}
+
+// usb_respond completes the data/status stage of the control request.
+// If data is NULL, the request is stalled (gadgetfs stalls on i/o in the wrong direction).
+void usb_respond(int fd, const usb_ctrlrequest* ctrl, const char* data, uint64_t len)
+{
+	char buf[kUsbMaxDescriptors];
+	bool in = ctrl->bRequestType & USB_DIR_IN;
+	if (data == NULL) {
+		if (in)
+			read(fd, buf, 0);
+		else
+			write(fd, buf, 0);
+		return;
+	}
+	if (in) {
+		if (len > ctrl->wLength)
+			len = ctrl->wLength;
+		write(fd, data, len);
+	} else {
+		len = ctrl->wLength;
+		if (len > sizeof(buf))
+			len = sizeof(buf);
+		read(fd, buf, len);
+	}
+}
+
+// usb_connect mounts gadgetfs, configures the UDC with the config and device descriptors
+// and handles host requests until the host sets the configuration.
+// Returns fd of the UDC that is used to handle further control requests.
+int usb_connect(const char* dev, const char* conf, uint64_t conflen)
+{
+	if (conflen > kUsbMaxDescriptors)
+		conflen = kUsbMaxDescriptors;
+	mkdir(kUsbGadgetDir, 0777);
+	// The program could have connected a device already.
+	if (mount("gadgetfs", kUsbGadgetDir, "gadgetfs", 0, NULL) && errno != EBUSY)
+		return -1;
+	char udc[128];
+	if (!usb_find_udc(udc, sizeof(udc)))
+		return -1;
+	int fd = open(udc, O_RDWR);
+	if (fd == -1)
+		return -1;
+	// gadgetfs wants tag 0, full speed config (we don't provide high speed config) and device descriptor.
+	char buf[4 + kUsbMaxDescriptors + USB_DT_DEVICE_SIZE];
+	memset(buf, 0, 4);
+	memcpy(buf + 4, conf, conflen);
+	memcpy(buf + 4 + conflen, dev, USB_DT_DEVICE_SIZE);
+	debug("usb_connect(\"%s\", %lu)\n", udc, conflen);
+	if (write(fd, buf, 4 + conflen + USB_DT_DEVICE_SIZE) == -1) {
+		int err = errno;
+		close(fd);
+		errno = err;
+		return -1;
+	}
+	// gadgetfs handles device/config descriptor requests itself, the rest is delegated to us.
+	uint64_t deadline = current_time_ms() + kUsbEnumerateTimeout;
+	for (;;) {
+		uint64_t now = current_time_ms();
+		usb_ctrlrequest ctrl;
+		if (now >= deadline || !usb_wait_setup(fd, &ctrl, deadline - now))
+			break;
+		if ((ctrl.bRequestType & USB_TYPE_MASK) == USB_TYPE_STANDARD && ctrl.bRequest == USB_REQ_SET_CONFIGURATION) {
+			usb_respond(fd, &ctrl, "", 0);
+			break;
+		}
+		if ((ctrl.bRequestType & USB_TYPE_MASK) == USB_TYPE_STANDARD && ctrl.bRequest == USB_REQ_GET_DESCRIPTOR && (ctrl.wValue >> 8) == USB_DT_STRING) {
+			// Language ids for index 0, "syz" for the rest.
+			static const char langs[] = {4, USB_DT_STRING, 0x09, 0x04};
+			static const char str[] = {8, USB_DT_STRING, 's', 0, 'y', 0, 'z', 0};
+			if ((ctrl.wValue & 0xff) == 0)
+				usb_respond(fd, &ctrl, langs, sizeof(langs));
+			else
+				usb_respond(fd, &ctrl, str, sizeof(str));
+			continue;
+		}
+		usb_respond(fd, &ctrl, NULL, 0);
+	}
+	return fd;
+}
+
+// usb_control_io waits for the next control request on fd and responds to it with data
+// (IN requests get data, OUT requests are acked). Returns 0 if a request was handled.
+int usb_control_io(int fd, const char* data, uint64_t len)
+{
+	usb_ctrlrequest ctrl;
+	if (!usb_wait_setup(fd, &ctrl, kUsbControlTimeout)) {
+		errno = ETIMEDOUT;
+		return -1;
+	}
+	debug("usb_control_io: type=0x%x req=0x%x value=0x%x index=0x%x length=%d\n",
+	      ctrl.bRequestType, ctrl.bRequest, ctrl.wValue, ctrl.wIndex, ctrl.wLength);
+	usb_respond(fd, &ctrl, data, len);
+	return 0;
+}
@@ -11,8 +11,6 @@ import (
 	"path/filepath"
 	"strconv"
 	"sync"
-	"syscall"
-	"unsafe"
 )
 
 var copyMu sync.Mutex
@@ -57,19 +55,55 @@ func WriteTempFile(data []byte) (string, error) {
 	return f.Name(), nil
 }
 
+// WriteFileAtomic writes data to filename so that after a crash (of the process or of the kernel)
+// the file contains either the old or the new data: data is written to filename.tmp,
+// fsync-ed and renamed over filename. Leftover .tmp files are incomplete and can be removed.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp := filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(tmp, filename)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	SyncDir(filepath.Dir(filename))
+	return nil
+}
+
+// SyncDir fsyncs directory dir to persist renames/creation of files in it (best effort,
+// directories can't be synced on some OSes).
+func SyncDir(dir string) {
+	if f, err := os.Open(dir); err == nil {
+		f.Sync()
+		f.Close()
+	}
+}
+
 // ProcessTempDir creates a new temp dir in where and returns its path and an unique index.
 // It also cleans up old, unused temp dirs after dead processes.
 func ProcessTempDir(where string) (string, int, error) {
 	lk := filepath.Join(where, "instance-lock")
-	lkf, err := syscall.Open(lk, syscall.O_RDWR|syscall.O_CREAT, 0600)
+	lkf, err := os.OpenFile(lk, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return "", 0, err
 	}
-	defer syscall.Close(lkf)
-	if err := syscall.Flock(lkf, syscall.LOCK_EX); err != nil {
+	// Closing the file releases the lock.
+	defer lkf.Close()
+	if err := LockFile(lkf, true); err != nil {
 		return "", 0, err
 	}
-	defer syscall.Flock(lkf, syscall.LOCK_UN)
 
 	for i := 0; i < 1e3; i++ {
 		path := filepath.Join(where, fmt.Sprintf("instance-%v", i))
@@ -81,7 +115,7 @@ func ProcessTempDir(where string) (string, int, error) {
 			if err == nil && len(data) > 0 {
 				pid, err := strconv.Atoi(string(data))
 				if err == nil && pid > 1 {
-					if err := syscall.Kill(pid, 0); err == syscall.ESRCH {
+					if !ProcessExists(pid) {
 						if os.Remove(pidfile) == nil {
 							if os.RemoveAll(path) == nil {
 								i--
@@ -97,23 +131,10 @@ func ProcessTempDir(where string) (string, int, error) {
 		if err != nil {
 			return "", 0, err
 		}
-		if err := ioutil.WriteFile(pidfile, []byte(strconv.Itoa(syscall.Getpid())), 0600); err != nil {
+		if err := ioutil.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
 			return "", 0, err
 		}
 		return path, i, nil
 	}
 	return "", 0, fmt.Errorf("too many live instances")
 }
-
-// UmountAll recurusively unmounts all mounts in dir.
-func UmountAll(dir string) {
-	files, _ := ioutil.ReadDir(dir)
-	for _, f := range files {
-		name := filepath.Join(dir, f.Name())
-		if f.IsDir() {
-			UmountAll(name)
-		}
-		fn := []byte(name + "\x00")
-		syscall.Syscall(syscall.SYS_UMOUNT2, uintptr(unsafe.Pointer(&fn[0])), syscall.MNT_FORCE, 0)
-	}
-}
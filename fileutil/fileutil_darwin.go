@@ -0,0 +1,29 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package fileutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const mntForce = 0x80000 // MNT_FORCE from sys/mount.h, missing in syscall package
+
+// GrowPipe is a no-op on darwin, pipe buffer size can't be changed.
+func GrowPipe(f *os.File) {
+}
+
+// UmountAll recurusively unmounts all mounts in dir.
+func UmountAll(dir string) {
+	files, _ := ioutil.ReadDir(dir)
+	for _, f := range files {
+		name := filepath.Join(dir, f.Name())
+		if f.IsDir() {
+			UmountAll(name)
+		}
+		syscall.Unmount(name, mntForce)
+	}
+}
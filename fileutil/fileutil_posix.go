@@ -0,0 +1,32 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// +build !windows
+
+package fileutil
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// LockFile takes an exclusive advisory lock on f, the lock is released when f is closed.
+// If wait is false, fails if the file is already locked.
+func LockFile(f *os.File, wait bool) error {
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	return syscall.Flock(int(f.Fd()), how)
+}
+
+// ProcessExists returns true if a process with the pid is running.
+func ProcessExists(pid int) bool {
+	return syscall.Kill(pid, 0) != syscall.ESRCH
+}
+
+// ShellCommand returns a command that executes command with the host shell.
+func ShellCommand(command string) *exec.Cmd {
+	return exec.Command("sh", "-c", command)
+}
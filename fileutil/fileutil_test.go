@@ -78,3 +78,27 @@ func TestProcessTempDir(t *testing.T) {
 		}()
 	}
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syz")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fn := filepath.Join(dir, "file")
+	for _, data := range []string{"first", "second, longer", "3"} {
+		if err := WriteFileAtomic(fn, []byte(data), 0600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		got, err := ioutil.ReadFile(fn)
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		if string(got) != data {
+			t.Fatalf("got %q, want %q", got, data)
+		}
+		if _, err := os.Stat(fn + ".tmp"); err == nil {
+			t.Fatalf("temp file is left behind")
+		}
+	}
+}
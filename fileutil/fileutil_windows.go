@@ -0,0 +1,39 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package fileutil
+
+import (
+	"os"
+	"os/exec"
+)
+
+// LockFile is a no-op on Windows: files are not locked, so running
+// several managers in the same workdir is not detected.
+func LockFile(f *os.File, wait bool) error {
+	return nil
+}
+
+// ProcessExists returns true if a process with the pid is running.
+func ProcessExists(pid int) bool {
+	// Unlike on unix, FindProcess opens the process and fails if it does not exist.
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// GrowPipe is a no-op on Windows, pipe buffer size is fixed at creation.
+func GrowPipe(f *os.File) {
+}
+
+// ShellCommand returns a command that executes command with the host shell.
+func ShellCommand(command string) *exec.Cmd {
+	return exec.Command("cmd", "/C", command)
+}
+
+// UmountAll is a no-op on Windows, there are no mounts in workdir.
+func UmountAll(dir string) {
+}
@@ -91,11 +91,60 @@ func isSupportedSyzkall(kallsyms []byte, c *sys.Call) bool {
 	case "syz_fuseblk_mount":
 		_, err := os.Stat("/dev/fuse")
 		return err == nil && syscall.Getuid() == 0
+	case "syz_unix_relay":
+		return true
+	case "syz_mount_image":
+		// The image is attached to a loop device.
+		if syscall.Getuid() != 0 {
+			return false
+		}
+		if _, err := os.Stat("/dev/loop-control"); err != nil {
+			return false
+		}
+		ptr, ok := c.Args[0].(sys.PtrType)
+		if !ok {
+			return true
+		}
+		fs, ok := ptr.Type.(sys.StrConstType)
+		if !ok {
+			panic("first syz_mount_image arg is not a pointer to string const")
+		}
+		return isSupportedFilesystem(fs.Val[:len(fs.Val)-1])
+	case "syz_usb_connect", "syz_usb_control_io":
+		// Devices are emulated with gadgetfs on top of a UDC (e.g. dummy_hcd).
+		if syscall.Getuid() != 0 || !isSupportedFilesystem("gadgetfs") {
+			return false
+		}
+		udcs, err := ioutil.ReadDir("/sys/class/udc")
+		return err == nil && len(udcs) != 0
+	case "syz_emit_ethernet", "syz_extract_tcp_res":
+		// The executor sets up a tap interface (creating it requires root).
+		_, err := os.Stat("/dev/net/tun")
+		return err == nil && syscall.Getuid() == 0
+	case "syz_kvm_setup_cpu":
+		_, err := os.Stat("/dev/kvm")
+		return err == nil
 	default:
 		panic("unknown syzkall: " + c.Name)
 	}
 }
 
+// isSupportedFilesystem checks that the kernel supports filesystem fs (built-in or loaded).
+func isSupportedFilesystem(fs string) bool {
+	data, err := ioutil.ReadFile("/proc/filesystems")
+	if err != nil {
+		return false
+	}
+	// Lines are "nodev\tproc" or "\text4".
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 0 && fields[len(fields)-1] == fs {
+			return true
+		}
+	}
+	return false
+}
+
 func isSupportedSocket(kallsyms []byte, c *sys.Call) bool {
 	af, ok := c.Args[0].(sys.ConstType)
 	if !ok {
@@ -0,0 +1,43 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package host
+
+import (
+	"runtime"
+	"syscall"
+)
+
+const (
+	kcovInitTrace = 0x80086301 // _IOR('c', 1, unsigned long long)
+	kcovEnable    = 0x6364     // _IO('c', 100)
+	kcovDisable   = 0x6365     // _IO('c', 101)
+	kcovTraceCmp  = 1
+	kcovCoverSize = 64 << 10
+)
+
+// KcovComparisonsSupported checks whether the kernel can trace comparison operands
+// (KCOV_TRACE_CMP mode, CONFIG_KCOV_ENABLE_COMPARISONS). Returns false if kcov itself is missing.
+func KcovComparisonsSupported() bool {
+	// kcov is enabled for the current thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	fd, err := syscall.Open("/sys/kernel/debug/kcov", syscall.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer syscall.Close(fd)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), kcovInitTrace, kcovCoverSize); errno != 0 {
+		return false
+	}
+	mem, err := syscall.Mmap(fd, 0, kcovCoverSize*8, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return false
+	}
+	defer syscall.Munmap(mem)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), kcovEnable, kcovTraceCmp); errno != 0 {
+		return false
+	}
+	syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), kcovDisable, 0)
+	return true
+}
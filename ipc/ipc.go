@@ -25,6 +25,15 @@ type Env struct {
 	In  []byte
 	Out []byte
 
+	// DropCaps is a mask of capabilities the executor drops before executing the next program.
+	DropCaps uint64
+	// NoCollide disables re-execution of the next program in collide mode (see FlagCollide).
+	NoCollide bool
+	// ProgramTimeout is the time after which the executor kills the program (0: 5 seconds).
+	// A program that can't be killed is abandoned and reported as hanged, the executor is restarted
+	// (the abandoned process may still write to the shared output).
+	ProgramTimeout time.Duration
+
 	cmd     *command
 	inFile  *os.File
 	outFile *os.File
@@ -34,6 +43,9 @@ type Env struct {
 
 	StatExecs    uint64
 	StatRestarts uint64
+	// Time spent serializing programs and waiting for executor, in nanoseconds.
+	StatSerializeTime uint64
+	StatExecTime      uint64
 }
 
 const (
@@ -44,6 +56,8 @@ const (
 	FlagDedupCover                           // deduplicate coverage in executor
 	FlagSandboxSetuid                        // impersonate nobody user
 	FlagSandboxNamespace                     // use namespaces for sandboxing
+	FlagDangerous                            // don't block calls that can destroy the test environment (see executor.cc)
+	FlagEnableTun                            // set up a tap interface for packet injection (syz_emit_ethernet)
 )
 
 var (
@@ -52,6 +66,8 @@ var (
 	flagCover    = flag.Bool("cover", true, "collect coverage")
 	flagSandbox  = flag.String("sandbox", "setuid", "sandbox for fuzzing (none/setuid/namespace)")
 	flagDebug    = flag.Bool("debug", false, "debug output from executor")
+	flagDanger   = flag.Bool("dangerous", false, "don't block calls that can destroy the test environment (reboot, kexec, modules, etc)")
+	flagTun      = flag.Bool("tun", true, "set up a tap interface for packet injection (requires CONFIG_TUN)")
 	// Executor protects against most hangs, so we use quite large timeout here.
 	// Executor can be slow due to global locks in namespaces and other things,
 	// so let's better wait than report false misleading crashes.
@@ -82,6 +98,12 @@ func DefaultFlags() (uint64, time.Duration, error) {
 	if *flagDebug {
 		flags |= FlagDebug
 	}
+	if *flagDanger {
+		flags |= FlagDangerous
+	}
+	if *flagTun {
+		flags |= FlagEnableTun
+	}
 	return flags, *flagTimeout, nil
 }
 
@@ -154,16 +176,117 @@ func (env *Env) Close() error {
 // output: process output
 // cov: per-call coverage, len(cov) == len(p.Calls)
 // failed: true if executor has detected a kernel bug
-// hanged: program hanged and was killed
+// hanged: program hanged and could not be killed (no coverage is returned),
+// or the executor did not answer and was killed (then err0 is set)
 // err0: failed to start process, or executor has detected a logical error
 func (env *Env) Exec(p *prog.Prog) (output []byte, cov [][]uint32, errnos []int, failed, hanged bool, err0 error) {
+	var restart bool
+	output, failed, hanged, restart, err0 = env.run(p, &execRequest{})
+	if err0 != nil || restart || env.flags&FlagCover == 0 || p == nil {
+		return
+	}
+	// Read out coverage information.
+	cov = make([][]uint32, len(p.Calls))
+	errnos, _, err0 = env.readOutput(p, func(callIndex, coverSize uint32, r *bytes.Reader) error {
+		cov1 := make([]uint32, coverSize)
+		if err := binary.Read(r, binary.LittleEndian, cov1); err != nil {
+			return fmt.Errorf("failed to read output coverage: call %v: %v", callIndex, err)
+		}
+		cov[callIndex] = cov1
+		return nil
+	})
+	return
+}
+
+// ExecComps executes program p collecting operands of comparisons executed by kernel
+// (KCOV_TRACE_CMP, requires CONFIG_KCOV_ENABLE_COMPARISONS) instead of coverage.
+// comps: per-call comparison operands, len(comps) == len(p.Calls),
+// the rest of results are the same as for Exec.
+func (env *Env) ExecComps(p *prog.Prog) (output []byte, comps []prog.CompMap, failed, hanged bool, err0 error) {
+	if env.flags&FlagCover == 0 {
+		err0 = fmt.Errorf("comparisons can't be collected without coverage")
+		return
+	}
+	var restart bool
+	output, failed, hanged, restart, err0 = env.run(p, &execRequest{flags: execFlagCollectComps})
+	if err0 != nil || restart {
+		return
+	}
+	comps = make([]prog.CompMap, len(p.Calls))
+	_, _, err0 = env.readOutput(p, func(callIndex, ncomps uint32, r *bytes.Reader) error {
+		m := make(prog.CompMap)
+		for j := uint32(0); j < ncomps; j++ {
+			var comp [5]uint32 // type, arg1, arg2 (64-bit operands are split into halves)
+			if err := binary.Read(r, binary.LittleEndian, &comp); err != nil {
+				return fmt.Errorf("failed to read output comparisons: call %v: %v", callIndex, err)
+			}
+			arg1 := uintptr(uint64(comp[1]) | uint64(comp[2])<<32)
+			arg2 := uintptr(uint64(comp[3]) | uint64(comp[4])<<32)
+			// arg1 is a compile-time constant in const comparisons,
+			// only arg2 can come from the program.
+			m.AddComp(arg2, arg1)
+			if comp[0]&kcovCmpConst == 0 {
+				m.AddComp(arg1, arg2)
+			}
+		}
+		comps[callIndex] = m
+		return nil
+	})
+	return
+}
+
+// ExecFault executes program p failing the nth (0-based) fault site hit by call
+// (fault injection, requires CONFIG_FAULT_INJECTION and /proc/thread-self/fail-nth).
+// injected: the fault was actually injected, false means that the call has less than nth+1
+// fault sites; the rest of results are the same as for Exec.
+func (env *Env) ExecFault(p *prog.Prog, call, nth int) (output []byte, cov [][]uint32, errnos []int, injected, failed, hanged bool, err0 error) {
+	var restart bool
+	req := &execRequest{flags: execFlagInjectFault, faultCall: uint64(call), faultNth: uint64(nth)}
+	output, failed, hanged, restart, err0 = env.run(p, req)
+	if err0 != nil || restart || env.flags&FlagCover == 0 {
+		return
+	}
+	cov = make([][]uint32, len(p.Calls))
+	var faults []bool
+	errnos, faults, err0 = env.readOutput(p, func(callIndex, coverSize uint32, r *bytes.Reader) error {
+		cov1 := make([]uint32, coverSize)
+		if err := binary.Read(r, binary.LittleEndian, cov1); err != nil {
+			return fmt.Errorf("failed to read output coverage: call %v: %v", callIndex, err)
+		}
+		cov[callIndex] = cov1
+		return nil
+	})
+	injected = err0 == nil && faults[call]
+	return
+}
+
+// execRequest is per-program parameters sent to executor over the control pipe.
+type execRequest struct {
+	flags     uint64
+	faultCall uint64
+	faultNth  uint64
+	timeout   uint64 // program timeout in ms
+}
+
+const (
+	execFlagCollectComps = uint64(1) << iota // collect comparisons instead of coverage
+	execFlagInjectFault                      // inject fault into execRequest.faultCall
+	execFlagNoCollide                        // don't re-execute the program in collide mode
+)
+
+const kcovCmpConst = 1 // KCOV_CMP_CONST bit of comparison type
+
+// run executes program p. restart is set if the executor process must be restarted.
+func (env *Env) run(p *prog.Prog, req *execRequest) (output []byte, failed, hanged, restart bool, err0 error) {
 	if p != nil {
 		// Copy-in serialized program.
+		start := time.Now()
 		progData := p.SerializeForExec()
 		if len(progData) > len(env.In) {
 			panic("program is too long")
 		}
 		copy(env.In, progData)
+		atomic.AddUint64(&env.StatSerializeTime, uint64(time.Since(start)))
 	}
 	if env.flags&FlagCover != 0 {
 		// Zero out the first word (ncmd), so that we don't have garbage there
@@ -181,72 +304,57 @@ func (env *Env) Exec(p *prog.Prog) (output []byte, cov [][]uint32, errnos []int,
 			return
 		}
 	}
-	var restart bool
-	output, failed, hanged, restart, err0 = env.cmd.exec()
+	if env.NoCollide {
+		req.flags |= execFlagNoCollide
+	}
+	req.timeout = uint64(env.ProgramTimeout / time.Millisecond)
+	start := time.Now()
+	output, failed, hanged, restart, err0 = env.cmd.exec(env.DropCaps, req)
+	atomic.AddUint64(&env.StatExecTime, uint64(time.Since(start)))
 	if err0 != nil || restart {
 		env.cmd.close()
 		env.cmd = nil
-		return
 	}
+	return
+}
 
-	if env.flags&FlagCover == 0 || p == nil {
-		return
-	}
-	// Read out coverage information.
+// readOutput parses per-call records of executor output and returns errnos of calls
+// and whether a fault was injected into calls. f reads the size elements of the call
+// record payload from r.
+func (env *Env) readOutput(p *prog.Prog, f func(callIndex, size uint32, r *bytes.Reader) error) ([]int, []bool, error) {
 	r := bytes.NewReader(env.Out)
 	var ncmd uint32
 	if err := binary.Read(r, binary.LittleEndian, &ncmd); err != nil {
-		err0 = fmt.Errorf("failed to read output coverage: %v", err)
-		return
+		return nil, nil, fmt.Errorf("failed to read output coverage: %v", err)
 	}
-	cov = make([][]uint32, len(p.Calls))
-	errnos = make([]int, len(p.Calls))
+	errnos := make([]int, len(p.Calls))
 	for i := range errnos {
 		errnos[i] = -1 // not executed
 	}
+	faults := make([]bool, len(p.Calls))
 	for i := uint32(0); i < ncmd; i++ {
-		var callIndex, callNum, errno, coverSize, pc uint32
-		if err := binary.Read(r, binary.LittleEndian, &callIndex); err != nil {
-			err0 = fmt.Errorf("failed to read output coverage: %v", err)
-			return
+		var hdr [5]uint32 // call index, call num, errno, fault injected, size
+		if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+			return nil, nil, fmt.Errorf("failed to read output coverage: %v", err)
 		}
-		if err := binary.Read(r, binary.LittleEndian, &callNum); err != nil {
-			err0 = fmt.Errorf("failed to read output coverage: %v", err)
-			return
+		callIndex, callNum, errno, fault, size := hdr[0], hdr[1], hdr[2], hdr[3], hdr[4]
+		if int(callIndex) >= len(p.Calls) {
+			return nil, nil, fmt.Errorf("failed to read output coverage: expect index %v, got %v", i, callIndex)
 		}
-		if err := binary.Read(r, binary.LittleEndian, &errno); err != nil {
-			err0 = fmt.Errorf("failed to read output errno: %v", err)
-			return
-		}
-		if err := binary.Read(r, binary.LittleEndian, &coverSize); err != nil {
-			err0 = fmt.Errorf("failed to read output coverage: %v", err)
-			return
-		}
-		if int(callIndex) > len(cov) {
-			err0 = fmt.Errorf("failed to read output coverage: expect index %v, got %v", i, callIndex)
-			return
-		}
-		if cov[callIndex] != nil {
-			err0 = fmt.Errorf("failed to read output coverage: double coverage for call %v", callIndex)
-			return
+		if errnos[callIndex] != -1 {
+			return nil, nil, fmt.Errorf("failed to read output coverage: double coverage for call %v", callIndex)
 		}
 		c := p.Calls[callIndex]
 		if num := c.Meta.ID; uint32(num) != callNum {
-			err0 = fmt.Errorf("failed to read output coverage: call %v: expect syscall %v, got %v, executed %v", callIndex, num, callNum, ncmd)
-			return
+			return nil, nil, fmt.Errorf("failed to read output coverage: call %v: expect syscall %v, got %v, executed %v", callIndex, num, callNum, ncmd)
 		}
-		cov1 := make([]uint32, coverSize)
-		for j := uint32(0); j < coverSize; j++ {
-			if err := binary.Read(r, binary.LittleEndian, &pc); err != nil {
-				err0 = fmt.Errorf("failed to read output coverage: expect index %v, got %v", i, callIndex)
-				return
-			}
-			cov1[j] = pc
+		if err := f(callIndex, size, r); err != nil {
+			return nil, nil, err
 		}
-		cov[callIndex] = cov1
 		errnos[callIndex] = int(errno)
+		faults[callIndex] = fault != 0
 	}
-	return
+	return errnos, faults, nil
 }
 
 func createMapping(size int) (f *os.File, mem []byte, err error) {
@@ -412,17 +520,27 @@ func (c *command) kill() {
 	syscall.Kill(c.cmd.Process.Pid, syscall.SIGKILL)
 }
 
-func (c *command) exec() (output []byte, failed, hanged, restart bool, err0 error) {
-	var tmp [1]byte
-	if _, err := c.outwp.Write(tmp[:]); err != nil {
+func (c *command) exec(dropCaps uint64, req *execRequest) (output []byte, failed, hanged, restart bool, err0 error) {
+	var data [40]byte
+	binary.LittleEndian.PutUint64(data[0:], dropCaps)
+	binary.LittleEndian.PutUint64(data[8:], req.flags)
+	binary.LittleEndian.PutUint64(data[16:], req.faultCall)
+	binary.LittleEndian.PutUint64(data[24:], req.faultNth)
+	binary.LittleEndian.PutUint64(data[32:], req.timeout)
+	if _, err := c.outwp.Write(data[:]); err != nil {
 		output, _ = ioutil.ReadAll(c.rp)
 		err0 = fmt.Errorf("failed to write control pipe: %v", err)
 		return
 	}
 	done := make(chan bool)
 	hang := make(chan bool)
+	// The executor kills the program after req.timeout and waits for it to die for up to 5 seconds.
+	timeout := c.timeout
+	if min := time.Duration(req.timeout)*time.Millisecond + 7*time.Second; timeout < min {
+		timeout = min
+	}
 	go func() {
-		t := time.NewTimer(c.timeout)
+		t := time.NewTimer(timeout)
 		select {
 		case <-t.C:
 			c.kill()
@@ -433,10 +551,13 @@ func (c *command) exec() (output []byte, failed, hanged, restart bool, err0 erro
 		}
 	}()
 	//!!! handle c.rp overflow
-	_, readErr := c.inrp.Read(tmp[:])
+	var reply [1]byte
+	_, readErr := c.inrp.Read(reply[:])
 	close(done)
 	if readErr == nil {
 		<-hang
+		hanged = reply[0] == 1
+		restart = hanged
 		return
 	}
 	err0 = fmt.Errorf("executor did not answer")
@@ -0,0 +1,113 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package manager implements syz-manager: it boots VMs with syz-fuzzer, maintains the corpus,
+// saves and reproduces crashes and serves the web UI. Other Go tools can embed a fuzzing
+// campaign with Start instead of running the syz-manager binary and parsing its output.
+package manager
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/syzkaller/config"
+)
+
+// Options are campaign parameters that are not part of the config.
+type Options struct {
+	Verbosity   int           // log verbosity
+	ConfigFile  string        // file the config was parsed from, re-read on SIGHUP (optional)
+	Bench       string        // write machine-readable progress records to this file (optional)
+	BenchPeriod time.Duration // period of Bench records (default: 1 minute)
+	Signals     bool          // reload config on SIGHUP, restart on SIGUSR1 and stop on SIGINT
+	Reexec      bool          // re-execute os.Args on graceful restart instead of just stopping
+	Seed        int64         // base seed of fuzzer random number generators (0 - random)
+}
+
+// Campaign is a running fuzzing campaign.
+type Campaign struct {
+	mgr  *Manager
+	done chan bool
+}
+
+// Crashes are sent to Subscribe channels without blocking,
+// they are dropped if the subscriber does not keep up.
+const subscriberBuffer = 100
+
+// Start loads the corpus, starts the web UI and the VMs and returns the running campaign.
+// cfg, syscalls and suppressions are usually created with config.Parse.
+func Start(cfg *config.Config, syscalls map[int]bool, suppressions []*regexp.Regexp, opts *Options) (*Campaign, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	if opts.BenchPeriod == 0 {
+		opts.BenchPeriod = time.Minute
+	}
+	mgr, err := newManager(cfg, syscalls, suppressions, opts)
+	if err != nil {
+		return nil, err
+	}
+	c := &Campaign{mgr, make(chan bool)}
+	go func() {
+		mgr.run()
+		close(c.done)
+	}()
+	return c, nil
+}
+
+// Stop stops fuzzing, Wait returns when the campaign is finished.
+func (c *Campaign) Stop(reason string) {
+	c.mgr.stopFuzzing(reason)
+}
+
+// Wait waits until the campaign is stopped (with Stop, Max_Run_Time/Max_Execs or from the web UI),
+// the corpus is minimized and the summary is written. It returns the error that stopped
+// the campaign (e.g. failure to write the corpus database), or nil.
+func (c *Campaign) Wait() error {
+	<-c.done
+	c.mgr.errMu.Lock()
+	defer c.mgr.errMu.Unlock()
+	return c.mgr.err
+}
+
+// Subscribe returns a channel that receives all crashes that pass triage and suppressions.
+func (c *Campaign) Subscribe() <-chan *Report {
+	ch := make(chan *Report, subscriberBuffer)
+	c.mgr.mu.Lock()
+	c.mgr.subs = append(c.mgr.subs, ch)
+	c.mgr.mu.Unlock()
+	return ch
+}
+
+// Corpus returns serialized corpus programs.
+func (c *Campaign) Corpus() [][]byte {
+	c.mgr.mu.Lock()
+	defer c.mgr.mu.Unlock()
+	res := make([][]byte, len(c.mgr.corpus))
+	for i, inp := range c.mgr.corpus {
+		res[i] = inp.Prog
+	}
+	return res
+}
+
+// Stats returns a snapshot of the manager stats shown on the main page (e.g. "exec total").
+func (c *Campaign) Stats() map[string]uint64 {
+	c.mgr.mu.Lock()
+	defer c.mgr.mu.Unlock()
+	res := make(map[string]uint64, len(c.mgr.stats))
+	for k, v := range c.mgr.stats {
+		res[k] = v
+	}
+	return res
+}
+
+// publishCrash sends rep to subscribers, must be called with mgr.mu held.
+func (mgr *Manager) publishCrash(rep *Report) {
+	for _, ch := range mgr.subs {
+		rep1 := *rep
+		select {
+		case ch <- &rep1:
+		default:
+		}
+	}
+}
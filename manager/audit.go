@@ -0,0 +1,54 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditLog appends a record of every significant manager decision to workdir/events.jsonl
+// (newline-delimited JSON): inputs accepted into or rejected from the corpus, crashes recorded
+// or suppressed, VM restarts with the reason, reproduction attempts, crash storms, config reloads.
+// It allows to answer "why did the fuzzer do X" after the fact.
+type AuditLog struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+type AuditEvent struct {
+	Time   time.Time
+	Type   string // e.g. "input accepted", "crash", "vm restart", "repro finished"
+	VM     string `json:",omitempty"`
+	Title  string `json:",omitempty"` // crash title
+	Prog   string `json:",omitempty"` // program hash
+	Call   string `json:",omitempty"`
+	Cover  int    `json:",omitempty"` // number of new PCs
+	Reason string `json:",omitempty"`
+}
+
+func newAuditLog(filename string) (*AuditLog, error) {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// audit records an event, it can be called with or without mgr.mu.
+func (mgr *Manager) audit(ev *AuditEvent) {
+	a := mgr.auditLog
+	if a == nil {
+		return
+	}
+	ev.Time = time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.enc.Encode(ev); err != nil {
+		mgr.logf(0, "failed to write audit event: %v", err)
+	}
+}
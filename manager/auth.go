@@ -0,0 +1,111 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// httpAuth wraps the web UI handler with authentication (cfg.Http_Auth) and read-only mode
+// (cfg.Http_Read_Only). In basic mode users are checked against cfg.Http_Users.
+// In proxy mode the manager sits behind an OAuth proxy (e.g. oauth2_proxy) that authenticates
+// users and passes the identity in X-Forwarded-Email/X-Forwarded-User headers; since the headers
+// are trivially spoofable, only requests from loopback are accepted.
+// All state-changing handlers require POST, so read-only mode rejects everything except GET/HEAD.
+// Browsers send credentials automatically, so with authentication enabled state-changing
+// requests must come from the web UI itself (see sameOrigin) to prevent cross-site request forgery.
+func (mgr *Manager) httpAuth(h http.Handler) http.Handler {
+	users := make(map[string]string)
+	for _, u := range mgr.cfg.Http_Users {
+		colon := strings.IndexByte(u, ':')
+		if colon == -1 {
+			users[u] = ""
+		} else {
+			users[u[:colon]] = u[colon+1:]
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := mgr.httpUser(r, users)
+		if err != nil {
+			mgr.logf(1, "rejecting http request from %v: %v", r.RemoteAddr, err)
+			if mgr.cfg.Http_Auth == "basic" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="syzkaller"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			} else {
+				http.Error(w, "forbidden", http.StatusForbidden)
+			}
+			return
+		}
+		if r.Method != "GET" && r.Method != "HEAD" {
+			if mgr.cfg.Http_Read_Only {
+				http.Error(w, "the manager web UI is read-only", http.StatusForbidden)
+				return
+			}
+			if mgr.cfg.Http_Auth != "none" && !sameOrigin(r) {
+				mgr.logf(0, "rejecting cross-origin %v %v by %q", r.Method, r.URL.Path, user)
+				http.Error(w, "cross-origin request", http.StatusForbidden)
+				return
+			}
+			mgr.audit(&AuditEvent{Type: "http request", Reason: fmt.Sprintf("%v %v by %q", r.Method, r.URL.Path, user)})
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// httpUser returns the authenticated user of the request (empty if auth is disabled).
+func (mgr *Manager) httpUser(r *http.Request, users map[string]string) (string, error) {
+	switch mgr.cfg.Http_Auth {
+	case "basic":
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			return "", fmt.Errorf("no credentials")
+		}
+		want, known := users[user]
+		if !known || subtle.ConstantTimeCompare([]byte(pass), []byte(want)) != 1 {
+			return "", fmt.Errorf("bad credentials for %q", user)
+		}
+		return user, nil
+	case "proxy":
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			return "", fmt.Errorf("request does not come from a local proxy")
+		}
+		user := r.Header.Get("X-Forwarded-Email")
+		if user == "" {
+			user = r.Header.Get("X-Forwarded-User")
+		}
+		if user == "" {
+			return "", fmt.Errorf("no user identity passed by proxy")
+		}
+		if _, ok := users[user]; len(users) != 0 && !ok {
+			return "", fmt.Errorf("user %q is not allowed", user)
+		}
+		return user, nil
+	default:
+		return "", nil
+	}
+}
+
+// sameOrigin checks that the request was issued by a page served by the manager.
+// Browsers send Origin (or at least Referer) with POST requests; requests without both
+// come from non-browser clients (e.g. curl corpus upload) and can't be forged cross-site.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
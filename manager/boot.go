@@ -0,0 +1,90 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// If VMs fail to boot (or crash before the fuzzer starts executing programs),
+// the target kernel is most likely broken. Instead of rebooting VMs at full speed
+// we back off exponentially and show the last boot failure on the main page.
+const (
+	bootBackoffMin     = 10 * time.Second
+	bootBackoffMax     = 10 * time.Minute
+	bootBrokenFailures = 3 // consecutive boot failures after which the kernel is considered broken
+	bootErrorMax       = 64 << 10
+)
+
+// BootState tracks consecutive boot failures across all instances.
+type BootState struct {
+	Failures int // consecutive boot failures, reset when any instance starts fuzzing
+	Error    string
+	Time     time.Time
+	Instance string
+}
+
+func (bs *BootState) broken() bool {
+	return bs.Failures >= bootBrokenFailures
+}
+
+// bootFailed records a boot failure of instance name and returns how long to wait before the next boot.
+func (mgr *Manager) bootFailed(name string, err error) time.Duration {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	bs := &mgr.boot
+	bs.Failures++
+	bs.Error = err.Error()
+	if len(bs.Error) > bootErrorMax {
+		bs.Error = bs.Error[len(bs.Error)-bootErrorMax:]
+	}
+	bs.Time = time.Now()
+	bs.Instance = name
+	mgr.stats["boot failures"]++
+	if bs.Failures == bootBrokenFailures {
+		mgr.logf(0, "target kernel is broken: %v consecutive boot failures", bs.Failures)
+	}
+	// Keep the last failure on disk, the console output is not buried in the log then.
+	fn := filepath.Join(mgr.cfg.Workdir, "boot-failure.log")
+	if err := ioutil.WriteFile(fn, []byte(bs.Error), 0660); err != nil {
+		mgr.logf(0, "failed to write %v: %v", fn, err)
+	}
+	return bootBackoff(bs.Failures)
+}
+
+// bootSucceeded resets the backoff after an instance has started fuzzing.
+// Must be called under mgr.mu.
+func (mgr *Manager) bootSucceeded() {
+	if mgr.boot.Failures == 0 {
+		return
+	}
+	if mgr.boot.broken() {
+		mgr.logf(0, "target kernel is working again")
+	}
+	mgr.boot.Failures = 0
+}
+
+// bootBackoff returns delay after the given number of consecutive boot failures:
+// bootBackoffMin doubled on every failure, capped at bootBackoffMax.
+func bootBackoff(failures int) time.Duration {
+	delay := bootBackoffMin
+	for i := 1; i < failures && delay < bootBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > bootBackoffMax {
+		delay = bootBackoffMax
+	}
+	return delay
+}
+
+func firstLine(s string) string {
+	if nl := strings.IndexByte(s, '\n'); nl != -1 {
+		return s[:nl]
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/google/syzkaller/cover"
+	. "github.com/google/syzkaller/rpctype"
+	"github.com/google/syzkaller/sys"
+)
+
+// addCallStats accumulates per-syscall stats reported by a fuzzer, must be called under mgr.mu.
+func (mgr *Manager) addCallStats(stats map[string]CallStats) {
+	for name, cs := range stats {
+		st := mgr.callStats[name]
+		if st == nil {
+			st = new(CallStats)
+			mgr.callStats[name] = st
+		}
+		st.Execs += cs.Execs
+		st.Errors += cs.Errors
+		st.NewCover += cs.NewCover
+	}
+}
+
+// httpCalls shows per-syscall execution stats for all enabled syscalls.
+// Corpus inputs are attributed to base syscalls (e.g. open for open$dir),
+// so inputs and cover are shown for the base syscall. Syscalls without corpus inputs
+// are highlighted: either descriptions are wrong or the syscall is not reachable.
+func (mgr *Manager) httpCalls(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	calls := make(map[string]*UICallStats)
+	for id := range mgr.syscalls {
+		c := sys.Calls[id]
+		calls[c.Name] = &UICallStats{
+			Name:        c.Name,
+			CallName:    c.CallName,
+			Unsupported: mgr.targetCalls != nil && !mgr.targetCalls[c.Name],
+		}
+	}
+	inputs := make(map[string]int)
+	callCover := make(map[string]cover.Cover)
+	for _, inp := range mgr.corpus {
+		inputs[inp.Call]++
+		callCover[inp.Call] = cover.Union(callCover[inp.Call], cover.Cover(inp.Cover))
+	}
+	var data []*UICallStats
+	for name, cs := range calls {
+		cs.Inputs = inputs[cs.CallName]
+		cs.Cover = len(callCover[cs.CallName])
+		if st := mgr.callStats[name]; st != nil {
+			cs.Execs = st.Execs
+			cs.Errors = st.Errors
+			cs.NewCover = st.NewCover
+			if st.Execs != 0 {
+				cs.ErrorRate = int(100 * st.Errors / st.Execs)
+			}
+		}
+		data = append(data, cs)
+	}
+	sort.Sort(UICallStatsArray(data))
+
+	if err := callsTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+type UICallStats struct {
+	Name        string
+	CallName    string
+	Unsupported bool // not supported on target, as reported by fuzzers
+	Execs       uint64
+	Errors      uint64
+	ErrorRate   int // percent of executions that returned an error
+	NewCover    uint64
+	Inputs      int // corpus inputs of the base syscall
+	Cover       int // corpus cover of the base syscall
+}
+
+type UICallStatsArray []*UICallStats
+
+func (a UICallStatsArray) Len() int           { return len(a) }
+func (a UICallStatsArray) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a UICallStatsArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+var callsTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller syscalls</title>
+</head>
+<body>
+<table>
+	<tr>
+		<th>Syscall</th>
+		<th>Execs</th>
+		<th>Errors</th>
+		<th>New cover</th>
+		<th>Inputs (base)</th>
+		<th>Cover (base)</th>
+	</tr>
+	{{range $c := $}}
+	<tr{{if not $c.Inputs}} style="background: rgb(255, 220, 220)"{{end}}>
+		<td>{{$c.Name}}{{if $c.Unsupported}} (unsupported){{end}}</td>
+		<td>{{$c.Execs}}</td>
+		<td>{{$c.Errors}}{{if $c.Execs}} ({{$c.ErrorRate}}%){{end}}</td>
+		<td>{{$c.NewCover}}</td>
+		<td><a href='/corpus?call={{$c.CallName}}'>{{$c.Inputs}}</a></td>
+		<td><a href='/cover?call={{$c.CallName}}'>{{$c.Cover}}</a></td>
+	</tr>
+	{{end}}
+</table>
+</body></html>
+`))
@@ -0,0 +1,88 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"sort"
+
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/sys"
+)
+
+// Candidates (programs loaded from persistent corpus, seeds, etc) are not
+// distributed to fuzzers in FIFO order. Instead they are ordered by a static
+// estimation of how likely they are to be useful on the target:
+// programs which calls are all enabled and supported by the target kernel
+// (including calls of subsystems enabled in the kernel config, see kconfig.go) go first; programs that mostly consist of calls that can't be executed
+// on the target go last (they are still triaged eventually, because
+// support detection is not perfect).
+
+// reachableCalls returns set of calls that fuzzers can execute on the target.
+// Must be called with mgr.mu held.
+func (mgr *Manager) reachableCalls() map[*sys.Call]bool {
+	if mgr.reachable != nil {
+		return mgr.reachable
+	}
+	enabled := make(map[*sys.Call]bool)
+	for id := range mgr.syscalls {
+		c := sys.Calls[id]
+		if mgr.targetCalls != nil && !mgr.targetCalls[c.Name] {
+			continue
+		}
+		if !configEnabled(mgr.kernelConfig, c) {
+			continue
+		}
+		enabled[c] = true
+	}
+	mgr.reachable = sys.TransitivelyEnabledCalls(enabled)
+	return mgr.reachable
+}
+
+// candidateScore returns fraction of calls in the program that are reachable on the target.
+func candidateScore(reachable map[*sys.Call]bool, data []byte) float64 {
+	p, err := prog.Deserialize(data)
+	if err != nil || len(p.Calls) == 0 {
+		return 0
+	}
+	n := 0
+	for _, c := range p.Calls {
+		if reachable[c.Meta] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(p.Calls))
+}
+
+// prioritizeCandidates sorts candidates so that the most promising programs are handed out first.
+// Must be called with mgr.mu held.
+func (mgr *Manager) prioritizeCandidates() {
+	reachable := mgr.reachableCalls()
+	a := &candidateArray{
+		progs:  mgr.candidates,
+		scores: make([]float64, len(mgr.candidates)),
+	}
+	for i, data := range mgr.candidates {
+		a.scores[i] = candidateScore(reachable, data)
+	}
+	// Poll takes candidates from the end, so the best ones must be last.
+	sort.Stable(a)
+}
+
+type candidateArray struct {
+	progs  [][]byte
+	scores []float64
+}
+
+func (a *candidateArray) Len() int { return len(a.progs) }
+func (a *candidateArray) Less(i, j int) bool {
+	if a.scores[i] != a.scores[j] {
+		return a.scores[i] < a.scores[j]
+	}
+	// Shorter programs are faster to triage.
+	return len(a.progs[i]) > len(a.progs[j])
+}
+func (a *candidateArray) Swap(i, j int) {
+	a.progs[i], a.progs[j] = a.progs[j], a.progs[i]
+	a.scores[i], a.scores[j] = a.scores[j], a.scores[i]
+}
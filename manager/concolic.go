@@ -0,0 +1,103 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync/atomic"
+	"time"
+
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Concolic assistance: fuzzers running with comparison hints report programs
+// whose call executed comparisons, but none of the operands matches its arguments
+// (so hints can't help, the checked value is derived from the arguments in a
+// non-trivial way). concolicLoop periodically sends these programs to an external
+// solver service at cfg.Concolic_Addr (Concolic.Solve RPC, see rpctype.ConcolicSolveArgs)
+// and adds the solved programs to candidates.
+const maxConcolicQueue = 100
+
+// addBlocked must be called under mgr.mu.
+func (mgr *Manager) addBlocked(blocked []BlockedProg) {
+	if mgr.cfg.Concolic_Addr == "" {
+		return
+	}
+	for _, b := range blocked {
+		key := fmt.Sprintf("%v-%v", hashString(b.Prog), b.Call)
+		if mgr.concolicSeen[key] || len(mgr.concolicQueue) >= maxConcolicQueue {
+			continue
+		}
+		mgr.concolicSeen[key] = true
+		mgr.concolicQueue = append(mgr.concolicQueue, b)
+		mgr.stats["concolic blocked"]++
+	}
+}
+
+func (mgr *Manager) concolicLoop() {
+	var conn *rpc.Client
+	for atomic.LoadUint32(&mgr.shutdown) == 0 {
+		time.Sleep(time.Minute)
+		mgr.mu.Lock()
+		blocked := mgr.concolicQueue
+		mgr.concolicQueue = nil
+		mgr.mu.Unlock()
+		if len(blocked) == 0 {
+			continue
+		}
+
+		if conn == nil {
+			var err error
+			conn, err = jsonrpc.Dial("tcp", mgr.cfg.Concolic_Addr)
+			if err != nil {
+				mgr.logf(0, "failed to connect to concolic service at %v: %v", mgr.cfg.Concolic_Addr, err)
+				conn = nil
+				mgr.returnBlocked(blocked)
+				continue
+			}
+		}
+		a := &ConcolicSolveArgs{
+			Name:    mgr.cfg.Name,
+			Key:     mgr.cfg.Concolic_Key,
+			Blocked: blocked,
+		}
+		r := new(ConcolicSolveRes)
+		if err := conn.Call("Concolic.Solve", a, r); err != nil {
+			mgr.logf(0, "concolic solve failed: %v", err)
+			conn.Close()
+			conn = nil
+			mgr.returnBlocked(blocked)
+			continue
+		}
+
+		mgr.mu.Lock()
+		added := 0
+		for _, data := range r.Progs {
+			if !mgr.enabledProgram(data) {
+				continue
+			}
+			mgr.importProvenance(data, "concolic", "", "")
+			mgr.candidates = append(mgr.candidates, data)
+			added++
+		}
+		if added != 0 {
+			mgr.stats["concolic new inputs"] += uint64(added)
+			mgr.prioritizeCandidates()
+		}
+		mgr.mu.Unlock()
+		mgr.logf(0, "concolic: sent %v blocked programs, got %v, added %v", len(blocked), len(r.Progs), added)
+	}
+}
+
+// returnBlocked queues programs that were not sent to the concolic service for the next round.
+func (mgr *Manager) returnBlocked(blocked []BlockedProg) {
+	mgr.mu.Lock()
+	mgr.concolicQueue = append(blocked, mgr.concolicQueue...)
+	if len(mgr.concolicQueue) > maxConcolicQueue {
+		mgr.concolicQueue = mgr.concolicQueue[:maxConcolicQueue]
+	}
+	mgr.mu.Unlock()
+}
@@ -0,0 +1,53 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"sort"
+	"time"
+
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// A freshly started fuzzer does not receive the corpus in the order programs were triaged.
+// Instead existing corpus programs are streamed the most recently discovered first
+// (they are the most likely to lead to new coverage), and if cfg.Fresh_Corpus is set
+// only that many of them are sent at all. Programs added to the corpus after the fuzzer
+// has connected are sent before the remaining old ones.
+
+// freshCorpus returns corpus programs to send to a new fuzzer in priority order.
+// Must be called with mgr.mu held.
+func (mgr *Manager) freshCorpus() []RpcInput {
+	a := &inputArray{
+		inputs: append([]RpcInput{}, mgr.corpus...),
+		times:  make([]time.Time, len(mgr.corpus)),
+	}
+	for i, inp := range a.inputs {
+		a.times[i] = mgr.corpusDB.Records[hashString(inp.Prog)].Time
+	}
+	sort.Stable(a)
+	if mgr.cfg.Fresh_Corpus != 0 && len(a.inputs) > mgr.cfg.Fresh_Corpus {
+		a.inputs = a.inputs[:mgr.cfg.Fresh_Corpus]
+	}
+	return a.inputs
+}
+
+// inputArray sorts inputs by discovery time, the most recent first,
+// and then by signal size, the largest first.
+type inputArray struct {
+	inputs []RpcInput
+	times  []time.Time
+}
+
+func (a *inputArray) Len() int { return len(a.inputs) }
+func (a *inputArray) Less(i, j int) bool {
+	if !a.times[i].Equal(a.times[j]) {
+		return a.times[i].After(a.times[j])
+	}
+	return len(a.inputs[i].Cover) > len(a.inputs[j].Cover)
+}
+func (a *inputArray) Swap(i, j int) {
+	a.inputs[i], a.inputs[j] = a.inputs[j], a.inputs[i]
+	a.times[i], a.times[j] = a.times[j], a.times[i]
+}
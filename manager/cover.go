@@ -0,0 +1,531 @@
+// Copyright 2015 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/syzkaller/cover"
+)
+
+type LineInfo struct {
+	file string
+	line int
+	fn   string
+	pc   uint32
+}
+
+func generateCoverHtml(w io.Writer, vmlinux string, cov []uint32) error {
+	files, err := coverFiles(vmlinux, cov)
+	if err != nil {
+		return err
+	}
+	var d templateData
+	for i, fc := range files {
+		lines, err := parseFile(fc.path)
+		if err != nil {
+			return err
+		}
+		anchors := make(map[int]bool)
+		for _, fn := range fc.Funcs {
+			anchors[fn.Line] = true
+		}
+		covered := fc.Lines
+		var buf bytes.Buffer
+		for j, ln := range lines {
+			if anchors[j+1] {
+				fmt.Fprintf(&buf, "<a id='file%v_line%v'></a>", i, j+1)
+			}
+			if len(covered) > 0 && covered[0] == j+1 {
+				buf.Write([]byte("<span id='covered'>"))
+				buf.Write(ln)
+				buf.Write([]byte("</span>\n"))
+				covered = covered[1:]
+			} else {
+				buf.Write(ln)
+				buf.Write([]byte("\n"))
+			}
+		}
+		d.Files = append(d.Files, &templateFile{
+			Name:     fc.Name,
+			Body:     template.HTML(buf.String()),
+			Coverage: len(fc.Lines),
+		})
+	}
+	if err := coverTemplate.Execute(w, d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// generateCoverSummaryHtml writes coverage aggregated by source directory, file and function.
+// Files link to the annotated source served by /cover, call is passed through to it.
+func generateCoverSummaryHtml(w io.Writer, vmlinux string, cov []uint32, call string) error {
+	files, err := coverFiles(vmlinux, cov)
+	if err != nil {
+		return err
+	}
+	d := &coverSummaryData{Call: call}
+	dirs := make(map[string]*coverDir)
+	maxPCs := 0
+	for _, fc := range files {
+		dir := filepath.Dir(fc.Name)
+		if dirs[dir] == nil {
+			dirs[dir] = &coverDir{Name: dir}
+			d.Dirs = append(d.Dirs, dirs[dir])
+		}
+		dirs[dir].Files++
+		dirs[dir].PCs += fc.PCs
+		if maxPCs < fc.PCs {
+			maxPCs = fc.PCs
+		}
+		sort.Sort(coverFuncArray(fc.Funcs))
+		d.Files = append(d.Files, fc)
+	}
+	maxDirPCs := 0
+	for _, dir := range d.Dirs {
+		if maxDirPCs < dir.PCs {
+			maxDirPCs = dir.PCs
+		}
+	}
+	for _, dir := range d.Dirs {
+		dir.Heat = 100 * dir.PCs / maxDirPCs
+	}
+	for _, fc := range d.Files {
+		fc.Heat = 100 * fc.PCs / maxPCs
+	}
+	sort.Sort(coverDirArray(d.Dirs))
+	sort.Sort(coverFileArray(d.Files))
+	return coverSummaryTemplate.Execute(w, d)
+}
+
+// coverFile is coverage of a single source file.
+type coverFile struct {
+	Index int    // index of the file on the /cover page
+	Name  string // file name without the common prefix
+	path  string
+	Lines []int // covered lines, sorted
+	PCs   int
+	Heat  int // PCs relative to the most covered file, in percents
+	Funcs []*coverFunc
+}
+
+type coverFunc struct {
+	Name string
+	Line int // first covered line
+	PCs  int
+}
+
+type coverDir struct {
+	Name  string
+	Files int
+	PCs   int
+	Heat  int
+}
+
+// coverFiles symbolizes cov and groups it by source files sorted by name.
+func coverFiles(vmlinux string, cov []uint32) ([]*coverFile, error) {
+	if len(cov) == 0 {
+		return nil, fmt.Errorf("No coverage data available")
+	}
+	info, prefix, err := symbolize(vmlinux, cov)
+	if err != nil {
+		return nil, err
+	}
+	if len(info) == 0 {
+		return nil, fmt.Errorf("'%s' does not have debug info (set CONFIG_DEBUG_INFO=y)", vmlinux)
+	}
+	pcs := make(map[string]map[uint32]bool)
+	funcs := make(map[string]map[string]*coverFunc)
+	for _, li := range info {
+		if pcs[li.file] == nil {
+			pcs[li.file] = make(map[uint32]bool)
+			funcs[li.file] = make(map[string]*coverFunc)
+		}
+		pcs[li.file][li.pc] = true
+		fn := funcs[li.file][li.fn]
+		if fn == nil {
+			fn = &coverFunc{Name: li.fn, Line: li.line}
+			funcs[li.file][li.fn] = fn
+		}
+		fn.PCs++
+		if fn.Line > li.line {
+			fn.Line = li.line
+		}
+	}
+	var files []*coverFile
+	for f, lines := range fileSet(info) {
+		fc := &coverFile{
+			Name:  f,
+			path:  f,
+			Lines: lines,
+			PCs:   len(pcs[f]),
+		}
+		if len(f) > len(prefix) {
+			fc.Name = f[len(prefix):]
+		}
+		for _, fn := range funcs[f] {
+			fc.Funcs = append(fc.Funcs, fn)
+		}
+		files = append(files, fc)
+	}
+	sort.Sort(coverFileNameArray(files))
+	for i, fc := range files {
+		fc.Index = i
+	}
+	return files, nil
+}
+
+func fileSet(info []LineInfo) map[string][]int {
+	files := make(map[string]map[int]struct{})
+	for _, li := range info {
+		if files[li.file] == nil {
+			files[li.file] = make(map[int]struct{})
+		}
+		files[li.file][li.line] = struct{}{}
+	}
+	res := make(map[string][]int)
+	for f, lines := range files {
+		sorted := make([]int, 0, len(lines))
+		for ln := range lines {
+			sorted = append(sorted, ln)
+		}
+		sort.Ints(sorted)
+		res[f] = sorted
+	}
+	return res
+}
+
+func parseFile(fn string) ([][]byte, error) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	htmlReplacer := strings.NewReplacer(">", "&gt;", "<", "&lt;", "&", "&amp;", "\t", "        ")
+	var lines [][]byte
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx == -1 {
+			break
+		}
+		lines = append(lines, []byte(htmlReplacer.Replace(string(data[:idx]))))
+		data = data[idx+1:]
+	}
+	if len(data) != 0 {
+		lines = append(lines, data)
+	}
+	return lines, nil
+}
+
+func getVmOffset(vmlinux string) (uint32, error) {
+	out, err := exec.Command("readelf", "-SW", vmlinux).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("readelf failed: %v\n%s", err, out)
+	}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	var addr uint32
+	for s.Scan() {
+		ln := s.Text()
+		pieces := strings.Fields(ln)
+		for i := 0; i < len(pieces); i++ {
+			if pieces[i] != "PROGBITS" {
+				continue
+			}
+			v, err := strconv.ParseUint("0x"+pieces[i+1], 0, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse addr in readelf output: %v", err)
+			}
+			if v == 0 {
+				continue
+			}
+			v32 := (uint32)(v >> 32)
+			if addr == 0 {
+				addr = v32
+			}
+			if addr != v32 {
+				return 0, fmt.Errorf("different section offsets in a single binary")
+			}
+		}
+	}
+	return addr, nil
+}
+
+func symbolize(vmlinux string, cov []uint32) ([]LineInfo, string, error) {
+	base, err := getVmOffset(vmlinux)
+	if err != nil {
+		return nil, "", err
+	}
+	cmd := exec.Command("addr2line", "-a", "-i", "-f", "-e", vmlinux)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, "", err
+	}
+	defer stdin.Close()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", err
+	}
+	defer stdout.Close()
+	if err := cmd.Start(); err != nil {
+		return nil, "", err
+	}
+	defer cmd.Wait()
+	go func() {
+		for _, pc := range cov {
+			fmt.Fprintf(stdin, "0x%x\n", cover.RestorePC(pc, base)-1)
+		}
+		stdin.Close()
+	}()
+	var info []LineInfo
+	prefix := ""
+	s := bufio.NewScanner(stdout)
+	var pc uint32
+	// With -f every frame is printed as a function name line followed by a file:line line.
+	fn, expectFunc := "", false
+	for s.Scan() {
+		ln := s.Text()
+		if len(ln) > 3 && ln[0] == '0' && ln[1] == 'x' {
+			v, err := strconv.ParseUint(ln, 0, 64)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to parse pc in addr2line output: %v", err)
+			}
+			pc = uint32(v) + 1
+			expectFunc = true
+			continue
+		}
+		if expectFunc {
+			fn, expectFunc = ln, false
+			continue
+		}
+		expectFunc = true
+		colon := strings.IndexByte(ln, ':')
+		if colon == -1 {
+			continue
+		}
+		file := ln[:colon]
+		lineStr := ln[colon+1:]
+		if space := strings.IndexByte(lineStr, ' '); space != -1 {
+			lineStr = lineStr[:space] // strip " (discriminator N)"
+		}
+		line, err := strconv.Atoi(lineStr)
+		if err != nil || pc == 0 || file == "" || file == "??" || line <= 0 {
+			continue
+		}
+		info = append(info, LineInfo{file, line, fn, pc})
+		if prefix == "" {
+			prefix = file
+		} else {
+			i := 0
+			for ; i < len(prefix) && i < len(file); i++ {
+				if prefix[i] != file[i] {
+					break
+				}
+			}
+			prefix = prefix[:i]
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, "", err
+	}
+	return info, prefix, nil
+}
+
+type templateData struct {
+	Files []*templateFile
+}
+
+type templateFile struct {
+	Name     string
+	Body     template.HTML
+	Coverage int
+}
+
+type coverSummaryData struct {
+	Call  string
+	Dirs  []*coverDir
+	Files []*coverFile
+}
+
+type coverFileNameArray []*coverFile
+
+func (a coverFileNameArray) Len() int           { return len(a) }
+func (a coverFileNameArray) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a coverFileNameArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// coverFileArray sorts files by the number of covered PCs, the most covered first.
+type coverFileArray []*coverFile
+
+func (a coverFileArray) Len() int { return len(a) }
+func (a coverFileArray) Less(i, j int) bool {
+	if a[i].PCs != a[j].PCs {
+		return a[i].PCs > a[j].PCs
+	}
+	return a[i].Name < a[j].Name
+}
+func (a coverFileArray) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+type coverFuncArray []*coverFunc
+
+func (a coverFuncArray) Len() int { return len(a) }
+func (a coverFuncArray) Less(i, j int) bool {
+	if a[i].PCs != a[j].PCs {
+		return a[i].PCs > a[j].PCs
+	}
+	return a[i].Name < a[j].Name
+}
+func (a coverFuncArray) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+type coverDirArray []*coverDir
+
+func (a coverDirArray) Len() int { return len(a) }
+func (a coverDirArray) Less(i, j int) bool {
+	if a[i].PCs != a[j].PCs {
+		return a[i].PCs > a[j].PCs
+	}
+	return a[i].Name < a[j].Name
+}
+func (a coverDirArray) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+var coverTemplate = template.Must(template.New("").Parse(
+	`
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+		<style>
+			body {
+				background: white;
+			}
+			#topbar {
+				background: black;
+				position: fixed;
+				top: 0; left: 0; right: 0;
+				height: 42px;
+				border-bottom: 1px solid rgb(70, 70, 70);
+			}
+			#nav {
+				float: left;
+				margin-left: 10px;
+				margin-top: 10px;
+			}
+			#content {
+				font-family: 'Courier New', Courier, monospace;
+				color: rgb(70, 70, 70);
+				margin-top: 50px;
+			}
+			#covered {
+				color: rgb(0, 0, 0);
+				font-weight: bold;
+			}
+		</style>
+	</head>
+	<body>
+		<div id="topbar">
+			<div id="nav">
+				<select id="files">
+				{{range $i, $f := .Files}}
+				<option value="file{{$i}}">{{$f.Name}} ({{$f.Coverage}})</option>
+				{{end}}
+				</select>
+			</div>
+		</div>
+		<div id="content">
+		{{range $i, $f := .Files}}
+		<pre class="file" id="file{{$i}}" {{if $i}}style="display: none"{{end}}>{{$f.Body}}</pre>
+		{{end}}
+		</div>
+	</body>
+	<script>
+	(function() {
+		var files = document.getElementById('files');
+		var visible = document.getElementById('file0');
+		files.addEventListener('change', onChange, false);
+		// #fileN or #fileN_lineM (as linked from /coverfiles) selects the file.
+		var hash = window.location.hash.substring(1);
+		if (hash) {
+			var file = hash.split('_')[0];
+			if (document.getElementById(file)) {
+				files.value = file;
+				onChange();
+				var anchor = document.getElementById(hash);
+				if (anchor) {
+					anchor.scrollIntoView();
+					window.scrollBy(0, -50);
+				}
+			}
+		}
+		function onChange() {
+			visible.style.display = 'none';
+			visible = document.getElementById(files.value);
+			visible.style.display = 'block';
+			window.scrollTo(0, 0);
+		}
+	})();
+	</script>
+</html>
+`))
+
+var coverSummaryTemplate = template.Must(template.New("").Parse(
+	`
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+		<title>coverage summary</title>
+		<style>
+			td.bar div {
+				background: rgb(200, 60, 40);
+				height: 10px;
+			}
+			summary {
+				cursor: pointer;
+			}
+		</style>
+	</head>
+	<body>
+	<b>Coverage by directory</b>
+	<table>
+		<tr><th>Directory</th><th>Files</th><th>PCs</th><th></th></tr>
+		{{range $d := $.Dirs}}
+		<tr>
+			<td>{{$d.Name}}</td>
+			<td>{{$d.Files}}</td>
+			<td>{{$d.PCs}}</td>
+			<td class="bar" width="100"><div style="width: {{$d.Heat}}px"></div></td>
+		</tr>
+		{{end}}
+	</table>
+	<br>
+	<b>Coverage by file</b>
+	<table>
+		<tr><th>File</th><th>Lines</th><th>PCs</th><th></th></tr>
+		{{range $f := $.Files}}
+		<tr>
+			<td>
+				<details>
+				<summary><a href="/cover?call={{$.Call}}#file{{$f.Index}}">{{$f.Name}}</a></summary>
+				{{range $fn := $f.Funcs}}
+				&nbsp;&nbsp;<a href="/cover?call={{$.Call}}#file{{$f.Index}}_line{{$fn.Line}}">{{$fn.Name}}</a> ({{$fn.PCs}})<br>
+				{{end}}
+				</details>
+			</td>
+			<td valign="top">{{len $f.Lines}}</td>
+			<td valign="top">{{$f.PCs}}</td>
+			<td valign="top" class="bar" width="100"><div style="width: {{$f.Heat}}px"></div></td>
+		</tr>
+		{{end}}
+	</table>
+	</body>
+</html>
+`))
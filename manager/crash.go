@@ -0,0 +1,184 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/report"
+	"github.com/google/syzkaller/vm"
+)
+
+// Crashes are grouped by title, every title has own dir workdir/crashes/HASH/
+// (HASH is hash of the title) with the following files:
+//
+//	description - the crash title
+//	logN - console output of the N-th crash
+//	logN.timeline - console output merged with host events
+//	reportN - the oops part of the console output, symbolized against vmlinux
+//	guilty - the most likely culprit source file of the most recent crash (if known)
+//	repro.prog - reproducer program (see repro.go)
+//	repro.c - the reproducer as a standalone C program
+//	emailed - marker that the crash was emailed (see email.go)
+//	disk.qcow2 - disk overlay of the first instance that crashed (if save_crash_disk is set)
+//
+// At most maxCrashLogs logs are kept per crash, the oldest ones are overwritten.
+const maxCrashLogs = 100
+
+// saveCrashDisk preserves disk of the crashed instance for inspection.
+// Disks are large, so only the first one is kept per crash.
+func (mgr *Manager) saveCrashDisk(name string, inst vm.Instance, title string) {
+	saver, ok := inst.(vm.DiskSaver)
+	if !ok {
+		return
+	}
+	dst := filepath.Join(mgr.crashdir, hashString([]byte(title)), "disk.qcow2")
+	if _, err := os.Stat(dst); err == nil {
+		return
+	}
+	if err := saver.SaveDisk(dst); err != nil {
+		mgr.logf(0, "%v: failed to save disk for '%v': %v", name, title, err)
+		return
+	}
+	mgr.logf(0, "%v: saved disk for '%v' to %v", name, title, dst)
+}
+
+// crashReport extracts the oops from output and symbolizes it against vmlinux.
+// Returns nil if output does not contain an oops. Symbolization runs addr2line
+// on vmlinux (and nm on the first call), so it must not be called with mgr.mu held.
+func (mgr *Manager) crashReport(vmlinux string, output []byte) []byte {
+	rep := report.Parse(output)
+	if rep == nil {
+		return nil
+	}
+	symbolized, err := report.Symbolize(vmlinux, mgr.cfg.Kernel_Src, rep.Report)
+	if err != nil {
+		mgr.logf(0, "failed to symbolize report: %v", err)
+		return rep.Report
+	}
+	return symbolized
+}
+
+// saveCrashLog stores a crash and returns name of the saved log file.
+// crashReport is the symbolized oops (see crashReport), it can be nil.
+// Must be called with mgr.mu held.
+func (mgr *Manager) saveCrashLog(title string, output, crashReport, timeline []byte) (string, error) {
+	id := hashString([]byte(title))
+	dir := filepath.Join(mgr.crashdir, id)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create crash dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "description"), []byte(title+"\n"), 0660); err != nil {
+		return "", fmt.Errorf("failed to write crash description: %v", err)
+	}
+	// Take the first free slot, or the oldest one.
+	slot := -1
+	var oldest time.Time
+	for i := 0; i < maxCrashLogs; i++ {
+		fi, err := os.Stat(filepath.Join(dir, fmt.Sprintf("log%v", i)))
+		if err != nil {
+			slot = i
+			break
+		}
+		if slot == -1 || fi.ModTime().Before(oldest) {
+			slot = i
+			oldest = fi.ModTime()
+		}
+	}
+	name := fmt.Sprintf("log%v", slot)
+	if err := ioutil.WriteFile(filepath.Join(dir, name), output, 0660); err != nil {
+		return "", fmt.Errorf("failed to write crash log: %v", err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, name+".timeline"), timeline, 0660)
+	reportFile := filepath.Join(dir, fmt.Sprintf("report%v", slot))
+	if crashReport != nil {
+		ioutil.WriteFile(reportFile, crashReport, 0660)
+		if guilty := report.ExtractGuiltyFile(crashReport); guilty != "" {
+			ioutil.WriteFile(filepath.Join(dir, "guilty"), []byte(guilty+"\n"), 0660)
+		}
+	} else {
+		os.Remove(reportFile)
+	}
+	return filepath.Join(id, name), nil
+}
+
+// CrashInfo describes a crash dir.
+type CrashInfo struct {
+	ID     string
+	Title  string
+	Guilty string   // the most likely culprit source file
+	Logs   []string // log file names, the most recent first
+	Count  int      // number of crashes in this manager run
+	Last   time.Time
+	Repro  bool // repro.prog exists
+}
+
+// readCrashes reads all crash dirs.
+// Must be called with mgr.mu held.
+func (mgr *Manager) readCrashes() ([]*CrashInfo, error) {
+	dirs, err := ioutil.ReadDir(mgr.crashdir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read crash dir: %v", err)
+	}
+	var crashes []*CrashInfo
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		crash, err := mgr.readCrash(dir.Name())
+		if err != nil {
+			continue
+		}
+		crashes = append(crashes, crash)
+	}
+	return crashes, nil
+}
+
+// readCrash must be called with mgr.mu held.
+func (mgr *Manager) readCrash(id string) (*CrashInfo, error) {
+	dir := filepath.Join(mgr.crashdir, id)
+	desc, err := ioutil.ReadFile(filepath.Join(dir, "description"))
+	if err != nil {
+		return nil, err
+	}
+	crash := &CrashInfo{
+		ID:    id,
+		Title: string(bytes.TrimRight(desc, "\r\n")),
+	}
+	crash.Count = mgr.crashTypes[crash.Title]
+	if guilty, err := ioutil.ReadFile(filepath.Join(dir, "guilty")); err == nil {
+		crash.Guilty = string(bytes.TrimRight(guilty, "\r\n"))
+	}
+	var logs []os.FileInfo
+	for i := 0; i < maxCrashLogs; i++ {
+		fi, err := os.Stat(filepath.Join(dir, fmt.Sprintf("log%v", i)))
+		if err == nil {
+			logs = append(logs, fi)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "repro.prog")); err == nil {
+		crash.Repro = true
+	}
+	sort.Sort(fileTimeArray(logs))
+	for _, fi := range logs {
+		crash.Logs = append(crash.Logs, fi.Name())
+	}
+	if len(logs) != 0 {
+		crash.Last = logs[0].ModTime()
+	}
+	return crash, nil
+}
+
+// fileTimeArray sorts files by modification time, the most recent first.
+type fileTimeArray []os.FileInfo
+
+func (a fileTimeArray) Len() int           { return len(a) }
+func (a fileTimeArray) Less(i, j int) bool { return a[i].ModTime().After(a[j].ModTime()) }
+func (a fileTimeArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
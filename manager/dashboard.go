@@ -0,0 +1,126 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/google/syzkaller/repro"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Crashes and reproducers are uploaded to syz-dash (cfg.Dashboard_Addr) in background,
+// the manager also periodically polls the dashboard so that it knows that the manager
+// is alive (used to detect bugs that stopped happening). Requests are retried
+// a few times and dropped if the dashboard is unreachable.
+
+type DashRequest struct {
+	method string
+	args   interface{}
+}
+
+const (
+	dashQueueSize  = 100
+	dashRetries    = 3
+	dashPollPeriod = 10 * time.Minute
+)
+
+func (mgr *Manager) queueDash(method string, args interface{}) {
+	if mgr.cfg.Dashboard_Addr == "" {
+		return
+	}
+	select {
+	case mgr.dashQueue <- &DashRequest{method, args}:
+	default:
+		mgr.logf(0, "dashboard queue is full, dropping %v", method)
+	}
+}
+
+func (mgr *Manager) uploadCrash(title string, output, crashReport []byte) {
+	a := &DashCrashArgs{
+		Name:   mgr.cfg.Name,
+		Key:    mgr.cfg.Dashboard_Key,
+		Title:  title,
+		Kernel: mgr.kernelTag.Version,
+		Commit: mgr.kernelTag.Commit,
+		Log:    output,
+		Report: crashReport,
+	}
+	mgr.queueDash("Dashboard.UploadCrash", a)
+}
+
+func (mgr *Manager) uploadRepro(title string, res *repro.Result, prog, cprog []byte) {
+	a := &DashReproArgs{
+		Name:  mgr.cfg.Name,
+		Key:   mgr.cfg.Dashboard_Key,
+		Title: title,
+		Opts:  fmt.Sprintf("-threaded=%v -collide=%v -procs=%v", res.Opts.Threaded, res.Opts.Collide, res.Opts.Procs),
+		Prog:  prog,
+	}
+	if res.CRepro {
+		a.CProg = cprog
+	}
+	mgr.queueDash("Dashboard.UploadRepro", a)
+}
+
+func (mgr *Manager) dashLoop() {
+	var dash *rpc.Client
+	poll := time.NewTicker(dashPollPeriod)
+	defer poll.Stop()
+	for {
+		var req *DashRequest
+		select {
+		case req = <-mgr.dashQueue:
+		case <-poll.C:
+			req = &DashRequest{"Dashboard.Poll", &DashPollArgs{Name: mgr.cfg.Name, Key: mgr.cfg.Dashboard_Key}}
+		case <-mgr.stop:
+			if dash != nil {
+				dash.Close()
+			}
+			return
+		}
+		for attempt := 0; ; attempt++ {
+			if attempt != 0 {
+				time.Sleep(time.Minute)
+			}
+			if dash == nil {
+				conn, err := jsonrpc.Dial("tcp", mgr.cfg.Dashboard_Addr)
+				if err != nil {
+					mgr.logf(0, "failed to connect to dashboard at %v: %v", mgr.cfg.Dashboard_Addr, err)
+					if attempt == dashRetries {
+						break
+					}
+					continue
+				}
+				dash = conn
+			}
+			var err error
+			if req.method == "Dashboard.UploadCrash" {
+				r := new(DashCrashRes)
+				if err = dash.Call(req.method, req.args, r); err == nil {
+					mgr.logf(1, "uploaded crash '%v' to dashboard, status: %v",
+						req.args.(*DashCrashArgs).Title, r.Status)
+				}
+			} else {
+				err = dash.Call(req.method, req.args, nil)
+			}
+			if err == nil {
+				break
+			}
+			mgr.logf(0, "dashboard %v failed: %v", req.method, err)
+			if _, ok := err.(rpc.ServerError); ok {
+				// The dashboard has rejected the request, retrying won't help.
+				break
+			}
+			dash.Close()
+			dash = nil
+			if attempt == dashRetries {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Fuzzers monitor free guest disk space and clean up temp files and logs when it falls below
+// cfg.Disk_Min (see syz-fuzzer/disk.go). If the cleanup does not help, the fuzzer reports
+// Manager.DiskFull: it is not a kernel bug, so the VM is restarted with a fresh disk and
+// the hangs and lost connections that follow (caused by ENOSPC) are not saved as crashes.
+
+// DiskFull receives a guest disk full report from a fuzzer.
+func (mgr *Manager) DiskFull(a *DiskFullArgs, r *int) error {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if err := mgr.authFuzzer(a.Name, a.Key, true); err != nil {
+		return err
+	}
+	mgr.logf(0, "%v: guest disk full (%v MB free)", a.Name, a.Free>>20)
+	mgr.stats["guest disk full"]++
+	inst := mgr.instances[a.Name]
+	if inst == nil || inst.diskFull {
+		return nil
+	}
+	inst.diskFull = true
+	select {
+	case inst.restart <- "guest disk full":
+	default:
+	}
+	return nil
+}
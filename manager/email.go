@@ -0,0 +1,170 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// New unique crashes are emailed to cfg.Email_Addrs with the report, console log
+// and reproducer (if any) attached. Every title is reported once: the crash dir
+// gets an emailed marker file. Crashes that are being reproduced are emailed
+// after the reproduction has finished, so that the email includes the result.
+// Emails go through the Notifier, so crashes found during quiet hours
+// are sent as a single digest email.
+
+const maxEmailAttachment = 1 << 20
+
+type emailAttachment struct {
+	name string
+	data []byte
+}
+
+// crashEmail is the email part of a crash notification.
+type crashEmail struct {
+	marker      string
+	body        []byte
+	attachments []emailAttachment
+}
+
+// emailCrash must be called with mgr.mu held.
+func (mgr *Manager) emailCrash(title string) {
+	if len(mgr.cfg.Email_Addrs) == 0 {
+		return
+	}
+	id := hashString([]byte(title))
+	dir := filepath.Join(mgr.crashdir, id)
+	marker := filepath.Join(dir, "emailed")
+	if _, err := os.Stat(marker); err == nil {
+		return
+	}
+	crash, err := mgr.readCrash(id)
+	if err != nil || len(crash.Logs) == 0 {
+		return
+	}
+	log := crash.Logs[0]
+	var attachments []emailAttachment
+	for _, name := range []string{"report" + strings.TrimPrefix(log, "log"), log, "repro.prog", "repro.c"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if len(data) > maxEmailAttachment {
+			// The end of the log is the most relevant part.
+			data = data[len(data)-maxEmailAttachment:]
+		}
+		attachments = append(attachments, emailAttachment{name, data})
+	}
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "%v\n\n", title)
+	if mgr.cfg.Name != "" {
+		fmt.Fprintf(body, "manager: %v\n", mgr.cfg.Name)
+	}
+	fmt.Fprintf(body, "%v", mgr.kernelTag)
+	fmt.Fprintf(body, "details: http://%v/crash?id=%v\n", mgr.cfg.Http, id)
+	if crash.Repro {
+		fmt.Fprintf(body, "reproducer is attached\n")
+	} else {
+		fmt.Fprintf(body, "no reproducer\n")
+	}
+	if err := ioutil.WriteFile(marker, nil, 0660); err != nil {
+		mgr.logf(0, "failed to write email marker: %v", err)
+		return
+	}
+	mgr.notifier.notifyEmail(Notification{
+		Title: title,
+		Time:  time.Now(),
+		email: &crashEmail{marker, body.Bytes(), attachments},
+	})
+}
+
+type emailSink struct {
+	from string
+	to   []string
+	addr string
+	logf logger
+}
+
+// Send emails a single crash, or a digest of several crashes
+// with attachments of every crash prefixed by the crash number.
+func (s *emailSink) Send(batch []Notification) error {
+	subject := "syzkaller: " + batch[0].Title
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "syzkaller hit a new crash:\n\n")
+	if len(batch) > 1 {
+		subject = fmt.Sprintf("syzkaller: %v new crashes", len(batch))
+		body.Reset()
+		fmt.Fprintf(body, "syzkaller hit %v new crashes:\n\n", len(batch))
+	}
+	var attachments []emailAttachment
+	for i, nt := range batch {
+		if i != 0 {
+			fmt.Fprintf(body, "\n")
+		}
+		body.Write(nt.email.body)
+		for _, a := range nt.email.attachments {
+			if len(batch) > 1 {
+				a.name = fmt.Sprintf("%v-%v", i+1, a.name)
+			}
+			attachments = append(attachments, a)
+		}
+	}
+	msg := buildEmail(s.from, s.to, subject, body.Bytes(), attachments)
+	if err := smtp.SendMail(s.addr, nil, s.from, s.to, msg); err != nil {
+		// Retry on the next crash with these titles.
+		for _, nt := range batch {
+			os.Remove(nt.email.marker)
+		}
+		return fmt.Errorf("failed to email '%v': %v", subject, err)
+	}
+	s.logf(0, "emailed '%v' to %v", subject, strings.Join(s.to, ", "))
+	return nil
+}
+
+// buildEmail creates a multipart MIME message with a text body and text file attachments.
+func buildEmail(from string, to []string, subject string, body []byte, attachments []emailAttachment) []byte {
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+	fmt.Fprintf(buf, "From: %v\r\n", from)
+	fmt.Fprintf(buf, "To: %v\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(buf, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(buf, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%v\r\n\r\n", w.Boundary())
+	writePart(w, "text/plain; charset=utf-8", "inline", body)
+	for _, a := range attachments {
+		writePart(w, "text/plain; charset=utf-8", fmt.Sprintf("attachment; filename=%q", a.name), a.data)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func writePart(w *multipart.Writer, contentType, disposition string, data []byte) {
+	hdr := make(textproto.MIMEHeader)
+	hdr.Set("Content-Type", contentType)
+	hdr.Set("Content-Disposition", disposition)
+	hdr.Set("Content-Transfer-Encoding", "base64")
+	pw, err := w.CreatePart(hdr)
+	if err != nil {
+		// Writes into bytes.Buffer don't fail.
+		panic(err)
+	}
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		fmt.Fprintf(pw, "%v\r\n", enc[:76])
+		enc = enc[76:]
+	}
+	fmt.Fprintf(pw, "%v\r\n", enc)
+}
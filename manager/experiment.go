@@ -0,0 +1,232 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/cover"
+	"github.com/google/syzkaller/sys"
+)
+
+// Experiment splits VM instances into control and treatment groups
+// that run fuzzers with different engine flags (cfg.Experiment),
+// and accounts stats, coverage and crashes separately for each group.
+// All methods must be called with mgr.mu held and are no-op on nil receiver.
+type Experiment struct {
+	name    string
+	groups  [2]*ExperimentGroup
+	members map[string]*experimentMember // fuzzer name -> member
+}
+
+type experimentMember struct {
+	group *ExperimentGroup
+	start time.Time
+}
+
+type ExperimentGroup struct {
+	Name    string
+	VMs     int // number of currently running VMs
+	VMTime  time.Duration
+	Stats   map[string]uint64
+	Cover   []cover.Cover
+	Crashes int
+}
+
+const (
+	groupControl   = 0
+	groupTreatment = 1
+)
+
+func newExperiment(cfg *config.Config) *Experiment {
+	if cfg.Experiment == nil {
+		return nil
+	}
+	e := &Experiment{
+		name:    cfg.Experiment.Name,
+		members: make(map[string]*experimentMember),
+	}
+	for i, name := range []string{"control", "treatment"} {
+		e.groups[i] = &ExperimentGroup{
+			Name:  name,
+			Stats: make(map[string]uint64),
+			Cover: make([]cover.Cover, sys.CallCount),
+		}
+	}
+	return e
+}
+
+// isTreatment says if VM slot i belongs to treatment group.
+// Treatment slots are spread evenly among all slots.
+func isTreatment(cfg *config.Config, i int) bool {
+	if cfg.Experiment == nil {
+		return false
+	}
+	share := cfg.Experiment.Share
+	return i*share/100 != (i+1)*share/100
+}
+
+func (e *Experiment) join(name string, group int) {
+	if e == nil {
+		return
+	}
+	g := e.groups[group]
+	e.members[name] = &experimentMember{g, time.Now()}
+	g.VMs++
+}
+
+func (e *Experiment) leave(name string) {
+	if e == nil {
+		return
+	}
+	if m := e.members[name]; m != nil {
+		m.group.VMs--
+		m.group.VMTime += time.Since(m.start)
+		delete(e.members, name)
+	}
+}
+
+func (e *Experiment) group(name string) *ExperimentGroup {
+	if e == nil || e.members[name] == nil {
+		return nil
+	}
+	return e.members[name].group
+}
+
+func (e *Experiment) addStats(name string, stats map[string]uint64) {
+	g := e.group(name)
+	if g == nil {
+		return
+	}
+	for k, v := range stats {
+		g.Stats[k] += v
+	}
+}
+
+func (e *Experiment) addCover(name string, call int, cov []uint32) {
+	g := e.group(name)
+	if g == nil {
+		return
+	}
+	g.Cover[call] = cover.Union(g.Cover[call], cov)
+}
+
+func (e *Experiment) addCrash(name string) {
+	if g := e.group(name); g != nil {
+		g.Crashes++
+	}
+}
+
+func (mgr *Manager) httpExperiment(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	e := mgr.experiment
+	if e == nil {
+		http.Error(w, "no experiment is configured", http.StatusNotFound)
+		return
+	}
+	data := &UIExperimentData{Name: e.name}
+	statNames := make(map[string]bool)
+	for _, g := range e.groups {
+		for k := range g.Stats {
+			statNames[k] = true
+		}
+	}
+	var names []string
+	for k := range statNames {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	addRow := func(name string, vals func(g *ExperimentGroup) float64) {
+		row := UIExperimentRow{Name: name}
+		var v [2]float64
+		for i, g := range e.groups {
+			v[i] = vals(g)
+			row.Values = append(row.Values, fmt.Sprintf("%.1f", v[i]))
+		}
+		if v[groupControl] != 0 {
+			row.Diff = fmt.Sprintf("%+.1f%%", (v[groupTreatment]/v[groupControl]-1)*100)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+	hours := func(g *ExperimentGroup) float64 {
+		t := g.VMTime
+		for _, m := range e.members {
+			if m.group == g {
+				t += time.Since(m.start)
+			}
+		}
+		return t.Hours()
+	}
+	perHour := func(v float64, g *ExperimentGroup) float64 {
+		if h := hours(g); h != 0 {
+			return v / h
+		}
+		return 0
+	}
+	addRow("running VMs", func(g *ExperimentGroup) float64 { return float64(g.VMs) })
+	addRow("VM hours", hours)
+	addRow("cover", func(g *ExperimentGroup) float64 {
+		n := 0
+		for _, cov := range g.Cover {
+			n += len(cov)
+		}
+		return float64(n)
+	})
+	addRow("crashes per VM hour", func(g *ExperimentGroup) float64 {
+		return perHour(float64(g.Crashes), g)
+	})
+	for _, name := range names {
+		name := name
+		addRow(name+" per VM hour", func(g *ExperimentGroup) float64 {
+			return perHour(float64(g.Stats[name]), g)
+		})
+	}
+	if err := experimentTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+type UIExperimentData struct {
+	Name string
+	Rows []UIExperimentRow
+}
+
+type UIExperimentRow struct {
+	Name   string
+	Values []string
+	Diff   string
+}
+
+var experimentTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller experiment {{.Name}}</title>
+</head>
+<body>
+Experiment: {{.Name}}<br>
+<table>
+	<tr>
+		<th></th>
+		<th>control</th>
+		<th>treatment</th>
+		<th>diff</th>
+	</tr>
+	{{range $r := $.Rows}}
+	<tr>
+		<td>{{$r.Name}}</td>
+		{{range $v := $r.Values}}<td>{{$v}}</td>{{end}}
+		<td>{{$r.Diff}}</td>
+	</tr>
+	{{end}}
+</table>
+</body></html>
+`))
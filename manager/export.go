@@ -0,0 +1,133 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Exporter appends structured crash and stats records to cfg.Export file
+// (and stats records to the -bench file) in newline-delimited JSON format.
+// Records are not streamed into BigQuery or an SQL database directly, the file needs
+// to be loaded periodically (e.g. bq load --source_format=NEWLINE_DELIMITED_JSON)
+// for long-term analytics. Every record has Type field ("crash" or "stats").
+// In bench mode (-bench=file) a separate Exporter appends only stats records to the file
+// every -bench_period, so that progress of several runs (e.g. with different fuzzing
+// strategies) can be plotted and compared directly.
+type Exporter struct {
+	mu   sync.Mutex
+	f    *os.File
+	enc  *json.Encoder
+	logf logger
+}
+
+type CrashRecord struct {
+	Type     string
+	Time     time.Time
+	Title    string
+	Severity string
+	VM       string
+	Uptime   float64 // VM uptime in seconds when the crash happened
+	Kernel   string
+	Commit   string
+}
+
+type StatsRecord struct {
+	Type       string
+	Time       time.Time
+	Uptime     float64 // manager uptime in seconds
+	Corpus     int
+	Cover      int
+	Candidates int
+	Crashes    int // total number of crashes
+	CrashTypes int // number of distinct crash titles
+	Stats      map[string]uint64
+}
+
+const exportPeriod = time.Minute
+
+func newExporter(filename string, logf logger) (*Exporter, error) {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{f: f, enc: json.NewEncoder(f), logf: logf}, nil
+}
+
+func (e *Exporter) write(rec interface{}) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.enc.Encode(rec); err != nil {
+		e.logf(0, "failed to export record: %v", err)
+	}
+}
+
+func (e *Exporter) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.f.Close()
+}
+
+func (e *Exporter) exportCrash(rep *Report) {
+	e.write(&CrashRecord{
+		Type:     "crash",
+		Time:     rep.Time,
+		Title:    rep.Title,
+		Severity: rep.Severity,
+		VM:       rep.VM,
+		Uptime:   rep.Uptime,
+		Kernel:   rep.Kernel,
+		Commit:   rep.Commit,
+	})
+}
+
+// exportStatsLoop periodically exports manager stats to e and once more when fuzzing stops,
+// then closes done (if not nil).
+func (mgr *Manager) exportStatsLoop(e *Exporter, period time.Duration, done chan bool) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for stop := false; !stop; {
+		select {
+		case <-ticker.C:
+		case <-mgr.stop:
+			stop = true
+		}
+		mgr.mu.Lock()
+		rec := mgr.statsRecord()
+		mgr.mu.Unlock()
+		e.write(rec)
+	}
+	if done != nil {
+		close(done)
+	}
+}
+
+// statsRecord returns a snapshot of manager stats, must be called under mgr.mu.
+func (mgr *Manager) statsRecord() *StatsRecord {
+	rec := &StatsRecord{
+		Type:       "stats",
+		Time:       time.Now(),
+		Uptime:     time.Since(mgr.startTime).Seconds(),
+		Corpus:     len(mgr.corpus),
+		Candidates: len(mgr.candidates),
+		CrashTypes: len(mgr.crashTypes),
+		Stats:      make(map[string]uint64),
+	}
+	for _, cov := range mgr.corpusCover {
+		rec.Cover += len(cov)
+	}
+	for _, n := range mgr.crashTypes {
+		rec.Crashes += n
+	}
+	for k, v := range mgr.stats {
+		rec.Stats[k] = v
+	}
+	return rec
+}
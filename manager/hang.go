@@ -0,0 +1,46 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Programs that could not be killed after cfg.Program_Timeout are reported by fuzzers
+// with Manager.Hang (see syz-fuzzer/hang.go), the VM continues fuzzing. Such programs are
+// not crashes (the kernel may recover later), but they point to deadlocks and missing
+// signal checks in long loops, so they are saved as workdir/hangs/HASH.prog
+// (HASH is hash of the program) with the VM name and execution parameters in comments.
+
+// Hang receives a program that hanged in a fuzzer.
+func (mgr *Manager) Hang(a *HangArgs, r *int) error {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if err := mgr.authFuzzer(a.Name, a.Key, true); err != nil {
+		return err
+	}
+	mgr.stats["hang programs"]++
+	sig := hashString(a.Prog)
+	dir := filepath.Join(mgr.cfg.Workdir, "hangs")
+	filename := filepath.Join(dir, sig+".prog")
+	if _, err := os.Stat(filename); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create hangs dir: %v", err)
+	}
+	data := fmt.Sprintf("# hanged on %v at %v (%v)\n%s", a.Name, time.Now().Format(time.RFC3339), a.Note, a.Prog)
+	if err := ioutil.WriteFile(filename, []byte(data), 0660); err != nil {
+		return fmt.Errorf("failed to write hang program: %v", err)
+	}
+	mgr.logf(0, "%v: saved hang program to %v", a.Name, filename)
+	mgr.audit(&AuditEvent{Type: "hang", VM: a.Name, Prog: sig, Reason: a.Note})
+	return nil
+}
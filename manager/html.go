@@ -0,0 +1,530 @@
+// Copyright 2015 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"github.com/google/syzkaller/cover"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/report"
+	"github.com/google/syzkaller/sys"
+)
+
+func (mgr *Manager) initHttp() {
+	mgr.mux = http.NewServeMux()
+	mgr.mux.HandleFunc("/", mgr.httpInfo)
+	mgr.mux.HandleFunc("/corpus", mgr.httpCorpus)
+	mgr.mux.HandleFunc("/corpus/download", mgr.httpCorpusDownload)
+	mgr.mux.HandleFunc("/corpus/upload", mgr.httpCorpusUpload)
+	mgr.mux.HandleFunc("/cover", mgr.httpCover)
+	mgr.mux.HandleFunc("/coverfiles", mgr.httpCoverFiles)
+	mgr.mux.HandleFunc("/subsystems", mgr.httpSubsystems)
+	mgr.mux.HandleFunc("/prio", mgr.httpPrio)
+	mgr.mux.HandleFunc("/calls", mgr.httpCalls)
+	mgr.mux.HandleFunc("/crashes", mgr.httpCrashes)
+	mgr.mux.HandleFunc("/crash", mgr.httpCrash)
+	mgr.mux.HandleFunc("/crash/trace", mgr.httpCrashTrace)
+	mgr.mux.HandleFunc("/crash/report", mgr.httpCrashReport)
+	mgr.mux.HandleFunc("/input", mgr.httpInput)
+	mgr.mux.HandleFunc("/input/trace", mgr.httpInputTrace)
+	mgr.mux.HandleFunc("/experiment", mgr.httpExperiment)
+	mgr.mux.HandleFunc("/kernels", mgr.httpKernels)
+	mgr.mux.HandleFunc("/instances", mgr.httpInstances)
+	mgr.mux.HandleFunc("/instance", mgr.httpInstance)
+	mgr.mux.HandleFunc("/instance/console", mgr.httpInstanceConsole)
+	mgr.mux.HandleFunc("/instance/restart", mgr.httpInstanceRestart)
+	mgr.mux.HandleFunc("/instance/pprof", mgr.httpInstancePprof)
+	mgr.mux.HandleFunc("/metrics", mgr.httpMetrics)
+	mgr.mux.HandleFunc("/restart", mgr.httpRestart)
+	mgr.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mgr.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mgr.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mgr.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mgr.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mgr.logf(0, "serving http on http://%v", mgr.cfg.Http)
+	go http.ListenAndServe(mgr.cfg.Http, mgr.httpAuth(mgr.mux))
+}
+
+func (mgr *Manager) httpInfo(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	uptime := time.Since(mgr.startTime)
+	data := &UIData{
+		CorpusSize:  len(mgr.corpus),
+		TriageQueue: len(mgr.candidates),
+		Uptime:      fmt.Sprintf("%v", uptime),
+	}
+	data.AllowedVMs, data.RunningVMs = mgr.scaler.state()
+	data.Experiment = mgr.experiment != nil
+	data.Kernels = mgr.kernels != nil
+	if mgr.boot.broken() {
+		data.Boot = &mgr.boot
+	}
+	if mgr.storm != nil {
+		storm := *mgr.storm
+		data.Storm = &storm
+	}
+
+	type CallCov struct {
+		count int
+		cov   cover.Cover
+	}
+	calls := make(map[string]*CallCov)
+	for _, inp := range mgr.corpus {
+		if calls[inp.Call] == nil {
+			calls[inp.Call] = new(CallCov)
+		}
+		cc := calls[inp.Call]
+		cc.count++
+		cc.cov = cover.Union(cc.cov, cover.Cover(inp.Cover))
+		data.CorpusCoverMem += len(inp.Cover) * int(unsafe.Sizeof(inp.Cover[0]))
+	}
+	for _, cov := range mgr.corpusCover {
+		data.CallCoverMem += len(cov) * int(unsafe.Sizeof(cov[0]))
+	}
+
+	secs := uint64(uptime) / 1e9
+	for k, v := range mgr.stats {
+		val := ""
+		if x := v / secs; x >= 10 {
+			val = fmt.Sprintf("%v/sec", x)
+		} else if x := v * 60 / secs; x >= 10 {
+			val = fmt.Sprintf("%v/min", x)
+		} else {
+			x := v * 60 * 60 / secs
+			val = fmt.Sprintf("%v/hour", x)
+		}
+		data.Stats = append(data.Stats, UIStat{Name: k, Value: val})
+	}
+	sort.Sort(UIStatArray(data.Stats))
+	data.Stats = append(data.Stats, phaseShares(mgr.phases)...)
+
+	var cov cover.Cover
+	for c, cc := range calls {
+		cov = cover.Union(cov, cc.cov)
+		data.Calls = append(data.Calls, UICallType{c, cc.count, len(cc.cov)})
+	}
+	sort.Sort(UICallTypeArray(data.Calls))
+	data.CoverSize = len(cov)
+
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (mgr *Manager) httpCorpus(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	var data []UIInput
+	call := r.FormValue("call")
+	for i, inp := range mgr.corpus {
+		if call != inp.Call {
+			continue
+		}
+		p, err := prog.Deserialize(inp.Prog)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to deserialize program: %v", err), http.StatusInternalServerError)
+		}
+		data = append(data, UIInput{
+			Sig:   hashString(inp.Prog),
+			Short: p.String(),
+			Full:  string(inp.Prog),
+			Cover: len(inp.Cover),
+			N:     i,
+		})
+	}
+	sort.Sort(UIInputArray(data))
+
+	if err := corpusTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (mgr *Manager) httpCover(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	cov := mgr.callCover(r.FormValue("call"))
+	if err := generateCoverHtml(w, mgr.cfg.Vmlinux, cov); err != nil {
+		http.Error(w, fmt.Sprintf("failed to generate coverage profile: %v", err), http.StatusInternalServerError)
+	}
+	runtime.GC()
+}
+
+func (mgr *Manager) httpCoverFiles(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	call := r.FormValue("call")
+	if err := generateCoverSummaryHtml(w, mgr.cfg.Vmlinux, mgr.callCover(call), call); err != nil {
+		http.Error(w, fmt.Sprintf("failed to generate coverage summary: %v", err), http.StatusInternalServerError)
+	}
+	runtime.GC()
+}
+
+// callCover returns coverage of the corpus input with index call,
+// or of all inputs for the syscall call, or of the whole corpus if call is empty.
+func (mgr *Manager) callCover(call string) cover.Cover {
+	if n, err := strconv.Atoi(call); err == nil && n < len(mgr.corpus) {
+		return mgr.corpus[n].Cover
+	}
+	var cov cover.Cover
+	for _, inp := range mgr.corpus {
+		if call == "" || call == inp.Call {
+			cov = cover.Union(cov, cover.Cover(inp.Cover))
+		}
+	}
+	return cov
+}
+
+func (mgr *Manager) httpPrio(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	mgr.minimizeCorpus()
+	call := r.FormValue("call")
+	idx := -1
+	for i, c := range sys.Calls {
+		if c.CallName == call {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		http.Error(w, fmt.Sprintf("unknown call: %v", call), http.StatusInternalServerError)
+		return
+	}
+
+	data := &UIPrioData{Call: call}
+	for i, p := range mgr.prios[idx] {
+		data.Prios = append(data.Prios, UIPrio{sys.Calls[i].Name, p})
+	}
+	sort.Sort(UIPrioArray(data.Prios))
+
+	if err := prioTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (mgr *Manager) httpCrashes(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	crashes, err := mgr.readCrashes()
+	mgr.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var data []UICrashType
+	for _, crash := range crashes {
+		data = append(data, UICrashType{
+			ID:        crash.ID,
+			Title:     crash.Title,
+			Guilty:    crash.Guilty,
+			Subsystem: report.GuiltySubsystem(crash.Guilty),
+			Logs:      len(crash.Logs),
+			Count:     crash.Count,
+			Last:      crash.Last.Format(time.Stamp),
+			Repro:     crash.Repro,
+			last:      crash.Last,
+		})
+	}
+	sort.Sort(UICrashTypeArray(data))
+	if err := crashesTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (mgr *Manager) httpCrash(w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("id")
+	if id == "" || filepath.Base(id) != id {
+		http.Error(w, fmt.Sprintf("bad crash id: %q", id), http.StatusBadRequest)
+		return
+	}
+	if name := r.FormValue("log"); name != "" {
+		if filepath.Base(name) != name {
+			http.Error(w, fmt.Sprintf("bad crash log: %q", name), http.StatusBadRequest)
+			return
+		}
+		// Prefer the timeline with all guest and host events on the host clock.
+		file := filepath.Join(mgr.crashdir, id, name)
+		data, err := ioutil.ReadFile(file + ".timeline")
+		if err != nil {
+			data, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read crash: %v", err), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(data)
+		return
+	}
+	mgr.mu.Lock()
+	crash, err := mgr.readCrash(id)
+	data := &UICrashData{
+		CrashInfo: crash,
+		CanTrace:  mgr.cfg.Ftrace != "",
+		Tracing:   mgr.tracing[mgr.reproTraceFile(id)],
+		Templates: mgr.reportTemplateNames(),
+	}
+	mgr.mu.Unlock()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read crash: %v", err), http.StatusNotFound)
+		return
+	}
+	if _, err := os.Stat(mgr.reproTraceFile(id)); err == nil {
+		data.Trace = true
+	}
+	if err := crashTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+type UICrashData struct {
+	*CrashInfo
+	CanTrace  bool
+	Tracing   bool
+	Trace     bool
+	Templates []string // bug report templates
+}
+
+type UICrashType struct {
+	ID        string
+	Title     string
+	Guilty    string
+	Subsystem string
+	Logs      int
+	Count     int
+	Last      string
+	Repro     bool
+	last      time.Time
+}
+
+type UICrashTypeArray []UICrashType
+
+func (a UICrashTypeArray) Len() int           { return len(a) }
+func (a UICrashTypeArray) Less(i, j int) bool { return a[i].last.After(a[j].last) }
+func (a UICrashTypeArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+type UIData struct {
+	CorpusSize     int
+	TriageQueue    int
+	CoverSize      int
+	CorpusCoverMem int
+	CallCoverMem   int
+	Uptime         string
+	RunningVMs     int
+	AllowedVMs     int
+	Experiment     bool
+	Kernels        bool
+	Boot           *BootState // set if the target kernel is broken
+	Storm          *Storm     // set during a crash storm
+	Stats          []UIStat
+	Calls          []UICallType
+}
+
+type UIStat struct {
+	Name  string
+	Value string
+}
+
+type UICallType struct {
+	Name   string
+	Inputs int
+	Cover  int
+}
+
+type UIInput struct {
+	Sig   string
+	Short string
+	Full  string
+	Calls int
+	Cover int
+	N     int
+}
+
+type UICallTypeArray []UICallType
+
+func (a UICallTypeArray) Len() int           { return len(a) }
+func (a UICallTypeArray) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a UICallTypeArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+type UIInputArray []UIInput
+
+func (a UIInputArray) Len() int           { return len(a) }
+func (a UIInputArray) Less(i, j int) bool { return a[i].Cover > a[j].Cover }
+func (a UIInputArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+type UIStatArray []UIStat
+
+func (a UIStatArray) Len() int           { return len(a) }
+func (a UIStatArray) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a UIStatArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+var htmlTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller</title>
+</head>
+<body>
+{{if .Boot}}
+<div style="background: rgb(255, 200, 200); border: 2px solid red; padding: 5px">
+<b>Target kernel is broken: {{.Boot.Failures}} consecutive boot failures, rebooting with backoff.</b><br>
+Last failure on {{.Boot.Instance}} at {{.Boot.Time.Format "2006/01/02 15:04:05"}} (saved to boot-failure.log in workdir):
+<pre>{{.Boot.Error}}</pre>
+</div>
+<br>
+{{end}}
+{{if .Storm}}
+<div style="background: rgb(255, 230, 180); border: 2px solid orange; padding: 5px">
+<b>Crash storm: <a href='/crash?id={{.Storm.ID}}'>{{.Storm.Title}}</a> dominates crashes since {{.Storm.Start.Format "2006/01/02 15:04:05"}}.</b><br>
+{{if .Storm.Calls}}Syscalls {{range $c := .Storm.Calls}}{{$c}} {{end}}are {{if eq .Storm.Mode "disable"}}disabled{{else}}deprioritized{{end}} until {{.Storm.Until.Format "15:04:05"}}.
+{{else if eq .Storm.Mode "none"}}Set crash_storm config param to disable or deprioritize its syscalls automatically.
+{{else}}Its syscalls will be {{.Storm.Mode}}d once the crash is reproduced.{{end}}
+</div>
+<br>
+{{end}}
+Uptime: {{.Uptime}}<br>
+Corpus: {{.CorpusSize}} (<a href='/corpus/download'>download</a>)<br>
+Triage queue len: {{.TriageQueue}}<br>
+<a href='/instances'>VMs: {{.RunningVMs}} running, {{.AllowedVMs}} allowed</a><br>
+Cover mem: {{.CorpusCoverMem}} + {{.CallCoverMem}} <br>
+{{if .CoverSize}}<a href='/cover'>Cover: {{.CoverSize}}</a> (<a href='/coverfiles'>by file</a>, <a href='/subsystems'>by subsystem over time</a>) <br>{{end}}
+<a href='/crashes'>Crashes</a> <br>
+<a href='/calls'>Syscall stats</a> <br>
+<form action='/restart' method='post' onsubmit='return confirm("Restart the manager?")'><input type='submit' value='Graceful restart'></form>
+{{if .Experiment}}<a href='/experiment'>Experiment</a> <br>{{end}}
+{{if .Kernels}}<a href='/kernels'>Kernels</a> <br>{{end}}
+<br>
+Stats: <br>
+{{range $stat := $.Stats}}
+	{{$stat.Name}}: {{$stat.Value}}<br>
+{{end}}
+<br>
+{{range $c := $.Calls}}
+	{{$c.Name}} <a href='/corpus?call={{$c.Name}}'>inputs:{{$c.Inputs}}</a> <a href='/cover?call={{$c.Name}}'>cover:{{$c.Cover}}</a> <a href='/coverfiles?call={{$c.Name}}'>files</a> <a href='/prio?call={{$c.Name}}'>prio</a> <br>
+{{end}}
+</body></html>
+`))
+
+var corpusTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller corpus</title>
+</head>
+<body>
+{{range $c := $}}
+	<a href='/input?sig={{$c.Sig}}' title="{{$c.Full}}">{{$c.Short}}</a> <a href='/cover?call={{$c.N}}'>cover:{{$c.Cover}}</a> <br>
+{{end}}
+</body></html>
+`))
+
+var crashesTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller crashes</title>
+</head>
+<body>
+<table>
+	<tr>
+		<th>Title</th>
+		<th>Guilty file</th>
+		<th>Subsystem</th>
+		<th>Count</th>
+		<th>Logs</th>
+		<th>Last</th>
+		<th>Repro</th>
+	</tr>
+	{{range $c := $}}
+	<tr>
+		<td><a href='/crash?id={{$c.ID}}'>{{$c.Title}}</a></td>
+		<td>{{$c.Guilty}}</td>
+		<td>{{$c.Subsystem}}</td>
+		<td>{{$c.Count}}</td>
+		<td>{{$c.Logs}}</td>
+		<td>{{$c.Last}}</td>
+		<td>{{if $c.Repro}}<a href='/crash?id={{$c.ID}}&log=repro.prog'>yes</a>{{end}}</td>
+	</tr>
+	{{end}}
+</table>
+</body></html>
+`))
+
+var crashTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller crash {{.Title}}</title>
+</head>
+<body>
+{{.Title}} <br>
+{{if .Guilty}}Guilty file: {{.Guilty}} <br>{{end}}
+Crashes in this run: {{.Count}} <br>
+Bug report: {{range $t := .Templates}}<a href='/crash/report?id={{$.ID}}&template={{$t}}'>{{$t}}</a> {{end}}<br>
+{{if .Repro}}
+	<a href='/crash?id={{.ID}}&log=repro.prog'>Reproducer</a> <br>
+	<a href='/crash?id={{.ID}}&log=repro.c'>C reproducer</a> <br>
+	{{if .Trace}}<a href='/crash?id={{.ID}}&log=repro.trace'>Reproducer trace</a> <br>{{end}}
+	{{if .Tracing}}
+		Tracing... <br>
+	{{else if .CanTrace}}
+	<form action='/crash/trace' method='post'>
+		<input type='hidden' name='id' value='{{.ID}}'>
+		<input type='submit' value='Trace reproducer'>
+	</form>
+	{{end}}
+{{end}}
+<br>
+{{range $log := .Logs}}
+	<a href='/crash?id={{$.ID}}&log={{$log}}'>{{$log}}</a> <br>
+{{end}}
+</body></html>
+`))
+
+type UIPrioData struct {
+	Call  string
+	Prios []UIPrio
+}
+
+type UIPrio struct {
+	Call string
+	Prio float32
+}
+
+type UIPrioArray []UIPrio
+
+func (a UIPrioArray) Len() int           { return len(a) }
+func (a UIPrioArray) Less(i, j int) bool { return a[i].Prio > a[j].Prio }
+func (a UIPrioArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+var prioTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller priorities</title>
+</head>
+<body>
+Priorities for {{$.Call}} <br> <br>
+{{range $p := $.Prios}}
+	{{printf "%.4f\t%s" $p.Prio $p.Call}} <br>
+{{end}}
+</body></html>
+`))
@@ -0,0 +1,183 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"io/ioutil"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/syzkaller/prog"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// hubFresh returns true if syz-hub has not seen the corpus yet.
+// Workdirs created before the marker file set corpus database version instead.
+func (mgr *Manager) hubFresh() bool {
+	if _, err := os.Stat(mgr.hubMarker); err == nil {
+		return false
+	}
+	return mgr.corpusDB.Version == 0
+}
+
+// hubSyncLoop periodically exchanges corpus with syz-hub (cfg.Hub_Addr):
+// uploads programs added to/deleted from the corpus since the last sync
+// and new reproducers, and adds programs and reproducers found by other managers to candidates.
+// Syncs happen only when the triage queue is empty, so that the hub's
+// view of the corpus is reasonably precise and we don't pile up candidates.
+func (mgr *Manager) hubSyncLoop() {
+	mgr.mu.Lock()
+	empty := len(mgr.corpusDB.Records) == 0
+	mgr.mu.Unlock()
+	if empty {
+		// The corpus was deleted, the hub needs to send us everything again.
+		os.Remove(mgr.hubMarker)
+	}
+	var hub *rpc.Client
+	var hubCorpus map[string]bool // programs that hub knows we have
+	for atomic.LoadUint32(&mgr.shutdown) == 0 {
+		time.Sleep(time.Minute)
+		mgr.mu.Lock()
+		if len(mgr.candidates) != 0 || mgr.targetCalls == nil {
+			mgr.mu.Unlock()
+			continue
+		}
+		corpus := make(map[string][]byte)
+		for _, inp := range mgr.corpus {
+			corpus[hashString(inp.Prog)] = inp.Prog
+		}
+		var calls []string
+		for c := range mgr.reachableCalls() {
+			calls = append(calls, c.Name)
+		}
+		fresh := mgr.hubFresh()
+		repros := mgr.hubRepros
+		mgr.hubRepros = nil
+		mgr.mu.Unlock()
+
+		if hub == nil {
+			conn, err := jsonrpc.Dial("tcp", mgr.cfg.Hub_Addr)
+			if err != nil {
+				mgr.logf(0, "failed to connect to hub at %v: %v", mgr.cfg.Hub_Addr, err)
+				mgr.returnHubRepros(repros)
+				continue
+			}
+			a := &HubConnectArgs{
+				Name:  mgr.cfg.Name,
+				Key:   mgr.cfg.Hub_Key,
+				Fresh: fresh,
+				Calls: calls,
+			}
+			for _, data := range corpus {
+				a.Corpus = append(a.Corpus, data)
+			}
+			if err := conn.Call("Hub.Connect", a, nil); err != nil {
+				mgr.logf(0, "hub connect failed: %v", err)
+				conn.Close()
+				mgr.returnHubRepros(repros)
+				continue
+			}
+			if fresh {
+				// Remember that the hub has seen this corpus,
+				// so that we are not sent the whole hub corpus again after restart.
+				if err := ioutil.WriteFile(mgr.hubMarker, nil, 0640); err != nil {
+					mgr.logf(0, "failed to write hub marker: %v", err)
+				}
+			}
+			hub = conn
+			hubCorpus = make(map[string]bool)
+			for sig := range corpus {
+				hubCorpus[sig] = true
+			}
+			mgr.logf(0, "connected to hub at %v, corpus %v", mgr.cfg.Hub_Addr, len(corpus))
+		}
+
+		a := &HubSyncArgs{
+			Name:   mgr.cfg.Name,
+			Key:    mgr.cfg.Hub_Key,
+			Repros: repros,
+		}
+		for sig, data := range corpus {
+			if !hubCorpus[sig] {
+				a.Add = append(a.Add, data)
+			}
+		}
+		for sig := range hubCorpus {
+			if corpus[sig] == nil {
+				a.Del = append(a.Del, sig)
+			}
+		}
+		r := new(HubSyncRes)
+		if err := hub.Call("Hub.Sync", a, r); err != nil {
+			mgr.logf(0, "hub sync failed: %v", err)
+			hub.Close()
+			hub = nil
+			mgr.returnHubRepros(repros)
+			continue
+		}
+		for _, data := range a.Add {
+			hubCorpus[hashString(data)] = true
+		}
+		for _, sig := range a.Del {
+			delete(hubCorpus, sig)
+		}
+
+		mgr.mu.Lock()
+		dropped := 0
+		for i, data := range append(r.Inputs, r.Repros...) {
+			if !mgr.enabledProgram(data) {
+				dropped++
+				continue
+			}
+			if i < len(r.Inputs) {
+				mgr.importProvenance(data, "hub", hubSource(r.Sources, i), "")
+			} else {
+				mgr.importProvenance(data, "hub repro", hubSource(r.ReproSources, i-len(r.Inputs)), "")
+			}
+			mgr.candidates = append(mgr.candidates, data)
+		}
+		if len(r.Inputs)+len(r.Repros) != 0 {
+			mgr.stats["hub new inputs"] += uint64(len(r.Inputs))
+			mgr.stats["hub new repros"] += uint64(len(r.Repros))
+			mgr.prioritizeCandidates()
+		}
+		mgr.mu.Unlock()
+		mgr.logf(0, "hub sync: add %v, del %v, new %v, new repros %v, dropped %v",
+			len(a.Add), len(a.Del), len(r.Inputs), len(r.Repros), dropped)
+	}
+}
+
+// hubSource returns name of the manager that sent the i-th program
+// (older hubs don't send sources).
+func hubSource(sources []string, i int) string {
+	if i < len(sources) {
+		return sources[i]
+	}
+	return ""
+}
+
+// returnHubRepros queues reproducers that were not sent to hub for the next sync.
+func (mgr *Manager) returnHubRepros(repros [][]byte) {
+	mgr.mu.Lock()
+	mgr.hubRepros = append(repros, mgr.hubRepros...)
+	mgr.mu.Unlock()
+}
+
+// enabledProgram returns true if data is a valid program that uses only enabled syscalls.
+// Must be called with mgr.mu held.
+func (mgr *Manager) enabledProgram(data []byte) bool {
+	p, err := prog.Deserialize(data)
+	if err != nil {
+		return false
+	}
+	for _, c := range p.Calls {
+		if !mgr.syscalls[c.Meta.ID] {
+			return false
+		}
+	}
+	return true
+}
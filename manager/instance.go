@@ -0,0 +1,216 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Instance is the state of a running VM shown on the web UI.
+// All fields are protected by mgr.mu.
+type Instance struct {
+	Name          string
+	Procs         int
+	Sandbox       string
+	Strategy      string
+	Features      []string
+	Ports         []string // forwarded guest ports as "GUEST->localhost:HOST" (cfg.Net_Fwd)
+	Started       time.Time
+	Execs         uint64
+	LastCrash     string
+	LastCrashTime time.Time
+
+	console    []byte            // tail of console output
+	restart    chan string       // requests instance restart, receives the reason
+	diskFull   bool              // the fuzzer reported guest disk full (see disk.go)
+	phases     map[string]uint64 // fuzzer time per phase (see profile.go)
+	profileReq string            // pprof profile to request on the next poll
+	profile    chan *ProfileArgs // receives requested profiles
+}
+
+const consoleTail = 64 << 10
+
+func (mgr *Manager) addInstance(inst *Instance) {
+	inst.Started = time.Now()
+	inst.restart = make(chan string, 1)
+	inst.profile = make(chan *ProfileArgs, 1)
+	mgr.mu.Lock()
+	mgr.instances[inst.Name] = inst
+	mgr.mu.Unlock()
+}
+
+func (mgr *Manager) removeInstance(name string) {
+	mgr.mu.Lock()
+	delete(mgr.instances, name)
+	mgr.mu.Unlock()
+}
+
+// appendConsole must be called under mgr.mu.
+func (inst *Instance) appendConsole(out []byte) {
+	inst.console = append(inst.console, out...)
+	if len(inst.console) > 2*consoleTail {
+		inst.console = append([]byte{}, inst.console[len(inst.console)-consoleTail:]...)
+	}
+}
+
+func (mgr *Manager) httpInstances(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	var data []UIInstance
+	for _, inst := range mgr.instances {
+		data = append(data, mgr.uiInstance(inst))
+	}
+	mgr.mu.Unlock()
+	sort.Sort(UIInstanceArray(data))
+	if err := instancesTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (mgr *Manager) httpInstance(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	inst := mgr.instances[r.FormValue("name")]
+	var data UIInstance
+	if inst != nil {
+		data = mgr.uiInstance(inst)
+	}
+	mgr.mu.Unlock()
+	if inst == nil {
+		http.Error(w, fmt.Sprintf("unknown instance %q", r.FormValue("name")), http.StatusNotFound)
+		return
+	}
+	if err := instanceTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (mgr *Manager) httpInstanceConsole(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	inst := mgr.instances[r.FormValue("name")]
+	var console []byte
+	if inst != nil {
+		console = append(console, inst.console...)
+	}
+	mgr.mu.Unlock()
+	if inst == nil {
+		http.Error(w, fmt.Sprintf("unknown instance %q", r.FormValue("name")), http.StatusNotFound)
+		return
+	}
+	if len(console) > consoleTail {
+		console = console[len(console)-consoleTail:]
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(console)
+}
+
+func (mgr *Manager) httpInstanceRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "restart requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.FormValue("name")
+	mgr.mu.Lock()
+	inst := mgr.instances[name]
+	if inst != nil {
+		select {
+		case inst.restart <- "user request":
+		default:
+		}
+	}
+	mgr.mu.Unlock()
+	if inst == nil {
+		http.Error(w, fmt.Sprintf("unknown instance %q", name), http.StatusNotFound)
+		return
+	}
+	mgr.logf(0, "%v: restart requested from web UI", name)
+	http.Redirect(w, r, "/instances", http.StatusSeeOther)
+}
+
+func (mgr *Manager) uiInstance(inst *Instance) UIInstance {
+	ui := UIInstance{
+		Name:     inst.Name,
+		Procs:    inst.Procs,
+		Sandbox:  inst.Sandbox,
+		Strategy: inst.Strategy,
+		Features: inst.Features,
+		Ports:    inst.Ports,
+		Uptime:   time.Since(inst.Started) - time.Since(inst.Started)%time.Second,
+		Execs:    inst.Execs,
+		Phases:   phaseShares(inst.phases),
+	}
+	if inst.LastCrash != "" {
+		ui.LastCrash = fmt.Sprintf("%v (%v ago)", inst.LastCrash,
+			time.Since(inst.LastCrashTime)-time.Since(inst.LastCrashTime)%time.Second)
+	}
+	return ui
+}
+
+type UIInstance struct {
+	Name      string
+	Procs     int
+	Sandbox   string
+	Strategy  string
+	Features  []string
+	Ports     []string
+	Uptime    time.Duration
+	Execs     uint64
+	LastCrash string
+	Phases    []UIStat
+}
+
+type UIInstanceArray []UIInstance
+
+func (a UIInstanceArray) Len() int           { return len(a) }
+func (a UIInstanceArray) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a UIInstanceArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+var instancesTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller instances</title>
+</head>
+<body>
+{{range $i := $}}
+	<a href='/instance?name={{$i.Name}}'>{{$i.Name}}</a> up {{$i.Uptime}}, {{$i.Execs}} execs{{if $i.LastCrash}}, last crash: {{$i.LastCrash}}{{end}} <br>
+{{end}}
+</body></html>
+`))
+
+var instanceTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller instance {{.Name}}</title>
+</head>
+<body>
+Instance: {{.Name}}<br>
+Uptime: {{.Uptime}}<br>
+Execs: {{.Execs}}<br>
+Procs: {{.Procs}}<br>
+Sandbox: {{.Sandbox}}<br>
+Strategy: {{.Strategy}}<br>
+Features: {{range $f := .Features}}{{$f}} {{end}}<br>
+{{if .Ports}}Forwarded ports: {{range $p := .Ports}}{{$p}} {{end}}<br>{{end}}
+Last crash: {{if .LastCrash}}{{.LastCrash}}{{else}}none{{end}}<br>
+{{if .Phases}}Fuzzer time: {{range $p := .Phases}}{{$p.Name}} {{$p.Value}} {{end}}<br>{{end}}
+<br>
+<a href='/instance/console?name={{.Name}}'>Console tail</a> <br>
+Fuzzer profile (takes up to a minute):
+	<a href='/instance/pprof?name={{.Name}}&kind=cpu'>cpu</a>
+	<a href='/instance/pprof?name={{.Name}}&kind=heap'>heap</a>
+	<a href='/instance/pprof?name={{.Name}}&kind=goroutine'>goroutine</a>
+	<a href='/instance/pprof?name={{.Name}}&kind=threadcreate'>threadcreate</a>
+<br>
+<form action='/instance/restart' method='post'>
+	<input type='hidden' name='name' value='{{.Name}}'>
+	<input type='submit' value='Restart'>
+</form>
+</body></html>
+`))
@@ -0,0 +1,95 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	"github.com/google/syzkaller/sys"
+)
+
+// callConfigs maps calls that create resources of optional kernel subsystems (name prefixes)
+// to the kernel config options the subsystems require. Calls that use the resources are
+// unreachable without these calls (see sys.TransitivelyEnabledCalls), so they are not listed.
+var callConfigs = []struct {
+	call   string
+	config string
+}{
+	{"bpf$", "CONFIG_BPF_SYSCALL"},
+	{"perf_event_open", "CONFIG_PERF_EVENTS"},
+	{"add_key", "CONFIG_KEYS"},
+	{"request_key", "CONFIG_KEYS"},
+	{"keyctl$", "CONFIG_KEYS"},
+	{"socket$alg", "CONFIG_CRYPTO_USER_API"},
+	{"socket$bt_", "CONFIG_BT"},
+	{"socket$inet6", "CONFIG_IPV6"},
+	{"socket$kcm", "CONFIG_AF_KCM"},
+	{"socket$netrom", "CONFIG_NETROM"},
+	{"socket$nfc_", "CONFIG_NFC"},
+	{"socket$sctp", "CONFIG_IP_SCTP"},
+	{"socketpair$sctp", "CONFIG_IP_SCTP"},
+	{"syz_emit_ethernet", "CONFIG_TUN"},
+	{"syz_extract_tcp_res", "CONFIG_TUN"},
+	{"syz_open_dev$tun", "CONFIG_TUN"},
+	{"syz_fuse_mount", "CONFIG_FUSE_FS"},
+	{"syz_fuseblk_mount", "CONFIG_FUSE_FS"},
+	{"syz_open_dev$cuse", "CONFIG_CUSE"},
+	{"syz_kvm_setup_cpu", "CONFIG_KVM"},
+	{"syz_open_dev$kvm", "CONFIG_KVM"},
+	{"syz_mount_image$btrfs", "CONFIG_BTRFS_FS"},
+	{"syz_mount_image$ext4", "CONFIG_EXT4_FS"},
+	{"syz_mount_image$vfat", "CONFIG_VFAT_FS"},
+	{"syz_open_dev$binder", "CONFIG_ANDROID_BINDER_IPC"},
+	{"syz_open_dev$ion", "CONFIG_ION"},
+	{"syz_open_dev$dri", "CONFIG_DRM"},
+	{"syz_open_dev$snd", "CONFIG_SND"},
+	{"syz_open_dev$usbmon", "CONFIG_USB_MON"},
+	{"syz_open_dev$vhci", "CONFIG_BT_HCIVHCI"},
+	{"syz_usb_", "CONFIG_USB_GADGET"},
+}
+
+// loadKernelConfig returns the set of enabled (=y or =m) options in kernel .config file.
+// Returns nil if the config is not a local file.
+func loadKernelConfig(file string) (map[string]bool, error) {
+	if file == "" || strings.Contains(file, "://") {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseKernelConfig(data), nil
+}
+
+func parseKernelConfig(data []byte) map[string]bool {
+	enabled := make(map[string]bool)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		ln := s.Text()
+		eq := strings.IndexByte(ln, '=')
+		if !strings.HasPrefix(ln, "CONFIG_") || eq == -1 {
+			continue
+		}
+		if v := ln[eq+1:]; v == "y" || v == "m" {
+			enabled[ln[:eq]] = true
+		}
+	}
+	return enabled
+}
+
+// configEnabled returns false if kconfig is known and lacks an option required by call c.
+func configEnabled(kconfig map[string]bool, c *sys.Call) bool {
+	if kconfig == nil {
+		return true
+	}
+	for _, cc := range callConfigs {
+		if strings.HasPrefix(c.Name, cc.call) && !kconfig[cc.config] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// KernelTag identifies the kernel build under test.
+// It is stamped on all artifacts (corpus programs, crashes, repros),
+// so that they remain interpretable after the kernel is replaced.
+type KernelTag struct {
+	Version string // "Linux version ..." string extracted from vmlinux
+	Commit  string // HEAD commit of kernel source tree, if configured
+}
+
+func (tag *KernelTag) String() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "kernel: %v\n", tag.Version)
+	if tag.Commit != "" {
+		fmt.Fprintf(buf, "commit: %v\n", tag.Commit)
+	}
+	return buf.String()
+}
+
+func extractKernelTag(vmlinux, src string) (*KernelTag, error) {
+	tag := new(KernelTag)
+	var err error
+	if tag.Version, err = extractKernelVersion(vmlinux); err != nil {
+		return nil, err
+	}
+	if src != "" {
+		out, err := exec.Command("git", "-C", src, "rev-parse", "HEAD").CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kernel commit: %v\n%s", err, out)
+		}
+		tag.Commit = strings.TrimSpace(string(out))
+	}
+	return tag, nil
+}
+
+// extractKernelVersion finds linux_banner in .rodata of vmlinux,
+// the rest of the image (hundreds of MBs with debug info) is not read.
+func extractKernelVersion(vmlinux string) (string, error) {
+	f, err := elf.Open(vmlinux)
+	if err != nil {
+		return "", fmt.Errorf("failed to open vmlinux: %v", err)
+	}
+	defer f.Close()
+	rodata := f.Section(".rodata")
+	if rodata == nil {
+		return "", fmt.Errorf("no .rodata section in %v", vmlinux)
+	}
+	data, err := rodata.Data()
+	if err != nil {
+		return "", fmt.Errorf("failed to read .rodata of %v: %v", vmlinux, err)
+	}
+	// linux_banner is "Linux version %s (%s@%s) (%s) %s\n".
+	// Some kernels also contain "Linux version " in other strings,
+	// so prefer the one that looks like the banner.
+	prefix := []byte("Linux version ")
+	for pos := 0; ; {
+		idx := bytes.Index(data[pos:], prefix)
+		if idx == -1 {
+			break
+		}
+		start := pos + idx
+		end := bytes.IndexAny(data[start:], "\n\x00")
+		if end == -1 {
+			end = len(data) - start
+		}
+		version := string(data[start : start+end])
+		if strings.Contains(version, "@") {
+			return version, nil
+		}
+		pos = start + len(prefix)
+	}
+	return "", fmt.Errorf("failed to find kernel version in %v", vmlinux)
+}
@@ -0,0 +1,254 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/cover"
+	"github.com/google/syzkaller/sys"
+)
+
+// Kernels runs a mix of VMs with different kernels (cfg.Kernels), e.g. with and without
+// a patch series, and accounts coverage and crashes separately for each kernel variant.
+// Corpus is shared between variants, and corpus coverage mixes PCs of all kernels
+// (coverage pages are symbolized against the main vmlinux).
+// All methods must be called with mgr.mu held and are no-op on nil receiver.
+type Kernels struct {
+	variants []*KernelGroup
+	members  map[string]*kernelMember // fuzzer name -> member
+}
+
+type kernelMember struct {
+	variant *KernelGroup
+	start   time.Time
+}
+
+// KernelGroup is stats of VMs that boot one of cfg.Kernels.
+type KernelGroup struct {
+	Name       string
+	cfg        *config.Config // main config with the variant kernel
+	tag        *KernelTag
+	VMs        int // number of currently running VMs
+	VMTime     time.Duration
+	Execs      uint64
+	Cover      []cover.Cover
+	Crashes    int
+	CrashTypes map[string]int
+}
+
+func newKernels(cfg *config.Config, mainTag *KernelTag, logf logger) *Kernels {
+	if len(cfg.Kernels) == 0 {
+		return nil
+	}
+	ks := &Kernels{
+		members: make(map[string]*kernelMember),
+	}
+	for i := range cfg.Kernels {
+		kcfg := config.KernelConfig(cfg, &cfg.Kernels[i])
+		tag := mainTag
+		if kcfg.Vmlinux != cfg.Vmlinux {
+			var err error
+			if tag, err = extractKernelTag(kcfg.Vmlinux, ""); err != nil {
+				logf(0, "failed to identify kernel %v: %v", cfg.Kernels[i].Name, err)
+				tag = &KernelTag{Version: "unknown"}
+			}
+		}
+		logf(0, "kernel %v: %v", cfg.Kernels[i].Name, tag.Version)
+		ks.variants = append(ks.variants, &KernelGroup{
+			Name:       cfg.Kernels[i].Name,
+			cfg:        kcfg,
+			tag:        tag,
+			Cover:      make([]cover.Cover, sys.CallCount),
+			CrashTypes: make(map[string]int),
+		})
+	}
+	return ks
+}
+
+func (ks *Kernels) join(name string, variant int) {
+	if ks == nil {
+		return
+	}
+	v := ks.variants[variant]
+	ks.members[name] = &kernelMember{v, time.Now()}
+	v.VMs++
+}
+
+func (ks *Kernels) leave(name string) {
+	if ks == nil {
+		return
+	}
+	if m := ks.members[name]; m != nil {
+		m.variant.VMs--
+		m.variant.VMTime += time.Since(m.start)
+		delete(ks.members, name)
+	}
+}
+
+func (ks *Kernels) variant(name string) *KernelGroup {
+	if ks == nil || ks.members[name] == nil {
+		return nil
+	}
+	return ks.members[name].variant
+}
+
+// lookup returns kernel variant by name, it does not need mgr.mu (the variant list is immutable).
+func (ks *Kernels) lookup(name string) *KernelGroup {
+	if ks == nil {
+		return nil
+	}
+	for _, v := range ks.variants {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (ks *Kernels) addExecs(name string, execs uint64) {
+	if v := ks.variant(name); v != nil {
+		v.Execs += execs
+	}
+}
+
+func (ks *Kernels) addCover(name string, call int, cov []uint32) {
+	v := ks.variant(name)
+	if v == nil {
+		return
+	}
+	v.Cover[call] = cover.Union(v.Cover[call], cov)
+}
+
+func (ks *Kernels) addCrash(name, title string) {
+	if v := ks.variant(name); v != nil {
+		v.Crashes++
+		v.CrashTypes[title]++
+	}
+}
+
+func (mgr *Manager) httpKernels(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	ks := mgr.kernels
+	if ks == nil {
+		http.Error(w, "no kernels are configured", http.StatusNotFound)
+		return
+	}
+	data := &UIKernelsData{}
+	titles := make(map[string]bool)
+	for _, v := range ks.variants {
+		t := v.VMTime
+		for _, m := range ks.members {
+			if m.variant == v {
+				t += time.Since(m.start)
+			}
+		}
+		uv := UIKernelVariant{
+			Name:    v.Name,
+			Version: v.tag.Version,
+			VMs:     v.VMs,
+			Hours:   fmt.Sprintf("%.1f", t.Hours()),
+			Execs:   v.Execs,
+			Crashes: v.Crashes,
+		}
+		for _, cov := range v.Cover {
+			uv.Cover += len(cov)
+		}
+		data.Kernels = append(data.Kernels, uv)
+		for title := range v.CrashTypes {
+			titles[title] = true
+		}
+	}
+	for title := range titles {
+		row := &UIKernelCrash{Title: title, ID: hashString([]byte(title))}
+		for _, v := range ks.variants {
+			row.Counts = append(row.Counts, v.CrashTypes[title])
+		}
+		data.Crashes = append(data.Crashes, row)
+	}
+	sort.Sort(UIKernelCrashArray(data.Crashes))
+	if err := kernelsTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+type UIKernelsData struct {
+	Kernels []UIKernelVariant
+	Crashes []*UIKernelCrash
+}
+
+type UIKernelVariant struct {
+	Name    string
+	Version string
+	VMs     int
+	Hours   string
+	Execs   uint64
+	Cover   int
+	Crashes int
+}
+
+type UIKernelCrash struct {
+	Title  string
+	ID     string
+	Counts []int // per kernel variant
+}
+
+type UIKernelCrashArray []*UIKernelCrash
+
+func (a UIKernelCrashArray) Len() int           { return len(a) }
+func (a UIKernelCrashArray) Less(i, j int) bool { return a[i].Title < a[j].Title }
+func (a UIKernelCrashArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+var kernelsTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller kernels</title>
+</head>
+<body>
+<table>
+	<tr>
+		<th>Kernel</th>
+		<th>Version</th>
+		<th>Running VMs</th>
+		<th>VM hours</th>
+		<th>Execs</th>
+		<th>Cover</th>
+		<th>Crashes</th>
+	</tr>
+	{{range $k := $.Kernels}}
+	<tr>
+		<td>{{$k.Name}}</td>
+		<td>{{$k.Version}}</td>
+		<td>{{$k.VMs}}</td>
+		<td>{{$k.Hours}}</td>
+		<td>{{$k.Execs}}</td>
+		<td>{{$k.Cover}}</td>
+		<td>{{$k.Crashes}}</td>
+	</tr>
+	{{end}}
+</table>
+<br>
+<b>Crashes by kernel:</b>
+<table>
+	<tr>
+		<th>Title</th>
+		{{range $k := $.Kernels}}<th>{{$k.Name}}</th>{{end}}
+	</tr>
+	{{range $c := $.Crashes}}
+	<tr>
+		<td><a href='/crash?id={{$c.ID}}'>{{$c.Title}}</a></td>
+		{{range $n := $c.Counts}}<td>{{$n}}</td>{{end}}
+	</tr>
+	{{end}}
+</table>
+</body></html>
+`))
@@ -0,0 +1,105 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/google/syzkaller/report"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Memory leaks are found by periodic kmemleak scans in the fuzzer (see syz-fuzzer/leak.go)
+// and reported with Manager.Leak, the VM continues fuzzing. Every leak is saved as a crash
+// titled "memory leak in FUNC" (FUNC is the allocation site), the crash log contains
+// the programs executed before the scan in the console log format followed by the kmemleak report.
+// Leaks are not reproduced: repro detects crashes in console output only.
+
+// maxLeakLogs limits number of logs saved per leak in a single manager run,
+// the same leak is usually reported after every VM restart.
+const maxLeakLogs = 10
+
+// Leak receives a memory leak found by a fuzzer.
+func (mgr *Manager) Leak(a *LeakArgs, r *int) error {
+	mgr.mu.Lock()
+	if err := mgr.authFuzzer(a.Name, a.Key, true); err != nil {
+		mgr.mu.Unlock()
+		return err
+	}
+	mgr.stats["leaks"]++
+	suppressions, knownCrashes := mgr.suppressions, mgr.knownCrashes
+	mgr.mu.Unlock()
+
+	buf := new(bytes.Buffer)
+	for _, data := range a.Progs {
+		fmt.Fprintf(buf, "executing program 0:\n%s\n", data)
+	}
+	buf.Write(a.Report)
+	output := buf.Bytes()
+	for _, re := range suppressions {
+		if re.Match(output) {
+			mgr.audit(&AuditEvent{Type: "crash suppressed", VM: a.Name, Title: a.Title, Reason: re.String()})
+			return nil
+		}
+	}
+	for _, re := range knownCrashes {
+		if re.MatchString(a.Title) {
+			mgr.audit(&AuditEvent{Type: "crash suppressed", VM: a.Name, Title: a.Title, Reason: "title:" + re.String()})
+			return nil
+		}
+	}
+	// Don't symbolize leaks that won't be saved anyway.
+	mgr.mu.Lock()
+	full := mgr.crashTypes[a.Title] >= maxLeakLogs
+	if full {
+		mgr.crashTypes[a.Title]++
+	}
+	mgr.mu.Unlock()
+	if full {
+		return nil
+	}
+	crashReport := mgr.crashReport(mgr.cfg.Vmlinux, output)
+	rep := &Report{
+		Title:  a.Title,
+		Time:   time.Now(),
+		VM:     a.Name,
+		Kernel: mgr.kernelTag.Version,
+		Commit: mgr.kernelTag.Commit,
+		Guilty: report.ExtractGuiltyFile(crashReport),
+		Output: string(output),
+	}
+	if !mgr.triage(rep) {
+		mgr.audit(&AuditEvent{Type: "crash ignored", VM: a.Name, Title: rep.Title, Reason: "triage"})
+		return nil
+	}
+	what := rep.Title
+
+	mgr.mu.Lock()
+	mgr.crashTypes[what]++
+	if mgr.crashTypes[what] > maxLeakLogs {
+		mgr.mu.Unlock()
+		return nil
+	}
+	filename, err := mgr.saveCrashLog(what, output, crashReport, output)
+	if err != nil {
+		mgr.logf(0, "%v: failed to save leak '%v': %v", a.Name, what, err)
+	} else {
+		mgr.logf(0, "%v: saved leak '%v' to %v", a.Name, what, filename)
+	}
+	mgr.emailCrash(what)
+	if inst := mgr.instances[a.Name]; inst != nil {
+		inst.LastCrash = what
+		inst.LastCrashTime = time.Now()
+	}
+	mgr.kernels.addCrash(a.Name, what)
+	mgr.publishCrash(rep)
+	mgr.mu.Unlock()
+	mgr.audit(&AuditEvent{Type: "crash", VM: a.Name, Title: what})
+	mgr.notifier.notify(what, output)
+	mgr.uploadCrash(what, output, crashReport)
+	mgr.exporter.exportCrash(rep)
+	return nil
+}
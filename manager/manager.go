@@ -0,0 +1,1206 @@
+// Copyright 2015 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/cover"
+	"github.com/google/syzkaller/db"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/report"
+	. "github.com/google/syzkaller/rpctype"
+	"github.com/google/syzkaller/sys"
+	"github.com/google/syzkaller/vm"
+	_ "github.com/google/syzkaller/vm/adb"
+	_ "github.com/google/syzkaller/vm/docker"
+	_ "github.com/google/syzkaller/vm/emulator"
+	_ "github.com/google/syzkaller/vm/kvm"
+	_ "github.com/google/syzkaller/vm/odroid"
+	_ "github.com/google/syzkaller/vm/qemu"
+)
+
+type Manager struct {
+	cfg       *config.Config
+	crashdir  string
+	port      int
+	corpusDB  *db.DB
+	hubMarker string // the file exists if syz-hub has seen the corpus (see hubSyncLoop)
+	valuesDB  *db.DB
+	startTime time.Time
+	stats     map[string]uint64
+	phases    map[string]uint64 // fuzzer time per phase of the fuzzing loop (see profile.go)
+	shutdown  uint32
+	stop      chan bool // closed when fuzzing is stopped
+	stopOnce  sync.Once
+	bench     *Exporter // Options.Bench
+	benchDone chan bool // closed when the final bench record is written
+	reproDone chan bool // closed when reproLoop exits
+	scaler    *Scaler
+	notifier  *Notifier
+	exporter  *Exporter
+	kernelTag *KernelTag
+	rpcKey    string // key fuzzers must present in every RPC
+	verbosity int32  // see Options.Verbosity, accessed atomically
+	mux       *http.ServeMux
+	errMu     sync.Mutex
+	err       error // the error that stopped the campaign (see fail)
+	opts      *Options
+	subs      []chan *Report // see Campaign.Subscribe
+
+	mu              sync.Mutex
+	syscalls        map[int]bool
+	enabledSyscalls string
+	suppressions    []*regexp.Regexp
+	knownCrashes    []*regexp.Regexp   // title suppressions
+	targetCalls     map[string]bool    // calls supported on target, as reported by fuzzers
+	reachable       map[*sys.Call]bool // cached result of reachableCalls
+	kernelConfig    map[string]bool    // enabled options of cfg.Kernel_Config, nil if unknown
+
+	configGen int // incremented on every config reload
+
+	stopReason string
+	restarting bool // graceful restart is requested, see restart.go
+	crashTypes map[string]int
+	reproQueue chan *ReproRequest
+	repros     map[string]*reproState
+	hubRepros  [][]byte // new reproducers to send to hub
+	traceQueue chan *TraceRequest
+	tracing    map[string]bool // trace files that are being generated
+	dashQueue  chan *DashRequest
+	boot       BootState
+	callStats  map[string]*CallStats     // per-syscall stats reported by fuzzers
+	resources  map[string]*ProgResources // results of resource-producing calls of corpus programs
+	subsys     []SubsysSnapshot          // coverage by subsystem over time
+
+	concolicQueue []BlockedProg   // programs to send to the concolic service
+	concolicSeen  map[string]bool // programs+calls that were already queued
+
+	recentCrashes []recentCrash // crashes in the last stormWindow
+	storm         *Storm        // current crash storm, if any
+
+	candidates     [][]byte // untriaged inputs
+	disabledHashes []string
+	corpus         []RpcInput
+	corpusCover    []cover.Cover
+	succeeded      map[int]bool // call IDs saved in corpus because they succeeded (errno feedback)
+	prios          [][]float32
+	values         *prog.ValuePool
+	provenance     map[string]*Provenance // provenance of imported programs and inputs derived from them
+	provenanceDB   *db.DB
+
+	fuzzers    map[string]*Fuzzer
+	instances  map[string]*Instance
+	experiment *Experiment
+	kernels    *Kernels
+	auditLog   *AuditLog
+}
+
+type Fuzzer struct {
+	name      string
+	input     int        // index of the next corpus program added after connect to send
+	pending   []RpcInput // old corpus programs that are not sent yet, see corpus.go
+	configGen int
+}
+
+// newManager loads the corpus and starts the HTTP and RPC servers, VMs are started by run.
+func newManager(cfg *config.Config, syscalls map[int]bool, suppressions []*regexp.Regexp, opts *Options) (*Manager, error) {
+	crashdir := filepath.Join(cfg.Workdir, "crashes")
+	os.MkdirAll(crashdir, 0700)
+
+	enabledSyscalls := serializeSyscalls(syscalls)
+	knownCrashes, err := config.TitleSuppressions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcKey := cfg.Rpc_Key
+	if rpcKey == "" {
+		var key [16]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return nil, fmt.Errorf("failed to generate rpc key: %v", err)
+		}
+		rpcKey = hex.EncodeToString(key[:])
+	}
+	mgr := &Manager{
+		cfg:             cfg,
+		opts:            opts,
+		rpcKey:          rpcKey,
+		verbosity:       int32(opts.Verbosity),
+		crashdir:        crashdir,
+		startTime:       time.Now(),
+		stats:           make(map[string]uint64),
+		syscalls:        syscalls,
+		enabledSyscalls: enabledSyscalls,
+		suppressions:    suppressions,
+		knownCrashes:    knownCrashes,
+		corpusCover:     make([]cover.Cover, sys.CallCount),
+		succeeded:       make(map[int]bool),
+		fuzzers:         make(map[string]*Fuzzer),
+		instances:       make(map[string]*Instance),
+		crashTypes:      make(map[string]int),
+		reproQueue:      make(chan *ReproRequest, reproQueueSize),
+		repros:          make(map[string]*reproState),
+		callStats:       make(map[string]*CallStats),
+		resources:       make(map[string]*ProgResources),
+		concolicSeen:    make(map[string]bool),
+		traceQueue:      make(chan *TraceRequest, traceQueueSize),
+		tracing:         make(map[string]bool),
+		dashQueue:       make(chan *DashRequest, dashQueueSize),
+		stop:            make(chan bool),
+		experiment:      newExperiment(cfg),
+	}
+
+	mgr.notifier = newNotifier(cfg, mgr.logf)
+	mgr.scaler = newScaler(cfg.Min_Count, cfg.Count, mgr.logf)
+	mgr.logf(1, "enabled syscalls: %v", enabledSyscalls)
+
+	kernelTag, err := extractKernelTag(cfg.Vmlinux, cfg.Kernel_Src)
+	if err != nil {
+		mgr.logf(0, "failed to identify kernel: %v", err)
+		kernelTag = &KernelTag{Version: "unknown"}
+	}
+	mgr.kernelTag = kernelTag
+	mgr.logf(0, "%v", kernelTag)
+	mgr.kernels = newKernels(cfg, kernelTag, mgr.logf)
+	if mgr.kernelConfig, err = loadKernelConfig(cfg.Kernel_Config); err != nil {
+		mgr.logf(0, "failed to load kernel config: %v", err)
+	}
+
+	if opts.Bench != "" {
+		if mgr.bench, err = newExporter(opts.Bench, mgr.logf); err != nil {
+			return nil, fmt.Errorf("failed to open bench file: %v", err)
+		}
+	}
+
+	if mgr.auditLog, err = newAuditLog(filepath.Join(cfg.Workdir, "events.jsonl")); err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	mgr.audit(&AuditEvent{Type: "manager started", Reason: kernelTag.Version})
+
+	if cfg.Export != "" {
+		exporter, err := newExporter(cfg.Export, mgr.logf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open export file: %v", err)
+		}
+		mgr.exporter = exporter
+		go mgr.exportStatsLoop(exporter, exportPeriod, nil)
+	}
+
+	mgr.logf(0, "loading corpus...")
+	corpusFile := "corpus.db"
+	if cfg.Corpus_Namespace != "" {
+		corpusFile = fmt.Sprintf("corpus-%v.db", cfg.Corpus_Namespace)
+	}
+	mgr.corpusDB, err = db.Open(filepath.Join(cfg.Workdir, corpusFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open corpus database: %v", err)
+	}
+	mgr.hubMarker = filepath.Join(cfg.Workdir, corpusFile+".hub")
+	if cfg.Corpus_Namespace == "" {
+		// The legacy corpus dir belongs to the main corpus.
+		if err := mgr.importCorpusDir(filepath.Join(cfg.Workdir, "corpus")); err != nil {
+			return nil, err
+		}
+	}
+	valuesDB, err := db.Open(filepath.Join(cfg.Workdir, "values.db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open values database: %v", err)
+	}
+	mgr.loadValues(valuesDB)
+	provDB, err := db.Open(filepath.Join(cfg.Workdir, "provenance.db"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open provenance database: %v", err)
+	}
+	mgr.loadProvenance(provDB)
+	for key, rec := range mgr.corpusDB.Records {
+		p, err := prog.Deserialize(rec.Val)
+		if err != nil {
+			mgr.logf(0, "deleting broken program: %v\n%s", err, rec.Val)
+			if err := mgr.corpusDB.Delete(key); err != nil {
+				return nil, fmt.Errorf("failed to delete program: %v", err)
+			}
+			continue
+		}
+		disabled := false
+		for _, c := range p.Calls {
+			if !syscalls[c.Meta.ID] {
+				disabled = true
+				break
+			}
+		}
+		if disabled {
+			// This program contains a disabled syscall.
+			// We won't execute it, but remeber its hash so
+			// it is not deleted during minimization.
+			mgr.disabledHashes = append(mgr.disabledHashes, key)
+			continue
+		}
+		mgr.addResourceBaseline(key, rec.Succeeded)
+		mgr.candidates = append(mgr.candidates, rec.Val)
+	}
+	mgr.loadCheckpoint()
+	if cfg.Seeds != "" {
+		mgr.loadSeeds(cfg.Seeds)
+	}
+	mgr.prioritizeCandidates()
+	mgr.logf(0, "loaded %v programs", len(mgr.corpusDB.Records))
+
+	// Create HTTP server.
+	mgr.initHttp()
+
+	// Create RPC server for fuzzers.
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on localhost:0: %v", err)
+	}
+	mgr.logf(0, "serving rpc on tcp://%v", ln.Addr())
+	mgr.port = ln.Addr().(*net.TCPAddr).Port
+	s := rpc.NewServer()
+	s.Register(mgr)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				mgr.logf(0, "failed to accept an rpc connection: %v", err)
+				continue
+			}
+			go s.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	return mgr, nil
+}
+
+// run starts VMs and background loops and returns when fuzzing is stopped and finished.
+func (mgr *Manager) run() {
+	cfg := mgr.cfg
+	if cfg.Max_Run_Time != 0 || cfg.Max_Execs != 0 {
+		go mgr.limitLoop()
+	}
+
+	if mgr.bench != nil {
+		mgr.benchDone = make(chan bool)
+		go mgr.exportStatsLoop(mgr.bench, mgr.opts.BenchPeriod, mgr.benchDone)
+	}
+
+	if cfg.Cover && cfg.Vmlinux != "" {
+		go mgr.subsysLoop()
+	}
+
+	if cfg.Reproduce {
+		mgr.reproDone = make(chan bool)
+		go mgr.reproLoop()
+	}
+
+	if cfg.Ftrace != "" {
+		go mgr.traceLoop()
+	}
+
+	if cfg.Hub_Addr != "" {
+		go mgr.hubSyncLoop()
+	}
+
+	if cfg.Concolic_Addr != "" {
+		go mgr.concolicLoop()
+	}
+
+	if cfg.Dashboard_Addr != "" {
+		go mgr.dashLoop()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(cfg.Count)
+	// The last instances run in memory pressure mode.
+	pressureCount := (cfg.Count*cfg.Pressure + 99) / 100
+	kernelSlots := config.AssignKernels(cfg)
+	for i := 0; i < cfg.Count; i++ {
+		index := i
+		first := i == 0
+		pressure := i >= cfg.Count-pressureCount
+		treatment := isTreatment(cfg, i)
+		instCfg, kernel := cfg, -1
+		if kernelSlots != nil {
+			kernel = kernelSlots[i]
+			instCfg = mgr.kernels.variants[kernel].cfg
+		}
+		go func() {
+			defer wg.Done()
+			for run := 0; ; run++ {
+				if !mgr.scaler.acquire() {
+					break
+				}
+				vmCfg, err := config.CreateVMConfig(instCfg)
+				if atomic.LoadUint32(&mgr.shutdown) != 0 {
+					mgr.scaler.release()
+					break
+				}
+				if err != nil {
+					mgr.scaler.release()
+					mgr.fail(fmt.Errorf("failed to create VM config: %v", err))
+					break
+				}
+				if pressure {
+					vmCfg.Mem = cfg.Pressure_Mem
+				}
+				err = mgr.runInstance(vmCfg, first, pressure, treatment, kernel, mgr.fuzzerSeed(index, run))
+				mgr.scaler.release()
+				if atomic.LoadUint32(&mgr.shutdown) != 0 {
+					break
+				}
+				if err != nil {
+					delay := mgr.bootFailed(vmCfg.Name, err)
+					mgr.audit(&AuditEvent{Type: "vm boot failed", VM: vmCfg.Name, Reason: firstLine(err.Error())})
+					mgr.logf(0, "%v: boot failed, retrying in %v: %v", vmCfg.Name, delay, firstLine(err.Error()))
+					select {
+					case <-time.After(delay):
+					case <-mgr.stop:
+					}
+				}
+			}
+		}()
+	}
+
+	if mgr.opts.Signals {
+		mgr.handleSignals()
+	}
+	wg.Wait()
+	if mgr.reproDone != nil {
+		// Don't leave repro VMs running, in particular across a graceful restart.
+		<-mgr.reproDone
+	}
+	if mgr.benchDone != nil {
+		<-mgr.benchDone
+		mgr.bench.close()
+	}
+	mgr.finish()
+	mgr.mu.Lock()
+	restarting := mgr.restarting
+	mgr.mu.Unlock()
+	if restarting {
+		mgr.restart()
+	}
+}
+
+// handleSignals reloads config on SIGHUP, restarts on SIGUSR1 and stops on SIGINT.
+func (mgr *Manager) handleSignals() {
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGHUP)
+		for range c {
+			mgr.reloadConfig()
+		}
+	}()
+
+	go func() {
+		c := make(chan os.Signal, 1)
+		notifyRestart(c)
+		<-c
+		mgr.logf(0, "restarting on SIGUSR1...")
+		mgr.requestRestart("SIGUSR1")
+	}()
+
+	go func() {
+		c := make(chan os.Signal, 2)
+		signal.Notify(c, syscall.SIGINT)
+		<-c
+		atomic.StoreInt32(&mgr.verbosity, -1) // VMs will fail
+		mgr.logf(-1, "shutting down...")
+		mgr.stopFuzzing("interrupted")
+		<-c
+		log.Fatalf("terminating")
+	}()
+}
+
+func serializeSyscalls(syscalls map[int]bool) string {
+	if len(syscalls) == 0 {
+		return ""
+	}
+	buf := new(bytes.Buffer)
+	for c := range syscalls {
+		fmt.Fprintf(buf, ",%v", c)
+	}
+	return buf.String()[1:]
+}
+
+// reloadConfig re-reads config file and applies changes in enabled/disabled syscalls
+// and suppressions. Running fuzzers pick up the new syscall set on the next poll.
+// Changes to other params require manager restart.
+func (mgr *Manager) reloadConfig() {
+	if mgr.opts.ConfigFile == "" {
+		mgr.logf(0, "not reloading config: config file is unknown")
+		return
+	}
+	mgr.logf(0, "reloading config %v", mgr.opts.ConfigFile)
+	cfg, syscalls, suppressions, err := config.Parse(mgr.opts.ConfigFile)
+	if err != nil {
+		mgr.logf(0, "failed to reload config: %v", err)
+		return
+	}
+	enabledSyscalls := serializeSyscalls(syscalls)
+	mgr.logf(1, "enabled syscalls: %v", enabledSyscalls)
+	knownCrashes, err := config.TitleSuppressions(cfg)
+	if err != nil {
+		mgr.logf(0, "failed to reload config: %v", err)
+		return
+	}
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	mgr.cfg.Enable_Syscalls = cfg.Enable_Syscalls
+	mgr.cfg.Disable_Syscalls = cfg.Disable_Syscalls
+	mgr.cfg.Suppressions = cfg.Suppressions
+	mgr.syscalls = syscalls
+	mgr.suppressions = suppressions
+	mgr.knownCrashes = knownCrashes
+	mgr.reachable = nil
+	if enabledSyscalls != mgr.enabledSyscalls {
+		mgr.enabledSyscalls = enabledSyscalls
+		mgr.configGen++
+	}
+	mgr.logf(0, "reloaded config: %v enabled syscalls, %v suppressions", len(syscalls), len(suppressions))
+	mgr.audit(&AuditEvent{Type: "config reloaded",
+		Reason: fmt.Sprintf("%v enabled syscalls, %v suppressions", len(syscalls), len(suppressions))})
+}
+
+// pmTimeout is how long a VM can stay silent during a power management cycle.
+const pmTimeout = 5 * time.Minute
+
+// fuzzerSeed returns the -seed for run-th fuzzer started on the index-th instance (0 means random).
+// With Options.Seed every fuzzer gets its own seed that does not change across manager runs,
+// so that program generation in a campaign is reproducible given the same corpus and crashes.
+func (mgr *Manager) fuzzerSeed(index, run int) int64 {
+	if mgr.opts.Seed == 0 {
+		return 0
+	}
+	return mgr.opts.Seed + int64(index)<<32 + int64(run)
+}
+
+// runInstance boots a VM and runs the fuzzer in it until it crashes or needs to be restarted.
+// kernel is index of the kernel variant the VM boots (-1 if cfg.Kernels is not set).
+// It returns an error if the VM failed to boot or crashed before executing any programs.
+func (mgr *Manager) runInstance(vmCfg *vm.Config, first, pressure, treatment bool, kernel int, seed int64) error {
+	inst, err := vm.Create(mgr.cfg.Type, vmCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create instance: %v", err)
+	}
+	defer inst.Close()
+
+	fwdAddr, err := inst.Forward(mgr.port)
+	if err != nil {
+		return fmt.Errorf("failed to setup port forwarding: %v", err)
+	}
+	fuzzerBin, err := inst.Copy(filepath.Join(mgr.cfg.Syzkaller, "bin", "syz-fuzzer"))
+	if err != nil {
+		return fmt.Errorf("failed to copy binary: %v", err)
+	}
+	executorBin, err := inst.Copy(filepath.Join(mgr.cfg.Syzkaller, "bin", "syz-executor"))
+	if err != nil {
+		return fmt.Errorf("failed to copy binary: %v", err)
+	}
+
+	// Run an aux command with best effort.
+	runCommand := func(cmd string) {
+		_, errc, err := inst.Run(10*time.Second, cmd)
+		if err == nil {
+			<-errc
+		}
+	}
+	runCommand("echo -n 0 > /proc/sys/debug/exception-trace")
+
+	var clock *ClockInfo
+	if mgr.cfg.Type != "local" {
+		if clock, err = measureClock(inst); err != nil {
+			mgr.logf(1, "%v: failed to measure guest clock: %v", vmCfg.Name, err)
+		}
+	}
+	events := []Event{{time.Now(), "instance booted"}}
+
+	// Leak detection significantly slows down fuzzing, so detect leaks only on the first instance.
+	leak := first && mgr.cfg.Leak
+
+	if pressure {
+		mgr.logf(1, "%v: running in memory pressure mode with %v MB", vmCfg.Name, vmCfg.Mem)
+	}
+
+	strategy, procs, sandbox := mgr.cfg.Strategy, mgr.cfg.Procs, mgr.cfg.Sandbox
+	group := groupControl
+	if treatment {
+		group = groupTreatment
+		e := mgr.cfg.Experiment
+		strategy, procs, sandbox = e.Strategy, e.Procs, e.Sandbox
+	}
+	kernelTag, kernelName, vmlinux := mgr.kernelTag, "", mgr.cfg.Vmlinux
+	mgr.mu.Lock()
+	mgr.experiment.join(vmCfg.Name, group)
+	if kernel != -1 {
+		mgr.kernels.join(vmCfg.Name, kernel)
+		v := mgr.kernels.variants[kernel]
+		kernelTag, kernelName, vmlinux = v.tag, v.Name, v.cfg.Vmlinux
+	}
+	mgr.mu.Unlock()
+	defer func() {
+		mgr.mu.Lock()
+		mgr.experiment.leave(vmCfg.Name)
+		mgr.kernels.leave(vmCfg.Name)
+		mgr.mu.Unlock()
+	}()
+
+	// Run the fuzzer binary.
+	var dropCaps []string
+	masks, _ := config.DropCapsMasks(mgr.cfg) // validated by config.Parse
+	for _, mask := range masks {
+		dropCaps = append(dropCaps, fmt.Sprintf("0x%x", mask))
+	}
+	weights, collide, _ := config.MutationWeights(mgr.cfg) // validated by config.Parse
+	mutationWeights := fmt.Sprintf("insert:%v,mutate_arg:%v,remove:%v,splice:%v",
+		weights.Insert, weights.MutateArg, weights.Remove, weights.Splice)
+	outputC, errorC, err := inst.Run(time.Hour, fmt.Sprintf("%v -executor %v -name %v -manager %v -key %v -output=%v -procs %v -leak=%v -leak_period=%vs -cover=%v -sandbox=%v -dangerous=%v -strategy=%v -pressure=%v -pm=%v -pm_period=%vs -flaky_repeat=%v -errno_feedback=%v -comps=%v -faults=%v -fault_max=%v -drop_caps=%v -mutation_weights=%v -collide_percent=%v -concolic=%v -seed=%v -program_timeout=%vs -disk_min=%v -v %d",
+		fuzzerBin, executorBin, vmCfg.Name, fwdAddr, mgr.rpcKey, mgr.cfg.Output, procs, leak, mgr.cfg.Leak_Period, mgr.cfg.Cover, sandbox, mgr.cfg.Dangerous_Calls, strategy, pressure, mgr.cfg.Pm, mgr.cfg.Pm_Period, mgr.cfg.Flaky_Repeat, mgr.cfg.Errno_Feedback, mgr.cfg.Comparisons && mgr.cfg.Cover, mgr.cfg.Fault_Injection && mgr.cfg.Cover, mgr.cfg.Fault_Max, strings.Join(dropCaps, ","), mutationWeights, collide, mgr.cfg.Concolic_Addr != "" && mgr.cfg.Comparisons && mgr.cfg.Cover, seed, mgr.cfg.Program_Timeout, mgr.cfg.Disk_Min, atomic.LoadInt32(&mgr.verbosity)))
+	if err != nil {
+		return fmt.Errorf("failed to run fuzzer: %v", err)
+	}
+	startTime := time.Now()
+	events = append(events, Event{startTime, "fuzzer started"})
+	instance := &Instance{
+		Name:     vmCfg.Name,
+		Procs:    procs,
+		Sandbox:  sandbox,
+		Strategy: strategy,
+	}
+	if mgr.cfg.Cover {
+		instance.Features = append(instance.Features, "cover")
+	}
+	if leak {
+		instance.Features = append(instance.Features, "leak")
+	}
+	if pressure {
+		instance.Features = append(instance.Features, "pressure")
+	}
+	if mgr.cfg.Pm != "none" {
+		instance.Features = append(instance.Features, "pm="+mgr.cfg.Pm)
+	}
+	if mgr.experiment != nil {
+		instance.Features = append(instance.Features, "group="+mgr.experiment.groups[group].Name)
+	}
+	if kernelName != "" {
+		instance.Features = append(instance.Features, "kernel="+kernelName)
+	}
+	if fwd, ok := inst.(vm.PortForwarder); ok {
+		hostPorts := fwd.HostPorts()
+		for _, guest := range vmCfg.NetFwd {
+			instance.Ports = append(instance.Ports, fmt.Sprintf("%v->localhost:%v", guest, hostPorts[guest]))
+		}
+	}
+	mgr.addInstance(instance)
+	defer mgr.removeInstance(vmCfg.Name)
+	var crashes []string
+	if mgr.cfg.Save_Crash_Disk {
+		// Runs before inst.Close, which destroys the disk.
+		defer func() {
+			if len(crashes) != 0 {
+				mgr.saveCrashDisk(vmCfg.Name, inst, crashes[len(crashes)-1])
+			}
+		}()
+	}
+
+	saveCrasher := func(what string, output []byte) {
+		if atomic.LoadUint32(&mgr.shutdown) != 0 {
+			// qemu crashes with "qemu: terminating on signal 2",
+			// which we detect as "lost connection".
+			return
+		}
+		mgr.mu.Lock()
+		suppressions, knownCrashes := mgr.suppressions, mgr.knownCrashes
+		mgr.mu.Unlock()
+		for _, re := range suppressions {
+			if re.Match(output) {
+				mgr.logf(1, "%v: suppressing '%v' with '%v'", vmCfg.Name, what, re.String())
+				mgr.audit(&AuditEvent{Type: "crash suppressed", VM: vmCfg.Name, Title: what, Reason: re.String()})
+				return
+			}
+		}
+		for _, re := range knownCrashes {
+			if re.MatchString(what) {
+				// Known bug: don't save anything, the VM continues fuzzing if the kernel survived.
+				mgr.logf(1, "%v: known crash '%v' (%v)", vmCfg.Name, what, re.String())
+				mgr.mu.Lock()
+				mgr.stats["known crashes"]++
+				mgr.mu.Unlock()
+				mgr.audit(&AuditEvent{Type: "crash suppressed", VM: vmCfg.Name, Title: what, Reason: "title:" + re.String()})
+				return
+			}
+		}
+		crashReport := mgr.crashReport(vmlinux, output)
+		rep := &Report{
+			Title:  what,
+			Time:   time.Now(),
+			VM:     vmCfg.Name,
+			Uptime: time.Since(startTime).Seconds(),
+			Kernel: kernelTag.Version,
+			Commit: kernelTag.Commit,
+			Guilty: report.ExtractGuiltyFile(crashReport),
+			Output: string(output),
+		}
+		if !mgr.triage(rep) {
+			mgr.audit(&AuditEvent{Type: "crash ignored", VM: vmCfg.Name, Title: rep.Title, Reason: "triage"})
+			return
+		}
+		what = rep.Title
+		buf := new(bytes.Buffer)
+		fmt.Fprintf(buf, "\n\n")
+		if len(crashes) != 0 {
+			fmt.Fprintf(buf, "previous crashes:\n")
+			for _, c := range crashes {
+				fmt.Fprintf(buf, "\t%s\n", c)
+			}
+		}
+		crashes = append(crashes, what)
+		fmt.Fprintf(buf, "%v", kernelTag)
+		if kernelName != "" {
+			fmt.Fprintf(buf, "kernel variant: %v\n", kernelName)
+		}
+		fmt.Fprintf(buf, "clock: %v\n", clock)
+		if rep.Severity != "" {
+			fmt.Fprintf(buf, "severity: %v\n", rep.Severity)
+		}
+		if rep.Guilty != "" {
+			fmt.Fprintf(buf, "guilty file: %v\n", rep.Guilty)
+		}
+		fmt.Fprintf(buf, "after running for %v:\n", time.Since(startTime))
+		fmt.Fprintf(buf, "%v\n", what)
+		output = append([]byte{}, output...)
+		output = append(output, buf.Bytes()...)
+		events = append(events, Event{time.Now(), "crash: " + what})
+		timeline := buildTimeline(output, clock, events)
+		mgr.mu.Lock()
+		filename, err := mgr.saveCrashLog(what, output, crashReport, timeline)
+		if err != nil {
+			mgr.logf(0, "%v: failed to save crash '%v': %v", vmCfg.Name, what, err)
+		} else {
+			mgr.logf(0, "%v: saved crash '%v' to %v", vmCfg.Name, what, filename)
+		}
+		mgr.crashTypes[what]++
+		mgr.stormCrash(what)
+		mgr.queueRepro(what, output, kernelName)
+		if !mgr.reproRunning(what) {
+			// Not being reproduced, report right away.
+			mgr.emailCrash(what)
+		}
+		instance.LastCrash = what
+		instance.LastCrashTime = time.Now()
+		mgr.experiment.addCrash(vmCfg.Name)
+		mgr.kernels.addCrash(vmCfg.Name, what)
+		mgr.publishCrash(rep)
+		mgr.mu.Unlock()
+		mgr.audit(&AuditEvent{Type: "crash", VM: vmCfg.Name, Title: what})
+		mgr.notifier.notify(what, output)
+		mgr.uploadCrash(what, output, crashReport)
+		mgr.exporter.exportCrash(rep)
+	}
+
+	var output []byte
+
+	waitForOutput := func(dur time.Duration) {
+		timer := time.NewTimer(dur).C
+	loop:
+		for {
+			select {
+			case out := <-outputC:
+				output = append(output, out...)
+				mgr.mu.Lock()
+				instance.appendConsole(out)
+				mgr.mu.Unlock()
+			case <-timer:
+				break loop
+			}
+		}
+	}
+
+	dumpVMState := func() {
+		// Shows all locks that are held.
+		runCommand("echo -n d > /proc/sysrq-trigger")
+		// Shows a stack backtrace for all active CPUs.
+		runCommand("echo -n l > /proc/sysrq-trigger")
+		// Will dump a list of current tasks and their information to your console.
+		runCommand("echo -n t > /proc/sysrq-trigger")
+		waitForOutput(time.Second)
+	}
+
+	matchPos := 0
+	const (
+		beforeContext = 256 << 10
+		afterContext  = 128 << 10
+	)
+	lastExecuteTime := time.Now()
+	executing := false  // the fuzzer has printed an executed program
+	lockdepOff := false // lockdep has turned itself off after the first report
+	restarted := func(reason string) {
+		mgr.audit(&AuditEvent{Type: "vm restart", VM: vmCfg.Name, Reason: reason})
+	}
+	// crashed is returned after a crash, it is a boot failure if nothing was executed yet.
+	crashed := func(what string) error {
+		restarted(what)
+		mgr.mu.Lock()
+		execs := instance.Execs
+		mgr.mu.Unlock()
+		if executing || execs != 0 {
+			return nil
+		}
+		return fmt.Errorf("%v before executing programs:\n%s", what, output)
+	}
+	// diskFull is checked before saving hangs and lost connections, with full guest disk
+	// they are caused by ENOSPC rather than by kernel bugs.
+	diskFull := func() bool {
+		mgr.mu.Lock()
+		full := instance.diskFull
+		mgr.mu.Unlock()
+		if full {
+			mgr.logf(0, "%v: restarting on guest disk full", vmCfg.Name)
+			restarted("guest disk full")
+		}
+		return full
+	}
+	var pmStart time.Time // time of the last unfinished power management cycle
+	ticker := time.NewTimer(time.Minute)
+	fired := false // the timer value was already received from ticker.C
+	for {
+		if !ticker.Reset(time.Minute) && !fired {
+			<-ticker.C
+		}
+		fired = false
+		select {
+		case <-mgr.stop:
+			restarted("manager stopped")
+			return nil
+		case reason := <-instance.restart:
+			mgr.logf(0, "%v: restarting on %v", vmCfg.Name, reason)
+			restarted(reason)
+			return nil
+		case err := <-errorC:
+			switch err {
+			case vm.TimeoutErr:
+				mgr.logf(0, "%v: running long enough, restarting", vmCfg.Name)
+				restarted("running long enough")
+				return nil
+			default:
+				if diskFull() {
+					return nil
+				}
+				mgr.logf(0, "%v: lost connection: %v", vmCfg.Name, err)
+				saveCrasher("lost connection", output)
+				return crashed("lost connection")
+			}
+		case out := <-outputC:
+			output = append(output, out...)
+			mgr.mu.Lock()
+			instance.appendConsole(out)
+			mgr.mu.Unlock()
+			if bytes.Index(output[matchPos:], []byte("executing program")) != -1 {
+				lastExecuteTime = time.Now()
+				if !executing {
+					executing = true
+					mgr.mu.Lock()
+					mgr.bootSucceeded()
+					mgr.mu.Unlock()
+				}
+			}
+			// The VM is silent during suspend, so don't detect it as a hang.
+			suspend := bytes.LastIndex(output[matchPos:], []byte("pm: suspending"))
+			resume := bytes.LastIndex(output[matchPos:], []byte("pm: resumed"))
+			if suspend > resume {
+				pmStart = time.Now()
+			} else if resume != -1 {
+				pmStart = time.Time{}
+				lastExecuteTime = time.Now()
+			}
+			if report.ContainsCrash(output[matchPos:]) {
+				// Give it some time to finish writing the error message.
+				waitForOutput(10 * time.Second)
+				rep := report.Parse(output[matchPos:])
+				start := rep.Start + matchPos - beforeContext
+				if start < 0 {
+					start = 0
+				}
+				end := rep.End + matchPos + afterContext
+				if end > len(output) {
+					end = len(output)
+				}
+				saveCrasher(rep.Title, output[start:end])
+			}
+			if !lockdepOff && report.LockdepOff(output[matchPos:]) {
+				lockdepOff = true
+				mgr.mu.Lock()
+				mgr.stats["lockdep turned off"]++
+				mgr.mu.Unlock()
+				if mgr.cfg.Lockdep_Reboot {
+					// Further locking bugs won't be reported until reboot.
+					mgr.logf(0, "%v: lockdep turned off, restarting", vmCfg.Name)
+					restarted("lockdep turned off")
+					return nil
+				}
+			}
+			if len(output) > 2*beforeContext {
+				copy(output, output[len(output)-beforeContext:])
+				output = output[:beforeContext]
+			}
+			matchPos = len(output) - 128
+			if matchPos < 0 {
+				matchPos = 0
+			}
+			// In some cases kernel constantly prints something to console,
+			// but fuzzer is not actually executing programs.
+			if mgr.cfg.Type != "local" && time.Since(lastExecuteTime) > 3*time.Minute {
+				if diskFull() {
+					return nil
+				}
+				dumpVMState()
+				saveCrasher("not executing programs", output)
+				return crashed("not executing programs")
+			}
+		case <-ticker.C:
+			fired = true
+			if !pmStart.IsZero() {
+				if time.Since(pmStart) < pmTimeout {
+					continue
+				}
+				dumpVMState()
+				saveCrasher("no output after suspend", output)
+				return crashed("no output after suspend")
+			}
+			if diskFull() {
+				return nil
+			}
+			if mgr.cfg.Type != "local" {
+				dumpVMState()
+				saveCrasher("no output", output)
+				return crashed("no output")
+			}
+		}
+	}
+}
+
+func (mgr *Manager) minimizeCorpus() {
+	if mgr.cfg.Cover && len(mgr.corpus) != 0 {
+		// First, sort corpus per call.
+		type Call struct {
+			inputs []RpcInput
+			cov    []cover.Cover
+		}
+		calls := make(map[string]Call)
+		for _, inp := range mgr.corpus {
+			c := calls[inp.Call]
+			c.inputs = append(c.inputs, inp)
+			c.cov = append(c.cov, inp.Cover)
+			calls[inp.Call] = c
+		}
+		// Now minimize and build new corpus.
+		// Keep the original order, so that recently added inputs stay at the end.
+		keep := make(map[string]bool)
+		for _, c := range calls {
+			for _, idx := range cover.Minimize(c.cov) {
+				keep[hashString(c.inputs[idx].Prog)] = true
+			}
+		}
+		var newCorpus []RpcInput
+		for _, inp := range mgr.corpus {
+			sig := hashString(inp.Prog)
+			// Inputs saved for errno feedback don't necessarily give unique coverage.
+			if !keep[sig] && !inp.Success {
+				continue
+			}
+			if mgr.brokenProg(sig) {
+				mgr.audit(&AuditEvent{Type: "input dropped", Prog: sig, Call: inp.Call, Reason: "broken"})
+				continue
+			}
+			newCorpus = append(newCorpus, inp)
+		}
+		mgr.logf(1, "minimized corpus: %v -> %v", len(mgr.corpus), len(newCorpus))
+		mgr.corpus = newCorpus
+	}
+	var corpus []*prog.Prog
+	for _, inp := range mgr.corpus {
+		p, err := prog.Deserialize(inp.Prog)
+		if err != nil {
+			panic(err)
+		}
+		corpus = append(corpus, p)
+	}
+	mgr.prios = prog.CalculatePriorities(corpus)
+
+	// Don't minimize persistent corpus until fuzzers have triaged all inputs from it.
+	if len(mgr.candidates) == 0 {
+		hashes := make(map[string]bool)
+		for _, inp := range mgr.corpus {
+			hashes[hashString(inp.Prog)] = true
+		}
+		for _, h := range mgr.disabledHashes {
+			hashes[h] = true
+		}
+		for key := range mgr.corpusDB.Records {
+			if hashes[key] {
+				continue
+			}
+			if err := mgr.corpusDB.Delete(key); err != nil {
+				go mgr.fail(fmt.Errorf("failed to delete program: %v", err)) // mgr.mu is held
+				break
+			}
+		}
+		for key := range mgr.resources {
+			if !hashes[key] {
+				delete(mgr.resources, key)
+			}
+		}
+		// Compact the database when it is mostly garbage.
+		if mgr.corpusDB.Stale() > len(mgr.corpusDB.Records)+100 {
+			if err := mgr.corpusDB.Compact(); err != nil {
+				mgr.logf(0, "failed to compact corpus database: %v", err)
+			}
+		}
+	}
+}
+
+func (mgr *Manager) Connect(a *ConnectArgs, r *ConnectRes) error {
+	mgr.logf(1, "fuzzer %v connected", a.Name)
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	if err := mgr.authFuzzer(a.Name, a.Key, false); err != nil {
+		return err
+	}
+	if a.Version != ProtocolVersion {
+		mgr.logf(0, "fuzzer %v has protocol version %v, want %v", a.Name, a.Version, ProtocolVersion)
+		return fmt.Errorf("fuzzer protocol version %v does not match manager version %v, rebuild syz-fuzzer",
+			a.Version, ProtocolVersion)
+	}
+	mgr.stats["vm restarts"]++
+	mgr.minimizeCorpus()
+	mgr.fuzzers[a.Name] = &Fuzzer{
+		name:      a.Name,
+		input:     len(mgr.corpus),
+		pending:   mgr.freshCorpus(),
+		configGen: mgr.configGen,
+	}
+	r.Prios = mgr.fuzzerPrios()
+	r.EnabledCalls = mgr.fuzzerSyscalls()
+	r.DisabledCalls = mgr.stormDisabledCalls()
+	r.Values = mgr.allValues()
+	for _, a := range mgr.cfg.Setup {
+		calls, _ := config.MatchSyscalls(a.Calls) // validated in config.Parse
+		action := SetupAction{Name: a.Name, Command: a.Command}
+		for id := range calls {
+			action.Calls = append(action.Calls, id)
+		}
+		r.Setup = append(r.Setup, action)
+	}
+
+	return nil
+}
+
+// authFuzzer checks the RPC key of a fuzzer request and, if connected is set,
+// that the fuzzer has called Connect. Must be called under mgr.mu.
+func (mgr *Manager) authFuzzer(name, key string, connected bool) error {
+	if subtle.ConstantTimeCompare([]byte(key), []byte(mgr.rpcKey)) != 1 {
+		mgr.logf(0, "bad rpc key from fuzzer %v", name)
+		return fmt.Errorf("unauthorized fuzzer")
+	}
+	if connected && mgr.fuzzers[name] == nil {
+		return fmt.Errorf("fuzzer %v is not connected", name)
+	}
+	return nil
+}
+
+func (mgr *Manager) Check(a *CheckArgs, r *int) error {
+	mgr.logf(1, "fuzzer %v supports %v calls", a.Name, len(a.Calls))
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	if err := mgr.authFuzzer(a.Name, a.Key, true); err != nil {
+		return err
+	}
+
+	if mgr.targetCalls != nil {
+		return nil
+	}
+	mgr.targetCalls = make(map[string]bool)
+	for _, c := range a.Calls {
+		mgr.targetCalls[c] = true
+	}
+	mgr.reachable = nil
+	mgr.prioritizeCandidates()
+	return nil
+}
+
+// addSuccess accounts the call of an input saved for errno feedback,
+// returns false if the call has already succeeded in the corpus.
+func (mgr *Manager) addSuccess(inp *RpcInput) bool {
+	p, err := prog.Deserialize(inp.Prog)
+	if err != nil || inp.CallIndex < 0 || inp.CallIndex >= len(p.Calls) {
+		return false
+	}
+	id := p.Calls[inp.CallIndex].Meta.ID
+	if mgr.succeeded[id] {
+		return false
+	}
+	mgr.succeeded[id] = true
+	return true
+}
+
+func (mgr *Manager) NewInput(a *NewInputArgs, r *int) error {
+	mgr.logf(2, "new input from %v for syscall %v", a.Name, a.Call)
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	if err := mgr.authFuzzer(a.Name, a.Key, true); err != nil {
+		return err
+	}
+	call, ok := sys.CallID[a.Call]
+	if !ok {
+		return fmt.Errorf("unknown syscall %v", a.Call)
+	}
+	mgr.experiment.addCover(a.Name, call, a.Cover)
+	mgr.kernels.addCover(a.Name, call, a.Cover)
+	mgr.addValues(a.Values)
+	key := hashString(a.RpcInput.Prog)
+	diff := cover.Difference(a.Cover, mgr.corpusCover[call])
+	a.Success = a.Success && mgr.addSuccess(&a.RpcInput)
+	if a.Success {
+		mgr.stats["manager new success inputs"]++
+	}
+	if len(diff) == 0 && !a.Success {
+		mgr.audit(&AuditEvent{Type: "input rejected", VM: a.Name, Prog: key, Call: a.Call, Reason: "no new coverage"})
+		return nil
+	}
+	ev := &AuditEvent{Type: "input accepted", VM: a.Name, Prog: key, Call: a.Call, Cover: len(diff)}
+	if a.Success {
+		ev.Reason = "call succeeded for the first time"
+	}
+	mgr.audit(ev)
+	a.Origin = mgr.inheritProvenance(a.Origin, a.RpcInput.Prog)
+	mgr.corpusCover[call] = cover.Union(mgr.corpusCover[call], a.Cover)
+	mgr.corpus = append(mgr.corpus, a.RpcInput)
+	mgr.stats["manager new inputs"]++
+	if _, ok := mgr.corpusDB.Records[key]; !ok {
+		mgr.addResourceBaseline(key, a.Resources)
+		rec := db.Record{
+			Val:       a.RpcInput.Prog,
+			Signal:    len(a.Cover),
+			Desc:      mgr.kernelTag.String(),
+			Succeeded: a.Resources,
+		}
+		if err := mgr.corpusDB.Save(key, rec); err != nil {
+			go mgr.fail(fmt.Errorf("failed to save program: %v", err)) // mgr.mu is held
+		}
+	}
+	return nil
+}
+
+func (mgr *Manager) Poll(a *PollArgs, r *PollRes) error {
+	mgr.logf(2, "poll from %v", a.Name)
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	if err := mgr.authFuzzer(a.Name, a.Key, true); err != nil {
+		return err
+	}
+
+	for k, v := range a.Stats {
+		if strings.HasPrefix(k, phaseStatPrefix) {
+			continue // see addPhases
+		}
+		mgr.stats[k] += v
+	}
+	inst := mgr.instances[a.Name]
+	mgr.addPhases(inst, a.Stats)
+	if inst != nil {
+		inst.Execs += a.Stats["exec total"]
+		if inst.Execs != 0 {
+			mgr.bootSucceeded()
+		}
+		r.Profile = inst.profileReq
+		inst.profileReq = ""
+	}
+	mgr.experiment.addStats(a.Name, a.Stats)
+	mgr.kernels.addExecs(a.Name, a.Stats["exec total"])
+	mgr.addCallStats(a.CallStats)
+	mgr.addResourceResults(a.ResourceResults)
+	mgr.addBlocked(a.Blocked)
+
+	mgr.checkStorm()
+
+	f := mgr.fuzzers[a.Name]
+
+	if f.configGen != mgr.configGen {
+		f.configGen = mgr.configGen
+		r.EnabledCalls = mgr.fuzzerSyscalls()
+		r.DisabledCalls = mgr.stormDisabledCalls()
+		r.Prios = mgr.fuzzerPrios()
+		r.Reconfigure = true
+	}
+
+	for i := 0; i < 100 && f.input < len(mgr.corpus); i++ {
+		r.NewInputs = append(r.NewInputs, mgr.corpus[f.input])
+		f.input++
+	}
+	for len(r.NewInputs) < 100 && len(f.pending) != 0 {
+		r.NewInputs = append(r.NewInputs, f.pending[0])
+		f.pending = f.pending[1:]
+	}
+	var held []RpcInput
+	r.NewInputs, held = mgr.stormFilter(r.NewInputs)
+	// Inputs held back due to a crash storm are sent after it's over.
+	f.pending = append(f.pending, held...)
+	if len(f.pending) == 0 {
+		f.pending = nil
+	}
+
+	for i := 0; i < 10 && len(mgr.candidates) > 0; i++ {
+		last := len(mgr.candidates) - 1
+		r.Candidates = append(r.Candidates, mgr.candidates[last])
+		mgr.candidates = mgr.candidates[:last]
+	}
+	r.CandidateOrigins = mgr.candidateOrigins(r.Candidates)
+	if len(mgr.candidates) == 0 {
+		mgr.candidates = nil
+	}
+
+	return nil
+}
+
+// logger is the signature of Manager.logf, helpers that log get it on creation.
+type logger func(v int, msg string, args ...interface{})
+
+func (mgr *Manager) logf(v int, msg string, args ...interface{}) {
+	if int(atomic.LoadInt32(&mgr.verbosity)) >= v {
+		log.Printf(msg, args...)
+	}
+}
+
+// fail stops the campaign because of an error, Campaign.Wait returns the first such error.
+func (mgr *Manager) fail(err error) {
+	mgr.logf(0, "%v", err)
+	mgr.errMu.Lock()
+	if mgr.err == nil {
+		mgr.err = err
+	}
+	mgr.errMu.Unlock()
+	mgr.stopFuzzing(err.Error())
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// httpMetrics serves manager stats in Prometheus text exposition format.
+// Counters (e.g. syz_exec_total) are cumulative over the manager run,
+// rates (execs/sec) are supposed to be computed with rate() on the Prometheus side.
+func (mgr *Manager) httpMetrics(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	rec := mgr.statsRecord()
+	crashes := 0
+	for _, n := range mgr.crashTypes {
+		crashes += n
+	}
+	crashTypes := len(mgr.crashTypes)
+	fuzzers := len(mgr.fuzzers)
+	bootFailures := mgr.boot.Failures
+	mgr.mu.Unlock()
+	allowed, running := mgr.scaler.state()
+
+	buf := new(bytes.Buffer)
+	metric := func(name, typ, help string, val interface{}) {
+		fmt.Fprintf(buf, "# HELP %v %v\n# TYPE %v %v\n%v %v\n", name, help, name, typ, name, val)
+	}
+	metric("syz_uptime_seconds", "gauge", "Time since the manager has started.", int64(rec.Uptime))
+	metric("syz_exec_total", "counter", "Number of executed programs.", rec.Stats["exec total"])
+	metric("syz_corpus_size", "gauge", "Number of programs in the corpus.", rec.Corpus)
+	metric("syz_coverage", "gauge", "Number of covered kernel PCs (summed per syscall).", rec.Cover)
+	metric("syz_triage_queue", "gauge", "Number of candidate programs waiting for triage.", rec.Candidates)
+	metric("syz_vm_restarts_total", "counter", "Number of VM (re)starts.", rec.Stats["vm restarts"])
+	metric("syz_crashes_total", "counter", "Number of crashes.", crashes)
+	metric("syz_crash_types", "gauge", "Number of distinct crash titles.", crashTypes)
+	metric("syz_fuzzers", "gauge", "Number of connected fuzzers.", fuzzers)
+	metric("syz_vms_running", "gauge", "Number of running VMs.", running)
+	metric("syz_vms_allowed", "gauge", "Number of VMs allowed by host load throttling.", allowed)
+	metric("syz_boot_failures", "gauge", "Number of consecutive VM boot failures.", bootFailures)
+
+	// All other stats that fuzzers and the manager collect.
+	var names []string
+	for name := range rec.Stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(buf, "# HELP syz_stat_total Fuzzer and manager stats.\n# TYPE syz_stat_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(buf, "syz_stat_total{stat=%q} %v\n", strings.Replace(name, " ", "_", -1), rec.Stats[name])
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
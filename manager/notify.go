@@ -0,0 +1,167 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/config"
+)
+
+// Notification describes a single new crash that needs to be reported to humans.
+type Notification struct {
+	Title  string
+	Time   time.Time
+	Report string
+
+	email *crashEmail
+}
+
+// Sink delivers a batch of notifications. A batch contains either
+// a single notification, or a digest of notifications accumulated during quiet hours.
+type Sink interface {
+	Send(batch []Notification) error
+}
+
+// Notifier dispatches crash notifications to all configured sinks.
+// Notifications are queued and sent from a background goroutine, so that a slow sink
+// does not block crash processing. During quiet hours notifications are accumulated
+// and sent as a single digest once the quiet window ends.
+type Notifier struct {
+	sinks      []Sink
+	email      Sink
+	quiet      bool
+	quietStart time.Duration
+	quietEnd   time.Duration
+
+	mu      sync.Mutex
+	pending map[Sink][]Notification
+	kick    chan bool
+	logf    logger
+}
+
+func newNotifier(cfg *config.Config, logf logger) *Notifier {
+	n := &Notifier{
+		pending: make(map[Sink][]Notification),
+		kick:    make(chan bool, 1),
+		logf:    logf,
+	}
+	if cfg.Webhook != "" {
+		n.sinks = append(n.sinks, &webhookSink{cfg.Webhook})
+	}
+	if len(cfg.Email_Addrs) != 0 {
+		n.email = &emailSink{cfg.Email_From, cfg.Email_Addrs, cfg.Smtp_Addr, logf}
+	}
+	if cfg.Quiet_Hours != "" {
+		// Validated in config.Parse.
+		n.quietStart, n.quietEnd, _ = config.ParseQuietHours(cfg.Quiet_Hours)
+		n.quiet = true
+	}
+	if len(n.sinks) != 0 || n.email != nil {
+		go n.loop()
+	}
+	return n
+}
+
+// loop sends queued notifications when new ones arrive,
+// and periodically to send the digest when quiet hours end.
+func (n *Notifier) loop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.kick:
+		case <-ticker.C:
+		}
+		n.flush()
+	}
+}
+
+func (n *Notifier) isQuiet(t time.Time) bool {
+	if !n.quiet {
+		return false
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	now := t.Sub(midnight)
+	if n.quietStart < n.quietEnd {
+		return now >= n.quietStart && now < n.quietEnd
+	}
+	return now >= n.quietStart || now < n.quietEnd
+}
+
+func (n *Notifier) notify(title string, report []byte) {
+	n.queue(n.sinks, Notification{
+		Title:  title,
+		Time:   time.Now(),
+		Report: string(report),
+	})
+}
+
+// notifyEmail queues a crash email, see emailCrash.
+func (n *Notifier) notifyEmail(nt Notification) {
+	if n.email == nil {
+		return
+	}
+	n.queue([]Sink{n.email}, nt)
+}
+
+func (n *Notifier) queue(sinks []Sink, nt Notification) {
+	if len(sinks) == 0 {
+		return
+	}
+	n.mu.Lock()
+	for _, s := range sinks {
+		n.pending[s] = append(n.pending[s], nt)
+	}
+	n.mu.Unlock()
+	select {
+	case n.kick <- true:
+	default:
+	}
+}
+
+func (n *Notifier) flush() {
+	n.mu.Lock()
+	if len(n.pending) == 0 || n.isQuiet(time.Now()) {
+		n.mu.Unlock()
+		return
+	}
+	pending := n.pending
+	n.pending = make(map[Sink][]Notification)
+	n.mu.Unlock()
+	for s, batch := range pending {
+		if len(batch) > 1 {
+			n.logf(0, "sending digest of %v notifications", len(batch))
+		}
+		if err := s.Send(batch); err != nil {
+			n.logf(0, "failed to send notification: %v", err)
+		}
+	}
+}
+
+type webhookSink struct {
+	url string
+}
+
+func (s *webhookSink) Send(batch []Notification) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %v returned %v", s.url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2015 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/syzkaller/db"
+)
+
+type Sig [sha1.Size]byte
+
+func hash(data []byte) Sig {
+	return Sig(sha1.Sum(data))
+}
+
+func hashString(data []byte) string {
+	sig := hash(data)
+	return hex.EncodeToString(sig[:])
+}
+
+// importCorpusDir imports programs from the old corpus directory format
+// (a file per program named by its hash, plus hash.kernel description files)
+// into the corpus database. The directory is renamed to corpus.old afterwards.
+func (mgr *Manager) importCorpusDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	mgr.logf(0, "importing corpus from %v...", dir)
+	imported := 0
+	for _, f := range files {
+		if f.IsDir() || strings.IndexByte(f.Name(), '.') != -1 {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read corpus file: %v", err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		desc, _ := ioutil.ReadFile(filepath.Join(dir, f.Name()+".kernel"))
+		rec := db.Record{
+			Val:  data,
+			Time: f.ModTime(),
+			Desc: string(desc),
+		}
+		if err := mgr.corpusDB.Save(hashString(data), rec); err != nil {
+			return fmt.Errorf("failed to save corpus: %v", err)
+		}
+		imported++
+	}
+	if err := os.Rename(dir, dir+".old"); err != nil {
+		return fmt.Errorf("failed to rename old corpus dir: %v", err)
+	}
+	mgr.logf(0, "imported %v programs, old corpus is moved to %v.old", imported, dir)
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Fuzzers report time spent in phases of the fuzzing loop as "time PHASE" stats
+// (see syz-fuzzer/profile.go). They are not shown as rates, instead the main page
+// and instance pages show the share of every phase.
+// Fuzzer pprof profiles are relayed through the manager: /instance/pprof records
+// the request, the fuzzer gets it on the next poll and sends the profile back
+// with Manager.Profile.
+
+const (
+	phaseStatPrefix = "time "
+	profileTimeout  = time.Minute // poll period + cpu profile duration + slack
+)
+
+var profileKinds = map[string]bool{"cpu": true, "heap": true, "goroutine": true, "threadcreate": true}
+
+// addPhases accounts phase times from fuzzer stats, must be called under mgr.mu.
+func (mgr *Manager) addPhases(inst *Instance, stats map[string]uint64) {
+	for k, v := range stats {
+		if !strings.HasPrefix(k, phaseStatPrefix) {
+			continue
+		}
+		name := k[len(phaseStatPrefix):]
+		if mgr.phases == nil {
+			mgr.phases = make(map[string]uint64)
+		}
+		mgr.phases[name] += v
+		if inst != nil {
+			if inst.phases == nil {
+				inst.phases = make(map[string]uint64)
+			}
+			inst.phases[name] += v
+		}
+	}
+}
+
+// phaseShares returns shares of fuzzer phases sorted by name.
+func phaseShares(phases map[string]uint64) []UIStat {
+	total := uint64(0)
+	for _, v := range phases {
+		total += v
+	}
+	var res []UIStat
+	if total == 0 {
+		return nil
+	}
+	for name, v := range phases {
+		res = append(res, UIStat{
+			Name:  phaseStatPrefix + name,
+			Value: fmt.Sprintf("%.1f%%", float64(v)*100/float64(total)),
+		})
+	}
+	sort.Sort(UIStatArray(res))
+	return res
+}
+
+// Profile receives a fuzzer profile requested in PollRes.
+func (mgr *Manager) Profile(a *ProfileArgs, r *int) error {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+
+	if err := mgr.authFuzzer(a.Name, a.Key, true); err != nil {
+		return err
+	}
+	if inst := mgr.instances[a.Name]; inst != nil {
+		select {
+		case inst.profile <- a:
+		default:
+		}
+	}
+	return nil
+}
+
+func (mgr *Manager) httpInstancePprof(w http.ResponseWriter, r *http.Request) {
+	name, kind := r.FormValue("name"), r.FormValue("kind")
+	if !profileKinds[kind] {
+		http.Error(w, fmt.Sprintf("unknown profile %q", kind), http.StatusBadRequest)
+		return
+	}
+	mgr.mu.Lock()
+	inst := mgr.instances[name]
+	var profile chan *ProfileArgs
+	if inst != nil {
+		// Drop a stale profile that arrived after a previous request has timed out.
+		select {
+		case <-inst.profile:
+		default:
+		}
+		inst.profileReq = kind
+		profile = inst.profile
+	}
+	mgr.mu.Unlock()
+	if inst == nil {
+		http.Error(w, fmt.Sprintf("unknown instance %q", name), http.StatusNotFound)
+		return
+	}
+	mgr.logf(1, "%v: %v profile requested from web UI", name, kind)
+	var res *ProfileArgs
+	select {
+	case res = <-profile:
+	case <-time.After(profileTimeout):
+		http.Error(w, fmt.Sprintf("%v did not send the profile in %v", name, profileTimeout), http.StatusGatewayTimeout)
+		return
+	}
+	if res.Error != "" {
+		http.Error(w, fmt.Sprintf("failed to collect profile: %v", res.Error), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v-%v.pprof", name, res.Kind))
+	w.Write(res.Data)
+}
@@ -0,0 +1,146 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/db"
+)
+
+// Provenance of imported programs (seeds, hub inputs and reproducers, uploads, concolic solutions)
+// is persisted in workdir/provenance.db keyed by program hash, so that organizations sharing
+// corpora can track where inputs came from. Candidates are handed out to fuzzers with their
+// hashes as origins (PollRes.CandidateOrigins); fuzzers pass the origin to inputs triaged from
+// the program and from its mutants (RpcInput.Origin) and log it with executed programs,
+// the inputs inherit the provenance and reproducers get it from origins in the crash log.
+type Provenance struct {
+	Source   string    // seeds, hub, hub repro, upload or concolic
+	Manager  string    // manager that added the program to the hub (for hub programs)
+	Author   string    // from a "# author: NAME" line of seed and uploaded programs
+	Imported time.Time // time the program was imported
+}
+
+func (p *Provenance) String() string {
+	s := fmt.Sprintf("source=%v", p.Source)
+	if p.Manager != "" {
+		s += fmt.Sprintf(" manager=%v", p.Manager)
+	}
+	if p.Author != "" {
+		s += fmt.Sprintf(" author=%v", p.Author)
+	}
+	return s + fmt.Sprintf(" imported=%v", p.Imported.UTC().Format(time.RFC3339))
+}
+
+func (mgr *Manager) loadProvenance(provDB *db.DB) {
+	mgr.provenanceDB = provDB
+	mgr.provenance = make(map[string]*Provenance)
+	for key, rec := range provDB.Records {
+		prov := new(Provenance)
+		if err := json.Unmarshal(rec.Val, prov); err != nil {
+			continue
+		}
+		mgr.provenance[key] = prov
+	}
+	mgr.logf(0, "loaded provenance of %v programs", len(mgr.provenance))
+}
+
+// importProvenance records provenance of data unless it is already known.
+// Must be called with mgr.mu held.
+func (mgr *Manager) importProvenance(data []byte, source, manager, author string) {
+	key := hashString(data)
+	if mgr.provenance[key] != nil {
+		return
+	}
+	mgr.saveProvenance(key, &Provenance{
+		Source:   source,
+		Manager:  manager,
+		Author:   author,
+		Imported: time.Now(),
+	})
+}
+
+// saveProvenance must be called with mgr.mu held.
+func (mgr *Manager) saveProvenance(key string, prov *Provenance) {
+	mgr.provenance[key] = prov
+	data, err := json.Marshal(prov)
+	if err != nil {
+		panic(err)
+	}
+	if err := mgr.provenanceDB.Save(key, db.Record{Val: data}); err != nil {
+		mgr.logf(0, "failed to save provenance: %v", err)
+	}
+}
+
+// inheritProvenance propagates provenance of origin to the new input data,
+// returns origin or "" if it is unknown. Must be called with mgr.mu held.
+func (mgr *Manager) inheritProvenance(origin string, data []byte) string {
+	prov := mgr.provenance[origin]
+	if prov == nil {
+		return ""
+	}
+	if key := hashString(data); mgr.provenance[key] == nil {
+		mgr.saveProvenance(key, prov)
+	}
+	return origin
+}
+
+// candidateOrigins returns PollRes.CandidateOrigins for candidates.
+// Must be called with mgr.mu held.
+func (mgr *Manager) candidateOrigins(candidates [][]byte) []string {
+	var origins []string
+	found := false
+	for _, data := range candidates {
+		key := hashString(data)
+		if mgr.provenance[key] == nil {
+			key = ""
+		} else {
+			found = true
+		}
+		origins = append(origins, key)
+	}
+	if !found {
+		return nil
+	}
+	return origins
+}
+
+var originRe = regexp.MustCompile(`executing program [0-9]+ \(.*origin ([0-9a-f]{40})`)
+
+// logProvenance returns provenance descriptions of programs
+// mentioned as origins in the crash log. Must be called with mgr.mu held.
+func (mgr *Manager) logProvenance(output []byte) []string {
+	dup := make(map[string]bool)
+	var res []string
+	for _, m := range originRe.FindAllSubmatch(output, -1) {
+		prov := mgr.provenance[string(m[1])]
+		if prov == nil {
+			continue
+		}
+		desc := prov.String()
+		if !dup[desc] {
+			dup[desc] = true
+			res = append(res, desc)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// progAuthor returns NAME from a "# author: NAME" line of a serialized program.
+func progAuthor(data []byte) string {
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if bytes.HasPrefix(line, []byte("# author:")) {
+			return strings.TrimSpace(string(line[len("# author:"):]))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/google/syzkaller/report"
+)
+
+// Crashes can be rendered into ready-to-send bug reports (/crash/report?id=ID&template=NAME).
+// Templates are text/template files executed on ReportData. The built-in "upstream" template
+// produces a kernel mailing list email body. Every NAME.txt file in cfg.Report_Templates dir
+// is an additional template (or replaces the built-in one with the same name).
+// Templates are re-read on every request, so they can be edited without a restart.
+
+// ReportData is what report templates are executed on.
+type ReportData struct {
+	Title     string
+	Manager   string // manager name
+	Kernel    string // kernel version
+	Commit    string // kernel commit, if known
+	Config    string // kernel config reference (cfg.Kernel_Config)
+	Link      string // crash page in the manager web UI
+	Guilty    string // the most likely culprit source file, if known
+	Subsystem string // subsystem of the guilty file (e.g. net/ipv4), for routing to maintainers
+	Count     int    // number of crashes in this manager run
+	Report    string // oops report of the most recent crash
+	Log       string // console log of the most recent crash
+	Repro     string // syzkaller reproducer program
+	CRepro    string // C reproducer, set only if it reproduces the crash
+}
+
+// maxReportLog is how much of the console log tail goes into reports.
+const maxReportLog = 64 << 10
+
+const upstreamTemplate = `Subject: {{.Title}}
+
+Hello,
+
+syzkaller hit the following crash on {{if .Commit}}commit {{.Commit}}{{else}}{{.Kernel}}{{end}}.
+{{if .Commit}}kernel: {{.Kernel}}
+{{end}}{{if .Guilty}}guilty file: {{.Guilty}}
+{{end}}{{if .Config}}.config is attached ({{.Config}}).
+{{end}}
+{{.Report}}
+{{if .CRepro}}
+C reproducer:
+{{.CRepro}}
+{{else if .Repro}}
+syzkaller reproducer (run with syz-execprog):
+{{.Repro}}
+{{else}}
+Unfortunately, I don't have a reproducer for this crash.
+{{end}}`
+
+// reportTemplates returns all available templates, must be called under mgr.mu.
+func (mgr *Manager) reportTemplates() (map[string]*template.Template, error) {
+	templates := map[string]*template.Template{
+		"upstream": template.Must(template.New("upstream").Parse(upstreamTemplate)),
+	}
+	if mgr.cfg.Report_Templates == "" {
+		return templates, nil
+	}
+	files, err := filepath.Glob(filepath.Join(mgr.cfg.Report_Templates, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".txt")
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %v", err)
+		}
+		t, err := template.New(name).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %v: %v", file, err)
+		}
+		templates[name] = t
+	}
+	return templates, nil
+}
+
+// reportTemplateNames returns sorted template names for the crash page, must be called under mgr.mu.
+func (mgr *Manager) reportTemplateNames() []string {
+	templates, err := mgr.reportTemplates()
+	if err != nil {
+		return []string{"upstream"}
+	}
+	var names []string
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reportData collects data for report templates, must be called under mgr.mu.
+func (mgr *Manager) reportData(crash *CrashInfo) *ReportData {
+	dir := filepath.Join(mgr.crashdir, crash.ID)
+	data := &ReportData{
+		Title:     crash.Title,
+		Manager:   mgr.cfg.Name,
+		Kernel:    mgr.kernelTag.Version,
+		Commit:    mgr.kernelTag.Commit,
+		Config:    mgr.cfg.Kernel_Config,
+		Link:      fmt.Sprintf("http://%v/crash?id=%v", mgr.cfg.Http, crash.ID),
+		Count:     crash.Count,
+		Guilty:    crash.Guilty,
+		Subsystem: report.GuiltySubsystem(crash.Guilty),
+	}
+	if len(crash.Logs) != 0 {
+		log := crash.Logs[0]
+		if report, err := ioutil.ReadFile(filepath.Join(dir, "report"+strings.TrimPrefix(log, "log"))); err == nil {
+			data.Report = string(report)
+		}
+		if output, err := ioutil.ReadFile(filepath.Join(dir, log)); err == nil {
+			if len(output) > maxReportLog {
+				output = output[len(output)-maxReportLog:]
+			}
+			data.Log = string(output)
+		}
+	}
+	if repro, err := ioutil.ReadFile(filepath.Join(dir, "repro.prog")); err == nil {
+		data.Repro = string(repro)
+		// See saveRepro for the header format.
+		if bytes.Contains(repro, []byte(" c_repro=true\n")) {
+			if crepro, err := ioutil.ReadFile(filepath.Join(dir, "repro.c")); err == nil {
+				data.CRepro = string(crepro)
+			}
+		}
+	}
+	return data
+}
+
+func (mgr *Manager) httpCrashReport(w http.ResponseWriter, r *http.Request) {
+	id := r.FormValue("id")
+	if id == "" || filepath.Base(id) != id {
+		http.Error(w, fmt.Sprintf("bad crash id: %q", id), http.StatusBadRequest)
+		return
+	}
+	mgr.mu.Lock()
+	crash, err := mgr.readCrash(id)
+	if err != nil {
+		mgr.mu.Unlock()
+		http.Error(w, fmt.Sprintf("failed to read crash: %v", err), http.StatusNotFound)
+		return
+	}
+	data := mgr.reportData(crash)
+	templates, err := mgr.reportTemplates()
+	mgr.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	name := r.FormValue("template")
+	if name == "" {
+		name = "upstream"
+	}
+	t := templates[name]
+	if t == nil {
+		http.Error(w, fmt.Sprintf("unknown template %q", name), http.StatusNotFound)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,162 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/syzkaller/csource"
+	"github.com/google/syzkaller/report"
+	"github.com/google/syzkaller/repro"
+)
+
+// Crashes with a kernel oops are reproduced automatically: the crash log is bisected
+// down to a minimal program on cfg.Repro_Count spare VMs (booted only for reproduction),
+// one crash at a time. Every crash title is tried up to cfg.Repro_Attempts times per manager run:
+// a failed attempt is retried when the crash happens again, with the fresh log and an increasing
+// budget (twice as many suspected programs every time, see repro.RunBudget).
+// Successful results are saved into the crash dir as repro.prog
+// (with execution options in a comment) and as a standalone C program repro.c,
+// failures are only logged.
+
+type ReproRequest struct {
+	title  string
+	output []byte
+	kernel string // kernel variant the crash happened on (see kernels.go)
+	budget int
+}
+
+// reproState is the reproduction schedule of a crash title.
+type reproState struct {
+	attempts int  // started attempts
+	running  bool // queued or being reproduced
+}
+
+const reproQueueSize = 100
+
+// queueRepro must be called with mgr.mu held.
+func (mgr *Manager) queueRepro(title string, output []byte, kernel string) {
+	if !mgr.cfg.Reproduce {
+		return
+	}
+	st := mgr.repros[title]
+	if st == nil {
+		st = new(reproState)
+		mgr.repros[title] = st
+	}
+	if st.running || st.attempts >= mgr.cfg.Repro_Attempts {
+		return
+	}
+	if !report.ContainsCrash(output) {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(mgr.crashdir, hashString([]byte(title)), "repro.prog")); err == nil {
+		return
+	}
+	select {
+	case mgr.reproQueue <- &ReproRequest{title, output, kernel, 1 << uint(st.attempts)}:
+		st.attempts++
+		st.running = true
+	default:
+	}
+}
+
+// reproRunning returns whether title is queued or being reproduced, must be called with mgr.mu held.
+func (mgr *Manager) reproRunning(title string) bool {
+	st := mgr.repros[title]
+	return st != nil && st.running
+}
+
+// reproLoop reproduces queued crashes one at a time until fuzzing is stopped,
+// then closes mgr.reproDone (repro VMs are shut down by then).
+func (mgr *Manager) reproLoop() {
+	defer close(mgr.reproDone)
+	for {
+		var req *ReproRequest
+		select {
+		case <-mgr.stop:
+			return
+		case req = <-mgr.reproQueue:
+		}
+		cfg := mgr.cfg
+		if v := mgr.kernels.lookup(req.kernel); v != nil {
+			// Reproduce on the kernel that crashed.
+			cfg = v.cfg
+			mgr.logf(0, "reproducing crash '%v' on kernel %v (budget %v)", req.title, v.Name, req.budget)
+		} else {
+			mgr.logf(0, "reproducing crash '%v' (budget %v)", req.title, req.budget)
+		}
+		mgr.audit(&AuditEvent{Type: "repro started", Title: req.title, Reason: req.kernel})
+		res, err := repro.RunBudget(req.output, cfg, mgr.cfg.Repro_Count, req.budget, mgr.stop)
+		mgr.mu.Lock()
+		mgr.repros[req.title].running = false
+		if err == repro.ErrStopped {
+			mgr.mu.Unlock()
+			mgr.logf(0, "reproduction of '%v' is stopped", req.title)
+			return
+		}
+		if err != nil {
+			mgr.logf(0, "failed to reproduce '%v': %v", req.title, err)
+			mgr.audit(&AuditEvent{Type: "repro finished", Title: req.title, Reason: err.Error()})
+		} else if res == nil {
+			mgr.stats["repro failed"]++
+			mgr.logf(0, "could not reproduce '%v'", req.title)
+			mgr.audit(&AuditEvent{Type: "repro finished", Title: req.title, Reason: "failed"})
+		} else {
+			mgr.stats["repro success"]++
+			mgr.saveRepro(req.title, res, req.output)
+			mgr.audit(&AuditEvent{Type: "repro finished", Title: req.title,
+				Reason: fmt.Sprintf("reproduced (c_repro=%v)", res.CRepro)})
+		}
+		mgr.emailCrash(req.title)
+		mgr.mu.Unlock()
+	}
+}
+
+// saveRepro must be called with mgr.mu held.
+// output is the crash log, provenance of origins mentioned in it is recorded in the reproducers.
+func (mgr *Manager) saveRepro(title string, res *repro.Result, output []byte) {
+	prog := res.Prog.Serialize()
+	provenance := mgr.logProvenance(output)
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# %v\n", res.Title)
+	fmt.Fprintf(buf, "# threaded=%v collide=%v procs=%v c_repro=%v\n",
+		res.Opts.Threaded, res.Opts.Collide, res.Opts.Procs, res.CRepro)
+	for _, prov := range provenance {
+		fmt.Fprintf(buf, "# provenance: %v\n", prov)
+	}
+	buf.Write(prog)
+	file := filepath.Join(mgr.crashdir, hashString([]byte(title)), "repro.prog")
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0660); err != nil {
+		mgr.logf(0, "failed to write reproducer: %v", err)
+		return
+	}
+	opts := res.Opts
+	opts.Repeat = true
+	src := csource.Write(res.Prog, opts)
+	if formatted, err := csource.Format(src); err != nil {
+		mgr.logf(0, "%v", err)
+	} else {
+		src = formatted
+	}
+	if !res.CRepro {
+		src = append([]byte("/* WARNING: this C program did not reproduce the crash, use repro.prog with syz-execprog. */\n"), src...)
+	}
+	for i := len(provenance) - 1; i >= 0; i-- {
+		// Comment lines of the embedded program, so that the C file can be used as a seed.
+		src = append([]byte(fmt.Sprintf("// # provenance: %v\n", provenance[i])), src...)
+	}
+	if err := ioutil.WriteFile(filepath.Join(filepath.Dir(file), "repro.c"), src, 0660); err != nil {
+		mgr.logf(0, "failed to write C reproducer: %v", err)
+	}
+	mgr.logf(0, "reproduced '%v' (c_repro=%v), saved to %v", title, res.CRepro, file)
+	if mgr.cfg.Hub_Addr != "" {
+		mgr.hubRepros = append(mgr.hubRepros, prog)
+	}
+	mgr.uploadRepro(title, res, prog, src)
+}
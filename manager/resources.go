@@ -0,0 +1,96 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Fuzzers report errnos of resource-producing calls (see prog.ResourceCalls)
+// whenever they execute an unmodified corpus program (candidates and periodic rechecks).
+// A corpus program is broken if one of its resource-producing calls succeeded when
+// the program was triaged, but has failed in all of at least resourceBrokenExecs
+// executions on the current kernel: the rest of the program then operates
+// on an invalid resource and does not reproduce the original coverage (typically
+// the kernel has changed and no longer allows to create the resource this way).
+// Calls that never succeeded (e.g. the program tests an error path) don't make it broken,
+// neither do programs triaged before the triage-time results were recorded.
+// Broken programs are dropped from the corpus on the next minimization,
+// fuzzers will rediscover working variants of them.
+const resourceBrokenExecs = 3
+
+// ProgResources is the distribution of errnos of resource-producing calls of a program.
+type ProgResources struct {
+	Execs     int
+	Errnos    map[int]map[int]int // call index -> errno -> number of executions
+	Succeeded map[int]bool        // calls that succeeded when the program was triaged
+}
+
+// broken returns index of a resource-producing call that consistently fails, or -1.
+func (pr *ProgResources) broken() int {
+	if pr.Execs < resourceBrokenExecs {
+		return -1
+	}
+	for call, errnos := range pr.Errnos {
+		if pr.Succeeded[call] && errnos[0] == 0 {
+			return call
+		}
+	}
+	return -1
+}
+
+// addResourceBaseline records triage-time results of resource-producing calls of the program
+// with hash sig (see RpcInput.Resources), must be called under mgr.mu.
+func (mgr *Manager) addResourceBaseline(sig string, succeeded []int) {
+	if len(succeeded) == 0 {
+		return
+	}
+	pr := mgr.resources[sig]
+	if pr == nil {
+		pr = &ProgResources{Errnos: make(map[int]map[int]int)}
+		mgr.resources[sig] = pr
+	}
+	pr.Succeeded = make(map[int]bool)
+	for _, call := range succeeded {
+		pr.Succeeded[call] = true
+	}
+}
+
+// addResourceResults must be called under mgr.mu.
+func (mgr *Manager) addResourceResults(results []ResourceResult) {
+	for _, res := range results {
+		if len(res.Calls) != len(res.Errnos) {
+			continue
+		}
+		pr := mgr.resources[res.Sig]
+		if pr == nil {
+			pr = &ProgResources{Errnos: make(map[int]map[int]int)}
+			mgr.resources[res.Sig] = pr
+		}
+		wasBroken := pr.broken() != -1
+		pr.Execs++
+		for i, call := range res.Calls {
+			if pr.Errnos[call] == nil {
+				pr.Errnos[call] = make(map[int]int)
+			}
+			pr.Errnos[call][res.Errnos[i]]++
+		}
+		if call := pr.broken(); call != -1 && !wasBroken {
+			mgr.stats["broken programs"]++
+			mgr.logf(0, "corpus program %v is broken: resource-producing call #%v succeeded during triage,"+
+				" but failed in all %v executions: %v",
+				res.Sig, call, pr.Execs, pr.Errnos[call])
+			mgr.audit(&AuditEvent{Type: "input broken", Prog: res.Sig,
+				Reason: fmt.Sprintf("resource-producing call #%v failed in all %v executions", call, pr.Execs)})
+		}
+	}
+}
+
+// brokenProg returns whether the program with hash sig is broken, must be called under mgr.mu.
+func (mgr *Manager) brokenProg(sig string) bool {
+	pr := mgr.resources[sig]
+	return pr != nil && pr.broken() != -1
+}
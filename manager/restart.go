@@ -0,0 +1,166 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/google/syzkaller/cover"
+	. "github.com/google/syzkaller/rpctype"
+	"github.com/google/syzkaller/sys"
+)
+
+// Graceful restart (SIGUSR1 or POST /restart) stops all VMs, saves a checkpoint
+// to workdir/checkpoint.json.gz and re-executes the manager binary (possibly a new one)
+// with the same arguments. The new process restores triaged corpus with coverage,
+// stats and crash counts from the checkpoint instead of re-triaging the whole corpus.
+// The checkpoint is discarded if the kernel or the set of enabled syscalls has changed.
+
+// Checkpoint is manager state saved across a graceful restart.
+type Checkpoint struct {
+	Kernel          string     // kernel tag, the coverage is valid only for the same kernel
+	EnabledSyscalls string     // the corpus is filtered by enabled syscalls
+	Corpus          []RpcInput // triaged corpus with coverage
+	Stats           map[string]uint64
+	CrashTypes      map[string]int
+}
+
+func (mgr *Manager) checkpointFile() string {
+	return filepath.Join(mgr.cfg.Workdir, "checkpoint.json.gz")
+}
+
+// requestRestart initiates a graceful restart.
+func (mgr *Manager) requestRestart(reason string) {
+	mgr.mu.Lock()
+	mgr.restarting = true
+	mgr.mu.Unlock()
+	mgr.stopFuzzing("restart: " + reason)
+}
+
+func (mgr *Manager) httpRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "restart requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	mgr.requestRestart("requested from web UI")
+	fmt.Fprintf(w, "restarting, VMs are being stopped\n")
+}
+
+// restart saves the checkpoint and re-executes the manager (if Options.Reexec), called after finish.
+func (mgr *Manager) restart() {
+	mgr.mu.Lock()
+	err := mgr.writeCheckpoint()
+	mgr.corpusDB.Close()
+	mgr.valuesDB.Close()
+	mgr.mu.Unlock()
+	if err != nil {
+		// The new process will re-triage the corpus.
+		mgr.logf(0, "failed to write checkpoint: %v", err)
+	}
+	if !mgr.opts.Reexec {
+		// Embedded campaign, the next Start picks up the checkpoint.
+		return
+	}
+	// Look the binary up by path (rather than use the running one),
+	// so that an updated binary is picked up.
+	bin, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		mgr.fail(fmt.Errorf("failed to find manager binary: %v", err))
+		return
+	}
+	mgr.logf(-1, "restarting %v", bin)
+	if err := reexec(bin, os.Args); err != nil {
+		mgr.fail(fmt.Errorf("failed to restart: %v", err))
+	}
+}
+
+// writeCheckpoint must be called under mgr.mu.
+func (mgr *Manager) writeCheckpoint() error {
+	cp := &Checkpoint{
+		Kernel:          mgr.kernelTag.String(),
+		EnabledSyscalls: mgr.enabledSyscalls,
+		Corpus:          mgr.corpus,
+		Stats:           mgr.stats,
+		CrashTypes:      mgr.crashTypes,
+	}
+	tmp := mgr.checkpointFile() + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	err = json.NewEncoder(gz).Encode(cp)
+	if err1 := gz.Close(); err == nil {
+		err = err1
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, mgr.checkpointFile())
+}
+
+// loadCheckpoint restores state saved before a graceful restart and removes
+// already triaged programs from candidates. The checkpoint is used only once.
+// Must be called under mgr.mu after the corpus database is loaded.
+func (mgr *Manager) loadCheckpoint() {
+	fn := mgr.checkpointFile()
+	f, err := os.Open(fn)
+	if err != nil {
+		return
+	}
+	defer os.Remove(fn)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		mgr.logf(0, "failed to read checkpoint: %v", err)
+		return
+	}
+	cp := new(Checkpoint)
+	if err := json.NewDecoder(gz).Decode(cp); err != nil {
+		mgr.logf(0, "failed to read checkpoint: %v", err)
+		return
+	}
+	for k, v := range cp.Stats {
+		mgr.stats[k] += v
+	}
+	for title, n := range cp.CrashTypes {
+		mgr.crashTypes[title] += n
+	}
+	if cp.Kernel != mgr.kernelTag.String() || cp.EnabledSyscalls != mgr.enabledSyscalls {
+		mgr.logf(0, "kernel or enabled syscalls have changed, re-triaging corpus")
+		return
+	}
+	triaged := make(map[string]bool)
+	for _, inp := range cp.Corpus {
+		call, ok := sys.CallID[inp.Call]
+		key := hashString(inp.Prog)
+		if _, inDB := mgr.corpusDB.Records[key]; !ok || !inDB {
+			continue
+		}
+		triaged[key] = true
+		if inp.Success {
+			inp.Success = mgr.addSuccess(&inp)
+		}
+		mgr.corpus = append(mgr.corpus, inp)
+		mgr.corpusCover[call] = cover.Union(mgr.corpusCover[call], inp.Cover)
+	}
+	candidates := mgr.candidates[:0]
+	for _, data := range mgr.candidates {
+		if !triaged[hashString(data)] {
+			candidates = append(candidates, data)
+		}
+	}
+	mgr.candidates = candidates
+	mgr.logf(0, "restored %v triaged programs from checkpoint", len(mgr.corpus))
+}
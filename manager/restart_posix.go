@@ -0,0 +1,22 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// +build !windows
+
+package manager
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyRestart relays SIGUSR1 (graceful restart request) to c.
+func notifyRestart(c chan os.Signal) {
+	signal.Notify(c, syscall.SIGUSR1)
+}
+
+// reexec replaces the current process with bin, the pid is preserved.
+func reexec(bin string, args []string) error {
+	return syscall.Exec(bin, args, os.Environ())
+}
@@ -0,0 +1,26 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"os"
+	"os/exec"
+)
+
+// notifyRestart is a no-op: there is no SIGUSR1 on Windows, use POST /restart.
+func notifyRestart(c chan os.Signal) {
+}
+
+// reexec starts bin as a new process with the same stdio and exits.
+func reexec(bin string, args []string) error {
+	cmd := exec.Command(bin, args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
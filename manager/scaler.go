@@ -0,0 +1,110 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"sync"
+	"time"
+)
+
+// Scaler throttles the number of simultaneously running VMs between
+// cfg.Min_Count and cfg.Count depending on host load, so that an overloaded
+// host does not cause spurious VM hangs. Running VMs are not killed
+// when the limit goes down, they just are not restarted.
+type Scaler struct {
+	min     int
+	max     int
+	mu      sync.Mutex
+	cv      *sync.Cond
+	allowed int
+	running int
+	stopped bool
+	logf    logger
+}
+
+const (
+	scalePeriod  = 30 * time.Second
+	highLoad     = 1.0 // per host CPU
+	lowLoad      = 0.7
+	lowMemory    = 0.1 // fraction of available memory
+	enoughMemory = 0.2
+)
+
+func newScaler(min, max int, logf logger) *Scaler {
+	s := &Scaler{
+		min:     min,
+		max:     max,
+		allowed: max,
+		logf:    logf,
+	}
+	s.cv = sync.NewCond(&s.mu)
+	if min != 0 && min < max {
+		go s.loop()
+	}
+	return s
+}
+
+// acquire waits for a free VM slot. Returns false if fuzzing is stopped.
+func (s *Scaler) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.running >= s.allowed && !s.stopped {
+		s.cv.Wait()
+	}
+	if s.stopped {
+		return false
+	}
+	s.running++
+	return true
+}
+
+func (s *Scaler) release() {
+	s.mu.Lock()
+	s.running--
+	s.cv.Signal()
+	s.mu.Unlock()
+}
+
+// stop wakes up all waiters in acquire and makes them return false.
+func (s *Scaler) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.cv.Broadcast()
+	s.mu.Unlock()
+}
+
+func (s *Scaler) state() (allowed, running int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.allowed, s.running
+}
+
+func (s *Scaler) loop() {
+	for range time.NewTicker(scalePeriod).C {
+		load, err := hostLoad()
+		if err != nil {
+			s.logf(0, "failed to get host load: %v", err)
+			continue
+		}
+		mem, err := hostMemory()
+		if err != nil {
+			s.logf(0, "failed to get host memory: %v", err)
+			continue
+		}
+		s.mu.Lock()
+		allowed := s.allowed
+		if (load > highLoad || mem < lowMemory) && allowed > s.min {
+			allowed--
+		} else if load < lowLoad && mem > enoughMemory && allowed < s.max {
+			allowed++
+			s.cv.Signal()
+		}
+		if allowed != s.allowed {
+			s.logf(0, "host load %.2f, free memory %.0f%%: changing VM limit %v -> %v",
+				load, mem*100, s.allowed, allowed)
+			s.allowed = allowed
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// hostLoad returns 1-minute load average per CPU.
+func hostLoad() (float64, error) {
+	// The output looks like "{ 1.52 1.61 1.70 }".
+	out, err := exec.Command("sysctl", "-n", "vm.loadavg").Output()
+	if err != nil {
+		return 0, fmt.Errorf("sysctl vm.loadavg failed: %v", err)
+	}
+	fields := strings.Fields(strings.Trim(string(out), "{} \n"))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("bad vm.loadavg: %q", out)
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return load / float64(runtime.NumCPU()), nil
+}
+
+// hostMemory returns fraction of available (free and inactive) host memory.
+func hostMemory() (float64, error) {
+	out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return 0, fmt.Errorf("sysctl hw.memsize failed: %v", err)
+	}
+	total, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil || total == 0 {
+		return 0, fmt.Errorf("bad hw.memsize: %q", out)
+	}
+	// The output looks like:
+	// Mach Virtual Memory Statistics: (page size of 4096 bytes)
+	// Pages free:                               12345.
+	// Pages inactive:                          123456.
+	out, err = exec.Command("vm_stat").Output()
+	if err != nil {
+		return 0, fmt.Errorf("vm_stat failed: %v", err)
+	}
+	pageSize := uint64(4096)
+	var avail uint64
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.Contains(line, "page size of") {
+			fields := strings.Fields(line[strings.Index(line, "page size of")+len("page size of"):])
+			if len(fields) != 0 {
+				if v, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+					pageSize = v
+				}
+			}
+			continue
+		}
+		colon := strings.Index(line, ":")
+		if colon == -1 {
+			continue
+		}
+		switch line[:colon] {
+		case "Pages free", "Pages inactive", "Pages speculative":
+			v, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(line[colon+1:]), "."), 10, 64)
+			avail += v
+		}
+	}
+	return float64(avail*pageSize) / float64(total), nil
+}
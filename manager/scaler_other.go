@@ -0,0 +1,19 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// +build !linux,!darwin
+
+package manager
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func hostLoad() (float64, error) {
+	return 0, fmt.Errorf("host load is not supported on %v", runtime.GOOS)
+}
+
+func hostMemory() (float64, error) {
+	return 0, fmt.Errorf("host memory is not supported on %v", runtime.GOOS)
+}
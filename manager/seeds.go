@@ -0,0 +1,83 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/syzkaller/prog"
+)
+
+// loadSeeds adds programs from cfg.Seeds directory to candidates.
+// Files are either serialized programs or C reproducers with the program
+// embedded in // comments (C code itself can't be converted back to a program).
+// Programs that are already in the corpus or use disabled syscalls are skipped.
+// A "# author: NAME" line in a seed is recorded in its provenance (see provenance.go).
+// Must be called with mgr.mu held.
+func (mgr *Manager) loadSeeds(dir string) {
+	loaded, skipped := 0, 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(path, ".c") {
+			data = extractCProgram(data)
+		}
+		p, err := prog.Deserialize(data)
+		if err != nil || len(p.Calls) == 0 {
+			mgr.logf(1, "skipping seed %v: not a syzkaller program", path)
+			skipped++
+			return nil
+		}
+		for _, c := range p.Calls {
+			if !mgr.syscalls[c.Meta.ID] {
+				mgr.logf(1, "skipping seed %v: uses disabled syscall %v", path, c.Meta.Name)
+				skipped++
+				return nil
+			}
+		}
+		author := progAuthor(data)
+		data = p.Serialize()
+		if _, ok := mgr.corpusDB.Records[hashString(data)]; ok {
+			return nil
+		}
+		mgr.importProvenance(data, "seeds", "", author)
+		mgr.candidates = append(mgr.candidates, data)
+		loaded++
+		return nil
+	})
+	if err != nil {
+		mgr.logf(0, "failed to read seeds: %v", err)
+	}
+	mgr.logf(0, "loaded %v seeds (%v skipped) from %v", loaded, skipped, dir)
+}
+
+// extractCProgram returns contents of // comments of a C file.
+func extractCProgram(data []byte) []byte {
+	buf := new(bytes.Buffer)
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if !bytes.HasPrefix(line, []byte("//")) {
+			continue
+		}
+		line = bytes.TrimSpace(line[2:])
+		if bytes.HasPrefix(line, []byte("autogenerated by syzkaller")) {
+			continue
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
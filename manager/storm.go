@@ -0,0 +1,219 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/prog"
+	. "github.com/google/syzkaller/rpctype"
+	"github.com/google/syzkaller/sys"
+)
+
+// A crash storm is a trivially hittable bug that dominates recent crashes: VMs spend
+// all the time rebooting and no other bugs are found. Depending on cfg.Crash_Storm
+// the manager temporarily disables (or deprioritizes) syscalls implicated in the reproducer
+// of the storm crash, so that fuzzing can continue finding other bugs.
+// The implicated syscalls are all calls of the reproducer except for mmap
+// and calls that create resources for other calls (they are usually needed by everything).
+const (
+	stormWindow     = time.Hour // recent crashes are crashes in this window
+	stormMinCrashes = 20        // minimal number of recent crashes with the same title
+	stormShare      = 80        // minimal percent of recent crashes with the same title
+	stormDuration   = 2 * time.Hour
+	stormPrioFactor = 0.01 // deprioritized syscalls prios are multiplied by this
+)
+
+// Storm describes the current crash storm.
+type Storm struct {
+	Title string
+	ID    string // crash ID
+	Start time.Time
+	Until time.Time // the syscalls are disabled until this time
+	Calls []string  // implicated syscalls, empty until the crash is reproduced
+	Mode  string    // cfg.Crash_Storm
+}
+
+type recentCrash struct {
+	time  time.Time
+	title string
+}
+
+// stormCrash accounts a new crash and detects/mitigates crash storms.
+// Must be called under mgr.mu.
+func (mgr *Manager) stormCrash(title string) {
+	now := time.Now()
+	mgr.recentCrashes = append(mgr.recentCrashes, recentCrash{now, title})
+	for len(mgr.recentCrashes) != 0 && now.Sub(mgr.recentCrashes[0].time) > stormWindow {
+		mgr.recentCrashes = mgr.recentCrashes[1:]
+	}
+	n := 0
+	for _, c := range mgr.recentCrashes {
+		if c.title == title {
+			n++
+		}
+	}
+	if n < stormMinCrashes || n*100 < len(mgr.recentCrashes)*stormShare {
+		return
+	}
+	st := mgr.storm
+	if st == nil || st.Title != title {
+		mgr.logf(0, "crash storm: '%v' caused %v out of %v crashes in the last %v",
+			title, n, len(mgr.recentCrashes), stormWindow)
+		mgr.stats["crash storms"]++
+		mgr.audit(&AuditEvent{Type: "crash storm", Title: title,
+			Reason: fmt.Sprintf("%v out of %v crashes in the last %v", n, len(mgr.recentCrashes), stormWindow)})
+		if st != nil && len(st.Calls) != 0 {
+			mgr.configGen++
+		}
+		st = &Storm{Title: title, ID: hashString([]byte(title)), Start: now, Mode: mgr.cfg.Crash_Storm}
+		mgr.storm = st
+	}
+	st.Until = now.Add(stormDuration)
+	if st.Mode == "none" || len(st.Calls) != 0 {
+		return
+	}
+	st.Calls = mgr.stormCalls(title)
+	if len(st.Calls) != 0 {
+		mgr.logf(0, "crash storm: %v syscalls %v for %v", st.Mode, st.Calls, stormDuration)
+		mgr.audit(&AuditEvent{Type: "crash storm", Title: title,
+			Reason: fmt.Sprintf("%v syscalls %v", st.Mode, strings.Join(st.Calls, " "))})
+		mgr.configGen++
+	}
+}
+
+// stormCalls returns syscalls implicated in the reproducer of the crash, if any.
+func (mgr *Manager) stormCalls(title string) []string {
+	data, err := ioutil.ReadFile(filepath.Join(mgr.crashdir, hashString([]byte(title)), "repro.prog"))
+	if err != nil {
+		return nil
+	}
+	p, err := prog.Deserialize(data)
+	if err != nil || len(p.Calls) == 0 {
+		return nil
+	}
+	resource := make(map[int]bool)
+	for _, idx := range p.ResourceCalls() {
+		resource[idx] = true
+	}
+	var calls []string
+	dup := make(map[string]bool)
+	for i, c := range p.Calls {
+		if c.Meta.Name == "mmap" || resource[i] || dup[c.Meta.Name] {
+			continue
+		}
+		dup[c.Meta.Name] = true
+		calls = append(calls, c.Meta.Name)
+	}
+	if len(calls) == 0 {
+		// The crash is most likely triggered by the last call.
+		calls = append(calls, p.Calls[len(p.Calls)-1].Meta.Name)
+	}
+	return calls
+}
+
+// checkStorm ends the current storm when it expires, must be called under mgr.mu.
+func (mgr *Manager) checkStorm() {
+	if mgr.storm == nil || time.Now().Before(mgr.storm.Until) {
+		return
+	}
+	mgr.logf(0, "crash storm '%v' is over", mgr.storm.Title)
+	mgr.audit(&AuditEvent{Type: "crash storm over", Title: mgr.storm.Title})
+	if len(mgr.storm.Calls) != 0 {
+		mgr.configGen++
+	}
+	mgr.storm = nil
+}
+
+// stormDisabled returns syscalls disabled due to a crash storm, must be called under mgr.mu.
+func (mgr *Manager) stormDisabled() map[int]bool {
+	if mgr.storm == nil || mgr.storm.Mode != "disable" || len(mgr.storm.Calls) == 0 {
+		return nil
+	}
+	disabled := make(map[int]bool)
+	for _, name := range mgr.storm.Calls {
+		if c := sys.CallMap[name]; c != nil {
+			disabled[c.ID] = true
+		}
+	}
+	return disabled
+}
+
+// stormDisabledCalls returns serialized syscalls disabled due to a crash storm
+// (see ConnectRes.DisabledCalls), must be called under mgr.mu.
+func (mgr *Manager) stormDisabledCalls() string {
+	var ids []string
+	for id := range mgr.stormDisabled() {
+		ids = append(ids, fmt.Sprint(id))
+	}
+	return strings.Join(ids, ",")
+}
+
+// fuzzerSyscalls returns syscalls enabled in fuzzers, must be called under mgr.mu.
+func (mgr *Manager) fuzzerSyscalls() string {
+	disabled := mgr.stormDisabled()
+	if disabled == nil {
+		return mgr.enabledSyscalls
+	}
+	syscalls := make(map[int]bool)
+	for id := range mgr.syscalls {
+		if !disabled[id] {
+			syscalls[id] = true
+		}
+	}
+	return serializeSyscalls(syscalls)
+}
+
+// fuzzerPrios returns syscall priorities for fuzzers, must be called under mgr.mu.
+func (mgr *Manager) fuzzerPrios() [][]float32 {
+	if mgr.storm == nil || mgr.storm.Mode != "deprioritize" || len(mgr.storm.Calls) == 0 {
+		return mgr.prios
+	}
+	var ids []int
+	for _, name := range mgr.storm.Calls {
+		if c := sys.CallMap[name]; c != nil {
+			ids = append(ids, c.ID)
+		}
+	}
+	prios := make([][]float32, len(mgr.prios))
+	for i := range mgr.prios {
+		prios[i] = append([]float32{}, mgr.prios[i]...)
+		for _, id := range ids {
+			prios[i][id] *= stormPrioFactor
+		}
+	}
+	return prios
+}
+
+// stormFilter splits inputs into inputs that can be sent to fuzzers and inputs
+// that use syscalls disabled due to a crash storm, must be called under mgr.mu.
+func (mgr *Manager) stormFilter(inputs []RpcInput) (res, held []RpcInput) {
+	disabled := mgr.stormDisabled()
+	if disabled == nil {
+		return inputs, nil
+	}
+	for _, inp := range inputs {
+		p, err := prog.Deserialize(inp.Prog)
+		if err != nil {
+			continue
+		}
+		ok := true
+		for _, c := range p.Calls {
+			if disabled[c.Meta.ID] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			res = append(res, inp)
+		} else {
+			held = append(held, inp)
+		}
+	}
+	return res, held
+}
@@ -0,0 +1,243 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Manager periodically snapshots corpus coverage per top-level kernel directory (subsystem)
+// and /subsystems shows a heat map of coverage growth over time: subsystems that still
+// climb are red, saturated ones are white. Only PCs that are not yet symbolized
+// are passed to addr2line on every snapshot, so snapshots are cheap after the first one.
+const (
+	subsysPeriod       = 10 * time.Minute
+	subsysMaxSnapshots = 1000 // older snapshots are thinned out
+	subsysColumns      = 48   // number of columns on the heat map
+)
+
+// SubsysSnapshot is corpus coverage per top-level kernel directory at Time.
+type SubsysSnapshot struct {
+	Time  time.Time
+	Cover map[string]int
+}
+
+// subsysLoop takes coverage snapshots, mgr.subsys is guarded by mgr.mu.
+func (mgr *Manager) subsysLoop() {
+	pcDir := make(map[uint32]string) // subsystem of every already symbolized PC
+	dirs := make(map[string]string)  // interned subsystem names
+	for {
+		select {
+		case <-time.After(subsysPeriod):
+		case <-mgr.stop:
+			return
+		}
+		mgr.mu.Lock()
+		cov := mgr.callCover("")
+		mgr.mu.Unlock()
+		var pcs []uint32
+		for _, pc := range cov {
+			if _, ok := pcDir[pc]; !ok {
+				pcs = append(pcs, pc)
+			}
+		}
+		if len(pcs) != 0 {
+			if err := symbolizeSubsys(mgr.cfg.Vmlinux, pcs, pcDir, dirs); err != nil {
+				mgr.logf(0, "failed to symbolize coverage: %v", err)
+				continue
+			}
+		}
+		snap := SubsysSnapshot{Time: time.Now(), Cover: make(map[string]int)}
+		for _, pc := range cov {
+			if dir := pcDir[pc]; dir != "" {
+				snap.Cover[dir]++
+			}
+		}
+		mgr.mu.Lock()
+		mgr.subsys = append(mgr.subsys, snap)
+		if len(mgr.subsys) > subsysMaxSnapshots {
+			// Drop every other snapshot, but keep the first and the last ones.
+			var thinned []SubsysSnapshot
+			for i, s := range mgr.subsys {
+				if i%2 == 0 || i == len(mgr.subsys)-1 {
+					thinned = append(thinned, s)
+				}
+			}
+			mgr.subsys = thinned
+		}
+		mgr.mu.Unlock()
+	}
+}
+
+// symbolizeSubsys adds subsystems of pcs to pcDir.
+// A PC belongs to the file of its outermost (non-inlined) frame.
+// Kernel sources root is the common prefix of all frame files, it is reliable only if
+// some of the frames come from the top-level include dir (headers are inlined everywhere).
+func symbolizeSubsys(vmlinux string, pcs []uint32, pcDir map[uint32]string, dirs map[string]string) error {
+	info, prefix, err := symbolize(vmlinux, pcs)
+	if err != nil {
+		return err
+	}
+	if len(info) == 0 {
+		return fmt.Errorf("'%s' does not have debug info (set CONFIG_DEBUG_INFO=y)", vmlinux)
+	}
+	prefix = prefix[:strings.LastIndexByte(prefix, '/')+1]
+	root := false
+	outer := make(map[uint32]string)
+	for _, li := range info {
+		outer[li.pc] = li.file // inlined frames go first
+		if strings.HasPrefix(li.file[len(prefix):], "include/") {
+			root = true
+		}
+	}
+	if !root {
+		return fmt.Errorf("failed to find kernel source root (common prefix %q)", prefix)
+	}
+	for _, pc := range pcs {
+		file := outer[pc]
+		if file == "" {
+			pcDir[pc] = "" // unknown, don't symbolize it again
+			continue
+		}
+		dir := file[len(prefix):]
+		if slash := strings.IndexByte(dir, '/'); slash != -1 {
+			dir = dir[:slash]
+		} else {
+			dir = "."
+		}
+		if dirs[dir] == "" {
+			dirs[dir] = dir
+		}
+		pcDir[pc] = dirs[dir]
+	}
+	return nil
+}
+
+func (mgr *Manager) httpSubsystems(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	snaps := mgr.subsys
+	mgr.mu.Unlock()
+
+	data := &UISubsysData{Period: subsysPeriod}
+	if len(snaps) == 0 {
+		if err := subsysTemplate.Execute(w, data); err != nil {
+			http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+	// Pick evenly spaced snapshots for columns, always including the last one.
+	var cols []SubsysSnapshot
+	step := (len(snaps) + subsysColumns - 1) / subsysColumns
+	for i := (len(snaps) - 1) % step; i < len(snaps); i += step {
+		cols = append(cols, snaps[i])
+	}
+	for _, s := range cols {
+		data.Times = append(data.Times, s.Time.Format("01/02 15:04"))
+	}
+	last := cols[len(cols)-1]
+	for dir := range last.Cover {
+		row := &UISubsys{Name: dir, Cover: last.Cover[dir]}
+		prev, maxGrowth := 0, 0
+		for i, s := range cols {
+			growth := 0
+			if i != 0 {
+				growth = s.Cover[dir] - prev
+			}
+			prev = s.Cover[dir]
+			if maxGrowth < growth {
+				maxGrowth = growth
+			}
+			row.Cells = append(row.Cells, UISubsysCell{Cover: s.Cover[dir], Growth: growth})
+		}
+		row.Growth = last.Cover[dir] - cols[0].Cover[dir]
+		for i := range row.Cells {
+			if maxGrowth != 0 && row.Cells[i].Growth > 0 {
+				// Whiter for smaller growth, relative to the max growth of the row.
+				row.Cells[i].Heat = 255 - 200*row.Cells[i].Growth/maxGrowth
+			} else {
+				row.Cells[i].Heat = 255
+			}
+		}
+		data.Subsys = append(data.Subsys, row)
+	}
+	sort.Sort(UISubsysArray(data.Subsys))
+	if err := subsysTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+type UISubsysData struct {
+	Period time.Duration
+	Times  []string
+	Subsys []*UISubsys
+}
+
+type UISubsys struct {
+	Name   string
+	Cover  int
+	Growth int // growth over the whole shown period
+	Cells  []UISubsysCell
+}
+
+type UISubsysCell struct {
+	Cover  int
+	Growth int // growth since the previous column
+	Heat   int // green and blue components of the cell background
+}
+
+type UISubsysArray []*UISubsys
+
+func (a UISubsysArray) Len() int { return len(a) }
+func (a UISubsysArray) Less(i, j int) bool {
+	if a[i].Cover != a[j].Cover {
+		return a[i].Cover > a[j].Cover
+	}
+	return a[i].Name < a[j].Name
+}
+func (a UISubsysArray) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+var subsysTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+	<title>syzkaller coverage by subsystem</title>
+	<style>
+		td.cell {
+			font-size: small;
+			text-align: right;
+		}
+	</style>
+</head>
+<body>
+{{if not $.Subsys}}
+No coverage snapshots yet, they are taken every {{$.Period}}.
+{{else}}
+<b>Coverage (PCs) by top-level kernel directory over time</b><br>
+Cell color shows coverage growth since the previous column relative to the max growth of the subsystem.<br>
+<table>
+	<tr>
+		<th>Subsystem</th>
+		<th>Cover</th>
+		<th>Growth</th>
+		{{range $t := $.Times}}<th style="font-size: small">{{$t}}</th>{{end}}
+	</tr>
+	{{range $s := $.Subsys}}
+	<tr>
+		<td>{{$s.Name}}</td>
+		<td>{{$s.Cover}}</td>
+		<td>{{$s.Growth}}</td>
+		{{range $c := $s.Cells}}
+		<td class="cell" style="background: rgb(255, {{$c.Heat}}, {{$c.Heat}})" title="+{{$c.Growth}}">{{$c.Cover}}</td>
+		{{end}}
+	</tr>
+	{{end}}
+</table>
+{{end}}
+</body></html>
+`))
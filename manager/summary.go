@@ -0,0 +1,93 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/syzkaller/fileutil"
+)
+
+// Summary is written to workdir/summary.json when the manager exits,
+// so that CI jobs running time-boxed fuzzing sessions can inspect the results.
+type Summary struct {
+	Reason   string // why fuzzing was stopped
+	Start    time.Time
+	Duration float64        // in seconds
+	Crashes  map[string]int // crash title -> number of occurrences in this session
+	Final    *StatsRecord
+}
+
+// stopFuzzing makes all instances finish and RunManager return.
+func (mgr *Manager) stopFuzzing(reason string) {
+	mgr.stopOnce.Do(func() {
+		mgr.logf(0, "stopping fuzzing: %v", reason)
+		mgr.audit(&AuditEvent{Type: "manager stopped", Reason: reason})
+		mgr.mu.Lock()
+		mgr.stopReason = reason
+		mgr.mu.Unlock()
+		atomic.StoreUint32(&mgr.shutdown, 1)
+		close(mgr.stop)
+		mgr.scaler.stop()
+	})
+}
+
+// limitLoop stops fuzzing when Max_Run_Time or Max_Execs is reached.
+func (mgr *Manager) limitLoop() {
+	for range time.NewTicker(10 * time.Second).C {
+		if mgr.cfg.Max_Run_Time != 0 && time.Since(mgr.startTime) >= time.Duration(mgr.cfg.Max_Run_Time)*time.Second {
+			mgr.stopFuzzing(fmt.Sprintf("reached max run time of %vs", mgr.cfg.Max_Run_Time))
+			return
+		}
+		mgr.mu.Lock()
+		execs := mgr.stats["exec total"]
+		mgr.mu.Unlock()
+		if mgr.cfg.Max_Execs != 0 && execs >= mgr.cfg.Max_Execs {
+			mgr.stopFuzzing(fmt.Sprintf("reached max execs of %v", mgr.cfg.Max_Execs))
+			return
+		}
+	}
+}
+
+// finish flushes the corpus and writes the final summary.
+func (mgr *Manager) finish() {
+	mgr.mu.Lock()
+	mgr.minimizeCorpus()
+	if err := mgr.corpusDB.Compact(); err != nil {
+		mgr.logf(0, "failed to compact corpus database: %v", err)
+	}
+	rec := mgr.statsRecord()
+	dur := time.Since(mgr.startTime)
+	summary := &Summary{
+		Reason:   mgr.stopReason,
+		Start:    mgr.startTime,
+		Duration: dur.Seconds(),
+		Crashes:  make(map[string]int),
+		Final:    rec,
+	}
+	for title, n := range mgr.crashTypes {
+		summary.Crashes[title] = n
+	}
+	mgr.mu.Unlock()
+	mgr.exporter.write(rec)
+
+	data, err := json.MarshalIndent(summary, "", "\t")
+	if err != nil {
+		mgr.logf(0, "failed to marshal summary: %v", err)
+		return
+	}
+	if err := fileutil.WriteFileAtomic(filepath.Join(mgr.cfg.Workdir, "summary.json"), data, 0640); err != nil {
+		mgr.logf(0, "failed to write summary: %v", err)
+	}
+	// Verbosity is lowered on SIGINT, but the summary must be printed anyway.
+	mgr.logf(-1, "fuzzed for %v: corpus %v, cover %v, %v execs, %v crash types",
+		dur-dur%time.Second, rec.Corpus, rec.Cover, rec.Stats["exec total"], len(summary.Crashes))
+	for title, n := range summary.Crashes {
+		mgr.logf(-1, "crash: %v (%v)", title, n)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/syzkaller/vm"
+)
+
+// Console output of a VM contains lines from several clocks:
+// kernel messages are stamped with time since guest boot, fuzzer log lines
+// are stamped with guest wall clock, and manager events happen on host clock.
+// To correlate them, manager measures guest clocks relative to host clock
+// when an instance starts, and then builds a timeline of the crash log
+// with all lines converted to host time.
+
+// ClockInfo relates guest clocks to host clock.
+type ClockInfo struct {
+	Boot time.Time      // host time of guest kernel boot (origin of console timestamps)
+	Skew time.Duration  // guest wall clock minus host wall clock
+	Zone *time.Location // guest time zone (used by fuzzer log timestamps)
+}
+
+func (ci *ClockInfo) String() string {
+	if ci == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("guest booted at %v, wall clock skew %v", ci.Boot.Format(timelineFormat), ci.Skew)
+}
+
+// Event is a manager event related to an instance.
+type Event struct {
+	Time time.Time
+	What string
+}
+
+const timelineFormat = "2006/01/02 15:04:05.000"
+
+var clockRe = regexp.MustCompile(`([0-9]+\.[0-9]+) [0-9]+\.[0-9]+\s+([0-9]{9,}) ([-+][0-9]{4})`)
+
+func measureClock(inst vm.Instance) (*ClockInfo, error) {
+	before := time.Now()
+	outc, errc, err := inst.Run(10*time.Second, "cat /proc/uptime; date '+%s %z'")
+	if err != nil {
+		return nil, err
+	}
+	var output []byte
+loop:
+	for {
+		select {
+		case out := <-outc:
+			output = append(output, out...)
+		case err := <-errc:
+			if err != nil {
+				return nil, err
+			}
+			break loop
+		}
+	}
+	for {
+		// Drain pending output.
+		select {
+		case out := <-outc:
+			output = append(output, out...)
+			continue
+		default:
+		}
+		break
+	}
+	host := before.Add(time.Since(before) / 2)
+	match := clockRe.FindSubmatch(output)
+	if match == nil {
+		return nil, fmt.Errorf("failed to parse guest clocks:\n%s", output)
+	}
+	uptime, err := strconv.ParseFloat(string(match[1]), 64)
+	if err != nil {
+		return nil, err
+	}
+	wall, err := strconv.ParseInt(string(match[2]), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	zone, err := time.Parse("-0700", string(match[3]))
+	if err != nil {
+		return nil, err
+	}
+	_, offset := zone.Zone()
+	ci := &ClockInfo{
+		Boot: host.Add(-time.Duration(uptime * float64(time.Second))),
+		Skew: time.Unix(wall, 0).Sub(host),
+		Zone: time.FixedZone("guest", offset),
+	}
+	return ci, nil
+}
+
+var (
+	kernelTimeRe = regexp.MustCompile(`^[^\[]{0,16}\[ *([0-9]+)\.([0-9]+)\]`)
+	fuzzerTimeRe = regexp.MustCompile(`^([0-9]{4}/[0-9]{2}/[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2})`)
+)
+
+// lineTime returns host time of a console output line, if it has a timestamp.
+func (ci *ClockInfo) lineTime(line []byte) (time.Time, bool) {
+	if match := kernelTimeRe.FindSubmatch(line); match != nil {
+		secs, _ := strconv.ParseInt(string(match[1]), 10, 64)
+		frac := string(match[2])
+		usecs, _ := strconv.ParseInt(frac, 10, 64)
+		for i := len(frac); i < 6; i++ {
+			usecs *= 10
+		}
+		return ci.Boot.Add(time.Duration(secs)*time.Second + time.Duration(usecs)*time.Microsecond), true
+	}
+	if match := fuzzerTimeRe.FindSubmatch(line); match != nil {
+		t, err := time.ParseInLocation("2006/01/02 15:04:05", string(match[1]), ci.Zone)
+		if err == nil {
+			return t.Add(-ci.Skew), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// buildTimeline prefixes every line of output with host time and inserts manager events.
+// Lines without own timestamps inherit time of the previous line.
+func buildTimeline(output []byte, ci *ClockInfo, events []Event) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# %v\n", ci)
+	var last time.Time
+	for pos := 0; pos < len(output); {
+		next := bytes.IndexByte(output[pos:], '\n')
+		if next == -1 {
+			next = len(output)
+		} else {
+			next += pos
+		}
+		line := bytes.TrimRight(output[pos:next], "\r")
+		pos = next + 1
+		if ci != nil {
+			if t, ok := ci.lineTime(line); ok {
+				last = t
+			}
+		}
+		for len(events) != 0 && !last.IsZero() && events[0].Time.Before(last) {
+			fmt.Fprintf(buf, "%v | --- manager: %v\n", events[0].Time.Format(timelineFormat), events[0].What)
+			events = events[1:]
+		}
+		stamp := "                       "
+		if !last.IsZero() {
+			stamp = last.Format(timelineFormat)
+		}
+		fmt.Fprintf(buf, "%v | %s\n", stamp, line)
+	}
+	for _, ev := range events {
+		fmt.Fprintf(buf, "%v | --- manager: %v\n", ev.Time.Format(timelineFormat), ev.What)
+	}
+	return buf.Bytes()
+}
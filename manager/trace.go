@@ -0,0 +1,294 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/fileutil"
+	"github.com/google/syzkaller/report"
+	"github.com/google/syzkaller/vm"
+)
+
+// Corpus programs and reproducers can be re-executed on request from the web UI
+// with ftrace function_graph tracing of cfg.Ftrace functions, so that one can see
+// the kernel path a program takes. Programs are traced one at a time on a spare VM
+// that is booted on demand and shut down when the queue is empty.
+// Traces are saved into workdir/traces/HASH (for corpus programs)
+// and into the crash dir as repro.trace (for reproducers).
+
+type TraceRequest struct {
+	prog []byte
+	file string // where to save the trace
+}
+
+const (
+	traceQueueSize = 100
+	traceTimeout   = 5 * time.Minute
+)
+
+// queueTrace must be called with mgr.mu held.
+func (mgr *Manager) queueTrace(prog []byte, file string) error {
+	if mgr.cfg.Ftrace == "" {
+		return fmt.Errorf("tracing is disabled (ftrace config param is empty)")
+	}
+	if mgr.tracing[file] {
+		return nil
+	}
+	select {
+	case mgr.traceQueue <- &TraceRequest{prog, file}:
+		mgr.tracing[file] = true
+		return nil
+	default:
+		return fmt.Errorf("too many pending trace requests")
+	}
+}
+
+func (mgr *Manager) traceLoop() {
+	var inst vm.Instance
+	var execprogBin, executorBin string
+	for {
+		var req *TraceRequest
+		select {
+		case req = <-mgr.traceQueue:
+		default:
+			if inst != nil {
+				inst.Close()
+				inst = nil
+			}
+			select {
+			case req = <-mgr.traceQueue:
+			case <-mgr.stop:
+				return
+			}
+		}
+		if inst == nil {
+			var err error
+			inst, execprogBin, executorBin, err = mgr.bootTraceVM()
+			if err != nil {
+				mgr.logf(0, "failed to boot VM for tracing: %v", err)
+				mgr.finishTrace(req.file, []byte(fmt.Sprintf("failed to boot VM: %v\n", err)))
+				continue
+			}
+		}
+		trace, ok := traceProg(inst, mgr.cfg.Ftrace, execprogBin, executorBin, req.prog)
+		mgr.finishTrace(req.file, trace)
+		if !ok {
+			// The VM is probably dead, boot a new one for the next request.
+			inst.Close()
+			inst = nil
+		}
+	}
+}
+
+func (mgr *Manager) finishTrace(file string, trace []byte) {
+	os.MkdirAll(filepath.Dir(file), 0700)
+	if err := ioutil.WriteFile(file, trace, 0660); err != nil {
+		mgr.logf(0, "failed to write trace: %v", err)
+	}
+	mgr.mu.Lock()
+	delete(mgr.tracing, file)
+	mgr.mu.Unlock()
+}
+
+func (mgr *Manager) bootTraceVM() (vm.Instance, string, string, error) {
+	vmCfg, err := config.CreateVMConfig(mgr.cfg)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to create VM config: %v", err)
+	}
+	inst, err := vm.Create(mgr.cfg.Type, vmCfg)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to create VM: %v", err)
+	}
+	execprogBin, err := inst.Copy(filepath.Join(mgr.cfg.Syzkaller, "bin", "syz-execprog"))
+	if err != nil {
+		inst.Close()
+		return nil, "", "", fmt.Errorf("failed to copy to VM: %v", err)
+	}
+	executorBin, err := inst.Copy(filepath.Join(mgr.cfg.Syzkaller, "bin", "syz-executor"))
+	if err != nil {
+		inst.Close()
+		return nil, "", "", fmt.Errorf("failed to copy to VM: %v", err)
+	}
+	return inst, execprogBin, executorBin, nil
+}
+
+// traceProg executes the program once with tracing enabled and returns
+// command and console output (with the trace). Returns false if the VM has failed.
+func traceProg(inst vm.Instance, filter, execprogBin, executorBin string, prog []byte) ([]byte, bool) {
+	progFile, err := fileutil.WriteTempFile(prog)
+	if err != nil {
+		return []byte(err.Error()), true
+	}
+	defer os.Remove(progFile)
+	vmProg, err := inst.Copy(progFile)
+	if err != nil {
+		return []byte(fmt.Sprintf("failed to copy to VM: %v\n", err)), false
+	}
+	command := fmt.Sprintf(traceScript, filter,
+		fmt.Sprintf("%v -executor %v -cover=0 -procs=1 -repeat=1 -threaded=false -collide=false %v",
+			execprogBin, executorBin, vmProg))
+	outc, errc, err := inst.Run(traceTimeout, command)
+	if err != nil {
+		return []byte(fmt.Sprintf("failed to run command in VM: %v\n", err)), false
+	}
+	var output []byte
+	for {
+		select {
+		case out := <-outc:
+			output = append(output, out...)
+		case err := <-errc:
+			if err != nil {
+				output = append(output, fmt.Sprintf("\ncommand failed: %v\n", err)...)
+			}
+			if report.ContainsCrash(output) {
+				return output, false
+			}
+			return output, err == nil
+		}
+	}
+}
+
+const traceScript = `cd /sys/kernel/debug/tracing && ` +
+	`echo 0 > tracing_on && ` +
+	`echo function_graph > current_tracer && ` +
+	`echo '%v' > set_ftrace_filter && ` +
+	`echo 4096 > buffer_size_kb && ` +
+	`echo > trace && ` +
+	`echo 1 > tracing_on && ` +
+	`%v; ` +
+	`echo 0 > tracing_on; ` +
+	`cat trace; ` +
+	`echo nop > current_tracer`
+
+func (mgr *Manager) httpInput(w http.ResponseWriter, r *http.Request) {
+	sig := r.FormValue("sig")
+	mgr.mu.Lock()
+	var data *UIInputData
+	for _, inp := range mgr.corpus {
+		if hashString(inp.Prog) == sig {
+			data = &UIInputData{
+				Sig:      sig,
+				Call:     inp.Call,
+				Prog:     string(inp.Prog),
+				Cover:    len(inp.Cover),
+				Tracing:  mgr.tracing[mgr.inputTraceFile(sig)],
+				CanTrace: mgr.cfg.Ftrace != "",
+			}
+			break
+		}
+	}
+	mgr.mu.Unlock()
+	if data == nil {
+		http.Error(w, fmt.Sprintf("unknown input %q", sig), http.StatusNotFound)
+		return
+	}
+	if trace, err := ioutil.ReadFile(mgr.inputTraceFile(sig)); err == nil {
+		data.Trace = string(trace)
+	}
+	if err := inputTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (mgr *Manager) httpInputTrace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "trace requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	sig := r.FormValue("sig")
+	mgr.mu.Lock()
+	var prog []byte
+	for _, inp := range mgr.corpus {
+		if hashString(inp.Prog) == sig {
+			prog = inp.Prog
+			break
+		}
+	}
+	var err error
+	if prog == nil {
+		err = fmt.Errorf("unknown input %q", sig)
+	} else {
+		err = mgr.queueTrace(prog, mgr.inputTraceFile(sig))
+	}
+	mgr.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/input?sig="+sig, http.StatusSeeOther)
+}
+
+func (mgr *Manager) httpCrashTrace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "trace requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.FormValue("id")
+	if id == "" || filepath.Base(id) != id {
+		http.Error(w, fmt.Sprintf("bad crash id: %q", id), http.StatusBadRequest)
+		return
+	}
+	prog, err := ioutil.ReadFile(filepath.Join(mgr.crashdir, id, "repro.prog"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read reproducer: %v", err), http.StatusNotFound)
+		return
+	}
+	mgr.mu.Lock()
+	err = mgr.queueTrace(prog, mgr.reproTraceFile(id))
+	mgr.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/crash?id="+id, http.StatusSeeOther)
+}
+
+// reproTraceFile returns trace file for reproducer of crash id.
+func (mgr *Manager) reproTraceFile(id string) string {
+	return filepath.Join(mgr.crashdir, id, "repro.trace")
+}
+
+func (mgr *Manager) inputTraceFile(sig string) string {
+	return filepath.Join(mgr.cfg.Workdir, "traces", sig)
+}
+
+type UIInputData struct {
+	Sig      string
+	Call     string
+	Prog     string
+	Cover    int
+	Trace    string
+	Tracing  bool
+	CanTrace bool
+}
+
+var inputTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syzkaller input {{.Sig}}</title>
+</head>
+<body>
+Call: {{.Call}} <br>
+Cover: {{.Cover}} <br>
+<pre>{{.Prog}}</pre>
+{{if .Tracing}}
+	Tracing... <br>
+{{else if .CanTrace}}
+<form action='/input/trace' method='post'>
+	<input type='hidden' name='sig' value='{{.Sig}}'>
+	<input type='submit' value='Trace'>
+</form>
+{{end}}
+{{if .Trace}}<pre>{{.Trace}}</pre>{{end}}
+</body></html>
+`))
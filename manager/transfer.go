@@ -0,0 +1,144 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// The corpus can be moved between managers over HTTP:
+//   curl http://manager1/corpus/download > corpus.tar.gz
+//   curl --data-binary @corpus.tar.gz http://manager2/corpus/upload
+// Download streams all programs of the persistent corpus as a gzipped tarball
+// (one file per program named by its hash). Upload accepts such tarball
+// (gzipped or not) or a single program, and adds new programs to candidates,
+// so they are triaged by fuzzers as usual.
+
+// maxUploadSize limits both the request body and the decompressed upload.
+const maxUploadSize = 256 << 20
+
+func (mgr *Manager) httpCorpusDownload(w http.ResponseWriter, r *http.Request) {
+	mgr.mu.Lock()
+	var keys []string
+	progs := make(map[string][]byte)
+	for key, rec := range mgr.corpusDB.Records {
+		keys = append(keys, key)
+		progs[key] = rec.Val
+	}
+	mgr.mu.Unlock()
+	sort.Strings(keys)
+
+	w.Header().Set("Content-Type", "application/x-gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%v-corpus.tar.gz", mgr.cfg.Name))
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, key := range keys {
+		data := progs[key]
+		hdr := &tar.Header{
+			Name:    "corpus/" + key,
+			Mode:    0640,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			mgr.logf(0, "failed to write corpus tarball: %v", err)
+			return
+		}
+		if _, err := tw.Write(data); err != nil {
+			mgr.logf(0, "failed to write corpus tarball: %v", err)
+			return
+		}
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func (mgr *Manager) httpCorpusUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "upload requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	progs, err := readUpload(http.MaxBytesReader(w, r.Body, maxUploadSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	mgr.mu.Lock()
+	added, known, dropped := 0, 0, 0
+	for _, data := range progs {
+		if !mgr.enabledProgram(data) {
+			mgr.audit(&AuditEvent{Type: "input rejected", Prog: hashString(data), Reason: "upload: invalid or uses disabled syscalls"})
+			dropped++
+			continue
+		}
+		if _, ok := mgr.corpusDB.Records[hashString(data)]; ok {
+			known++
+			continue
+		}
+		mgr.importProvenance(data, "upload", "", progAuthor(data))
+		mgr.candidates = append(mgr.candidates, data)
+		added++
+	}
+	mgr.stats["uploaded inputs"] += uint64(added)
+	mgr.prioritizeCandidates()
+	mgr.mu.Unlock()
+	mgr.logf(0, "corpus upload: added %v, known %v, dropped %v", added, known, dropped)
+	fmt.Fprintf(w, "added %v programs to candidates (%v are already in corpus, %v are invalid or use disabled syscalls)\n",
+		added, known, dropped)
+}
+
+// readUpload returns programs from an (optionally gzipped) tarball or a single program.
+func readUpload(r io.Reader) ([][]byte, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		// Don't let a small gzip bomb exhaust memory.
+		br = bufio.NewReader(io.LimitReader(gz, maxUploadSize+1))
+	}
+	data, err := ioutil.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxUploadSize {
+		return nil, fmt.Errorf("upload is larger than %v bytes", maxUploadSize)
+	}
+	// Tar archives have "ustar" magic at offset 257.
+	if len(data) < 262 || !bytes.Equal(data[257:262], []byte("ustar")) {
+		return [][]byte{data}, nil
+	}
+	var progs [][]byte
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		prog, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		progs = append(progs, prog)
+	}
+	return progs, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/google/syzkaller/fileutil"
+)
+
+// Report is a structured description of a new crash.
+// It is passed as JSON on stdin to the cfg.Triage command.
+type Report struct {
+	Title    string
+	Severity string
+	Time     time.Time
+	VM       string
+	Uptime   float64 // VM uptime in seconds when the crash happened
+	Kernel   string
+	Commit   string
+	Guilty   string // the most likely culprit source file (see report.ExtractGuiltyFile)
+	Output   string
+}
+
+// Verdict is the JSON object printed by the triage command on stdout.
+// All fields are optional: empty Title/Severity leave the report as is,
+// Ignore drops the crash, Suppress additionally drops all further crashes
+// with the same title (until the config is reloaded).
+type Verdict struct {
+	Title    string
+	Severity string
+	Ignore   bool
+	Suppress bool
+}
+
+const triageTimeout = time.Minute
+
+// triage runs the triage command on rep and applies the verdict to it.
+// Returns false if the crash must be dropped.
+func (mgr *Manager) triage(rep *Report) bool {
+	if mgr.cfg.Triage == "" {
+		return true
+	}
+	v, err := runTriage(mgr.cfg.Triage, rep)
+	if err != nil {
+		mgr.logf(0, "failed to triage '%v': %v", rep.Title, err)
+		return true
+	}
+	if v.Suppress {
+		re := regexp.MustCompile("^" + regexp.QuoteMeta(rep.Title) + "$")
+		mgr.mu.Lock()
+		mgr.knownCrashes = append(mgr.knownCrashes, re)
+		mgr.mu.Unlock()
+		mgr.logf(0, "triage: suppressing '%v'", rep.Title)
+		return false
+	}
+	if v.Ignore {
+		mgr.logf(1, "triage: ignoring '%v'", rep.Title)
+		return false
+	}
+	if v.Title != "" {
+		rep.Title = v.Title
+	}
+	if v.Severity != "" {
+		rep.Severity = v.Severity
+	}
+	return true
+}
+
+func runTriage(command string, rep *Report) (*Verdict, error) {
+	data, err := json.Marshal(rep)
+	if err != nil {
+		return nil, err
+	}
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd := fileutil.ShellCommand(command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	timer := time.AfterFunc(triageTimeout, func() {
+		cmd.Process.Kill()
+	})
+	err = cmd.Wait()
+	timer.Stop()
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, stderr.Bytes())
+	}
+	v := new(Verdict)
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return v, nil
+	}
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return nil, fmt.Errorf("failed to parse verdict: %v\n%s", err, stdout.Bytes())
+	}
+	return v, nil
+}
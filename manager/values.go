@@ -0,0 +1,90 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package manager
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/google/syzkaller/db"
+	"github.com/google/syzkaller/prog"
+)
+
+// Interesting argument values reported by fuzzers (values of calls that gave new coverage)
+// are accumulated in a pool that is persisted in workdir/values.db next to the corpus
+// and handed out to fuzzers on connect, so that learned magic values survive restarts.
+
+func (mgr *Manager) loadValues(valuesDB *db.DB) {
+	mgr.values = prog.NewValuePool()
+	mgr.valuesDB = valuesDB
+	for key, rec := range valuesDB.Records {
+		if !strings.Contains(key, ":") {
+			// Values keyed by type name (before they were keyed by call and arg path).
+			if err := valuesDB.Delete(key); err != nil {
+				mgr.logf(0, "failed to delete values: %v", err)
+			}
+			continue
+		}
+		for _, v := range decodeValues(rec.Val) {
+			mgr.values.Add(key, uintptr(v))
+		}
+	}
+	mgr.logf(0, "loaded %v interesting value types", len(mgr.values.Keys()))
+}
+
+// addValues adds values to the pool and persists changed types.
+// Must be called with mgr.mu held.
+func (mgr *Manager) addValues(values map[string][]uint64) {
+	for key, vals := range values {
+		changed := false
+		for _, v := range vals {
+			if mgr.values.Add(key, uintptr(v)) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		var all []uint64
+		for _, v := range mgr.values.Values(key) {
+			all = append(all, uint64(v))
+		}
+		if err := mgr.valuesDB.Save(key, db.Record{Val: encodeValues(all)}); err != nil {
+			mgr.logf(0, "failed to save values: %v", err)
+		}
+	}
+	if mgr.valuesDB.Stale() > 10*len(mgr.valuesDB.Records)+100 {
+		if err := mgr.valuesDB.Compact(); err != nil {
+			mgr.logf(0, "failed to compact values database: %v", err)
+		}
+	}
+}
+
+// allValues returns the whole pool in RPC format.
+// Must be called with mgr.mu held.
+func (mgr *Manager) allValues() map[string][]uint64 {
+	res := make(map[string][]uint64)
+	for _, key := range mgr.values.Keys() {
+		for _, v := range mgr.values.Values(key) {
+			res[key] = append(res[key], uint64(v))
+		}
+	}
+	return res
+}
+
+func encodeValues(vals []uint64) []byte {
+	buf := make([]byte, 8*len(vals))
+	for i, v := range vals {
+		binary.LittleEndian.PutUint64(buf[i*8:], v)
+	}
+	return buf
+}
+
+func decodeValues(data []byte) []uint64 {
+	var vals []uint64
+	for ; len(data) >= 8; data = data[8:] {
+		vals = append(vals, binary.LittleEndian.Uint64(data))
+	}
+	return vals
+}
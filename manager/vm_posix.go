@@ -0,0 +1,12 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// +build !windows
+
+package manager
+
+// VM backends that run the kernel as host processes and don't support Windows hosts.
+import (
+	_ "github.com/google/syzkaller/vm/local"
+	_ "github.com/google/syzkaller/vm/uml"
+)
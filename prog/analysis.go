@@ -52,7 +52,8 @@ func (s *state) analyze(c *Call) {
 	foreachArgArray(&c.Args, c.Ret, func(arg, base *Arg, _ *[]*Arg) {
 		switch typ := arg.Type.(type) {
 		case sys.FilenameType:
-			if arg.Kind == ArgData && arg.Dir != DirOut {
+			// Pseudo files must not be used as names of regular files (unlink, rename, mount, etc).
+			if arg.Kind == ArgData && arg.Dir != DirOut && typ.Kind != sys.FilenamePseudofs {
 				s.files[string(arg.Data)] = true
 			}
 		case sys.ResourceType:
@@ -289,3 +290,37 @@ func sanitizeCall(c *Call) {
 		}
 	}
 }
+
+// ResourceCalls returns indices of calls that create resources used by subsequent calls
+// (either as the return value or via output arguments). If such a call fails,
+// subsequent calls most likely fail as well.
+func (p *Prog) ResourceCalls() []int {
+	var res []int
+	for i, c := range p.Calls {
+		if producesUsedResources(c) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// producedResources returns resources created by c (the return value and output arguments).
+func producedResources(c *Call) []*Arg {
+	var res []*Arg
+	foreachArgArray(&c.Args, c.Ret, func(arg, _ *Arg, _ *[]*Arg) {
+		if _, ok := arg.Type.(sys.ResourceType); ok && arg.Dir != DirIn {
+			res = append(res, arg)
+		}
+	})
+	return res
+}
+
+// producesUsedResources returns true if c creates resources used by other calls.
+func producesUsedResources(c *Call) bool {
+	for _, arg := range producedResources(c) {
+		if len(arg.Uses) != 0 {
+			return true
+		}
+	}
+	return false
+}
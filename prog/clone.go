@@ -3,6 +3,7 @@
 
 package prog
 
+// Clone returns a deep copy of p.
 func (p *Prog) Clone() *Prog {
 	p1 := new(Prog)
 	newargs := make(map[*Arg]*Arg)
@@ -15,7 +16,7 @@ func (p *Prog) Clone() *Prog {
 		}
 		p1.Calls = append(p1.Calls, c1)
 	}
-	if err := p1.validate(); err != nil {
+	if err := p1.Validate(); err != nil {
 		panic(err)
 	}
 	return p1
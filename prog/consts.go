@@ -1,1739 +1,2021 @@
 // AUTOGENERATED FILE
 package prog
 
+import "github.com/google/syzkaller/sys"
+
 const (
-	ADDR_COMPAT_LAYOUT                       = 2097152
-	ADDR_LIMIT_32BIT                         = 8388608
-	ADDR_LIMIT_3GB                           = 134217728
-	ADDR_NO_RANDOMIZE                        = 262144
-	AF_ALG                                   = 38
-	AF_APPLETALK                             = 5
-	AF_ATMPVC                                = 8
-	AF_AX25                                  = 3
-	AF_BLUETOOTH                             = 31
-	AF_INET                                  = 2
-	AF_INET6                                 = 10
-	AF_IPX                                   = 4
-	AF_KCM                                   = 41
-	AF_NETLINK                               = 16
-	AF_NETROM                                = 6
-	AF_NFC                                   = 39
-	AF_PACKET                                = 17
-	AF_UNIX                                  = 1
-	AF_UNSPEC                                = 0
-	AF_X25                                   = 9
-	ALG_SET_AEAD_ASSOCLEN                    = 4
-	ALG_SET_AEAD_AUTHSIZE                    = 5
-	ALG_SET_IV                               = 2
-	ALG_SET_KEY                              = 1
-	ALG_SET_OP                               = 3
-	ARCH_GET_FS                              = 4099
-	ARCH_GET_GS                              = 4100
-	ARCH_SET_FS                              = 4098
-	ARCH_SET_GS                              = 4097
-	AT_EMPTY_PATH                            = 4096
-	AT_REMOVEDIR                             = 512
-	AT_SYMLINK_FOLLOW                        = 1024
-	AT_SYMLINK_NOFOLLOW                      = 256
-	AX25_MAX_DIGIS                           = 8
-	BNEPCONNADD                              = 1074021064
-	BNEPCONNDEL                              = 1074021065
-	BNEPGETCONNINFO                          = 2147762899
-	BNEPGETCONNLIST                          = 2147762898
-	BNEPGETSUPPFEAT                          = 2147762900
-	BPF_ANY                                  = 0
-	BPF_EXIST                                = 2
-	BPF_MAP_CREATE                           = 0
-	BPF_MAP_DELETE_ELEM                      = 3
-	BPF_MAP_GET_NEXT_KEY                     = 4
-	BPF_MAP_LOOKUP_ELEM                      = 1
-	BPF_MAP_TYPE_ARRAY                       = 2
-	BPF_MAP_TYPE_HASH                        = 1
-	BPF_MAP_TYPE_PERF_EVENT_ARRAY            = 4
-	BPF_MAP_TYPE_PROG_ARRAY                  = 3
-	BPF_MAP_UPDATE_ELEM                      = 2
-	BPF_NOEXIST                              = 1
-	BPF_OBJ_GET                              = 7
-	BPF_OBJ_PIN                              = 6
-	BPF_PROG_LOAD                            = 5
-	BPF_PROG_TYPE_KPROBE                     = 2
-	BPF_PROG_TYPE_SCHED_ACT                  = 4
-	BPF_PROG_TYPE_SCHED_CLS                  = 3
-	BPF_PROG_TYPE_SOCKET_FILTER              = 1
-	BTPROTO_BNEP                             = 4
-	BTPROTO_CMTP                             = 5
-	BTPROTO_HCI                              = 1
-	BTPROTO_HIDP                             = 6
-	BTPROTO_L2CAP                            = 0
-	BTPROTO_RFCOMM                           = 3
-	BTPROTO_SCO                              = 2
-	BT_CHANNEL_POLICY                        = 10
-	BT_DEFER_SETUP                           = 7
-	BT_FLUSHABLE                             = 8
-	BT_POWER                                 = 9
-	BT_RCVMTU                                = 13
-	BT_SECURITY                              = 4
-	BT_SNDMTU                                = 12
-	BT_VOICE                                 = 11
-	CLOCK_BOOTTIME                           = 7
-	CLOCK_MONOTONIC                          = 1
-	CLOCK_MONOTONIC_COARSE                   = 6
-	CLOCK_MONOTONIC_RAW                      = 4
-	CLOCK_PROCESS_CPUTIME_ID                 = 2
-	CLOCK_REALTIME                           = 0
-	CLOCK_REALTIME_COARSE                    = 5
-	CLOCK_THREAD_CPUTIME_ID                  = 3
-	CLONE_CHILD_CLEARTID                     = 2097152
-	CLONE_CHILD_SETTID                       = 16777216
-	CLONE_FILES                              = 1024
-	CLONE_FS                                 = 512
-	CLONE_IO                                 = 2147483648
-	CLONE_NEWIPC                             = 134217728
-	CLONE_NEWNET                             = 1073741824
-	CLONE_NEWNS                              = 131072
-	CLONE_NEWPID                             = 536870912
-	CLONE_NEWUTS                             = 67108864
-	CLONE_PARENT                             = 32768
-	CLONE_PARENT_SETTID                      = 1048576
-	CLONE_PTRACE                             = 8192
-	CLONE_SETTLS                             = 524288
-	CLONE_SIGHAND                            = 2048
-	CLONE_SYSVSEM                            = 262144
-	CLONE_THREAD                             = 65536
-	CLONE_UNTRACED                           = 8388608
-	CLONE_VFORK                              = 16384
-	CLONE_VM                                 = 256
-	CMTPCONNADD                              = 1074021320
-	CMTPCONNDEL                              = 1074021321
-	CMTPGETCONNINFO                          = 2147763155
-	CMTPGETCONNLIST                          = 2147763154
-	CRYPTO_ALG_ASYNC                         = 128
-	CRYPTO_ALG_DEAD                          = 32
-	CRYPTO_ALG_DYING                         = 64
-	CRYPTO_ALG_GENIV                         = 512
-	CRYPTO_ALG_INSTANCE                      = 2048
-	CRYPTO_ALG_INTERNAL                      = 8192
-	CRYPTO_ALG_KERN_DRIVER_ONLY              = 4096
-	CRYPTO_ALG_LARVAL                        = 16
-	CRYPTO_ALG_NEED_FALLBACK                 = 256
-	CRYPTO_ALG_TESTED                        = 1024
-	CRYPTO_ALG_TYPE_ABLKCIPHER               = 5
-	CRYPTO_ALG_TYPE_AEAD                     = 3
-	CRYPTO_ALG_TYPE_AHASH                    = 10
-	CRYPTO_ALG_TYPE_AKCIPHER                 = 13
-	CRYPTO_ALG_TYPE_BLKCIPHER                = 4
-	CRYPTO_ALG_TYPE_CIPHER                   = 1
-	CRYPTO_ALG_TYPE_COMPRESS                 = 2
-	CRYPTO_ALG_TYPE_DIGEST                   = 8
-	CRYPTO_ALG_TYPE_GIVCIPHER                = 6
-	CRYPTO_ALG_TYPE_HASH                     = 8
-	CRYPTO_ALG_TYPE_MASK                     = 15
-	CRYPTO_ALG_TYPE_PCOMPRESS                = 15
-	CRYPTO_ALG_TYPE_RNG                      = 12
-	CRYPTO_ALG_TYPE_SHASH                    = 9
-	DN_ACCESS                                = 1
-	DN_ATTRIB                                = 32
-	DN_CREATE                                = 4
-	DN_DELETE                                = 8
-	DN_MODIFY                                = 2
-	DN_MULTISHOT                             = 2147483648
-	DN_RENAME                                = 16
-	DRM_ADD_COMMAND                          = 0
-	DRM_DISPLAY_MODE_LEN                     = 32
-	DRM_INST_HANDLER                         = 2
-	DRM_IOCTL_ADD_BUFS                       = 3223348246
-	DRM_IOCTL_ADD_CTX                        = 3221775392
-	DRM_IOCTL_ADD_MAP                        = 3223872533
-	DRM_IOCTL_AGP_ACQUIRE                    = 25648
-	DRM_IOCTL_AGP_ALLOC                      = 3223348276
-	DRM_IOCTL_AGP_BIND                       = 1074816054
-	DRM_IOCTL_AGP_ENABLE                     = 1074291762
-	DRM_IOCTL_AGP_FREE                       = 1075864629
-	DRM_IOCTL_AGP_INFO                       = 2151179315
-	DRM_IOCTL_AGP_RELEASE                    = 25649
-	DRM_IOCTL_AGP_UNBIND                     = 1074816055
-	DRM_IOCTL_AUTH_MAGIC                     = 1074029585
-	DRM_IOCTL_CONTROL                        = 1074291732
-	DRM_IOCTL_DMA                            = 3225445417
-	DRM_IOCTL_DROP_MASTER                    = 25631
-	DRM_IOCTL_FREE_BUFS                      = 1074816026
-	DRM_IOCTL_GEM_CLOSE                      = 1074291721
-	DRM_IOCTL_GEM_FLINK                      = 3221775370
-	DRM_IOCTL_GEM_OPEN                       = 3222299659
-	DRM_IOCTL_GET_CAP                        = 3222299660
-	DRM_IOCTL_GET_CLIENT                     = 3223872517
-	DRM_IOCTL_GET_CTX                        = 3221775395
-	DRM_IOCTL_GET_MAGIC                      = 2147771394
-	DRM_IOCTL_GET_MAP                        = 3223872516
-	DRM_IOCTL_GET_SAREA_CTX                  = 3222299677
-	DRM_IOCTL_GET_STATS                      = 2163762182
-	DRM_IOCTL_GET_UNIQUE                     = 3222299649
-	DRM_IOCTL_INFO_BUFS                      = 3222299672
-	DRM_IOCTL_IRQ_BUSID                      = 3222299651
-	DRM_IOCTL_LOCK                           = 1074291754
-	DRM_IOCTL_MAP_BUFS                       = 3222823961
-	DRM_IOCTL_MARK_BUFS                      = 1075864599
-	DRM_IOCTL_MODESET_CTL                    = 1074291720
-	DRM_IOCTL_MODE_GETCRTC                   = 3228066977
-	DRM_IOCTL_MODE_GETPLANERESOURCES         = 3222299829
-	DRM_IOCTL_MODE_GETRESOURCES              = 3225445536
-	DRM_IOCTL_MODE_SETCRTC                   = 3228066978
-	DRM_IOCTL_NEW_CTX                        = 1074291749
-	DRM_IOCTL_PRIME_FD_TO_HANDLE             = 3222037550
-	DRM_IOCTL_PRIME_HANDLE_TO_FD             = 3222037549
-	DRM_IOCTL_RES_CTX                        = 3222299686
-	DRM_IOCTL_RM_CTX                         = 3221775393
-	DRM_IOCTL_RM_MAP                         = 1076388891
-	DRM_IOCTL_SET_CLIENT_CAP                 = 1074816013
-	DRM_IOCTL_SET_MASTER                     = 25630
-	DRM_IOCTL_SET_SAREA_CTX                  = 1074816028
-	DRM_IOCTL_SET_UNIQUE                     = 1074816016
-	DRM_IOCTL_SET_VERSION                    = 3222299655
-	DRM_IOCTL_SG_ALLOC                       = 3222299704
-	DRM_IOCTL_SG_FREE                        = 1074816057
-	DRM_IOCTL_SWITCH_CTX                     = 1074291748
-	DRM_IOCTL_UNLOCK                         = 1074291755
-	DRM_IOCTL_VERSION                        = 3225445376
-	DRM_IOCTL_WAIT_VBLANK                    = 3222823994
-	DRM_RM_COMMAND                           = 1
-	DRM_UNINST_HANDLER                       = 3
-	EFD_CLOEXEC                              = 524288
-	EFD_NONBLOCK                             = 2048
-	EFD_SEMAPHORE                            = 1
-	EPOLLET                                  = 18446744071562067968
-	EPOLLONESHOT                             = 1073741824
-	EPOLL_CLOEXEC                            = 524288
-	EPOLL_CTL_ADD                            = 1
-	EPOLL_CTL_DEL                            = 2
-	EPOLL_CTL_MOD                            = 3
-	EVIOCGABS0                               = 2149074240
-	EVIOCGABS20                              = 2149074272
-	EVIOCGABS2F                              = 2149074287
-	EVIOCGABS3F                              = 2149074303
-	EVIOCGBITKEY64                           = 2151695649
-	EVIOCGBITSND64                           = 2151695666
-	EVIOCGBITSW64                            = 2151695653
-	EVIOCGEFFECTS                            = 2147763588
-	EVIOCGID                                 = 2148025602
-	EVIOCGKEY64                              = 2151695640
-	EVIOCGKEYCODE                            = 2148025604
-	EVIOCGKEYCODE_V2                         = 2150122756
-	EVIOCGLED64                              = 2151695641
-	EVIOCGMASK                               = 2148550034
-	EVIOCGMTSLOTS64                          = 2151695626
-	EVIOCGNAME64                             = 2151695622
-	EVIOCGPHYS64                             = 2151695623
-	EVIOCGPROP64                             = 2151695625
-	EVIOCGRAB                                = 1074021776
-	EVIOCGREP                                = 2148025603
-	EVIOCGSND64                              = 2151695642
-	EVIOCGSW64                               = 2151695643
-	EVIOCGUNIQ64                             = 2151695624
-	EVIOCGVERSION                            = 2147763457
-	EVIOCREVOKE                              = 1074021777
-	EVIOCRMFF                                = 1074021761
-	EVIOCSABS0                               = 1075332544
-	EVIOCSABS20                              = 1075332576
-	EVIOCSABS2F                              = 1075332591
-	EVIOCSABS3F                              = 1075332607
-	EVIOCSCLOCKID                            = 1074021792
-	EVIOCSFF                                 = 1076905344
-	EVIOCSKEYCODE                            = 1074283780
-	EVIOCSKEYCODE_V2                         = 1076380932
-	EVIOCSMASK                               = 1074808211
-	EVIOCSREP                                = 1074283779
-	EV_ABS                                   = 3
-	EV_FF                                    = 21
-	EV_KEY                                   = 1
-	EV_LED                                   = 17
-	EV_MSC                                   = 4
-	EV_REL                                   = 2
-	EV_SND                                   = 18
-	EV_SW                                    = 5
-	EV_SYN                                   = 0
-	FALLOC_FL_KEEP_SIZE                      = 1
-	FALLOC_FL_PUNCH_HOLE                     = 2
-	FAN_ACCESS                               = 1
-	FAN_ACCESS_PERM                          = 131072
-	FAN_CLASS_CONTENT                        = 4
-	FAN_CLASS_NOTIF                          = 0
-	FAN_CLASS_PRE_CONTENT                    = 8
-	FAN_CLOEXEC                              = 1
-	FAN_CLOSE_NOWRITE                        = 16
-	FAN_CLOSE_WRITE                          = 8
-	FAN_EVENT_ON_CHILD                       = 134217728
-	FAN_MARK_ADD                             = 1
-	FAN_MARK_DONT_FOLLOW                     = 4
-	FAN_MARK_FLUSH                           = 128
-	FAN_MARK_IGNORED_MASK                    = 32
-	FAN_MARK_IGNORED_SURV_MODIFY             = 64
-	FAN_MARK_MOUNT                           = 16
-	FAN_MARK_ONLYDIR                         = 8
-	FAN_MARK_REMOVE                          = 2
-	FAN_MODIFY                               = 2
-	FAN_NONBLOCK                             = 2
-	FAN_ONDIR                                = 1073741824
-	FAN_OPEN                                 = 32
-	FAN_OPEN_PERM                            = 65536
-	FAN_UNLIMITED_MARKS                      = 32
-	FAN_UNLIMITED_QUEUE                      = 16
-	FASYNC                                   = 8192
-	FD_CLOEXEC                               = 1
-	FF_CONSTANT                              = 82
-	FF_CUSTOM                                = 93
-	FF_DAMPER                                = 85
-	FF_FRICTION                              = 84
-	FF_INERTIA                               = 86
-	FF_PERIODIC                              = 81
-	FF_RAMP                                  = 87
-	FF_SAW_DOWN                              = 92
-	FF_SAW_UP                                = 91
-	FF_SINE                                  = 90
-	FF_SPRING                                = 83
-	FF_SQUARE                                = 88
-	FF_TRIANGLE                              = 89
-	FIEMAP_EXTENT_DATA_ENCRYPTED             = 128
-	FIEMAP_EXTENT_DATA_INLINE                = 512
-	FIEMAP_EXTENT_DATA_TAIL                  = 1024
-	FIEMAP_EXTENT_DELALLOC                   = 4
-	FIEMAP_EXTENT_ENCODED                    = 8
-	FIEMAP_EXTENT_LAST                       = 1
-	FIEMAP_EXTENT_MERGED                     = 4096
-	FIEMAP_EXTENT_NOT_ALIGNED                = 256
-	FIEMAP_EXTENT_SHARED                     = 8192
-	FIEMAP_EXTENT_UNKNOWN                    = 2
-	FIEMAP_EXTENT_UNWRITTEN                  = 2048
-	FIEMAP_FLAG_CACHE                        = 4
-	FIEMAP_FLAG_SYNC                         = 1
-	FIEMAP_FLAG_XATTR                        = 2
-	FIFREEZE                                 = 3221510263
-	FIGETBSZ                                 = 2
-	FIOASYNC                                 = 21586
-	FIOCLEX                                  = 21585
-	FIONBIO                                  = 21537
-	FIONCLEX                                 = 21584
-	FIONREAD                                 = 21531
-	FIOQSIZE                                 = 21600
-	FITHAW                                   = 3221510264
-	FS_IOC_FIEMAP                            = 3223348747
-	FUSE_DEV_IOC_CLONE                       = 2147804416
-	FUTEX_CMP_REQUEUE                        = 4
-	FUTEX_REQUEUE                            = 3
-	FUTEX_WAIT                               = 0
-	FUTEX_WAIT_BITSET                        = 9
-	FUTEX_WAKE                               = 1
-	F_ADD_SEALS                              = 1033
-	F_DUPFD                                  = 0
-	F_DUPFD_CLOEXEC                          = 1030
-	F_GETFD                                  = 1
-	F_GETFL                                  = 3
-	F_GETLEASE                               = 1025
-	F_GETLK                                  = 5
-	F_GETOWN                                 = 9
-	F_GETOWN_EX                              = 16
-	F_GETPIPE_SZ                             = 1032
-	F_GETSIG                                 = 11
-	F_GET_SEALS                              = 1034
-	F_OWNER_PGRP                             = 2
-	F_OWNER_PID                              = 1
-	F_OWNER_TID                              = 0
-	F_RDLCK                                  = 0
-	F_SEAL_GROW                              = 4
-	F_SEAL_SEAL                              = 1
-	F_SEAL_SHRINK                            = 2
-	F_SEAL_WRITE                             = 8
-	F_SETFD                                  = 2
-	F_SETFL                                  = 4
-	F_SETLEASE                               = 1024
-	F_SETLK                                  = 6
-	F_SETLKW                                 = 7
-	F_SETOWN                                 = 8
-	F_SETOWN_EX                              = 15
-	F_SETPIPE_SZ                             = 1031
-	F_SETSIG                                 = 10
-	F_UNLCK                                  = 2
-	F_WRLCK                                  = 1
-	GETALL                                   = 13
-	GETNCNT                                  = 14
-	GETPID                                   = 11
-	GETVAL                                   = 12
-	GETZCNT                                  = 15
-	GIO_CMAP                                 = 19312
-	GIO_FONT                                 = 19296
-	GIO_FONTX                                = 19307
-	GIO_SCRNMAP                              = 19264
-	GIO_UNIMAP                               = 19302
-	GIO_UNISCRNMAP                           = 19305
-	GRND_NONBLOCK                            = 1
-	GRND_RANDOM                              = 2
-	HCIBLOCKADDR                             = 1074022630
-	HCIDEVDOWN                               = 1074022602
-	HCIDEVRESET                              = 1074022603
-	HCIDEVRESTAT                             = 1074022604
-	HCIDEVUP                                 = 1074022601
-	HCIGETAUTHINFO                           = 2147764439
-	HCIGETCONNINFO                           = 2147764437
-	HCIGETCONNLIST                           = 2147764436
-	HCIGETDEVINFO                            = 2147764435
-	HCIGETDEVLIST                            = 2147764434
-	HCIINQUIRY                               = 2147764464
-	HCISETACLMTU                             = 1074022627
-	HCISETAUTH                               = 1074022622
-	HCISETENCRYPT                            = 1074022623
-	HCISETLINKMODE                           = 1074022626
-	HCISETLINKPOL                            = 1074022625
-	HCISETPTYPE                              = 1074022624
-	HCISETRAW                                = 1074022620
-	HCISETSCAN                               = 1074022621
-	HCISETSCOMTU                             = 1074022628
-	HCIUNBLOCKADDR                           = 1074022631
-	HCI_CHANNEL_CONTROL                      = 3
-	HCI_CHANNEL_MONITOR                      = 2
-	HCI_CHANNEL_RAW                          = 0
-	HCI_CHANNEL_USER                         = 1
-	HCI_DATA_DIR                             = 1
-	HCI_FILTER                               = 2
-	HCI_TIME_STAMP                           = 3
-	HIDPCONNADD                              = 1074022600
-	HIDPCONNDEL                              = 1074022601
-	HIDPGETCONNINFO                          = 2147764435
-	HIDPGETCONNLIST                          = 2147764434
-	HW_BREAKPOINT_EMPTY                      = 0
-	HW_BREAKPOINT_R                          = 1
-	HW_BREAKPOINT_W                          = 2
-	HW_BREAKPOINT_X                          = 4
-	IFF_ATTACH_QUEUE                         = 512
-	IFF_DETACH_QUEUE                         = 1024
-	IFF_MULTI_QUEUE                          = 256
-	IFF_NOFILTER                             = 4096
-	IFF_NO_PI                                = 4096
-	IFF_ONE_QUEUE                            = 8192
-	IFF_PERSIST                              = 2048
-	IFF_TAP                                  = 2
-	IFF_TUN                                  = 1
-	IFF_TUN_EXCL                             = 32768
-	IFF_VNET_HDR                             = 16384
-	IN_ACCESS                                = 1
-	IN_ATTRIB                                = 4
-	IN_CLOEXEC                               = 524288
-	IN_CLOSE_NOWRITE                         = 16
-	IN_CLOSE_WRITE                           = 8
-	IN_CREATE                                = 256
-	IN_DELETE                                = 512
-	IN_DELETE_SELF                           = 1024
-	IN_DONT_FOLLOW                           = 33554432
-	IN_EXCL_UNLINK                           = 67108864
-	IN_MASK_ADD                              = 536870912
-	IN_MODIFY                                = 2
-	IN_MOVED_FROM                            = 64
-	IN_MOVED_TO                              = 128
-	IN_MOVE_SELF                             = 2048
-	IN_NONBLOCK                              = 2048
-	IN_ONESHOT                               = 2147483648
-	IN_ONLYDIR                               = 16777216
-	IN_OPEN                                  = 32
-	IOCB_CMD_FDSYNC                          = 3
-	IOCB_CMD_FSYNC                           = 2
-	IOCB_CMD_NOOP                            = 6
-	IOCB_CMD_PREAD                           = 0
-	IOCB_CMD_PREADV                          = 7
-	IOCB_CMD_PWRITE                          = 1
-	IOCB_CMD_PWRITEV                         = 8
-	IOCB_FLAG_RESFD                          = 1
-	IOPRIO_WHO_PGRP                          = 2
-	IOPRIO_WHO_PROCESS                       = 1
-	IOPRIO_WHO_USER                          = 3
-	IPC_CREAT                                = 512
-	IPC_EXCL                                 = 1024
-	IPC_INFO                                 = 3
-	IPC_NOWAIT                               = 2048
-	IPC_RMID                                 = 0
-	IPC_SET                                  = 1
-	IPC_STAT                                 = 2
-	IPPROTO_IP                               = 0
-	IPPROTO_IPV6                             = 41
-	IPPROTO_SCTP                             = 132
-	IPPROTO_TCP                              = 6
-	IPPROTO_UDP                              = 17
-	IPV6_2292DSTOPTS                         = 4
-	IPV6_2292HOPLIMIT                        = 8
-	IPV6_2292HOPOPTS                         = 3
-	IPV6_2292PKTINFO                         = 2
-	IPV6_2292PKTOPTIONS                      = 6
-	IPV6_2292RTHDR                           = 5
-	IPV6_ADDRFORM                            = 1
-	IPV6_ADD_MEMBERSHIP                      = 20
-	IPV6_AUTHHDR                             = 10
-	IPV6_CHECKSUM                            = 7
-	IPV6_DROP_MEMBERSHIP                     = 21
-	IPV6_DSTOPTS                             = 59
-	IPV6_FLOWINFO                            = 11
-	IPV6_HOPLIMIT                            = 52
-	IPV6_HOPOPTS                             = 54
-	IPV6_JOIN_ANYCAST                        = 27
-	IPV6_LEAVE_ANYCAST                       = 28
-	IPV6_MTU                                 = 24
-	IPV6_MTU_DISCOVER                        = 23
-	IPV6_MULTICAST_HOPS                      = 18
-	IPV6_MULTICAST_IF                        = 17
-	IPV6_MULTICAST_LOOP                      = 19
-	IPV6_RECVERR                             = 25
-	IPV6_RECVPKTINFO                         = 49
-	IPV6_ROUTER_ALERT                        = 22
-	IPV6_RTHDR                               = 57
-	IPV6_UNICAST_HOPS                        = 16
-	IPV6_V6ONLY                              = 26
-	IP_ADD_MEMBERSHIP                        = 35
-	IP_ADD_SOURCE_MEMBERSHIP                 = 39
-	IP_BIND_ADDRESS_NO_PORT                  = 24
-	IP_BLOCK_SOURCE                          = 38
-	IP_CHECKSUM                              = 23
-	IP_DROP_MEMBERSHIP                       = 36
-	IP_DROP_SOURCE_MEMBERSHIP                = 40
-	IP_FREEBIND                              = 15
-	IP_HDRINCL                               = 3
-	IP_IPSEC_POLICY                          = 16
-	IP_MINTTL                                = 21
-	IP_MSFILTER                              = 41
-	IP_MTU                                   = 14
-	IP_MTU_DISCOVER                          = 10
-	IP_MULTICAST_ALL                         = 49
-	IP_MULTICAST_IF                          = 32
-	IP_MULTICAST_LOOP                        = 34
-	IP_MULTICAST_TTL                         = 33
-	IP_NODEFRAG                              = 22
-	IP_OPTIONS                               = 4
-	IP_PASSSEC                               = 18
-	IP_PKTINFO                               = 8
-	IP_PKTOPTIONS                            = 9
-	IP_PMTUDISC_DO                           = 2
-	IP_PMTUDISC_DONT                         = 0
-	IP_PMTUDISC_INTERFACE                    = 4
-	IP_PMTUDISC_OMIT                         = 5
-	IP_PMTUDISC_PROBE                        = 3
-	IP_PMTUDISC_WANT                         = 1
-	IP_RECVERR                               = 11
-	IP_RECVOPTS                              = 6
-	IP_RECVORIGDSTADDR                       = 20
-	IP_RECVTOS                               = 13
-	IP_RECVTTL                               = 12
-	IP_RETOPTS                               = 7
-	IP_ROUTER_ALERT                          = 5
-	IP_TOS                                   = 1
-	IP_TRANSPARENT                           = 19
-	IP_TTL                                   = 2
-	IP_UNBLOCK_SOURCE                        = 37
-	ITIMER_PROF                              = 2
-	ITIMER_REAL                              = 0
-	ITIMER_VIRTUAL                           = 1
-	KCMPROTO_CONNECTED                       = 0
-	KCMP_FILE                                = 0
-	KCMP_FILES                               = 2
-	KCMP_FS                                  = 3
-	KCMP_IO                                  = 5
-	KCMP_SIGHAND                             = 4
-	KCMP_SYSVSEM                             = 6
-	KCMP_VM                                  = 1
-	KCM_RECV_DISABLE                         = 1
-	KDADDIO                                  = 19252
-	KDBUS_ATTACH_ANY                         = 18446744073709551615
-	KDBUS_ATTACH_AUDIT                       = 4096
-	KDBUS_ATTACH_AUXGROUPS                   = 8
-	KDBUS_ATTACH_CAPS                        = 1024
-	KDBUS_ATTACH_CGROUP                      = 512
-	KDBUS_ATTACH_CMDLINE                     = 256
-	KDBUS_ATTACH_CONN_DESCRIPTION            = 8192
-	KDBUS_ATTACH_CREDS                       = 2
-	KDBUS_ATTACH_EXE                         = 128
-	KDBUS_ATTACH_NAMES                       = 16
-	KDBUS_ATTACH_PIDS                        = 4
-	KDBUS_ATTACH_PID_COMM                    = 64
-	KDBUS_ATTACH_SECLABEL                    = 2048
-	KDBUS_ATTACH_TID_COMM                    = 32
-	KDBUS_ATTACH_TIMESTAMP                   = 1
-	KDBUS_CMD_BUS_CREATOR_INFO               = 2147784069
-	KDBUS_CMD_BUS_MAKE                       = 1074042112
-	KDBUS_CMD_BYEBYE                         = 1074042242
-	KDBUS_CMD_CONN_INFO                      = 2147784068
-	KDBUS_CMD_ENDPOINT_MAKE                  = 1074042128
-	KDBUS_CMD_ENDPOINT_UPDATE                = 1074042129
-	KDBUS_CMD_FREE                           = 1074042243
-	KDBUS_CMD_HELLO                          = 3221525888
-	KDBUS_CMD_LIST                           = 2147784070
-	KDBUS_CMD_MATCH_ADD                      = 1074042288
-	KDBUS_CMD_MATCH_REMOVE                   = 1074042289
-	KDBUS_CMD_NAME_ACQUIRE                   = 1074042272
-	KDBUS_CMD_NAME_RELEASE                   = 1074042273
-	KDBUS_CMD_RECV                           = 2147784081
-	KDBUS_CMD_SEND                           = 1074042256
-	KDBUS_CMD_UPDATE                         = 1074042241
-	KDBUS_HELLO_ACCEPT_FD                    = 1
-	KDBUS_HELLO_ACTIVATOR                    = 2
-	KDBUS_HELLO_MONITOR                      = 8
-	KDBUS_HELLO_POLICY_HOLDER                = 4
-	KDBUS_IOCTL_MAGIC                        = 149
-	KDBUS_ITEM_ATTACH_FLAGS_RECV             = 13
-	KDBUS_ITEM_ATTACH_FLAGS_SEND             = 12
-	KDBUS_ITEM_AUDIT                         = 4108
-	KDBUS_ITEM_AUXGROUPS                     = 4099
-	KDBUS_ITEM_BLOOM_FILTER                  = 8
-	KDBUS_ITEM_BLOOM_MASK                    = 9
-	KDBUS_ITEM_BLOOM_PARAMETER               = 7
-	KDBUS_ITEM_CANCEL_FD                     = 6
-	KDBUS_ITEM_CAPS                          = 4106
-	KDBUS_ITEM_CGROUP                        = 4105
-	KDBUS_ITEM_CMDLINE                       = 4104
-	KDBUS_ITEM_CONN_DESCRIPTION              = 4109
-	KDBUS_ITEM_CREDS                         = 4097
-	KDBUS_ITEM_DST_ID                        = 16
-	KDBUS_ITEM_DST_NAME                      = 10
-	KDBUS_ITEM_EXE                           = 4103
-	KDBUS_ITEM_FDS                           = 5
-	KDBUS_ITEM_ID                            = 14
-	KDBUS_ITEM_ID_ADD                        = 32771
-	KDBUS_ITEM_ID_REMOVE                     = 32772
-	KDBUS_ITEM_MAKE_NAME                     = 11
-	KDBUS_ITEM_NAME                          = 15
-	KDBUS_ITEM_NAME_ADD                      = 32768
-	KDBUS_ITEM_NAME_CHANGE                   = 32770
-	KDBUS_ITEM_NAME_REMOVE                   = 32769
-	KDBUS_ITEM_NEGOTIATE                     = 1
-	KDBUS_ITEM_OWNED_NAME                    = 4100
-	KDBUS_ITEM_PAYLOAD_MEMFD                 = 4
-	KDBUS_ITEM_PAYLOAD_OFF                   = 3
-	KDBUS_ITEM_PAYLOAD_VEC                   = 2
-	KDBUS_ITEM_PIDS                          = 4098
-	KDBUS_ITEM_PID_COM                       = 4102
-	KDBUS_ITEM_POLICY_ACCESS                 = 8192
-	KDBUS_ITEM_REPLY_DEAD                    = 32774
-	KDBUS_ITEM_REPLY_TIMEOUT                 = 32773
-	KDBUS_ITEM_SECLABEL                      = 4107
-	KDBUS_ITEM_TID_COMM                      = 4101
-	KDBUS_ITEM_TIMESTAMP                     = 4096
-	KDBUS_LIST_ACTIVATORS                    = 4
-	KDBUS_LIST_NAMES                         = 2
-	KDBUS_LIST_QUEUED                        = 8
-	KDBUS_LIST_UNIQUE                        = 1
-	KDBUS_MAKE_ACCESS_GROUP                  = 1
-	KDBUS_MAKE_ACCESS_WORLD                  = 2
-	KDBUS_MATCH_REPLACE                      = 1
-	KDBUS_MSG_EXPECT_REPLY                   = 1
-	KDBUS_MSG_NO_AUTO_START                  = 2
-	KDBUS_MSG_SIGNAL                         = 4
-	KDBUS_NAME_ACQUIRED                      = 64
-	KDBUS_NAME_ACTIVATOR                     = 16
-	KDBUS_NAME_ALLOW_REPLACEMENT             = 2
-	KDBUS_NAME_IN_QUEUE                      = 8
-	KDBUS_NAME_PRIMARY                       = 32
-	KDBUS_NAME_QUEUE                         = 4
-	KDBUS_NAME_REPLACE_EXISTING              = 1
-	KDBUS_POLICY_ACCESS_GROUP                = 2
-	KDBUS_POLICY_ACCESS_NULL                 = 0
-	KDBUS_POLICY_ACCESS_USER                 = 1
-	KDBUS_POLICY_ACCESS_WORLD                = 3
-	KDBUS_POLICY_OWN                         = 2
-	KDBUS_POLICY_SEE                         = 0
-	KDBUS_POLICY_TALK                        = 1
-	KDBUS_RECV_RETURN_DROPPED_MSGS           = 2
-	KDBUS_RECV_RETURN_INCOMPLETE_FDS         = 1
-	KDBUS_SEND_SYNC_REPLY                    = 1
-	KDDELIO                                  = 19253
-	KDDISABIO                                = 19255
-	KDENABIO                                 = 19254
-	KDGETKEYCODE                             = 19276
-	KDGETLED                                 = 19249
-	KDGETMODE                                = 19259
-	KDGKBDIACR                               = 19274
-	KDGKBENT                                 = 19270
-	KDGKBLED                                 = 19300
-	KDGKBMETA                                = 19298
-	KDGKBMODE                                = 19268
-	KDGKBSENT                                = 19272
-	KDGKBTYPE                                = 19251
-	KDSETKEYCODE                             = 19277
-	KDSETLED                                 = 19250
-	KDSETMODE                                = 19258
-	KDSIGACCEPT                              = 19278
-	KDSKBLED                                 = 19301
-	KDSKBMETA                                = 19299
-	KDSKBMODE                                = 19269
-	KDSKBSENT                                = 19273
-	KERNEL_CLIENT                            = 2
-	KEXEC_ARCH_386                           = 196608
-	KEXEC_ARCH_ARM                           = 2621440
-	KEXEC_ARCH_IA_64                         = 3276800
-	KEXEC_ARCH_MIPS                          = 524288
-	KEXEC_ARCH_MIPS_LE                       = 655360
-	KEXEC_ARCH_PPC                           = 1310720
-	KEXEC_ARCH_PPC64                         = 1376256
-	KEXEC_ARCH_S390                          = 1441792
-	KEXEC_ARCH_SH                            = 2752512
-	KEXEC_ARCH_X86_64                        = 4063232
-	KEXEC_ON_CRASH                           = 1
-	KEXEC_PRESERVE_CONTEXT                   = 2
-	KEYCTL_ASSUME_AUTHORITY                  = 16
-	KEYCTL_CHOWN                             = 4
-	KEYCTL_CLEAR                             = 7
-	KEYCTL_DESCRIBE                          = 6
-	KEYCTL_GET_KEYRING_ID                    = 0
-	KEYCTL_GET_PERSISTENT                    = 22
-	KEYCTL_GET_SECURITY                      = 17
-	KEYCTL_INSTANTIATE                       = 12
-	KEYCTL_INSTANTIATE_IOV                   = 20
-	KEYCTL_INVALIDATE                        = 21
-	KEYCTL_JOIN_SESSION_KEYRING              = 1
-	KEYCTL_LINK                              = 8
-	KEYCTL_NEGATE                            = 13
-	KEYCTL_READ                              = 11
-	KEYCTL_REJECT                            = 19
-	KEYCTL_REVOKE                            = 3
-	KEYCTL_SEARCH                            = 10
-	KEYCTL_SESSION_TO_PARENT                 = 18
-	KEYCTL_SETPERM                           = 5
-	KEYCTL_SET_REQKEY_KEYRING                = 14
-	KEYCTL_SET_TIMEOUT                       = 15
-	KEYCTL_UNLINK                            = 9
-	KEYCTL_UPDATE                            = 2
-	KEY_REQKEY_DEFL_DEFAULT                  = 0
-	KEY_REQKEY_DEFL_GROUP_KEYRING            = 6
-	KEY_REQKEY_DEFL_NO_CHANGE                = 18446744073709551615
-	KEY_REQKEY_DEFL_PROCESS_KEYRING          = 2
-	KEY_REQKEY_DEFL_REQUESTOR_KEYRING        = 7
-	KEY_REQKEY_DEFL_SESSION_KEYRING          = 3
-	KEY_REQKEY_DEFL_THREAD_KEYRING           = 1
-	KEY_REQKEY_DEFL_USER_KEYRING             = 4
-	KEY_REQKEY_DEFL_USER_SESSION_KEYRING     = 5
-	KEY_SPEC_PROCESS_KEYRING                 = 18446744073709551614
-	KEY_SPEC_SESSION_KEYRING                 = 18446744073709551613
-	KEY_SPEC_THREAD_KEYRING                  = 18446744073709551615
-	KEY_SPEC_USER_KEYRING                    = 18446744073709551612
-	KEY_SPEC_USER_SESSION_KEYRING            = 18446744073709551611
-	KIOCSOUND                                = 19247
-	KVM_ASSIGN_DEV_IRQ                       = 1077980784
-	KVM_ASSIGN_PCI_DEVICE                    = 2151722601
-	KVM_ASSIGN_SET_INTX_MASK                 = 1077980836
-	KVM_ASSIGN_SET_MSIX_ENTRY                = 1074835060
-	KVM_ASSIGN_SET_MSIX_NR                   = 1074310771
-	KVM_CHECK_EXTENSION                      = 44547
-	KVM_CREATE_DEVICE                        = 3222056672
-	KVM_CREATE_IRQCHIP                       = 44640
-	KVM_CREATE_PIT2                          = 1077980791
-	KVM_CREATE_VCPU                          = 44609
-	KVM_CREATE_VM                            = 44545
-	KVM_DEASSIGN_DEV_IRQ                     = 1077980789
-	KVM_DEASSIGN_PCI_DEVICE                  = 1077980786
-	KVM_DEV_IRQ_GUEST_INTX                   = 256
-	KVM_DEV_IRQ_GUEST_MSI                    = 512
-	KVM_DEV_IRQ_GUEST_MSIX                   = 1024
-	KVM_DEV_IRQ_HOST_INTX                    = 1
-	KVM_DEV_IRQ_HOST_MSI                     = 2
-	KVM_DEV_IRQ_HOST_MSIX                    = 4
-	KVM_DEV_TYPE_FSL_MPIC_20                 = 1
-	KVM_DEV_TYPE_FSL_MPIC_42                 = 2
-	KVM_DEV_TYPE_VFIO                        = 4
-	KVM_DEV_TYPE_XICS                        = 3
-	KVM_DIRTY_TLB                            = 1074835114
-	KVM_ENABLE_CAP                           = 1080602275
-	KVM_GET_CLOCK                            = 2150674044
-	KVM_GET_DEBUGREGS                        = 2155916961
-	KVM_GET_DEVICE_ATTR                      = 1075359458
-	KVM_GET_DIRTY_LOG                        = 1074835010
-	KVM_GET_EMULATED_CPUID                   = 3221794313
-	KVM_GET_FPU                              = 2174791308
-	KVM_GET_IRQCHIP                          = 3255348834
-	KVM_GET_LAPIC                            = 2214637198
-	KVM_GET_MP_STATE                         = 2147790488
-	KVM_GET_MSRS                             = 3221794440
-	KVM_GET_MSR_INDEX_LIST                   = 3221532162
-	KVM_GET_ONE_REG                          = 1074835115
-	KVM_GET_PIT2                             = 2154868383
-	KVM_GET_REGS                             = 2156965505
-	KVM_GET_REG_LIST                         = 3221794480
-	KVM_GET_SREGS                            = 2167975555
-	KVM_GET_SUPPORTED_CPUID                  = 3221794309
-	KVM_GET_TSC_KHZ                          = 44707
-	KVM_GET_VCPU_EVENTS                      = 2151722655
-	KVM_GET_VCPU_MMAP_SIZE                   = 44548
-	KVM_GET_XCRS                             = 2173218470
-	KVM_GET_XSAVE                            = 2415963812
-	KVM_GUESTDBG_ENABLE                      = 1
-	KVM_GUESTDBG_INJECT_BP                   = 524288
-	KVM_GUESTDBG_INJECT_DB                   = 262144
-	KVM_GUESTDBG_SINGLESTEP                  = 2
-	KVM_GUESTDBG_USE_HW_BP                   = 131072
-	KVM_GUESTDBG_USE_SW_BP                   = 65536
-	KVM_HAS_DEVICE_ATTR                      = 1075359459
-	KVM_INTERRUPT                            = 1074048646
-	KVM_IOEVENTFD                            = 1077980793
-	KVM_IOEVENTFD_FLAG_DATAMATCH             = 1
-	KVM_IOEVENTFD_FLAG_DEASSIGN              = 4
-	KVM_IOEVENTFD_FLAG_PIO                   = 2
-	KVM_IOEVENTFD_FLAG_VIRTIO_CCW_NOTIFY     = 8
-	KVM_IRQFD                                = 1075883638
-	KVM_IRQ_LINE                             = 1074310753
-	KVM_IRQ_ROUTING_IRQCHIP                  = 1
-	KVM_IRQ_ROUTING_MSI                      = 2
-	KVM_KVMCLOCK_CTRL                        = 44717
-	KVM_MEMSLOT_INCOHERENT                   = 131072
-	KVM_MEMSLOT_INVALID                      = 65536
-	KVM_MEM_LOG_DIRTY_PAGES                  = 1
-	KVM_MEM_READONLY                         = 2
-	KVM_MP_STATE_CHECK_STOP                  = 6
-	KVM_MP_STATE_HALTED                      = 3
-	KVM_MP_STATE_INIT_RECEIVED               = 2
-	KVM_MP_STATE_LOAD                        = 8
-	KVM_MP_STATE_OPERATING                   = 7
-	KVM_MP_STATE_RUNNABLE                    = 0
-	KVM_MP_STATE_SIPI_RECEIVED               = 4
-	KVM_MP_STATE_STOPPED                     = 5
-	KVM_MP_STATE_UNINITIALIZED               = 1
-	KVM_NMI                                  = 44698
-	KVM_PPC_ALLOCATE_HTAB                    = 3221532327
-	KVM_PPC_GET_PVINFO                       = 1082175137
-	KVM_PPC_GET_SMMU_INFO                    = 2186325670
-	KVM_RUN                                  = 44672
-	KVM_S390_INTERRUPT                       = 1074835092
-	KVM_S390_UCAS_MAP                        = 1075359312
-	KVM_S390_UCAS_UNMAP                      = 1075359313
-	KVM_S390_VCPU_FAULT                      = 1074310738
-	KVM_SET_BOOT_CPU_ID                      = 44664
-	KVM_SET_CLOCK                            = 1076932219
-	KVM_SET_CPUID                            = 1074310794
-	KVM_SET_DEBUGREGS                        = 1082175138
-	KVM_SET_DEVICE_ATTR                      = 1075359457
-	KVM_SET_FPU                              = 1101049485
-	KVM_SET_GSI_ROUTING                      = 1074310762
-	KVM_SET_GUEST_DEBUG                      = 1078505115
-	KVM_SET_IDENTITY_MAP_ADDR                = 1074310728
-	KVM_SET_IRQCHIP                          = 2181607011
-	KVM_SET_LAPIC                            = 1140895375
-	KVM_SET_MEMORY_REGION                    = 1075359296
-	KVM_SET_MP_STATE                         = 1074048665
-	KVM_SET_MSRS                             = 1074310793
-	KVM_SET_ONE_REG                          = 1074835116
-	KVM_SET_PIT2                             = 1081126560
-	KVM_SET_REGS                             = 1083223682
-	KVM_SET_SIGNAL_MASK                      = 1074048651
-	KVM_SET_SREGS                            = 1094233732
-	KVM_SET_TSC_KHZ                          = 44706
-	KVM_SET_TSS_ADDR                         = 44615
-	KVM_SET_USER_MEMORY_REGION               = 1075883590
-	KVM_SET_VCPU_EVENTS                      = 1077980832
-	KVM_SET_XCRS                             = 1099476647
-	KVM_SET_XSAVE                            = 1342221989
-	KVM_SIGNAL_MSI                           = 1075883685
-	KVM_SMI                                  = 44727
-	KVM_TRANSLATE                            = 3222843013
-	KVM_XEN_HVM_CONFIG                       = 1077456506
-	L2CAP_CONNINFO                           = 2
-	L2CAP_LM                                 = 3
-	L2CAP_LM_AUTH                            = 2
-	L2CAP_LM_ENCRYPT                         = 4
-	L2CAP_LM_FIPS                            = 64
-	L2CAP_LM_MASTER                          = 1
-	L2CAP_LM_RELIABLE                        = 16
-	L2CAP_LM_SECURE                          = 32
-	L2CAP_LM_TRUSTED                         = 8
-	L2CAP_OPTIONS                            = 1
-	LOCK_EX                                  = 2
-	LOCK_NB                                  = 4
-	LOCK_SH                                  = 1
-	LOCK_UN                                  = 8
-	MADV_DODUMP                              = 17
-	MADV_DOFORK                              = 11
-	MADV_DONTDUMP                            = 16
-	MADV_DONTFORK                            = 10
-	MADV_DONTNEED                            = 4
-	MADV_HUGEPAGE                            = 14
-	MADV_HWPOISON                            = 100
-	MADV_MERGEABLE                           = 12
-	MADV_NOHUGEPAGE                          = 15
-	MADV_NORMAL                              = 0
-	MADV_RANDOM                              = 1
-	MADV_REMOVE                              = 9
-	MADV_SEQUENTIAL                          = 2
-	MADV_SOFT_OFFLINE                        = 101
-	MADV_UNMERGEABLE                         = 13
-	MADV_WILLNEED                            = 3
-	MAP_32BIT                                = 64
-	MAP_ANONYMOUS                            = 32
-	MAP_DENYWRITE                            = 2048
-	MAP_EXECUTABLE                           = 4096
-	MAP_FILE                                 = 0
-	MAP_FIXED                                = 16
-	MAP_GROWSDOWN                            = 256
-	MAP_HUGETLB                              = 262144
-	MAP_LOCKED                               = 8192
-	MAP_NONBLOCK                             = 65536
-	MAP_NORESERVE                            = 16384
-	MAP_POPULATE                             = 32768
-	MAP_PRIVATE                              = 2
-	MAP_SHARED                               = 1
-	MAP_STACK                                = 131072
-	MAP_UNINITIALIZED                        = 0
-	MCAST_EXCLUDE                            = 0
-	MCAST_INCLUDE                            = 1
-	MCL_CURRENT                              = 1
-	MCL_FUTURE                               = 2
-	MFD_ALLOW_SEALING                        = 2
-	MFD_CLOEXEC                              = 1
-	MLOCK_ONFAULT                            = 1
-	MMAP_PAGE_ZERO                           = 1048576
-	MNT_DETACH                               = 2
-	MNT_EXPIRE                               = 4
-	MNT_FORCE                                = 1
-	MODULE_INIT_IGNORE_MODVERSIONS           = 1
-	MODULE_INIT_IGNORE_VERMAGIC              = 2
-	MPOL_BIND                                = 2
-	MPOL_DEFAULT                             = 0
-	MPOL_F_ADDR                              = 2
-	MPOL_F_MEMS_ALLOWED                      = 4
-	MPOL_F_NODE                              = 1
-	MPOL_F_RELATIVE_NODES                    = 16384
-	MPOL_F_STATIC_NODES                      = 32768
-	MPOL_INTERLEAVE                          = 3
-	MPOL_MF_MOVE                             = 2
-	MPOL_MF_MOVE_ALL                         = 4
-	MPOL_MF_STRICT                           = 1
-	MPOL_PREFERRED                           = 1
-	MREMAP_FIXED                             = 2
-	MREMAP_MAYMOVE                           = 1
-	MSG_CMSG_CLOEXEC                         = 1073741824
-	MSG_CONFIRM                              = 2048
-	MSG_DONTROUTE                            = 4
-	MSG_DONTWAIT                             = 64
-	MSG_EOR                                  = 128
-	MSG_ERRQUEUE                             = 8192
-	MSG_EXCEPT                               = 8192
-	MSG_INFO                                 = 12
-	MSG_MORE                                 = 32768
-	MSG_NOERROR                              = 4096
-	MSG_NOSIGNAL                             = 16384
-	MSG_OOB                                  = 1
-	MSG_PEEK                                 = 2
-	MSG_STAT                                 = 11
-	MSG_TRUNC                                = 32
-	MSG_WAITALL                              = 256
-	MSG_WAITFORONE                           = 65536
-	MS_ASYNC                                 = 1
-	MS_BIND                                  = 4096
-	MS_DIRSYNC                               = 128
-	MS_INVALIDATE                            = 2
-	MS_MANDLOCK                              = 64
-	MS_MOVE                                  = 8192
-	MS_NOATIME                               = 1024
-	MS_NODEV                                 = 4
-	MS_NODIRATIME                            = 2048
-	MS_NOEXEC                                = 8
-	MS_NOSUID                                = 2
-	MS_RDONLY                                = 1
-	MS_RELATIME                              = 2097152
-	MS_REMOUNT                               = 32
-	MS_SILENT                                = 32768
-	MS_STRICTATIME                           = 16777216
-	MS_SYNC                                  = 4
-	MS_SYNCHRONOUS                           = 16
-	NETLINK_ADD_MEMBERSHIP                   = 1
-	NETLINK_AUDIT                            = 9
-	NETLINK_BROADCAST_ERROR                  = 4
-	NETLINK_CAP_ACK                          = 10
-	NETLINK_CONNECTOR                        = 11
-	NETLINK_CRYPTO                           = 21
-	NETLINK_DNRTMSG                          = 14
-	NETLINK_DROP_MEMBERSHIP                  = 2
-	NETLINK_ECRYPTFS                         = 19
-	NETLINK_FIB_LOOKUP                       = 10
-	NETLINK_FIREWALL                         = 3
-	NETLINK_GENERIC                          = 16
-	NETLINK_INET_DIAG                        = 4
-	NETLINK_IP6_FW                           = 13
-	NETLINK_ISCSI                            = 8
-	NETLINK_KOBJECT_UEVENT                   = 15
-	NETLINK_LISTEN_ALL_NSID                  = 8
-	NETLINK_LIST_MEMBERSHIPS                 = 9
-	NETLINK_NETFILTER                        = 12
-	NETLINK_NFLOG                            = 5
-	NETLINK_NO_ENOBUFS                       = 5
-	NETLINK_PKTINFO                          = 3
-	NETLINK_RDMA                             = 20
-	NETLINK_ROUTE                            = 0
-	NETLINK_RX_RING                          = 6
-	NETLINK_SCSITRANSPORT                    = 18
-	NETLINK_SELINUX                          = 7
-	NETLINK_SOCK_DIAG                        = 4
-	NETLINK_TX_RING                          = 7
-	NETLINK_UNUSED                           = 1
-	NETLINK_USERSOCK                         = 2
-	NETLINK_XFRM                             = 6
-	NETROM_IDLE                              = 7
-	NETROM_N2                                = 3
-	NETROM_T1                                = 1
-	NETROM_T2                                = 2
-	NETROM_T4                                = 6
-	NFC_LLCP_MIUX                            = 1
-	NFC_LLCP_REMOTE_LTO                      = 3
-	NFC_LLCP_REMOTE_MIU                      = 2
-	NFC_LLCP_REMOTE_RW                       = 4
-	NFC_LLCP_RW                              = 0
-	NFC_PROTO_FELICA                         = 3
-	NFC_PROTO_ISO14443                       = 4
-	NFC_PROTO_ISO14443_B                     = 6
-	NFC_PROTO_ISO15693                       = 7
-	NFC_PROTO_JEWEL                          = 1
-	NFC_PROTO_MIFARE                         = 2
-	NFC_PROTO_NFC_DEP                        = 5
-	NFC_SOCKPROTO_LLCP                       = 1
-	NFC_SOCKPROTO_RAW                        = 0
-	NLM_F_ACK                                = 4
-	NLM_F_APPEND                             = 2048
-	NLM_F_ATOMIC                             = 1024
-	NLM_F_CREATE                             = 1024
-	NLM_F_DUMP                               = 768
-	NLM_F_DUMP_FILTERED                      = 32
-	NLM_F_DUMP_INTR                          = 16
-	NLM_F_ECHO                               = 8
-	NLM_F_EXCL                               = 512
-	NLM_F_MATCH                              = 512
-	NLM_F_MULTI                              = 2
-	NLM_F_REPLACE                            = 256
-	NLM_F_REQUEST                            = 1
-	NLM_F_ROOT                               = 256
-	NO_CLIENT                                = 0
-	NT_386_IOPERM                            = 513
-	NT_386_TLS                               = 512
-	NT_AUXV                                  = 6
-	NT_PRFPREG                               = 2
-	NT_PRPSINFO                              = 3
-	NT_PRSTATUS                              = 1
-	NT_TASKSTRUCT                            = 4
-	NT_X86_XSTATE                            = 514
-	O_APPEND                                 = 1024
-	O_CLOEXEC                                = 524288
-	O_CREAT                                  = 64
-	O_DIRECT                                 = 16384
-	O_DIRECTORY                              = 65536
-	O_DSYNC                                  = 4096
-	O_EXCL                                   = 128
-	O_LARGEFILE                              = 32768
-	O_NOATIME                                = 262144
-	O_NOCTTY                                 = 256
-	O_NOFOLLOW                               = 131072
-	O_NONBLOCK                               = 2048
-	O_PATH                                   = 2097152
-	O_RDONLY                                 = 0
-	O_RDWR                                   = 2
-	O_SYNC                                   = 1052672
-	O_TRUNC                                  = 512
-	O_WRONLY                                 = 1
-	PERF_EVENT_IOC_DISABLE                   = 9217
-	PERF_EVENT_IOC_ENABLE                    = 9216
-	PERF_EVENT_IOC_ID                        = 2148017159
-	PERF_EVENT_IOC_PERIOD                    = 1074275332
-	PERF_EVENT_IOC_REFRESH                   = 9218
-	PERF_EVENT_IOC_RESET                     = 9219
-	PERF_EVENT_IOC_SET_BPF                   = 1074013192
-	PERF_EVENT_IOC_SET_FILTER                = 1074275334
-	PERF_EVENT_IOC_SET_OUTPUT                = 9221
-	PERF_FLAG_FD_CLOEXEC                     = 8
-	PERF_FLAG_FD_NO_GROUP                    = 1
-	PERF_FLAG_FD_OUTPUT                      = 2
-	PERF_FLAG_PID_CGROUP                     = 4
-	PERF_TYPE_BREAKPOINT                     = 5
-	PERF_TYPE_HARDWARE                       = 0
-	PERF_TYPE_HW_CACHE                       = 3
-	PERF_TYPE_RAW                            = 4
-	PERF_TYPE_SOFTWARE                       = 1
-	PERF_TYPE_TRACEPOINT                     = 2
-	PER_BSD                                  = 6
-	PER_HPUX                                 = 16
-	PER_IRIX32                               = 67108873
-	PER_IRIX64                               = 67108875
-	PER_IRIXN32                              = 67108874
-	PER_ISCR4                                = 67108869
-	PER_LINUX                                = 0
-	PER_LINUX32                              = 8
-	PER_OSF4                                 = 15
-	PER_OSR5                                 = 100663299
-	PER_RISCOS                               = 12
-	PER_SOLARIS                              = 67108877
-	PER_SVR3                                 = 83886082
-	PER_SVR4                                 = 68157441
-	PER_UW7                                  = 68157454
-	PER_WYSEV386                             = 83886084
-	PER_XENIX                                = 83886087
-	PIO_FONT                                 = 19297
-	PIO_FONTRESET                            = 19309
-	PIO_FONTX                                = 19308
-	PIO_SCRNMAP                              = 19265
-	PIO_UNIMAP                               = 19303
-	PIO_UNIMAPCLR                            = 19304
-	PIO_UNISCRNMAP                           = 19306
-	POLLERR                                  = 8
-	POLLHUP                                  = 16
-	POLLIN                                   = 1
-	POLLOUT                                  = 4
-	POLLPRI                                  = 2
-	POLLRDHUP                                = 8192
-	POSIX_FADV_DONTNEED                      = 4
-	POSIX_FADV_NOREUSE                       = 5
-	POSIX_FADV_NORMAL                        = 0
-	POSIX_FADV_RANDOM                        = 1
-	POSIX_FADV_SEQUENTIAL                    = 2
-	POSIX_FADV_WILLNEED                      = 3
-	PRIO_PGRP                                = 1
-	PRIO_PROCESS                             = 0
-	PRIO_USER                                = 2
-	PROT_EXEC                                = 4
-	PROT_READ                                = 1
-	PROT_WRITE                               = 2
-	PR_CAPBSET_DROP                          = 24
-	PR_CAPBSET_READ                          = 23
-	PR_ENDIAN_BIG                            = 0
-	PR_ENDIAN_LITTLE                         = 1
-	PR_ENDIAN_PPC_LITTLE                     = 2
-	PR_FP_EXC_ASYNC                          = 2
-	PR_FP_EXC_DISABLED                       = 0
-	PR_FP_EXC_DIV                            = 65536
-	PR_FP_EXC_INV                            = 1048576
-	PR_FP_EXC_NONRECOV                       = 1
-	PR_FP_EXC_OVF                            = 131072
-	PR_FP_EXC_PRECISE                        = 3
-	PR_FP_EXC_RES                            = 524288
-	PR_FP_EXC_SW_ENABLE                      = 128
-	PR_FP_EXC_UND                            = 262144
-	PR_GET_CHILD_SUBREAPER                   = 37
-	PR_GET_DUMPABLE                          = 3
-	PR_GET_ENDIAN                            = 19
-	PR_GET_FPEMU                             = 9
-	PR_GET_FPEXC                             = 11
-	PR_GET_KEEPCAPS                          = 7
-	PR_GET_NAME                              = 16
-	PR_GET_NO_NEW_PRIVS                      = 39
-	PR_GET_PDEATHSIG                         = 2
-	PR_GET_SECCOMP                           = 21
-	PR_GET_SECUREBITS                        = 27
-	PR_GET_TID_ADDRESS                       = 40
-	PR_GET_TIMERSLACK                        = 30
-	PR_GET_TIMING                            = 13
-	PR_GET_TSC                               = 25
-	PR_GET_UNALIGN                           = 5
-	PR_MCE_KILL                              = 33
-	PR_MCE_KILL_GET                          = 34
-	PR_SET_CHILD_SUBREAPER                   = 36
-	PR_SET_DUMPABLE                          = 4
-	PR_SET_ENDIAN                            = 20
-	PR_SET_FPEMU                             = 10
-	PR_SET_FPEXC                             = 12
-	PR_SET_KEEPCAPS                          = 8
-	PR_SET_MM                                = 35
-	PR_SET_MM_BRK                            = 7
-	PR_SET_MM_END_CODE                       = 2
-	PR_SET_MM_END_DATA                       = 4
-	PR_SET_MM_START_BRK                      = 6
-	PR_SET_MM_START_CODE                     = 1
-	PR_SET_MM_START_DATA                     = 3
-	PR_SET_MM_START_STACK                    = 5
-	PR_SET_NAME                              = 15
-	PR_SET_NO_NEW_PRIVS                      = 38
-	PR_SET_PDEATHSIG                         = 1
-	PR_SET_PTRACER                           = 1499557217
-	PR_SET_SECCOMP                           = 22
-	PR_SET_SECUREBITS                        = 28
-	PR_SET_TIMERSLACK                        = 29
-	PR_SET_TIMING                            = 14
-	PR_SET_TSC                               = 26
-	PR_SET_UNALIGN                           = 6
-	PR_TASK_PERF_EVENTS_DISABLE              = 31
-	PR_TASK_PERF_EVENTS_ENABLE               = 32
-	PTRACE_ATTACH                            = 16
-	PTRACE_CONT                              = 7
-	PTRACE_DETACH                            = 17
-	PTRACE_GETEVENTMSG                       = 16897
-	PTRACE_GETFPREGS                         = 14
-	PTRACE_GETREGS                           = 12
-	PTRACE_GETREGSET                         = 16900
-	PTRACE_GETSIGINFO                        = 16898
-	PTRACE_INTERRUPT                         = 16903
-	PTRACE_KILL                              = 8
-	PTRACE_LISTEN                            = 16904
-	PTRACE_O_EXITKILL                        = 1048576
-	PTRACE_O_TRACECLONE                      = 8
-	PTRACE_O_TRACEEXEC                       = 16
-	PTRACE_O_TRACEEXIT                       = 64
-	PTRACE_O_TRACEFORK                       = 2
-	PTRACE_O_TRACESYSGOOD                    = 1
-	PTRACE_O_TRACEVFORK                      = 4
-	PTRACE_O_TRACEVFORKDONE                  = 32
-	PTRACE_PEEKDATA                          = 2
-	PTRACE_PEEKTEXT                          = 1
-	PTRACE_PEEKUSR                           = 3
-	PTRACE_POKEDATA                          = 5
-	PTRACE_POKETEXT                          = 4
-	PTRACE_POKEUSR                           = 6
-	PTRACE_SEIZE                             = 16902
-	PTRACE_SETFPREGS                         = 15
-	PTRACE_SETOPTIONS                        = 16896
-	PTRACE_SETREGS                           = 13
-	PTRACE_SETREGSET                         = 16901
-	PTRACE_SETSIGINFO                        = 16899
-	PTRACE_SINGLESTEP                        = 9
-	PTRACE_SYSCALL                           = 24
-	PTRACE_SYSEMU                            = 31
-	PTRACE_SYSEMU_SINGLESTEP                 = 32
-	PTRACE_TRACEME                           = 0
-	P_ALL                                    = 0
-	P_PGID                                   = 2
-	P_PID                                    = 1
-	READ_IMPLIES_EXEC                        = 4194304
-	RENAME_EXCHANGE                          = 2
-	RENAME_NOREPLACE                         = 1
-	RENAME_WHITEOUT                          = 4
-	RFCOMM_CONNINFO                          = 2
-	RFCOMM_LM                                = 3
-	RLIMIT_AS                                = 9
-	RLIMIT_CORE                              = 4
-	RLIMIT_CPU                               = 0
-	RLIMIT_DATA                              = 2
-	RLIMIT_FSIZE                             = 1
-	RLIMIT_LOCKS                             = 10
-	RLIMIT_MEMLOCK                           = 8
-	RLIMIT_MSGQUEUE                          = 12
-	RLIMIT_NICE                              = 13
-	RLIMIT_NOFILE                            = 7
-	RLIMIT_NPROC                             = 6
-	RLIMIT_RSS                               = 5
-	RLIMIT_RTPRIO                            = 14
-	RLIMIT_RTTIME                            = 15
-	RLIMIT_SIGPENDING                        = 11
-	RLIMIT_STACK                             = 3
-	RNDADDENTROPY                            = 1074287107
-	RNDADDTOENTCNT                           = 1074024961
-	RNDCLEARPOOL                             = 20998
-	RNDGETENTCNT                             = 2147766784
-	RNDZAPENTCNT                             = 20996
-	RUSAGE_CHILDREN                          = 18446744073709551615
-	RUSAGE_SELF                              = 0
-	RUSAGE_THREAD                            = 1
-	SA_NOCLDSTOP                             = 1
-	SA_NOCLDWAIT                             = 2
-	SA_NODEFER                               = 1073741824
-	SA_ONSTACK                               = 134217728
-	SA_RESETHAND                             = 2147483648
-	SA_RESTART                               = 268435456
-	SA_SIGINFO                               = 4
-	SCHED_BATCH                              = 3
-	SCHED_DEADLINE                           = 6
-	SCHED_FIFO                               = 1
-	SCHED_FLAG_RESET_ON_FORK                 = 1
-	SCHED_IDLE                               = 5
-	SCHED_NORMAL                             = 0
-	SCHED_RR                                 = 2
-	SCM_CREDENTIALS                          = 2
-	SCM_RIGHTS                               = 1
-	SCO_CONNINFO                             = 2
-	SCO_OPTIONS                              = 1
-	SCTP_ABORT                               = 4
-	SCTP_ADAPTATION_LAYER                    = 7
-	SCTP_ADDR_OVER                           = 2
-	SCTP_ASSOCINFO                           = 1
-	SCTP_AUTH_ACTIVE_KEY                     = 24
-	SCTP_AUTH_CHUNK                          = 21
-	SCTP_AUTH_DELETE_KEY                     = 25
-	SCTP_AUTH_KEY                            = 23
-	SCTP_AUTOCLOSE                           = 4
-	SCTP_AUTO_ASCONF                         = 30
-	SCTP_CONTEXT                             = 17
-	SCTP_DEFAULT_SEND_PARAM                  = 10
-	SCTP_DEFAULT_SNDINFO                     = 34
-	SCTP_DELAYED_SACK                        = 16
-	SCTP_DISABLE_FRAGMENTS                   = 8
-	SCTP_EOF                                 = 512
-	SCTP_EVENTS                              = 11
-	SCTP_FRAGMENT_INTERLEAVE                 = 18
-	SCTP_GET_ASSOC_ID_LIST                   = 29
-	SCTP_GET_ASSOC_NUMBER                    = 28
-	SCTP_GET_ASSOC_STATS                     = 112
-	SCTP_GET_LOCAL_ADDRS                     = 109
-	SCTP_GET_PEER_ADDRS                      = 108
-	SCTP_GET_PEER_ADDR_INFO                  = 15
-	SCTP_HMAC_IDENT                          = 22
-	SCTP_INIT                                = 0
-	SCTP_INITMSG                             = 2
-	SCTP_I_WANT_MAPPED_V4_ADDR               = 12
-	SCTP_LOCAL_AUTH_CHUNKS                   = 27
-	SCTP_MAXSEG                              = 13
-	SCTP_MAX_BURST                           = 20
-	SCTP_NODELAY                             = 3
-	SCTP_PARTIAL_DELIVERY_POINT              = 19
-	SCTP_PEER_ADDR_PARAMS                    = 9
-	SCTP_PEER_ADDR_THLDS                     = 31
-	SCTP_PEER_AUTH_CHUNKS                    = 26
-	SCTP_PRIMARY_ADDR                        = 6
-	SCTP_RECVNXTINFO                         = 33
-	SCTP_RECVRCVINFO                         = 32
-	SCTP_RTOINFO                             = 0
-	SCTP_SET_PEER_PRIMARY_ADDR               = 5
-	SCTP_SNDINFO                             = 2
-	SCTP_SNDRCV                              = 1
-	SCTP_SOCKOPT_BINDX_ADD                   = 100
-	SCTP_SOCKOPT_BINDX_REM                   = 101
-	SCTP_SOCKOPT_CONNECTX                    = 110
-	SCTP_SOCKOPT_CONNECTX3                   = 111
-	SCTP_SOCKOPT_CONNECTX_OLD                = 107
-	SCTP_SOCKOPT_PEELOFF                     = 102
-	SCTP_STATUS                              = 14
-	SCTP_UNORDERED                           = 1
-	SECCOMP_FILTER_FLAG_TSYNC                = 1
-	SECCOMP_MODE_DISABLED                    = 0
-	SECCOMP_MODE_FILTER                      = 2
-	SECCOMP_MODE_STRICT                      = 1
-	SECCOMP_SET_MODE_FILTER                  = 1
-	SECCOMP_SET_MODE_STRICT                  = 0
-	SEEK_CUR                                 = 1
-	SEEK_DATA                                = 3
-	SEEK_END                                 = 2
-	SEEK_HOLE                                = 4
-	SEEK_SET                                 = 0
-	SEM_INFO                                 = 19
-	SEM_STAT                                 = 18
-	SEM_UNDO                                 = 4096
-	SETALL                                   = 17
-	SETVAL                                   = 16
-	SFD_CLOEXEC                              = 524288
-	SFD_NONBLOCK                             = 2048
-	SHM_HUGETLB                              = 2048
-	SHM_INFO                                 = 14
-	SHM_LOCK                                 = 11
-	SHM_NORESERVE                            = 4096
-	SHM_RDONLY                               = 4096
-	SHM_REMAP                                = 16384
-	SHM_RND                                  = 8192
-	SHM_STAT                                 = 13
-	SHM_UNLOCK                               = 12
-	SHORT_INODE                              = 16777216
-	SHUT_RD                                  = 0
-	SHUT_WR                                  = 1
-	SIGEV_NONE                               = 1
-	SIGEV_SIGNAL                             = 0
-	SIGEV_THREAD                             = 2
-	SIG_BLOCK                                = 0
-	SIG_SETMASK                              = 2
-	SIG_UNBLOCK                              = 1
-	SIOCADDRT                                = 35083
-	SIOCGIFHWADDR                            = 35111
-	SIOCGSTAMP                               = 35078
-	SIOCGSTAMPNS                             = 35079
-	SIOCINQ                                  = 21531
-	SIOCKCMATTACH                            = 35296
-	SIOCKCMCLONE                             = 35298
-	SIOCKCMUNATTACH                          = 35297
-	SIOCOUTQ                                 = 21521
-	SIOCSIFHWADDR                            = 35108
-	SNDRV_CTL_ELEM_IFACE_CARD                = 0
-	SNDRV_CTL_ELEM_IFACE_HWDEP               = 1
-	SNDRV_CTL_ELEM_IFACE_MIXER               = 2
-	SNDRV_CTL_ELEM_IFACE_PCM                 = 3
-	SNDRV_CTL_ELEM_IFACE_RAWMIDI             = 4
-	SNDRV_CTL_ELEM_IFACE_SEQUENCER           = 6
-	SNDRV_CTL_ELEM_IFACE_TIMER               = 5
-	SNDRV_CTL_IOCTL_CARD_INFO                = 2172146945
-	SNDRV_CTL_IOCTL_ELEM_ADD                 = 3239073047
-	SNDRV_CTL_IOCTL_ELEM_INFO                = 3239073041
-	SNDRV_CTL_IOCTL_ELEM_LIST                = 3226490128
-	SNDRV_CTL_IOCTL_ELEM_LOCK                = 1077957908
-	SNDRV_CTL_IOCTL_ELEM_READ                = 3301463314
-	SNDRV_CTL_IOCTL_ELEM_REMOVE              = 3225441561
-	SNDRV_CTL_IOCTL_ELEM_REPLACE             = 3239073048
-	SNDRV_CTL_IOCTL_ELEM_UNLOCK              = 1077957909
-	SNDRV_CTL_IOCTL_ELEM_WRITE               = 3301463315
-	SNDRV_CTL_IOCTL_HWDEP_INFO               = 2161923361
-	SNDRV_CTL_IOCTL_HWDEP_NEXT_DEVICE        = 3221509408
-	SNDRV_CTL_IOCTL_PCM_INFO                 = 3240121649
-	SNDRV_CTL_IOCTL_PCM_NEXT_DEVICE          = 2147767600
-	SNDRV_CTL_IOCTL_PCM_PREFER_SUBDEVICE     = 1074025778
-	SNDRV_CTL_IOCTL_POWER_STATE              = 2147767761
-	SNDRV_CTL_IOCTL_PVERSION                 = 2147767552
-	SNDRV_CTL_IOCTL_RAWMIDI_INFO             = 3238810945
-	SNDRV_CTL_IOCTL_RAWMIDI_NEXT_DEVICE      = 3221509440
-	SNDRV_CTL_IOCTL_RAWMIDI_PREFER_SUBDEVICE = 1074025794
-	SNDRV_CTL_IOCTL_SUBSCRIBE_EVENTS         = 3221509398
-	SNDRV_CTL_IOCTL_TLV_COMMAND              = 3221771548
-	SNDRV_CTL_IOCTL_TLV_READ                 = 3221771546
-	SNDRV_CTL_IOCTL_TLV_WRITE                = 3221771547
-	SNDRV_SEQ_FILTER_BOUNCE                  = 4
-	SNDRV_SEQ_FILTER_BROADCAST               = 1
-	SNDRV_SEQ_FILTER_MULTICAST               = 2
-	SNDRV_SEQ_FILTER_USE_EVENT               = 18446744071562067968
-	SNDRV_SEQ_IOCTL_CLIENT_ID                = 2147767041
-	SNDRV_SEQ_IOCTL_CREATE_PORT              = 3232256800
-	SNDRV_SEQ_IOCTL_CREATE_QUEUE             = 3230421810
-	SNDRV_SEQ_IOCTL_DELETE_PORT              = 1084773153
-	SNDRV_SEQ_IOCTL_DELETE_QUEUE             = 1082938163
-	SNDRV_SEQ_IOCTL_GET_CLIENT_INFO          = 3233567504
-	SNDRV_SEQ_IOCTL_GET_CLIENT_POOL          = 3227013963
-	SNDRV_SEQ_IOCTL_GET_NAMED_QUEUE          = 3230421814
-	SNDRV_SEQ_IOCTL_GET_PORT_INFO            = 3232256802
-	SNDRV_SEQ_IOCTL_GET_QUEUE_CLIENT         = 3226227529
-	SNDRV_SEQ_IOCTL_GET_QUEUE_INFO           = 3230421812
-	SNDRV_SEQ_IOCTL_GET_QUEUE_STATUS         = 3227276096
-	SNDRV_SEQ_IOCTL_GET_QUEUE_TEMPO          = 3224130369
-	SNDRV_SEQ_IOCTL_GET_QUEUE_TIMER          = 3227538245
-	SNDRV_SEQ_IOCTL_GET_SUBSCRIPTION         = 3226489680
-	SNDRV_SEQ_IOCTL_PVERSION                 = 2147767040
-	SNDRV_SEQ_IOCTL_QUERY_NEXT_CLIENT        = 3233567569
-	SNDRV_SEQ_IOCTL_QUERY_NEXT_PORT          = 3232256850
-	SNDRV_SEQ_IOCTL_QUERY_SUBS               = 3227013967
-	SNDRV_SEQ_IOCTL_REMOVE_EVENTS            = 1077957454
-	SNDRV_SEQ_IOCTL_RUNNING_MODE             = 3222295299
-	SNDRV_SEQ_IOCTL_SET_CLIENT_INFO          = 1086083857
-	SNDRV_SEQ_IOCTL_SET_CLIENT_POOL          = 1079530316
-	SNDRV_SEQ_IOCTL_SET_PORT_INFO            = 1084773155
-	SNDRV_SEQ_IOCTL_SET_QUEUE_CLIENT         = 1078743882
-	SNDRV_SEQ_IOCTL_SET_QUEUE_INFO           = 3230421813
-	SNDRV_SEQ_IOCTL_SET_QUEUE_TEMPO          = 1076646722
-	SNDRV_SEQ_IOCTL_SET_QUEUE_TIMER          = 1080054598
-	SNDRV_SEQ_IOCTL_SUBSCRIBE_PORT           = 1079006000
-	SNDRV_SEQ_IOCTL_SYSTEM_INFO              = 3224392450
-	SNDRV_SEQ_IOCTL_UNSUBSCRIBE_PORT         = 1079006001
-	SNDRV_SEQ_PORT_CAP_DUPLEX                = 16
-	SNDRV_SEQ_PORT_CAP_NO_EXPORT             = 128
-	SNDRV_SEQ_PORT_CAP_READ                  = 1
-	SNDRV_SEQ_PORT_CAP_SUBS_READ             = 32
-	SNDRV_SEQ_PORT_CAP_SUBS_WRITE            = 64
-	SNDRV_SEQ_PORT_CAP_SYNC_READ             = 4
-	SNDRV_SEQ_PORT_CAP_SYNC_WRITE            = 8
-	SNDRV_SEQ_PORT_CAP_WRITE                 = 2
-	SNDRV_SEQ_PORT_FLG_GIVEN_PORT            = 1
-	SNDRV_SEQ_PORT_FLG_TIMESTAMP             = 2
-	SNDRV_SEQ_PORT_FLG_TIME_REAL             = 4
-	SNDRV_SEQ_PORT_SUBS_EXCLUSIVE            = 1
-	SNDRV_SEQ_PORT_SUBS_TIMESTAMP            = 2
-	SNDRV_SEQ_PORT_SUBS_TIME_REAL            = 4
-	SNDRV_SEQ_PORT_TYPE_APPLICATION          = 1048576
-	SNDRV_SEQ_PORT_TYPE_DIRECT_SAMPLE        = 2048
-	SNDRV_SEQ_PORT_TYPE_HARDWARE             = 65536
-	SNDRV_SEQ_PORT_TYPE_MIDI_GENERIC         = 2
-	SNDRV_SEQ_PORT_TYPE_MIDI_GM              = 4
-	SNDRV_SEQ_PORT_TYPE_MIDI_GM2             = 64
-	SNDRV_SEQ_PORT_TYPE_MIDI_GS              = 8
-	SNDRV_SEQ_PORT_TYPE_MIDI_MT32            = 32
-	SNDRV_SEQ_PORT_TYPE_MIDI_XG              = 16
-	SNDRV_SEQ_PORT_TYPE_PORT                 = 524288
-	SNDRV_SEQ_PORT_TYPE_SAMPLE               = 4096
-	SNDRV_SEQ_PORT_TYPE_SOFTWARE             = 131072
-	SNDRV_SEQ_PORT_TYPE_SPECIFIC             = 1
-	SNDRV_SEQ_PORT_TYPE_SYNTH                = 1024
-	SNDRV_SEQ_PORT_TYPE_SYNTHESIZER          = 262144
-	SNDRV_SEQ_QUERY_SUBS_READ                = 0
-	SNDRV_SEQ_QUERY_SUBS_WRITE               = 1
-	SNDRV_SEQ_REMOVE_DEST                    = 4
-	SNDRV_SEQ_REMOVE_DEST_CHANNEL            = 8
-	SNDRV_SEQ_REMOVE_EVENT_TYPE              = 128
-	SNDRV_SEQ_REMOVE_IGNORE_OFF              = 256
-	SNDRV_SEQ_REMOVE_INPUT                   = 1
-	SNDRV_SEQ_REMOVE_OUTPUT                  = 2
-	SNDRV_SEQ_REMOVE_TAG_MATCH               = 512
-	SNDRV_SEQ_REMOVE_TIME_AFTER              = 32
-	SNDRV_SEQ_REMOVE_TIME_BEFORE             = 16
-	SNDRV_SEQ_REMOVE_TIME_TICK               = 64
-	SNDRV_SEQ_TIMER_ALSA                     = 0
-	SNDRV_SEQ_TIMER_MIDI_CLOCK               = 1
-	SNDRV_SEQ_TIMER_MIDI_TICK                = 2
-	SNDRV_TIMER_EVENT_CONTINUE               = 4
-	SNDRV_TIMER_EVENT_EARLY                  = 6
-	SNDRV_TIMER_EVENT_MCONTINUE              = 14
-	SNDRV_TIMER_EVENT_MPAUSE                 = 15
-	SNDRV_TIMER_EVENT_MRESUME                = 18
-	SNDRV_TIMER_EVENT_MSTART                 = 12
-	SNDRV_TIMER_EVENT_MSTOP                  = 13
-	SNDRV_TIMER_EVENT_MSUSPEND               = 17
-	SNDRV_TIMER_EVENT_PAUSE                  = 5
-	SNDRV_TIMER_EVENT_RESOLUTION             = 0
-	SNDRV_TIMER_EVENT_RESUME                 = 8
-	SNDRV_TIMER_EVENT_START                  = 2
-	SNDRV_TIMER_EVENT_STOP                   = 3
-	SNDRV_TIMER_EVENT_SUSPEND                = 7
-	SNDRV_TIMER_EVENT_TICK                   = 1
-	SNDRV_TIMER_IOCTL_CONTINUE               = 21666
-	SNDRV_TIMER_IOCTL_GINFO                  = 3237499907
-	SNDRV_TIMER_IOCTL_GPARAMS                = 1078481924
-	SNDRV_TIMER_IOCTL_GSTATUS                = 3226489861
-	SNDRV_TIMER_IOCTL_INFO                   = 2162709521
-	SNDRV_TIMER_IOCTL_NEXT_DEVICE            = 3222557697
-	SNDRV_TIMER_IOCTL_PARAMS                 = 1079006226
-	SNDRV_TIMER_IOCTL_PAUSE                  = 21667
-	SNDRV_TIMER_IOCTL_PVERSION               = 2147767296
-	SNDRV_TIMER_IOCTL_SELECT                 = 1077171216
-	SNDRV_TIMER_IOCTL_START                  = 21664
-	SNDRV_TIMER_IOCTL_STATUS                 = 2153796628
-	SNDRV_TIMER_IOCTL_STOP                   = 21665
-	SNDRV_TIMER_IOCTL_TREAD                  = 1074025474
-	SNDRV_TIMER_PSFLG_AUTO                   = 1
-	SNDRV_TIMER_PSFLG_EARLY_EVENT            = 4
-	SNDRV_TIMER_PSFLG_EXCLUSIVE              = 2
-	SOCK_CLOEXEC                             = 524288
-	SOCK_DGRAM                               = 2
-	SOCK_NONBLOCK                            = 2048
-	SOCK_PACKET                              = 10
-	SOCK_RAW                                 = 3
-	SOCK_RDM                                 = 4
-	SOCK_SEQPACKET                           = 5
-	SOCK_STREAM                              = 1
-	SOF_TIMESTAMPING_OPT_CMSG                = 1024
-	SOF_TIMESTAMPING_OPT_ID                  = 128
-	SOF_TIMESTAMPING_OPT_TSONLY              = 2048
-	SOF_TIMESTAMPING_RAW_HARDWARE            = 64
-	SOF_TIMESTAMPING_RX_HARDWARE             = 4
-	SOF_TIMESTAMPING_RX_SOFTWARE             = 8
-	SOF_TIMESTAMPING_SOFTWARE                = 16
-	SOF_TIMESTAMPING_SYS_HARDWARE            = 32
-	SOF_TIMESTAMPING_TX_ACK                  = 512
-	SOF_TIMESTAMPING_TX_HARDWARE             = 1
-	SOF_TIMESTAMPING_TX_SCHED                = 256
-	SOF_TIMESTAMPING_TX_SOFTWARE             = 2
-	SOL_ALG                                  = 279
-	SOL_BLUETOOTH                            = 274
-	SOL_KCM                                  = 281
-	SOL_L2CAP                                = 6
-	SOL_NETLINK                              = 270
-	SOL_NETROM                               = 259
-	SOL_NFC                                  = 280
-	SOL_RFCOMM                               = 18
-	SOL_SCO                                  = 17
-	SOL_SCTP                                 = 132
-	SOL_SOCKET                               = 1
-	SO_ACCEPTCONN                            = 30
-	SO_ATTACH_BPF                            = 50
-	SO_ATTACH_FILTER                         = 26
-	SO_BINDTODEVICE                          = 25
-	SO_BROADCAST                             = 6
-	SO_BUSY_POLL                             = 46
-	SO_DEBUG                                 = 1
-	SO_DETACH_FILTER                         = 27
-	SO_DOMAIN                                = 39
-	SO_DONTROUTE                             = 5
-	SO_ERROR                                 = 4
-	SO_GET_FILTER                            = 26
-	SO_KEEPALIVE                             = 9
-	SO_LINGER                                = 13
-	SO_LOCK_FILTER                           = 44
-	SO_MARK                                  = 36
-	SO_MAX_PACING_RATE                       = 47
-	SO_NOFCS                                 = 43
-	SO_NO_CHECK                              = 11
-	SO_OOBINLINE                             = 10
-	SO_PASSCRED                              = 16
-	SO_PASSSEC                               = 34
-	SO_PEEK_OFF                              = 42
-	SO_PEERCRED                              = 17
-	SO_PEERNAME                              = 28
-	SO_PEERSEC                               = 31
-	SO_PRIORITY                              = 12
-	SO_PROTOCOL                              = 38
-	SO_RCVBUF                                = 8
-	SO_RCVBUFFORCE                           = 33
-	SO_RCVLOWAT                              = 18
-	SO_RCVTIMEO                              = 20
-	SO_REUSEADDR                             = 2
-	SO_REUSEPORT                             = 15
-	SO_RXQ_OVFL                              = 40
-	SO_SELECT_ERR_QUEUE                      = 45
-	SO_SNDBUF                                = 7
-	SO_SNDBUFFORCE                           = 32
-	SO_SNDLOWAT                              = 19
-	SO_SNDTIMEO                              = 21
-	SO_TIMESTAMP                             = 29
-	SO_TIMESTAMPING                          = 37
-	SO_TIMESTAMPNS                           = 35
-	SO_TYPE                                  = 3
-	SO_WIFI_STATUS                           = 41
-	SPLICE_F_GIFT                            = 8
-	SPLICE_F_MORE                            = 4
-	SPLICE_F_MOVE                            = 1
-	SPLICE_F_NONBLOCK                        = 2
-	SPP_HB_DEMAND                            = 4
-	SPP_HB_DISABLE                           = 2
-	SPP_HB_ENABLE                            = 1
-	SPP_HB_TIME_IS_ZERO                      = 128
-	SPP_PMTUD_DISABLE                        = 16
-	SPP_PMTUD_ENABLE                         = 8
-	SPP_SACKDELAY_DISABLE                    = 64
-	SPP_SACKDELAY_ENABLE                     = 32
-	STICKY_TIMEOUTS                          = 67108864
-	SYNC_FILE_RANGE_WAIT_AFTER               = 4
-	SYNC_FILE_RANGE_WAIT_BEFORE              = 1
-	SYNC_FILE_RANGE_WRITE                    = 2
-	SYSLOG_ACTION_CLEAR                      = 5
-	SYSLOG_ACTION_CLOSE                      = 0
-	SYSLOG_ACTION_CONSOLE_OFF                = 6
-	SYSLOG_ACTION_CONSOLE_ON                 = 7
-	SYSLOG_ACTION_OPEN                       = 1
-	SYSLOG_ACTION_READ                       = 2
-	SYSLOG_ACTION_READ_ALL                   = 3
-	SYSLOG_ACTION_READ_CLEAR                 = 4
-	SYSLOG_ACTION_SIZE_BUFFER                = 10
-	SYSLOG_ACTION_SIZE_UNREAD                = 9
-	S_IFBLK                                  = 24576
-	S_IFCHR                                  = 8192
-	S_IFDIR                                  = 16384
-	S_IFIFO                                  = 4096
-	S_IFLNK                                  = 40960
-	S_IFREG                                  = 32768
-	S_IFSOCK                                 = 49152
-	S_IRGRP                                  = 32
-	S_IROTH                                  = 4
-	S_IRUSR                                  = 256
-	S_IWGRP                                  = 16
-	S_IWOTH                                  = 2
-	S_IWUSR                                  = 128
-	S_IXGRP                                  = 8
-	S_IXOTH                                  = 1
-	S_IXUSR                                  = 64
-	TCFLSH                                   = 21515
-	TCGETA                                   = 21509
-	TCGETS                                   = 21505
-	TCP_CORK                                 = 3
-	TCP_DEFER_ACCEPT                         = 9
-	TCP_INFO                                 = 11
-	TCP_KEEPCNT                              = 6
-	TCP_KEEPIDLE                             = 4
-	TCP_KEEPINTVL                            = 5
-	TCP_LINGER2                              = 8
-	TCP_MAXSEG                               = 2
-	TCP_NODELAY                              = 1
-	TCP_QUICKACK                             = 12
-	TCP_SYNCNT                               = 7
-	TCP_WINDOW_CLAMP                         = 10
-	TCSBRK                                   = 21513
-	TCSBRKP                                  = 21541
-	TCSETS                                   = 21506
-	TCSETSF                                  = 21508
-	TCXONC                                   = 21514
-	TFD_CLOEXEC                              = 524288
-	TFD_NONBLOCK                             = 2048
-	TFD_TIMER_ABSTIME                        = 1
-	TIMER_ABSTIME                            = 1
-	TIOCCBRK                                 = 21544
-	TIOCCONS                                 = 21533
-	TIOCEXCL                                 = 21516
-	TIOCGETD                                 = 21540
-	TIOCGLCKTRMIOS                           = 21590
-	TIOCGPGRP                                = 21519
-	TIOCGSOFTCAR                             = 21529
-	TIOCGWINSZ                               = 21523
-	TIOCINQ                                  = 21531
-	TIOCLINUX                                = 21532
-	TIOCMBIC                                 = 21527
-	TIOCMGET                                 = 21525
-	TIOCMSET                                 = 21528
-	TIOCNOTTY                                = 21538
-	TIOCNXCL                                 = 21517
-	TIOCOUTQ                                 = 21521
-	TIOCPKT                                  = 21536
-	TIOCSBRK                                 = 21543
-	TIOCSCTTY                                = 21518
-	TIOCSETD                                 = 21539
-	TIOCSLCKTRMIOS                           = 21591
-	TIOCSSOFTCAR                             = 21530
-	TIOCSTI                                  = 21522
-	TIOCSWINSZ                               = 21524
-	TUNATTACHFILTER                          = 1074812117
-	TUNDETACHFILTER                          = 1074812118
-	TUNGETFEATURES                           = 2147767503
-	TUNGETFILTER                             = 2148553947
-	TUNGETIFF                                = 2147767506
-	TUNGETSNDBUF                             = 2147767507
-	TUNGETVNETHDRSZ                          = 2147767511
-	TUNSETIFF                                = 1074025674
-	TUNSETIFINDEX                            = 1074025690
-	TUNSETLINK                               = 1074025677
-	TUNSETNOCSUM                             = 1074025672
-	TUNSETOFFLOAD                            = 1074025680
-	TUNSETOWNER                              = 1074025676
-	TUNSETPERSIST                            = 1074025675
-	TUNSETQUEUE                              = 1074025689
-	TUNSETSNDBUF                             = 1074025684
-	TUNSETTXFILTER                           = 1074025681
-	TUNSETVNETHDRSZ                          = 1074025688
-	UDP_CORK                                 = 1
-	UFFDIO_API                               = 3222841919
-	UFFDIO_COPY_MODE_DONTWAKE                = 1
-	UFFDIO_REGISTER                          = 3223366144
-	UFFDIO_REGISTER_MODE_MISSING             = 1
-	UFFDIO_REGISTER_MODE_WP                  = 2
-	UFFDIO_UNREGISTER                        = 2148575745
-	UFFDIO_WAKE                              = 2148575746
-	UFFDIO_ZEROPAGE_MODE_DONTWAKE            = 1
-	UMOUNT_NOFOLLOW                          = 8
-	USER_CLIENT                              = 1
-	VIRTIO_NET_HDR_F_DATA_VALID              = 2
-	VIRTIO_NET_HDR_F_NEEDS_CSUM              = 1
-	VIRTIO_NET_HDR_GSO_ECN                   = 128
-	VIRTIO_NET_HDR_GSO_NONE                  = 0
-	VIRTIO_NET_HDR_GSO_TCPV4                 = 1
-	VIRTIO_NET_HDR_GSO_TCPV6                 = 4
-	VIRTIO_NET_HDR_GSO_UDP                   = 3
-	VT_ACTIVATE                              = 22022
-	VT_DISALLOCATE                           = 22024
-	VT_GETMODE                               = 22017
-	VT_GETSTATE                              = 22019
-	VT_OPENQRY                               = 22016
-	VT_RELDISP                               = 22021
-	VT_RESIZE                                = 22025
-	VT_RESIZEX                               = 22026
-	VT_SETMODE                               = 22018
-	VT_WAITACTIVE                            = 22023
-	WCONTINUED                               = 8
-	WEXITED                                  = 4
-	WHOLE_SECONDS                            = 33554432
-	WNOHANG                                  = 1
-	WNOWAIT                                  = 16777216
-	WSTOPPED                                 = 2
-	WUNTRACED                                = 2
-	XATTR_CREATE                             = 1
-	XATTR_REPLACE                            = 2
-	_DRM_AGP                                 = 3
-	_DRM_AGP_BUFFER                          = 2
-	_DRM_CONSISTENT                          = 5
-	_DRM_CONTAINS_LOCK                       = 32
-	_DRM_CONTEXT_2DONLY                      = 2
-	_DRM_CONTEXT_PRESERVED                   = 1
-	_DRM_DMA_BLOCK                           = 1
-	_DRM_DMA_LARGER_OK                       = 64
-	_DRM_DMA_PRIORITY                        = 4
-	_DRM_DMA_SMALLER_OK                      = 32
-	_DRM_DMA_WAIT                            = 16
-	_DRM_DMA_WHILE_LOCKED                    = 2
-	_DRM_DRIVER                              = 128
-	_DRM_FB_BUFFER                           = 8
-	_DRM_FRAME_BUFFER                        = 0
-	_DRM_HALT_ALL_QUEUES                     = 16
-	_DRM_HALT_CUR_QUEUES                     = 32
-	_DRM_KERNEL                              = 8
-	_DRM_LOCKED                              = 4
-	_DRM_LOCK_FLUSH                          = 4
-	_DRM_LOCK_FLUSH_ALL                      = 8
-	_DRM_LOCK_QUIESCENT                      = 2
-	_DRM_LOCK_READY                          = 1
-	_DRM_PAGE_ALIGN                          = 1
-	_DRM_PCI_BUFFER_RO                       = 16
-	_DRM_READ_ONLY                           = 2
-	_DRM_REGISTERS                           = 1
-	_DRM_REMOVABLE                           = 64
-	_DRM_RESTRICTED                          = 1
-	_DRM_SCATTER_GATHER                      = 4
-	_DRM_SG_BUFFER                           = 4
-	_DRM_SHM                                 = 2
-	_DRM_VBLANK_ABSOLUTE                     = 0
-	_DRM_VBLANK_EVENT                        = 67108864
-	_DRM_VBLANK_FLIP                         = 134217728
-	_DRM_VBLANK_HIGH_CRTC_MASK               = 62
-	_DRM_VBLANK_NEXTONMISS                   = 268435456
-	_DRM_VBLANK_RELATIVE                     = 1
-	_DRM_VBLANK_SECONDARY                    = 536870912
-	_DRM_VBLANK_SIGNAL                       = 1073741824
-	_DRM_WRITE_COMBINING                     = 16
-	__WALL                                   = 1073741824
-	__WCLONE                                 = 2147483648
-	__WNOTHREAD                              = 536870912
+	ADDR_COMPAT_LAYOUT                       = sys.ADDR_COMPAT_LAYOUT
+	ADDR_LIMIT_32BIT                         = sys.ADDR_LIMIT_32BIT
+	ADDR_LIMIT_3GB                           = sys.ADDR_LIMIT_3GB
+	ADDR_NO_RANDOMIZE                        = sys.ADDR_NO_RANDOMIZE
+	AF_ALG                                   = sys.AF_ALG
+	AF_APPLETALK                             = sys.AF_APPLETALK
+	AF_ATMPVC                                = sys.AF_ATMPVC
+	AF_AX25                                  = sys.AF_AX25
+	AF_BLUETOOTH                             = sys.AF_BLUETOOTH
+	AF_BRIDGE                                = sys.AF_BRIDGE
+	AF_INET                                  = sys.AF_INET
+	AF_INET6                                 = sys.AF_INET6
+	AF_IPX                                   = sys.AF_IPX
+	AF_KCM                                   = sys.AF_KCM
+	AF_NETLINK                               = sys.AF_NETLINK
+	AF_NETROM                                = sys.AF_NETROM
+	AF_NFC                                   = sys.AF_NFC
+	AF_PACKET                                = sys.AF_PACKET
+	AF_UNIX                                  = sys.AF_UNIX
+	AF_UNSPEC                                = sys.AF_UNSPEC
+	AF_X25                                   = sys.AF_X25
+	ALG_SET_AEAD_ASSOCLEN                    = sys.ALG_SET_AEAD_ASSOCLEN
+	ALG_SET_AEAD_AUTHSIZE                    = sys.ALG_SET_AEAD_AUTHSIZE
+	ALG_SET_IV                               = sys.ALG_SET_IV
+	ALG_SET_KEY                              = sys.ALG_SET_KEY
+	ALG_SET_OP                               = sys.ALG_SET_OP
+	ARCH_GET_FS                              = sys.ARCH_GET_FS
+	ARCH_GET_GS                              = sys.ARCH_GET_GS
+	ARCH_SET_FS                              = sys.ARCH_SET_FS
+	ARCH_SET_GS                              = sys.ARCH_SET_GS
+	AT_EMPTY_PATH                            = sys.AT_EMPTY_PATH
+	AT_FDCWD                                 = sys.AT_FDCWD
+	AT_REMOVEDIR                             = sys.AT_REMOVEDIR
+	AT_SYMLINK_FOLLOW                        = sys.AT_SYMLINK_FOLLOW
+	AT_SYMLINK_NOFOLLOW                      = sys.AT_SYMLINK_NOFOLLOW
+	AX25_MAX_DIGIS                           = sys.AX25_MAX_DIGIS
+	BNEPCONNADD                              = sys.BNEPCONNADD
+	BNEPCONNDEL                              = sys.BNEPCONNDEL
+	BNEPGETCONNINFO                          = sys.BNEPGETCONNINFO
+	BNEPGETCONNLIST                          = sys.BNEPGETCONNLIST
+	BNEPGETSUPPFEAT                          = sys.BNEPGETSUPPFEAT
+	BPF_ANY                                  = sys.BPF_ANY
+	BPF_EXIST                                = sys.BPF_EXIST
+	BPF_FUNC_clone_redirect                  = sys.BPF_FUNC_clone_redirect
+	BPF_FUNC_get_cgroup_classid              = sys.BPF_FUNC_get_cgroup_classid
+	BPF_FUNC_get_current_comm                = sys.BPF_FUNC_get_current_comm
+	BPF_FUNC_get_current_pid_tgid            = sys.BPF_FUNC_get_current_pid_tgid
+	BPF_FUNC_get_current_uid_gid             = sys.BPF_FUNC_get_current_uid_gid
+	BPF_FUNC_get_prandom_u32                 = sys.BPF_FUNC_get_prandom_u32
+	BPF_FUNC_get_route_realm                 = sys.BPF_FUNC_get_route_realm
+	BPF_FUNC_get_smp_processor_id            = sys.BPF_FUNC_get_smp_processor_id
+	BPF_FUNC_ktime_get_ns                    = sys.BPF_FUNC_ktime_get_ns
+	BPF_FUNC_l3_csum_replace                 = sys.BPF_FUNC_l3_csum_replace
+	BPF_FUNC_l4_csum_replace                 = sys.BPF_FUNC_l4_csum_replace
+	BPF_FUNC_map_delete_elem                 = sys.BPF_FUNC_map_delete_elem
+	BPF_FUNC_map_lookup_elem                 = sys.BPF_FUNC_map_lookup_elem
+	BPF_FUNC_map_update_elem                 = sys.BPF_FUNC_map_update_elem
+	BPF_FUNC_perf_event_output               = sys.BPF_FUNC_perf_event_output
+	BPF_FUNC_perf_event_read                 = sys.BPF_FUNC_perf_event_read
+	BPF_FUNC_probe_read                      = sys.BPF_FUNC_probe_read
+	BPF_FUNC_redirect                        = sys.BPF_FUNC_redirect
+	BPF_FUNC_skb_get_tunnel_key              = sys.BPF_FUNC_skb_get_tunnel_key
+	BPF_FUNC_skb_set_tunnel_key              = sys.BPF_FUNC_skb_set_tunnel_key
+	BPF_FUNC_skb_store_bytes                 = sys.BPF_FUNC_skb_store_bytes
+	BPF_FUNC_skb_vlan_pop                    = sys.BPF_FUNC_skb_vlan_pop
+	BPF_FUNC_skb_vlan_push                   = sys.BPF_FUNC_skb_vlan_push
+	BPF_FUNC_tail_call                       = sys.BPF_FUNC_tail_call
+	BPF_FUNC_trace_printk                    = sys.BPF_FUNC_trace_printk
+	BPF_MAP_CREATE                           = sys.BPF_MAP_CREATE
+	BPF_MAP_DELETE_ELEM                      = sys.BPF_MAP_DELETE_ELEM
+	BPF_MAP_GET_NEXT_KEY                     = sys.BPF_MAP_GET_NEXT_KEY
+	BPF_MAP_LOOKUP_ELEM                      = sys.BPF_MAP_LOOKUP_ELEM
+	BPF_MAP_TYPE_ARRAY                       = sys.BPF_MAP_TYPE_ARRAY
+	BPF_MAP_TYPE_HASH                        = sys.BPF_MAP_TYPE_HASH
+	BPF_MAP_TYPE_PERF_EVENT_ARRAY            = sys.BPF_MAP_TYPE_PERF_EVENT_ARRAY
+	BPF_MAP_TYPE_PROG_ARRAY                  = sys.BPF_MAP_TYPE_PROG_ARRAY
+	BPF_MAP_UPDATE_ELEM                      = sys.BPF_MAP_UPDATE_ELEM
+	BPF_NOEXIST                              = sys.BPF_NOEXIST
+	BPF_OBJ_GET                              = sys.BPF_OBJ_GET
+	BPF_OBJ_PIN                              = sys.BPF_OBJ_PIN
+	BPF_PROG_LOAD                            = sys.BPF_PROG_LOAD
+	BPF_PROG_TYPE_KPROBE                     = sys.BPF_PROG_TYPE_KPROBE
+	BPF_PROG_TYPE_SCHED_ACT                  = sys.BPF_PROG_TYPE_SCHED_ACT
+	BPF_PROG_TYPE_SCHED_CLS                  = sys.BPF_PROG_TYPE_SCHED_CLS
+	BPF_PROG_TYPE_SOCKET_FILTER              = sys.BPF_PROG_TYPE_SOCKET_FILTER
+	BTPROTO_BNEP                             = sys.BTPROTO_BNEP
+	BTPROTO_CMTP                             = sys.BTPROTO_CMTP
+	BTPROTO_HCI                              = sys.BTPROTO_HCI
+	BTPROTO_HIDP                             = sys.BTPROTO_HIDP
+	BTPROTO_L2CAP                            = sys.BTPROTO_L2CAP
+	BTPROTO_RFCOMM                           = sys.BTPROTO_RFCOMM
+	BTPROTO_SCO                              = sys.BTPROTO_SCO
+	BT_CHANNEL_POLICY                        = sys.BT_CHANNEL_POLICY
+	BT_DEFER_SETUP                           = sys.BT_DEFER_SETUP
+	BT_FLUSHABLE                             = sys.BT_FLUSHABLE
+	BT_POWER                                 = sys.BT_POWER
+	BT_RCVMTU                                = sys.BT_RCVMTU
+	BT_SECURITY                              = sys.BT_SECURITY
+	BT_SNDMTU                                = sys.BT_SNDMTU
+	BT_VOICE                                 = sys.BT_VOICE
+	CLOCK_BOOTTIME                           = sys.CLOCK_BOOTTIME
+	CLOCK_MONOTONIC                          = sys.CLOCK_MONOTONIC
+	CLOCK_MONOTONIC_COARSE                   = sys.CLOCK_MONOTONIC_COARSE
+	CLOCK_MONOTONIC_RAW                      = sys.CLOCK_MONOTONIC_RAW
+	CLOCK_PROCESS_CPUTIME_ID                 = sys.CLOCK_PROCESS_CPUTIME_ID
+	CLOCK_REALTIME                           = sys.CLOCK_REALTIME
+	CLOCK_REALTIME_COARSE                    = sys.CLOCK_REALTIME_COARSE
+	CLOCK_THREAD_CPUTIME_ID                  = sys.CLOCK_THREAD_CPUTIME_ID
+	CLONE_CHILD_CLEARTID                     = sys.CLONE_CHILD_CLEARTID
+	CLONE_CHILD_SETTID                       = sys.CLONE_CHILD_SETTID
+	CLONE_FILES                              = sys.CLONE_FILES
+	CLONE_FS                                 = sys.CLONE_FS
+	CLONE_IO                                 = sys.CLONE_IO
+	CLONE_NEWIPC                             = sys.CLONE_NEWIPC
+	CLONE_NEWNET                             = sys.CLONE_NEWNET
+	CLONE_NEWNS                              = sys.CLONE_NEWNS
+	CLONE_NEWPID                             = sys.CLONE_NEWPID
+	CLONE_NEWUTS                             = sys.CLONE_NEWUTS
+	CLONE_PARENT                             = sys.CLONE_PARENT
+	CLONE_PARENT_SETTID                      = sys.CLONE_PARENT_SETTID
+	CLONE_PTRACE                             = sys.CLONE_PTRACE
+	CLONE_SETTLS                             = sys.CLONE_SETTLS
+	CLONE_SIGHAND                            = sys.CLONE_SIGHAND
+	CLONE_SYSVSEM                            = sys.CLONE_SYSVSEM
+	CLONE_THREAD                             = sys.CLONE_THREAD
+	CLONE_UNTRACED                           = sys.CLONE_UNTRACED
+	CLONE_VFORK                              = sys.CLONE_VFORK
+	CLONE_VM                                 = sys.CLONE_VM
+	CMTPCONNADD                              = sys.CMTPCONNADD
+	CMTPCONNDEL                              = sys.CMTPCONNDEL
+	CMTPGETCONNINFO                          = sys.CMTPGETCONNINFO
+	CMTPGETCONNLIST                          = sys.CMTPGETCONNLIST
+	CRYPTO_ALG_ASYNC                         = sys.CRYPTO_ALG_ASYNC
+	CRYPTO_ALG_DEAD                          = sys.CRYPTO_ALG_DEAD
+	CRYPTO_ALG_DYING                         = sys.CRYPTO_ALG_DYING
+	CRYPTO_ALG_GENIV                         = sys.CRYPTO_ALG_GENIV
+	CRYPTO_ALG_INSTANCE                      = sys.CRYPTO_ALG_INSTANCE
+	CRYPTO_ALG_INTERNAL                      = sys.CRYPTO_ALG_INTERNAL
+	CRYPTO_ALG_KERN_DRIVER_ONLY              = sys.CRYPTO_ALG_KERN_DRIVER_ONLY
+	CRYPTO_ALG_LARVAL                        = sys.CRYPTO_ALG_LARVAL
+	CRYPTO_ALG_NEED_FALLBACK                 = sys.CRYPTO_ALG_NEED_FALLBACK
+	CRYPTO_ALG_TESTED                        = sys.CRYPTO_ALG_TESTED
+	CRYPTO_ALG_TYPE_ABLKCIPHER               = sys.CRYPTO_ALG_TYPE_ABLKCIPHER
+	CRYPTO_ALG_TYPE_AEAD                     = sys.CRYPTO_ALG_TYPE_AEAD
+	CRYPTO_ALG_TYPE_AHASH                    = sys.CRYPTO_ALG_TYPE_AHASH
+	CRYPTO_ALG_TYPE_AKCIPHER                 = sys.CRYPTO_ALG_TYPE_AKCIPHER
+	CRYPTO_ALG_TYPE_BLKCIPHER                = sys.CRYPTO_ALG_TYPE_BLKCIPHER
+	CRYPTO_ALG_TYPE_CIPHER                   = sys.CRYPTO_ALG_TYPE_CIPHER
+	CRYPTO_ALG_TYPE_COMPRESS                 = sys.CRYPTO_ALG_TYPE_COMPRESS
+	CRYPTO_ALG_TYPE_DIGEST                   = sys.CRYPTO_ALG_TYPE_DIGEST
+	CRYPTO_ALG_TYPE_GIVCIPHER                = sys.CRYPTO_ALG_TYPE_GIVCIPHER
+	CRYPTO_ALG_TYPE_HASH                     = sys.CRYPTO_ALG_TYPE_HASH
+	CRYPTO_ALG_TYPE_MASK                     = sys.CRYPTO_ALG_TYPE_MASK
+	CRYPTO_ALG_TYPE_PCOMPRESS                = sys.CRYPTO_ALG_TYPE_PCOMPRESS
+	CRYPTO_ALG_TYPE_RNG                      = sys.CRYPTO_ALG_TYPE_RNG
+	CRYPTO_ALG_TYPE_SHASH                    = sys.CRYPTO_ALG_TYPE_SHASH
+	CTRL_ATTR_FAMILY_ID                      = sys.CTRL_ATTR_FAMILY_ID
+	CTRL_ATTR_FAMILY_NAME                    = sys.CTRL_ATTR_FAMILY_NAME
+	CTRL_CMD_GETFAMILY                       = sys.CTRL_CMD_GETFAMILY
+	DN_ACCESS                                = sys.DN_ACCESS
+	DN_ATTRIB                                = sys.DN_ATTRIB
+	DN_CREATE                                = sys.DN_CREATE
+	DN_DELETE                                = sys.DN_DELETE
+	DN_MODIFY                                = sys.DN_MODIFY
+	DN_MULTISHOT                             = sys.DN_MULTISHOT
+	DN_RENAME                                = sys.DN_RENAME
+	DRM_ADD_COMMAND                          = sys.DRM_ADD_COMMAND
+	DRM_DISPLAY_MODE_LEN                     = sys.DRM_DISPLAY_MODE_LEN
+	DRM_INST_HANDLER                         = sys.DRM_INST_HANDLER
+	DRM_IOCTL_ADD_BUFS                       = sys.DRM_IOCTL_ADD_BUFS
+	DRM_IOCTL_ADD_CTX                        = sys.DRM_IOCTL_ADD_CTX
+	DRM_IOCTL_ADD_MAP                        = sys.DRM_IOCTL_ADD_MAP
+	DRM_IOCTL_AGP_ACQUIRE                    = sys.DRM_IOCTL_AGP_ACQUIRE
+	DRM_IOCTL_AGP_ALLOC                      = sys.DRM_IOCTL_AGP_ALLOC
+	DRM_IOCTL_AGP_BIND                       = sys.DRM_IOCTL_AGP_BIND
+	DRM_IOCTL_AGP_ENABLE                     = sys.DRM_IOCTL_AGP_ENABLE
+	DRM_IOCTL_AGP_FREE                       = sys.DRM_IOCTL_AGP_FREE
+	DRM_IOCTL_AGP_INFO                       = sys.DRM_IOCTL_AGP_INFO
+	DRM_IOCTL_AGP_RELEASE                    = sys.DRM_IOCTL_AGP_RELEASE
+	DRM_IOCTL_AGP_UNBIND                     = sys.DRM_IOCTL_AGP_UNBIND
+	DRM_IOCTL_AUTH_MAGIC                     = sys.DRM_IOCTL_AUTH_MAGIC
+	DRM_IOCTL_CONTROL                        = sys.DRM_IOCTL_CONTROL
+	DRM_IOCTL_DMA                            = sys.DRM_IOCTL_DMA
+	DRM_IOCTL_DROP_MASTER                    = sys.DRM_IOCTL_DROP_MASTER
+	DRM_IOCTL_FREE_BUFS                      = sys.DRM_IOCTL_FREE_BUFS
+	DRM_IOCTL_GEM_CLOSE                      = sys.DRM_IOCTL_GEM_CLOSE
+	DRM_IOCTL_GEM_FLINK                      = sys.DRM_IOCTL_GEM_FLINK
+	DRM_IOCTL_GEM_OPEN                       = sys.DRM_IOCTL_GEM_OPEN
+	DRM_IOCTL_GET_CAP                        = sys.DRM_IOCTL_GET_CAP
+	DRM_IOCTL_GET_CLIENT                     = sys.DRM_IOCTL_GET_CLIENT
+	DRM_IOCTL_GET_CTX                        = sys.DRM_IOCTL_GET_CTX
+	DRM_IOCTL_GET_MAGIC                      = sys.DRM_IOCTL_GET_MAGIC
+	DRM_IOCTL_GET_MAP                        = sys.DRM_IOCTL_GET_MAP
+	DRM_IOCTL_GET_SAREA_CTX                  = sys.DRM_IOCTL_GET_SAREA_CTX
+	DRM_IOCTL_GET_STATS                      = sys.DRM_IOCTL_GET_STATS
+	DRM_IOCTL_GET_UNIQUE                     = sys.DRM_IOCTL_GET_UNIQUE
+	DRM_IOCTL_INFO_BUFS                      = sys.DRM_IOCTL_INFO_BUFS
+	DRM_IOCTL_IRQ_BUSID                      = sys.DRM_IOCTL_IRQ_BUSID
+	DRM_IOCTL_LOCK                           = sys.DRM_IOCTL_LOCK
+	DRM_IOCTL_MAP_BUFS                       = sys.DRM_IOCTL_MAP_BUFS
+	DRM_IOCTL_MARK_BUFS                      = sys.DRM_IOCTL_MARK_BUFS
+	DRM_IOCTL_MODESET_CTL                    = sys.DRM_IOCTL_MODESET_CTL
+	DRM_IOCTL_MODE_GETCRTC                   = sys.DRM_IOCTL_MODE_GETCRTC
+	DRM_IOCTL_MODE_GETPLANERESOURCES         = sys.DRM_IOCTL_MODE_GETPLANERESOURCES
+	DRM_IOCTL_MODE_GETRESOURCES              = sys.DRM_IOCTL_MODE_GETRESOURCES
+	DRM_IOCTL_MODE_SETCRTC                   = sys.DRM_IOCTL_MODE_SETCRTC
+	DRM_IOCTL_NEW_CTX                        = sys.DRM_IOCTL_NEW_CTX
+	DRM_IOCTL_PRIME_FD_TO_HANDLE             = sys.DRM_IOCTL_PRIME_FD_TO_HANDLE
+	DRM_IOCTL_PRIME_HANDLE_TO_FD             = sys.DRM_IOCTL_PRIME_HANDLE_TO_FD
+	DRM_IOCTL_RES_CTX                        = sys.DRM_IOCTL_RES_CTX
+	DRM_IOCTL_RM_CTX                         = sys.DRM_IOCTL_RM_CTX
+	DRM_IOCTL_RM_MAP                         = sys.DRM_IOCTL_RM_MAP
+	DRM_IOCTL_SET_CLIENT_CAP                 = sys.DRM_IOCTL_SET_CLIENT_CAP
+	DRM_IOCTL_SET_MASTER                     = sys.DRM_IOCTL_SET_MASTER
+	DRM_IOCTL_SET_SAREA_CTX                  = sys.DRM_IOCTL_SET_SAREA_CTX
+	DRM_IOCTL_SET_UNIQUE                     = sys.DRM_IOCTL_SET_UNIQUE
+	DRM_IOCTL_SET_VERSION                    = sys.DRM_IOCTL_SET_VERSION
+	DRM_IOCTL_SG_ALLOC                       = sys.DRM_IOCTL_SG_ALLOC
+	DRM_IOCTL_SG_FREE                        = sys.DRM_IOCTL_SG_FREE
+	DRM_IOCTL_SWITCH_CTX                     = sys.DRM_IOCTL_SWITCH_CTX
+	DRM_IOCTL_UNLOCK                         = sys.DRM_IOCTL_UNLOCK
+	DRM_IOCTL_VERSION                        = sys.DRM_IOCTL_VERSION
+	DRM_IOCTL_WAIT_VBLANK                    = sys.DRM_IOCTL_WAIT_VBLANK
+	DRM_RM_COMMAND                           = sys.DRM_RM_COMMAND
+	DRM_UNINST_HANDLER                       = sys.DRM_UNINST_HANDLER
+	EFD_CLOEXEC                              = sys.EFD_CLOEXEC
+	EFD_NONBLOCK                             = sys.EFD_NONBLOCK
+	EFD_SEMAPHORE                            = sys.EFD_SEMAPHORE
+	EPOLLET                                  = sys.EPOLLET
+	EPOLLONESHOT                             = sys.EPOLLONESHOT
+	EPOLL_CLOEXEC                            = sys.EPOLL_CLOEXEC
+	EPOLL_CTL_ADD                            = sys.EPOLL_CTL_ADD
+	EPOLL_CTL_DEL                            = sys.EPOLL_CTL_DEL
+	EPOLL_CTL_MOD                            = sys.EPOLL_CTL_MOD
+	EVIOCGABS0                               = sys.EVIOCGABS0
+	EVIOCGABS20                              = sys.EVIOCGABS20
+	EVIOCGABS2F                              = sys.EVIOCGABS2F
+	EVIOCGABS3F                              = sys.EVIOCGABS3F
+	EVIOCGBITKEY64                           = sys.EVIOCGBITKEY64
+	EVIOCGBITSND64                           = sys.EVIOCGBITSND64
+	EVIOCGBITSW64                            = sys.EVIOCGBITSW64
+	EVIOCGEFFECTS                            = sys.EVIOCGEFFECTS
+	EVIOCGID                                 = sys.EVIOCGID
+	EVIOCGKEY64                              = sys.EVIOCGKEY64
+	EVIOCGKEYCODE                            = sys.EVIOCGKEYCODE
+	EVIOCGKEYCODE_V2                         = sys.EVIOCGKEYCODE_V2
+	EVIOCGLED64                              = sys.EVIOCGLED64
+	EVIOCGMASK                               = sys.EVIOCGMASK
+	EVIOCGMTSLOTS64                          = sys.EVIOCGMTSLOTS64
+	EVIOCGNAME64                             = sys.EVIOCGNAME64
+	EVIOCGPHYS64                             = sys.EVIOCGPHYS64
+	EVIOCGPROP64                             = sys.EVIOCGPROP64
+	EVIOCGRAB                                = sys.EVIOCGRAB
+	EVIOCGREP                                = sys.EVIOCGREP
+	EVIOCGSND64                              = sys.EVIOCGSND64
+	EVIOCGSW64                               = sys.EVIOCGSW64
+	EVIOCGUNIQ64                             = sys.EVIOCGUNIQ64
+	EVIOCGVERSION                            = sys.EVIOCGVERSION
+	EVIOCREVOKE                              = sys.EVIOCREVOKE
+	EVIOCRMFF                                = sys.EVIOCRMFF
+	EVIOCSABS0                               = sys.EVIOCSABS0
+	EVIOCSABS20                              = sys.EVIOCSABS20
+	EVIOCSABS2F                              = sys.EVIOCSABS2F
+	EVIOCSABS3F                              = sys.EVIOCSABS3F
+	EVIOCSCLOCKID                            = sys.EVIOCSCLOCKID
+	EVIOCSFF                                 = sys.EVIOCSFF
+	EVIOCSKEYCODE                            = sys.EVIOCSKEYCODE
+	EVIOCSKEYCODE_V2                         = sys.EVIOCSKEYCODE_V2
+	EVIOCSMASK                               = sys.EVIOCSMASK
+	EVIOCSREP                                = sys.EVIOCSREP
+	EV_ABS                                   = sys.EV_ABS
+	EV_FF                                    = sys.EV_FF
+	EV_KEY                                   = sys.EV_KEY
+	EV_LED                                   = sys.EV_LED
+	EV_MSC                                   = sys.EV_MSC
+	EV_REL                                   = sys.EV_REL
+	EV_SND                                   = sys.EV_SND
+	EV_SW                                    = sys.EV_SW
+	EV_SYN                                   = sys.EV_SYN
+	FALLOC_FL_KEEP_SIZE                      = sys.FALLOC_FL_KEEP_SIZE
+	FALLOC_FL_PUNCH_HOLE                     = sys.FALLOC_FL_PUNCH_HOLE
+	FAN_ACCESS                               = sys.FAN_ACCESS
+	FAN_ACCESS_PERM                          = sys.FAN_ACCESS_PERM
+	FAN_CLASS_CONTENT                        = sys.FAN_CLASS_CONTENT
+	FAN_CLASS_NOTIF                          = sys.FAN_CLASS_NOTIF
+	FAN_CLASS_PRE_CONTENT                    = sys.FAN_CLASS_PRE_CONTENT
+	FAN_CLOEXEC                              = sys.FAN_CLOEXEC
+	FAN_CLOSE_NOWRITE                        = sys.FAN_CLOSE_NOWRITE
+	FAN_CLOSE_WRITE                          = sys.FAN_CLOSE_WRITE
+	FAN_EVENT_ON_CHILD                       = sys.FAN_EVENT_ON_CHILD
+	FAN_MARK_ADD                             = sys.FAN_MARK_ADD
+	FAN_MARK_DONT_FOLLOW                     = sys.FAN_MARK_DONT_FOLLOW
+	FAN_MARK_FLUSH                           = sys.FAN_MARK_FLUSH
+	FAN_MARK_IGNORED_MASK                    = sys.FAN_MARK_IGNORED_MASK
+	FAN_MARK_IGNORED_SURV_MODIFY             = sys.FAN_MARK_IGNORED_SURV_MODIFY
+	FAN_MARK_MOUNT                           = sys.FAN_MARK_MOUNT
+	FAN_MARK_ONLYDIR                         = sys.FAN_MARK_ONLYDIR
+	FAN_MARK_REMOVE                          = sys.FAN_MARK_REMOVE
+	FAN_MODIFY                               = sys.FAN_MODIFY
+	FAN_NONBLOCK                             = sys.FAN_NONBLOCK
+	FAN_ONDIR                                = sys.FAN_ONDIR
+	FAN_OPEN                                 = sys.FAN_OPEN
+	FAN_OPEN_PERM                            = sys.FAN_OPEN_PERM
+	FAN_UNLIMITED_MARKS                      = sys.FAN_UNLIMITED_MARKS
+	FAN_UNLIMITED_QUEUE                      = sys.FAN_UNLIMITED_QUEUE
+	FASYNC                                   = sys.FASYNC
+	FD_CLOEXEC                               = sys.FD_CLOEXEC
+	FF_CONSTANT                              = sys.FF_CONSTANT
+	FF_CUSTOM                                = sys.FF_CUSTOM
+	FF_DAMPER                                = sys.FF_DAMPER
+	FF_FRICTION                              = sys.FF_FRICTION
+	FF_INERTIA                               = sys.FF_INERTIA
+	FF_PERIODIC                              = sys.FF_PERIODIC
+	FF_RAMP                                  = sys.FF_RAMP
+	FF_SAW_DOWN                              = sys.FF_SAW_DOWN
+	FF_SAW_UP                                = sys.FF_SAW_UP
+	FF_SINE                                  = sys.FF_SINE
+	FF_SPRING                                = sys.FF_SPRING
+	FF_SQUARE                                = sys.FF_SQUARE
+	FF_TRIANGLE                              = sys.FF_TRIANGLE
+	FIEMAP_EXTENT_DATA_ENCRYPTED             = sys.FIEMAP_EXTENT_DATA_ENCRYPTED
+	FIEMAP_EXTENT_DATA_INLINE                = sys.FIEMAP_EXTENT_DATA_INLINE
+	FIEMAP_EXTENT_DATA_TAIL                  = sys.FIEMAP_EXTENT_DATA_TAIL
+	FIEMAP_EXTENT_DELALLOC                   = sys.FIEMAP_EXTENT_DELALLOC
+	FIEMAP_EXTENT_ENCODED                    = sys.FIEMAP_EXTENT_ENCODED
+	FIEMAP_EXTENT_LAST                       = sys.FIEMAP_EXTENT_LAST
+	FIEMAP_EXTENT_MERGED                     = sys.FIEMAP_EXTENT_MERGED
+	FIEMAP_EXTENT_NOT_ALIGNED                = sys.FIEMAP_EXTENT_NOT_ALIGNED
+	FIEMAP_EXTENT_SHARED                     = sys.FIEMAP_EXTENT_SHARED
+	FIEMAP_EXTENT_UNKNOWN                    = sys.FIEMAP_EXTENT_UNKNOWN
+	FIEMAP_EXTENT_UNWRITTEN                  = sys.FIEMAP_EXTENT_UNWRITTEN
+	FIEMAP_FLAG_CACHE                        = sys.FIEMAP_FLAG_CACHE
+	FIEMAP_FLAG_SYNC                         = sys.FIEMAP_FLAG_SYNC
+	FIEMAP_FLAG_XATTR                        = sys.FIEMAP_FLAG_XATTR
+	FIFREEZE                                 = sys.FIFREEZE
+	FIGETBSZ                                 = sys.FIGETBSZ
+	FIOASYNC                                 = sys.FIOASYNC
+	FIOCLEX                                  = sys.FIOCLEX
+	FIONBIO                                  = sys.FIONBIO
+	FIONCLEX                                 = sys.FIONCLEX
+	FIONREAD                                 = sys.FIONREAD
+	FIOQSIZE                                 = sys.FIOQSIZE
+	FITHAW                                   = sys.FITHAW
+	FS_IOC_FIEMAP                            = sys.FS_IOC_FIEMAP
+	FUSE_DEV_IOC_CLONE                       = sys.FUSE_DEV_IOC_CLONE
+	FUTEX_CMP_REQUEUE                        = sys.FUTEX_CMP_REQUEUE
+	FUTEX_REQUEUE                            = sys.FUTEX_REQUEUE
+	FUTEX_WAIT                               = sys.FUTEX_WAIT
+	FUTEX_WAIT_BITSET                        = sys.FUTEX_WAIT_BITSET
+	FUTEX_WAKE                               = sys.FUTEX_WAKE
+	F_ADD_SEALS                              = sys.F_ADD_SEALS
+	F_DUPFD                                  = sys.F_DUPFD
+	F_DUPFD_CLOEXEC                          = sys.F_DUPFD_CLOEXEC
+	F_GETFD                                  = sys.F_GETFD
+	F_GETFL                                  = sys.F_GETFL
+	F_GETLEASE                               = sys.F_GETLEASE
+	F_GETLK                                  = sys.F_GETLK
+	F_GETOWN                                 = sys.F_GETOWN
+	F_GETOWN_EX                              = sys.F_GETOWN_EX
+	F_GETPIPE_SZ                             = sys.F_GETPIPE_SZ
+	F_GETSIG                                 = sys.F_GETSIG
+	F_GET_SEALS                              = sys.F_GET_SEALS
+	F_OWNER_PGRP                             = sys.F_OWNER_PGRP
+	F_OWNER_PID                              = sys.F_OWNER_PID
+	F_OWNER_TID                              = sys.F_OWNER_TID
+	F_RDLCK                                  = sys.F_RDLCK
+	F_SEAL_GROW                              = sys.F_SEAL_GROW
+	F_SEAL_SEAL                              = sys.F_SEAL_SEAL
+	F_SEAL_SHRINK                            = sys.F_SEAL_SHRINK
+	F_SEAL_WRITE                             = sys.F_SEAL_WRITE
+	F_SETFD                                  = sys.F_SETFD
+	F_SETFL                                  = sys.F_SETFL
+	F_SETLEASE                               = sys.F_SETLEASE
+	F_SETLK                                  = sys.F_SETLK
+	F_SETLKW                                 = sys.F_SETLKW
+	F_SETOWN                                 = sys.F_SETOWN
+	F_SETOWN_EX                              = sys.F_SETOWN_EX
+	F_SETPIPE_SZ                             = sys.F_SETPIPE_SZ
+	F_SETSIG                                 = sys.F_SETSIG
+	F_UNLCK                                  = sys.F_UNLCK
+	F_WRLCK                                  = sys.F_WRLCK
+	GENL_ID_CTRL                             = sys.GENL_ID_CTRL
+	GENL_NAME_BATADV                         = sys.GENL_NAME_BATADV
+	GENL_NAME_DEVLINK                        = sys.GENL_NAME_DEVLINK
+	GENL_NAME_FOU                            = sys.GENL_NAME_FOU
+	GENL_NAME_GTP                            = sys.GENL_NAME_GTP
+	GENL_NAME_L2TP                           = sys.GENL_NAME_L2TP
+	GENL_NAME_MACSEC                         = sys.GENL_NAME_MACSEC
+	GENL_NAME_NET_DM                         = sys.GENL_NAME_NET_DM
+	GENL_NAME_NL80211                        = sys.GENL_NAME_NL80211
+	GENL_NAME_NLCTRL                         = sys.GENL_NAME_NLCTRL
+	GENL_NAME_TEAM                           = sys.GENL_NAME_TEAM
+	GETALL                                   = sys.GETALL
+	GETNCNT                                  = sys.GETNCNT
+	GETPID                                   = sys.GETPID
+	GETVAL                                   = sys.GETVAL
+	GETZCNT                                  = sys.GETZCNT
+	GIO_CMAP                                 = sys.GIO_CMAP
+	GIO_FONT                                 = sys.GIO_FONT
+	GIO_FONTX                                = sys.GIO_FONTX
+	GIO_SCRNMAP                              = sys.GIO_SCRNMAP
+	GIO_UNIMAP                               = sys.GIO_UNIMAP
+	GIO_UNISCRNMAP                           = sys.GIO_UNISCRNMAP
+	GRND_NONBLOCK                            = sys.GRND_NONBLOCK
+	GRND_RANDOM                              = sys.GRND_RANDOM
+	GSMIOC_GETCONF                           = sys.GSMIOC_GETCONF
+	GSMIOC_SETCONF                           = sys.GSMIOC_SETCONF
+	HCIBLOCKADDR                             = sys.HCIBLOCKADDR
+	HCIDEVDOWN                               = sys.HCIDEVDOWN
+	HCIDEVRESET                              = sys.HCIDEVRESET
+	HCIDEVRESTAT                             = sys.HCIDEVRESTAT
+	HCIDEVUP                                 = sys.HCIDEVUP
+	HCIGETAUTHINFO                           = sys.HCIGETAUTHINFO
+	HCIGETCONNINFO                           = sys.HCIGETCONNINFO
+	HCIGETCONNLIST                           = sys.HCIGETCONNLIST
+	HCIGETDEVINFO                            = sys.HCIGETDEVINFO
+	HCIGETDEVLIST                            = sys.HCIGETDEVLIST
+	HCIINQUIRY                               = sys.HCIINQUIRY
+	HCISETACLMTU                             = sys.HCISETACLMTU
+	HCISETAUTH                               = sys.HCISETAUTH
+	HCISETENCRYPT                            = sys.HCISETENCRYPT
+	HCISETLINKMODE                           = sys.HCISETLINKMODE
+	HCISETLINKPOL                            = sys.HCISETLINKPOL
+	HCISETPTYPE                              = sys.HCISETPTYPE
+	HCISETRAW                                = sys.HCISETRAW
+	HCISETSCAN                               = sys.HCISETSCAN
+	HCISETSCOMTU                             = sys.HCISETSCOMTU
+	HCIUARTGETDEVICE                         = sys.HCIUARTGETDEVICE
+	HCIUARTGETFLAGS                          = sys.HCIUARTGETFLAGS
+	HCIUARTGETPROTO                          = sys.HCIUARTGETPROTO
+	HCIUARTSETFLAGS                          = sys.HCIUARTSETFLAGS
+	HCIUARTSETPROTO                          = sys.HCIUARTSETPROTO
+	HCIUNBLOCKADDR                           = sys.HCIUNBLOCKADDR
+	HCI_CHANNEL_CONTROL                      = sys.HCI_CHANNEL_CONTROL
+	HCI_CHANNEL_MONITOR                      = sys.HCI_CHANNEL_MONITOR
+	HCI_CHANNEL_RAW                          = sys.HCI_CHANNEL_RAW
+	HCI_CHANNEL_USER                         = sys.HCI_CHANNEL_USER
+	HCI_DATA_DIR                             = sys.HCI_DATA_DIR
+	HCI_FILTER                               = sys.HCI_FILTER
+	HCI_TIME_STAMP                           = sys.HCI_TIME_STAMP
+	HIDPCONNADD                              = sys.HIDPCONNADD
+	HIDPCONNDEL                              = sys.HIDPCONNDEL
+	HIDPGETCONNINFO                          = sys.HIDPGETCONNINFO
+	HIDPGETCONNLIST                          = sys.HIDPGETCONNLIST
+	HID_DT_HID                               = sys.HID_DT_HID
+	HID_DT_REPORT                            = sys.HID_DT_REPORT
+	HW_BREAKPOINT_EMPTY                      = sys.HW_BREAKPOINT_EMPTY
+	HW_BREAKPOINT_R                          = sys.HW_BREAKPOINT_R
+	HW_BREAKPOINT_W                          = sys.HW_BREAKPOINT_W
+	HW_BREAKPOINT_X                          = sys.HW_BREAKPOINT_X
+	ICMPV6_FILTER                            = sys.ICMPV6_FILTER
+	IFA_ADDRESS                              = sys.IFA_ADDRESS
+	IFA_BROADCAST                            = sys.IFA_BROADCAST
+	IFA_CACHEINFO                            = sys.IFA_CACHEINFO
+	IFA_FLAGS                                = sys.IFA_FLAGS
+	IFA_F_DADFAILED                          = sys.IFA_F_DADFAILED
+	IFA_F_DEPRECATED                         = sys.IFA_F_DEPRECATED
+	IFA_F_HOMEADDRESS                        = sys.IFA_F_HOMEADDRESS
+	IFA_F_NODAD                              = sys.IFA_F_NODAD
+	IFA_F_OPTIMISTIC                         = sys.IFA_F_OPTIMISTIC
+	IFA_F_PERMANENT                          = sys.IFA_F_PERMANENT
+	IFA_F_SECONDARY                          = sys.IFA_F_SECONDARY
+	IFA_F_TENTATIVE                          = sys.IFA_F_TENTATIVE
+	IFA_LABEL                                = sys.IFA_LABEL
+	IFA_LOCAL                                = sys.IFA_LOCAL
+	IFF_ALLMULTI                             = sys.IFF_ALLMULTI
+	IFF_ATTACH_QUEUE                         = sys.IFF_ATTACH_QUEUE
+	IFF_AUTOMEDIA                            = sys.IFF_AUTOMEDIA
+	IFF_BROADCAST                            = sys.IFF_BROADCAST
+	IFF_DEBUG                                = sys.IFF_DEBUG
+	IFF_DETACH_QUEUE                         = sys.IFF_DETACH_QUEUE
+	IFF_DYNAMIC                              = sys.IFF_DYNAMIC
+	IFF_LOOPBACK                             = sys.IFF_LOOPBACK
+	IFF_MASTER                               = sys.IFF_MASTER
+	IFF_MULTICAST                            = sys.IFF_MULTICAST
+	IFF_MULTI_QUEUE                          = sys.IFF_MULTI_QUEUE
+	IFF_NOARP                                = sys.IFF_NOARP
+	IFF_NOFILTER                             = sys.IFF_NOFILTER
+	IFF_NOTRAILERS                           = sys.IFF_NOTRAILERS
+	IFF_NO_PI                                = sys.IFF_NO_PI
+	IFF_ONE_QUEUE                            = sys.IFF_ONE_QUEUE
+	IFF_PERSIST                              = sys.IFF_PERSIST
+	IFF_POINTOPOINT                          = sys.IFF_POINTOPOINT
+	IFF_PORTSEL                              = sys.IFF_PORTSEL
+	IFF_PROMISC                              = sys.IFF_PROMISC
+	IFF_RUNNING                              = sys.IFF_RUNNING
+	IFF_SLAVE                                = sys.IFF_SLAVE
+	IFF_TAP                                  = sys.IFF_TAP
+	IFF_TUN                                  = sys.IFF_TUN
+	IFF_TUN_EXCL                             = sys.IFF_TUN_EXCL
+	IFF_UP                                   = sys.IFF_UP
+	IFF_VNET_HDR                             = sys.IFF_VNET_HDR
+	IFLA_ADDRESS                             = sys.IFLA_ADDRESS
+	IFLA_BROADCAST                           = sys.IFLA_BROADCAST
+	IFLA_CARRIER                             = sys.IFLA_CARRIER
+	IFLA_GROUP                               = sys.IFLA_GROUP
+	IFLA_IFNAME                              = sys.IFLA_IFNAME
+	IFLA_INFO_DATA                           = sys.IFLA_INFO_DATA
+	IFLA_INFO_KIND                           = sys.IFLA_INFO_KIND
+	IFLA_LINK                                = sys.IFLA_LINK
+	IFLA_LINKINFO                            = sys.IFLA_LINKINFO
+	IFLA_LINKMODE                            = sys.IFLA_LINKMODE
+	IFLA_MASTER                              = sys.IFLA_MASTER
+	IFLA_MTU                                 = sys.IFLA_MTU
+	IFLA_NUM_RX_QUEUES                       = sys.IFLA_NUM_RX_QUEUES
+	IFLA_NUM_TX_QUEUES                       = sys.IFLA_NUM_TX_QUEUES
+	IFLA_OPERSTATE                           = sys.IFLA_OPERSTATE
+	IFLA_PROMISCUITY                         = sys.IFLA_PROMISCUITY
+	IFLA_TXQLEN                              = sys.IFLA_TXQLEN
+	IFNAMSIZ                                 = sys.IFNAMSIZ
+	IN_ACCESS                                = sys.IN_ACCESS
+	IN_ATTRIB                                = sys.IN_ATTRIB
+	IN_CLOEXEC                               = sys.IN_CLOEXEC
+	IN_CLOSE_NOWRITE                         = sys.IN_CLOSE_NOWRITE
+	IN_CLOSE_WRITE                           = sys.IN_CLOSE_WRITE
+	IN_CREATE                                = sys.IN_CREATE
+	IN_DELETE                                = sys.IN_DELETE
+	IN_DELETE_SELF                           = sys.IN_DELETE_SELF
+	IN_DONT_FOLLOW                           = sys.IN_DONT_FOLLOW
+	IN_EXCL_UNLINK                           = sys.IN_EXCL_UNLINK
+	IN_MASK_ADD                              = sys.IN_MASK_ADD
+	IN_MODIFY                                = sys.IN_MODIFY
+	IN_MOVED_FROM                            = sys.IN_MOVED_FROM
+	IN_MOVED_TO                              = sys.IN_MOVED_TO
+	IN_MOVE_SELF                             = sys.IN_MOVE_SELF
+	IN_NONBLOCK                              = sys.IN_NONBLOCK
+	IN_ONESHOT                               = sys.IN_ONESHOT
+	IN_ONLYDIR                               = sys.IN_ONLYDIR
+	IN_OPEN                                  = sys.IN_OPEN
+	IOCB_CMD_FDSYNC                          = sys.IOCB_CMD_FDSYNC
+	IOCB_CMD_FSYNC                           = sys.IOCB_CMD_FSYNC
+	IOCB_CMD_NOOP                            = sys.IOCB_CMD_NOOP
+	IOCB_CMD_PREAD                           = sys.IOCB_CMD_PREAD
+	IOCB_CMD_PREADV                          = sys.IOCB_CMD_PREADV
+	IOCB_CMD_PWRITE                          = sys.IOCB_CMD_PWRITE
+	IOCB_CMD_PWRITEV                         = sys.IOCB_CMD_PWRITEV
+	IOCB_FLAG_RESFD                          = sys.IOCB_FLAG_RESFD
+	IOPRIO_WHO_PGRP                          = sys.IOPRIO_WHO_PGRP
+	IOPRIO_WHO_PROCESS                       = sys.IOPRIO_WHO_PROCESS
+	IOPRIO_WHO_USER                          = sys.IOPRIO_WHO_USER
+	IPC_CREAT                                = sys.IPC_CREAT
+	IPC_EXCL                                 = sys.IPC_EXCL
+	IPC_INFO                                 = sys.IPC_INFO
+	IPC_NOWAIT                               = sys.IPC_NOWAIT
+	IPC_RMID                                 = sys.IPC_RMID
+	IPC_SET                                  = sys.IPC_SET
+	IPC_STAT                                 = sys.IPC_STAT
+	IPPROTO_ICMP                             = sys.IPPROTO_ICMP
+	IPPROTO_ICMPV6                           = sys.IPPROTO_ICMPV6
+	IPPROTO_IP                               = sys.IPPROTO_IP
+	IPPROTO_IPV6                             = sys.IPPROTO_IPV6
+	IPPROTO_SCTP                             = sys.IPPROTO_SCTP
+	IPPROTO_TCP                              = sys.IPPROTO_TCP
+	IPPROTO_UDP                              = sys.IPPROTO_UDP
+	IPV6_2292DSTOPTS                         = sys.IPV6_2292DSTOPTS
+	IPV6_2292HOPLIMIT                        = sys.IPV6_2292HOPLIMIT
+	IPV6_2292HOPOPTS                         = sys.IPV6_2292HOPOPTS
+	IPV6_2292PKTINFO                         = sys.IPV6_2292PKTINFO
+	IPV6_2292PKTOPTIONS                      = sys.IPV6_2292PKTOPTIONS
+	IPV6_2292RTHDR                           = sys.IPV6_2292RTHDR
+	IPV6_ADDRFORM                            = sys.IPV6_ADDRFORM
+	IPV6_ADD_MEMBERSHIP                      = sys.IPV6_ADD_MEMBERSHIP
+	IPV6_AUTHHDR                             = sys.IPV6_AUTHHDR
+	IPV6_CHECKSUM                            = sys.IPV6_CHECKSUM
+	IPV6_DROP_MEMBERSHIP                     = sys.IPV6_DROP_MEMBERSHIP
+	IPV6_DSTOPTS                             = sys.IPV6_DSTOPTS
+	IPV6_FLOWINFO                            = sys.IPV6_FLOWINFO
+	IPV6_HOPLIMIT                            = sys.IPV6_HOPLIMIT
+	IPV6_HOPOPTS                             = sys.IPV6_HOPOPTS
+	IPV6_JOIN_ANYCAST                        = sys.IPV6_JOIN_ANYCAST
+	IPV6_LEAVE_ANYCAST                       = sys.IPV6_LEAVE_ANYCAST
+	IPV6_MTU                                 = sys.IPV6_MTU
+	IPV6_MTU_DISCOVER                        = sys.IPV6_MTU_DISCOVER
+	IPV6_MULTICAST_HOPS                      = sys.IPV6_MULTICAST_HOPS
+	IPV6_MULTICAST_IF                        = sys.IPV6_MULTICAST_IF
+	IPV6_MULTICAST_LOOP                      = sys.IPV6_MULTICAST_LOOP
+	IPV6_PKTINFO                             = sys.IPV6_PKTINFO
+	IPV6_RECVDSTOPTS                         = sys.IPV6_RECVDSTOPTS
+	IPV6_RECVERR                             = sys.IPV6_RECVERR
+	IPV6_RECVHOPLIMIT                        = sys.IPV6_RECVHOPLIMIT
+	IPV6_RECVHOPOPTS                         = sys.IPV6_RECVHOPOPTS
+	IPV6_RECVORIGDSTADDR                     = sys.IPV6_RECVORIGDSTADDR
+	IPV6_RECVPATHMTU                         = sys.IPV6_RECVPATHMTU
+	IPV6_RECVPKTINFO                         = sys.IPV6_RECVPKTINFO
+	IPV6_RECVRTHDR                           = sys.IPV6_RECVRTHDR
+	IPV6_RECVTCLASS                          = sys.IPV6_RECVTCLASS
+	IPV6_ROUTER_ALERT                        = sys.IPV6_ROUTER_ALERT
+	IPV6_RTHDR                               = sys.IPV6_RTHDR
+	IPV6_RTHDRDSTOPTS                        = sys.IPV6_RTHDRDSTOPTS
+	IPV6_TCLASS                              = sys.IPV6_TCLASS
+	IPV6_TLV_CALIPSO                         = sys.IPV6_TLV_CALIPSO
+	IPV6_TLV_HAO                             = sys.IPV6_TLV_HAO
+	IPV6_TLV_JUMBO                           = sys.IPV6_TLV_JUMBO
+	IPV6_TLV_PAD1                            = sys.IPV6_TLV_PAD1
+	IPV6_TLV_PADN                            = sys.IPV6_TLV_PADN
+	IPV6_TLV_ROUTERALERT                     = sys.IPV6_TLV_ROUTERALERT
+	IPV6_UNICAST_HOPS                        = sys.IPV6_UNICAST_HOPS
+	IPV6_V6ONLY                              = sys.IPV6_V6ONLY
+	IP_ADD_MEMBERSHIP                        = sys.IP_ADD_MEMBERSHIP
+	IP_ADD_SOURCE_MEMBERSHIP                 = sys.IP_ADD_SOURCE_MEMBERSHIP
+	IP_BIND_ADDRESS_NO_PORT                  = sys.IP_BIND_ADDRESS_NO_PORT
+	IP_BLOCK_SOURCE                          = sys.IP_BLOCK_SOURCE
+	IP_CHECKSUM                              = sys.IP_CHECKSUM
+	IP_DROP_MEMBERSHIP                       = sys.IP_DROP_MEMBERSHIP
+	IP_DROP_SOURCE_MEMBERSHIP                = sys.IP_DROP_SOURCE_MEMBERSHIP
+	IP_FREEBIND                              = sys.IP_FREEBIND
+	IP_HDRINCL                               = sys.IP_HDRINCL
+	IP_IPSEC_POLICY                          = sys.IP_IPSEC_POLICY
+	IP_MINTTL                                = sys.IP_MINTTL
+	IP_MSFILTER                              = sys.IP_MSFILTER
+	IP_MTU                                   = sys.IP_MTU
+	IP_MTU_DISCOVER                          = sys.IP_MTU_DISCOVER
+	IP_MULTICAST_ALL                         = sys.IP_MULTICAST_ALL
+	IP_MULTICAST_IF                          = sys.IP_MULTICAST_IF
+	IP_MULTICAST_LOOP                        = sys.IP_MULTICAST_LOOP
+	IP_MULTICAST_TTL                         = sys.IP_MULTICAST_TTL
+	IP_NODEFRAG                              = sys.IP_NODEFRAG
+	IP_OPTIONS                               = sys.IP_OPTIONS
+	IP_PASSSEC                               = sys.IP_PASSSEC
+	IP_PKTINFO                               = sys.IP_PKTINFO
+	IP_PKTOPTIONS                            = sys.IP_PKTOPTIONS
+	IP_PMTUDISC_DO                           = sys.IP_PMTUDISC_DO
+	IP_PMTUDISC_DONT                         = sys.IP_PMTUDISC_DONT
+	IP_PMTUDISC_INTERFACE                    = sys.IP_PMTUDISC_INTERFACE
+	IP_PMTUDISC_OMIT                         = sys.IP_PMTUDISC_OMIT
+	IP_PMTUDISC_PROBE                        = sys.IP_PMTUDISC_PROBE
+	IP_PMTUDISC_WANT                         = sys.IP_PMTUDISC_WANT
+	IP_RECVERR                               = sys.IP_RECVERR
+	IP_RECVOPTS                              = sys.IP_RECVOPTS
+	IP_RECVORIGDSTADDR                       = sys.IP_RECVORIGDSTADDR
+	IP_RECVTOS                               = sys.IP_RECVTOS
+	IP_RECVTTL                               = sys.IP_RECVTTL
+	IP_RETOPTS                               = sys.IP_RETOPTS
+	IP_ROUTER_ALERT                          = sys.IP_ROUTER_ALERT
+	IP_TOS                                   = sys.IP_TOS
+	IP_TRANSPARENT                           = sys.IP_TRANSPARENT
+	IP_TTL                                   = sys.IP_TTL
+	IP_UNBLOCK_SOURCE                        = sys.IP_UNBLOCK_SOURCE
+	ITIMER_PROF                              = sys.ITIMER_PROF
+	ITIMER_REAL                              = sys.ITIMER_REAL
+	ITIMER_VIRTUAL                           = sys.ITIMER_VIRTUAL
+	KCMPROTO_CONNECTED                       = sys.KCMPROTO_CONNECTED
+	KCMP_FILE                                = sys.KCMP_FILE
+	KCMP_FILES                               = sys.KCMP_FILES
+	KCMP_FS                                  = sys.KCMP_FS
+	KCMP_IO                                  = sys.KCMP_IO
+	KCMP_SIGHAND                             = sys.KCMP_SIGHAND
+	KCMP_SYSVSEM                             = sys.KCMP_SYSVSEM
+	KCMP_VM                                  = sys.KCMP_VM
+	KCM_RECV_DISABLE                         = sys.KCM_RECV_DISABLE
+	KDADDIO                                  = sys.KDADDIO
+	KDBUS_ATTACH_ANY                         = sys.KDBUS_ATTACH_ANY
+	KDBUS_ATTACH_AUDIT                       = sys.KDBUS_ATTACH_AUDIT
+	KDBUS_ATTACH_AUXGROUPS                   = sys.KDBUS_ATTACH_AUXGROUPS
+	KDBUS_ATTACH_CAPS                        = sys.KDBUS_ATTACH_CAPS
+	KDBUS_ATTACH_CGROUP                      = sys.KDBUS_ATTACH_CGROUP
+	KDBUS_ATTACH_CMDLINE                     = sys.KDBUS_ATTACH_CMDLINE
+	KDBUS_ATTACH_CONN_DESCRIPTION            = sys.KDBUS_ATTACH_CONN_DESCRIPTION
+	KDBUS_ATTACH_CREDS                       = sys.KDBUS_ATTACH_CREDS
+	KDBUS_ATTACH_EXE                         = sys.KDBUS_ATTACH_EXE
+	KDBUS_ATTACH_NAMES                       = sys.KDBUS_ATTACH_NAMES
+	KDBUS_ATTACH_PIDS                        = sys.KDBUS_ATTACH_PIDS
+	KDBUS_ATTACH_PID_COMM                    = sys.KDBUS_ATTACH_PID_COMM
+	KDBUS_ATTACH_SECLABEL                    = sys.KDBUS_ATTACH_SECLABEL
+	KDBUS_ATTACH_TID_COMM                    = sys.KDBUS_ATTACH_TID_COMM
+	KDBUS_ATTACH_TIMESTAMP                   = sys.KDBUS_ATTACH_TIMESTAMP
+	KDBUS_CMD_BUS_CREATOR_INFO               = sys.KDBUS_CMD_BUS_CREATOR_INFO
+	KDBUS_CMD_BUS_MAKE                       = sys.KDBUS_CMD_BUS_MAKE
+	KDBUS_CMD_BYEBYE                         = sys.KDBUS_CMD_BYEBYE
+	KDBUS_CMD_CONN_INFO                      = sys.KDBUS_CMD_CONN_INFO
+	KDBUS_CMD_ENDPOINT_MAKE                  = sys.KDBUS_CMD_ENDPOINT_MAKE
+	KDBUS_CMD_ENDPOINT_UPDATE                = sys.KDBUS_CMD_ENDPOINT_UPDATE
+	KDBUS_CMD_FREE                           = sys.KDBUS_CMD_FREE
+	KDBUS_CMD_HELLO                          = sys.KDBUS_CMD_HELLO
+	KDBUS_CMD_LIST                           = sys.KDBUS_CMD_LIST
+	KDBUS_CMD_MATCH_ADD                      = sys.KDBUS_CMD_MATCH_ADD
+	KDBUS_CMD_MATCH_REMOVE                   = sys.KDBUS_CMD_MATCH_REMOVE
+	KDBUS_CMD_NAME_ACQUIRE                   = sys.KDBUS_CMD_NAME_ACQUIRE
+	KDBUS_CMD_NAME_RELEASE                   = sys.KDBUS_CMD_NAME_RELEASE
+	KDBUS_CMD_RECV                           = sys.KDBUS_CMD_RECV
+	KDBUS_CMD_SEND                           = sys.KDBUS_CMD_SEND
+	KDBUS_CMD_UPDATE                         = sys.KDBUS_CMD_UPDATE
+	KDBUS_HELLO_ACCEPT_FD                    = sys.KDBUS_HELLO_ACCEPT_FD
+	KDBUS_HELLO_ACTIVATOR                    = sys.KDBUS_HELLO_ACTIVATOR
+	KDBUS_HELLO_MONITOR                      = sys.KDBUS_HELLO_MONITOR
+	KDBUS_HELLO_POLICY_HOLDER                = sys.KDBUS_HELLO_POLICY_HOLDER
+	KDBUS_IOCTL_MAGIC                        = sys.KDBUS_IOCTL_MAGIC
+	KDBUS_ITEM_ATTACH_FLAGS_RECV             = sys.KDBUS_ITEM_ATTACH_FLAGS_RECV
+	KDBUS_ITEM_ATTACH_FLAGS_SEND             = sys.KDBUS_ITEM_ATTACH_FLAGS_SEND
+	KDBUS_ITEM_AUDIT                         = sys.KDBUS_ITEM_AUDIT
+	KDBUS_ITEM_AUXGROUPS                     = sys.KDBUS_ITEM_AUXGROUPS
+	KDBUS_ITEM_BLOOM_FILTER                  = sys.KDBUS_ITEM_BLOOM_FILTER
+	KDBUS_ITEM_BLOOM_MASK                    = sys.KDBUS_ITEM_BLOOM_MASK
+	KDBUS_ITEM_BLOOM_PARAMETER               = sys.KDBUS_ITEM_BLOOM_PARAMETER
+	KDBUS_ITEM_CANCEL_FD                     = sys.KDBUS_ITEM_CANCEL_FD
+	KDBUS_ITEM_CAPS                          = sys.KDBUS_ITEM_CAPS
+	KDBUS_ITEM_CGROUP                        = sys.KDBUS_ITEM_CGROUP
+	KDBUS_ITEM_CMDLINE                       = sys.KDBUS_ITEM_CMDLINE
+	KDBUS_ITEM_CONN_DESCRIPTION              = sys.KDBUS_ITEM_CONN_DESCRIPTION
+	KDBUS_ITEM_CREDS                         = sys.KDBUS_ITEM_CREDS
+	KDBUS_ITEM_DST_ID                        = sys.KDBUS_ITEM_DST_ID
+	KDBUS_ITEM_DST_NAME                      = sys.KDBUS_ITEM_DST_NAME
+	KDBUS_ITEM_EXE                           = sys.KDBUS_ITEM_EXE
+	KDBUS_ITEM_FDS                           = sys.KDBUS_ITEM_FDS
+	KDBUS_ITEM_ID                            = sys.KDBUS_ITEM_ID
+	KDBUS_ITEM_ID_ADD                        = sys.KDBUS_ITEM_ID_ADD
+	KDBUS_ITEM_ID_REMOVE                     = sys.KDBUS_ITEM_ID_REMOVE
+	KDBUS_ITEM_MAKE_NAME                     = sys.KDBUS_ITEM_MAKE_NAME
+	KDBUS_ITEM_NAME                          = sys.KDBUS_ITEM_NAME
+	KDBUS_ITEM_NAME_ADD                      = sys.KDBUS_ITEM_NAME_ADD
+	KDBUS_ITEM_NAME_CHANGE                   = sys.KDBUS_ITEM_NAME_CHANGE
+	KDBUS_ITEM_NAME_REMOVE                   = sys.KDBUS_ITEM_NAME_REMOVE
+	KDBUS_ITEM_NEGOTIATE                     = sys.KDBUS_ITEM_NEGOTIATE
+	KDBUS_ITEM_OWNED_NAME                    = sys.KDBUS_ITEM_OWNED_NAME
+	KDBUS_ITEM_PAYLOAD_MEMFD                 = sys.KDBUS_ITEM_PAYLOAD_MEMFD
+	KDBUS_ITEM_PAYLOAD_OFF                   = sys.KDBUS_ITEM_PAYLOAD_OFF
+	KDBUS_ITEM_PAYLOAD_VEC                   = sys.KDBUS_ITEM_PAYLOAD_VEC
+	KDBUS_ITEM_PIDS                          = sys.KDBUS_ITEM_PIDS
+	KDBUS_ITEM_PID_COM                       = sys.KDBUS_ITEM_PID_COM
+	KDBUS_ITEM_POLICY_ACCESS                 = sys.KDBUS_ITEM_POLICY_ACCESS
+	KDBUS_ITEM_REPLY_DEAD                    = sys.KDBUS_ITEM_REPLY_DEAD
+	KDBUS_ITEM_REPLY_TIMEOUT                 = sys.KDBUS_ITEM_REPLY_TIMEOUT
+	KDBUS_ITEM_SECLABEL                      = sys.KDBUS_ITEM_SECLABEL
+	KDBUS_ITEM_TID_COMM                      = sys.KDBUS_ITEM_TID_COMM
+	KDBUS_ITEM_TIMESTAMP                     = sys.KDBUS_ITEM_TIMESTAMP
+	KDBUS_LIST_ACTIVATORS                    = sys.KDBUS_LIST_ACTIVATORS
+	KDBUS_LIST_NAMES                         = sys.KDBUS_LIST_NAMES
+	KDBUS_LIST_QUEUED                        = sys.KDBUS_LIST_QUEUED
+	KDBUS_LIST_UNIQUE                        = sys.KDBUS_LIST_UNIQUE
+	KDBUS_MAKE_ACCESS_GROUP                  = sys.KDBUS_MAKE_ACCESS_GROUP
+	KDBUS_MAKE_ACCESS_WORLD                  = sys.KDBUS_MAKE_ACCESS_WORLD
+	KDBUS_MATCH_REPLACE                      = sys.KDBUS_MATCH_REPLACE
+	KDBUS_MSG_EXPECT_REPLY                   = sys.KDBUS_MSG_EXPECT_REPLY
+	KDBUS_MSG_NO_AUTO_START                  = sys.KDBUS_MSG_NO_AUTO_START
+	KDBUS_MSG_SIGNAL                         = sys.KDBUS_MSG_SIGNAL
+	KDBUS_NAME_ACQUIRED                      = sys.KDBUS_NAME_ACQUIRED
+	KDBUS_NAME_ACTIVATOR                     = sys.KDBUS_NAME_ACTIVATOR
+	KDBUS_NAME_ALLOW_REPLACEMENT             = sys.KDBUS_NAME_ALLOW_REPLACEMENT
+	KDBUS_NAME_IN_QUEUE                      = sys.KDBUS_NAME_IN_QUEUE
+	KDBUS_NAME_PRIMARY                       = sys.KDBUS_NAME_PRIMARY
+	KDBUS_NAME_QUEUE                         = sys.KDBUS_NAME_QUEUE
+	KDBUS_NAME_REPLACE_EXISTING              = sys.KDBUS_NAME_REPLACE_EXISTING
+	KDBUS_POLICY_ACCESS_GROUP                = sys.KDBUS_POLICY_ACCESS_GROUP
+	KDBUS_POLICY_ACCESS_NULL                 = sys.KDBUS_POLICY_ACCESS_NULL
+	KDBUS_POLICY_ACCESS_USER                 = sys.KDBUS_POLICY_ACCESS_USER
+	KDBUS_POLICY_ACCESS_WORLD                = sys.KDBUS_POLICY_ACCESS_WORLD
+	KDBUS_POLICY_OWN                         = sys.KDBUS_POLICY_OWN
+	KDBUS_POLICY_SEE                         = sys.KDBUS_POLICY_SEE
+	KDBUS_POLICY_TALK                        = sys.KDBUS_POLICY_TALK
+	KDBUS_RECV_RETURN_DROPPED_MSGS           = sys.KDBUS_RECV_RETURN_DROPPED_MSGS
+	KDBUS_RECV_RETURN_INCOMPLETE_FDS         = sys.KDBUS_RECV_RETURN_INCOMPLETE_FDS
+	KDBUS_SEND_SYNC_REPLY                    = sys.KDBUS_SEND_SYNC_REPLY
+	KDDELIO                                  = sys.KDDELIO
+	KDDISABIO                                = sys.KDDISABIO
+	KDENABIO                                 = sys.KDENABIO
+	KDGETKEYCODE                             = sys.KDGETKEYCODE
+	KDGETLED                                 = sys.KDGETLED
+	KDGETMODE                                = sys.KDGETMODE
+	KDGKBDIACR                               = sys.KDGKBDIACR
+	KDGKBENT                                 = sys.KDGKBENT
+	KDGKBLED                                 = sys.KDGKBLED
+	KDGKBMETA                                = sys.KDGKBMETA
+	KDGKBMODE                                = sys.KDGKBMODE
+	KDGKBSENT                                = sys.KDGKBSENT
+	KDGKBTYPE                                = sys.KDGKBTYPE
+	KDMKTONE                                 = sys.KDMKTONE
+	KDSETKEYCODE                             = sys.KDSETKEYCODE
+	KDSETLED                                 = sys.KDSETLED
+	KDSETMODE                                = sys.KDSETMODE
+	KDSIGACCEPT                              = sys.KDSIGACCEPT
+	KDSKBLED                                 = sys.KDSKBLED
+	KDSKBMETA                                = sys.KDSKBMETA
+	KDSKBMODE                                = sys.KDSKBMODE
+	KDSKBSENT                                = sys.KDSKBSENT
+	KERNEL_CLIENT                            = sys.KERNEL_CLIENT
+	KEXEC_ARCH_386                           = sys.KEXEC_ARCH_386
+	KEXEC_ARCH_ARM                           = sys.KEXEC_ARCH_ARM
+	KEXEC_ARCH_IA_64                         = sys.KEXEC_ARCH_IA_64
+	KEXEC_ARCH_MIPS                          = sys.KEXEC_ARCH_MIPS
+	KEXEC_ARCH_MIPS_LE                       = sys.KEXEC_ARCH_MIPS_LE
+	KEXEC_ARCH_PPC                           = sys.KEXEC_ARCH_PPC
+	KEXEC_ARCH_PPC64                         = sys.KEXEC_ARCH_PPC64
+	KEXEC_ARCH_S390                          = sys.KEXEC_ARCH_S390
+	KEXEC_ARCH_SH                            = sys.KEXEC_ARCH_SH
+	KEXEC_ARCH_X86_64                        = sys.KEXEC_ARCH_X86_64
+	KEXEC_ON_CRASH                           = sys.KEXEC_ON_CRASH
+	KEXEC_PRESERVE_CONTEXT                   = sys.KEXEC_PRESERVE_CONTEXT
+	KEYCTL_ASSUME_AUTHORITY                  = sys.KEYCTL_ASSUME_AUTHORITY
+	KEYCTL_CHOWN                             = sys.KEYCTL_CHOWN
+	KEYCTL_CLEAR                             = sys.KEYCTL_CLEAR
+	KEYCTL_DESCRIBE                          = sys.KEYCTL_DESCRIBE
+	KEYCTL_GET_KEYRING_ID                    = sys.KEYCTL_GET_KEYRING_ID
+	KEYCTL_GET_PERSISTENT                    = sys.KEYCTL_GET_PERSISTENT
+	KEYCTL_GET_SECURITY                      = sys.KEYCTL_GET_SECURITY
+	KEYCTL_INSTANTIATE                       = sys.KEYCTL_INSTANTIATE
+	KEYCTL_INSTANTIATE_IOV                   = sys.KEYCTL_INSTANTIATE_IOV
+	KEYCTL_INVALIDATE                        = sys.KEYCTL_INVALIDATE
+	KEYCTL_JOIN_SESSION_KEYRING              = sys.KEYCTL_JOIN_SESSION_KEYRING
+	KEYCTL_LINK                              = sys.KEYCTL_LINK
+	KEYCTL_NEGATE                            = sys.KEYCTL_NEGATE
+	KEYCTL_READ                              = sys.KEYCTL_READ
+	KEYCTL_REJECT                            = sys.KEYCTL_REJECT
+	KEYCTL_REVOKE                            = sys.KEYCTL_REVOKE
+	KEYCTL_SEARCH                            = sys.KEYCTL_SEARCH
+	KEYCTL_SESSION_TO_PARENT                 = sys.KEYCTL_SESSION_TO_PARENT
+	KEYCTL_SETPERM                           = sys.KEYCTL_SETPERM
+	KEYCTL_SET_REQKEY_KEYRING                = sys.KEYCTL_SET_REQKEY_KEYRING
+	KEYCTL_SET_TIMEOUT                       = sys.KEYCTL_SET_TIMEOUT
+	KEYCTL_UNLINK                            = sys.KEYCTL_UNLINK
+	KEYCTL_UPDATE                            = sys.KEYCTL_UPDATE
+	KEY_REQKEY_DEFL_DEFAULT                  = sys.KEY_REQKEY_DEFL_DEFAULT
+	KEY_REQKEY_DEFL_GROUP_KEYRING            = sys.KEY_REQKEY_DEFL_GROUP_KEYRING
+	KEY_REQKEY_DEFL_NO_CHANGE                = sys.KEY_REQKEY_DEFL_NO_CHANGE
+	KEY_REQKEY_DEFL_PROCESS_KEYRING          = sys.KEY_REQKEY_DEFL_PROCESS_KEYRING
+	KEY_REQKEY_DEFL_REQUESTOR_KEYRING        = sys.KEY_REQKEY_DEFL_REQUESTOR_KEYRING
+	KEY_REQKEY_DEFL_SESSION_KEYRING          = sys.KEY_REQKEY_DEFL_SESSION_KEYRING
+	KEY_REQKEY_DEFL_THREAD_KEYRING           = sys.KEY_REQKEY_DEFL_THREAD_KEYRING
+	KEY_REQKEY_DEFL_USER_KEYRING             = sys.KEY_REQKEY_DEFL_USER_KEYRING
+	KEY_REQKEY_DEFL_USER_SESSION_KEYRING     = sys.KEY_REQKEY_DEFL_USER_SESSION_KEYRING
+	KEY_SPEC_PROCESS_KEYRING                 = sys.KEY_SPEC_PROCESS_KEYRING
+	KEY_SPEC_SESSION_KEYRING                 = sys.KEY_SPEC_SESSION_KEYRING
+	KEY_SPEC_THREAD_KEYRING                  = sys.KEY_SPEC_THREAD_KEYRING
+	KEY_SPEC_USER_KEYRING                    = sys.KEY_SPEC_USER_KEYRING
+	KEY_SPEC_USER_SESSION_KEYRING            = sys.KEY_SPEC_USER_SESSION_KEYRING
+	KIOCSOUND                                = sys.KIOCSOUND
+	KVM_ASSIGN_DEV_IRQ                       = sys.KVM_ASSIGN_DEV_IRQ
+	KVM_ASSIGN_PCI_DEVICE                    = sys.KVM_ASSIGN_PCI_DEVICE
+	KVM_ASSIGN_SET_INTX_MASK                 = sys.KVM_ASSIGN_SET_INTX_MASK
+	KVM_ASSIGN_SET_MSIX_ENTRY                = sys.KVM_ASSIGN_SET_MSIX_ENTRY
+	KVM_ASSIGN_SET_MSIX_NR                   = sys.KVM_ASSIGN_SET_MSIX_NR
+	KVM_CHECK_EXTENSION                      = sys.KVM_CHECK_EXTENSION
+	KVM_CREATE_DEVICE                        = sys.KVM_CREATE_DEVICE
+	KVM_CREATE_IRQCHIP                       = sys.KVM_CREATE_IRQCHIP
+	KVM_CREATE_PIT2                          = sys.KVM_CREATE_PIT2
+	KVM_CREATE_VCPU                          = sys.KVM_CREATE_VCPU
+	KVM_CREATE_VM                            = sys.KVM_CREATE_VM
+	KVM_DEASSIGN_DEV_IRQ                     = sys.KVM_DEASSIGN_DEV_IRQ
+	KVM_DEASSIGN_PCI_DEVICE                  = sys.KVM_DEASSIGN_PCI_DEVICE
+	KVM_DEV_IRQ_GUEST_INTX                   = sys.KVM_DEV_IRQ_GUEST_INTX
+	KVM_DEV_IRQ_GUEST_MSI                    = sys.KVM_DEV_IRQ_GUEST_MSI
+	KVM_DEV_IRQ_GUEST_MSIX                   = sys.KVM_DEV_IRQ_GUEST_MSIX
+	KVM_DEV_IRQ_HOST_INTX                    = sys.KVM_DEV_IRQ_HOST_INTX
+	KVM_DEV_IRQ_HOST_MSI                     = sys.KVM_DEV_IRQ_HOST_MSI
+	KVM_DEV_IRQ_HOST_MSIX                    = sys.KVM_DEV_IRQ_HOST_MSIX
+	KVM_DEV_TYPE_FSL_MPIC_20                 = sys.KVM_DEV_TYPE_FSL_MPIC_20
+	KVM_DEV_TYPE_FSL_MPIC_42                 = sys.KVM_DEV_TYPE_FSL_MPIC_42
+	KVM_DEV_TYPE_VFIO                        = sys.KVM_DEV_TYPE_VFIO
+	KVM_DEV_TYPE_XICS                        = sys.KVM_DEV_TYPE_XICS
+	KVM_DIRTY_TLB                            = sys.KVM_DIRTY_TLB
+	KVM_ENABLE_CAP                           = sys.KVM_ENABLE_CAP
+	KVM_GET_CLOCK                            = sys.KVM_GET_CLOCK
+	KVM_GET_DEBUGREGS                        = sys.KVM_GET_DEBUGREGS
+	KVM_GET_DEVICE_ATTR                      = sys.KVM_GET_DEVICE_ATTR
+	KVM_GET_DIRTY_LOG                        = sys.KVM_GET_DIRTY_LOG
+	KVM_GET_EMULATED_CPUID                   = sys.KVM_GET_EMULATED_CPUID
+	KVM_GET_FPU                              = sys.KVM_GET_FPU
+	KVM_GET_IRQCHIP                          = sys.KVM_GET_IRQCHIP
+	KVM_GET_LAPIC                            = sys.KVM_GET_LAPIC
+	KVM_GET_MP_STATE                         = sys.KVM_GET_MP_STATE
+	KVM_GET_MSRS                             = sys.KVM_GET_MSRS
+	KVM_GET_MSR_INDEX_LIST                   = sys.KVM_GET_MSR_INDEX_LIST
+	KVM_GET_ONE_REG                          = sys.KVM_GET_ONE_REG
+	KVM_GET_PIT2                             = sys.KVM_GET_PIT2
+	KVM_GET_REGS                             = sys.KVM_GET_REGS
+	KVM_GET_REG_LIST                         = sys.KVM_GET_REG_LIST
+	KVM_GET_SREGS                            = sys.KVM_GET_SREGS
+	KVM_GET_SUPPORTED_CPUID                  = sys.KVM_GET_SUPPORTED_CPUID
+	KVM_GET_TSC_KHZ                          = sys.KVM_GET_TSC_KHZ
+	KVM_GET_VCPU_EVENTS                      = sys.KVM_GET_VCPU_EVENTS
+	KVM_GET_VCPU_MMAP_SIZE                   = sys.KVM_GET_VCPU_MMAP_SIZE
+	KVM_GET_XCRS                             = sys.KVM_GET_XCRS
+	KVM_GET_XSAVE                            = sys.KVM_GET_XSAVE
+	KVM_GUESTDBG_ENABLE                      = sys.KVM_GUESTDBG_ENABLE
+	KVM_GUESTDBG_INJECT_BP                   = sys.KVM_GUESTDBG_INJECT_BP
+	KVM_GUESTDBG_INJECT_DB                   = sys.KVM_GUESTDBG_INJECT_DB
+	KVM_GUESTDBG_SINGLESTEP                  = sys.KVM_GUESTDBG_SINGLESTEP
+	KVM_GUESTDBG_USE_HW_BP                   = sys.KVM_GUESTDBG_USE_HW_BP
+	KVM_GUESTDBG_USE_SW_BP                   = sys.KVM_GUESTDBG_USE_SW_BP
+	KVM_HAS_DEVICE_ATTR                      = sys.KVM_HAS_DEVICE_ATTR
+	KVM_INTERRUPT                            = sys.KVM_INTERRUPT
+	KVM_IOEVENTFD                            = sys.KVM_IOEVENTFD
+	KVM_IOEVENTFD_FLAG_DATAMATCH             = sys.KVM_IOEVENTFD_FLAG_DATAMATCH
+	KVM_IOEVENTFD_FLAG_DEASSIGN              = sys.KVM_IOEVENTFD_FLAG_DEASSIGN
+	KVM_IOEVENTFD_FLAG_PIO                   = sys.KVM_IOEVENTFD_FLAG_PIO
+	KVM_IOEVENTFD_FLAG_VIRTIO_CCW_NOTIFY     = sys.KVM_IOEVENTFD_FLAG_VIRTIO_CCW_NOTIFY
+	KVM_IRQFD                                = sys.KVM_IRQFD
+	KVM_IRQ_LINE                             = sys.KVM_IRQ_LINE
+	KVM_IRQ_ROUTING_IRQCHIP                  = sys.KVM_IRQ_ROUTING_IRQCHIP
+	KVM_IRQ_ROUTING_MSI                      = sys.KVM_IRQ_ROUTING_MSI
+	KVM_KVMCLOCK_CTRL                        = sys.KVM_KVMCLOCK_CTRL
+	KVM_MEMSLOT_INCOHERENT                   = sys.KVM_MEMSLOT_INCOHERENT
+	KVM_MEMSLOT_INVALID                      = sys.KVM_MEMSLOT_INVALID
+	KVM_MEM_LOG_DIRTY_PAGES                  = sys.KVM_MEM_LOG_DIRTY_PAGES
+	KVM_MEM_READONLY                         = sys.KVM_MEM_READONLY
+	KVM_MP_STATE_CHECK_STOP                  = sys.KVM_MP_STATE_CHECK_STOP
+	KVM_MP_STATE_HALTED                      = sys.KVM_MP_STATE_HALTED
+	KVM_MP_STATE_INIT_RECEIVED               = sys.KVM_MP_STATE_INIT_RECEIVED
+	KVM_MP_STATE_LOAD                        = sys.KVM_MP_STATE_LOAD
+	KVM_MP_STATE_OPERATING                   = sys.KVM_MP_STATE_OPERATING
+	KVM_MP_STATE_RUNNABLE                    = sys.KVM_MP_STATE_RUNNABLE
+	KVM_MP_STATE_SIPI_RECEIVED               = sys.KVM_MP_STATE_SIPI_RECEIVED
+	KVM_MP_STATE_STOPPED                     = sys.KVM_MP_STATE_STOPPED
+	KVM_MP_STATE_UNINITIALIZED               = sys.KVM_MP_STATE_UNINITIALIZED
+	KVM_NMI                                  = sys.KVM_NMI
+	KVM_PPC_ALLOCATE_HTAB                    = sys.KVM_PPC_ALLOCATE_HTAB
+	KVM_PPC_GET_PVINFO                       = sys.KVM_PPC_GET_PVINFO
+	KVM_PPC_GET_SMMU_INFO                    = sys.KVM_PPC_GET_SMMU_INFO
+	KVM_RUN                                  = sys.KVM_RUN
+	KVM_S390_INTERRUPT                       = sys.KVM_S390_INTERRUPT
+	KVM_S390_UCAS_MAP                        = sys.KVM_S390_UCAS_MAP
+	KVM_S390_UCAS_UNMAP                      = sys.KVM_S390_UCAS_UNMAP
+	KVM_S390_VCPU_FAULT                      = sys.KVM_S390_VCPU_FAULT
+	KVM_SET_BOOT_CPU_ID                      = sys.KVM_SET_BOOT_CPU_ID
+	KVM_SET_CLOCK                            = sys.KVM_SET_CLOCK
+	KVM_SET_CPUID                            = sys.KVM_SET_CPUID
+	KVM_SET_DEBUGREGS                        = sys.KVM_SET_DEBUGREGS
+	KVM_SET_DEVICE_ATTR                      = sys.KVM_SET_DEVICE_ATTR
+	KVM_SET_FPU                              = sys.KVM_SET_FPU
+	KVM_SET_GSI_ROUTING                      = sys.KVM_SET_GSI_ROUTING
+	KVM_SET_GUEST_DEBUG                      = sys.KVM_SET_GUEST_DEBUG
+	KVM_SET_IDENTITY_MAP_ADDR                = sys.KVM_SET_IDENTITY_MAP_ADDR
+	KVM_SET_IRQCHIP                          = sys.KVM_SET_IRQCHIP
+	KVM_SET_LAPIC                            = sys.KVM_SET_LAPIC
+	KVM_SET_MEMORY_REGION                    = sys.KVM_SET_MEMORY_REGION
+	KVM_SET_MP_STATE                         = sys.KVM_SET_MP_STATE
+	KVM_SET_MSRS                             = sys.KVM_SET_MSRS
+	KVM_SET_ONE_REG                          = sys.KVM_SET_ONE_REG
+	KVM_SET_PIT2                             = sys.KVM_SET_PIT2
+	KVM_SET_REGS                             = sys.KVM_SET_REGS
+	KVM_SET_SIGNAL_MASK                      = sys.KVM_SET_SIGNAL_MASK
+	KVM_SET_SREGS                            = sys.KVM_SET_SREGS
+	KVM_SET_TSC_KHZ                          = sys.KVM_SET_TSC_KHZ
+	KVM_SET_TSS_ADDR                         = sys.KVM_SET_TSS_ADDR
+	KVM_SET_USER_MEMORY_REGION               = sys.KVM_SET_USER_MEMORY_REGION
+	KVM_SET_VCPU_EVENTS                      = sys.KVM_SET_VCPU_EVENTS
+	KVM_SET_XCRS                             = sys.KVM_SET_XCRS
+	KVM_SET_XSAVE                            = sys.KVM_SET_XSAVE
+	KVM_SIGNAL_MSI                           = sys.KVM_SIGNAL_MSI
+	KVM_SMI                                  = sys.KVM_SMI
+	KVM_TRANSLATE                            = sys.KVM_TRANSLATE
+	KVM_XEN_HVM_CONFIG                       = sys.KVM_XEN_HVM_CONFIG
+	L2CAP_CONNINFO                           = sys.L2CAP_CONNINFO
+	L2CAP_LM                                 = sys.L2CAP_LM
+	L2CAP_LM_AUTH                            = sys.L2CAP_LM_AUTH
+	L2CAP_LM_ENCRYPT                         = sys.L2CAP_LM_ENCRYPT
+	L2CAP_LM_FIPS                            = sys.L2CAP_LM_FIPS
+	L2CAP_LM_MASTER                          = sys.L2CAP_LM_MASTER
+	L2CAP_LM_RELIABLE                        = sys.L2CAP_LM_RELIABLE
+	L2CAP_LM_SECURE                          = sys.L2CAP_LM_SECURE
+	L2CAP_LM_TRUSTED                         = sys.L2CAP_LM_TRUSTED
+	L2CAP_OPTIONS                            = sys.L2CAP_OPTIONS
+	LINK_KIND_BOND                           = sys.LINK_KIND_BOND
+	LINK_KIND_BRIDGE                         = sys.LINK_KIND_BRIDGE
+	LINK_KIND_DUMMY                          = sys.LINK_KIND_DUMMY
+	LINK_KIND_IPVLAN                         = sys.LINK_KIND_IPVLAN
+	LINK_KIND_MACVLAN                        = sys.LINK_KIND_MACVLAN
+	LINK_KIND_VETH                           = sys.LINK_KIND_VETH
+	LINK_KIND_VLAN                           = sys.LINK_KIND_VLAN
+	LINK_KIND_VXLAN                          = sys.LINK_KIND_VXLAN
+	LOCK_EX                                  = sys.LOCK_EX
+	LOCK_NB                                  = sys.LOCK_NB
+	LOCK_SH                                  = sys.LOCK_SH
+	LOCK_UN                                  = sys.LOCK_UN
+	MADV_DODUMP                              = sys.MADV_DODUMP
+	MADV_DOFORK                              = sys.MADV_DOFORK
+	MADV_DONTDUMP                            = sys.MADV_DONTDUMP
+	MADV_DONTFORK                            = sys.MADV_DONTFORK
+	MADV_DONTNEED                            = sys.MADV_DONTNEED
+	MADV_HUGEPAGE                            = sys.MADV_HUGEPAGE
+	MADV_HWPOISON                            = sys.MADV_HWPOISON
+	MADV_MERGEABLE                           = sys.MADV_MERGEABLE
+	MADV_NOHUGEPAGE                          = sys.MADV_NOHUGEPAGE
+	MADV_NORMAL                              = sys.MADV_NORMAL
+	MADV_RANDOM                              = sys.MADV_RANDOM
+	MADV_REMOVE                              = sys.MADV_REMOVE
+	MADV_SEQUENTIAL                          = sys.MADV_SEQUENTIAL
+	MADV_SOFT_OFFLINE                        = sys.MADV_SOFT_OFFLINE
+	MADV_UNMERGEABLE                         = sys.MADV_UNMERGEABLE
+	MADV_WILLNEED                            = sys.MADV_WILLNEED
+	MAP_32BIT                                = sys.MAP_32BIT
+	MAP_ANONYMOUS                            = sys.MAP_ANONYMOUS
+	MAP_DENYWRITE                            = sys.MAP_DENYWRITE
+	MAP_EXECUTABLE                           = sys.MAP_EXECUTABLE
+	MAP_FILE                                 = sys.MAP_FILE
+	MAP_FIXED                                = sys.MAP_FIXED
+	MAP_GROWSDOWN                            = sys.MAP_GROWSDOWN
+	MAP_HUGETLB                              = sys.MAP_HUGETLB
+	MAP_LOCKED                               = sys.MAP_LOCKED
+	MAP_NONBLOCK                             = sys.MAP_NONBLOCK
+	MAP_NORESERVE                            = sys.MAP_NORESERVE
+	MAP_POPULATE                             = sys.MAP_POPULATE
+	MAP_PRIVATE                              = sys.MAP_PRIVATE
+	MAP_SHARED                               = sys.MAP_SHARED
+	MAP_STACK                                = sys.MAP_STACK
+	MAP_UNINITIALIZED                        = sys.MAP_UNINITIALIZED
+	MCAST_BLOCK_SOURCE                       = sys.MCAST_BLOCK_SOURCE
+	MCAST_EXCLUDE                            = sys.MCAST_EXCLUDE
+	MCAST_INCLUDE                            = sys.MCAST_INCLUDE
+	MCAST_JOIN_GROUP                         = sys.MCAST_JOIN_GROUP
+	MCAST_JOIN_SOURCE_GROUP                  = sys.MCAST_JOIN_SOURCE_GROUP
+	MCAST_LEAVE_GROUP                        = sys.MCAST_LEAVE_GROUP
+	MCAST_LEAVE_SOURCE_GROUP                 = sys.MCAST_LEAVE_SOURCE_GROUP
+	MCAST_MSFILTER                           = sys.MCAST_MSFILTER
+	MCAST_UNBLOCK_SOURCE                     = sys.MCAST_UNBLOCK_SOURCE
+	MCL_CURRENT                              = sys.MCL_CURRENT
+	MCL_FUTURE                               = sys.MCL_FUTURE
+	MFD_ALLOW_SEALING                        = sys.MFD_ALLOW_SEALING
+	MFD_CLOEXEC                              = sys.MFD_CLOEXEC
+	MLOCK_ONFAULT                            = sys.MLOCK_ONFAULT
+	MMAP_PAGE_ZERO                           = sys.MMAP_PAGE_ZERO
+	MNT_DETACH                               = sys.MNT_DETACH
+	MNT_EXPIRE                               = sys.MNT_EXPIRE
+	MNT_FORCE                                = sys.MNT_FORCE
+	MODULE_INIT_IGNORE_MODVERSIONS           = sys.MODULE_INIT_IGNORE_MODVERSIONS
+	MODULE_INIT_IGNORE_VERMAGIC              = sys.MODULE_INIT_IGNORE_VERMAGIC
+	MPOL_BIND                                = sys.MPOL_BIND
+	MPOL_DEFAULT                             = sys.MPOL_DEFAULT
+	MPOL_F_ADDR                              = sys.MPOL_F_ADDR
+	MPOL_F_MEMS_ALLOWED                      = sys.MPOL_F_MEMS_ALLOWED
+	MPOL_F_NODE                              = sys.MPOL_F_NODE
+	MPOL_F_RELATIVE_NODES                    = sys.MPOL_F_RELATIVE_NODES
+	MPOL_F_STATIC_NODES                      = sys.MPOL_F_STATIC_NODES
+	MPOL_INTERLEAVE                          = sys.MPOL_INTERLEAVE
+	MPOL_MF_MOVE                             = sys.MPOL_MF_MOVE
+	MPOL_MF_MOVE_ALL                         = sys.MPOL_MF_MOVE_ALL
+	MPOL_MF_STRICT                           = sys.MPOL_MF_STRICT
+	MPOL_PREFERRED                           = sys.MPOL_PREFERRED
+	MREMAP_FIXED                             = sys.MREMAP_FIXED
+	MREMAP_MAYMOVE                           = sys.MREMAP_MAYMOVE
+	MSG_CMSG_CLOEXEC                         = sys.MSG_CMSG_CLOEXEC
+	MSG_CONFIRM                              = sys.MSG_CONFIRM
+	MSG_DONTROUTE                            = sys.MSG_DONTROUTE
+	MSG_DONTWAIT                             = sys.MSG_DONTWAIT
+	MSG_EOR                                  = sys.MSG_EOR
+	MSG_ERRQUEUE                             = sys.MSG_ERRQUEUE
+	MSG_EXCEPT                               = sys.MSG_EXCEPT
+	MSG_INFO                                 = sys.MSG_INFO
+	MSG_MORE                                 = sys.MSG_MORE
+	MSG_NOERROR                              = sys.MSG_NOERROR
+	MSG_NOSIGNAL                             = sys.MSG_NOSIGNAL
+	MSG_OOB                                  = sys.MSG_OOB
+	MSG_PEEK                                 = sys.MSG_PEEK
+	MSG_STAT                                 = sys.MSG_STAT
+	MSG_TRUNC                                = sys.MSG_TRUNC
+	MSG_WAITALL                              = sys.MSG_WAITALL
+	MSG_WAITFORONE                           = sys.MSG_WAITFORONE
+	MS_ASYNC                                 = sys.MS_ASYNC
+	MS_BIND                                  = sys.MS_BIND
+	MS_DIRSYNC                               = sys.MS_DIRSYNC
+	MS_INVALIDATE                            = sys.MS_INVALIDATE
+	MS_MANDLOCK                              = sys.MS_MANDLOCK
+	MS_MOVE                                  = sys.MS_MOVE
+	MS_NOATIME                               = sys.MS_NOATIME
+	MS_NODEV                                 = sys.MS_NODEV
+	MS_NODIRATIME                            = sys.MS_NODIRATIME
+	MS_NOEXEC                                = sys.MS_NOEXEC
+	MS_NOSUID                                = sys.MS_NOSUID
+	MS_RDONLY                                = sys.MS_RDONLY
+	MS_RELATIME                              = sys.MS_RELATIME
+	MS_REMOUNT                               = sys.MS_REMOUNT
+	MS_SILENT                                = sys.MS_SILENT
+	MS_STRICTATIME                           = sys.MS_STRICTATIME
+	MS_SYNC                                  = sys.MS_SYNC
+	MS_SYNCHRONOUS                           = sys.MS_SYNCHRONOUS
+	NDA_DST                                  = sys.NDA_DST
+	NDA_IFINDEX                              = sys.NDA_IFINDEX
+	NDA_LLADDR                               = sys.NDA_LLADDR
+	NDA_PORT                                 = sys.NDA_PORT
+	NDA_PROBES                               = sys.NDA_PROBES
+	NDA_VLAN                                 = sys.NDA_VLAN
+	NDA_VNI                                  = sys.NDA_VNI
+	NETLINK_ADD_MEMBERSHIP                   = sys.NETLINK_ADD_MEMBERSHIP
+	NETLINK_AUDIT                            = sys.NETLINK_AUDIT
+	NETLINK_BROADCAST_ERROR                  = sys.NETLINK_BROADCAST_ERROR
+	NETLINK_CAP_ACK                          = sys.NETLINK_CAP_ACK
+	NETLINK_CONNECTOR                        = sys.NETLINK_CONNECTOR
+	NETLINK_CRYPTO                           = sys.NETLINK_CRYPTO
+	NETLINK_DNRTMSG                          = sys.NETLINK_DNRTMSG
+	NETLINK_DROP_MEMBERSHIP                  = sys.NETLINK_DROP_MEMBERSHIP
+	NETLINK_ECRYPTFS                         = sys.NETLINK_ECRYPTFS
+	NETLINK_FIB_LOOKUP                       = sys.NETLINK_FIB_LOOKUP
+	NETLINK_FIREWALL                         = sys.NETLINK_FIREWALL
+	NETLINK_GENERIC                          = sys.NETLINK_GENERIC
+	NETLINK_INET_DIAG                        = sys.NETLINK_INET_DIAG
+	NETLINK_IP6_FW                           = sys.NETLINK_IP6_FW
+	NETLINK_ISCSI                            = sys.NETLINK_ISCSI
+	NETLINK_KOBJECT_UEVENT                   = sys.NETLINK_KOBJECT_UEVENT
+	NETLINK_LISTEN_ALL_NSID                  = sys.NETLINK_LISTEN_ALL_NSID
+	NETLINK_LIST_MEMBERSHIPS                 = sys.NETLINK_LIST_MEMBERSHIPS
+	NETLINK_NETFILTER                        = sys.NETLINK_NETFILTER
+	NETLINK_NFLOG                            = sys.NETLINK_NFLOG
+	NETLINK_NO_ENOBUFS                       = sys.NETLINK_NO_ENOBUFS
+	NETLINK_PKTINFO                          = sys.NETLINK_PKTINFO
+	NETLINK_RDMA                             = sys.NETLINK_RDMA
+	NETLINK_ROUTE                            = sys.NETLINK_ROUTE
+	NETLINK_RX_RING                          = sys.NETLINK_RX_RING
+	NETLINK_SCSITRANSPORT                    = sys.NETLINK_SCSITRANSPORT
+	NETLINK_SELINUX                          = sys.NETLINK_SELINUX
+	NETLINK_SOCK_DIAG                        = sys.NETLINK_SOCK_DIAG
+	NETLINK_TX_RING                          = sys.NETLINK_TX_RING
+	NETLINK_UNUSED                           = sys.NETLINK_UNUSED
+	NETLINK_USERSOCK                         = sys.NETLINK_USERSOCK
+	NETLINK_XFRM                             = sys.NETLINK_XFRM
+	NETROM_IDLE                              = sys.NETROM_IDLE
+	NETROM_N2                                = sys.NETROM_N2
+	NETROM_T1                                = sys.NETROM_T1
+	NETROM_T2                                = sys.NETROM_T2
+	NETROM_T4                                = sys.NETROM_T4
+	NFC_LLCP_MIUX                            = sys.NFC_LLCP_MIUX
+	NFC_LLCP_REMOTE_LTO                      = sys.NFC_LLCP_REMOTE_LTO
+	NFC_LLCP_REMOTE_MIU                      = sys.NFC_LLCP_REMOTE_MIU
+	NFC_LLCP_REMOTE_RW                       = sys.NFC_LLCP_REMOTE_RW
+	NFC_LLCP_RW                              = sys.NFC_LLCP_RW
+	NFC_PROTO_FELICA                         = sys.NFC_PROTO_FELICA
+	NFC_PROTO_ISO14443                       = sys.NFC_PROTO_ISO14443
+	NFC_PROTO_ISO14443_B                     = sys.NFC_PROTO_ISO14443_B
+	NFC_PROTO_ISO15693                       = sys.NFC_PROTO_ISO15693
+	NFC_PROTO_JEWEL                          = sys.NFC_PROTO_JEWEL
+	NFC_PROTO_MIFARE                         = sys.NFC_PROTO_MIFARE
+	NFC_PROTO_NFC_DEP                        = sys.NFC_PROTO_NFC_DEP
+	NFC_SOCKPROTO_LLCP                       = sys.NFC_SOCKPROTO_LLCP
+	NFC_SOCKPROTO_RAW                        = sys.NFC_SOCKPROTO_RAW
+	NLM_F_ACK                                = sys.NLM_F_ACK
+	NLM_F_APPEND                             = sys.NLM_F_APPEND
+	NLM_F_ATOMIC                             = sys.NLM_F_ATOMIC
+	NLM_F_CREATE                             = sys.NLM_F_CREATE
+	NLM_F_DUMP                               = sys.NLM_F_DUMP
+	NLM_F_DUMP_FILTERED                      = sys.NLM_F_DUMP_FILTERED
+	NLM_F_DUMP_INTR                          = sys.NLM_F_DUMP_INTR
+	NLM_F_ECHO                               = sys.NLM_F_ECHO
+	NLM_F_EXCL                               = sys.NLM_F_EXCL
+	NLM_F_MATCH                              = sys.NLM_F_MATCH
+	NLM_F_MULTI                              = sys.NLM_F_MULTI
+	NLM_F_REPLACE                            = sys.NLM_F_REPLACE
+	NLM_F_REQUEST                            = sys.NLM_F_REQUEST
+	NLM_F_ROOT                               = sys.NLM_F_ROOT
+	NO_CLIENT                                = sys.NO_CLIENT
+	NTF_MASTER                               = sys.NTF_MASTER
+	NTF_PROXY                                = sys.NTF_PROXY
+	NTF_ROUTER                               = sys.NTF_ROUTER
+	NTF_SELF                                 = sys.NTF_SELF
+	NTF_USE                                  = sys.NTF_USE
+	NT_386_IOPERM                            = sys.NT_386_IOPERM
+	NT_386_TLS                               = sys.NT_386_TLS
+	NT_AUXV                                  = sys.NT_AUXV
+	NT_PRFPREG                               = sys.NT_PRFPREG
+	NT_PRPSINFO                              = sys.NT_PRPSINFO
+	NT_PRSTATUS                              = sys.NT_PRSTATUS
+	NT_TASKSTRUCT                            = sys.NT_TASKSTRUCT
+	NT_X86_XSTATE                            = sys.NT_X86_XSTATE
+	NUD_DELAY                                = sys.NUD_DELAY
+	NUD_FAILED                               = sys.NUD_FAILED
+	NUD_INCOMPLETE                           = sys.NUD_INCOMPLETE
+	NUD_NOARP                                = sys.NUD_NOARP
+	NUD_NONE                                 = sys.NUD_NONE
+	NUD_PERMANENT                            = sys.NUD_PERMANENT
+	NUD_PROBE                                = sys.NUD_PROBE
+	NUD_REACHABLE                            = sys.NUD_REACHABLE
+	NUD_STALE                                = sys.NUD_STALE
+	N_6PACK                                  = sys.N_6PACK
+	N_AX25                                   = sys.N_AX25
+	N_CAIF                                   = sys.N_CAIF
+	N_GIGASET_M101                           = sys.N_GIGASET_M101
+	N_GSM0710                                = sys.N_GSM0710
+	N_HCI                                    = sys.N_HCI
+	N_HDLC                                   = sys.N_HDLC
+	N_IRDA                                   = sys.N_IRDA
+	N_MASC                                   = sys.N_MASC
+	N_MOUSE                                  = sys.N_MOUSE
+	N_PPP                                    = sys.N_PPP
+	N_PPS                                    = sys.N_PPS
+	N_PROFIBUS_FDL                           = sys.N_PROFIBUS_FDL
+	N_R3964                                  = sys.N_R3964
+	N_SLCAN                                  = sys.N_SLCAN
+	N_SLIP                                   = sys.N_SLIP
+	N_SMSBLOCK                               = sys.N_SMSBLOCK
+	N_STRIP                                  = sys.N_STRIP
+	N_SYNC_PPP                               = sys.N_SYNC_PPP
+	N_TI_WL                                  = sys.N_TI_WL
+	N_TRACEROUTER                            = sys.N_TRACEROUTER
+	N_TRACESINK                              = sys.N_TRACESINK
+	N_TTY                                    = sys.N_TTY
+	N_V253                                   = sys.N_V253
+	N_X25                                    = sys.N_X25
+	O_APPEND                                 = sys.O_APPEND
+	O_CLOEXEC                                = sys.O_CLOEXEC
+	O_CREAT                                  = sys.O_CREAT
+	O_DIRECT                                 = sys.O_DIRECT
+	O_DIRECTORY                              = sys.O_DIRECTORY
+	O_DSYNC                                  = sys.O_DSYNC
+	O_EXCL                                   = sys.O_EXCL
+	O_LARGEFILE                              = sys.O_LARGEFILE
+	O_NOATIME                                = sys.O_NOATIME
+	O_NOCTTY                                 = sys.O_NOCTTY
+	O_NOFOLLOW                               = sys.O_NOFOLLOW
+	O_NONBLOCK                               = sys.O_NONBLOCK
+	O_PATH                                   = sys.O_PATH
+	O_RDONLY                                 = sys.O_RDONLY
+	O_RDWR                                   = sys.O_RDWR
+	O_SYNC                                   = sys.O_SYNC
+	O_TRUNC                                  = sys.O_TRUNC
+	O_WRONLY                                 = sys.O_WRONLY
+	PERF_COUNT_HW_BRANCH_INSTRUCTIONS        = sys.PERF_COUNT_HW_BRANCH_INSTRUCTIONS
+	PERF_COUNT_HW_BRANCH_MISSES              = sys.PERF_COUNT_HW_BRANCH_MISSES
+	PERF_COUNT_HW_BUS_CYCLES                 = sys.PERF_COUNT_HW_BUS_CYCLES
+	PERF_COUNT_HW_CACHE_BPU                  = sys.PERF_COUNT_HW_CACHE_BPU
+	PERF_COUNT_HW_CACHE_DTLB                 = sys.PERF_COUNT_HW_CACHE_DTLB
+	PERF_COUNT_HW_CACHE_ITLB                 = sys.PERF_COUNT_HW_CACHE_ITLB
+	PERF_COUNT_HW_CACHE_L1D                  = sys.PERF_COUNT_HW_CACHE_L1D
+	PERF_COUNT_HW_CACHE_L1I                  = sys.PERF_COUNT_HW_CACHE_L1I
+	PERF_COUNT_HW_CACHE_LL                   = sys.PERF_COUNT_HW_CACHE_LL
+	PERF_COUNT_HW_CACHE_MISSES               = sys.PERF_COUNT_HW_CACHE_MISSES
+	PERF_COUNT_HW_CACHE_NODE                 = sys.PERF_COUNT_HW_CACHE_NODE
+	PERF_COUNT_HW_CACHE_OP_PREFETCH          = sys.PERF_COUNT_HW_CACHE_OP_PREFETCH
+	PERF_COUNT_HW_CACHE_OP_READ              = sys.PERF_COUNT_HW_CACHE_OP_READ
+	PERF_COUNT_HW_CACHE_OP_WRITE             = sys.PERF_COUNT_HW_CACHE_OP_WRITE
+	PERF_COUNT_HW_CACHE_REFERENCES           = sys.PERF_COUNT_HW_CACHE_REFERENCES
+	PERF_COUNT_HW_CACHE_RESULT_ACCESS        = sys.PERF_COUNT_HW_CACHE_RESULT_ACCESS
+	PERF_COUNT_HW_CACHE_RESULT_MISS          = sys.PERF_COUNT_HW_CACHE_RESULT_MISS
+	PERF_COUNT_HW_CPU_CYCLES                 = sys.PERF_COUNT_HW_CPU_CYCLES
+	PERF_COUNT_HW_INSTRUCTIONS               = sys.PERF_COUNT_HW_INSTRUCTIONS
+	PERF_COUNT_HW_REF_CPU_CYCLES             = sys.PERF_COUNT_HW_REF_CPU_CYCLES
+	PERF_COUNT_HW_STALLED_CYCLES_BACKEND     = sys.PERF_COUNT_HW_STALLED_CYCLES_BACKEND
+	PERF_COUNT_HW_STALLED_CYCLES_FRONTEND    = sys.PERF_COUNT_HW_STALLED_CYCLES_FRONTEND
+	PERF_COUNT_SW_ALIGNMENT_FAULTS           = sys.PERF_COUNT_SW_ALIGNMENT_FAULTS
+	PERF_COUNT_SW_CONTEXT_SWITCHES           = sys.PERF_COUNT_SW_CONTEXT_SWITCHES
+	PERF_COUNT_SW_CPU_CLOCK                  = sys.PERF_COUNT_SW_CPU_CLOCK
+	PERF_COUNT_SW_CPU_MIGRATIONS             = sys.PERF_COUNT_SW_CPU_MIGRATIONS
+	PERF_COUNT_SW_DUMMY                      = sys.PERF_COUNT_SW_DUMMY
+	PERF_COUNT_SW_EMULATION_FAULTS           = sys.PERF_COUNT_SW_EMULATION_FAULTS
+	PERF_COUNT_SW_PAGE_FAULTS                = sys.PERF_COUNT_SW_PAGE_FAULTS
+	PERF_COUNT_SW_PAGE_FAULTS_MAJ            = sys.PERF_COUNT_SW_PAGE_FAULTS_MAJ
+	PERF_COUNT_SW_PAGE_FAULTS_MIN            = sys.PERF_COUNT_SW_PAGE_FAULTS_MIN
+	PERF_COUNT_SW_TASK_CLOCK                 = sys.PERF_COUNT_SW_TASK_CLOCK
+	PERF_EVENT_IOC_DISABLE                   = sys.PERF_EVENT_IOC_DISABLE
+	PERF_EVENT_IOC_ENABLE                    = sys.PERF_EVENT_IOC_ENABLE
+	PERF_EVENT_IOC_ID                        = sys.PERF_EVENT_IOC_ID
+	PERF_EVENT_IOC_PERIOD                    = sys.PERF_EVENT_IOC_PERIOD
+	PERF_EVENT_IOC_REFRESH                   = sys.PERF_EVENT_IOC_REFRESH
+	PERF_EVENT_IOC_RESET                     = sys.PERF_EVENT_IOC_RESET
+	PERF_EVENT_IOC_SET_BPF                   = sys.PERF_EVENT_IOC_SET_BPF
+	PERF_EVENT_IOC_SET_FILTER                = sys.PERF_EVENT_IOC_SET_FILTER
+	PERF_EVENT_IOC_SET_OUTPUT                = sys.PERF_EVENT_IOC_SET_OUTPUT
+	PERF_FLAG_FD_CLOEXEC                     = sys.PERF_FLAG_FD_CLOEXEC
+	PERF_FLAG_FD_NO_GROUP                    = sys.PERF_FLAG_FD_NO_GROUP
+	PERF_FLAG_FD_OUTPUT                      = sys.PERF_FLAG_FD_OUTPUT
+	PERF_FLAG_PID_CGROUP                     = sys.PERF_FLAG_PID_CGROUP
+	PERF_TYPE_BREAKPOINT                     = sys.PERF_TYPE_BREAKPOINT
+	PERF_TYPE_HARDWARE                       = sys.PERF_TYPE_HARDWARE
+	PERF_TYPE_HW_CACHE                       = sys.PERF_TYPE_HW_CACHE
+	PERF_TYPE_RAW                            = sys.PERF_TYPE_RAW
+	PERF_TYPE_SOFTWARE                       = sys.PERF_TYPE_SOFTWARE
+	PERF_TYPE_TRACEPOINT                     = sys.PERF_TYPE_TRACEPOINT
+	PER_BSD                                  = sys.PER_BSD
+	PER_HPUX                                 = sys.PER_HPUX
+	PER_IRIX32                               = sys.PER_IRIX32
+	PER_IRIX64                               = sys.PER_IRIX64
+	PER_IRIXN32                              = sys.PER_IRIXN32
+	PER_ISCR4                                = sys.PER_ISCR4
+	PER_LINUX                                = sys.PER_LINUX
+	PER_LINUX32                              = sys.PER_LINUX32
+	PER_OSF4                                 = sys.PER_OSF4
+	PER_OSR5                                 = sys.PER_OSR5
+	PER_RISCOS                               = sys.PER_RISCOS
+	PER_SOLARIS                              = sys.PER_SOLARIS
+	PER_SVR3                                 = sys.PER_SVR3
+	PER_SVR4                                 = sys.PER_SVR4
+	PER_UW7                                  = sys.PER_UW7
+	PER_WYSEV386                             = sys.PER_WYSEV386
+	PER_XENIX                                = sys.PER_XENIX
+	PIO_CMAP                                 = sys.PIO_CMAP
+	PIO_FONT                                 = sys.PIO_FONT
+	PIO_FONTRESET                            = sys.PIO_FONTRESET
+	PIO_FONTX                                = sys.PIO_FONTX
+	PIO_SCRNMAP                              = sys.PIO_SCRNMAP
+	PIO_UNIMAP                               = sys.PIO_UNIMAP
+	PIO_UNIMAPCLR                            = sys.PIO_UNIMAPCLR
+	PIO_UNISCRNMAP                           = sys.PIO_UNISCRNMAP
+	POLLERR                                  = sys.POLLERR
+	POLLHUP                                  = sys.POLLHUP
+	POLLIN                                   = sys.POLLIN
+	POLLOUT                                  = sys.POLLOUT
+	POLLPRI                                  = sys.POLLPRI
+	POLLRDHUP                                = sys.POLLRDHUP
+	POSIX_FADV_DONTNEED                      = sys.POSIX_FADV_DONTNEED
+	POSIX_FADV_NOREUSE                       = sys.POSIX_FADV_NOREUSE
+	POSIX_FADV_NORMAL                        = sys.POSIX_FADV_NORMAL
+	POSIX_FADV_RANDOM                        = sys.POSIX_FADV_RANDOM
+	POSIX_FADV_SEQUENTIAL                    = sys.POSIX_FADV_SEQUENTIAL
+	POSIX_FADV_WILLNEED                      = sys.POSIX_FADV_WILLNEED
+	PPPIOCGCHAN                              = sys.PPPIOCGCHAN
+	PPPIOCGUNIT                              = sys.PPPIOCGUNIT
+	PRIO_PGRP                                = sys.PRIO_PGRP
+	PRIO_PROCESS                             = sys.PRIO_PROCESS
+	PRIO_USER                                = sys.PRIO_USER
+	PROT_EXEC                                = sys.PROT_EXEC
+	PROT_READ                                = sys.PROT_READ
+	PROT_WRITE                               = sys.PROT_WRITE
+	PR_CAPBSET_DROP                          = sys.PR_CAPBSET_DROP
+	PR_CAPBSET_READ                          = sys.PR_CAPBSET_READ
+	PR_ENDIAN_BIG                            = sys.PR_ENDIAN_BIG
+	PR_ENDIAN_LITTLE                         = sys.PR_ENDIAN_LITTLE
+	PR_ENDIAN_PPC_LITTLE                     = sys.PR_ENDIAN_PPC_LITTLE
+	PR_FP_EXC_ASYNC                          = sys.PR_FP_EXC_ASYNC
+	PR_FP_EXC_DISABLED                       = sys.PR_FP_EXC_DISABLED
+	PR_FP_EXC_DIV                            = sys.PR_FP_EXC_DIV
+	PR_FP_EXC_INV                            = sys.PR_FP_EXC_INV
+	PR_FP_EXC_NONRECOV                       = sys.PR_FP_EXC_NONRECOV
+	PR_FP_EXC_OVF                            = sys.PR_FP_EXC_OVF
+	PR_FP_EXC_PRECISE                        = sys.PR_FP_EXC_PRECISE
+	PR_FP_EXC_RES                            = sys.PR_FP_EXC_RES
+	PR_FP_EXC_SW_ENABLE                      = sys.PR_FP_EXC_SW_ENABLE
+	PR_FP_EXC_UND                            = sys.PR_FP_EXC_UND
+	PR_GET_CHILD_SUBREAPER                   = sys.PR_GET_CHILD_SUBREAPER
+	PR_GET_DUMPABLE                          = sys.PR_GET_DUMPABLE
+	PR_GET_ENDIAN                            = sys.PR_GET_ENDIAN
+	PR_GET_FPEMU                             = sys.PR_GET_FPEMU
+	PR_GET_FPEXC                             = sys.PR_GET_FPEXC
+	PR_GET_KEEPCAPS                          = sys.PR_GET_KEEPCAPS
+	PR_GET_NAME                              = sys.PR_GET_NAME
+	PR_GET_NO_NEW_PRIVS                      = sys.PR_GET_NO_NEW_PRIVS
+	PR_GET_PDEATHSIG                         = sys.PR_GET_PDEATHSIG
+	PR_GET_SECCOMP                           = sys.PR_GET_SECCOMP
+	PR_GET_SECUREBITS                        = sys.PR_GET_SECUREBITS
+	PR_GET_TID_ADDRESS                       = sys.PR_GET_TID_ADDRESS
+	PR_GET_TIMERSLACK                        = sys.PR_GET_TIMERSLACK
+	PR_GET_TIMING                            = sys.PR_GET_TIMING
+	PR_GET_TSC                               = sys.PR_GET_TSC
+	PR_GET_UNALIGN                           = sys.PR_GET_UNALIGN
+	PR_MCE_KILL                              = sys.PR_MCE_KILL
+	PR_MCE_KILL_GET                          = sys.PR_MCE_KILL_GET
+	PR_SET_CHILD_SUBREAPER                   = sys.PR_SET_CHILD_SUBREAPER
+	PR_SET_DUMPABLE                          = sys.PR_SET_DUMPABLE
+	PR_SET_ENDIAN                            = sys.PR_SET_ENDIAN
+	PR_SET_FPEMU                             = sys.PR_SET_FPEMU
+	PR_SET_FPEXC                             = sys.PR_SET_FPEXC
+	PR_SET_KEEPCAPS                          = sys.PR_SET_KEEPCAPS
+	PR_SET_MM                                = sys.PR_SET_MM
+	PR_SET_MM_BRK                            = sys.PR_SET_MM_BRK
+	PR_SET_MM_END_CODE                       = sys.PR_SET_MM_END_CODE
+	PR_SET_MM_END_DATA                       = sys.PR_SET_MM_END_DATA
+	PR_SET_MM_START_BRK                      = sys.PR_SET_MM_START_BRK
+	PR_SET_MM_START_CODE                     = sys.PR_SET_MM_START_CODE
+	PR_SET_MM_START_DATA                     = sys.PR_SET_MM_START_DATA
+	PR_SET_MM_START_STACK                    = sys.PR_SET_MM_START_STACK
+	PR_SET_NAME                              = sys.PR_SET_NAME
+	PR_SET_NO_NEW_PRIVS                      = sys.PR_SET_NO_NEW_PRIVS
+	PR_SET_PDEATHSIG                         = sys.PR_SET_PDEATHSIG
+	PR_SET_PTRACER                           = sys.PR_SET_PTRACER
+	PR_SET_SECCOMP                           = sys.PR_SET_SECCOMP
+	PR_SET_SECUREBITS                        = sys.PR_SET_SECUREBITS
+	PR_SET_TIMERSLACK                        = sys.PR_SET_TIMERSLACK
+	PR_SET_TIMING                            = sys.PR_SET_TIMING
+	PR_SET_TSC                               = sys.PR_SET_TSC
+	PR_SET_UNALIGN                           = sys.PR_SET_UNALIGN
+	PR_TASK_PERF_EVENTS_DISABLE              = sys.PR_TASK_PERF_EVENTS_DISABLE
+	PR_TASK_PERF_EVENTS_ENABLE               = sys.PR_TASK_PERF_EVENTS_ENABLE
+	PTRACE_ATTACH                            = sys.PTRACE_ATTACH
+	PTRACE_CONT                              = sys.PTRACE_CONT
+	PTRACE_DETACH                            = sys.PTRACE_DETACH
+	PTRACE_GETEVENTMSG                       = sys.PTRACE_GETEVENTMSG
+	PTRACE_GETFPREGS                         = sys.PTRACE_GETFPREGS
+	PTRACE_GETREGS                           = sys.PTRACE_GETREGS
+	PTRACE_GETREGSET                         = sys.PTRACE_GETREGSET
+	PTRACE_GETSIGINFO                        = sys.PTRACE_GETSIGINFO
+	PTRACE_INTERRUPT                         = sys.PTRACE_INTERRUPT
+	PTRACE_KILL                              = sys.PTRACE_KILL
+	PTRACE_LISTEN                            = sys.PTRACE_LISTEN
+	PTRACE_O_EXITKILL                        = sys.PTRACE_O_EXITKILL
+	PTRACE_O_TRACECLONE                      = sys.PTRACE_O_TRACECLONE
+	PTRACE_O_TRACEEXEC                       = sys.PTRACE_O_TRACEEXEC
+	PTRACE_O_TRACEEXIT                       = sys.PTRACE_O_TRACEEXIT
+	PTRACE_O_TRACEFORK                       = sys.PTRACE_O_TRACEFORK
+	PTRACE_O_TRACESYSGOOD                    = sys.PTRACE_O_TRACESYSGOOD
+	PTRACE_O_TRACEVFORK                      = sys.PTRACE_O_TRACEVFORK
+	PTRACE_O_TRACEVFORKDONE                  = sys.PTRACE_O_TRACEVFORKDONE
+	PTRACE_PEEKDATA                          = sys.PTRACE_PEEKDATA
+	PTRACE_PEEKTEXT                          = sys.PTRACE_PEEKTEXT
+	PTRACE_PEEKUSR                           = sys.PTRACE_PEEKUSR
+	PTRACE_POKEDATA                          = sys.PTRACE_POKEDATA
+	PTRACE_POKETEXT                          = sys.PTRACE_POKETEXT
+	PTRACE_POKEUSR                           = sys.PTRACE_POKEUSR
+	PTRACE_SEIZE                             = sys.PTRACE_SEIZE
+	PTRACE_SETFPREGS                         = sys.PTRACE_SETFPREGS
+	PTRACE_SETOPTIONS                        = sys.PTRACE_SETOPTIONS
+	PTRACE_SETREGS                           = sys.PTRACE_SETREGS
+	PTRACE_SETREGSET                         = sys.PTRACE_SETREGSET
+	PTRACE_SETSIGINFO                        = sys.PTRACE_SETSIGINFO
+	PTRACE_SINGLESTEP                        = sys.PTRACE_SINGLESTEP
+	PTRACE_SYSCALL                           = sys.PTRACE_SYSCALL
+	PTRACE_SYSEMU                            = sys.PTRACE_SYSEMU
+	PTRACE_SYSEMU_SINGLESTEP                 = sys.PTRACE_SYSEMU_SINGLESTEP
+	PTRACE_TRACEME                           = sys.PTRACE_TRACEME
+	P_ALL                                    = sys.P_ALL
+	P_PGID                                   = sys.P_PGID
+	P_PID                                    = sys.P_PID
+	READ_IMPLIES_EXEC                        = sys.READ_IMPLIES_EXEC
+	RENAME_EXCHANGE                          = sys.RENAME_EXCHANGE
+	RENAME_NOREPLACE                         = sys.RENAME_NOREPLACE
+	RENAME_WHITEOUT                          = sys.RENAME_WHITEOUT
+	RFCOMM_CONNINFO                          = sys.RFCOMM_CONNINFO
+	RFCOMM_LM                                = sys.RFCOMM_LM
+	RLIMIT_AS                                = sys.RLIMIT_AS
+	RLIMIT_CORE                              = sys.RLIMIT_CORE
+	RLIMIT_CPU                               = sys.RLIMIT_CPU
+	RLIMIT_DATA                              = sys.RLIMIT_DATA
+	RLIMIT_FSIZE                             = sys.RLIMIT_FSIZE
+	RLIMIT_LOCKS                             = sys.RLIMIT_LOCKS
+	RLIMIT_MEMLOCK                           = sys.RLIMIT_MEMLOCK
+	RLIMIT_MSGQUEUE                          = sys.RLIMIT_MSGQUEUE
+	RLIMIT_NICE                              = sys.RLIMIT_NICE
+	RLIMIT_NOFILE                            = sys.RLIMIT_NOFILE
+	RLIMIT_NPROC                             = sys.RLIMIT_NPROC
+	RLIMIT_RSS                               = sys.RLIMIT_RSS
+	RLIMIT_RTPRIO                            = sys.RLIMIT_RTPRIO
+	RLIMIT_RTTIME                            = sys.RLIMIT_RTTIME
+	RLIMIT_SIGPENDING                        = sys.RLIMIT_SIGPENDING
+	RLIMIT_STACK                             = sys.RLIMIT_STACK
+	RNDADDENTROPY                            = sys.RNDADDENTROPY
+	RNDADDTOENTCNT                           = sys.RNDADDTOENTCNT
+	RNDCLEARPOOL                             = sys.RNDCLEARPOOL
+	RNDGETENTCNT                             = sys.RNDGETENTCNT
+	RNDZAPENTCNT                             = sys.RNDZAPENTCNT
+	RTAX_ADVMSS                              = sys.RTAX_ADVMSS
+	RTAX_FEATURES                            = sys.RTAX_FEATURES
+	RTAX_HOPLIMIT                            = sys.RTAX_HOPLIMIT
+	RTAX_INITCWND                            = sys.RTAX_INITCWND
+	RTAX_MTU                                 = sys.RTAX_MTU
+	RTAX_RTT                                 = sys.RTAX_RTT
+	RTAX_WINDOW                              = sys.RTAX_WINDOW
+	RTA_DST                                  = sys.RTA_DST
+	RTA_FLOW                                 = sys.RTA_FLOW
+	RTA_GATEWAY                              = sys.RTA_GATEWAY
+	RTA_IIF                                  = sys.RTA_IIF
+	RTA_MARK                                 = sys.RTA_MARK
+	RTA_METRICS                              = sys.RTA_METRICS
+	RTA_OIF                                  = sys.RTA_OIF
+	RTA_PREFSRC                              = sys.RTA_PREFSRC
+	RTA_PRIORITY                             = sys.RTA_PRIORITY
+	RTA_SRC                                  = sys.RTA_SRC
+	RTA_TABLE                                = sys.RTA_TABLE
+	RTM_DELADDR                              = sys.RTM_DELADDR
+	RTM_DELLINK                              = sys.RTM_DELLINK
+	RTM_DELNEIGH                             = sys.RTM_DELNEIGH
+	RTM_DELROUTE                             = sys.RTM_DELROUTE
+	RTM_F_CLONED                             = sys.RTM_F_CLONED
+	RTM_F_EQUALIZE                           = sys.RTM_F_EQUALIZE
+	RTM_F_NOTIFY                             = sys.RTM_F_NOTIFY
+	RTM_F_PREFIX                             = sys.RTM_F_PREFIX
+	RTM_GETADDR                              = sys.RTM_GETADDR
+	RTM_GETLINK                              = sys.RTM_GETLINK
+	RTM_GETNEIGH                             = sys.RTM_GETNEIGH
+	RTM_GETROUTE                             = sys.RTM_GETROUTE
+	RTM_NEWADDR                              = sys.RTM_NEWADDR
+	RTM_NEWLINK                              = sys.RTM_NEWLINK
+	RTM_NEWNEIGH                             = sys.RTM_NEWNEIGH
+	RTM_NEWROUTE                             = sys.RTM_NEWROUTE
+	RTM_SETLINK                              = sys.RTM_SETLINK
+	RTN_ANYCAST                              = sys.RTN_ANYCAST
+	RTN_BLACKHOLE                            = sys.RTN_BLACKHOLE
+	RTN_BROADCAST                            = sys.RTN_BROADCAST
+	RTN_LOCAL                                = sys.RTN_LOCAL
+	RTN_MULTICAST                            = sys.RTN_MULTICAST
+	RTN_NAT                                  = sys.RTN_NAT
+	RTN_PROHIBIT                             = sys.RTN_PROHIBIT
+	RTN_THROW                                = sys.RTN_THROW
+	RTN_UNICAST                              = sys.RTN_UNICAST
+	RTN_UNREACHABLE                          = sys.RTN_UNREACHABLE
+	RTN_UNSPEC                               = sys.RTN_UNSPEC
+	RTN_XRESOLVE                             = sys.RTN_XRESOLVE
+	RTPROT_BOOT                              = sys.RTPROT_BOOT
+	RTPROT_KERNEL                            = sys.RTPROT_KERNEL
+	RTPROT_REDIRECT                          = sys.RTPROT_REDIRECT
+	RTPROT_STATIC                            = sys.RTPROT_STATIC
+	RTPROT_UNSPEC                            = sys.RTPROT_UNSPEC
+	RT_SCOPE_HOST                            = sys.RT_SCOPE_HOST
+	RT_SCOPE_LINK                            = sys.RT_SCOPE_LINK
+	RT_SCOPE_NOWHERE                         = sys.RT_SCOPE_NOWHERE
+	RT_SCOPE_SITE                            = sys.RT_SCOPE_SITE
+	RT_SCOPE_UNIVERSE                        = sys.RT_SCOPE_UNIVERSE
+	RT_TABLE_COMPAT                          = sys.RT_TABLE_COMPAT
+	RT_TABLE_DEFAULT                         = sys.RT_TABLE_DEFAULT
+	RT_TABLE_LOCAL                           = sys.RT_TABLE_LOCAL
+	RT_TABLE_MAIN                            = sys.RT_TABLE_MAIN
+	RT_TABLE_UNSPEC                          = sys.RT_TABLE_UNSPEC
+	RUSAGE_CHILDREN                          = sys.RUSAGE_CHILDREN
+	RUSAGE_SELF                              = sys.RUSAGE_SELF
+	RUSAGE_THREAD                            = sys.RUSAGE_THREAD
+	SA_NOCLDSTOP                             = sys.SA_NOCLDSTOP
+	SA_NOCLDWAIT                             = sys.SA_NOCLDWAIT
+	SA_NODEFER                               = sys.SA_NODEFER
+	SA_ONSTACK                               = sys.SA_ONSTACK
+	SA_RESETHAND                             = sys.SA_RESETHAND
+	SA_RESTART                               = sys.SA_RESTART
+	SA_SIGINFO                               = sys.SA_SIGINFO
+	SCHED_BATCH                              = sys.SCHED_BATCH
+	SCHED_DEADLINE                           = sys.SCHED_DEADLINE
+	SCHED_FIFO                               = sys.SCHED_FIFO
+	SCHED_FLAG_RESET_ON_FORK                 = sys.SCHED_FLAG_RESET_ON_FORK
+	SCHED_IDLE                               = sys.SCHED_IDLE
+	SCHED_NORMAL                             = sys.SCHED_NORMAL
+	SCHED_RR                                 = sys.SCHED_RR
+	SCM_CREDENTIALS                          = sys.SCM_CREDENTIALS
+	SCM_RIGHTS                               = sys.SCM_RIGHTS
+	SCO_CONNINFO                             = sys.SCO_CONNINFO
+	SCO_OPTIONS                              = sys.SCO_OPTIONS
+	SCTP_ABORT                               = sys.SCTP_ABORT
+	SCTP_ADAPTATION_LAYER                    = sys.SCTP_ADAPTATION_LAYER
+	SCTP_ADDR_OVER                           = sys.SCTP_ADDR_OVER
+	SCTP_ASSOCINFO                           = sys.SCTP_ASSOCINFO
+	SCTP_AUTH_ACTIVE_KEY                     = sys.SCTP_AUTH_ACTIVE_KEY
+	SCTP_AUTH_CHUNK                          = sys.SCTP_AUTH_CHUNK
+	SCTP_AUTH_DELETE_KEY                     = sys.SCTP_AUTH_DELETE_KEY
+	SCTP_AUTH_KEY                            = sys.SCTP_AUTH_KEY
+	SCTP_AUTOCLOSE                           = sys.SCTP_AUTOCLOSE
+	SCTP_AUTO_ASCONF                         = sys.SCTP_AUTO_ASCONF
+	SCTP_CONTEXT                             = sys.SCTP_CONTEXT
+	SCTP_DEFAULT_SEND_PARAM                  = sys.SCTP_DEFAULT_SEND_PARAM
+	SCTP_DEFAULT_SNDINFO                     = sys.SCTP_DEFAULT_SNDINFO
+	SCTP_DELAYED_SACK                        = sys.SCTP_DELAYED_SACK
+	SCTP_DISABLE_FRAGMENTS                   = sys.SCTP_DISABLE_FRAGMENTS
+	SCTP_EOF                                 = sys.SCTP_EOF
+	SCTP_EVENTS                              = sys.SCTP_EVENTS
+	SCTP_FRAGMENT_INTERLEAVE                 = sys.SCTP_FRAGMENT_INTERLEAVE
+	SCTP_GET_ASSOC_ID_LIST                   = sys.SCTP_GET_ASSOC_ID_LIST
+	SCTP_GET_ASSOC_NUMBER                    = sys.SCTP_GET_ASSOC_NUMBER
+	SCTP_GET_ASSOC_STATS                     = sys.SCTP_GET_ASSOC_STATS
+	SCTP_GET_LOCAL_ADDRS                     = sys.SCTP_GET_LOCAL_ADDRS
+	SCTP_GET_PEER_ADDRS                      = sys.SCTP_GET_PEER_ADDRS
+	SCTP_GET_PEER_ADDR_INFO                  = sys.SCTP_GET_PEER_ADDR_INFO
+	SCTP_HMAC_IDENT                          = sys.SCTP_HMAC_IDENT
+	SCTP_INIT                                = sys.SCTP_INIT
+	SCTP_INITMSG                             = sys.SCTP_INITMSG
+	SCTP_I_WANT_MAPPED_V4_ADDR               = sys.SCTP_I_WANT_MAPPED_V4_ADDR
+	SCTP_LOCAL_AUTH_CHUNKS                   = sys.SCTP_LOCAL_AUTH_CHUNKS
+	SCTP_MAXSEG                              = sys.SCTP_MAXSEG
+	SCTP_MAX_BURST                           = sys.SCTP_MAX_BURST
+	SCTP_NODELAY                             = sys.SCTP_NODELAY
+	SCTP_PARTIAL_DELIVERY_POINT              = sys.SCTP_PARTIAL_DELIVERY_POINT
+	SCTP_PEER_ADDR_PARAMS                    = sys.SCTP_PEER_ADDR_PARAMS
+	SCTP_PEER_ADDR_THLDS                     = sys.SCTP_PEER_ADDR_THLDS
+	SCTP_PEER_AUTH_CHUNKS                    = sys.SCTP_PEER_AUTH_CHUNKS
+	SCTP_PRIMARY_ADDR                        = sys.SCTP_PRIMARY_ADDR
+	SCTP_RECVNXTINFO                         = sys.SCTP_RECVNXTINFO
+	SCTP_RECVRCVINFO                         = sys.SCTP_RECVRCVINFO
+	SCTP_RTOINFO                             = sys.SCTP_RTOINFO
+	SCTP_SET_PEER_PRIMARY_ADDR               = sys.SCTP_SET_PEER_PRIMARY_ADDR
+	SCTP_SNDINFO                             = sys.SCTP_SNDINFO
+	SCTP_SNDRCV                              = sys.SCTP_SNDRCV
+	SCTP_SOCKOPT_BINDX_ADD                   = sys.SCTP_SOCKOPT_BINDX_ADD
+	SCTP_SOCKOPT_BINDX_REM                   = sys.SCTP_SOCKOPT_BINDX_REM
+	SCTP_SOCKOPT_CONNECTX                    = sys.SCTP_SOCKOPT_CONNECTX
+	SCTP_SOCKOPT_CONNECTX3                   = sys.SCTP_SOCKOPT_CONNECTX3
+	SCTP_SOCKOPT_CONNECTX_OLD                = sys.SCTP_SOCKOPT_CONNECTX_OLD
+	SCTP_SOCKOPT_PEELOFF                     = sys.SCTP_SOCKOPT_PEELOFF
+	SCTP_STATUS                              = sys.SCTP_STATUS
+	SCTP_UNORDERED                           = sys.SCTP_UNORDERED
+	SECCOMP_FILTER_FLAG_TSYNC                = sys.SECCOMP_FILTER_FLAG_TSYNC
+	SECCOMP_MODE_DISABLED                    = sys.SECCOMP_MODE_DISABLED
+	SECCOMP_MODE_FILTER                      = sys.SECCOMP_MODE_FILTER
+	SECCOMP_MODE_STRICT                      = sys.SECCOMP_MODE_STRICT
+	SECCOMP_RET_ALLOW                        = sys.SECCOMP_RET_ALLOW
+	SECCOMP_RET_ERRNO                        = sys.SECCOMP_RET_ERRNO
+	SECCOMP_RET_TRAP                         = sys.SECCOMP_RET_TRAP
+	SECCOMP_SET_MODE_FILTER                  = sys.SECCOMP_SET_MODE_FILTER
+	SECCOMP_SET_MODE_STRICT                  = sys.SECCOMP_SET_MODE_STRICT
+	SEEK_CUR                                 = sys.SEEK_CUR
+	SEEK_DATA                                = sys.SEEK_DATA
+	SEEK_END                                 = sys.SEEK_END
+	SEEK_HOLE                                = sys.SEEK_HOLE
+	SEEK_SET                                 = sys.SEEK_SET
+	SEM_INFO                                 = sys.SEM_INFO
+	SEM_STAT                                 = sys.SEM_STAT
+	SEM_UNDO                                 = sys.SEM_UNDO
+	SETALL                                   = sys.SETALL
+	SETVAL                                   = sys.SETVAL
+	SFD_CLOEXEC                              = sys.SFD_CLOEXEC
+	SFD_NONBLOCK                             = sys.SFD_NONBLOCK
+	SHM_HUGETLB                              = sys.SHM_HUGETLB
+	SHM_INFO                                 = sys.SHM_INFO
+	SHM_LOCK                                 = sys.SHM_LOCK
+	SHM_NORESERVE                            = sys.SHM_NORESERVE
+	SHM_RDONLY                               = sys.SHM_RDONLY
+	SHM_REMAP                                = sys.SHM_REMAP
+	SHM_RND                                  = sys.SHM_RND
+	SHM_STAT                                 = sys.SHM_STAT
+	SHM_UNLOCK                               = sys.SHM_UNLOCK
+	SHORT_INODE                              = sys.SHORT_INODE
+	SHUT_RD                                  = sys.SHUT_RD
+	SHUT_WR                                  = sys.SHUT_WR
+	SIGEV_NONE                               = sys.SIGEV_NONE
+	SIGEV_SIGNAL                             = sys.SIGEV_SIGNAL
+	SIGEV_THREAD                             = sys.SIGEV_THREAD
+	SIG_BLOCK                                = sys.SIG_BLOCK
+	SIG_SETMASK                              = sys.SIG_SETMASK
+	SIG_UNBLOCK                              = sys.SIG_UNBLOCK
+	SIOCADDRT                                = sys.SIOCADDRT
+	SIOCGIFHWADDR                            = sys.SIOCGIFHWADDR
+	SIOCGIFNAME                              = sys.SIOCGIFNAME
+	SIOCGSTAMP                               = sys.SIOCGSTAMP
+	SIOCGSTAMPNS                             = sys.SIOCGSTAMPNS
+	SIOCINQ                                  = sys.SIOCINQ
+	SIOCKCMATTACH                            = sys.SIOCKCMATTACH
+	SIOCKCMCLONE                             = sys.SIOCKCMCLONE
+	SIOCKCMUNATTACH                          = sys.SIOCKCMUNATTACH
+	SIOCOUTQ                                 = sys.SIOCOUTQ
+	SIOCSIFHWADDR                            = sys.SIOCSIFHWADDR
+	SNDRV_CTL_ELEM_IFACE_CARD                = sys.SNDRV_CTL_ELEM_IFACE_CARD
+	SNDRV_CTL_ELEM_IFACE_HWDEP               = sys.SNDRV_CTL_ELEM_IFACE_HWDEP
+	SNDRV_CTL_ELEM_IFACE_MIXER               = sys.SNDRV_CTL_ELEM_IFACE_MIXER
+	SNDRV_CTL_ELEM_IFACE_PCM                 = sys.SNDRV_CTL_ELEM_IFACE_PCM
+	SNDRV_CTL_ELEM_IFACE_RAWMIDI             = sys.SNDRV_CTL_ELEM_IFACE_RAWMIDI
+	SNDRV_CTL_ELEM_IFACE_SEQUENCER           = sys.SNDRV_CTL_ELEM_IFACE_SEQUENCER
+	SNDRV_CTL_ELEM_IFACE_TIMER               = sys.SNDRV_CTL_ELEM_IFACE_TIMER
+	SNDRV_CTL_IOCTL_CARD_INFO                = sys.SNDRV_CTL_IOCTL_CARD_INFO
+	SNDRV_CTL_IOCTL_ELEM_ADD                 = sys.SNDRV_CTL_IOCTL_ELEM_ADD
+	SNDRV_CTL_IOCTL_ELEM_INFO                = sys.SNDRV_CTL_IOCTL_ELEM_INFO
+	SNDRV_CTL_IOCTL_ELEM_LIST                = sys.SNDRV_CTL_IOCTL_ELEM_LIST
+	SNDRV_CTL_IOCTL_ELEM_LOCK                = sys.SNDRV_CTL_IOCTL_ELEM_LOCK
+	SNDRV_CTL_IOCTL_ELEM_READ                = sys.SNDRV_CTL_IOCTL_ELEM_READ
+	SNDRV_CTL_IOCTL_ELEM_REMOVE              = sys.SNDRV_CTL_IOCTL_ELEM_REMOVE
+	SNDRV_CTL_IOCTL_ELEM_REPLACE             = sys.SNDRV_CTL_IOCTL_ELEM_REPLACE
+	SNDRV_CTL_IOCTL_ELEM_UNLOCK              = sys.SNDRV_CTL_IOCTL_ELEM_UNLOCK
+	SNDRV_CTL_IOCTL_ELEM_WRITE               = sys.SNDRV_CTL_IOCTL_ELEM_WRITE
+	SNDRV_CTL_IOCTL_HWDEP_INFO               = sys.SNDRV_CTL_IOCTL_HWDEP_INFO
+	SNDRV_CTL_IOCTL_HWDEP_NEXT_DEVICE        = sys.SNDRV_CTL_IOCTL_HWDEP_NEXT_DEVICE
+	SNDRV_CTL_IOCTL_PCM_INFO                 = sys.SNDRV_CTL_IOCTL_PCM_INFO
+	SNDRV_CTL_IOCTL_PCM_NEXT_DEVICE          = sys.SNDRV_CTL_IOCTL_PCM_NEXT_DEVICE
+	SNDRV_CTL_IOCTL_PCM_PREFER_SUBDEVICE     = sys.SNDRV_CTL_IOCTL_PCM_PREFER_SUBDEVICE
+	SNDRV_CTL_IOCTL_POWER_STATE              = sys.SNDRV_CTL_IOCTL_POWER_STATE
+	SNDRV_CTL_IOCTL_PVERSION                 = sys.SNDRV_CTL_IOCTL_PVERSION
+	SNDRV_CTL_IOCTL_RAWMIDI_INFO             = sys.SNDRV_CTL_IOCTL_RAWMIDI_INFO
+	SNDRV_CTL_IOCTL_RAWMIDI_NEXT_DEVICE      = sys.SNDRV_CTL_IOCTL_RAWMIDI_NEXT_DEVICE
+	SNDRV_CTL_IOCTL_RAWMIDI_PREFER_SUBDEVICE = sys.SNDRV_CTL_IOCTL_RAWMIDI_PREFER_SUBDEVICE
+	SNDRV_CTL_IOCTL_SUBSCRIBE_EVENTS         = sys.SNDRV_CTL_IOCTL_SUBSCRIBE_EVENTS
+	SNDRV_CTL_IOCTL_TLV_COMMAND              = sys.SNDRV_CTL_IOCTL_TLV_COMMAND
+	SNDRV_CTL_IOCTL_TLV_READ                 = sys.SNDRV_CTL_IOCTL_TLV_READ
+	SNDRV_CTL_IOCTL_TLV_WRITE                = sys.SNDRV_CTL_IOCTL_TLV_WRITE
+	SNDRV_SEQ_FILTER_BOUNCE                  = sys.SNDRV_SEQ_FILTER_BOUNCE
+	SNDRV_SEQ_FILTER_BROADCAST               = sys.SNDRV_SEQ_FILTER_BROADCAST
+	SNDRV_SEQ_FILTER_MULTICAST               = sys.SNDRV_SEQ_FILTER_MULTICAST
+	SNDRV_SEQ_FILTER_USE_EVENT               = sys.SNDRV_SEQ_FILTER_USE_EVENT
+	SNDRV_SEQ_IOCTL_CLIENT_ID                = sys.SNDRV_SEQ_IOCTL_CLIENT_ID
+	SNDRV_SEQ_IOCTL_CREATE_PORT              = sys.SNDRV_SEQ_IOCTL_CREATE_PORT
+	SNDRV_SEQ_IOCTL_CREATE_QUEUE             = sys.SNDRV_SEQ_IOCTL_CREATE_QUEUE
+	SNDRV_SEQ_IOCTL_DELETE_PORT              = sys.SNDRV_SEQ_IOCTL_DELETE_PORT
+	SNDRV_SEQ_IOCTL_DELETE_QUEUE             = sys.SNDRV_SEQ_IOCTL_DELETE_QUEUE
+	SNDRV_SEQ_IOCTL_GET_CLIENT_INFO          = sys.SNDRV_SEQ_IOCTL_GET_CLIENT_INFO
+	SNDRV_SEQ_IOCTL_GET_CLIENT_POOL          = sys.SNDRV_SEQ_IOCTL_GET_CLIENT_POOL
+	SNDRV_SEQ_IOCTL_GET_NAMED_QUEUE          = sys.SNDRV_SEQ_IOCTL_GET_NAMED_QUEUE
+	SNDRV_SEQ_IOCTL_GET_PORT_INFO            = sys.SNDRV_SEQ_IOCTL_GET_PORT_INFO
+	SNDRV_SEQ_IOCTL_GET_QUEUE_CLIENT         = sys.SNDRV_SEQ_IOCTL_GET_QUEUE_CLIENT
+	SNDRV_SEQ_IOCTL_GET_QUEUE_INFO           = sys.SNDRV_SEQ_IOCTL_GET_QUEUE_INFO
+	SNDRV_SEQ_IOCTL_GET_QUEUE_STATUS         = sys.SNDRV_SEQ_IOCTL_GET_QUEUE_STATUS
+	SNDRV_SEQ_IOCTL_GET_QUEUE_TEMPO          = sys.SNDRV_SEQ_IOCTL_GET_QUEUE_TEMPO
+	SNDRV_SEQ_IOCTL_GET_QUEUE_TIMER          = sys.SNDRV_SEQ_IOCTL_GET_QUEUE_TIMER
+	SNDRV_SEQ_IOCTL_GET_SUBSCRIPTION         = sys.SNDRV_SEQ_IOCTL_GET_SUBSCRIPTION
+	SNDRV_SEQ_IOCTL_PVERSION                 = sys.SNDRV_SEQ_IOCTL_PVERSION
+	SNDRV_SEQ_IOCTL_QUERY_NEXT_CLIENT        = sys.SNDRV_SEQ_IOCTL_QUERY_NEXT_CLIENT
+	SNDRV_SEQ_IOCTL_QUERY_NEXT_PORT          = sys.SNDRV_SEQ_IOCTL_QUERY_NEXT_PORT
+	SNDRV_SEQ_IOCTL_QUERY_SUBS               = sys.SNDRV_SEQ_IOCTL_QUERY_SUBS
+	SNDRV_SEQ_IOCTL_REMOVE_EVENTS            = sys.SNDRV_SEQ_IOCTL_REMOVE_EVENTS
+	SNDRV_SEQ_IOCTL_RUNNING_MODE             = sys.SNDRV_SEQ_IOCTL_RUNNING_MODE
+	SNDRV_SEQ_IOCTL_SET_CLIENT_INFO          = sys.SNDRV_SEQ_IOCTL_SET_CLIENT_INFO
+	SNDRV_SEQ_IOCTL_SET_CLIENT_POOL          = sys.SNDRV_SEQ_IOCTL_SET_CLIENT_POOL
+	SNDRV_SEQ_IOCTL_SET_PORT_INFO            = sys.SNDRV_SEQ_IOCTL_SET_PORT_INFO
+	SNDRV_SEQ_IOCTL_SET_QUEUE_CLIENT         = sys.SNDRV_SEQ_IOCTL_SET_QUEUE_CLIENT
+	SNDRV_SEQ_IOCTL_SET_QUEUE_INFO           = sys.SNDRV_SEQ_IOCTL_SET_QUEUE_INFO
+	SNDRV_SEQ_IOCTL_SET_QUEUE_TEMPO          = sys.SNDRV_SEQ_IOCTL_SET_QUEUE_TEMPO
+	SNDRV_SEQ_IOCTL_SET_QUEUE_TIMER          = sys.SNDRV_SEQ_IOCTL_SET_QUEUE_TIMER
+	SNDRV_SEQ_IOCTL_SUBSCRIBE_PORT           = sys.SNDRV_SEQ_IOCTL_SUBSCRIBE_PORT
+	SNDRV_SEQ_IOCTL_SYSTEM_INFO              = sys.SNDRV_SEQ_IOCTL_SYSTEM_INFO
+	SNDRV_SEQ_IOCTL_UNSUBSCRIBE_PORT         = sys.SNDRV_SEQ_IOCTL_UNSUBSCRIBE_PORT
+	SNDRV_SEQ_PORT_CAP_DUPLEX                = sys.SNDRV_SEQ_PORT_CAP_DUPLEX
+	SNDRV_SEQ_PORT_CAP_NO_EXPORT             = sys.SNDRV_SEQ_PORT_CAP_NO_EXPORT
+	SNDRV_SEQ_PORT_CAP_READ                  = sys.SNDRV_SEQ_PORT_CAP_READ
+	SNDRV_SEQ_PORT_CAP_SUBS_READ             = sys.SNDRV_SEQ_PORT_CAP_SUBS_READ
+	SNDRV_SEQ_PORT_CAP_SUBS_WRITE            = sys.SNDRV_SEQ_PORT_CAP_SUBS_WRITE
+	SNDRV_SEQ_PORT_CAP_SYNC_READ             = sys.SNDRV_SEQ_PORT_CAP_SYNC_READ
+	SNDRV_SEQ_PORT_CAP_SYNC_WRITE            = sys.SNDRV_SEQ_PORT_CAP_SYNC_WRITE
+	SNDRV_SEQ_PORT_CAP_WRITE                 = sys.SNDRV_SEQ_PORT_CAP_WRITE
+	SNDRV_SEQ_PORT_FLG_GIVEN_PORT            = sys.SNDRV_SEQ_PORT_FLG_GIVEN_PORT
+	SNDRV_SEQ_PORT_FLG_TIMESTAMP             = sys.SNDRV_SEQ_PORT_FLG_TIMESTAMP
+	SNDRV_SEQ_PORT_FLG_TIME_REAL             = sys.SNDRV_SEQ_PORT_FLG_TIME_REAL
+	SNDRV_SEQ_PORT_SUBS_EXCLUSIVE            = sys.SNDRV_SEQ_PORT_SUBS_EXCLUSIVE
+	SNDRV_SEQ_PORT_SUBS_TIMESTAMP            = sys.SNDRV_SEQ_PORT_SUBS_TIMESTAMP
+	SNDRV_SEQ_PORT_SUBS_TIME_REAL            = sys.SNDRV_SEQ_PORT_SUBS_TIME_REAL
+	SNDRV_SEQ_PORT_TYPE_APPLICATION          = sys.SNDRV_SEQ_PORT_TYPE_APPLICATION
+	SNDRV_SEQ_PORT_TYPE_DIRECT_SAMPLE        = sys.SNDRV_SEQ_PORT_TYPE_DIRECT_SAMPLE
+	SNDRV_SEQ_PORT_TYPE_HARDWARE             = sys.SNDRV_SEQ_PORT_TYPE_HARDWARE
+	SNDRV_SEQ_PORT_TYPE_MIDI_GENERIC         = sys.SNDRV_SEQ_PORT_TYPE_MIDI_GENERIC
+	SNDRV_SEQ_PORT_TYPE_MIDI_GM              = sys.SNDRV_SEQ_PORT_TYPE_MIDI_GM
+	SNDRV_SEQ_PORT_TYPE_MIDI_GM2             = sys.SNDRV_SEQ_PORT_TYPE_MIDI_GM2
+	SNDRV_SEQ_PORT_TYPE_MIDI_GS              = sys.SNDRV_SEQ_PORT_TYPE_MIDI_GS
+	SNDRV_SEQ_PORT_TYPE_MIDI_MT32            = sys.SNDRV_SEQ_PORT_TYPE_MIDI_MT32
+	SNDRV_SEQ_PORT_TYPE_MIDI_XG              = sys.SNDRV_SEQ_PORT_TYPE_MIDI_XG
+	SNDRV_SEQ_PORT_TYPE_PORT                 = sys.SNDRV_SEQ_PORT_TYPE_PORT
+	SNDRV_SEQ_PORT_TYPE_SAMPLE               = sys.SNDRV_SEQ_PORT_TYPE_SAMPLE
+	SNDRV_SEQ_PORT_TYPE_SOFTWARE             = sys.SNDRV_SEQ_PORT_TYPE_SOFTWARE
+	SNDRV_SEQ_PORT_TYPE_SPECIFIC             = sys.SNDRV_SEQ_PORT_TYPE_SPECIFIC
+	SNDRV_SEQ_PORT_TYPE_SYNTH                = sys.SNDRV_SEQ_PORT_TYPE_SYNTH
+	SNDRV_SEQ_PORT_TYPE_SYNTHESIZER          = sys.SNDRV_SEQ_PORT_TYPE_SYNTHESIZER
+	SNDRV_SEQ_QUERY_SUBS_READ                = sys.SNDRV_SEQ_QUERY_SUBS_READ
+	SNDRV_SEQ_QUERY_SUBS_WRITE               = sys.SNDRV_SEQ_QUERY_SUBS_WRITE
+	SNDRV_SEQ_REMOVE_DEST                    = sys.SNDRV_SEQ_REMOVE_DEST
+	SNDRV_SEQ_REMOVE_DEST_CHANNEL            = sys.SNDRV_SEQ_REMOVE_DEST_CHANNEL
+	SNDRV_SEQ_REMOVE_EVENT_TYPE              = sys.SNDRV_SEQ_REMOVE_EVENT_TYPE
+	SNDRV_SEQ_REMOVE_IGNORE_OFF              = sys.SNDRV_SEQ_REMOVE_IGNORE_OFF
+	SNDRV_SEQ_REMOVE_INPUT                   = sys.SNDRV_SEQ_REMOVE_INPUT
+	SNDRV_SEQ_REMOVE_OUTPUT                  = sys.SNDRV_SEQ_REMOVE_OUTPUT
+	SNDRV_SEQ_REMOVE_TAG_MATCH               = sys.SNDRV_SEQ_REMOVE_TAG_MATCH
+	SNDRV_SEQ_REMOVE_TIME_AFTER              = sys.SNDRV_SEQ_REMOVE_TIME_AFTER
+	SNDRV_SEQ_REMOVE_TIME_BEFORE             = sys.SNDRV_SEQ_REMOVE_TIME_BEFORE
+	SNDRV_SEQ_REMOVE_TIME_TICK               = sys.SNDRV_SEQ_REMOVE_TIME_TICK
+	SNDRV_SEQ_TIMER_ALSA                     = sys.SNDRV_SEQ_TIMER_ALSA
+	SNDRV_SEQ_TIMER_MIDI_CLOCK               = sys.SNDRV_SEQ_TIMER_MIDI_CLOCK
+	SNDRV_SEQ_TIMER_MIDI_TICK                = sys.SNDRV_SEQ_TIMER_MIDI_TICK
+	SNDRV_TIMER_EVENT_CONTINUE               = sys.SNDRV_TIMER_EVENT_CONTINUE
+	SNDRV_TIMER_EVENT_EARLY                  = sys.SNDRV_TIMER_EVENT_EARLY
+	SNDRV_TIMER_EVENT_MCONTINUE              = sys.SNDRV_TIMER_EVENT_MCONTINUE
+	SNDRV_TIMER_EVENT_MPAUSE                 = sys.SNDRV_TIMER_EVENT_MPAUSE
+	SNDRV_TIMER_EVENT_MRESUME                = sys.SNDRV_TIMER_EVENT_MRESUME
+	SNDRV_TIMER_EVENT_MSTART                 = sys.SNDRV_TIMER_EVENT_MSTART
+	SNDRV_TIMER_EVENT_MSTOP                  = sys.SNDRV_TIMER_EVENT_MSTOP
+	SNDRV_TIMER_EVENT_MSUSPEND               = sys.SNDRV_TIMER_EVENT_MSUSPEND
+	SNDRV_TIMER_EVENT_PAUSE                  = sys.SNDRV_TIMER_EVENT_PAUSE
+	SNDRV_TIMER_EVENT_RESOLUTION             = sys.SNDRV_TIMER_EVENT_RESOLUTION
+	SNDRV_TIMER_EVENT_RESUME                 = sys.SNDRV_TIMER_EVENT_RESUME
+	SNDRV_TIMER_EVENT_START                  = sys.SNDRV_TIMER_EVENT_START
+	SNDRV_TIMER_EVENT_STOP                   = sys.SNDRV_TIMER_EVENT_STOP
+	SNDRV_TIMER_EVENT_SUSPEND                = sys.SNDRV_TIMER_EVENT_SUSPEND
+	SNDRV_TIMER_EVENT_TICK                   = sys.SNDRV_TIMER_EVENT_TICK
+	SNDRV_TIMER_IOCTL_CONTINUE               = sys.SNDRV_TIMER_IOCTL_CONTINUE
+	SNDRV_TIMER_IOCTL_GINFO                  = sys.SNDRV_TIMER_IOCTL_GINFO
+	SNDRV_TIMER_IOCTL_GPARAMS                = sys.SNDRV_TIMER_IOCTL_GPARAMS
+	SNDRV_TIMER_IOCTL_GSTATUS                = sys.SNDRV_TIMER_IOCTL_GSTATUS
+	SNDRV_TIMER_IOCTL_INFO                   = sys.SNDRV_TIMER_IOCTL_INFO
+	SNDRV_TIMER_IOCTL_NEXT_DEVICE            = sys.SNDRV_TIMER_IOCTL_NEXT_DEVICE
+	SNDRV_TIMER_IOCTL_PARAMS                 = sys.SNDRV_TIMER_IOCTL_PARAMS
+	SNDRV_TIMER_IOCTL_PAUSE                  = sys.SNDRV_TIMER_IOCTL_PAUSE
+	SNDRV_TIMER_IOCTL_PVERSION               = sys.SNDRV_TIMER_IOCTL_PVERSION
+	SNDRV_TIMER_IOCTL_SELECT                 = sys.SNDRV_TIMER_IOCTL_SELECT
+	SNDRV_TIMER_IOCTL_START                  = sys.SNDRV_TIMER_IOCTL_START
+	SNDRV_TIMER_IOCTL_STATUS                 = sys.SNDRV_TIMER_IOCTL_STATUS
+	SNDRV_TIMER_IOCTL_STOP                   = sys.SNDRV_TIMER_IOCTL_STOP
+	SNDRV_TIMER_IOCTL_TREAD                  = sys.SNDRV_TIMER_IOCTL_TREAD
+	SNDRV_TIMER_PSFLG_AUTO                   = sys.SNDRV_TIMER_PSFLG_AUTO
+	SNDRV_TIMER_PSFLG_EARLY_EVENT            = sys.SNDRV_TIMER_PSFLG_EARLY_EVENT
+	SNDRV_TIMER_PSFLG_EXCLUSIVE              = sys.SNDRV_TIMER_PSFLG_EXCLUSIVE
+	SOCK_CLOEXEC                             = sys.SOCK_CLOEXEC
+	SOCK_DGRAM                               = sys.SOCK_DGRAM
+	SOCK_NONBLOCK                            = sys.SOCK_NONBLOCK
+	SOCK_PACKET                              = sys.SOCK_PACKET
+	SOCK_RAW                                 = sys.SOCK_RAW
+	SOCK_RDM                                 = sys.SOCK_RDM
+	SOCK_SEQPACKET                           = sys.SOCK_SEQPACKET
+	SOCK_STREAM                              = sys.SOCK_STREAM
+	SOF_TIMESTAMPING_OPT_CMSG                = sys.SOF_TIMESTAMPING_OPT_CMSG
+	SOF_TIMESTAMPING_OPT_ID                  = sys.SOF_TIMESTAMPING_OPT_ID
+	SOF_TIMESTAMPING_OPT_TSONLY              = sys.SOF_TIMESTAMPING_OPT_TSONLY
+	SOF_TIMESTAMPING_RAW_HARDWARE            = sys.SOF_TIMESTAMPING_RAW_HARDWARE
+	SOF_TIMESTAMPING_RX_HARDWARE             = sys.SOF_TIMESTAMPING_RX_HARDWARE
+	SOF_TIMESTAMPING_RX_SOFTWARE             = sys.SOF_TIMESTAMPING_RX_SOFTWARE
+	SOF_TIMESTAMPING_SOFTWARE                = sys.SOF_TIMESTAMPING_SOFTWARE
+	SOF_TIMESTAMPING_SYS_HARDWARE            = sys.SOF_TIMESTAMPING_SYS_HARDWARE
+	SOF_TIMESTAMPING_TX_ACK                  = sys.SOF_TIMESTAMPING_TX_ACK
+	SOF_TIMESTAMPING_TX_HARDWARE             = sys.SOF_TIMESTAMPING_TX_HARDWARE
+	SOF_TIMESTAMPING_TX_SCHED                = sys.SOF_TIMESTAMPING_TX_SCHED
+	SOF_TIMESTAMPING_TX_SOFTWARE             = sys.SOF_TIMESTAMPING_TX_SOFTWARE
+	SOL_ALG                                  = sys.SOL_ALG
+	SOL_BLUETOOTH                            = sys.SOL_BLUETOOTH
+	SOL_KCM                                  = sys.SOL_KCM
+	SOL_L2CAP                                = sys.SOL_L2CAP
+	SOL_NETLINK                              = sys.SOL_NETLINK
+	SOL_NETROM                               = sys.SOL_NETROM
+	SOL_NFC                                  = sys.SOL_NFC
+	SOL_RFCOMM                               = sys.SOL_RFCOMM
+	SOL_SCO                                  = sys.SOL_SCO
+	SOL_SCTP                                 = sys.SOL_SCTP
+	SOL_SOCKET                               = sys.SOL_SOCKET
+	SO_ACCEPTCONN                            = sys.SO_ACCEPTCONN
+	SO_ATTACH_BPF                            = sys.SO_ATTACH_BPF
+	SO_ATTACH_FILTER                         = sys.SO_ATTACH_FILTER
+	SO_BINDTODEVICE                          = sys.SO_BINDTODEVICE
+	SO_BROADCAST                             = sys.SO_BROADCAST
+	SO_BUSY_POLL                             = sys.SO_BUSY_POLL
+	SO_DEBUG                                 = sys.SO_DEBUG
+	SO_DETACH_FILTER                         = sys.SO_DETACH_FILTER
+	SO_DOMAIN                                = sys.SO_DOMAIN
+	SO_DONTROUTE                             = sys.SO_DONTROUTE
+	SO_ERROR                                 = sys.SO_ERROR
+	SO_GET_FILTER                            = sys.SO_GET_FILTER
+	SO_KEEPALIVE                             = sys.SO_KEEPALIVE
+	SO_LINGER                                = sys.SO_LINGER
+	SO_LOCK_FILTER                           = sys.SO_LOCK_FILTER
+	SO_MARK                                  = sys.SO_MARK
+	SO_MAX_PACING_RATE                       = sys.SO_MAX_PACING_RATE
+	SO_NOFCS                                 = sys.SO_NOFCS
+	SO_NO_CHECK                              = sys.SO_NO_CHECK
+	SO_OOBINLINE                             = sys.SO_OOBINLINE
+	SO_PASSCRED                              = sys.SO_PASSCRED
+	SO_PASSSEC                               = sys.SO_PASSSEC
+	SO_PEEK_OFF                              = sys.SO_PEEK_OFF
+	SO_PEERCRED                              = sys.SO_PEERCRED
+	SO_PEERNAME                              = sys.SO_PEERNAME
+	SO_PEERSEC                               = sys.SO_PEERSEC
+	SO_PRIORITY                              = sys.SO_PRIORITY
+	SO_PROTOCOL                              = sys.SO_PROTOCOL
+	SO_RCVBUF                                = sys.SO_RCVBUF
+	SO_RCVBUFFORCE                           = sys.SO_RCVBUFFORCE
+	SO_RCVLOWAT                              = sys.SO_RCVLOWAT
+	SO_RCVTIMEO                              = sys.SO_RCVTIMEO
+	SO_REUSEADDR                             = sys.SO_REUSEADDR
+	SO_REUSEPORT                             = sys.SO_REUSEPORT
+	SO_RXQ_OVFL                              = sys.SO_RXQ_OVFL
+	SO_SELECT_ERR_QUEUE                      = sys.SO_SELECT_ERR_QUEUE
+	SO_SNDBUF                                = sys.SO_SNDBUF
+	SO_SNDBUFFORCE                           = sys.SO_SNDBUFFORCE
+	SO_SNDLOWAT                              = sys.SO_SNDLOWAT
+	SO_SNDTIMEO                              = sys.SO_SNDTIMEO
+	SO_TIMESTAMP                             = sys.SO_TIMESTAMP
+	SO_TIMESTAMPING                          = sys.SO_TIMESTAMPING
+	SO_TIMESTAMPNS                           = sys.SO_TIMESTAMPNS
+	SO_TYPE                                  = sys.SO_TYPE
+	SO_WIFI_STATUS                           = sys.SO_WIFI_STATUS
+	SPLICE_F_GIFT                            = sys.SPLICE_F_GIFT
+	SPLICE_F_MORE                            = sys.SPLICE_F_MORE
+	SPLICE_F_MOVE                            = sys.SPLICE_F_MOVE
+	SPLICE_F_NONBLOCK                        = sys.SPLICE_F_NONBLOCK
+	SPP_HB_DEMAND                            = sys.SPP_HB_DEMAND
+	SPP_HB_DISABLE                           = sys.SPP_HB_DISABLE
+	SPP_HB_ENABLE                            = sys.SPP_HB_ENABLE
+	SPP_HB_TIME_IS_ZERO                      = sys.SPP_HB_TIME_IS_ZERO
+	SPP_PMTUD_DISABLE                        = sys.SPP_PMTUD_DISABLE
+	SPP_PMTUD_ENABLE                         = sys.SPP_PMTUD_ENABLE
+	SPP_SACKDELAY_DISABLE                    = sys.SPP_SACKDELAY_DISABLE
+	SPP_SACKDELAY_ENABLE                     = sys.SPP_SACKDELAY_ENABLE
+	STICKY_TIMEOUTS                          = sys.STICKY_TIMEOUTS
+	SYNC_FILE_RANGE_WAIT_AFTER               = sys.SYNC_FILE_RANGE_WAIT_AFTER
+	SYNC_FILE_RANGE_WAIT_BEFORE              = sys.SYNC_FILE_RANGE_WAIT_BEFORE
+	SYNC_FILE_RANGE_WRITE                    = sys.SYNC_FILE_RANGE_WRITE
+	SYSLOG_ACTION_CLEAR                      = sys.SYSLOG_ACTION_CLEAR
+	SYSLOG_ACTION_CLOSE                      = sys.SYSLOG_ACTION_CLOSE
+	SYSLOG_ACTION_CONSOLE_OFF                = sys.SYSLOG_ACTION_CONSOLE_OFF
+	SYSLOG_ACTION_CONSOLE_ON                 = sys.SYSLOG_ACTION_CONSOLE_ON
+	SYSLOG_ACTION_OPEN                       = sys.SYSLOG_ACTION_OPEN
+	SYSLOG_ACTION_READ                       = sys.SYSLOG_ACTION_READ
+	SYSLOG_ACTION_READ_ALL                   = sys.SYSLOG_ACTION_READ_ALL
+	SYSLOG_ACTION_READ_CLEAR                 = sys.SYSLOG_ACTION_READ_CLEAR
+	SYSLOG_ACTION_SIZE_BUFFER                = sys.SYSLOG_ACTION_SIZE_BUFFER
+	SYSLOG_ACTION_SIZE_UNREAD                = sys.SYSLOG_ACTION_SIZE_UNREAD
+	S_IFBLK                                  = sys.S_IFBLK
+	S_IFCHR                                  = sys.S_IFCHR
+	S_IFDIR                                  = sys.S_IFDIR
+	S_IFIFO                                  = sys.S_IFIFO
+	S_IFLNK                                  = sys.S_IFLNK
+	S_IFREG                                  = sys.S_IFREG
+	S_IFSOCK                                 = sys.S_IFSOCK
+	S_IRGRP                                  = sys.S_IRGRP
+	S_IROTH                                  = sys.S_IROTH
+	S_IRUSR                                  = sys.S_IRUSR
+	S_IWGRP                                  = sys.S_IWGRP
+	S_IWOTH                                  = sys.S_IWOTH
+	S_IWUSR                                  = sys.S_IWUSR
+	S_IXGRP                                  = sys.S_IXGRP
+	S_IXOTH                                  = sys.S_IXOTH
+	S_IXUSR                                  = sys.S_IXUSR
+	TCFLSH                                   = sys.TCFLSH
+	TCGETA                                   = sys.TCGETA
+	TCGETS                                   = sys.TCGETS
+	TCP_CORK                                 = sys.TCP_CORK
+	TCP_DEFER_ACCEPT                         = sys.TCP_DEFER_ACCEPT
+	TCP_INFO                                 = sys.TCP_INFO
+	TCP_KEEPCNT                              = sys.TCP_KEEPCNT
+	TCP_KEEPIDLE                             = sys.TCP_KEEPIDLE
+	TCP_KEEPINTVL                            = sys.TCP_KEEPINTVL
+	TCP_LINGER2                              = sys.TCP_LINGER2
+	TCP_MAXSEG                               = sys.TCP_MAXSEG
+	TCP_NODELAY                              = sys.TCP_NODELAY
+	TCP_QUICKACK                             = sys.TCP_QUICKACK
+	TCP_SYNCNT                               = sys.TCP_SYNCNT
+	TCP_WINDOW_CLAMP                         = sys.TCP_WINDOW_CLAMP
+	TCSBRK                                   = sys.TCSBRK
+	TCSBRKP                                  = sys.TCSBRKP
+	TCSETA                                   = sys.TCSETA
+	TCSETAF                                  = sys.TCSETAF
+	TCSETAW                                  = sys.TCSETAW
+	TCSETS                                   = sys.TCSETS
+	TCSETSF                                  = sys.TCSETSF
+	TCSETSW                                  = sys.TCSETSW
+	TCXONC                                   = sys.TCXONC
+	TFD_CLOEXEC                              = sys.TFD_CLOEXEC
+	TFD_NONBLOCK                             = sys.TFD_NONBLOCK
+	TFD_TIMER_ABSTIME                        = sys.TFD_TIMER_ABSTIME
+	TIMER_ABSTIME                            = sys.TIMER_ABSTIME
+	TIOCCBRK                                 = sys.TIOCCBRK
+	TIOCCONS                                 = sys.TIOCCONS
+	TIOCEXCL                                 = sys.TIOCEXCL
+	TIOCGDEV                                 = sys.TIOCGDEV
+	TIOCGETD                                 = sys.TIOCGETD
+	TIOCGEXCL                                = sys.TIOCGEXCL
+	TIOCGLCKTRMIOS                           = sys.TIOCGLCKTRMIOS
+	TIOCGPGRP                                = sys.TIOCGPGRP
+	TIOCGPKT                                 = sys.TIOCGPKT
+	TIOCGPTLCK                               = sys.TIOCGPTLCK
+	TIOCGPTN                                 = sys.TIOCGPTN
+	TIOCGSID                                 = sys.TIOCGSID
+	TIOCGSOFTCAR                             = sys.TIOCGSOFTCAR
+	TIOCGWINSZ                               = sys.TIOCGWINSZ
+	TIOCINQ                                  = sys.TIOCINQ
+	TIOCLINUX                                = sys.TIOCLINUX
+	TIOCMBIC                                 = sys.TIOCMBIC
+	TIOCMBIS                                 = sys.TIOCMBIS
+	TIOCMGET                                 = sys.TIOCMGET
+	TIOCMSET                                 = sys.TIOCMSET
+	TIOCNOTTY                                = sys.TIOCNOTTY
+	TIOCNXCL                                 = sys.TIOCNXCL
+	TIOCOUTQ                                 = sys.TIOCOUTQ
+	TIOCPKT                                  = sys.TIOCPKT
+	TIOCSBRK                                 = sys.TIOCSBRK
+	TIOCSCTTY                                = sys.TIOCSCTTY
+	TIOCSETD                                 = sys.TIOCSETD
+	TIOCSIG                                  = sys.TIOCSIG
+	TIOCSLCKTRMIOS                           = sys.TIOCSLCKTRMIOS
+	TIOCSPGRP                                = sys.TIOCSPGRP
+	TIOCSPTLCK                               = sys.TIOCSPTLCK
+	TIOCSSOFTCAR                             = sys.TIOCSSOFTCAR
+	TIOCSTI                                  = sys.TIOCSTI
+	TIOCSWINSZ                               = sys.TIOCSWINSZ
+	TIOCTTYGSTRUCT                           = sys.TIOCTTYGSTRUCT
+	TIOCVHANGUP                              = sys.TIOCVHANGUP
+	TUNATTACHFILTER                          = sys.TUNATTACHFILTER
+	TUNDETACHFILTER                          = sys.TUNDETACHFILTER
+	TUNGETFEATURES                           = sys.TUNGETFEATURES
+	TUNGETFILTER                             = sys.TUNGETFILTER
+	TUNGETIFF                                = sys.TUNGETIFF
+	TUNGETSNDBUF                             = sys.TUNGETSNDBUF
+	TUNGETVNETHDRSZ                          = sys.TUNGETVNETHDRSZ
+	TUNSETIFF                                = sys.TUNSETIFF
+	TUNSETIFINDEX                            = sys.TUNSETIFINDEX
+	TUNSETLINK                               = sys.TUNSETLINK
+	TUNSETNOCSUM                             = sys.TUNSETNOCSUM
+	TUNSETOFFLOAD                            = sys.TUNSETOFFLOAD
+	TUNSETOWNER                              = sys.TUNSETOWNER
+	TUNSETPERSIST                            = sys.TUNSETPERSIST
+	TUNSETQUEUE                              = sys.TUNSETQUEUE
+	TUNSETSNDBUF                             = sys.TUNSETSNDBUF
+	TUNSETTXFILTER                           = sys.TUNSETTXFILTER
+	TUNSETVNETHDRSZ                          = sys.TUNSETVNETHDRSZ
+	UDP_CORK                                 = sys.UDP_CORK
+	UFFDIO_API                               = sys.UFFDIO_API
+	UFFDIO_COPY_MODE_DONTWAKE                = sys.UFFDIO_COPY_MODE_DONTWAKE
+	UFFDIO_REGISTER                          = sys.UFFDIO_REGISTER
+	UFFDIO_REGISTER_MODE_MISSING             = sys.UFFDIO_REGISTER_MODE_MISSING
+	UFFDIO_REGISTER_MODE_WP                  = sys.UFFDIO_REGISTER_MODE_WP
+	UFFDIO_UNREGISTER                        = sys.UFFDIO_UNREGISTER
+	UFFDIO_WAKE                              = sys.UFFDIO_WAKE
+	UFFDIO_ZEROPAGE_MODE_DONTWAKE            = sys.UFFDIO_ZEROPAGE_MODE_DONTWAKE
+	UMOUNT_NOFOLLOW                          = sys.UMOUNT_NOFOLLOW
+	USB_CLASS_APP_SPEC                       = sys.USB_CLASS_APP_SPEC
+	USB_CLASS_AUDIO                          = sys.USB_CLASS_AUDIO
+	USB_CLASS_CDC_DATA                       = sys.USB_CLASS_CDC_DATA
+	USB_CLASS_COMM                           = sys.USB_CLASS_COMM
+	USB_CLASS_HID                            = sys.USB_CLASS_HID
+	USB_CLASS_HUB                            = sys.USB_CLASS_HUB
+	USB_CLASS_MASS_STORAGE                   = sys.USB_CLASS_MASS_STORAGE
+	USB_CLASS_PER_INTERFACE                  = sys.USB_CLASS_PER_INTERFACE
+	USB_CLASS_PRINTER                        = sys.USB_CLASS_PRINTER
+	USB_CLASS_VENDOR_SPEC                    = sys.USB_CLASS_VENDOR_SPEC
+	USB_CLASS_VIDEO                          = sys.USB_CLASS_VIDEO
+	USB_CLASS_WIRELESS_CONTROLLER            = sys.USB_CLASS_WIRELESS_CONTROLLER
+	USB_CONFIG_ATT_ONE                       = sys.USB_CONFIG_ATT_ONE
+	USB_CONFIG_ATT_SELFPOWER                 = sys.USB_CONFIG_ATT_SELFPOWER
+	USB_CONFIG_ATT_WAKEUP                    = sys.USB_CONFIG_ATT_WAKEUP
+	USB_DT_CONFIG                            = sys.USB_DT_CONFIG
+	USB_DT_CONFIG_SIZE                       = sys.USB_DT_CONFIG_SIZE
+	USB_DT_DEVICE                            = sys.USB_DT_DEVICE
+	USB_DT_DEVICE_SIZE                       = sys.USB_DT_DEVICE_SIZE
+	USB_DT_ENDPOINT                          = sys.USB_DT_ENDPOINT
+	USB_DT_ENDPOINT_SIZE                     = sys.USB_DT_ENDPOINT_SIZE
+	USB_DT_INTERFACE                         = sys.USB_DT_INTERFACE
+	USB_DT_INTERFACE_SIZE                    = sys.USB_DT_INTERFACE_SIZE
+	USB_DT_STRING                            = sys.USB_DT_STRING
+	USB_ENDPOINT_XFER_BULK                   = sys.USB_ENDPOINT_XFER_BULK
+	USB_ENDPOINT_XFER_CONTROL                = sys.USB_ENDPOINT_XFER_CONTROL
+	USB_ENDPOINT_XFER_INT                    = sys.USB_ENDPOINT_XFER_INT
+	USB_ENDPOINT_XFER_ISOC                   = sys.USB_ENDPOINT_XFER_ISOC
+	USER_CLIENT                              = sys.USER_CLIENT
+	VIRTIO_NET_HDR_F_DATA_VALID              = sys.VIRTIO_NET_HDR_F_DATA_VALID
+	VIRTIO_NET_HDR_F_NEEDS_CSUM              = sys.VIRTIO_NET_HDR_F_NEEDS_CSUM
+	VIRTIO_NET_HDR_GSO_ECN                   = sys.VIRTIO_NET_HDR_GSO_ECN
+	VIRTIO_NET_HDR_GSO_NONE                  = sys.VIRTIO_NET_HDR_GSO_NONE
+	VIRTIO_NET_HDR_GSO_TCPV4                 = sys.VIRTIO_NET_HDR_GSO_TCPV4
+	VIRTIO_NET_HDR_GSO_TCPV6                 = sys.VIRTIO_NET_HDR_GSO_TCPV6
+	VIRTIO_NET_HDR_GSO_UDP                   = sys.VIRTIO_NET_HDR_GSO_UDP
+	VT_ACTIVATE                              = sys.VT_ACTIVATE
+	VT_DISALLOCATE                           = sys.VT_DISALLOCATE
+	VT_GETMODE                               = sys.VT_GETMODE
+	VT_GETSTATE                              = sys.VT_GETSTATE
+	VT_OPENQRY                               = sys.VT_OPENQRY
+	VT_RELDISP                               = sys.VT_RELDISP
+	VT_RESIZE                                = sys.VT_RESIZE
+	VT_RESIZEX                               = sys.VT_RESIZEX
+	VT_SETMODE                               = sys.VT_SETMODE
+	VT_WAITACTIVE                            = sys.VT_WAITACTIVE
+	WCONTINUED                               = sys.WCONTINUED
+	WEXITED                                  = sys.WEXITED
+	WHOLE_SECONDS                            = sys.WHOLE_SECONDS
+	WNOHANG                                  = sys.WNOHANG
+	WNOWAIT                                  = sys.WNOWAIT
+	WSTOPPED                                 = sys.WSTOPPED
+	WUNTRACED                                = sys.WUNTRACED
+	XATTR_CREATE                             = sys.XATTR_CREATE
+	XATTR_REPLACE                            = sys.XATTR_REPLACE
 )
@@ -26,9 +26,10 @@ func (p *Prog) String() string {
 	return buf.String()
 }
 
+// Serialize returns p in the stable text format accepted by Deserialize.
 func (p *Prog) Serialize() []byte {
 	/*
-		if err := p.validate(); err != nil {
+		if err := p.Validate(); err != nil {
 			panic("serializing invalid program")
 		}
 	*/
@@ -117,6 +118,7 @@ func (a *Arg) serialize(buf io.Writer, vars map[*Arg]int, varSeq *int) {
 	}
 }
 
+// Deserialize parses a program in the format produced by Serialize.
 func Deserialize(data []byte) (prog *Prog, err error) {
 	prog = new(Prog)
 	p := &parser{r: bufio.NewScanner(bytes.NewReader(data))}
@@ -174,7 +176,7 @@ func Deserialize(data []byte) (prog *Prog, err error) {
 	if p.Err() != nil {
 		return nil, err
 	}
-	if err := prog.validate(); err != nil {
+	if err := prog.Validate(); err != nil {
 		return nil, err
 	}
 	return
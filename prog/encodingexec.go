@@ -31,7 +31,7 @@ const (
 )
 
 func (p *Prog) SerializeForExec() []byte {
-	if err := p.validate(); err != nil {
+	if err := p.Validate(); err != nil {
 		panic(fmt.Errorf("serializing invalid program: %v", err))
 	}
 	var instrSeq uintptr
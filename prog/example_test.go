@@ -0,0 +1,44 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog_test
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/google/syzkaller/prog"
+)
+
+func Example() {
+	rs := rand.NewSource(0)
+	p := prog.Generate(rs, 10, nil)
+	p.Mutate(rs, 10, nil)
+	p1, err := prog.Deserialize(p.Serialize())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(p1.Validate() == nil && string(p1.Serialize()) == string(p.Serialize()))
+	// Output: true
+}
+
+func ExampleMinimize() {
+	p, err := prog.Deserialize([]byte(
+		"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
+			"r0 = open(&(0x7f0000000000)=\"2e2f66696c6530\", 0x42, 0x0)\n" +
+			"getpid()\n" +
+			"write(r0, &(0x7f0000000000)=\"1155\", 0x2)\n"))
+	if err != nil {
+		panic(err)
+	}
+	// Find the shortest program that still writes to a file: open is kept because write uses its result.
+	p1, callIndex := prog.Minimize(p, 3, func(p1 *prog.Prog, callIndex int) bool {
+		return p1.Calls[callIndex].Meta.Name == "write"
+	})
+	fmt.Printf("%s", p1.Serialize())
+	fmt.Println(callIndex)
+	// Output:
+	// r0 = open(&(0x7f0000000000)="2e2f66696c6530", 0x42, 0x0)
+	// write(r0, &(0x7f0000000000)="1155", 0x2)
+	// 1
+}
@@ -0,0 +1,223 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// Filesystem images are passed to syz_mount_image as a set of segments written at given offsets
+// into an otherwise zeroed image. The superblock segment (fsimage[FS] type) is generated and mutated
+// with knowledge of the superblock layout: known fields are set to plausible values,
+// magic values are preserved and checksums are fixed up (unless we want to test checksum checking).
+
+type fsField struct {
+	off  int
+	size int      // 1, 2, 4 or 8 bytes, little-endian
+	vals []uint64 // plausible values
+}
+
+type fsMagic struct {
+	off int
+	val []byte
+}
+
+type fsImageDesc struct {
+	size   int // superblock size
+	magic  []fsMagic
+	fields []fsField
+	fixup  func(data []byte) // restores cross-field consistency and checksums
+}
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+var fsImages = map[string]*fsImageDesc{
+	// struct ext4_super_block, located at offset 1024 in the image.
+	"ext4": {
+		size:  1024,
+		magic: []fsMagic{{0x38, []byte{0x53, 0xef}}},
+		fields: []fsField{
+			{0x00, 4, []uint64{16, 32, 128, 1024, 0, 0xffffffff}},                    // s_inodes_count
+			{0x04, 4, []uint64{64, 128, 1024, 4096, 0, 0xffffffff}},                  // s_blocks_count_lo
+			{0x08, 4, []uint64{0, 8}},                                                // s_r_blocks_count_lo
+			{0x0c, 4, []uint64{0, 32, 56, 0xffffffff}},                               // s_free_blocks_count_lo
+			{0x10, 4, []uint64{0, 5, 16, 0xffffffff}},                                // s_free_inodes_count
+			{0x14, 4, []uint64{0, 1, 2}},                                             // s_first_data_block
+			{0x18, 4, []uint64{0, 1, 2, 6, 7}},                                       // s_log_block_size
+			{0x1c, 4, []uint64{0, 1, 2, 16}},                                         // s_log_cluster_size
+			{0x20, 4, []uint64{8, 8192, 32768, 0}},                                   // s_blocks_per_group
+			{0x24, 4, []uint64{8, 8192, 32768, 0}},                                   // s_clusters_per_group
+			{0x28, 4, []uint64{8, 16, 32, 128, 0}},                                   // s_inodes_per_group
+			{0x34, 2, []uint64{0, 1, 0xffff}},                                        // s_mnt_count
+			{0x36, 2, []uint64{20, 0xffff}},                                          // s_max_mnt_count
+			{0x3a, 2, []uint64{1, 2, 4}},                                             // s_state
+			{0x3c, 2, []uint64{1, 2, 3}},                                             // s_errors
+			{0x4c, 4, []uint64{0, 1, 2}},                                             // s_rev_level
+			{0x54, 4, []uint64{11, 0, 1, 16}},                                        // s_first_ino
+			{0x58, 2, []uint64{128, 256, 1024, 0, 1}},                                // s_inode_size
+			{0x5c, 4, []uint64{0, 0x4, 0x10, 0x20, 0x200, 0x34}},                     // s_feature_compat
+			{0x60, 4, []uint64{0, 0x2, 0x4, 0x10, 0x42, 0x80, 0x200, 0x2c2, 0x8000}}, // s_feature_incompat
+			{0x64, 4, []uint64{0, 0x1, 0x2, 0x8, 0x400, 0x100, 0x40b}},               // s_feature_ro_compat
+			{0xce, 2, []uint64{0, 1, 1024}},                                          // s_reserved_gdt_blocks
+			{0xe0, 4, []uint64{0, 2, 8}},                                             // s_journal_inum
+			{0xfe, 2, []uint64{0, 32, 64, 1024}},                                     // s_desc_size
+			{0x104, 4, []uint64{0, 1, 0xffffffff}},                                   // s_first_meta_bg
+			{0x15c, 2, []uint64{0, 32, 1024}},                                        // s_min_extra_isize
+			{0x174, 1, []uint64{0, 4, 31, 64}},                                       // s_log_groups_per_flex
+			{0x175, 1, []uint64{0, 1}},                                               // s_checksum_type
+		},
+		fixup: func(data []byte) {
+			binary.LittleEndian.PutUint32(data[0x3fc:], crc32.Checksum(data[:0x3fc], crc32c))
+		},
+	},
+	// FAT boot sector with BIOS parameter block, located at offset 0 in the image.
+	"vfat": {
+		size:  512,
+		magic: []fsMagic{{0x0, []byte{0xeb, 0x3c, 0x90}}, {0x1fe, []byte{0x55, 0xaa}}},
+		fields: []fsField{
+			{0x0b, 2, []uint64{512, 1024, 2048, 4096, 0, 511}}, // bytes per sector
+			{0x0d, 1, []uint64{1, 2, 4, 8, 64, 128, 0, 3}},     // sectors per cluster
+			{0x0e, 2, []uint64{1, 4, 32, 0}},                   // reserved sectors
+			{0x10, 1, []uint64{1, 2, 0}},                       // number of FATs
+			{0x11, 2, []uint64{16, 512, 0}},                    // root dir entries
+			{0x13, 2, []uint64{128, 2048, 0}},                  // total sectors (16-bit)
+			{0x15, 1, []uint64{0xf8, 0xf0, 0}},                 // media
+			{0x16, 2, []uint64{1, 8, 0}},                       // sectors per FAT (FAT12/16)
+			{0x20, 4, []uint64{0, 65536, 0x100000}},            // total sectors (32-bit)
+			{0x26, 1, []uint64{0x29, 0x28, 0}},                 // extended boot signature (FAT12/16)
+			{0x24, 4, []uint64{0, 1, 1024}},                    // sectors per FAT (FAT32)
+			{0x2c, 4, []uint64{2, 0, 0xfffffff}},               // root dir cluster (FAT32)
+			{0x30, 2, []uint64{1, 0, 0xffff}},                  // fsinfo sector (FAT32)
+			{0x32, 2, []uint64{6, 0}},                          // backup boot sector (FAT32)
+			{0x42, 1, []uint64{0x29, 0x28, 0}},                 // extended boot signature (FAT32)
+		},
+	},
+	// struct btrfs_super_block, located at offset 64K in the image.
+	"btrfs": {
+		size:  4096,
+		magic: []fsMagic{{0x40, []byte("_BHRfS_M")}},
+		fields: []fsField{
+			{0x30, 8, []uint64{0x10000, 0}},                         // bytenr
+			{0x38, 8, []uint64{0, 1 << 32, 1 << 34}},                // flags
+			{0x48, 8, []uint64{1, 0, 0xffffffffffffffff}},           // generation
+			{0x50, 8, []uint64{0x400000, 0x4000, 0}},                // root
+			{0x58, 8, []uint64{0x500000, 0x20000, 0}},               // chunk_root
+			{0x60, 8, []uint64{0, 0x4000}},                          // log_root
+			{0x70, 8, []uint64{1 << 20, 16 << 20, 1 << 30, 0}},      // total_bytes
+			{0x78, 8, []uint64{0x4000, 0}},                          // bytes_used
+			{0x80, 8, []uint64{6, 0}},                               // root_dir_objectid
+			{0x88, 8, []uint64{1, 2, 0}},                            // num_devices
+			{0x90, 4, []uint64{4096, 512, 65536, 0}},                // sectorsize
+			{0x94, 4, []uint64{4096, 16384, 65536, 0}},              // nodesize
+			{0x98, 4, []uint64{4096, 16384, 65536, 0}},              // __unused_leafsize
+			{0x9c, 4, []uint64{4096, 0}},                            // stripesize
+			{0xa0, 4, []uint64{0, 0x61, 2048, 2049}},                // sys_chunk_array_size
+			{0xa4, 8, []uint64{1, 0}},                               // chunk_root_generation
+			{0xb4, 8, []uint64{0, 1, 2}},                            // compat_ro_flags
+			{0xbc, 8, []uint64{0x1, 0x41, 0x161, 0x8, 0x10, 0x100}}, // incompat_flags
+			{0xc4, 2, []uint64{0, 1, 4}},                            // csum_type
+			{0xc6, 1, []uint64{0, 1, 8}},                            // root_level
+			{0xc7, 1, []uint64{0, 1}},                               // chunk_root_level
+			{0xc9, 8, []uint64{1, 0}},                               // dev_item.devid
+		},
+		fixup: func(data []byte) {
+			// dev_item.fsid must match fsid.
+			copy(data[0x11b:0x12b], data[0x20:0x30])
+			binary.LittleEndian.PutUint32(data[0x0:], crc32.Checksum(data[0x20:], crc32c))
+		},
+	},
+}
+
+func fsImageDescFor(fs string) *fsImageDesc {
+	desc := fsImages[fs]
+	if desc == nil {
+		panic(fmt.Sprintf("unknown filesystem image kind: %v", fs))
+	}
+	return desc
+}
+
+// fsImage generates a plausible superblock for filesystem fs.
+func (r *randGen) fsImage(fs string) []byte {
+	desc := fsImageDescFor(fs)
+	data := make([]byte, desc.size)
+	for _, f := range desc.fields {
+		f.set(data, f.vals[r.biasedRand(len(f.vals), 5)])
+	}
+	desc.finish(data, true)
+	return data
+}
+
+// mutateFsImage mutates a superblock for filesystem fs in place.
+func (r *randGen) mutateFsImage(fs string, data []byte) []byte {
+	desc := fsImageDescFor(fs)
+	if len(data) != desc.size {
+		data = append(data, make([]byte, desc.size)...)[:desc.size]
+	}
+	for stop := false; !stop; stop = r.bin() {
+		f := desc.fields[r.Intn(len(desc.fields))]
+		r.choose(
+			10, func() {
+				f.set(data, f.vals[r.Intn(len(f.vals))])
+			},
+			5, func() {
+				f.set(data, uint64(r.randInt()))
+			},
+			3, func() {
+				v := f.get(data)
+				if r.bin() {
+					v++
+				} else {
+					v--
+				}
+				f.set(data, v)
+			},
+			1, func() {
+				data[r.Intn(len(data))] ^= 1 << uint(r.Intn(8))
+			},
+		)
+	}
+	desc.finish(data, !r.oneOf(10))
+	return data
+}
+
+func (desc *fsImageDesc) finish(data []byte, fixup bool) {
+	for _, m := range desc.magic {
+		copy(data[m.off:], m.val)
+	}
+	if fixup && desc.fixup != nil {
+		desc.fixup(data)
+	}
+}
+
+func (f fsField) get(data []byte) uint64 {
+	switch f.size {
+	case 1:
+		return uint64(data[f.off])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(data[f.off:]))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(data[f.off:]))
+	case 8:
+		return binary.LittleEndian.Uint64(data[f.off:])
+	default:
+		panic("bad field size")
+	}
+}
+
+func (f fsField) set(data []byte, v uint64) {
+	switch f.size {
+	case 1:
+		data[f.off] = byte(v)
+	case 2:
+		binary.LittleEndian.PutUint16(data[f.off:], uint16(v))
+	case 4:
+		binary.LittleEndian.PutUint32(data[f.off:], uint32(v))
+	case 8:
+		binary.LittleEndian.PutUint64(data[f.off:], v)
+	default:
+		panic("bad field size")
+	}
+}
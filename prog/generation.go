@@ -4,15 +4,27 @@
 package prog
 
 import (
+	"fmt"
 	"math/rand"
+	"sync"
+
+	"github.com/google/syzkaller/sys"
 )
 
 // Generate generates a random program of length ~ncalls.
 // calls is a set of allowed syscalls, if nil all syscalls are used.
+// From time to time the program starts with one of the seeds that use only
+// enabled syscalls (see sys.Seeds).
 func Generate(rs rand.Source, ncalls int, ct *ChoiceTable) *Prog {
 	p := new(Prog)
 	r := newRand(rs)
 	s := newState(ct)
+	if ct != nil && len(ct.seeds) != 0 && r.oneOf(seedRate) {
+		p = ct.seeds[r.Intn(len(ct.seeds))].Clone()
+		for _, c := range p.Calls {
+			s.analyze(c)
+		}
+	}
 	for len(p.Calls) < ncalls {
 		calls := r.generateCall(s, p)
 		for _, c := range calls {
@@ -20,8 +32,52 @@ func Generate(rs rand.Source, ncalls int, ct *ChoiceTable) *Prog {
 			p.Calls = append(p.Calls, c)
 		}
 	}
-	if err := p.validate(); err != nil {
+	if err := p.Validate(); err != nil {
 		panic(err)
 	}
 	return p
 }
+
+const seedRate = 10 // on average every that many generated programs start with a seed
+
+var (
+	seedsOnce sync.Once
+	seedProgs []*Prog
+)
+
+// parseSeeds returns programs of sys.Seeds. Values of const args are reset to
+// the values of the target arch, so that seeds can be written once for all arches.
+func parseSeeds() []*Prog {
+	seedsOnce.Do(func() {
+		for _, seed := range sys.Seeds {
+			p, err := Deserialize([]byte(seed.Prog))
+			if err != nil {
+				panic(fmt.Sprintf("failed to parse seed %v: %v", seed.Name, err))
+			}
+			for _, c := range p.Calls {
+				foreachArg(c, func(arg, _ *Arg, _ *[]*Arg) {
+					if t, ok := arg.Type.(sys.ConstType); ok && arg.Kind == ArgConst {
+						arg.Val = t.Val
+					}
+				})
+			}
+			seedProgs = append(seedProgs, p)
+		}
+	})
+	return seedProgs
+}
+
+// enabledSeeds returns seeds that use only enabled syscalls.
+func enabledSeeds(enabled map[*sys.Call]bool) []*Prog {
+	var res []*Prog
+next:
+	for _, p := range parseSeeds() {
+		for _, c := range p.Calls {
+			if !enabled[c.Meta] {
+				continue next
+			}
+		}
+		res = append(res, p)
+	}
+	return res
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"github.com/google/syzkaller/sys"
+)
+
+// Comparison hints: the executor can collect operands of comparisons executed by kernel
+// (KCOV_TRACE_CMP) instead of coverage. If an argument value of a call is equal to
+// one of the operands, substituting the other operand is likely to flip the branch,
+// which defeats magic numbers that are hopeless to guess by random mutation.
+
+// CompMap maps comparison operands to the set of the other operands they were compared with.
+type CompMap map[uintptr]map[uintptr]bool
+
+// AddComp records that v was compared with other.
+func (m CompMap) AddComp(v, other uintptr) {
+	if v == other {
+		return
+	}
+	if m[v] == nil {
+		m[v] = make(map[uintptr]bool)
+	}
+	m[v][other] = true
+}
+
+const maxHintsPerArg = 64
+
+// MutateWithHints calls exec for every mutant of p obtained by substituting
+// comparison operands from comps into integer and flags arguments of call callIndex.
+// p itself is not changed.
+func (p *Prog) MutateWithHints(callIndex int, comps CompMap, exec func(p *Prog)) {
+	if len(comps) == 0 {
+		return
+	}
+	var args []*Arg
+	foreachArg(p.Calls[callIndex], func(arg, _ *Arg, _ *[]*Arg) {
+		args = append(args, arg)
+	})
+	for i, arg := range args {
+		for _, v := range argHints(arg, comps) {
+			p1 := p.Clone()
+			j := 0
+			foreachArg(p1.Calls[callIndex], func(arg1, _ *Arg, _ *[]*Arg) {
+				if j == i {
+					arg1.Val = v
+				}
+				j++
+			})
+			exec(p1)
+		}
+	}
+}
+
+// argHints returns replacement values for arg. Comparisons of narrower types
+// are matched against the low bytes of the value (operands are zero-extended,
+// so an operand that does not fit into the type did not come from such comparison).
+func argHints(arg *Arg, comps CompMap) []uintptr {
+	if arg.Kind != ArgConst || arg.Dir == DirOut {
+		return nil
+	}
+	switch arg.Type.(type) {
+	case sys.IntType, sys.FlagsType:
+	default:
+		return nil
+	}
+	argSize := arg.Type.Size()
+	if argSize == 0 {
+		argSize = ptrSize // syscall arguments
+	}
+	var res []uintptr
+	dup := make(map[uintptr]bool)
+	for _, size := range []uintptr{1, 2, 4, 8} {
+		if size > argSize {
+			break
+		}
+		mask := ^uintptr(0)
+		if size < 8 {
+			mask = uintptr(1)<<(size*8) - 1
+		}
+		for other := range comps[arg.Val&mask] {
+			if other&^mask != 0 {
+				continue
+			}
+			v := arg.Val&^mask | other
+			if v == arg.Val || dup[v] {
+				continue
+			}
+			dup[v] = true
+			res = append(res, v)
+			if len(res) == maxHintsPerArg {
+				return res
+			}
+		}
+	}
+	return res
+}
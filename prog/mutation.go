@@ -10,13 +10,67 @@ import (
 	"github.com/google/syzkaller/sys"
 )
 
+// MutationWeights are relative probabilities of mutation operations.
+type MutationWeights struct {
+	Insert    int // insert a new call
+	MutateArg int // change args of a call
+	Remove    int // remove a call
+	Splice    int // insert calls of a random corpus program (see ChoiceTable.SetSpliceCorpus)
+}
+
+var DefaultMutationWeights = MutationWeights{Insert: 20, MutateArg: 10, Remove: 1}
+
+// ParseMutationWeights overrides default weights with weights given by name
+// (insert, mutate_arg, remove, splice).
+func ParseMutationWeights(weights map[string]int) (MutationWeights, error) {
+	w := DefaultMutationWeights
+	fields := map[string]*int{
+		"insert":     &w.Insert,
+		"mutate_arg": &w.MutateArg,
+		"remove":     &w.Remove,
+		"splice":     &w.Splice,
+	}
+	for name, v := range weights {
+		f := fields[name]
+		if f == nil {
+			return w, fmt.Errorf("unknown mutation '%v' (known: insert, mutate_arg, remove, splice)", name)
+		}
+		if v < 0 {
+			return w, fmt.Errorf("negative weight %v of mutation '%v'", v, name)
+		}
+		*f = v
+	}
+	if w.Insert+w.MutateArg+w.Remove+w.Splice == 0 {
+		return w, fmt.Errorf("all mutation weights are zero")
+	}
+	return w, nil
+}
+
+// Mutate mutates p in place with the default strategy: inserts new calls (up to ncalls),
+// mutates args of existing calls, removes calls and splices in calls of corpus programs
+// with weights set by ChoiceTable.SetMutationWeights. ct is used to choose new calls (can be nil).
 func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 	r := newRand(rs)
+	w := DefaultMutationWeights
+	var corpus []*Prog
+	if ct != nil {
+		if ct.weights != nil {
+			w = *ct.weights
+		}
+		if w.Splice != 0 && ct.corpus != nil {
+			corpus = ct.corpus()
+		}
+	}
 	retry := false
-	for stop := false; !stop || retry; stop = r.bin() {
+	// With custom weights all enabled mutations may be inapplicable
+	// (e.g. only insert for a program of ncalls calls), so bound the retries.
+	for stop, retries := false, 0; (!stop || retry) && retries < 100; stop = r.bin() {
+		if retry {
+			retries++
+		}
 		retry = false
 		r.choose(
-			20, func() {
+			w.Insert, func() {
 				// Insert a new call.
 				if len(p.Calls) >= ncalls {
 					retry = true
@@ -31,7 +85,7 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 				calls := r.generateCall(s, p)
 				p.insertBefore(c, calls)
 			},
-			10, func() {
+			w.MutateArg, func() {
 				// Change args of a call.
 				if len(p.Calls) == 0 {
 					retry = true
@@ -59,6 +113,7 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 						baseSize = base.Res.Size(base.Res.Type)
 					}
 					var size *Arg
+					r.valueCall, r.valuePath = c.Meta.Name, valuePath(c, arg)
 					switch a := arg.Type.(type) {
 					case sys.IntType, sys.FlagsType, sys.FileoffType, sys.ResourceType, sys.VmaType:
 						arg1, size1, calls1 := r.generateArg(s, arg.Type, arg.Dir, nil)
@@ -94,13 +149,24 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 							arg.Data = r.algType(s)
 						case sys.BufferAlgName:
 							arg.Data = r.algName(s)
+						case sys.BufferPseudofsValue:
+							if r.bin() {
+								arg.Data = mutateData(r, append([]byte{}, arg.Data...))
+							} else {
+								arg.Data = r.pseudofsValue(s)
+							}
+						case sys.BufferFsImage:
+							if r.oneOf(10) {
+								arg.Data = r.fsImage(a.Fs)
+							} else {
+								arg.Data = r.mutateFsImage(a.Fs, append([]byte{}, arg.Data...))
+							}
 						default:
 							panic("unknown buffer kind")
 						}
 						size = constArg(uintptr(len(arg.Data)))
 					case sys.FilenameType:
-						filename := r.filename(s)
-						arg.Data = []byte(filename)
+						arg.Data = []byte(r.filenameOfKind(s, a.Kind))
 					case sys.ArrayType:
 						count := r.rand(6)
 						if count == uintptr(len(arg.Inner)) {
@@ -108,6 +174,7 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 						}
 						if count > uintptr(len(arg.Inner)) {
 							var calls []*Call
+							r.valuePath = append(r.valuePath, a.Name())
 							for count > uintptr(len(arg.Inner)) {
 								arg1, _, calls1 := r.generateArg(s, a.Type, arg.Dir, nil)
 								arg.Inner = append(arg.Inner, arg1)
@@ -158,6 +225,7 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 							optType = a.Options[r.Intn(len(a.Options))]
 						}
 						p.removeArg(arg.Option)
+						r.valuePath = append(r.valuePath, a.Name())
 						opt, size1, calls := r.generateArg(s, optType, arg.Dir, nil)
 						arg1 := unionArg(opt, optType)
 						p.replaceArg(arg, arg1, calls)
@@ -178,7 +246,7 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 							name = base.Type.Name()
 						}
 						for _, arg1 := range *parent {
-							if sz, ok := arg1.Type.(sys.LenType); ok && sz.Buf == name {
+							if sz, ok := arg1.Type.(sys.LenType); ok && sz.Buf == name && !sz.Tag {
 								if arg1.Kind != ArgConst && arg1.Kind != ArgPageSize {
 									panic(fmt.Sprintf("size arg is not const: %#v", *arg1))
 								}
@@ -187,7 +255,7 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 									if size.Val != 0 && size.ByteSize == 0 {
 										panic(fmt.Sprintf("no byte size for %v in %v: size=%v", name, c.Meta.Name, size.Val))
 									}
-									arg1.Val = size.ByteSize
+									arg1.Val = byteSize(sz, size.ByteSize)
 								}
 								arg1.AddrPage = size.AddrPage
 								arg1.AddrOffset = size.AddrOffset
@@ -195,6 +263,17 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 						}
 					}
 
+					// Update associated tag argument if union option has changed.
+					if u, ok := arg.Type.(sys.UnionType); ok {
+						if tag, ok := u.Tag(arg.OptionType); ok {
+							for _, arg1 := range *parent {
+								if sz, ok := arg1.Type.(sys.LenType); ok && sz.Buf == u.Name() && sz.Tag {
+									arg1.Val = tag
+								}
+							}
+						}
+					}
+
 					// Update base pointer if size has increased.
 					if base != nil && baseSize < base.Res.Size(base.Res.Type) {
 						arg1, calls1 := r.addr(s, base.Res.Size(base.Res.Type), base.Res)
@@ -208,7 +287,7 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 					}
 				}
 			},
-			1, func() {
+			w.Remove, func() {
 				// Remove a random call.
 				if len(p.Calls) == 0 {
 					retry = true
@@ -217,13 +296,30 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 				idx := r.Intn(len(p.Calls))
 				p.removeCall(idx)
 			},
+			w.Splice, func() {
+				// Insert calls of a random corpus program.
+				if len(corpus) == 0 || len(p.Calls) >= ncalls {
+					retry = true
+					return
+				}
+				p0 := corpus[r.Intn(len(corpus))].Clone()
+				idx := r.Intn(len(p.Calls) + 1)
+				var c *Call
+				if idx < len(p.Calls) {
+					c = p.Calls[idx]
+				}
+				p.insertBefore(c, p0.Calls)
+				for len(p.Calls) > ncalls {
+					p.removeCall(len(p.Calls) - 1)
+				}
+			},
 		)
 	}
 	for _, c := range p.Calls {
 		assignTypeAndDir(c)
 		sanitizeCall(c)
 	}
-	if err := p.validate(); err != nil {
+	if err := p.Validate(); err != nil {
 		panic(err)
 	}
 }
@@ -232,6 +328,8 @@ func (p *Prog) Mutate(rs rand.Source, ncalls int, ct *ChoiceTable) {
 // predicate pred.  It iteratively generates simpler programs and asks pred
 // whether it is equal to the orginal program or not. If it is equivalent then
 // the simplification attempt is committed and the process continues.
+// callIndex0 is the index of the call that must be preserved (-1 if none), pred receives
+// its index in the simplified program. Minimize returns the program and the new index, p0 is not changed.
 func Minimize(p0 *Prog, callIndex0 int, pred func(*Prog, int) bool) (*Prog, int) {
 	name0 := ""
 	if callIndex0 != -1 {
@@ -287,6 +385,11 @@ func Minimize(p0 *Prog, callIndex0 int, pred func(*Prog, int) bool) (*Prog, int)
 	}
 
 	// Try to remove all calls except the last one one-by-one.
+	// Calls that create resources used by other calls are not removed: uses would be replaced
+	// with default values and the minimized program would most likely not reproduce.
+	// Consumers are tried first (the loop goes backwards), so producers become removable
+	// once all their consumers are gone. Otherwise the producer is substituted with
+	// an earlier call that creates equivalent resources.
 	for i := len(p0.Calls) - 1; i >= 0; i-- {
 		if i == callIndex0 {
 			continue
@@ -295,6 +398,13 @@ func Minimize(p0 *Prog, callIndex0 int, pred func(*Prog, int) bool) (*Prog, int)
 		if i < callIndex {
 			callIndex--
 		}
+		if producesUsedResources(p0.Calls[i]) {
+			if p := substituteProducer(p0, i, callIndex, pred); p != nil {
+				p0 = p
+				callIndex0 = callIndex
+			}
+			continue
+		}
 		p := p0.Clone()
 		p.removeCall(i)
 		if !pred(p, callIndex) {
@@ -318,6 +428,60 @@ func Minimize(p0 *Prog, callIndex0 int, pred func(*Prog, int) bool) (*Prog, int)
 	return p0, callIndex0
 }
 
+// substituteProducer tries to remove call idx that creates used resources by redirecting
+// all uses to compatible resources created by one of the earlier calls (a call of the same
+// syscall is matched resource-by-resource). It returns the new program if pred holds for it.
+func substituteProducer(p0 *Prog, idx, callIndex int, pred func(*Prog, int) bool) *Prog {
+	for j := idx - 1; j >= 0; j-- {
+		p := p0.Clone()
+		c, c1 := p.Calls[idx], p.Calls[j]
+		res1 := producedResources(c1)
+		if len(res1) == 0 {
+			continue
+		}
+		ok := true
+		for k, r := range producedResources(c) {
+			if len(r.Uses) == 0 {
+				continue
+			}
+			var sub *Arg
+			if c.Meta == c1.Meta && k < len(res1) && compatibleResource(res1[k], r) {
+				sub = res1[k]
+			} else {
+				for _, r1 := range res1 {
+					if compatibleResource(r1, r) {
+						sub = r1
+						break
+					}
+				}
+			}
+			if sub == nil {
+				ok = false
+				break
+			}
+			for use := range r.Uses {
+				opDiv, opAdd := use.OpDiv, use.OpAdd
+				p.replaceArg(use, resultArg(sub), nil)
+				use.OpDiv, use.OpAdd = opDiv, opAdd
+			}
+		}
+		if !ok {
+			continue
+		}
+		p.removeCall(idx)
+		if pred(p, callIndex) {
+			return p
+		}
+	}
+	return nil
+}
+
+// compatibleResource returns true if resource arg can be used instead of resource orig.
+func compatibleResource(arg, orig *Arg) bool {
+	typ, typ1 := arg.Type.(sys.ResourceType), orig.Type.(sys.ResourceType)
+	return typ.Kind == typ1.Kind && (typ1.Subkind == sys.ResAny || typ.Subkind == typ1.Subkind)
+}
+
 func (p *Prog) TrimAfter(idx int) {
 	if idx < 0 || idx >= len(p.Calls) {
 		panic("trimming non-existing call")
@@ -209,7 +209,7 @@ func TestMinimize(t *testing.T) {
 			"sched_yield()\n",
 			0,
 		},
-		// Remove a call and replace results.
+		// Don't remove a call that creates a used resource.
 		{
 			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
 				"pipe2(&(0x7f0000000000)={<r0=>0x0, 0x0}, 0x0)\n" +
@@ -220,11 +220,12 @@ func TestMinimize(t *testing.T) {
 				return p.String() == "mmap-write-sched_yield"
 			},
 			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
-				"write(0xffffffffffffffff, &(0x7f0000000000)=\"1155\", 0x2)\n" +
+				"pipe2(&(0x7f0000000000)={<r0=>0x0, 0x0}, 0x0)\n" +
+				"write(r0, &(0x7f0000000000)=\"1155\", 0x2)\n" +
 				"sched_yield()\n",
-			2,
+			3,
 		},
-		// Remove a call and replace results.
+		// Don't remove a call that creates a used resource.
 		{
 			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
 				"r0=open(&(0x7f0000000000)=\"1155\", 0x0, 0x0)\n" +
@@ -235,10 +236,40 @@ func TestMinimize(t *testing.T) {
 				return p.String() == "mmap-write-sched_yield"
 			},
 			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
-				"write(0xffffffffffffffff, &(0x7f0000000000)=\"1155\", 0x2)\n" +
+				"r0 = open(&(0x7f0000000000)=\"1155\", 0x0, 0x0)\n" +
+				"write(r0, &(0x7f0000000000)=\"1155\", 0x2)\n" +
 				"sched_yield()\n",
 			-1,
 		},
+		// Remove the producer together with the consumer.
+		{
+			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
+				"r0 = open(&(0x7f0000000000)=\"1155\", 0x0, 0x0)\n" +
+				"write(r0, &(0x7f0000000000)=\"1155\", 0x2)\n" +
+				"sched_yield()\n",
+			3,
+			func(p *Prog, callIndex int) bool {
+				return p.String() == "mmap-open-sched_yield" || p.String() == "mmap-sched_yield"
+			},
+			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
+				"sched_yield()\n",
+			1,
+		},
+		// Substitute a producer with an earlier call that creates the same resources.
+		{
+			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
+				"pipe2(&(0x7f0000000000)={<r0=>0x0, <r1=>0x0}, 0x0)\n" +
+				"pipe2(&(0x7f0000000000)={<r2=>0x0, <r3=>0x0}, 0x0)\n" +
+				"write(r3, &(0x7f0000000000)=\"1155\", 0x2)\n",
+			3,
+			func(p *Prog, callIndex int) bool {
+				return p.String() == "mmap-pipe2-write"
+			},
+			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
+				"pipe2(&(0x7f0000000000)={0x0, <r0=>0x0}, 0x0)\n" +
+				"write(r0, &(0x7f0000000000)=\"1155\", 0x2)\n",
+			2,
+		},
 		// Glue several mmaps together.
 		{
 			"sched_yield()\n" +
@@ -279,16 +310,119 @@ func TestMinimizeRandom(t *testing.T) {
 	for i := 0; i < iters; i++ {
 		p := Generate(rs, 10, nil)
 		Minimize(p, len(p.Calls)-1, func(p1 *Prog, callIndex int) bool {
-			if err := p1.validate(); err != nil {
+			if err := p1.Validate(); err != nil {
 				t.Fatalf("invalid program: %v", err)
 			}
 			return false
 		})
 		Minimize(p, len(p.Calls)-1, func(p1 *Prog, callIndex int) bool {
-			if err := p1.validate(); err != nil {
+			if err := p1.Validate(); err != nil {
 				t.Fatalf("invalid program: %v", err)
 			}
 			return true
 		})
 	}
 }
+
+func TestStrategies(t *testing.T) {
+	if _, err := LookupStrategy(""); err != nil {
+		t.Fatalf("default strategy is not registered: %v", err)
+	}
+	if _, err := LookupStrategy("foo"); err == nil {
+		t.Fatalf("unknown strategy is found")
+	}
+	rs, iters := initTest(t)
+	for _, name := range Strategies() {
+		s, _ := LookupStrategy(name)
+		for i := 0; i < iters/10; i++ {
+			p := Generate(rs, 10, nil)
+			s.Mutate(p, rs, 10, nil)
+			if err := p.Validate(); err != nil {
+				t.Fatalf("strategy %v produced invalid program: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestMutationWeights(t *testing.T) {
+	if w, err := ParseMutationWeights(nil); err != nil || w != DefaultMutationWeights {
+		t.Fatalf("bad default weights: %+v, %v", w, err)
+	}
+	for _, weights := range []map[string]int{
+		{"foo": 1},
+		{"insert": -1},
+		{"insert": 0, "mutate_arg": 0, "remove": 0},
+	} {
+		if _, err := ParseMutationWeights(weights); err == nil {
+			t.Fatalf("weights %v are accepted", weights)
+		}
+	}
+	w, err := ParseMutationWeights(map[string]int{"insert": 0, "mutate_arg": 0, "remove": 0, "splice": 1})
+	if err != nil {
+		t.Fatalf("failed to parse weights: %v", err)
+	}
+	p0, err := Deserialize([]byte("sched_yield()\n"))
+	if err != nil {
+		t.Fatalf("failed to deserialize program: %v", err)
+	}
+	count := func(p *Prog) int {
+		n := 0
+		for _, c := range p.Calls {
+			if c.Meta.Name == "sched_yield" {
+				n++
+			}
+		}
+		return n
+	}
+	ct := BuildChoiceTable(CalculatePriorities(nil), nil)
+	ct.SetMutationWeights(w)
+	ct.SetSpliceCorpus(func() []*Prog { return []*Prog{p0} })
+	rs, iters := initTest(t)
+	for i := 0; i < iters/10; i++ {
+		p := Generate(rs, 5, ct)
+		if len(p.Calls) >= 10 {
+			continue
+		}
+		n := count(p)
+		p.Mutate(rs, 10, ct)
+		if err := p.Validate(); err != nil {
+			t.Fatalf("splice produced invalid program: %v", err)
+		}
+		if len(p.Calls) > 10 {
+			t.Fatalf("splice produced %v calls", len(p.Calls))
+		}
+		if count(p) <= n {
+			t.Fatalf("corpus program is not spliced:\n%s", p.Serialize())
+		}
+	}
+}
+
+func TestHints(t *testing.T) {
+	p, err := Deserialize([]byte("open(&(0x7f0000001000)=\"2e2f66696c653000\", 0x22c0, 0x1)\n"))
+	if err != nil {
+		t.Fatalf("failed to deserialize program: %v", err)
+	}
+	comps := make(CompMap)
+	comps.AddComp(0x22c0, 0x80000)
+	comps.AddComp(0xc0, 0x41)
+	comps.AddComp(0x1, 0x1)
+	got := make(map[string]bool)
+	p.MutateWithHints(0, comps, func(p1 *Prog) {
+		got[string(p1.Serialize())] = true
+	})
+	want := map[string]bool{
+		"open(&(0x7f0000001000)=\"2e2f66696c653000\", 0x80000, 0x1)\n": true,
+		"open(&(0x7f0000001000)=\"2e2f66696c653000\", 0x2241, 0x1)\n":  true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v mutants, want %v: %v", len(got), len(want), got)
+	}
+	for m := range want {
+		if !got[m] {
+			t.Fatalf("mutant is not generated:\n%s\ngot: %v", m, got)
+		}
+	}
+	if data := string(p.Serialize()); data != "open(&(0x7f0000001000)=\"2e2f66696c653000\", 0x22c0, 0x1)\n" {
+		t.Fatalf("original program is changed:\n%s", data)
+	}
+}
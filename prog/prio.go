@@ -19,6 +19,8 @@ import (
 // The static component is based on analysis of argument types. For example,
 // if call X and call Y both accept fd[sock], then they are more likely to give
 // new coverage together.
+// On top of that, if call X produces a resource (e.g. open of /dev/kvm returns fd[kvm])
+// that call Y accepts, Y is preferred after X: it can actually use the produced resource.
 // The dynamic component is based on frequency of occurrence of a particular
 // pair of syscalls in a single program in corpus. For example, if socket and
 // connect frequently occur in programs together, we give higher priority to
@@ -75,7 +77,8 @@ func calcStaticPriorities() [][]float32 {
 				}
 			case sys.BufferType:
 				switch a.Kind {
-				case sys.BufferBlob, sys.BufferFilesystem, sys.BufferAlgType, sys.BufferAlgName:
+				case sys.BufferBlob, sys.BufferFilesystem, sys.BufferAlgType, sys.BufferAlgName,
+					sys.BufferPseudofsValue, sys.BufferFsImage:
 				case sys.BufferString:
 					noteUsage(0.2, "str")
 				case sys.BufferSockaddr:
@@ -86,7 +89,11 @@ func calcStaticPriorities() [][]float32 {
 			case sys.VmaType:
 				noteUsage(0.5, "vma")
 			case sys.FilenameType:
-				noteUsage(1.0, "filename")
+				if a.Kind == sys.FilenamePseudofs {
+					noteUsage(1.0, "pseudofs")
+				} else {
+					noteUsage(1.0, "filename")
+				}
 			case sys.IntType:
 				switch a.Kind {
 				case sys.IntPlain:
@@ -132,6 +139,8 @@ func calcStaticPriorities() [][]float32 {
 		}
 	}
 
+	calcResourcePriorities(prios)
+
 	// Self-priority (call wrt itself) is assigned to the maximum priority
 	// this call has wrt other calls. This way the priority is high, but not too high.
 	for c0, pp := range prios {
@@ -147,6 +156,40 @@ func calcStaticPriorities() [][]float32 {
 	return prios
 }
 
+// calcResourcePriorities boosts priorities of calls that accept a particular resource
+// (e.g. fd[kvm], but not any fd) wrt calls that produce it.
+func calcResourcePriorities(prios [][]float32) {
+	produces := make(map[string][]int)
+	consumes := make(map[string][]int)
+	for _, c := range sys.Calls {
+		seen := make(map[string]bool)
+		foreachArgType(c, func(t sys.Type, d ArgDir) {
+			a, ok := t.(sys.ResourceType)
+			if !ok || a.Subkind == sys.ResAny || a.Kind == sys.ResPid || a.Kind == sys.ResUid || a.Kind == sys.ResGid {
+				return
+			}
+			id := fmt.Sprintf("res%v-%v", a.Kind, a.Subkind)
+			if d != DirIn && !seen["out"+id] {
+				seen["out"+id] = true
+				produces[id] = append(produces[id], c.ID)
+			}
+			if d != DirOut && !seen["in"+id] {
+				seen["in"+id] = true
+				consumes[id] = append(consumes[id], c.ID)
+			}
+		})
+	}
+	for id, producers := range produces {
+		for _, c0 := range producers {
+			for _, c1 := range consumes[id] {
+				if c0 != c1 {
+					prios[c0][c1] += 1.0
+				}
+			}
+		}
+	}
+}
+
 func calcDynamicPrio(corpus []*Prog) [][]float32 {
 	prios := make([][]float32, len(sys.Calls))
 	for i := range prios {
@@ -242,6 +285,11 @@ type ChoiceTable struct {
 	run          [][]int
 	enabledCalls []*sys.Call
 	enabled      map[*sys.Call]bool
+	values       *ValuePool
+	pseudoFiles  []string // writable procfs/sysfs files for filename[pseudofs] args
+	weights      *MutationWeights
+	corpus       func() []*Prog
+	seeds        []*Prog // seeds that use only enabled calls
 }
 
 func BuildChoiceTable(prios [][]float32, enabled map[*sys.Call]bool) *ChoiceTable {
@@ -269,7 +317,29 @@ func BuildChoiceTable(prios [][]float32, enabled map[*sys.Call]bool) *ChoiceTabl
 			run[i][j] = sum
 		}
 	}
-	return &ChoiceTable{run, enabledCalls, enabled}
+	return &ChoiceTable{run, enabledCalls, enabled, nil, nil, nil, nil, enabledSeeds(enabled)}
+}
+
+// SetValuePool makes generation and mutation use values from vp.
+func (ct *ChoiceTable) SetValuePool(vp *ValuePool) {
+	ct.values = vp
+}
+
+// SetPseudoFiles sets the procfs/sysfs files that filename[pseudofs] args refer to,
+// the files are discovered at runtime in the target (the slice is not modified later).
+func (ct *ChoiceTable) SetPseudoFiles(files []string) {
+	ct.pseudoFiles = files
+}
+
+// SetMutationWeights makes Mutate use weights w instead of DefaultMutationWeights.
+func (ct *ChoiceTable) SetMutationWeights(w MutationWeights) {
+	ct.weights = &w
+}
+
+// SetSpliceCorpus sets the source of programs for the splice mutation,
+// corpus is called once per Mutate and the returned programs are not modified.
+func (ct *ChoiceTable) SetSpliceCorpus(corpus func() []*Prog) {
+	ct.corpus = corpus
 }
 
 func (ct *ChoiceTable) Choose(r *rand.Rand, call int) int {
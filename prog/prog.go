@@ -1,6 +1,24 @@
 // Copyright 2015 syzkaller project authors. All rights reserved.
 // Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
 
+// Package prog generates, mutates, minimizes and serializes syzkaller programs
+// (sequences of syscalls described in sys). It does not depend on the fuzzer or the manager,
+// so external tools can use it directly:
+//
+//	ct := prog.BuildChoiceTable(prog.CalculatePriorities(corpus), enabled) // or nil for all syscalls
+//	p := prog.Generate(rs, 30, ct)
+//	p.Mutate(rs, 30, ct) // or a registered Strategy, see LookupStrategy
+//	p, _ = prog.Minimize(p, -1, func(p1 *prog.Prog, callIndex int) bool { return stillCrashes(p1) })
+//	data := p.Serialize()
+//	p, err := prog.Deserialize(data)
+//
+// Serialize produces the text format used in corpus databases, crash logs and reproducers,
+// one call per line, e.g. "r0 = open(&(0x7f0000000000)="2e2f66696c6530", 0x42, 0x0)".
+// The format is stable: programs serialized by older versions are accepted by Deserialize
+// as long as the syscall descriptions they use exist, lines starting with '#' are comments.
+// SerializeForExec produces the binary format interpreted by syz-executor.
+// All functions that return programs return valid programs (see Validate),
+// programs must not be shared between goroutines without Clone.
 package prog
 
 import (
@@ -6,8 +6,11 @@ package prog
 import (
 	"bytes"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/google/syzkaller/sys"
 )
 
 func initTest(t *testing.T) (rand.Source, int) {
@@ -54,3 +57,243 @@ func TestSerializeForExec(t *testing.T) {
 		p.SerializeForExec()
 	}
 }
+
+func TestValuePool(t *testing.T) {
+	rs, iters := initTest(t)
+	const magic = 0xdeadbeef
+	vp := NewValuePool()
+	for i := 0; i < 10; i++ {
+		p := Generate(rs, 10, nil)
+		for _, c := range p.Calls {
+			for key := range CallValues(c) {
+				vp.Add(key, magic)
+			}
+		}
+	}
+	if len(vp.Keys()) == 0 {
+		t.Fatalf("no interesting values in generated programs")
+	}
+	if vp.Add(vp.Keys()[0], magic) {
+		t.Fatalf("duplicate value is added")
+	}
+	ct := BuildChoiceTable(CalculatePriorities(nil), nil)
+	ct.SetValuePool(vp)
+	for i := 0; i < iters; i++ {
+		p := Generate(rs, 10, ct)
+		for _, c := range p.Calls {
+			for _, vals := range CallValues(c) {
+				for _, v := range vals {
+					if v == magic {
+						return
+					}
+				}
+			}
+		}
+	}
+	t.Fatalf("pool values are not used in generated programs")
+}
+
+func TestCallValues(t *testing.T) {
+	p, err := Deserialize([]byte("mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n"))
+	if err != nil {
+		t.Fatalf("failed to deserialize: %v", err)
+	}
+	vals := CallValues(p.Calls[0])
+	if len(vals) != 1 || len(vals["mmap:flags"]) != 1 || vals["mmap:flags"][0] != 0x32 {
+		t.Fatalf("bad values: %+v", vals)
+	}
+}
+
+func TestResourceCalls(t *testing.T) {
+	tests := []struct {
+		prog  string
+		calls []int
+	}{
+		{
+			"mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
+				"r0 = open(&(0x7f0000000000)=\"2e2f66696c653000\", 0x0, 0x0)\n" +
+				"open(&(0x7f0000000000)=\"2e2f66696c653000\", 0x0, 0x0)\n" +
+				"read(r0, &(0x7f0000000000)=\"00\", 0x1)\n",
+			[]int{1},
+		},
+		{
+			"pipe2(&(0x7f0000000000)={<r0=>0x0, 0x0}, 0x0)\n" +
+				"close(r0)\n",
+			[]int{0},
+		},
+		{
+			"pipe2(&(0x7f0000000000)={0x0, 0x0}, 0x0)\n" +
+				"sched_yield()\n",
+			nil,
+		},
+	}
+	for i, test := range tests {
+		p, err := Deserialize([]byte(test.prog))
+		if err != nil {
+			t.Fatalf("#%v: failed to deserialize: %v", i, err)
+		}
+		calls := p.ResourceCalls()
+		if len(calls) != len(test.calls) {
+			t.Fatalf("#%v: got calls %v, want %v", i, calls, test.calls)
+		}
+		for j := range calls {
+			if calls[j] != test.calls[j] {
+				t.Fatalf("#%v: got calls %v, want %v", i, calls, test.calls)
+			}
+		}
+	}
+}
+
+func TestResourcePriorities(t *testing.T) {
+	prios := calcStaticPriorities()
+	vm := sys.CallMap["ioctl$KVM_CREATE_VM"].ID
+	vcpu := sys.CallMap["ioctl$KVM_CREATE_VCPU"].ID
+	open := sys.CallMap["syz_open_dev$kvm"].ID
+	// KVM_CREATE_VM accepts fd[kvm] of open and produces fd[kvmvm] that KVM_CREATE_VCPU accepts.
+	if prios[vm][vcpu] <= prios[vm][open] {
+		t.Fatalf("consumer of the produced resource is not preferred: %v <= %v",
+			prios[vm][vcpu], prios[vm][open])
+	}
+	if prios[open][vm] <= prios[open][vcpu] {
+		t.Fatalf("consumer of the produced resource is not preferred: %v <= %v",
+			prios[open][vm], prios[open][vcpu])
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	if len(parseSeeds()) != len(sys.Seeds) {
+		t.Fatalf("parsed %v seeds out of %v", len(parseSeeds()), len(sys.Seeds))
+	}
+	for i, p := range parseSeeds() {
+		if err := p.Validate(); err != nil {
+			t.Fatalf("seed %v is invalid: %v", sys.Seeds[i].Name, err)
+		}
+	}
+	seed := parseSeeds()[0]
+	enabled := make(map[*sys.Call]bool)
+	for _, c := range seed.Calls {
+		enabled[c.Meta] = true
+	}
+	ct := BuildChoiceTable(CalculatePriorities(nil), enabled)
+	if len(ct.seeds) != 1 || ct.seeds[0] != seed {
+		t.Fatalf("seed %v is not enabled", sys.Seeds[0].Name)
+	}
+	delete(enabled, seed.Calls[len(seed.Calls)-1].Meta)
+	ct = BuildChoiceTable(CalculatePriorities(nil), enabled)
+	if len(ct.seeds) != 0 {
+		t.Fatalf("seed %v with a disabled call is enabled", sys.Seeds[0].Name)
+	}
+	rs, iters := initTest(t)
+	// Enable only calls of the seed, otherwise it is chosen too rarely among all seeds.
+	enabled[seed.Calls[len(seed.Calls)-1].Meta] = true
+	ct = BuildChoiceTable(CalculatePriorities(nil), enabled)
+	seeded := false
+	for i := 0; i < iters && !seeded; i++ {
+		p := Generate(rs, 10, ct)
+		seeded = len(p.Calls) >= len(seed.Calls) &&
+			bytes.HasPrefix(p.Serialize(), seed.Serialize())
+	}
+	if !seeded {
+		t.Fatalf("generation never starts with a seed")
+	}
+}
+
+func TestPseudoFiles(t *testing.T) {
+	rs, iters := initTest(t)
+	files := []string{"/proc/sys/vm/swappiness", "/sys/kernel/mm/ksm/run"}
+	ct := BuildChoiceTable(CalculatePriorities(nil), nil)
+	r := newRand(rs)
+	s := newState(ct)
+	if f := r.filenameOfKind(s, sys.FilenamePseudofs); strings.HasPrefix(f, "/proc/sys/") {
+		t.Fatalf("pseudo file %q is generated without discovered files", f)
+	}
+	ct.SetPseudoFiles(files)
+	for i := 0; i < iters; i++ {
+		f := r.filenameOfKind(s, sys.FilenamePseudofs)
+		if f != files[0]+"\x00" && f != files[1]+"\x00" {
+			t.Fatalf("bad pseudo file %q", f)
+		}
+		if f := r.filename(s); strings.HasPrefix(f, "/proc/") || strings.HasPrefix(f, "/sys/") {
+			t.Fatalf("plain filename refers to pseudo file %q", f)
+		}
+	}
+}
+
+func TestTaggedUnion(t *testing.T) {
+	rs, iters := initTest(t)
+	u := sys.UnionType{
+		TypeCommon: sys.TypeCommon{TypeName: "u"},
+		Options: []sys.Type{
+			sys.IntType{TypeCommon: sys.TypeCommon{TypeName: "a"}, TypeSize: 8},
+			sys.ConstType{TypeCommon: sys.TypeCommon{TypeName: "b"}, TypeSize: 8, Val: 1},
+		},
+		Tags: []uintptr{10, 20},
+	}
+	types := []sys.Type{
+		sys.LenType{TypeCommon: sys.TypeCommon{TypeName: "tag"}, TypeSize: 4, Tag: true, Buf: "u"},
+		sys.LenType{TypeCommon: sys.TypeCommon{TypeName: "size"}, TypeSize: 4, ByteSize: true, Buf: "u"},
+		u,
+	}
+	r := newRand(rs)
+	for i := 0; i < iters; i++ {
+		args, _ := r.generateArgs(newState(nil), types, DirIn)
+		want := uintptr(10)
+		if args[2].OptionType.Name() == "b" {
+			want = 20
+		}
+		if args[0].Val != want {
+			t.Fatalf("tag %v for option %v, want %v", args[0].Val, args[2].OptionType.Name(), want)
+		}
+		if args[1].Val != 8 {
+			t.Fatalf("union byte size %v, want 8", args[1].Val)
+		}
+	}
+}
+
+func TestByteSizeUnit(t *testing.T) {
+	rs, iters := initTest(t)
+	types := []sys.Type{
+		sys.LenType{TypeCommon: sys.TypeCommon{TypeName: "n"}, TypeSize: 4, ByteSize: true, Unit: 8, Buf: "a"},
+		sys.ArrayType{TypeCommon: sys.TypeCommon{TypeName: "a"}, Type: sys.IntType{TypeSize: 4}},
+	}
+	r := newRand(rs)
+	for i := 0; i < iters; i++ {
+		args, _ := r.generateArgs(newState(nil), types, DirIn)
+		if want := uintptr(len(args[1].Inner)) / 2; args[0].Val != want {
+			t.Fatalf("bytesize8 of %v int32 elements is %v, want %v", len(args[1].Inner), args[0].Val, want)
+		}
+	}
+}
+
+func TestFsImage(t *testing.T) {
+	rs, iters := initTest(t)
+	r := newRand(rs)
+	for fs, desc := range fsImages {
+		check := func(data []byte) {
+			if len(data) != desc.size {
+				t.Fatalf("%v: image size %v, want %v", fs, len(data), desc.size)
+			}
+			for _, m := range desc.magic {
+				if !bytes.Equal(data[m.off:m.off+len(m.val)], m.val) {
+					t.Fatalf("%v: magic at 0x%x is corrupted: %x", fs, m.off, data[m.off:m.off+len(m.val)])
+				}
+			}
+		}
+		for i := 0; i < iters; i++ {
+			data := r.fsImage(fs)
+			check(data)
+			if desc.fixup != nil {
+				data1 := append([]byte{}, data...)
+				desc.fixup(data1)
+				if !bytes.Equal(data, data1) {
+					t.Fatalf("%v: generated image is not fixed up", fs)
+				}
+			}
+			for j := 0; j < 10; j++ {
+				data = r.mutateFsImage(fs, data)
+				check(data)
+			}
+			check(r.mutateFsImage(fs, data[:r.Intn(len(data))]))
+		}
+	}
+}
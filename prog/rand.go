@@ -16,10 +16,12 @@ import (
 type randGen struct {
 	*rand.Rand
 	inCreateResource bool
+	valueCall        string   // name of the call being generated, for value pool keys
+	valuePath        []string // type names of the args being generated (see foreachValuePath)
 }
 
 func newRand(rs rand.Source) *randGen {
-	return &randGen{rand.New(rs), false}
+	return &randGen{Rand: rand.New(rs)}
 }
 
 func (r *randGen) rand(n int) uintptr {
@@ -75,7 +77,7 @@ func (r *randGen) randInt() uintptr {
 // probability of n-1 is k times higher than probability of 0.
 func (r *randGen) biasedRand(n, k int) int {
 	nf, kf := float64(n), float64(k)
-	rf := nf * (kf/2 + 1) * rand.Float64()
+	rf := nf * (kf/2 + 1) * r.Float64()
 	bf := (-1 + math.Sqrt(1+2*kf*rf/nf)) * nf / kf
 	return int(bf)
 }
@@ -152,32 +154,108 @@ func (r *randGen) filename(s *state) string {
 	return files[r.Intn(len(files))]
 }
 
+func (r *randGen) filenameOfKind(s *state, kind sys.FilenameKind) string {
+	if kind == sys.FilenamePseudofs && s.ct != nil && len(s.ct.pseudoFiles) != 0 {
+		return s.ct.pseudoFiles[r.Intn(len(s.ct.pseudoFiles))] + "\x00"
+	}
+	return r.filename(s)
+}
+
+// pseudofsValue generates a value for a procfs/sysfs file: most of them parse
+// a decimal/hex number, a boolean or one of a few keywords followed by a new line.
+func (r *randGen) pseudofsValue(s *state) []byte {
+	words := []string{"on", "off", "Y", "N", "y", "n", "enable", "disable", "enabled", "disabled",
+		"max", "none", "auto", "default", "always", "never", "madvise", "reset", "clear", "all"}
+	var val string
+	r.choose(
+		10, func() { val = fmt.Sprint(r.Intn(4)) },
+		10, func() { val = fmt.Sprint(r.randInt()) },
+		5, func() { val = fmt.Sprintf("-%v", r.randInt()) },
+		5, func() { val = fmt.Sprintf("0x%x", r.rand64()) },
+		5, func() { val = words[r.Intn(len(words))] },
+		5, func() { val = fmt.Sprintf("%v %v", r.randInt(), r.randInt()) },
+		1, func() { val = string(r.randString(s)) },
+	)
+	if !r.oneOf(5) {
+		val += "\n"
+	}
+	return []byte(val)
+}
+
 var sockFamilies = []uint16{AF_UNIX, AF_INET, AF_INET6, AF_IPX, AF_NETLINK, AF_X25, AF_AX25, AF_ATMPVC, AF_APPLETALK, AF_PACKET}
 
-func (r *randGen) inaddr(s *state) uint32 {
+// Addresses of the tap interface set up by executor (see executor/tun.h and sys/vnet.txt),
+// the kernel derives the IPv6 link-local addresses from the MAC addresses.
+var (
+	tunLocalIPv4  = [4]byte{172, 20, 0, 170}
+	tunRemoteIPv4 = [4]byte{172, 20, 0, 187}
+	tunLocalIPv6  = [16]byte{0xfe, 0x80, 8: 0xa8, 0xaa, 0xaa, 0xff, 0xfe, 0xaa, 0xaa, 0xaa}
+	tunRemoteIPv6 = [16]byte{0xfe, 0x80, 8: 0xb9, 0xbb, 0xbb, 0xff, 0xfe, 0xbb, 0xbb, 0xbb}
+)
+
+// inaddrBytes returns an IPv4 address in network byte order.
+func (r *randGen) inaddrBytes(s *state) [4]byte {
 	// TODO: extract addresses of network interfaces.
-	var addr uint32
+	var addr [4]byte
 	r.choose(
-		// Note: assuming little-endian host
-		5, func() { addr = 127<<0 + 0<<8 + 0<<16 + 1<<24 },
-		3, func() { addr = 0 }, // INADDR_ANY
-		1, func() { addr = ^uint32(0) }, // INADDR_NONE/INADDR_BROADCAST
+		5, func() { addr = [4]byte{127, 0, 0, 1} },
+		2, func() { addr = tunLocalIPv4 },
+		2, func() { addr = tunRemoteIPv4 },
+		3, func() { addr = [4]byte{} }, // INADDR_ANY
+		1, func() { addr = [4]byte{255, 255, 255, 255} }, // INADDR_NONE/INADDR_BROADCAST
 	)
 	return addr
 }
 
+// inaddr returns an IPv4 address as an int32 argument value: descriptions describe addresses
+// as int32 which executor stores in host byte order, so the value is byte-swapped
+// to make the address appear in memory in network byte order (assuming little-endian host).
+func (r *randGen) inaddr(s *state) uint32 {
+	addr := r.inaddrBytes(s)
+	return binary.LittleEndian.Uint32(addr[:])
+}
+
+// poolValue sometimes returns a value from the value pool for the arg being generated.
+func (r *randGen) poolValue(s *state) (uintptr, bool) {
+	if s.ct == nil || s.ct.values == nil || !r.oneOf(4) {
+		return 0, false
+	}
+	return s.ct.values.choose(r.Rand, valueKey(r.valueCall, r.valuePath))
+}
+
 func (r *randGen) inport(s *state) uint16 {
 	return uint16(r.Intn(20))<<8 + 0xab
 }
 
+// in6addrBytes returns an IPv6 address in network byte order.
+func (r *randGen) in6addrBytes(s *state) [16]byte {
+	var addr [16]byte
+	r.choose(
+		5, func() { addr = [16]byte{15: 1} }, // ::1
+		2, func() { addr = [16]byte{} }, // ::
+		2, func() { addr = tunLocalIPv6 },
+		2, func() { addr = tunRemoteIPv6 },
+		2, func() { addr = [16]byte{0xfe, 0x80, 15: 0xaa} }, // fe80::aa
+		2, func() { addr = [16]byte{0xff, 0x02, 15: 1} }, // ff02::1 (all nodes)
+		1, func() { addr = [16]byte{0xff, 0x02, 15: 2} }, // ff02::2 (all routers)
+		1, func() { addr = [16]byte{0xff, 0x05, 14: 1, 15: 1} }, // ff05::101
+		2, func() { // ::ffff:ipv4
+			addr[10], addr[11] = 0xff, 0xff
+			v4 := r.inaddrBytes(s)
+			copy(addr[12:], v4[:])
+		},
+	)
+	return addr
+}
+
+// in6addr generates in6_addr struct that is described as 4 int32 words (see inaddr for byte order).
 func (r *randGen) in6addr(s *state) (arg *Arg, calls []*Call) {
-	// addr: loopback (big endian)
-	return groupArg([]*Arg{
-		constArg(0),
-		constArg(0),
-		constArg(0),
-		constArg(1 << 24),
-	}), nil
+	addr := r.in6addrBytes(s)
+	var words []*Arg
+	for i := 0; i < 4; i++ {
+		words = append(words, constArg(uintptr(binary.LittleEndian.Uint32(addr[i*4:]))))
+	}
+	return groupArg(words), nil
 }
 
 func (r *randGen) inaddrany(s *state) (arg *Arg, calls []*Call) {
@@ -202,12 +280,13 @@ func (r *randGen) sockaddr(s *state) []byte {
 		buf.WriteString(r.filename(s))
 	case AF_INET:
 		binary.Write(buf, binary.LittleEndian, r.inport(s))
-		binary.Write(buf, binary.LittleEndian, r.inaddr(s))
+		addr := r.inaddrBytes(s)
+		buf.Write(addr[:])
 	case AF_INET6:
 		binary.Write(buf, binary.LittleEndian, r.inport(s))
 		binary.Write(buf, binary.BigEndian, uint32(r.Int63())) // flow info
-		binary.Write(buf, binary.BigEndian, uint64(0))         // addr: loopback
-		binary.Write(buf, binary.BigEndian, uint64(1))         // addr: loopback
+		addr := r.in6addrBytes(s)
+		buf.Write(addr[:])
 		binary.Write(buf, binary.BigEndian, uint32(r.Int63())) // scope id
 	case AF_IPX:
 	case AF_NETLINK:
@@ -565,7 +644,11 @@ func (r *randGen) generateCall(s *state, p *Prog) []*Call {
 
 func (r *randGen) generateParticularCall(s *state, meta *sys.Call) (calls []*Call) {
 	c := &Call{Meta: meta}
+	// Calls that create resources for the args are generated recursively.
+	valueCall, valuePath := r.valueCall, r.valuePath
+	r.valueCall, r.valuePath = meta.Name, nil
 	c.Args, calls = r.generateArgs(s, meta.Args, DirIn)
+	r.valueCall, r.valuePath = valueCall, valuePath
 	calls = append(calls, c)
 	for _, c1 := range calls {
 		assignTypeAndDir(c1)
@@ -578,6 +661,7 @@ func (r *randGen) generateArgs(s *state, types []sys.Type, dir ArgDir) ([]*Arg,
 	var calls []*Call
 	args := make([]*Arg, len(types))
 	sizes := make(map[string]*Arg)
+	tags := make(map[string]uintptr)
 	// Pass 1: generate all args except size arguments.
 	for i, typ := range types {
 		if _, ok := typ.(sys.LenType); ok {
@@ -586,9 +670,22 @@ func (r *randGen) generateArgs(s *state, types []sys.Type, dir ArgDir) ([]*Arg,
 		arg, size, calls1 := r.generateArg(s, typ, dir, sizes)
 		args[i] = arg
 		calls = append(calls, calls1...)
+		if size == nil && arg != nil {
+			switch typ.(type) {
+			case sys.StructType, sys.UnionType:
+				// Embed structs and unions are referenced by byte size.
+				size = constArg(arg.Size(typ))
+				size.ByteSize = size.Val
+			}
+		}
 		if size != nil {
 			sizes[typ.Name()] = size
 		}
+		if u, ok := typ.(sys.UnionType); ok && arg != nil {
+			if tag, ok := u.Tag(arg.OptionType); ok {
+				tags[typ.Name()] = tag
+			}
+		}
 	}
 
 	// Pass 2: calculate size of the whole struct.
@@ -602,9 +699,17 @@ func (r *randGen) generateArgs(s *state, types []sys.Type, dir ArgDir) ([]*Arg,
 	}
 	sizes["parent"] = constArg(parentSize)
 
-	// Pass 3: fill in size arguments.
+	// Pass 3: fill in size and tag arguments.
 	for i, typ := range types {
 		if a, ok := typ.(sys.LenType); ok {
+			if a.Tag {
+				tag, ok := tags[a.Buf]
+				if !ok {
+					panic(fmt.Sprintf("no tag for %v[%v], types: %+v", a.Name(), a.Buf, types))
+				}
+				args[i] = constArg(tag)
+				continue
+			}
 			size := sizes[a.Buf]
 			if size == nil {
 				panic(fmt.Sprintf("no size for %v[%v] (%+v), types: %+v", a.Name(), a.Buf, sizes, types))
@@ -613,7 +718,7 @@ func (r *randGen) generateArgs(s *state, types []sys.Type, dir ArgDir) ([]*Arg,
 				if size.Val != 0 && size.ByteSize == 0 {
 					panic(fmt.Sprintf("no byte size for %v: size=%v", a.Name(), size.Val))
 				}
-				size = constArg(size.ByteSize)
+				size = constArg(byteSize(a, size.ByteSize))
 			}
 			args[i] = size
 		}
@@ -621,7 +726,19 @@ func (r *randGen) generateArgs(s *state, types []sys.Type, dir ArgDir) ([]*Arg,
 	return args, calls
 }
 
+// byteSize returns value of the bytesize argument typ for an object of size bytes.
+func byteSize(typ sys.LenType, size uintptr) uintptr {
+	if typ.Unit > 1 {
+		return size / typ.Unit
+	}
+	return size
+}
+
 func (r *randGen) generateArg(s *state, typ sys.Type, dir ArgDir, sizes map[string]*Arg) (arg, size *Arg, calls []*Call) {
+	depth := len(r.valuePath)
+	r.valuePath = append(r.valuePath, typ.Name())
+	defer func() { r.valuePath = r.valuePath[:depth] }()
+
 	if dir == DirOut {
 		// No need to generate something interesting for output scalar arguments.
 		// But we still need to generate the argument itself so that it can be referenced
@@ -722,6 +839,12 @@ func (r *randGen) generateArg(s *state, typ sys.Type, dir ArgDir, sizes map[stri
 				}
 			}
 			return dataArg(data), constArg(uintptr(len(data))), nil
+		case sys.BufferPseudofsValue:
+			data := r.pseudofsValue(s)
+			return dataArg(data), constArg(uintptr(len(data))), nil
+		case sys.BufferFsImage:
+			data := r.fsImage(a.Fs)
+			return dataArg(data), constArg(uintptr(len(data))), nil
 		default:
 			panic("unknown buffer kind")
 		}
@@ -730,6 +853,9 @@ func (r *randGen) generateArg(s *state, typ sys.Type, dir ArgDir, sizes map[stri
 		arg := r.randPageAddr(s, npages, nil)
 		return arg, pageSizeArg(npages, 0), nil
 	case sys.FlagsType:
+		if v, ok := r.poolValue(s); ok {
+			return constArg(v), nil, nil
+		}
 		return constArg(r.flags(a.Vals)), nil, nil
 	case sys.ConstType:
 		return constArg(a.Val), nil, nil
@@ -738,6 +864,10 @@ func (r *randGen) generateArg(s *state, typ sys.Type, dir ArgDir, sizes map[stri
 	case sys.IntType:
 		v := r.randInt()
 		switch a.Kind {
+		case sys.IntPlain:
+			if v1, ok := r.poolValue(s); ok {
+				v = v1
+			}
 		case sys.IntSignalno:
 			v %= 130
 		case sys.IntInaddr:
@@ -747,7 +877,7 @@ func (r *randGen) generateArg(s *state, typ sys.Type, dir ArgDir, sizes map[stri
 		}
 		return constArg(v), nil, nil
 	case sys.FilenameType:
-		filename := r.filename(s)
+		filename := r.filenameOfKind(s, a.Kind)
 		return dataArg([]byte(filename)), nil, nil
 	case sys.ArrayType:
 		count := a.Len
@@ -785,6 +915,7 @@ func (r *randGen) generateArg(s *state, typ sys.Type, dir ArgDir, sizes map[stri
 		}
 		if size == nil {
 			size = constArg(inner.Size(a.Type))
+			size.ByteSize = size.Val
 		}
 		if a.Type.Name() == "iocb" && r.bin() && len(s.resources[sys.ResIocbPtr][sys.ResAny]) != 0 {
 			// It is weird, but these are actually identified by kernel by address.
@@ -0,0 +1,64 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Strategy is a program mutation strategy.
+// Alternative strategies are registered with RegisterStrategy (usually from init
+// of a package that is linked into syz-fuzzer) and selected by name in config.
+type Strategy interface {
+	// Mutate mutates program p in place. ncalls is the desired maximum
+	// number of calls in the program, ct is the choice table to use
+	// for new calls (can be nil).
+	Mutate(p *Prog, rs rand.Source, ncalls int, ct *ChoiceTable)
+}
+
+const DefaultStrategy = "default"
+
+var strategies = make(map[string]Strategy)
+
+func RegisterStrategy(name string, s Strategy) {
+	if strategies[name] != nil {
+		panic(fmt.Sprintf("strategy %v is already registered", name))
+	}
+	strategies[name] = s
+}
+
+// LookupStrategy returns a registered strategy by name.
+// Empty name denotes the default strategy.
+func LookupStrategy(name string) (Strategy, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+	s := strategies[name]
+	if s == nil {
+		return nil, fmt.Errorf("unknown mutation strategy '%v' (known: %v)", name, Strategies())
+	}
+	return s, nil
+}
+
+// Strategies returns sorted names of all registered strategies.
+func Strategies() []string {
+	var names []string
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type defaultStrategy struct{}
+
+func (defaultStrategy) Mutate(p *Prog, rs rand.Source, ncalls int, ct *ChoiceTable) {
+	p.Mutate(rs, ncalls, ct)
+}
+
+func init() {
+	RegisterStrategy(DefaultStrategy, defaultStrategy{})
+}
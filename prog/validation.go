@@ -14,7 +14,9 @@ type validCtx struct {
 	uses map[*Arg]*Arg
 }
 
-func (p *Prog) validate() error {
+// Validate checks that p is well-formed: all args match types of their syscalls
+// and all resource uses refer to resources created by preceding calls.
+func (p *Prog) Validate() error {
 	ctx := &validCtx{make(map[*Arg]bool), make(map[*Arg]*Arg)}
 	for _, c := range p.Calls {
 		if err := c.validate(ctx); err != nil {
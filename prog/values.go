@@ -0,0 +1,151 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package prog
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/google/syzkaller/sys"
+)
+
+// ValuePool is a pool of interesting integer argument values (e.g. magic values
+// that flipped a branch in kernel), keyed by call name and argument path
+// (e.g. "ioctl$KVM_RUN:arg" or "setsockopt$sock_int:val.int").
+// Generation and mutation use values from the pool for the same argument of the same call.
+// ValuePool is safe for concurrent use.
+type ValuePool struct {
+	mu   sync.RWMutex
+	vals map[string][]uintptr
+}
+
+const (
+	maxPoolValues = 64 // per key
+	minPoolValue  = 16 // smaller values are generated often enough anyway
+)
+
+func NewValuePool() *ValuePool {
+	return &ValuePool{vals: make(map[string][]uintptr)}
+}
+
+// Add adds value v for key and returns true if the pool has changed.
+func (vp *ValuePool) Add(key string, v uintptr) bool {
+	if v < minPoolValue || key == "" {
+		return false
+	}
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+	vals := vp.vals[key]
+	for _, v1 := range vals {
+		if v1 == v {
+			return false
+		}
+	}
+	if len(vals) >= maxPoolValues {
+		// Evict the oldest value.
+		copy(vals, vals[1:])
+		vals[len(vals)-1] = v
+	} else {
+		vp.vals[key] = append(vals, v)
+	}
+	return true
+}
+
+// Values returns a copy of values for key.
+func (vp *ValuePool) Values(key string) []uintptr {
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+	return append([]uintptr{}, vp.vals[key]...)
+}
+
+// Keys returns all keys in the pool.
+func (vp *ValuePool) Keys() []string {
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+	var keys []string
+	for key := range vp.vals {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (vp *ValuePool) choose(r *rand.Rand, key string) (uintptr, bool) {
+	if vp == nil {
+		return 0, false
+	}
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+	vals := vp.vals[key]
+	if len(vals) == 0 {
+		return 0, false
+	}
+	return vals[r.Intn(len(vals))], true
+}
+
+// CallValues returns input integer and flags argument values of call c
+// that are worth adding to a value pool, keyed by call name and argument path.
+func CallValues(c *Call) map[string][]uintptr {
+	res := make(map[string][]uintptr)
+	foreachValuePath(c, func(arg *Arg, path []string) {
+		if arg.Kind != ArgConst || arg.Dir == DirOut || arg.Val < minPoolValue {
+			return
+		}
+		switch typ := arg.Type.(type) {
+		case sys.IntType:
+			if typ.Kind != sys.IntPlain {
+				return
+			}
+		case sys.FlagsType:
+		default:
+			return
+		}
+		key := valueKey(c.Meta.Name, path)
+		res[key] = append(res[key], arg.Val)
+	})
+	return res
+}
+
+// foreachValuePath calls f for every argument of c with the argument path:
+// type names of the argument and all its parents (the same path generateArg builds).
+func foreachValuePath(c *Call, f func(arg *Arg, path []string)) {
+	var rec func(arg *Arg, path []string)
+	rec = func(arg *Arg, path []string) {
+		path = append(path[:len(path):len(path)], arg.Type.Name())
+		f(arg, path)
+		for _, arg1 := range arg.Inner {
+			rec(arg1, path)
+		}
+		if arg.Kind == ArgPointer && arg.Res != nil {
+			rec(arg.Res, path)
+		}
+		if arg.Kind == ArgUnion {
+			rec(arg.Option, path)
+		}
+	}
+	for _, arg := range c.Args {
+		rec(arg, nil)
+	}
+}
+
+// valuePath returns path of the parents of arg in c (see foreachValuePath).
+func valuePath(c *Call, arg *Arg) []string {
+	var res []string
+	foreachValuePath(c, func(arg1 *Arg, path []string) {
+		if arg1 == arg {
+			res = path[:len(path)-1]
+		}
+	})
+	return res
+}
+
+func valueKey(call string, path []string) string {
+	var names []string
+	for _, name := range path {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return call + ":" + strings.Join(names, ".")
+}
@@ -0,0 +1,71 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ExtractGuiltyFile returns the most likely culprit source file of a symbolized report
+// (see Symbolize): the first file in the report that is not a generic helper
+// (allocators, KASAN, locking, printk, arch code, headers, etc).
+// For KASAN reports only the bad access stack is considered,
+// allocation and free stacks point to whoever owned the object.
+// Returns "" if the report is not symbolized or all frames are in generic code.
+func ExtractGuiltyFile(report []byte) string {
+	if pos := guiltyStackEnd.FindIndex(report); pos != nil {
+		report = report[:pos[0]]
+	}
+	for _, m := range guiltyFile.FindAllSubmatch(report, -1) {
+		file := string(m[1])
+		if !guiltyIgnores.MatchString(file) {
+			return file
+		}
+	}
+	return ""
+}
+
+// GuiltySubsystem returns the subsystem of the guilty file: two leading dirs
+// for large top-level dirs (e.g. net/ipv4, drivers/usb), one for the rest (e.g. mm).
+// Returns "" for absolute file names (kernel source dir is unknown).
+func GuiltySubsystem(file string) string {
+	if file == "" || strings.HasPrefix(file, "/") {
+		return ""
+	}
+	dirs := strings.Split(file, "/")
+	dirs = dirs[:len(dirs)-1]
+	if len(dirs) == 0 {
+		return "."
+	}
+	n := 1
+	if len(dirs) > 1 && guiltyLargeDirs[dirs[0]] {
+		n = 2
+	}
+	return strings.Join(dirs[:n], "/")
+}
+
+var (
+	// guiltyFile matches file:line annotations, e.g. "at net/core/dev.c:123" in WARNING
+	// headers or "func+0x1/0x2 net/core/dev.c:123" in symbolized frames.
+	guiltyFile     = regexp.MustCompile(`(?:^| )([a-zA-Z0-9_\-/.]+\.[chS]):[0-9]+`)
+	guiltyStackEnd = regexp.MustCompile(`(?m)^(?:Allocated|Freed) by task`)
+	// guiltyIgnores are files that are on stacks of most crashes but are rarely the culprit.
+	// Kernel source dir can be left in file names, so patterns are not anchored to the root.
+	guiltyIgnores = regexp.MustCompile(`(?:^|/)(?:` +
+		`include/|lib/|arch/[a-z0-9]+/(?:kernel|entry|lib|mm)/|` +
+		`mm/kasan/|mm/kmsan/|mm/kmemleak\.c|mm/slab\.c|mm/slub\.c|mm/slob\.c|mm/slab\.h|` +
+		`mm/slab_common\.c|mm/util\.c|mm/vmalloc\.c|mm/page_alloc\.c|` +
+		`kernel/locking/|kernel/printk/|kernel/panic\.c|kernel/softirq\.c|kernel/irq/|` +
+		`kernel/rcu/|kernel/sched/|kernel/time/|kernel/workqueue\.c|kernel/kthread\.c|` +
+		`kernel/hung_task\.c|kernel/watchdog\.c|kernel/stacktrace\.c|kernel/kcov\.c|` +
+		`kernel/exit\.c|kernel/signal\.c)`)
+	guiltyLargeDirs = map[string]bool{
+		"arch":    true,
+		"drivers": true,
+		"fs":      true,
+		"net":     true,
+		"sound":   true,
+	}
+)
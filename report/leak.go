@@ -0,0 +1,50 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Leak is a single object reported by kmemleak.
+type Leak struct {
+	Title  string // "memory leak in FUNC", FUNC is the first non-allocator backtrace frame
+	Report []byte
+}
+
+// ParseLeaks splits contents of /sys/kernel/debug/kmemleak into individual leaks.
+func ParseLeaks(output []byte) []Leak {
+	var leaks []Leak
+	// The first part is whatever precedes the first object.
+	for _, part := range bytes.Split(output, leakStart)[1:] {
+		report := append(append([]byte{}, leakStart...), part...)
+		leaks = append(leaks, Leak{Title: leakTitle(report), Report: report})
+	}
+	return leaks
+}
+
+var (
+	leakStart = []byte("unreferenced object ")
+	leakFrame = regexp.MustCompile(`\[<[0-9a-f]+>\] ([a-zA-Z0-9_.]+)`)
+	// leakAllocators are skipped in backtraces, they don't identify the leak.
+	leakAllocators = regexp.MustCompile(`^(kmemleak_alloc|kmemleak_alloc_recursive|kmemleak_vmalloc|` +
+		`slab_post_alloc_hook|slab_alloc|slab_alloc_node|` +
+		`_?_?k[mz]alloc|_?_?k[mz]alloc_node|_?_?kmalloc_track_caller|kmalloc_array|kcalloc|` +
+		`kmem_cache_alloc|kmem_cache_alloc_node|kmem_cache_alloc_trace|kmem_cache_zalloc|` +
+		`__?vmalloc.*|vzalloc|kmemdup|kstrdup|kstrndup|krealloc|__krealloc|` +
+		`__alloc_skb|alloc_skb|__kmalloc_reserve.*|` +
+		`.*\.(constprop|isra|part)\.[0-9]+)$`)
+)
+
+// leakTitle returns title for a single kmemleak report.
+func leakTitle(report []byte) string {
+	for _, m := range leakFrame.FindAllSubmatch(report, -1) {
+		fn := string(m[1])
+		if !leakAllocators.MatchString(fn) {
+			return "memory leak in " + fn
+		}
+	}
+	return "memory leak"
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// lockdepTitle returns title of a lockdep report with the first line desc and text report.
+// The title is based on the dependency chain rather than on the header, e.g.
+// "possible deadlock in FUNC" where FUNC is the function that tries to acquire the lock,
+// otherwise all circular dependencies would get the same title. ok is false if desc is not
+// a lockdep report or FUNC is not found.
+func lockdepTitle(desc string, report []byte) (title string, ok bool) {
+	for _, ld := range lockdepReports {
+		if !strings.Contains(desc, ld.header) {
+			continue
+		}
+		anchor := bytes.Index(report, []byte(ld.anchor))
+		if anchor == -1 {
+			return "", false
+		}
+		// The lock is on the next line followed by the acquisition site.
+		rest := report[anchor+len(ld.anchor):]
+		lines := bytes.SplitN(rest, []byte("\n"), 4)
+		if len(lines) == 4 {
+			lines = lines[:3]
+		}
+		for _, line := range lines {
+			if m := lockdepSite.FindSubmatch(line); m != nil {
+				return ld.title + " in " + trimFunc(string(m[1])), true
+			}
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// LockdepOff reports whether output says that lockdep has turned itself off. Lockdep reports
+// only the first locking bug per boot, later bugs can only be found after a reboot.
+func LockdepOff(output []byte) bool {
+	for _, marker := range lockdepOffMarkers {
+		if bytes.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	lockdepReports = []struct {
+		header string // part of the first line of the report
+		anchor string // the line before the lock with the interesting acquisition site
+		title  string
+	}{
+		{"possible circular locking dependency detected", "is trying to acquire lock:", "possible deadlock"},
+		{"possible recursive locking detected", "is trying to acquire lock:", "possible deadlock"},
+		{"possible irq lock inversion dependency detected", "just changed the state of lock:", "possible deadlock"},
+		{"inconsistent lock state", "takes:", "inconsistent lock state"},
+	}
+	// Old kernels print "at: [<ffffffff81234567>] func+0x1/0x2", new ones "at: func+0x1/0x2".
+	lockdepSite       = regexp.MustCompile(`at: (?:\[<[0-9a-f]+>\] )?([a-zA-Z0-9_.]+)`)
+	lockdepOffMarkers = [][]byte{
+		[]byte("turning off the locking correctness validator"),
+		[]byte("Disabling lock debugging due to kernel taint"),
+	}
+)
@@ -0,0 +1,363 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package report contains functions that process kernel console output:
+// detect crashes, extract and classify crash reports and give them canonical titles.
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Type is the class of a crash.
+type Type int
+
+const (
+	Unknown    Type = iota
+	KASAN           // KASAN reports
+	Warning         // WARNING: and WARN_ON
+	Bug             // BUG:, kernel BUG, general protection faults, bad memory accesses
+	Lockdep         // lockdep and RCU lockdep splats
+	Hang            // hung tasks, RCU stalls and lockups
+	Panic           // kernel panics
+	UBSAN           // UBSAN reports
+	MemoryLeak      // kmemleak reports
+)
+
+var typeNames = map[Type]string{
+	Unknown:    "unknown",
+	KASAN:      "KASAN",
+	Warning:    "WARNING",
+	Bug:        "BUG",
+	Lockdep:    "lockdep",
+	Hang:       "hang",
+	Panic:      "panic",
+	UBSAN:      "UBSAN",
+	MemoryLeak: "leak",
+}
+
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+// Report describes the first crash found in console output.
+type Report struct {
+	Title  string // canonical title, the same crash gets the same title across runs and VMs
+	Type   Type
+	Desc   string // the first oops line as printed by the kernel
+	Report []byte // text of the first oops without console prefixes
+	Start  int    // Start and End denote region of output with oops message(s)
+	End    int
+}
+
+// ContainsCrash returns whether output contains an oops message.
+func ContainsCrash(output []byte) bool {
+	_, _, _, found := find(output)
+	return found
+}
+
+// Parse extracts the first crash from kernel console output,
+// returns nil if output does not contain a crash.
+func Parse(output []byte) *Report {
+	desc, start, end, found := find(output)
+	if !found {
+		return nil
+	}
+	rep := &Report{
+		Desc:  desc,
+		Start: start,
+		End:   end,
+	}
+	rep.Report = extractReport(output[start:])
+	rep.Title, rep.Type = classify(desc, rep.Report)
+	return rep
+}
+
+// find searches kernel console output for oops messages.
+// Desc contains a more-or-less representative description of the first oops,
+// start and end denote region of output with oops message(s).
+func find(output []byte) (desc string, start int, end int, found bool) {
+	for pos := 0; pos < len(output); {
+		next := bytes.IndexByte(output[pos:], '\n')
+		if next != -1 {
+			next += pos
+		} else {
+			next = len(output)
+		}
+		for _, oops := range oopses {
+			match := bytes.Index(output[pos:next], oops)
+			if match == -1 || ignoredOops(output[pos+match:next]) {
+				continue
+			}
+			if !found {
+				found = true
+				start = pos
+				desc = string(output[pos+match : next])
+				if desc[len(desc)-1] == '\r' {
+					desc = desc[:len(desc)-1]
+				}
+			}
+			end = next
+		}
+		pos = next + 1
+	}
+	return
+}
+
+// extractReport returns text of the oops at the beginning of output: lines up to the end marker
+// of the oops (or maxReportLines), with console timestamps and carriage returns stripped.
+func extractReport(output []byte) []byte {
+	buf := new(bytes.Buffer)
+	for i, line := range bytes.Split(output, []byte("\n")) {
+		if i == maxReportLines {
+			break
+		}
+		line = bytes.TrimRight(line, "\r")
+		line = consolePrefix.ReplaceAll(line, nil)
+		if i != 0 && bytes.HasPrefix(line, kasanDelimiter) {
+			// KASAN reports are enclosed in ===== lines.
+			break
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		if i != 0 && isReportEnd(line) {
+			break
+		}
+	}
+	return buf.Bytes()
+}
+
+func isReportEnd(line []byte) bool {
+	for _, marker := range reportEndMarkers {
+		if bytes.Contains(line, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// classify returns title and type of the oops with the first line desc and text report.
+func classify(desc string, report []byte) (string, Type) {
+	if title, ok := lockdepTitle(desc, report); ok {
+		return title, Lockdep
+	}
+	if strings.HasPrefix(desc, "unreferenced object") {
+		return leakTitle(report), MemoryLeak
+	}
+	for _, f := range oopsFormats {
+		if !strings.Contains(desc, f.header) {
+			continue
+		}
+		m := f.re.FindSubmatch(report)
+		if m == nil {
+			continue
+		}
+		var args []interface{}
+		for _, arg := range m[1:] {
+			args = append(args, trimFunc(string(arg)))
+		}
+		return Title(fmt.Sprintf(f.title, args...)), f.typ
+	}
+	typ := Unknown
+	for _, t := range fallbackTypes {
+		if strings.Contains(desc, t.header) {
+			typ = t.typ
+			break
+		}
+	}
+	return Title(desc), typ
+}
+
+// Title canonicalizes crash description, so that the same crash produces the same title
+// across runs and VMs: addresses, function offsets, CPU/PID numbers and executor/task pids are stripped.
+func Title(desc string) string {
+	for _, r := range titleRewrites {
+		desc = r.re.ReplaceAllString(desc, r.repl)
+	}
+	// Addresses without 0x prefix (e.g. "at addr ffff88002db3cf50") are told apart from
+	// decimal numbers (e.g. line numbers) and hex-looking words by having both digits and letters.
+	desc = bareAddr.ReplaceAllStringFunc(desc, func(s string) string {
+		if strings.ContainsAny(s, "abcdef") && strings.ContainsAny(s, "0123456789") {
+			return "ADDR"
+		}
+		return s
+	})
+	return strings.TrimSpace(desc)
+}
+
+// trimFunc strips compiler-generated suffixes of function names (e.g. foo.constprop.3).
+func trimFunc(fn string) string {
+	return funcSuffix.ReplaceAllString(fn, "")
+}
+
+const maxReportLines = 200
+
+var (
+	consolePrefix  = regexp.MustCompile(`^\[ *[0-9]+\.[0-9]+\] `)
+	funcSuffix     = regexp.MustCompile(`(\.(constprop|isra|part|cold)\.[0-9]+)+$`)
+	kasanDelimiter = []byte("==================================================================")
+	// Note: lines with the markers are included into reports.
+	reportEndMarkers = [][]byte{
+		[]byte("---[ end trace"),
+		[]byte("---[ end Kernel panic"),
+		[]byte("Kernel Offset:"),
+	}
+)
+
+// frame matches a function name in the kernel text position printed by an oops:
+// "[<ffffffff81234567>] func+0x1/0x2" on old kernels and "func+0x1/0x2" on new ones,
+// "RIP: 0010:[<ffffffff81234567>]  [<ffffffff81234567>] func+0x1/0x2",
+// inlined frames are printed as "[<     inline     >] func file:line".
+const frame = `(?:[0-9a-f]{4}:)?(?:\[<[0-9a-f]+>\] +)*(?:\[< *inline *>\] +)?([a-zA-Z0-9_.]+)`
+
+// oopsFormats give titles to oopses whose first line contains header.
+// The title is formatted with submatches of re matched against the report text.
+// The first matching format wins.
+var oopsFormats = []struct {
+	header string
+	re     *regexp.Regexp
+	title  string
+	typ    Type
+}{
+	{
+		"BUG: KASAN:",
+		regexp.MustCompile(`BUG: KASAN: ([a-z\- ]+) in ([a-zA-Z0-9_.]+)(?:.*\n)+?.*(Read|Write) of size`),
+		"KASAN: %[1]v %[3]v in %[2]v",
+		KASAN,
+	},
+	{
+		"BUG: KASAN:",
+		regexp.MustCompile(`BUG: KASAN: ([a-z\- ]+) in ([a-zA-Z0-9_.]+)`),
+		"KASAN: %[1]v in %[2]v",
+		KASAN,
+	},
+	{
+		"BUG: unable to handle kernel",
+		regexp.MustCompile(`BUG: unable to handle kernel (paging request|NULL pointer dereference)(?:.*\n)+?.*IP: ` + frame),
+		"BUG: unable to handle kernel %[1]v in %[2]v",
+		Bug,
+	},
+	{
+		"general protection fault",
+		regexp.MustCompile(`general protection fault(?:.*\n)+?.*RIP: ` + frame),
+		"general protection fault in %[1]v",
+		Bug,
+	},
+	{
+		"BUG: soft lockup",
+		regexp.MustCompile(`BUG: soft lockup`),
+		"BUG: soft lockup",
+		Hang,
+	},
+	{
+		"BUG: workqueue lockup",
+		regexp.MustCompile(`BUG: workqueue lockup`),
+		"BUG: workqueue lockup",
+		Hang,
+	},
+	{
+		"BUG: spinlock",
+		regexp.MustCompile(`BUG: spinlock ([a-z ]+) on CPU`),
+		"BUG: spinlock %[1]v",
+		Bug,
+	},
+	{
+		"BUG: sleeping function called from invalid context",
+		regexp.MustCompile(`BUG: sleeping function called from invalid context at ([^ \n]+)`),
+		"BUG: sleeping function called from invalid context at %[1]v",
+		Bug,
+	},
+	{
+		"WARNING:",
+		regexp.MustCompile(`WARNING: .* at [^ \n]+(?: |\n)` + frame),
+		"WARNING in %[1]v",
+		Warning,
+	},
+	{
+		"INFO: task",
+		regexp.MustCompile(`INFO: task .* blocked for more than [0-9]+ seconds`),
+		"INFO: task hung",
+		Hang,
+	},
+	{
+		"INFO: rcu_",
+		regexp.MustCompile(`INFO: rcu_(?:preempt|sched|bh) (?:self-)?detected(?: expedited)? stall`),
+		"INFO: rcu detected stall",
+		Hang,
+	},
+	{
+		"INFO: suspicious RCU usage",
+		regexp.MustCompile(`INFO: suspicious RCU usage(?:.*\n)+?([a-zA-Z0-9_/.\-]+\.[ch]:[0-9]+) `),
+		"suspicious RCU usage at %[1]v",
+		Lockdep,
+	},
+	{
+		"Kernel panic",
+		regexp.MustCompile(`Kernel panic - not syncing: ([^\n]*)`),
+		"kernel panic: %[1]v",
+		Panic,
+	},
+}
+
+// fallbackTypes classify oopses that don't match any format.
+var fallbackTypes = []struct {
+	header string
+	typ    Type
+}{
+	{"locking dependency detected", Lockdep},
+	{"recursive locking detected", Lockdep},
+	{"inconsistent lock state", Lockdep},
+	{"BUG: KASAN:", KASAN},
+	{"WARNING:", Warning},
+	{"Kernel panic", Panic},
+	{"UBSAN:", UBSAN},
+	{"INFO: task", Hang},
+	{"BUG", Bug},
+	{"unable to handle", Bug},
+	{"Unable to handle kernel", Bug},
+	{"general protection fault", Bug},
+}
+
+var bareAddr = regexp.MustCompile(`\b[0-9a-f]{8,}\b`)
+
+var titleRewrites = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`\+0x[0-9a-f]+/0x[0-9a-f]+`), ""},
+	{regexp.MustCompile(`\b0x[0-9a-f]{6,}\b`), "ADDR"},
+	{regexp.MustCompile(`CPU: [0-9]+ PID: [0-9]+ `), ""},
+	{regexp.MustCompile(`syz-executor[0-9]+`), "syz-executor"},
+	{regexp.MustCompile(`(task [^ :]+):[0-9]+`), "$1"},
+}
+
+var oopses = [][]byte{
+	[]byte("Kernel panic"),
+	[]byte("BUG:"),
+	[]byte("kernel BUG"),
+	[]byte("WARNING:"),
+	[]byte("INFO:"),
+	[]byte("unable to handle"),
+	[]byte("Unable to handle kernel"),
+	[]byte("general protection fault"),
+	[]byte("UBSAN:"),
+	[]byte("unreferenced object"),
+}
+
+// oopsIgnores are messages that match oopses but are not bugs by themselves.
+var oopsIgnores = [][]byte{
+	// Printed by lock dumps (e.g. sysrq-d) after lockdep has turned itself off.
+	[]byte("INFO: lockdep is turned off"),
+}
+
+func ignoredOops(line []byte) bool {
+	for _, ignore := range oopsIgnores {
+		if bytes.HasPrefix(line, ignore) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,421 @@
+// Copyright 2015 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDesc(t *testing.T) {
+	tests := map[string]string{
+		`
+[   50.583499] something 
+[   50.583499] BUG: unable to handle kernel paging request at 00000000ffffff8a
+[   50.583499] IP: [<     inline     >] list_del include/linux/list.h:107 
+`: "BUG: unable to handle kernel paging request at 00000000ffffff8a",
+		`
+[   50.583499] something
+[   50.583499] INFO: rcu_sched self-detected stall on CPU
+[   50.583499]         0: (20822 ticks this GP) idle=94b/140000000000001/0
+`: "INFO: rcu_sched self-detected stall on CPU",
+		`
+[   50.583499] general protection fault: 0000 [#1] SMP KASAN
+[   50.583499] Modules linked in: 
+`: "general protection fault: 0000 [#1] SMP KASAN",
+		`
+[   50.583499] BUG: unable to handle kernel NULL pointer dereference at 000000000000003a
+[   50.583499] Modules linked in: 
+`: "BUG: unable to handle kernel NULL pointer dereference at 000000000000003a",
+		`
+[   50.583499] WARNING: CPU: 2 PID: 2636 at ipc/shm.c:162 shm_open+0x74/0x80()
+[   50.583499] Modules linked in: 
+`: "WARNING: CPU: 2 PID: 2636 at ipc/shm.c:162 shm_open+0x74/0x80()",
+		`
+[   50.583499] BUG: KASAN: use after free in remove_wait_queue+0xfb/0x120 at addr ffff88002db3cf50
+[   50.583499] Write of size 8 by task syzkaller_execu/10568 
+`: "BUG: KASAN: use after free in remove_wait_queue+0xfb/0x120 at addr ffff88002db3cf50",
+		`
+BUG UNIX (Not tainted): kasan: bad access detected
+`: "",
+		`
+[   50.583499] [ INFO: possible circular locking dependency detected ]
+[   50.583499] 4.3.0+ #30 Not tainted
+`: "INFO: possible circular locking dependency detected ]",
+		`
+BUG: unable to handle kernel paging request at 00000000ffffff8a
+IP: [<ffffffff810a376f>] __call_rcu.constprop.76+0x1f/0x280 kernel/rcu/tree.c:3046
+`: "BUG: unable to handle kernel paging request at 00000000ffffff8a",
+		`
+==================================================================
+BUG: KASAN: slab-out-of-bounds in memcpy+0x1d/0x40 at addr ffff88003a6bd110
+Read of size 8 by task a.out/6260
+`: "BUG: KASAN: slab-out-of-bounds in memcpy+0x1d/0x40 at addr ffff88003a6bd110",
+		`
+[   50.583499] unreferenced object 0xffff880039a55260 (size 64):
+[   50.583499]   comm "executor", pid 11746, jiffies 4298984475 (age 16.078s)
+`: "unreferenced object 0xffff880039a55260 (size 64):",
+		`
+[   50.583499] UBSAN: Undefined behaviour in kernel/time/hrtimer.c:310:16
+[   50.583499] signed integer overflow:
+`: "UBSAN: Undefined behaviour in kernel/time/hrtimer.c:310:16",
+		`
+------------[ cut here ]------------
+kernel BUG at fs/buffer.c:1917!
+invalid opcode: 0000 [#1] SMP
+`: "kernel BUG at fs/buffer.c:1917!",
+		`
+BUG: sleeping function called from invalid context at include/linux/wait.h:1095 
+in_atomic(): 1, irqs_disabled(): 0, pid: 3658, name: syz-fuzzer 
+`: "BUG: sleeping function called from invalid context at include/linux/wait.h:1095 ",
+		`
+------------[ cut here ]------------
+WARNING: CPU: 3 PID: 1975 at fs/locks.c:241
+locks_free_lock_context+0x118/0x180()
+`: "WARNING: CPU: 3 PID: 1975 at fs/locks.c:241",
+		`
+[   50.583499] Showing all locks held in the system:
+[   50.583499] INFO: lockdep is turned off.
+`: "",
+	}
+	for log, crash := range tests {
+		if strings.Index(log, "\r\n") != -1 {
+			continue
+		}
+		tests[strings.Replace(log, "\n", "\r\n", -1)] = crash
+	}
+	for log, crash := range tests {
+		rep := Parse([]byte(log))
+		if ContainsCrash([]byte(log)) != (rep != nil) {
+			t.Fatalf("ContainsCrash disagrees with Parse on:\n%v", log)
+		}
+		if rep == nil {
+			if crash != "" {
+				t.Fatalf("did not find crash message '%v' in:\n%v", crash, log)
+			}
+			continue
+		}
+		if crash == "" {
+			t.Fatalf("found bogus crash message '%v' in:\n%v", rep.Desc, log)
+		}
+		if rep.Desc != crash {
+			t.Fatalf("extracted bad crash message:\n%v\nwant:\n%v", rep.Desc, crash)
+		}
+		if bytes.Contains(rep.Report, []byte("\r")) || bytes.Contains(rep.Report, []byte("[   50.583499]")) {
+			t.Fatalf("report is not cleaned up:\n%s", rep.Report)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := map[string]string{
+		"BUG: unable to handle kernel paging request at 00000000ffffff8a":                     "BUG: unable to handle kernel paging request at ADDR",
+		"WARNING: CPU: 2 PID: 2636 at ipc/shm.c:162 shm_open+0x74/0x80()":                     "WARNING: at ipc/shm.c:162 shm_open()",
+		"BUG: KASAN: use after free in remove_wait_queue+0xfb/0x120 at addr ffff88002db3cf50": "BUG: KASAN: use after free in remove_wait_queue at addr ADDR",
+		"unreferenced object 0xffff880039a55260 (size 64):":                                   "unreferenced object ADDR (size 64):",
+		"INFO: task syz-executor3:10568 blocked for more than 120 seconds.":                   "INFO: task syz-executor blocked for more than 120 seconds.",
+		"kernel BUG at fs/buffer.c:1917!":                                                     "kernel BUG at fs/buffer.c:1917!",
+		"general protection fault: 0000 [#1] SMP KASAN":                                       "general protection fault: 0000 [#1] SMP KASAN",
+		"BUG: sleeping function called from invalid context at include/linux/wait.h:1095 ":    "BUG: sleeping function called from invalid context at include/linux/wait.h:1095",
+		"lost connection": "lost connection",
+		"INFO: rcu_sched detected stalls on CPUs/tasks: 1234567 ticks": "INFO: rcu_sched detected stalls on CPUs/tasks: 1234567 ticks",
+		"WARNING in deadbeefcafe":                                      "WARNING in deadbeefcafe",
+	}
+	for desc, want := range tests {
+		if got := Title(desc); got != want {
+			t.Errorf("Title(%q) = %q, want %q", desc, got, want)
+		}
+	}
+}
+
+func TestLockdepTitle(t *testing.T) {
+	tests := []struct {
+		output string
+		title  string
+	}{
+		{`
+[   50.583499] ======================================================
+[   50.583499] [ INFO: possible circular locking dependency detected ]
+[   50.583499] 4.10.0+ #1 Not tainted
+[   50.583499] -------------------------------------------------------
+[   50.583499] syz-executor3/12345 is trying to acquire lock:
+[   50.583499]  (&mm->mmap_sem){++++++}, at: [<ffffffff8172f4a1>] __might_fault+0x101/0x1d0 mm/memory.c:4133
+[   50.583499] 
+[   50.583499] but task is already holding lock:
+[   50.583499]  (&pipe->mutex/1){+.+.+.}, at: [<ffffffff81ad1fda>] pipe_lock_nested fs/pipe.c:66 [inline]
+`, "possible deadlock in __might_fault"},
+		{`
+WARNING: possible recursive locking detected
+4.15.0-rc1+ #1 Not tainted
+--------------------------------------------
+syz-executor0/3152 is trying to acquire lock:
+ (&sb->s_type->i_mutex_key#10){+.+.}, at: inode_lock include/linux/fs.h:713 [inline]
+`, "possible deadlock in inode_lock"},
+		{`
+[ INFO: inconsistent lock state ]
+inconsistent {SOFTIRQ-ON-W} -> {IN-SOFTIRQ-W} usage.
+swapper/0/0 [HC0[0]:SC1[1]:HE1:SE0] takes:
+ (&(&q->lock)->rlock){+.?...}, at: [<ffffffff81234567>] rt_spin_lock+0x2f/0x40
+`, "inconsistent lock state in rt_spin_lock"},
+		{`
+[ INFO: possible circular locking dependency detected ]
+syz-executor3/12345 is trying to acquire lock:
+`, ""},
+		{`
+BUG: unable to handle kernel paging request at 00000000ffffff8a
+ (&mm->mmap_sem){++++++}, at: [<ffffffff8172f4a1>] __might_fault+0x101/0x1d0
+`, ""},
+	}
+	for i, test := range tests {
+		desc, _, _, found := find([]byte(test.output))
+		if !found {
+			t.Fatalf("test %v: did not find crash", i)
+		}
+		title, ok := lockdepTitle(desc, []byte(test.output))
+		if ok != (test.title != "") || title != test.title {
+			t.Errorf("test %v: title %q (%v), want %q", i, title, ok, test.title)
+		}
+		if !ok {
+			continue
+		}
+		rep := Parse([]byte(test.output))
+		if rep.Title != test.title || rep.Type != Lockdep {
+			t.Errorf("test %v: report title %q (%v), want %q", i, rep.Title, rep.Type, test.title)
+		}
+	}
+	// Lockdep title can't be extracted, but the report is still recognized.
+	rep := Parse([]byte("[ INFO: possible circular locking dependency detected ]\nsyz-executor3/12345 is trying to acquire lock:\n"))
+	if rep == nil || rep.Title != "INFO: possible circular locking dependency detected ]" {
+		t.Errorf("bad report for truncated lockdep splat: %+v", rep)
+	}
+}
+
+func TestLockdepOff(t *testing.T) {
+	if !LockdepOff([]byte("[   50.583499] turning off the locking correctness validator.\n")) {
+		t.Errorf("lockdep off is not detected")
+	}
+	if LockdepOff([]byte("[   50.583499] INFO: possible circular locking dependency detected\n")) {
+		t.Errorf("bogus lockdep off")
+	}
+}
+
+func TestParseLeaks(t *testing.T) {
+	output := `garbage
+unreferenced object 0xffff88003ca9d000 (size 1024):
+  comm "syz-executor0", pid 4588, jiffies 4294945076 (age 12.340s)
+  hex dump (first 32 bytes):
+    00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00  ................
+  backtrace:
+    [<ffffffff81b52ed5>] kmemleak_alloc+0x55/0xb0 mm/kmemleak.c:915
+    [<ffffffff81706c47>] __kmalloc+0x127/0x2f0
+    [<ffffffff8440eee7>] sock_alloc_send_pskb+0x1f7/0x6a0
+    [<ffffffffffffffff>] 0xffffffffffffffff
+unreferenced object 0xffff880039a55260 (size 64):
+  comm "syz-executor1", pid 4600, jiffies 4294945080 (age 12.300s)
+  backtrace:
+    [<ffffffff81b52ed5>] kmemleak_alloc+0x55/0xb0
+    [<ffffffff81706c47>] kmem_cache_alloc_trace+0x127/0x2f0
+    [<ffffffff8440eee8>] tun_set_iff.constprop.42+0x1f7/0x6a0
+    [<ffffffff8440eee9>] __tun_chr_ioctl+0x17/0x60
+`
+	leaks := ParseLeaks([]byte(output))
+	if len(leaks) != 2 {
+		t.Fatalf("got %v leaks, want 2", len(leaks))
+	}
+	titles := []string{"memory leak in sock_alloc_send_pskb", "memory leak in __tun_chr_ioctl"}
+	for i, leak := range leaks {
+		if leak.Title != titles[i] {
+			t.Errorf("leak %v: title %q, want %q", i, leak.Title, titles[i])
+		}
+		if !strings.HasPrefix(string(leak.Report), "unreferenced object 0x") {
+			t.Errorf("leak %v: bad report start:\n%s", i, leak.Report)
+		}
+	}
+	if strings.Contains(string(leaks[0].Report), "syz-executor1") {
+		t.Errorf("the first leak contains the second one:\n%s", leaks[0].Report)
+	}
+	if leaks := ParseLeaks(nil); len(leaks) != 0 {
+		t.Errorf("got %v leaks in empty output", len(leaks))
+	}
+}
+
+func TestSymbolize(t *testing.T) {
+	nm := `ffffffff81000000 0000000000000020 T foo
+ffffffff81000100 0000000000000080 t bar
+ffffffff81000200 0000000000000010 t dup
+ffffffff81000300 0000000000000010 t dup
+ffffffff82000000 0000000000000010 D foo_data
+`
+	text := `BUG: KASAN: use-after-free in bar+0x50/0x80 at addr ffff88003ca9d000
+RIP: 0010:[<ffffffff81000010>]  [<ffffffff81000010>] foo+0x10/0x20
+Call Trace:
+ [<ffffffff81000150>] bar+0x50/0x80
+ [<ffffffff81000305>] dup+0x5/0x10
+ baz+0x1/0x2
+`
+	// Note: the faulting pc is not moved back into the call.
+	a2l := `0xffffffff81000010
+foo
+/src/linux/mm/foo.c:10
+0xffffffff8100014f
+inl
+/src/linux/include/linux/inl.h:5 (discriminator 2)
+bar
+/src/linux/mm/bar.c:20
+`
+	want := `BUG: KASAN: use-after-free in bar+0x50/0x80 at addr ffff88003ca9d000
+RIP: 0010:[<ffffffff81000010>]  [<ffffffff81000010>] foo+0x10/0x20 mm/foo.c:10
+Call Trace:
+ [<     inline     >] inl include/linux/inl.h:5
+ [<ffffffff81000150>] bar+0x50/0x80 mm/bar.c:20
+ [<ffffffff81000305>] dup+0x5/0x10
+ baz+0x1/0x2
+`
+	symbols := parseSymbols([]byte(nm))
+	var pcs []uint64
+	for _, line := range strings.Split(text, "\n") {
+		if pc, ok := framePC([]byte(line), symbols); ok {
+			pcs = append(pcs, pc)
+		}
+	}
+	if len(pcs) != 2 || pcs[0] != 0xffffffff81000010 || pcs[1] != 0xffffffff8100014f {
+		t.Fatalf("bad pcs: %x", pcs)
+	}
+	frames, err := parseAddr2line([]byte(a2l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(symbolizeText([]byte(text), symbols, frames, "/src/linux/"))
+	if got != want {
+		t.Fatalf("bad symbolized report:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExtractGuiltyFile(t *testing.T) {
+	tests := []struct {
+		report string
+		guilty string
+	}{
+		{
+			`BUG: KASAN: use-after-free in skb_put+0x10/0x20
+Read of size 8 by task syz-executor0/4588
+Call Trace:
+ [<     inline     >] __dump_stack lib/dump_stack.c:15
+ [<ffffffff82d9b7e9>] dump_stack+0x12e/0x185 lib/dump_stack.c:51
+ [<ffffffff817e5c11>] kasan_report+0x491/0x4c0 mm/kasan/report.c:303
+ [<     inline     >] skb_tail_pointer include/linux/skbuff.h:1923
+ [<ffffffff8440eee7>] skb_put+0x10/0x20 net/core/skbuff.c:1512
+ [<ffffffff8440ef00>] tun_get_user+0x1f7/0x6a0 drivers/net/tun.c:1234
+Allocated by task 4588:
+ [<ffffffff81706c47>] kmem_cache_alloc+0x127/0x2f0 mm/slub.c:2700
+ [<ffffffff8440ee00>] sock_alloc_send_pskb+0x1f7/0x6a0 net/core/sock.c:1800
+`,
+			"net/core/skbuff.c",
+		},
+		{
+			`WARNING: CPU: 0 PID: 4588 at /src/linux/fs/ext4/inode.c:3596 ext4_set_page_dirty+0x1/0x2
+Call Trace:
+`,
+			"/src/linux/fs/ext4/inode.c",
+		},
+		{
+			`BUG: sleeping function called from invalid context at kernel/locking/mutex.c:620
+Call Trace:
+ [<ffffffff81234567>] ___might_sleep+0x1/0x2 kernel/sched/core.c:7880
+ [<ffffffff81234567>] sctp_sendmsg+0x1/0x2 net/sctp/socket.c:1950
+`,
+			"net/sctp/socket.c",
+		},
+		{
+			`BUG: KASAN: use-after-free in skb_put+0x10/0x20
+Call Trace:
+ [<ffffffff8440eee7>] skb_put+0x10/0x20
+`,
+			"",
+		},
+	}
+	for i, test := range tests {
+		if guilty := ExtractGuiltyFile([]byte(test.report)); guilty != test.guilty {
+			t.Errorf("report %v: guilty file %q, want %q", i, guilty, test.guilty)
+		}
+	}
+	subsystems := map[string]string{
+		"net/ipv4/tcp.c":           "net/ipv4",
+		"mm/mmap.c":                "mm",
+		"drivers/usb/core/hub.c":   "drivers/usb",
+		"kernel/bpf/verifier.c":    "kernel",
+		"fs/inode.c":               "fs",
+		"/src/linux/fs/ext4/dir.c": "",
+		"":                         "",
+	}
+	for file, subsys := range subsystems {
+		if got := GuiltySubsystem(file); got != subsys {
+			t.Errorf("file %q: subsystem %q, want %q", file, got, subsys)
+		}
+	}
+}
+
+// TestCorpus checks parsing of console logs in testdata. Every file starts with a header:
+//
+//	TITLE: expected title (empty if the log does not contain a crash)
+//	TYPE: expected type
+//	REPORT: a line that the report must end with (optional)
+//
+// followed by an empty line and the console log.
+func TestCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no test logs in testdata")
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var title, typ, last string
+		s := bufio.NewScanner(bytes.NewReader(data))
+		hdr := 0
+		for s.Scan() {
+			hdr += len(s.Bytes()) + 1
+			line := s.Text()
+			if line == "" {
+				break
+			}
+			switch {
+			case strings.HasPrefix(line, "TITLE:"):
+				title = strings.TrimSpace(strings.TrimPrefix(line, "TITLE:"))
+			case strings.HasPrefix(line, "TYPE:"):
+				typ = strings.TrimSpace(strings.TrimPrefix(line, "TYPE:"))
+			case strings.HasPrefix(line, "REPORT:"):
+				last = strings.TrimSpace(strings.TrimPrefix(line, "REPORT:"))
+			default:
+				t.Fatalf("%v: bad header line %q", file, line)
+			}
+		}
+		output := data[hdr:]
+		rep := Parse(output)
+		if rep == nil {
+			if title != "" {
+				t.Errorf("%v: did not find crash, want %q", file, title)
+			}
+			continue
+		}
+		if rep.Title != title || rep.Type.String() != typ {
+			t.Errorf("%v: got %q (%v), want %q (%v)", file, rep.Title, rep.Type, title, typ)
+		}
+		if last != "" && !bytes.HasSuffix(rep.Report, []byte(last+"\n")) {
+			t.Errorf("%v: report does not end with %q:\n%s", file, last, rep.Report)
+		}
+		if !bytes.Contains(output[rep.Start:rep.End], []byte(rep.Desc)) {
+			t.Errorf("%v: crash region does not contain the description", file)
+		}
+	}
+}
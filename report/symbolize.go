@@ -0,0 +1,252 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Symbolize annotates stack frames in report text with source file:line
+// and adds frames of inlined functions, e.g.:
+//
+//	[<ffffffff81234567>] foo+0x10/0x20
+//
+// becomes:
+//
+//	[<     inline     >] bar mm/bar.h:12
+//	[<ffffffff81234567>] foo+0x10/0x20 mm/foo.c:34
+//
+// Frames are located by function name and offset, so KASLR does not matter.
+// strip is a prefix (kernel source dir) that is removed from file names.
+// Frames that can't be symbolized (e.g. vmlinux has no debug info) are left intact.
+func Symbolize(vmlinux, strip string, text []byte) ([]byte, error) {
+	symbols, err := loadSymbols(vmlinux)
+	if err != nil {
+		return nil, err
+	}
+	var pcs []uint64
+	for _, line := range bytes.Split(text, []byte("\n")) {
+		if pc, ok := framePC(line, symbols); ok {
+			pcs = append(pcs, pc)
+		}
+	}
+	if len(pcs) == 0 {
+		return text, nil
+	}
+	frames, err := addr2line(vmlinux, pcs)
+	if err != nil {
+		return nil, err
+	}
+	return symbolizeText(text, symbols, frames, strip), nil
+}
+
+type symbol struct {
+	addr uint64
+	size uint64
+}
+
+// srcFrame is a single (possibly inlined) frame of a symbolized pc.
+type srcFrame struct {
+	fn   string
+	file string
+	line int
+}
+
+// reportFrame matches stack frame lines "func+0x1/0x2" (" ? func+0x1/0x2" for unreliable
+// frames) and the faulting instruction "RIP: 0010:func+0x1/0x2", on old kernels
+// the function is preceded by "[<ffffffff81234567>] ". Other lines that mention
+// functions (e.g. "BUG: KASAN: use-after-free in func+0x1/0x2") are not stack frames.
+var reportFrame = regexp.MustCompile(`^[ \t]*(?:R?IP: (?:[0-9a-f]{4}:)?)?(?:\[<[0-9a-f]+>\] +)*(?:\? )?` +
+	`([a-zA-Z0-9_.]+)\+0x([0-9a-f]+)/0x([0-9a-f]+)`)
+
+// symbolCache holds parsed symbol tables of vmlinux files, so that nm runs once per vmlinux
+// rather than once per crash. An entry is reloaded if the file is replaced (size or mtime change).
+var symbolCache struct {
+	sync.Mutex
+	entries map[string]*symbolCacheEntry
+}
+
+type symbolCacheEntry struct {
+	size    int64
+	mtime   time.Time
+	symbols map[string][]symbol
+}
+
+func loadSymbols(vmlinux string) (map[string][]symbol, error) {
+	stat, err := os.Stat(vmlinux)
+	if err != nil {
+		return nil, err
+	}
+	symbolCache.Lock()
+	defer symbolCache.Unlock()
+	if ent := symbolCache.entries[vmlinux]; ent != nil && ent.size == stat.Size() && ent.mtime.Equal(stat.ModTime()) {
+		return ent.symbols, nil
+	}
+	out, err := exec.Command("nm", "-S", vmlinux).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run nm on %v: %v", vmlinux, err)
+	}
+	if symbolCache.entries == nil {
+		symbolCache.entries = make(map[string]*symbolCacheEntry)
+	}
+	ent := &symbolCacheEntry{stat.Size(), stat.ModTime(), parseSymbols(out)}
+	symbolCache.entries[vmlinux] = ent
+	return ent.symbols, nil
+}
+
+// parseSymbols parses output of nm -S. Static functions can have the same name,
+// so every name maps to all symbols with that name.
+func parseSymbols(out []byte) map[string][]symbol {
+	symbols := make(map[string][]symbol)
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		// ffffffff81234567 0000000000000123 T foo
+		fields := strings.Fields(s.Text())
+		if len(fields) != 4 || (fields[2] != "t" && fields[2] != "T") {
+			continue
+		}
+		addr, err := strconv.ParseUint(fields[0], 16, 64)
+		if err != nil {
+			continue
+		}
+		size, err := strconv.ParseUint(fields[1], 16, 64)
+		if err != nil {
+			continue
+		}
+		symbols[fields[3]] = append(symbols[fields[3]], symbol{addr, size})
+	}
+	return symbols
+}
+
+// framePC returns pc of the stack frame on line. Frames other than the faulting
+// instruction (IP:/RIP: lines) hold return addresses, so pc is moved back into the call.
+// Ambiguous frames (several functions with the same name and size) are not symbolized.
+func framePC(line []byte, symbols map[string][]symbol) (uint64, bool) {
+	m := reportFrame.FindSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	off, err := strconv.ParseUint(string(m[2]), 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	size, err := strconv.ParseUint(string(m[3]), 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	var pc uint64
+	found := false
+	for _, sym := range symbols[string(m[1])] {
+		if sym.size != size {
+			continue
+		}
+		if found {
+			return 0, false
+		}
+		pc, found = sym.addr+off, true
+	}
+	if !found {
+		return 0, false
+	}
+	if off != 0 && !bytes.Contains(line, []byte("IP: ")) {
+		pc--
+	}
+	return pc, true
+}
+
+// addr2line symbolizes pcs, frames of every pc are ordered from the innermost inlined one.
+func addr2line(vmlinux string, pcs []uint64) (map[uint64][]srcFrame, error) {
+	cmd := exec.Command("addr2line", "-a", "-i", "-f", "-e", vmlinux)
+	input := new(bytes.Buffer)
+	for _, pc := range pcs {
+		fmt.Fprintf(input, "0x%x\n", pc)
+	}
+	cmd.Stdin = input
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run addr2line on %v: %v", vmlinux, err)
+	}
+	return parseAddr2line(out)
+}
+
+// parseAddr2line parses output of addr2line -a -i -f: every pc is printed on its own line
+// followed by a function name line and a file:line line for every frame.
+func parseAddr2line(out []byte) (map[uint64][]srcFrame, error) {
+	frames := make(map[uint64][]srcFrame)
+	s := bufio.NewScanner(bytes.NewReader(out))
+	var pc uint64
+	fn, expectFunc := "", false
+	for s.Scan() {
+		ln := s.Text()
+		if strings.HasPrefix(ln, "0x") {
+			v, err := strconv.ParseUint(ln, 0, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse pc in addr2line output: %v", err)
+			}
+			pc, expectFunc = v, true
+			continue
+		}
+		if expectFunc {
+			fn, expectFunc = ln, false
+			continue
+		}
+		expectFunc = true
+		if paren := strings.Index(ln, " ("); paren != -1 {
+			ln = ln[:paren] // strip " (discriminator N)"
+		}
+		colon := strings.LastIndexByte(ln, ':')
+		if colon == -1 {
+			continue
+		}
+		file := ln[:colon]
+		line, err := strconv.Atoi(ln[colon+1:])
+		if err != nil || file == "" || file == "??" || line <= 0 {
+			continue
+		}
+		frames[pc] = append(frames[pc], srcFrame{fn, file, line})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// symbolizeText annotates frames in text with symbolized frames.
+func symbolizeText(text []byte, symbols map[string][]symbol, frames map[uint64][]srcFrame, strip string) []byte {
+	buf := new(bytes.Buffer)
+	lines := bytes.Split(text, []byte("\n"))
+	for i, line := range lines {
+		pc, ok := framePC(line, symbols)
+		if fs := frames[pc]; ok && len(fs) != 0 {
+			indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+			for _, f := range fs[:len(fs)-1] {
+				fmt.Fprintf(buf, "%s[<     inline     >] %v %v:%v\n", indent, f.fn, stripFile(f.file, strip), f.line)
+			}
+			f := fs[len(fs)-1]
+			line = append(append([]byte{}, line...), fmt.Sprintf(" %v:%v", stripFile(f.file, strip), f.line)...)
+		}
+		buf.Write(line)
+		if i != len(lines)-1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+func stripFile(file, strip string) string {
+	if strip == "" {
+		return file
+	}
+	strip = strings.TrimRight(strip, "/") + "/"
+	return strings.TrimPrefix(file, strip)
+}
@@ -0,0 +1,362 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package repro extracts a minimal reproducer program from a crash log
+// (console output with "executing program" entries).
+// It is used by syz-manager for automatic reproduction and by syz-repro tool.
+package repro
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/csource"
+	"github.com/google/syzkaller/fileutil"
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/report"
+	"github.com/google/syzkaller/vm"
+)
+
+type Result struct {
+	Prog   *prog.Prog
+	Opts   csource.Options // execution mode (threaded, collide, procs) in which Prog reproduces the crash
+	CRepro bool            // C program generated from Prog with Opts (and Repeat) also reproduces the crash
+	Title  string          // description of the crash that Prog triggers
+}
+
+type context struct {
+	cfg          *config.Config
+	crashDesc    string
+	instances    chan *instance
+	bootRequests chan bool
+	bootErrors   chan error
+	budget       int // number of last programs of every proc that are suspected
+	stop         <-chan bool
+	lastDesc     string
+	err          error
+}
+
+type instance struct {
+	vm.Instance
+	execprogBin string
+	executorBin string
+}
+
+// Run tries to reproduce the crash in crashLog using count fresh VMs created from cfg.
+// Returns nil Result if no program reproduces the crash.
+func Run(crashLog []byte, cfg *config.Config, count int) (*Result, error) {
+	return RunBudget(crashLog, cfg, count, 1, nil)
+}
+
+// ErrStopped is returned by RunBudget if reproduction is aborted with stop.
+var ErrStopped = errors.New("reproduction is stopped")
+
+// RunBudget is Run that suspects the last budget programs of every proc instead of only
+// the last one. Larger budgets catch crashes caused by earlier programs, but take longer.
+// Closing stop (if not nil) aborts the running test and makes RunBudget close all VMs
+// and return ErrStopped.
+func RunBudget(crashLog []byte, cfg *config.Config, count, budget int, stop <-chan bool) (*Result, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("no VMs for reproduction")
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Syzkaller, "bin", "syz-execprog")); err != nil {
+		return nil, fmt.Errorf("bin/syz-execprog is missing (run 'make execprog')")
+	}
+	entries := prog.ParseLog(crashLog)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("crash log does not contain any programs")
+	}
+	rep := report.Parse(crashLog)
+	if rep == nil {
+		return nil, fmt.Errorf("can't find crash message in the log")
+	}
+	crashStart := rep.Start
+	ctx := &context{
+		cfg:          cfg,
+		crashDesc:    rep.Title,
+		instances:    make(chan *instance, count),
+		bootRequests: make(chan bool, count),
+		bootErrors:   make(chan error, count),
+		budget:       budget,
+		stop:         stop,
+	}
+	if ctx.budget < 1 {
+		ctx.budget = 1
+	}
+	ctx.logf("parsed %v programs", len(entries))
+	var wg sync.WaitGroup
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		ctx.bootRequests <- true
+		go func() {
+			defer wg.Done()
+			for range ctx.bootRequests {
+				inst, err := ctx.boot()
+				if err != nil {
+					ctx.bootErrors <- err
+					return
+				}
+				ctx.instances <- inst
+			}
+		}()
+	}
+
+	res := ctx.repro(entries, crashStart)
+
+	close(ctx.bootRequests)
+	wg.Wait()
+	for {
+		select {
+		case inst := <-ctx.instances:
+			inst.Close()
+		default:
+			return res, ctx.err
+		}
+	}
+}
+
+func (ctx *context) boot() (*instance, error) {
+	vmCfg, err := config.CreateVMConfig(ctx.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VM config: %v", err)
+	}
+	inst, err := vm.Create(ctx.cfg.Type, vmCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VM: %v", err)
+	}
+	execprogBin, err := inst.Copy(filepath.Join(ctx.cfg.Syzkaller, "bin", "syz-execprog"))
+	if err != nil {
+		inst.Close()
+		return nil, fmt.Errorf("failed to copy to VM: %v", err)
+	}
+	executorBin, err := inst.Copy(filepath.Join(ctx.cfg.Syzkaller, "bin", "syz-executor"))
+	if err != nil {
+		inst.Close()
+		return nil, fmt.Errorf("failed to copy to VM: %v", err)
+	}
+	return &instance{inst, execprogBin, executorBin}, nil
+}
+
+func (ctx *context) repro(entries []*prog.LogEntry, crashStart int) *Result {
+	// Cut programs that were executed after crash.
+	for i, ent := range entries {
+		if ent.Start > crashStart {
+			entries = entries[:i]
+			break
+		}
+	}
+	// Extract last ctx.budget programs on every proc, the most recent first.
+	perProc := make(map[int]int)
+	var suspected []*prog.LogEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		ent := entries[i]
+		if perProc[ent.Proc] < ctx.budget {
+			perProc[ent.Proc]++
+			suspected = append(suspected, ent)
+		}
+	}
+	ctx.logf("%v suspected programs", len(suspected))
+	// Execute the suspected programs in the most aggressive mode.
+	opts := csource.Options{
+		Threaded: true,
+		Collide:  true,
+		Procs:    ctx.cfg.Procs,
+	}
+	if opts.Procs < 1 {
+		opts.Procs = 1
+	}
+	var p *prog.Prog
+	multiplier := 1
+	for ; p == nil && multiplier <= 100 && ctx.err == nil; multiplier *= 10 {
+		for _, ent := range suspected {
+			if ctx.testProg(ent.P, multiplier, opts) {
+				p = ent.P
+				break
+			}
+		}
+	}
+	if p == nil {
+		ctx.logf("no program crashed")
+		return nil
+	}
+	ctx.logf("minimizing program")
+
+	p, _ = prog.Minimize(p, -1, func(p1 *prog.Prog, callIndex int) bool {
+		return ctx.testProg(p1, multiplier, opts)
+	})
+
+	// Simplify the execution mode as long as the program still crashes:
+	// collide requires threaded, procs are independent of both.
+	simplify := func(change func(opts *csource.Options)) bool {
+		opts1 := opts
+		change(&opts1)
+		if !ctx.testProg(p, multiplier, opts1) {
+			return false
+		}
+		opts = opts1
+		return true
+	}
+	if simplify(func(opts *csource.Options) { opts.Collide = false }) {
+		simplify(func(opts *csource.Options) { opts.Threaded = false })
+	}
+	if opts.Procs > 1 {
+		simplify(func(opts *csource.Options) { opts.Procs = 1 })
+	}
+	if ctx.err != nil {
+		return nil
+	}
+	res := &Result{
+		Prog:  p,
+		Opts:  opts,
+		Title: ctx.lastDesc,
+	}
+	if res.Title == "" {
+		res.Title = ctx.crashDesc
+	}
+	res.CRepro = ctx.testCProg(p, opts)
+	return res
+}
+
+func (ctx *context) testCProg(p *prog.Prog, opts csource.Options) bool {
+	opts.Repeat = true
+	src := csource.Write(p, opts)
+	srcf, err := fileutil.WriteTempFile(src)
+	if err != nil {
+		ctx.logf("%v", err)
+		return false
+	}
+	defer os.Remove(srcf)
+	bin, err := csource.Build(srcf)
+	if err != nil {
+		ctx.logf("%v", err)
+		return false
+	}
+	defer os.Remove(bin)
+	return ctx.testBin(bin)
+}
+
+func (ctx *context) returnInstance(inst *instance, res bool) {
+	if res {
+		// The test crashed, discard the VM and issue another boot request.
+		ctx.bootRequests <- true
+		inst.Close()
+	} else {
+		// The test did not crash, reuse the same VM in future.
+		ctx.instances <- inst
+	}
+}
+
+// getInstance returns nil if VMs can't be booted.
+func (ctx *context) getInstance() *instance {
+	if ctx.err != nil {
+		return nil
+	}
+	select {
+	case inst := <-ctx.instances:
+		return inst
+	case err := <-ctx.bootErrors:
+		ctx.err = err
+		return nil
+	case <-ctx.stop:
+		ctx.err = ErrStopped
+		return nil
+	}
+}
+
+// testProg executes p with syz-execprog in the mode specified by opts (Threaded, Collide and Procs).
+func (ctx *context) testProg(p *prog.Prog, multiplier int, opts csource.Options) (res bool) {
+	inst := ctx.getInstance()
+	if inst == nil {
+		return false
+	}
+	defer func() {
+		ctx.returnInstance(inst, res)
+	}()
+
+	pstr := p.Serialize()
+	progFile, err := fileutil.WriteTempFile(pstr)
+	if err != nil {
+		ctx.err = err
+		return false
+	}
+	defer os.Remove(progFile)
+	bin, err := inst.Copy(progFile)
+	if err != nil {
+		ctx.err = fmt.Errorf("failed to copy to VM: %v", err)
+		return false
+	}
+
+	repeat := 100
+	timeoutSec := 10 * repeat / opts.Procs
+	if opts.Threaded {
+		repeat *= 10
+		timeoutSec *= 1
+	}
+	repeat *= multiplier
+	timeoutSec *= multiplier
+	timeout := time.Duration(timeoutSec) * time.Second
+	command := fmt.Sprintf("%v -executor %v -cover=0 -procs=%v -repeat=%v -threaded=%v -collide=%v -dangerous=%v %v",
+		inst.execprogBin, inst.executorBin, opts.Procs, repeat, opts.Threaded, opts.Collide, ctx.cfg.Dangerous_Calls, bin)
+	ctx.logf("testing program (threaded=%v, collide=%v, procs=%v, repeat=%v, timeout=%v): %v",
+		opts.Threaded, opts.Collide, opts.Procs, repeat, timeout, p)
+	return ctx.testImpl(inst, command, timeout, false)
+}
+
+func (ctx *context) testBin(bin string) (res bool) {
+	inst := ctx.getInstance()
+	if inst == nil {
+		return false
+	}
+	defer func() {
+		ctx.returnInstance(inst, res)
+	}()
+
+	bin, err := inst.Copy(bin)
+	if err != nil {
+		ctx.err = fmt.Errorf("failed to copy to VM: %v", err)
+		return false
+	}
+	ctx.logf("testing compiled C program")
+	// The program executes the test in an endless loop, so timeout is the normal outcome.
+	return ctx.testImpl(inst, bin, time.Minute, true)
+}
+
+func (ctx *context) testImpl(inst vm.Instance, command string, timeout time.Duration, timeoutOK bool) (res bool) {
+	outc, errc, err := inst.Run(timeout, command)
+	if err != nil {
+		ctx.err = fmt.Errorf("failed to run command in VM: %v", err)
+		return false
+	}
+	var output []byte
+	for {
+		select {
+		case out := <-outc:
+			output = append(output, out...)
+			if rep := report.Parse(output); rep != nil {
+				ctx.logf("program crashed with '%s'", rep.Title)
+				ctx.lastDesc = rep.Title
+				return true
+			}
+		case err := <-errc:
+			if err != nil && !(timeoutOK && err == vm.TimeoutErr) {
+				ctx.logf("program crashed with result '%v'", err)
+				return true
+			}
+			ctx.logf("program did not crash")
+			return false
+		case <-ctx.stop:
+			ctx.err = ErrStopped
+			return false
+		}
+	}
+}
+
+func (ctx *context) logf(msg string, args ...interface{}) {
+	log.Printf("reproducing '%v': %v", ctx.crashDesc, fmt.Sprintf(msg, args...))
+}
@@ -10,28 +10,195 @@ type RpcInput struct {
 	Prog      []byte
 	CallIndex int
 	Cover     []uint32
+	Success   bool   // the input was saved because the call succeeded for the first time (errno feedback)
+	Origin    string // hash of the imported program the input descends from (see manager/provenance.go)
+	Resources []int  // resource-producing calls (see ResourceResult) that succeeded during triage
 }
 
+// ProtocolVersion is the version of the fuzzer<->manager protocol,
+// it must be incremented on incompatible changes of the Manager RPC types.
+const ProtocolVersion = 1
+
+// Key in all fuzzer requests is the manager RPC key (see Rpc_Key config param).
 type ConnectArgs struct {
-	Name string
+	Name    string
+	Key     string
+	Version int // ProtocolVersion of the fuzzer
 }
 
 type ConnectRes struct {
 	Prios        [][]float32
 	EnabledCalls string
+	Setup        []SetupAction
+	Values       map[string][]uint64 // interesting argument values (see prog.ValuePool)
+	// Syscalls disabled due to a crash storm, programs that use them must not be executed.
+	DisabledCalls string
+}
+
+// SetupAction is a shell command that the fuzzer runs once
+// before executing the first program that contains one of the Calls.
+type SetupAction struct {
+	Name    string
+	Command string
+	Calls   []int
+}
+
+type CheckArgs struct {
+	Name  string
+	Key   string
+	Calls []string
 }
 
 type NewInputArgs struct {
 	Name string
+	Key  string
 	RpcInput
+	Values map[string][]uint64 // argument values of the call that gave new coverage
 }
 
 type PollArgs struct {
-	Name  string
-	Stats map[string]uint64
+	Name      string
+	Key       string
+	Stats     map[string]uint64
+	CallStats map[string]CallStats // per-syscall stats since the previous poll
+
+	// Results of executions of unmodified corpus programs since the previous poll.
+	ResourceResults []ResourceResult
+
+	// Programs blocked on hard comparisons since the previous poll (with -concolic).
+	Blocked []BlockedProg
+}
+
+// BlockedProg is a program with a call that executes comparisons none of which can be
+// satisfied by substituting comparison operands into the call arguments (see prog.MutateWithHints),
+// e.g. checksums or values derived from the input. They are solved by a concolic service.
+type BlockedProg struct {
+	Prog  []byte
+	Call  int         // index of the blocked call
+	Comps [][2]uint64 // operands of comparisons executed by the call
+}
+
+// ResourceResult is the outcome of resource-producing calls (see prog.ResourceCalls)
+// of a single execution of a corpus program.
+type ResourceResult struct {
+	Sig    string // hash of the program (as used in the corpus database)
+	Calls  []int  // indices of resource-producing calls
+	Errnos []int  // errnos of the calls (0 on success, -1 if not executed)
+}
+
+// CallStats are execution stats of a single syscall.
+type CallStats struct {
+	Execs    uint64 // number of executions
+	Errors   uint64 // number of executions that returned an error
+	NewCover uint64 // number of new PCs the syscall has discovered
 }
 
 type PollRes struct {
-	Candidates [][]byte
-	NewInputs  []RpcInput
+	Candidates       [][]byte
+	CandidateOrigins []string // RpcInput.Origin for every candidate ("" if it was not imported)
+	NewInputs        []RpcInput
+
+	// Set when manager config was reloaded, the fuzzer needs to update the set of enabled calls.
+	Reconfigure   bool
+	EnabledCalls  string
+	DisabledCalls string // see ConnectRes.DisabledCalls
+	Prios         [][]float32
+
+	Profile string // pprof profile requested from web UI (cpu/heap/goroutine/threadcreate)
+}
+
+// ProfileArgs is a fuzzer pprof profile requested in PollRes.
+type ProfileArgs struct {
+	Name  string
+	Key   string
+	Kind  string
+	Data  []byte
+	Error string
+}
+
+// LeakArgs reports a memory leak found by a kmemleak scan in the fuzzer.
+type LeakArgs struct {
+	Name   string
+	Key    string
+	Title  string
+	Report []byte
+	Progs  [][]byte // programs executed before the scan, the most recent last
+}
+
+// HangArgs reports a program that could not be killed after the program timeout.
+type HangArgs struct {
+	Name string
+	Key  string
+	Prog []byte
+	Note string // how the program was executed (e.g. dropped caps, injected fault)
+}
+
+// DiskFullArgs reports that the guest ran out of disk space and the fuzzer could not free enough.
+type DiskFullArgs struct {
+	Name string
+	Key  string
+	Free uint64 // free bytes after cleanup
+}
+
+type HubConnectArgs struct {
+	Name   string   // manager name, must be listed in hub config
+	Key    string   // manager key from hub config
+	Fresh  bool     // manager has started with an empty corpus and needs the whole hub corpus
+	Calls  []string // syscalls enabled on the manager
+	Corpus [][]byte // whole manager corpus
+}
+
+type HubSyncArgs struct {
+	Name   string
+	Key    string
+	Add    [][]byte // programs added to the manager corpus since the last sync
+	Del    []string // hashes of programs deleted from the manager corpus since the last sync
+	Repros [][]byte // new reproducers found by the manager
+}
+
+type HubSyncRes struct {
+	Inputs       [][]byte // new programs from other managers
+	Repros       [][]byte // new reproducers from other managers
+	Sources      []string // names of managers that added Inputs (older hubs don't send them)
+	ReproSources []string // names of managers that sent Repros
+}
+
+type DashCrashArgs struct {
+	Name   string // manager name, must be listed in dashboard config
+	Key    string // manager key from dashboard config
+	Title  string
+	Kernel string // kernel version
+	Commit string // kernel git commit (if known)
+	Report []byte // oops part of the console output
+	Log    []byte // whole console output
+}
+
+type DashCrashRes struct {
+	Status string // status of the bug on the dashboard: open/fixed/invalid
+}
+
+type DashReproArgs struct {
+	Name  string
+	Key   string
+	Title string
+	Opts  string // execution options of Prog (as accepted by syz-execprog)
+	Prog  []byte
+	CProg []byte // standalone C reproducer
+}
+
+type DashPollArgs struct {
+	Name string
+	Key  string
+}
+
+// ConcolicSolveArgs are sent by the manager to the concolic service (cfg.Concolic_Addr)
+// in Concolic.Solve calls.
+type ConcolicSolveArgs struct {
+	Name    string // manager name
+	Key     string // manager key in concolic service
+	Blocked []BlockedProg
+}
+
+type ConcolicSolveRes struct {
+	Progs [][]byte // programs that satisfy some of the comparisons, the manager triages them as candidates
 }
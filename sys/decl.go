@@ -9,6 +9,12 @@ import (
 
 const ptrSize = 8
 
+// Seed is an example program attached to descriptions (see "seed" in sys.txt).
+type Seed struct {
+	Name string
+	Prog string
+}
+
 type Call struct {
 	ID       int
 	NR       int // kernel syscall number
@@ -68,6 +74,7 @@ const (
 	ResTimerid
 	ResIocbPtr
 	ResDrmCtx
+	ResTcpSeq
 )
 
 const (
@@ -116,6 +123,8 @@ const (
 	FdRandom
 	FdKcm
 	FdNetRom
+	FdPseudofs
+	FdUsb
 
 	IPCMsq
 	IPCSem
@@ -134,6 +143,7 @@ func ResourceKinds() []ResourceKind {
 		ResGid,
 		ResTimerid,
 		ResIocbPtr,
+		ResTcpSeq,
 	}
 }
 
@@ -146,10 +156,10 @@ func ResourceSubkinds(kind ResourceKind) []ResourceSubkind {
 			FdAlg, FdAlgConn, FdNfcRaw, FdNfcLlcp, FdBtHci, FdBtSco, FdBtL2cap,
 			FdBtRfcomm, FdBtHidp, FdBtCmtp, FdBtBnep, FdUnix, FdSctp, FdNetlink, FdKvm, FdKvmVm,
 			FdKvmCpu, FdSndSeq, FdSndTimer, FdSndControl, FdInputEvent, FdTun, FdRandom, FdKcm,
-			FdNetRom}
+			FdNetRom, FdPseudofs, FdUsb}
 	case ResIPC:
 		return []ResourceSubkind{IPCMsq, IPCSem, IPCShm}
-	case ResIOCtx, ResKey, ResInotifyDesc, ResPid, ResUid, ResGid, ResTimerid, ResIocbPtr, ResDrmCtx:
+	case ResIOCtx, ResKey, ResInotifyDesc, ResPid, ResUid, ResGid, ResTimerid, ResIocbPtr, ResDrmCtx, ResTcpSeq:
 		return []ResourceSubkind{ResAny}
 	default:
 		panic("unknown resource kind")
@@ -194,6 +204,8 @@ func (t ResourceType) Default() uintptr {
 		return 0
 	case ResDrmCtx:
 		return 0
+	case ResTcpSeq:
+		return 0
 	default:
 		panic("unknown resource type")
 	}
@@ -223,6 +235,8 @@ func (t ResourceType) SpecialValues() []uintptr {
 		return []uintptr{0}
 	case ResDrmCtx:
 		return []uintptr{0}
+	case ResTcpSeq:
+		return []uintptr{0}
 	default:
 		panic("unknown resource kind")
 	}
@@ -250,6 +264,8 @@ func (t ResourceType) Size() uintptr {
 		return 4
 	case ResDrmCtx:
 		return 4
+	case ResTcpSeq:
+		return 4
 	default:
 		panic("unknown resource kind")
 	}
@@ -286,11 +302,14 @@ const (
 	BufferFilesystem
 	BufferAlgType
 	BufferAlgName
+	BufferPseudofsValue // textual value written to a procfs/sysfs file (e.g. "1\n")
+	BufferFsImage       // superblock of a filesystem image, filesystem is specified by Fs
 )
 
 type BufferType struct {
 	TypeCommon
 	Kind BufferKind
+	Fs   string // filesystem for BufferFsImage (ext4, vfat, btrfs)
 }
 
 func (t BufferType) Size() uintptr {
@@ -323,7 +342,9 @@ func (t VmaType) Align() uintptr {
 type LenType struct {
 	TypeCommon
 	TypeSize uintptr
-	ByteSize bool // want size in bytes instead of array size
+	ByteSize bool    // want size in bytes instead of array size
+	Unit     uintptr // for ByteSize, size is measured in units of Unit bytes (e.g. 8 for BPF instructions)
+	Tag      bool    // want tag of the chosen option of a tagged union instead of size
 	Buf      string
 }
 
@@ -401,8 +422,16 @@ func (t IntType) Align() uintptr {
 	return t.Size()
 }
 
+type FilenameKind int
+
+const (
+	FilenamePlain    FilenameKind = iota
+	FilenamePseudofs              // writable procfs/sysfs/debugfs file discovered in the guest
+)
+
 type FilenameType struct {
 	TypeCommon
+	Kind FilenameKind
 }
 
 func (t FilenameType) Size() uintptr {
@@ -479,6 +508,7 @@ func (t StructType) Align() uintptr {
 type UnionType struct {
 	TypeCommon
 	Options []Type
+	Tags    []uintptr // values of the tag field for options of tagged unions, nil for untagged unions
 	varlen  bool
 }
 
@@ -495,6 +525,19 @@ func (t UnionType) Size() uintptr {
 	return size
 }
 
+// Tag returns the tag value for the option opt of a tagged union.
+func (t UnionType) Tag(opt Type) (uintptr, bool) {
+	if t.Tags == nil {
+		return 0, false
+	}
+	for i, opt1 := range t.Options {
+		if opt1.Name() == opt.Name() {
+			return t.Tags[i], true
+		}
+	}
+	return 0, false
+}
+
 func (t UnionType) Align() uintptr {
 	var align uintptr
 	for _, opt := range t.Options {
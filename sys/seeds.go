@@ -0,0 +1,14 @@
+// AUTOGENERATED FILE
+package sys
+
+var Seeds = []Seed{
+	{Name: "tty_pty_ldisc", Prog: "mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nr0 = open$ptmx(&(0x7f0000000000)=\"2f6465762f70746d7800\", 0x2, 0x0)\nr1 = syz_open_pts(r0, 0x2)\nioctl$TIOCSETD(r1, 0x5423, &(0x7f0000000000+0x100)=0xf)\nioctl$HCIUARTSETPROTO(r1, 0x400455c8, 0x0)\nwrite(r0, &(0x7f0000000000+0x200)=\"040e0401030c00\", 0x7)\n"},
+	{Name: "bpf_map_lookup", Prog: "mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nr0 = bpf$MAP_CREATE(0x0, &(0x7f0000000000)={0x1, 0x4, 0x8, 0x10}, 0x10)\nr1 = bpf$PROG_LOAD(0x5, &(0x7f0000000000+0x40)={0x1, 0x9, &(0x7f0000000000+0x100)={{0xb7, 0x0, 0x0, 0x0}, [@bpf_insn_ldst={0x62, 0xa, 0xfffc, 0x0}, @bpf_insn_alu={0xbf, 0xa2, 0x0, 0x0}, @bpf_insn_alu={0x7, 0x2, 0x0, 0xfffffffc}, @bpf_insn_map={0x18, 0x11, 0x0, r0, 0x0, 0x0, 0x0, 0x0}, @bpf_insn_call={0x85, 0x0, 0x0, 0x1}, @bpf_insn_alu={0xb7, 0x0, 0x0, 0x0}], {0x95, 0x0, 0x0, 0x0}}, &(0x7f0000000000+0x200)=\"47504c00\", 0x0, 0x0, &(0x7f0000000000+0x300)=\"\", 0x0}, 0x30)\nr2 = socket(0x2, 0x2, 0x0)\nsetsockopt$sock_attach_bpf(r2, 0x1, 0x32, &(0x7f0000000000+0x380)=r1, 0x4)\n"},
+	{Name: "bpf_socket_filter", Prog: "mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nr0 = socket(0x2, 0x2, 0x0)\nsetsockopt$SO_ATTACH_FILTER(r0, 0x1, 0x1a, &(0x7f0000000000)={0x4, &(0x7f0000000000+0x100)={[@sock_filter_ld={0x28, 0x0, 0x0, 0xfffff000}, @sock_filter_jmp={0x15, 0x0, 0x1, 0x800}, @sock_filter_ret={0x6, 0x0, 0x0, 0xffff}], {0x6, 0x0, 0x0, 0x0}}}, 0x10)\n"},
+	{Name: "kvm_vcpu", Prog: "mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nr0 = syz_open_dev$kvm(&(0x7f0000000000)=\"2f6465762f6b766d00\", 0x0, 0x2)\nr1 = ioctl$KVM_CREATE_VM(r0, 0xae01, 0x0)\nr2 = ioctl$KVM_CREATE_VCPU(r1, 0xae41, 0x0)\nsyz_kvm_setup_cpu$x86(r1, r2, 0x40, &(0x7f0000000000+0x100)=[@cpuid=0xa20f, @kvm_insn_io={0xe6, 0x80}, @kvm_insn_mmio={0x89, 0x4, 0x25, 0xfee00000}], 0xb, 0x0)\nioctl$KVM_RUN(r2, 0xae80)\n"},
+	{Name: "fs_image_ext4", Prog: "mmap(&(0x7f0000000000)=nil, (0x10000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nsyz_mount_image$ext4(&(0x7f0000000000)=\"6578743400\", &(0x7f0000000000+0x100)=\"2e2f66696c653000\", 0x10000, 0x6, &(0x7f0000000000+0x800)=[@fs_image_ext4_sb={&(0x7f0000001000)=\"100000004000000000000000380000000600000001000000000000000000000000200000002000001000000000000000000000000000ffff53ef01000100000000000000000000000000000001000000000000000b000000800000000000000002000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000059d4eb42\", 0x400, 0x400}, @fs_image_segment={&(0x7f0000001000+0x400)=\"0300000004000000050000003800060001\", 0x11, 0x800}, @fs_image_segment={&(0x7f0000001000+0x500)=\"7f00000000000080ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff\", 0x400, 0xc00}, @fs_image_segment={&(0x7f0000001000+0x900)=\"ff03ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff\", 0x400, 0x1000}, @fs_image_segment={&(0x7f0000001000+0xd00)=\"ed41000000040000000000000000000000000000000000000000020002000000000000000000000007\", 0x29, 0x1480}, @fs_image_segment={&(0x7f0000001000+0xe00)=\"020000000c0001022e00000002000000f40302022e2e\", 0x16, 0x1c00}], 0x0)\n"},
+	{Name: "fs_image_vfat", Prog: "mmap(&(0x7f0000000000)=nil, (0x10000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nsyz_mount_image$vfat(&(0x7f0000000000)=\"7666617400\", &(0x7f0000000000+0x100)=\"2e2f66696c653000\", 0x100000, 0x3, &(0x7f0000000000+0x800)=[@fs_image_vfat_sb={&(0x7f0000001000)=\"eb3c906d6b66732e66617400020401000200020008f80200200040000000000000000000800029785634124e4f204e414d452020202046415431322020200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000055aa\", 0x200, 0x0}, @fs_image_segment={&(0x7f0000001000+0x200)=\"f8ffff\", 0x3, 0x200}, @fs_image_segment={&(0x7f0000001000+0x300)=\"f8ffff\", 0x3, 0x600}], 0x0)\n"},
+	{Name: "fs_image_btrfs", Prog: "mmap(&(0x7f0000000000)=nil, (0x10000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nsyz_mount_image$btrfs(&(0x7f0000000000)=\"627472667300\", &(0x7f0000000000+0x100)=\"2e2f66696c653000\", 0x100000, 0x1, &(0x7f0000000000+0x800)=[@fs_image_btrfs_sb={&(0x7f0000001000)=\"6217db8900000000000000000000000000000000000000000000000000000000101112131415161718191a1b1c1d1e1f000001000000000000000000000000005f42485266535f4d01000000000000000040010000000000008001000000000000000000000000000000000000000000000010000000000000400000000000000600000000000000010000000000000000100000001000000010000000100000000000000100000000000000000000000000000000000000000000004101000000000000000000000001000000000000000000100000000000004000000000000000100000001000000010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000101112131415161718191a1b1c1d1e1f0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000\", 0x1000, 0x10000}], 0x0)\n"},
+	{Name: "usb_hid_keyboard", Prog: "mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nr0 = syz_usb_connect(&(0x7f0000000000)={0x12, 0x1, 0x200, 0x0, 0x0, 0x0, 0x40, 0x46d, 0xc31c, 0x100, 0x0, 0x0, 0x0, 0x1}, &(0x7f0000000000+0x100)={0x9, 0x2, 0x22, 0x1, 0x1, 0x0, 0x80, 0x32, [{0x9, 0x4, 0x0, 0x0, 0x1, 0x3, 0x1, 0x1, 0x0, [@usb_hid_descriptor={0x9, 0x21, 0x110, 0x0, 0x1, 0x22, 0x2d}], [{0x7, 0x5, 0x81, 0x3, 0x8, 0xa}]}]}, 0x22)\nsyz_usb_control_io(r0, &(0x7f0000000000+0x200)=@hid_report=[@usb_hid_item={0x5, 0x1}, @usb_hid_item={0x9, 0x6}, @usb_hid_item={0xa1, 0x1}, @usb_hid_item={0x5, 0x7}, @usb_hid_item={0x19, 0xe0}, @usb_hid_item={0x29, 0xe7}, @usb_hid_item={0x15, 0x0}, @usb_hid_item={0x25, 0x1}, @usb_hid_item={0x75, 0x1}, @usb_hid_item={0x95, 0x8}, @usb_hid_item={0x81, 0x2}, @usb_hid_item={0x95, 0x1}, @usb_hid_item={0x75, 0x8}, @usb_hid_item={0x81, 0x1}, @usb_hid_item={0x95, 0x6}, @usb_hid_item={0x75, 0x8}, @usb_hid_item={0x15, 0x0}, @usb_hid_item={0x25, 0x65}, @usb_hid_item={0x5, 0x7}, @usb_hid_item={0x19, 0x0}, @usb_hid_item={0x29, 0x65}, @usb_hid_item={0x81, 0x0}, @end_collection=0xc0], 0x2d)\n"},
+	{Name: "vnet_tcp_handshake", Prog: "mmap(&(0x7f0000000000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\nr0 = socket(0x2, 0x1, 0x0)\nbind(r0, &(0x7f0000000000)=\"0200ab14ac1400aa0000000000000000\", 0x10)\nlisten(r0, 0x5)\nsyz_emit_ethernet(0x36, &(0x7f0000000000+0x100)={@local=[0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa], @remote=[0xbb, 0xbb, 0xbb, 0xbb, 0xbb, 0xbb], 0x8, @ipv4_packet={0x45, 0x0, 0x0, 0x0, 0x40, 0x40, 0x6, 0x0, @remote=0xbb0014ac, @local=0xaa0014ac, @tcp_packet={0xab, 0x14ab, 0x42424242, 0x0, 0x50, 0x2, 0x10, 0x0, 0x0, []}}})\nsyz_extract_tcp_res(&(0x7f0000000000+0x200)={<r1=>0x0, <r2=>0x0}, 0x1, 0x0)\nsyz_emit_ethernet(0x36, &(0x7f0000000000+0x300)={@local=[0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa], @remote=[0xbb, 0xbb, 0xbb, 0xbb, 0xbb, 0xbb], 0x8, @ipv4_packet={0x45, 0x0, 0x0, 0x0, 0x40, 0x40, 0x6, 0x0, @remote=0xbb0014ac, @local=0xaa0014ac, @tcp_packet={0xab, 0x14ab, r2, r1, 0x50, 0x10, 0x10, 0x0, 0x0, []}}})\naccept(r0, 0x0, 0x0)\n"},
+}
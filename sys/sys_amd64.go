@@ -5,4 +5,2066 @@
 package sys
 
 // Maps internal syscall ID onto kernel syscall number.
-var numbers = []int{2, 2, 257, 85, 3, 0, 17, 19, 295, 1, 18, 20, 296, 8, 32, 33, 292, 22, 293, 276, 275, 278, 40, 4, 6, 5, 7, 271, 23, 270, 213, 291, 233, 232, 281, 282, 289, 284, 290, 283, 286, 287, 323, 16, 16, 16, 16, 16, 16, 9, 11, 25, 216, 10, 26, 28, 221, 187, 237, 279, 256, 238, 239, 27, 149, 325, 150, 151, 152, 319, 272, 312, 202, 273, 274, 219, 16, 16, 16, 16, 16, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 206, 207, 208, 209, 210, 125, 126, 157, 157, 157, 157, 157, 157, 157, 157, 157, 157, 158, 317, 240, 242, 243, 244, 245, 241, 68, 69, 70, 71, 64, 65, 220, 66, 29, 30, 31, 67, 133, 259, 90, 91, 268, 92, 94, 93, 260, 285, 269, 132, 235, 261, 280, 104, 108, 105, 106, 102, 107, 109, 121, 111, 39, 186, 113, 114, 117, 119, 118, 120, 122, 123, 115, 116, 135, 253, 294, 254, 255, 300, 301, 86, 265, 266, 88, 87, 263, 89, 267, 82, 264, 316, 83, 258, 84, 76, 77, 73, 74, 75, 162, 306, 277, 212, 78, 217, 303, 304, 165, 165, 166, 155, 139, 139, 139, 137, 138, 134, 175, 313, 176, 246, 177, 103, 63, 99, 136, 163, 98, 97, 160, 302, 172, 173, 252, 252, 251, 251, 308, 188, 189, 190, 191, 192, 193, 194, 195, 196, 197, 198, 199, 201, 228, 227, 305, 229, 230, 222, 224, 225, 223, 226, 13, 14, 15, 127, 128, 130, 129, 297, 131, 234, 200, 34, 37, 35, 36, 38, 60, 231, 247, 61, 100, 205, 211, 154, 154, 154, 154, 310, 311, 218, 140, 141, 145, 144, 148, 143, 142, 204, 203, 315, 314, 24, 318, 324, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 41, 53, 43, 288, 49, 50, 42, 48, 44, 46, 307, 45, 47, 299, 51, 52, 55, 54, 16, 16, 54, 55, 54, 54, 55, 54, 55, 54, 55, 54, 54, 54, 55, 54, 55, 55, 54, 55, 54, 55, 54, 55, 54, 55, 55, 54, 55, 54, 55, 54, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 41, 53, 49, 42, 43, 288, 44, 46, 307, 45, 51, 52, 41, 49, 54, 54, 43, 46, 307, 41, 49, 42, 43, 54, 54, 55, 46, 307, 41, 42, 41, 49, 16, 54, 54, 54, 55, 41, 49, 42, 55, 55, 41, 49, 42, 54, 55, 54, 55, 54, 55, 41, 49, 42, 54, 55, 55, 41, 16, 16, 16, 16, 41, 16, 16, 16, 16, 41, 16, 16, 16, 16, 16, 16, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 2, 1000002, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 298, 16, 16, 16, 16, 16, 16, 16, 16, 16, 248, 249, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 321, 321, 321, 321, 321, 321, 321, 321, 321, 321, 1000003, 1000004, 16, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1000001, 1000001, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 2, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 41, 41, 53, 49, 42, 43, 288, 44, 46, 307, 45, 51, 52, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 16, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 2, 1000001, 1, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 1000001, 1000001, 1, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 41, 49, 42, 51, 52, 46, 54, 54, 54, 54, 54, 54, 54, 54, 54, 55, 1000001, 1, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 1000001, 16, 16, 16, 16, 16, 41, 54, 55, 46, 47, 16, 16, 16, 41, 49, 42, 43, 50, 46, 47, 51, 52, 54, 54, 54, 54, 54, 55, 55, 55, 55, 55, 16, 16, 16, 16, 16}
+var numbers = []int{2, 2, 257, 85, 3, 0, 17, 19, 295, 1, 18, 20, 296, 8, 32, 33, 292, 22, 293, 276, 275, 278, 40, 4, 6, 5, 7, 271, 23, 270, 213, 291, 233, 232, 281, 282, 289, 284, 290, 283, 286, 287, 323, 16, 16, 16, 16, 16, 16, 9, 11, 25, 216, 10, 26, 28, 221, 187, 237, 279, 256, 238, 239, 27, 149, 325, 150, 151, 152, 319, 272, 312, 202, 273, 274, 219, 16, 16, 16, 16, 16, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 72, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 101, 206, 207, 208, 209, 210, 125, 126, 157, 157, 157, 157, 157, 157, 157, 157, 157, 157, 158, 317, 240, 242, 243, 244, 245, 241, 68, 69, 70, 71, 64, 65, 220, 66, 29, 30, 31, 67, 133, 259, 90, 91, 268, 92, 94, 93, 260, 285, 269, 132, 235, 261, 280, 104, 108, 105, 106, 102, 107, 109, 121, 111, 39, 186, 113, 114, 117, 119, 118, 120, 122, 123, 115, 116, 135, 253, 294, 254, 255, 300, 301, 86, 265, 266, 88, 87, 263, 89, 267, 82, 264, 316, 83, 258, 84, 76, 77, 73, 74, 75, 162, 306, 277, 212, 78, 217, 303, 304, 165, 165, 166, 155, 139, 139, 139, 137, 138, 134, 175, 313, 176, 246, 177, 103, 63, 99, 136, 163, 98, 97, 160, 302, 172, 173, 252, 252, 251, 251, 308, 188, 189, 190, 191, 192, 193, 194, 195, 196, 197, 198, 199, 201, 228, 227, 305, 229, 230, 222, 224, 225, 223, 226, 13, 14, 15, 127, 128, 130, 129, 297, 131, 234, 200, 34, 37, 35, 36, 38, 60, 231, 247, 61, 100, 205, 211, 154, 154, 154, 154, 310, 311, 218, 140, 141, 145, 144, 148, 143, 142, 204, 203, 315, 314, 24, 318, 324, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 1000001, 41, 53, 43, 288, 49, 50, 42, 48, 44, 46, 307, 45, 47, 299, 51, 52, 55, 54, 16, 16, 54, 55, 54, 54, 55, 54, 55, 54, 55, 54, 54, 54, 55, 54, 55, 55, 54, 55, 54, 55, 54, 55, 54, 55, 55, 54, 55, 54, 55, 54, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 41, 53, 49, 42, 43, 288, 44, 46, 307, 45, 47, 54, 51, 52, 1000005, 41, 49, 54, 54, 43, 46, 307, 41, 49, 42, 43, 54, 54, 55, 46, 307, 41, 42, 41, 49, 16, 54, 54, 54, 55, 41, 49, 42, 55, 55, 41, 49, 42, 54, 55, 54, 55, 54, 55, 41, 49, 42, 54, 55, 55, 41, 16, 16, 16, 16, 41, 16, 16, 16, 16, 41, 16, 16, 16, 16, 16, 16, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 54, 55, 2, 1000002, 1000001, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 298, 16, 16, 16, 16, 16, 16, 16, 16, 16, 248, 249, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 250, 321, 321, 321, 321, 321, 321, 321, 321, 321, 321, 1000003, 1000004, 16, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1000001, 1000001, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 2, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 41, 41, 53, 49, 42, 43, 288, 44, 46, 307, 45, 51, 52, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 54, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 55, 16, 1000001, 1000007, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 2, 1000001, 1, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 1000001, 1000001, 1, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 41, 49, 42, 51, 52, 46, 54, 54, 54, 54, 54, 54, 54, 54, 54, 55, 41, 46, 41, 46, 1000001, 1, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 1000001, 1000001, 16, 16, 16, 16, 16, 41, 54, 55, 46, 47, 16, 16, 16, 41, 49, 42, 43, 50, 46, 47, 51, 52, 54, 54, 54, 54, 54, 55, 55, 55, 55, 55, 16, 16, 16, 16, 16, 41, 41, 49, 42, 44, 46, 307, 54, 55, 54, 54, 54, 55, 54, 55, 54, 54, 54, 55, 257, 1, 18, 0, 8, 76, 1000006, 1000006, 1000006, 1000008, 1000009, 1000010, 1000011}
+
+// Values of named constants used in descriptions.
+const (
+	ADDR_COMPAT_LAYOUT                       = 2097152
+	ADDR_LIMIT_32BIT                         = 8388608
+	ADDR_LIMIT_3GB                           = 134217728
+	ADDR_NO_RANDOMIZE                        = 262144
+	AF_ALG                                   = 38
+	AF_APPLETALK                             = 5
+	AF_ATMPVC                                = 8
+	AF_AX25                                  = 3
+	AF_BLUETOOTH                             = 31
+	AF_BRIDGE                                = 7
+	AF_INET                                  = 2
+	AF_INET6                                 = 10
+	AF_IPX                                   = 4
+	AF_KCM                                   = 41
+	AF_NETLINK                               = 16
+	AF_NETROM                                = 6
+	AF_NFC                                   = 39
+	AF_PACKET                                = 17
+	AF_UNIX                                  = 1
+	AF_UNSPEC                                = 0
+	AF_X25                                   = 9
+	ALG_SET_AEAD_ASSOCLEN                    = 4
+	ALG_SET_AEAD_AUTHSIZE                    = 5
+	ALG_SET_IV                               = 2
+	ALG_SET_KEY                              = 1
+	ALG_SET_OP                               = 3
+	ARCH_GET_FS                              = 4099
+	ARCH_GET_GS                              = 4100
+	ARCH_SET_FS                              = 4098
+	ARCH_SET_GS                              = 4097
+	AT_EMPTY_PATH                            = 4096
+	AT_FDCWD                                 = 18446744073709551516
+	AT_REMOVEDIR                             = 512
+	AT_SYMLINK_FOLLOW                        = 1024
+	AT_SYMLINK_NOFOLLOW                      = 256
+	AX25_MAX_DIGIS                           = 8
+	BNEPCONNADD                              = 1074021064
+	BNEPCONNDEL                              = 1074021065
+	BNEPGETCONNINFO                          = 2147762899
+	BNEPGETCONNLIST                          = 2147762898
+	BNEPGETSUPPFEAT                          = 2147762900
+	BPF_ANY                                  = 0
+	BPF_EXIST                                = 2
+	BPF_FUNC_clone_redirect                  = 13
+	BPF_FUNC_get_cgroup_classid              = 17
+	BPF_FUNC_get_current_comm                = 16
+	BPF_FUNC_get_current_pid_tgid            = 14
+	BPF_FUNC_get_current_uid_gid             = 15
+	BPF_FUNC_get_prandom_u32                 = 7
+	BPF_FUNC_get_route_realm                 = 24
+	BPF_FUNC_get_smp_processor_id            = 8
+	BPF_FUNC_ktime_get_ns                    = 5
+	BPF_FUNC_l3_csum_replace                 = 10
+	BPF_FUNC_l4_csum_replace                 = 11
+	BPF_FUNC_map_delete_elem                 = 3
+	BPF_FUNC_map_lookup_elem                 = 1
+	BPF_FUNC_map_update_elem                 = 2
+	BPF_FUNC_perf_event_output               = 25
+	BPF_FUNC_perf_event_read                 = 22
+	BPF_FUNC_probe_read                      = 4
+	BPF_FUNC_redirect                        = 23
+	BPF_FUNC_skb_get_tunnel_key              = 20
+	BPF_FUNC_skb_set_tunnel_key              = 21
+	BPF_FUNC_skb_store_bytes                 = 9
+	BPF_FUNC_skb_vlan_pop                    = 19
+	BPF_FUNC_skb_vlan_push                   = 18
+	BPF_FUNC_tail_call                       = 12
+	BPF_FUNC_trace_printk                    = 6
+	BPF_MAP_CREATE                           = 0
+	BPF_MAP_DELETE_ELEM                      = 3
+	BPF_MAP_GET_NEXT_KEY                     = 4
+	BPF_MAP_LOOKUP_ELEM                      = 1
+	BPF_MAP_TYPE_ARRAY                       = 2
+	BPF_MAP_TYPE_HASH                        = 1
+	BPF_MAP_TYPE_PERF_EVENT_ARRAY            = 4
+	BPF_MAP_TYPE_PROG_ARRAY                  = 3
+	BPF_MAP_UPDATE_ELEM                      = 2
+	BPF_NOEXIST                              = 1
+	BPF_OBJ_GET                              = 7
+	BPF_OBJ_PIN                              = 6
+	BPF_PROG_LOAD                            = 5
+	BPF_PROG_TYPE_KPROBE                     = 2
+	BPF_PROG_TYPE_SCHED_ACT                  = 4
+	BPF_PROG_TYPE_SCHED_CLS                  = 3
+	BPF_PROG_TYPE_SOCKET_FILTER              = 1
+	BTPROTO_BNEP                             = 4
+	BTPROTO_CMTP                             = 5
+	BTPROTO_HCI                              = 1
+	BTPROTO_HIDP                             = 6
+	BTPROTO_L2CAP                            = 0
+	BTPROTO_RFCOMM                           = 3
+	BTPROTO_SCO                              = 2
+	BT_CHANNEL_POLICY                        = 10
+	BT_DEFER_SETUP                           = 7
+	BT_FLUSHABLE                             = 8
+	BT_POWER                                 = 9
+	BT_RCVMTU                                = 13
+	BT_SECURITY                              = 4
+	BT_SNDMTU                                = 12
+	BT_VOICE                                 = 11
+	CLOCK_BOOTTIME                           = 7
+	CLOCK_MONOTONIC                          = 1
+	CLOCK_MONOTONIC_COARSE                   = 6
+	CLOCK_MONOTONIC_RAW                      = 4
+	CLOCK_PROCESS_CPUTIME_ID                 = 2
+	CLOCK_REALTIME                           = 0
+	CLOCK_REALTIME_COARSE                    = 5
+	CLOCK_THREAD_CPUTIME_ID                  = 3
+	CLONE_CHILD_CLEARTID                     = 2097152
+	CLONE_CHILD_SETTID                       = 16777216
+	CLONE_FILES                              = 1024
+	CLONE_FS                                 = 512
+	CLONE_IO                                 = 2147483648
+	CLONE_NEWIPC                             = 134217728
+	CLONE_NEWNET                             = 1073741824
+	CLONE_NEWNS                              = 131072
+	CLONE_NEWPID                             = 536870912
+	CLONE_NEWUTS                             = 67108864
+	CLONE_PARENT                             = 32768
+	CLONE_PARENT_SETTID                      = 1048576
+	CLONE_PTRACE                             = 8192
+	CLONE_SETTLS                             = 524288
+	CLONE_SIGHAND                            = 2048
+	CLONE_SYSVSEM                            = 262144
+	CLONE_THREAD                             = 65536
+	CLONE_UNTRACED                           = 8388608
+	CLONE_VFORK                              = 16384
+	CLONE_VM                                 = 256
+	CMTPCONNADD                              = 1074021320
+	CMTPCONNDEL                              = 1074021321
+	CMTPGETCONNINFO                          = 2147763155
+	CMTPGETCONNLIST                          = 2147763154
+	CRYPTO_ALG_ASYNC                         = 128
+	CRYPTO_ALG_DEAD                          = 32
+	CRYPTO_ALG_DYING                         = 64
+	CRYPTO_ALG_GENIV                         = 512
+	CRYPTO_ALG_INSTANCE                      = 2048
+	CRYPTO_ALG_INTERNAL                      = 8192
+	CRYPTO_ALG_KERN_DRIVER_ONLY              = 4096
+	CRYPTO_ALG_LARVAL                        = 16
+	CRYPTO_ALG_NEED_FALLBACK                 = 256
+	CRYPTO_ALG_TESTED                        = 1024
+	CRYPTO_ALG_TYPE_ABLKCIPHER               = 5
+	CRYPTO_ALG_TYPE_AEAD                     = 3
+	CRYPTO_ALG_TYPE_AHASH                    = 10
+	CRYPTO_ALG_TYPE_AKCIPHER                 = 13
+	CRYPTO_ALG_TYPE_BLKCIPHER                = 4
+	CRYPTO_ALG_TYPE_CIPHER                   = 1
+	CRYPTO_ALG_TYPE_COMPRESS                 = 2
+	CRYPTO_ALG_TYPE_DIGEST                   = 8
+	CRYPTO_ALG_TYPE_GIVCIPHER                = 6
+	CRYPTO_ALG_TYPE_HASH                     = 8
+	CRYPTO_ALG_TYPE_MASK                     = 15
+	CRYPTO_ALG_TYPE_PCOMPRESS                = 15
+	CRYPTO_ALG_TYPE_RNG                      = 12
+	CRYPTO_ALG_TYPE_SHASH                    = 9
+	CTRL_ATTR_FAMILY_ID                      = 1
+	CTRL_ATTR_FAMILY_NAME                    = 2
+	CTRL_CMD_GETFAMILY                       = 3
+	DN_ACCESS                                = 1
+	DN_ATTRIB                                = 32
+	DN_CREATE                                = 4
+	DN_DELETE                                = 8
+	DN_MODIFY                                = 2
+	DN_MULTISHOT                             = 2147483648
+	DN_RENAME                                = 16
+	DRM_ADD_COMMAND                          = 0
+	DRM_DISPLAY_MODE_LEN                     = 32
+	DRM_INST_HANDLER                         = 2
+	DRM_IOCTL_ADD_BUFS                       = 3223348246
+	DRM_IOCTL_ADD_CTX                        = 3221775392
+	DRM_IOCTL_ADD_MAP                        = 3223872533
+	DRM_IOCTL_AGP_ACQUIRE                    = 25648
+	DRM_IOCTL_AGP_ALLOC                      = 3223348276
+	DRM_IOCTL_AGP_BIND                       = 1074816054
+	DRM_IOCTL_AGP_ENABLE                     = 1074291762
+	DRM_IOCTL_AGP_FREE                       = 1075864629
+	DRM_IOCTL_AGP_INFO                       = 2151179315
+	DRM_IOCTL_AGP_RELEASE                    = 25649
+	DRM_IOCTL_AGP_UNBIND                     = 1074816055
+	DRM_IOCTL_AUTH_MAGIC                     = 1074029585
+	DRM_IOCTL_CONTROL                        = 1074291732
+	DRM_IOCTL_DMA                            = 3225445417
+	DRM_IOCTL_DROP_MASTER                    = 25631
+	DRM_IOCTL_FREE_BUFS                      = 1074816026
+	DRM_IOCTL_GEM_CLOSE                      = 1074291721
+	DRM_IOCTL_GEM_FLINK                      = 3221775370
+	DRM_IOCTL_GEM_OPEN                       = 3222299659
+	DRM_IOCTL_GET_CAP                        = 3222299660
+	DRM_IOCTL_GET_CLIENT                     = 3223872517
+	DRM_IOCTL_GET_CTX                        = 3221775395
+	DRM_IOCTL_GET_MAGIC                      = 2147771394
+	DRM_IOCTL_GET_MAP                        = 3223872516
+	DRM_IOCTL_GET_SAREA_CTX                  = 3222299677
+	DRM_IOCTL_GET_STATS                      = 2163762182
+	DRM_IOCTL_GET_UNIQUE                     = 3222299649
+	DRM_IOCTL_INFO_BUFS                      = 3222299672
+	DRM_IOCTL_IRQ_BUSID                      = 3222299651
+	DRM_IOCTL_LOCK                           = 1074291754
+	DRM_IOCTL_MAP_BUFS                       = 3222823961
+	DRM_IOCTL_MARK_BUFS                      = 1075864599
+	DRM_IOCTL_MODESET_CTL                    = 1074291720
+	DRM_IOCTL_MODE_GETCRTC                   = 3228066977
+	DRM_IOCTL_MODE_GETPLANERESOURCES         = 3222299829
+	DRM_IOCTL_MODE_GETRESOURCES              = 3225445536
+	DRM_IOCTL_MODE_SETCRTC                   = 3228066978
+	DRM_IOCTL_NEW_CTX                        = 1074291749
+	DRM_IOCTL_PRIME_FD_TO_HANDLE             = 3222037550
+	DRM_IOCTL_PRIME_HANDLE_TO_FD             = 3222037549
+	DRM_IOCTL_RES_CTX                        = 3222299686
+	DRM_IOCTL_RM_CTX                         = 3221775393
+	DRM_IOCTL_RM_MAP                         = 1076388891
+	DRM_IOCTL_SET_CLIENT_CAP                 = 1074816013
+	DRM_IOCTL_SET_MASTER                     = 25630
+	DRM_IOCTL_SET_SAREA_CTX                  = 1074816028
+	DRM_IOCTL_SET_UNIQUE                     = 1074816016
+	DRM_IOCTL_SET_VERSION                    = 3222299655
+	DRM_IOCTL_SG_ALLOC                       = 3222299704
+	DRM_IOCTL_SG_FREE                        = 1074816057
+	DRM_IOCTL_SWITCH_CTX                     = 1074291748
+	DRM_IOCTL_UNLOCK                         = 1074291755
+	DRM_IOCTL_VERSION                        = 3225445376
+	DRM_IOCTL_WAIT_VBLANK                    = 3222823994
+	DRM_RM_COMMAND                           = 1
+	DRM_UNINST_HANDLER                       = 3
+	EFD_CLOEXEC                              = 524288
+	EFD_NONBLOCK                             = 2048
+	EFD_SEMAPHORE                            = 1
+	EPOLLET                                  = 18446744071562067968
+	EPOLLONESHOT                             = 1073741824
+	EPOLL_CLOEXEC                            = 524288
+	EPOLL_CTL_ADD                            = 1
+	EPOLL_CTL_DEL                            = 2
+	EPOLL_CTL_MOD                            = 3
+	EVIOCGABS0                               = 2149074240
+	EVIOCGABS20                              = 2149074272
+	EVIOCGABS2F                              = 2149074287
+	EVIOCGABS3F                              = 2149074303
+	EVIOCGBITKEY64                           = 2151695649
+	EVIOCGBITSND64                           = 2151695666
+	EVIOCGBITSW64                            = 2151695653
+	EVIOCGEFFECTS                            = 2147763588
+	EVIOCGID                                 = 2148025602
+	EVIOCGKEY64                              = 2151695640
+	EVIOCGKEYCODE                            = 2148025604
+	EVIOCGKEYCODE_V2                         = 2150122756
+	EVIOCGLED64                              = 2151695641
+	EVIOCGMASK                               = 2148550034
+	EVIOCGMTSLOTS64                          = 2151695626
+	EVIOCGNAME64                             = 2151695622
+	EVIOCGPHYS64                             = 2151695623
+	EVIOCGPROP64                             = 2151695625
+	EVIOCGRAB                                = 1074021776
+	EVIOCGREP                                = 2148025603
+	EVIOCGSND64                              = 2151695642
+	EVIOCGSW64                               = 2151695643
+	EVIOCGUNIQ64                             = 2151695624
+	EVIOCGVERSION                            = 2147763457
+	EVIOCREVOKE                              = 1074021777
+	EVIOCRMFF                                = 1074021761
+	EVIOCSABS0                               = 1075332544
+	EVIOCSABS20                              = 1075332576
+	EVIOCSABS2F                              = 1075332591
+	EVIOCSABS3F                              = 1075332607
+	EVIOCSCLOCKID                            = 1074021792
+	EVIOCSFF                                 = 1076905344
+	EVIOCSKEYCODE                            = 1074283780
+	EVIOCSKEYCODE_V2                         = 1076380932
+	EVIOCSMASK                               = 1074808211
+	EVIOCSREP                                = 1074283779
+	EV_ABS                                   = 3
+	EV_FF                                    = 21
+	EV_KEY                                   = 1
+	EV_LED                                   = 17
+	EV_MSC                                   = 4
+	EV_REL                                   = 2
+	EV_SND                                   = 18
+	EV_SW                                    = 5
+	EV_SYN                                   = 0
+	FALLOC_FL_KEEP_SIZE                      = 1
+	FALLOC_FL_PUNCH_HOLE                     = 2
+	FAN_ACCESS                               = 1
+	FAN_ACCESS_PERM                          = 131072
+	FAN_CLASS_CONTENT                        = 4
+	FAN_CLASS_NOTIF                          = 0
+	FAN_CLASS_PRE_CONTENT                    = 8
+	FAN_CLOEXEC                              = 1
+	FAN_CLOSE_NOWRITE                        = 16
+	FAN_CLOSE_WRITE                          = 8
+	FAN_EVENT_ON_CHILD                       = 134217728
+	FAN_MARK_ADD                             = 1
+	FAN_MARK_DONT_FOLLOW                     = 4
+	FAN_MARK_FLUSH                           = 128
+	FAN_MARK_IGNORED_MASK                    = 32
+	FAN_MARK_IGNORED_SURV_MODIFY             = 64
+	FAN_MARK_MOUNT                           = 16
+	FAN_MARK_ONLYDIR                         = 8
+	FAN_MARK_REMOVE                          = 2
+	FAN_MODIFY                               = 2
+	FAN_NONBLOCK                             = 2
+	FAN_ONDIR                                = 1073741824
+	FAN_OPEN                                 = 32
+	FAN_OPEN_PERM                            = 65536
+	FAN_UNLIMITED_MARKS                      = 32
+	FAN_UNLIMITED_QUEUE                      = 16
+	FASYNC                                   = 8192
+	FD_CLOEXEC                               = 1
+	FF_CONSTANT                              = 82
+	FF_CUSTOM                                = 93
+	FF_DAMPER                                = 85
+	FF_FRICTION                              = 84
+	FF_INERTIA                               = 86
+	FF_PERIODIC                              = 81
+	FF_RAMP                                  = 87
+	FF_SAW_DOWN                              = 92
+	FF_SAW_UP                                = 91
+	FF_SINE                                  = 90
+	FF_SPRING                                = 83
+	FF_SQUARE                                = 88
+	FF_TRIANGLE                              = 89
+	FIEMAP_EXTENT_DATA_ENCRYPTED             = 128
+	FIEMAP_EXTENT_DATA_INLINE                = 512
+	FIEMAP_EXTENT_DATA_TAIL                  = 1024
+	FIEMAP_EXTENT_DELALLOC                   = 4
+	FIEMAP_EXTENT_ENCODED                    = 8
+	FIEMAP_EXTENT_LAST                       = 1
+	FIEMAP_EXTENT_MERGED                     = 4096
+	FIEMAP_EXTENT_NOT_ALIGNED                = 256
+	FIEMAP_EXTENT_SHARED                     = 8192
+	FIEMAP_EXTENT_UNKNOWN                    = 2
+	FIEMAP_EXTENT_UNWRITTEN                  = 2048
+	FIEMAP_FLAG_CACHE                        = 4
+	FIEMAP_FLAG_SYNC                         = 1
+	FIEMAP_FLAG_XATTR                        = 2
+	FIFREEZE                                 = 3221510263
+	FIGETBSZ                                 = 2
+	FIOASYNC                                 = 21586
+	FIOCLEX                                  = 21585
+	FIONBIO                                  = 21537
+	FIONCLEX                                 = 21584
+	FIONREAD                                 = 21531
+	FIOQSIZE                                 = 21600
+	FITHAW                                   = 3221510264
+	FS_IOC_FIEMAP                            = 3223348747
+	FUSE_DEV_IOC_CLONE                       = 2147804416
+	FUTEX_CMP_REQUEUE                        = 4
+	FUTEX_REQUEUE                            = 3
+	FUTEX_WAIT                               = 0
+	FUTEX_WAIT_BITSET                        = 9
+	FUTEX_WAKE                               = 1
+	F_ADD_SEALS                              = 1033
+	F_DUPFD                                  = 0
+	F_DUPFD_CLOEXEC                          = 1030
+	F_GETFD                                  = 1
+	F_GETFL                                  = 3
+	F_GETLEASE                               = 1025
+	F_GETLK                                  = 5
+	F_GETOWN                                 = 9
+	F_GETOWN_EX                              = 16
+	F_GETPIPE_SZ                             = 1032
+	F_GETSIG                                 = 11
+	F_GET_SEALS                              = 1034
+	F_OWNER_PGRP                             = 2
+	F_OWNER_PID                              = 1
+	F_OWNER_TID                              = 0
+	F_RDLCK                                  = 0
+	F_SEAL_GROW                              = 4
+	F_SEAL_SEAL                              = 1
+	F_SEAL_SHRINK                            = 2
+	F_SEAL_WRITE                             = 8
+	F_SETFD                                  = 2
+	F_SETFL                                  = 4
+	F_SETLEASE                               = 1024
+	F_SETLK                                  = 6
+	F_SETLKW                                 = 7
+	F_SETOWN                                 = 8
+	F_SETOWN_EX                              = 15
+	F_SETPIPE_SZ                             = 1031
+	F_SETSIG                                 = 10
+	F_UNLCK                                  = 2
+	F_WRLCK                                  = 1
+	GENL_ID_CTRL                             = 16
+	GENL_NAME_BATADV                         = 130173503824226
+	GENL_NAME_DEVLINK                        = 30239221578360164
+	GENL_NAME_FOU                            = 7696230
+	GENL_NAME_GTP                            = 7369831
+	GENL_NAME_L2TP                           = 1886663276
+	GENL_NAME_MACSEC                         = 109287378739565
+	GENL_NAME_NET_DM                         = 84956052473166
+	GENL_NAME_NL80211                        = 13846365485952110
+	GENL_NAME_NLCTRL                         = 119238834744430
+	GENL_NAME_TEAM                           = 1835099508
+	GETALL                                   = 13
+	GETNCNT                                  = 14
+	GETPID                                   = 11
+	GETVAL                                   = 12
+	GETZCNT                                  = 15
+	GIO_CMAP                                 = 19312
+	GIO_FONT                                 = 19296
+	GIO_FONTX                                = 19307
+	GIO_SCRNMAP                              = 19264
+	GIO_UNIMAP                               = 19302
+	GIO_UNISCRNMAP                           = 19305
+	GRND_NONBLOCK                            = 1
+	GRND_RANDOM                              = 2
+	GSMIOC_GETCONF                           = 2152482560
+	GSMIOC_SETCONF                           = 1078740737
+	HCIBLOCKADDR                             = 1074022630
+	HCIDEVDOWN                               = 1074022602
+	HCIDEVRESET                              = 1074022603
+	HCIDEVRESTAT                             = 1074022604
+	HCIDEVUP                                 = 1074022601
+	HCIGETAUTHINFO                           = 2147764439
+	HCIGETCONNINFO                           = 2147764437
+	HCIGETCONNLIST                           = 2147764436
+	HCIGETDEVINFO                            = 2147764435
+	HCIGETDEVLIST                            = 2147764434
+	HCIINQUIRY                               = 2147764464
+	HCISETACLMTU                             = 1074022627
+	HCISETAUTH                               = 1074022622
+	HCISETENCRYPT                            = 1074022623
+	HCISETLINKMODE                           = 1074022626
+	HCISETLINKPOL                            = 1074022625
+	HCISETPTYPE                              = 1074022624
+	HCISETRAW                                = 1074022620
+	HCISETSCAN                               = 1074022621
+	HCISETSCOMTU                             = 1074022628
+	HCIUARTGETDEVICE                         = 2147767754
+	HCIUARTGETFLAGS                          = 2147767756
+	HCIUARTGETPROTO                          = 2147767753
+	HCIUARTSETFLAGS                          = 1074025931
+	HCIUARTSETPROTO                          = 1074025928
+	HCIUNBLOCKADDR                           = 1074022631
+	HCI_CHANNEL_CONTROL                      = 3
+	HCI_CHANNEL_MONITOR                      = 2
+	HCI_CHANNEL_RAW                          = 0
+	HCI_CHANNEL_USER                         = 1
+	HCI_DATA_DIR                             = 1
+	HCI_FILTER                               = 2
+	HCI_TIME_STAMP                           = 3
+	HIDPCONNADD                              = 1074022600
+	HIDPCONNDEL                              = 1074022601
+	HIDPGETCONNINFO                          = 2147764435
+	HIDPGETCONNLIST                          = 2147764434
+	HID_DT_HID                               = 33
+	HID_DT_REPORT                            = 34
+	HW_BREAKPOINT_EMPTY                      = 0
+	HW_BREAKPOINT_R                          = 1
+	HW_BREAKPOINT_W                          = 2
+	HW_BREAKPOINT_X                          = 4
+	ICMPV6_FILTER                            = 1
+	IFA_ADDRESS                              = 1
+	IFA_BROADCAST                            = 4
+	IFA_CACHEINFO                            = 6
+	IFA_FLAGS                                = 8
+	IFA_F_DADFAILED                          = 8
+	IFA_F_DEPRECATED                         = 32
+	IFA_F_HOMEADDRESS                        = 16
+	IFA_F_NODAD                              = 2
+	IFA_F_OPTIMISTIC                         = 4
+	IFA_F_PERMANENT                          = 128
+	IFA_F_SECONDARY                          = 1
+	IFA_F_TENTATIVE                          = 64
+	IFA_LABEL                                = 3
+	IFA_LOCAL                                = 2
+	IFF_ALLMULTI                             = 512
+	IFF_ATTACH_QUEUE                         = 512
+	IFF_AUTOMEDIA                            = 16384
+	IFF_BROADCAST                            = 2
+	IFF_DEBUG                                = 4
+	IFF_DETACH_QUEUE                         = 1024
+	IFF_DYNAMIC                              = 32768
+	IFF_LOOPBACK                             = 8
+	IFF_MASTER                               = 1024
+	IFF_MULTICAST                            = 4096
+	IFF_MULTI_QUEUE                          = 256
+	IFF_NOARP                                = 128
+	IFF_NOFILTER                             = 4096
+	IFF_NOTRAILERS                           = 32
+	IFF_NO_PI                                = 4096
+	IFF_ONE_QUEUE                            = 8192
+	IFF_PERSIST                              = 2048
+	IFF_POINTOPOINT                          = 16
+	IFF_PORTSEL                              = 8192
+	IFF_PROMISC                              = 256
+	IFF_RUNNING                              = 64
+	IFF_SLAVE                                = 2048
+	IFF_TAP                                  = 2
+	IFF_TUN                                  = 1
+	IFF_TUN_EXCL                             = 32768
+	IFF_UP                                   = 1
+	IFF_VNET_HDR                             = 16384
+	IFLA_ADDRESS                             = 1
+	IFLA_BROADCAST                           = 2
+	IFLA_CARRIER                             = 33
+	IFLA_GROUP                               = 27
+	IFLA_IFNAME                              = 3
+	IFLA_INFO_DATA                           = 2
+	IFLA_INFO_KIND                           = 1
+	IFLA_LINK                                = 5
+	IFLA_LINKINFO                            = 18
+	IFLA_LINKMODE                            = 17
+	IFLA_MASTER                              = 10
+	IFLA_MTU                                 = 4
+	IFLA_NUM_RX_QUEUES                       = 32
+	IFLA_NUM_TX_QUEUES                       = 31
+	IFLA_OPERSTATE                           = 16
+	IFLA_PROMISCUITY                         = 30
+	IFLA_TXQLEN                              = 13
+	IFNAMSIZ                                 = 16
+	IN_ACCESS                                = 1
+	IN_ATTRIB                                = 4
+	IN_CLOEXEC                               = 524288
+	IN_CLOSE_NOWRITE                         = 16
+	IN_CLOSE_WRITE                           = 8
+	IN_CREATE                                = 256
+	IN_DELETE                                = 512
+	IN_DELETE_SELF                           = 1024
+	IN_DONT_FOLLOW                           = 33554432
+	IN_EXCL_UNLINK                           = 67108864
+	IN_MASK_ADD                              = 536870912
+	IN_MODIFY                                = 2
+	IN_MOVED_FROM                            = 64
+	IN_MOVED_TO                              = 128
+	IN_MOVE_SELF                             = 2048
+	IN_NONBLOCK                              = 2048
+	IN_ONESHOT                               = 2147483648
+	IN_ONLYDIR                               = 16777216
+	IN_OPEN                                  = 32
+	IOCB_CMD_FDSYNC                          = 3
+	IOCB_CMD_FSYNC                           = 2
+	IOCB_CMD_NOOP                            = 6
+	IOCB_CMD_PREAD                           = 0
+	IOCB_CMD_PREADV                          = 7
+	IOCB_CMD_PWRITE                          = 1
+	IOCB_CMD_PWRITEV                         = 8
+	IOCB_FLAG_RESFD                          = 1
+	IOPRIO_WHO_PGRP                          = 2
+	IOPRIO_WHO_PROCESS                       = 1
+	IOPRIO_WHO_USER                          = 3
+	IPC_CREAT                                = 512
+	IPC_EXCL                                 = 1024
+	IPC_INFO                                 = 3
+	IPC_NOWAIT                               = 2048
+	IPC_RMID                                 = 0
+	IPC_SET                                  = 1
+	IPC_STAT                                 = 2
+	IPPROTO_ICMP                             = 1
+	IPPROTO_ICMPV6                           = 58
+	IPPROTO_IP                               = 0
+	IPPROTO_IPV6                             = 41
+	IPPROTO_SCTP                             = 132
+	IPPROTO_TCP                              = 6
+	IPPROTO_UDP                              = 17
+	IPV6_2292DSTOPTS                         = 4
+	IPV6_2292HOPLIMIT                        = 8
+	IPV6_2292HOPOPTS                         = 3
+	IPV6_2292PKTINFO                         = 2
+	IPV6_2292PKTOPTIONS                      = 6
+	IPV6_2292RTHDR                           = 5
+	IPV6_ADDRFORM                            = 1
+	IPV6_ADD_MEMBERSHIP                      = 20
+	IPV6_AUTHHDR                             = 10
+	IPV6_CHECKSUM                            = 7
+	IPV6_DROP_MEMBERSHIP                     = 21
+	IPV6_DSTOPTS                             = 59
+	IPV6_FLOWINFO                            = 11
+	IPV6_HOPLIMIT                            = 52
+	IPV6_HOPOPTS                             = 54
+	IPV6_JOIN_ANYCAST                        = 27
+	IPV6_LEAVE_ANYCAST                       = 28
+	IPV6_MTU                                 = 24
+	IPV6_MTU_DISCOVER                        = 23
+	IPV6_MULTICAST_HOPS                      = 18
+	IPV6_MULTICAST_IF                        = 17
+	IPV6_MULTICAST_LOOP                      = 19
+	IPV6_PKTINFO                             = 50
+	IPV6_RECVDSTOPTS                         = 58
+	IPV6_RECVERR                             = 25
+	IPV6_RECVHOPLIMIT                        = 51
+	IPV6_RECVHOPOPTS                         = 53
+	IPV6_RECVORIGDSTADDR                     = 74
+	IPV6_RECVPATHMTU                         = 60
+	IPV6_RECVPKTINFO                         = 49
+	IPV6_RECVRTHDR                           = 56
+	IPV6_RECVTCLASS                          = 66
+	IPV6_ROUTER_ALERT                        = 22
+	IPV6_RTHDR                               = 57
+	IPV6_RTHDRDSTOPTS                        = 55
+	IPV6_TCLASS                              = 67
+	IPV6_TLV_CALIPSO                         = 7
+	IPV6_TLV_HAO                             = 201
+	IPV6_TLV_JUMBO                           = 194
+	IPV6_TLV_PAD1                            = 0
+	IPV6_TLV_PADN                            = 1
+	IPV6_TLV_ROUTERALERT                     = 5
+	IPV6_UNICAST_HOPS                        = 16
+	IPV6_V6ONLY                              = 26
+	IP_ADD_MEMBERSHIP                        = 35
+	IP_ADD_SOURCE_MEMBERSHIP                 = 39
+	IP_BIND_ADDRESS_NO_PORT                  = 24
+	IP_BLOCK_SOURCE                          = 38
+	IP_CHECKSUM                              = 23
+	IP_DROP_MEMBERSHIP                       = 36
+	IP_DROP_SOURCE_MEMBERSHIP                = 40
+	IP_FREEBIND                              = 15
+	IP_HDRINCL                               = 3
+	IP_IPSEC_POLICY                          = 16
+	IP_MINTTL                                = 21
+	IP_MSFILTER                              = 41
+	IP_MTU                                   = 14
+	IP_MTU_DISCOVER                          = 10
+	IP_MULTICAST_ALL                         = 49
+	IP_MULTICAST_IF                          = 32
+	IP_MULTICAST_LOOP                        = 34
+	IP_MULTICAST_TTL                         = 33
+	IP_NODEFRAG                              = 22
+	IP_OPTIONS                               = 4
+	IP_PASSSEC                               = 18
+	IP_PKTINFO                               = 8
+	IP_PKTOPTIONS                            = 9
+	IP_PMTUDISC_DO                           = 2
+	IP_PMTUDISC_DONT                         = 0
+	IP_PMTUDISC_INTERFACE                    = 4
+	IP_PMTUDISC_OMIT                         = 5
+	IP_PMTUDISC_PROBE                        = 3
+	IP_PMTUDISC_WANT                         = 1
+	IP_RECVERR                               = 11
+	IP_RECVOPTS                              = 6
+	IP_RECVORIGDSTADDR                       = 20
+	IP_RECVTOS                               = 13
+	IP_RECVTTL                               = 12
+	IP_RETOPTS                               = 7
+	IP_ROUTER_ALERT                          = 5
+	IP_TOS                                   = 1
+	IP_TRANSPARENT                           = 19
+	IP_TTL                                   = 2
+	IP_UNBLOCK_SOURCE                        = 37
+	ITIMER_PROF                              = 2
+	ITIMER_REAL                              = 0
+	ITIMER_VIRTUAL                           = 1
+	KCMPROTO_CONNECTED                       = 0
+	KCMP_FILE                                = 0
+	KCMP_FILES                               = 2
+	KCMP_FS                                  = 3
+	KCMP_IO                                  = 5
+	KCMP_SIGHAND                             = 4
+	KCMP_SYSVSEM                             = 6
+	KCMP_VM                                  = 1
+	KCM_RECV_DISABLE                         = 1
+	KDADDIO                                  = 19252
+	KDBUS_ATTACH_ANY                         = 18446744073709551615
+	KDBUS_ATTACH_AUDIT                       = 4096
+	KDBUS_ATTACH_AUXGROUPS                   = 8
+	KDBUS_ATTACH_CAPS                        = 1024
+	KDBUS_ATTACH_CGROUP                      = 512
+	KDBUS_ATTACH_CMDLINE                     = 256
+	KDBUS_ATTACH_CONN_DESCRIPTION            = 8192
+	KDBUS_ATTACH_CREDS                       = 2
+	KDBUS_ATTACH_EXE                         = 128
+	KDBUS_ATTACH_NAMES                       = 16
+	KDBUS_ATTACH_PIDS                        = 4
+	KDBUS_ATTACH_PID_COMM                    = 64
+	KDBUS_ATTACH_SECLABEL                    = 2048
+	KDBUS_ATTACH_TID_COMM                    = 32
+	KDBUS_ATTACH_TIMESTAMP                   = 1
+	KDBUS_CMD_BUS_CREATOR_INFO               = 2147784069
+	KDBUS_CMD_BUS_MAKE                       = 1074042112
+	KDBUS_CMD_BYEBYE                         = 1074042242
+	KDBUS_CMD_CONN_INFO                      = 2147784068
+	KDBUS_CMD_ENDPOINT_MAKE                  = 1074042128
+	KDBUS_CMD_ENDPOINT_UPDATE                = 1074042129
+	KDBUS_CMD_FREE                           = 1074042243
+	KDBUS_CMD_HELLO                          = 3221525888
+	KDBUS_CMD_LIST                           = 2147784070
+	KDBUS_CMD_MATCH_ADD                      = 1074042288
+	KDBUS_CMD_MATCH_REMOVE                   = 1074042289
+	KDBUS_CMD_NAME_ACQUIRE                   = 1074042272
+	KDBUS_CMD_NAME_RELEASE                   = 1074042273
+	KDBUS_CMD_RECV                           = 2147784081
+	KDBUS_CMD_SEND                           = 1074042256
+	KDBUS_CMD_UPDATE                         = 1074042241
+	KDBUS_HELLO_ACCEPT_FD                    = 1
+	KDBUS_HELLO_ACTIVATOR                    = 2
+	KDBUS_HELLO_MONITOR                      = 8
+	KDBUS_HELLO_POLICY_HOLDER                = 4
+	KDBUS_IOCTL_MAGIC                        = 149
+	KDBUS_ITEM_ATTACH_FLAGS_RECV             = 13
+	KDBUS_ITEM_ATTACH_FLAGS_SEND             = 12
+	KDBUS_ITEM_AUDIT                         = 4108
+	KDBUS_ITEM_AUXGROUPS                     = 4099
+	KDBUS_ITEM_BLOOM_FILTER                  = 8
+	KDBUS_ITEM_BLOOM_MASK                    = 9
+	KDBUS_ITEM_BLOOM_PARAMETER               = 7
+	KDBUS_ITEM_CANCEL_FD                     = 6
+	KDBUS_ITEM_CAPS                          = 4106
+	KDBUS_ITEM_CGROUP                        = 4105
+	KDBUS_ITEM_CMDLINE                       = 4104
+	KDBUS_ITEM_CONN_DESCRIPTION              = 4109
+	KDBUS_ITEM_CREDS                         = 4097
+	KDBUS_ITEM_DST_ID                        = 16
+	KDBUS_ITEM_DST_NAME                      = 10
+	KDBUS_ITEM_EXE                           = 4103
+	KDBUS_ITEM_FDS                           = 5
+	KDBUS_ITEM_ID                            = 14
+	KDBUS_ITEM_ID_ADD                        = 32771
+	KDBUS_ITEM_ID_REMOVE                     = 32772
+	KDBUS_ITEM_MAKE_NAME                     = 11
+	KDBUS_ITEM_NAME                          = 15
+	KDBUS_ITEM_NAME_ADD                      = 32768
+	KDBUS_ITEM_NAME_CHANGE                   = 32770
+	KDBUS_ITEM_NAME_REMOVE                   = 32769
+	KDBUS_ITEM_NEGOTIATE                     = 1
+	KDBUS_ITEM_OWNED_NAME                    = 4100
+	KDBUS_ITEM_PAYLOAD_MEMFD                 = 4
+	KDBUS_ITEM_PAYLOAD_OFF                   = 3
+	KDBUS_ITEM_PAYLOAD_VEC                   = 2
+	KDBUS_ITEM_PIDS                          = 4098
+	KDBUS_ITEM_PID_COM                       = 4102
+	KDBUS_ITEM_POLICY_ACCESS                 = 8192
+	KDBUS_ITEM_REPLY_DEAD                    = 32774
+	KDBUS_ITEM_REPLY_TIMEOUT                 = 32773
+	KDBUS_ITEM_SECLABEL                      = 4107
+	KDBUS_ITEM_TID_COMM                      = 4101
+	KDBUS_ITEM_TIMESTAMP                     = 4096
+	KDBUS_LIST_ACTIVATORS                    = 4
+	KDBUS_LIST_NAMES                         = 2
+	KDBUS_LIST_QUEUED                        = 8
+	KDBUS_LIST_UNIQUE                        = 1
+	KDBUS_MAKE_ACCESS_GROUP                  = 1
+	KDBUS_MAKE_ACCESS_WORLD                  = 2
+	KDBUS_MATCH_REPLACE                      = 1
+	KDBUS_MSG_EXPECT_REPLY                   = 1
+	KDBUS_MSG_NO_AUTO_START                  = 2
+	KDBUS_MSG_SIGNAL                         = 4
+	KDBUS_NAME_ACQUIRED                      = 64
+	KDBUS_NAME_ACTIVATOR                     = 16
+	KDBUS_NAME_ALLOW_REPLACEMENT             = 2
+	KDBUS_NAME_IN_QUEUE                      = 8
+	KDBUS_NAME_PRIMARY                       = 32
+	KDBUS_NAME_QUEUE                         = 4
+	KDBUS_NAME_REPLACE_EXISTING              = 1
+	KDBUS_POLICY_ACCESS_GROUP                = 2
+	KDBUS_POLICY_ACCESS_NULL                 = 0
+	KDBUS_POLICY_ACCESS_USER                 = 1
+	KDBUS_POLICY_ACCESS_WORLD                = 3
+	KDBUS_POLICY_OWN                         = 2
+	KDBUS_POLICY_SEE                         = 0
+	KDBUS_POLICY_TALK                        = 1
+	KDBUS_RECV_RETURN_DROPPED_MSGS           = 2
+	KDBUS_RECV_RETURN_INCOMPLETE_FDS         = 1
+	KDBUS_SEND_SYNC_REPLY                    = 1
+	KDDELIO                                  = 19253
+	KDDISABIO                                = 19255
+	KDENABIO                                 = 19254
+	KDGETKEYCODE                             = 19276
+	KDGETLED                                 = 19249
+	KDGETMODE                                = 19259
+	KDGKBDIACR                               = 19274
+	KDGKBENT                                 = 19270
+	KDGKBLED                                 = 19300
+	KDGKBMETA                                = 19298
+	KDGKBMODE                                = 19268
+	KDGKBSENT                                = 19272
+	KDGKBTYPE                                = 19251
+	KDMKTONE                                 = 19248
+	KDSETKEYCODE                             = 19277
+	KDSETLED                                 = 19250
+	KDSETMODE                                = 19258
+	KDSIGACCEPT                              = 19278
+	KDSKBLED                                 = 19301
+	KDSKBMETA                                = 19299
+	KDSKBMODE                                = 19269
+	KDSKBSENT                                = 19273
+	KERNEL_CLIENT                            = 2
+	KEXEC_ARCH_386                           = 196608
+	KEXEC_ARCH_ARM                           = 2621440
+	KEXEC_ARCH_IA_64                         = 3276800
+	KEXEC_ARCH_MIPS                          = 524288
+	KEXEC_ARCH_MIPS_LE                       = 655360
+	KEXEC_ARCH_PPC                           = 1310720
+	KEXEC_ARCH_PPC64                         = 1376256
+	KEXEC_ARCH_S390                          = 1441792
+	KEXEC_ARCH_SH                            = 2752512
+	KEXEC_ARCH_X86_64                        = 4063232
+	KEXEC_ON_CRASH                           = 1
+	KEXEC_PRESERVE_CONTEXT                   = 2
+	KEYCTL_ASSUME_AUTHORITY                  = 16
+	KEYCTL_CHOWN                             = 4
+	KEYCTL_CLEAR                             = 7
+	KEYCTL_DESCRIBE                          = 6
+	KEYCTL_GET_KEYRING_ID                    = 0
+	KEYCTL_GET_PERSISTENT                    = 22
+	KEYCTL_GET_SECURITY                      = 17
+	KEYCTL_INSTANTIATE                       = 12
+	KEYCTL_INSTANTIATE_IOV                   = 20
+	KEYCTL_INVALIDATE                        = 21
+	KEYCTL_JOIN_SESSION_KEYRING              = 1
+	KEYCTL_LINK                              = 8
+	KEYCTL_NEGATE                            = 13
+	KEYCTL_READ                              = 11
+	KEYCTL_REJECT                            = 19
+	KEYCTL_REVOKE                            = 3
+	KEYCTL_SEARCH                            = 10
+	KEYCTL_SESSION_TO_PARENT                 = 18
+	KEYCTL_SETPERM                           = 5
+	KEYCTL_SET_REQKEY_KEYRING                = 14
+	KEYCTL_SET_TIMEOUT                       = 15
+	KEYCTL_UNLINK                            = 9
+	KEYCTL_UPDATE                            = 2
+	KEY_REQKEY_DEFL_DEFAULT                  = 0
+	KEY_REQKEY_DEFL_GROUP_KEYRING            = 6
+	KEY_REQKEY_DEFL_NO_CHANGE                = 18446744073709551615
+	KEY_REQKEY_DEFL_PROCESS_KEYRING          = 2
+	KEY_REQKEY_DEFL_REQUESTOR_KEYRING        = 7
+	KEY_REQKEY_DEFL_SESSION_KEYRING          = 3
+	KEY_REQKEY_DEFL_THREAD_KEYRING           = 1
+	KEY_REQKEY_DEFL_USER_KEYRING             = 4
+	KEY_REQKEY_DEFL_USER_SESSION_KEYRING     = 5
+	KEY_SPEC_PROCESS_KEYRING                 = 18446744073709551614
+	KEY_SPEC_SESSION_KEYRING                 = 18446744073709551613
+	KEY_SPEC_THREAD_KEYRING                  = 18446744073709551615
+	KEY_SPEC_USER_KEYRING                    = 18446744073709551612
+	KEY_SPEC_USER_SESSION_KEYRING            = 18446744073709551611
+	KIOCSOUND                                = 19247
+	KVM_ASSIGN_DEV_IRQ                       = 1077980784
+	KVM_ASSIGN_PCI_DEVICE                    = 2151722601
+	KVM_ASSIGN_SET_INTX_MASK                 = 1077980836
+	KVM_ASSIGN_SET_MSIX_ENTRY                = 1074835060
+	KVM_ASSIGN_SET_MSIX_NR                   = 1074310771
+	KVM_CHECK_EXTENSION                      = 44547
+	KVM_CREATE_DEVICE                        = 3222056672
+	KVM_CREATE_IRQCHIP                       = 44640
+	KVM_CREATE_PIT2                          = 1077980791
+	KVM_CREATE_VCPU                          = 44609
+	KVM_CREATE_VM                            = 44545
+	KVM_DEASSIGN_DEV_IRQ                     = 1077980789
+	KVM_DEASSIGN_PCI_DEVICE                  = 1077980786
+	KVM_DEV_IRQ_GUEST_INTX                   = 256
+	KVM_DEV_IRQ_GUEST_MSI                    = 512
+	KVM_DEV_IRQ_GUEST_MSIX                   = 1024
+	KVM_DEV_IRQ_HOST_INTX                    = 1
+	KVM_DEV_IRQ_HOST_MSI                     = 2
+	KVM_DEV_IRQ_HOST_MSIX                    = 4
+	KVM_DEV_TYPE_FSL_MPIC_20                 = 1
+	KVM_DEV_TYPE_FSL_MPIC_42                 = 2
+	KVM_DEV_TYPE_VFIO                        = 4
+	KVM_DEV_TYPE_XICS                        = 3
+	KVM_DIRTY_TLB                            = 1074835114
+	KVM_ENABLE_CAP                           = 1080602275
+	KVM_GET_CLOCK                            = 2150674044
+	KVM_GET_DEBUGREGS                        = 2155916961
+	KVM_GET_DEVICE_ATTR                      = 1075359458
+	KVM_GET_DIRTY_LOG                        = 1074835010
+	KVM_GET_EMULATED_CPUID                   = 3221794313
+	KVM_GET_FPU                              = 2174791308
+	KVM_GET_IRQCHIP                          = 3255348834
+	KVM_GET_LAPIC                            = 2214637198
+	KVM_GET_MP_STATE                         = 2147790488
+	KVM_GET_MSRS                             = 3221794440
+	KVM_GET_MSR_INDEX_LIST                   = 3221532162
+	KVM_GET_ONE_REG                          = 1074835115
+	KVM_GET_PIT2                             = 2154868383
+	KVM_GET_REGS                             = 2156965505
+	KVM_GET_REG_LIST                         = 3221794480
+	KVM_GET_SREGS                            = 2167975555
+	KVM_GET_SUPPORTED_CPUID                  = 3221794309
+	KVM_GET_TSC_KHZ                          = 44707
+	KVM_GET_VCPU_EVENTS                      = 2151722655
+	KVM_GET_VCPU_MMAP_SIZE                   = 44548
+	KVM_GET_XCRS                             = 2173218470
+	KVM_GET_XSAVE                            = 2415963812
+	KVM_GUESTDBG_ENABLE                      = 1
+	KVM_GUESTDBG_INJECT_BP                   = 524288
+	KVM_GUESTDBG_INJECT_DB                   = 262144
+	KVM_GUESTDBG_SINGLESTEP                  = 2
+	KVM_GUESTDBG_USE_HW_BP                   = 131072
+	KVM_GUESTDBG_USE_SW_BP                   = 65536
+	KVM_HAS_DEVICE_ATTR                      = 1075359459
+	KVM_INTERRUPT                            = 1074048646
+	KVM_IOEVENTFD                            = 1077980793
+	KVM_IOEVENTFD_FLAG_DATAMATCH             = 1
+	KVM_IOEVENTFD_FLAG_DEASSIGN              = 4
+	KVM_IOEVENTFD_FLAG_PIO                   = 2
+	KVM_IOEVENTFD_FLAG_VIRTIO_CCW_NOTIFY     = 8
+	KVM_IRQFD                                = 1075883638
+	KVM_IRQ_LINE                             = 1074310753
+	KVM_IRQ_ROUTING_IRQCHIP                  = 1
+	KVM_IRQ_ROUTING_MSI                      = 2
+	KVM_KVMCLOCK_CTRL                        = 44717
+	KVM_MEMSLOT_INCOHERENT                   = 131072
+	KVM_MEMSLOT_INVALID                      = 65536
+	KVM_MEM_LOG_DIRTY_PAGES                  = 1
+	KVM_MEM_READONLY                         = 2
+	KVM_MP_STATE_CHECK_STOP                  = 6
+	KVM_MP_STATE_HALTED                      = 3
+	KVM_MP_STATE_INIT_RECEIVED               = 2
+	KVM_MP_STATE_LOAD                        = 8
+	KVM_MP_STATE_OPERATING                   = 7
+	KVM_MP_STATE_RUNNABLE                    = 0
+	KVM_MP_STATE_SIPI_RECEIVED               = 4
+	KVM_MP_STATE_STOPPED                     = 5
+	KVM_MP_STATE_UNINITIALIZED               = 1
+	KVM_NMI                                  = 44698
+	KVM_PPC_ALLOCATE_HTAB                    = 3221532327
+	KVM_PPC_GET_PVINFO                       = 1082175137
+	KVM_PPC_GET_SMMU_INFO                    = 2186325670
+	KVM_RUN                                  = 44672
+	KVM_S390_INTERRUPT                       = 1074835092
+	KVM_S390_UCAS_MAP                        = 1075359312
+	KVM_S390_UCAS_UNMAP                      = 1075359313
+	KVM_S390_VCPU_FAULT                      = 1074310738
+	KVM_SET_BOOT_CPU_ID                      = 44664
+	KVM_SET_CLOCK                            = 1076932219
+	KVM_SET_CPUID                            = 1074310794
+	KVM_SET_DEBUGREGS                        = 1082175138
+	KVM_SET_DEVICE_ATTR                      = 1075359457
+	KVM_SET_FPU                              = 1101049485
+	KVM_SET_GSI_ROUTING                      = 1074310762
+	KVM_SET_GUEST_DEBUG                      = 1078505115
+	KVM_SET_IDENTITY_MAP_ADDR                = 1074310728
+	KVM_SET_IRQCHIP                          = 2181607011
+	KVM_SET_LAPIC                            = 1140895375
+	KVM_SET_MEMORY_REGION                    = 1075359296
+	KVM_SET_MP_STATE                         = 1074048665
+	KVM_SET_MSRS                             = 1074310793
+	KVM_SET_ONE_REG                          = 1074835116
+	KVM_SET_PIT2                             = 1081126560
+	KVM_SET_REGS                             = 1083223682
+	KVM_SET_SIGNAL_MASK                      = 1074048651
+	KVM_SET_SREGS                            = 1094233732
+	KVM_SET_TSC_KHZ                          = 44706
+	KVM_SET_TSS_ADDR                         = 44615
+	KVM_SET_USER_MEMORY_REGION               = 1075883590
+	KVM_SET_VCPU_EVENTS                      = 1077980832
+	KVM_SET_XCRS                             = 1099476647
+	KVM_SET_XSAVE                            = 1342221989
+	KVM_SIGNAL_MSI                           = 1075883685
+	KVM_SMI                                  = 44727
+	KVM_TRANSLATE                            = 3222843013
+	KVM_XEN_HVM_CONFIG                       = 1077456506
+	L2CAP_CONNINFO                           = 2
+	L2CAP_LM                                 = 3
+	L2CAP_LM_AUTH                            = 2
+	L2CAP_LM_ENCRYPT                         = 4
+	L2CAP_LM_FIPS                            = 64
+	L2CAP_LM_MASTER                          = 1
+	L2CAP_LM_RELIABLE                        = 16
+	L2CAP_LM_SECURE                          = 32
+	L2CAP_LM_TRUSTED                         = 8
+	L2CAP_OPTIONS                            = 1
+	LINK_KIND_BOND                           = 1684959074
+	LINK_KIND_BRIDGE                         = 111494740669026
+	LINK_KIND_DUMMY                          = 521526932836
+	LINK_KIND_IPVLAN                         = 121364710584425
+	LINK_KIND_MACVLAN                        = 31069365908758893
+	LINK_KIND_VETH                           = 1752458614
+	LINK_KIND_VLAN                           = 1851878518
+	LINK_KIND_VXLAN                          = 474080901238
+	LOCK_EX                                  = 2
+	LOCK_NB                                  = 4
+	LOCK_SH                                  = 1
+	LOCK_UN                                  = 8
+	MADV_DODUMP                              = 17
+	MADV_DOFORK                              = 11
+	MADV_DONTDUMP                            = 16
+	MADV_DONTFORK                            = 10
+	MADV_DONTNEED                            = 4
+	MADV_HUGEPAGE                            = 14
+	MADV_HWPOISON                            = 100
+	MADV_MERGEABLE                           = 12
+	MADV_NOHUGEPAGE                          = 15
+	MADV_NORMAL                              = 0
+	MADV_RANDOM                              = 1
+	MADV_REMOVE                              = 9
+	MADV_SEQUENTIAL                          = 2
+	MADV_SOFT_OFFLINE                        = 101
+	MADV_UNMERGEABLE                         = 13
+	MADV_WILLNEED                            = 3
+	MAP_32BIT                                = 64
+	MAP_ANONYMOUS                            = 32
+	MAP_DENYWRITE                            = 2048
+	MAP_EXECUTABLE                           = 4096
+	MAP_FILE                                 = 0
+	MAP_FIXED                                = 16
+	MAP_GROWSDOWN                            = 256
+	MAP_HUGETLB                              = 262144
+	MAP_LOCKED                               = 8192
+	MAP_NONBLOCK                             = 65536
+	MAP_NORESERVE                            = 16384
+	MAP_POPULATE                             = 32768
+	MAP_PRIVATE                              = 2
+	MAP_SHARED                               = 1
+	MAP_STACK                                = 131072
+	MAP_UNINITIALIZED                        = 0
+	MCAST_BLOCK_SOURCE                       = 43
+	MCAST_EXCLUDE                            = 0
+	MCAST_INCLUDE                            = 1
+	MCAST_JOIN_GROUP                         = 42
+	MCAST_JOIN_SOURCE_GROUP                  = 46
+	MCAST_LEAVE_GROUP                        = 45
+	MCAST_LEAVE_SOURCE_GROUP                 = 47
+	MCAST_MSFILTER                           = 48
+	MCAST_UNBLOCK_SOURCE                     = 44
+	MCL_CURRENT                              = 1
+	MCL_FUTURE                               = 2
+	MFD_ALLOW_SEALING                        = 2
+	MFD_CLOEXEC                              = 1
+	MLOCK_ONFAULT                            = 1
+	MMAP_PAGE_ZERO                           = 1048576
+	MNT_DETACH                               = 2
+	MNT_EXPIRE                               = 4
+	MNT_FORCE                                = 1
+	MODULE_INIT_IGNORE_MODVERSIONS           = 1
+	MODULE_INIT_IGNORE_VERMAGIC              = 2
+	MPOL_BIND                                = 2
+	MPOL_DEFAULT                             = 0
+	MPOL_F_ADDR                              = 2
+	MPOL_F_MEMS_ALLOWED                      = 4
+	MPOL_F_NODE                              = 1
+	MPOL_F_RELATIVE_NODES                    = 16384
+	MPOL_F_STATIC_NODES                      = 32768
+	MPOL_INTERLEAVE                          = 3
+	MPOL_MF_MOVE                             = 2
+	MPOL_MF_MOVE_ALL                         = 4
+	MPOL_MF_STRICT                           = 1
+	MPOL_PREFERRED                           = 1
+	MREMAP_FIXED                             = 2
+	MREMAP_MAYMOVE                           = 1
+	MSG_CMSG_CLOEXEC                         = 1073741824
+	MSG_CONFIRM                              = 2048
+	MSG_DONTROUTE                            = 4
+	MSG_DONTWAIT                             = 64
+	MSG_EOR                                  = 128
+	MSG_ERRQUEUE                             = 8192
+	MSG_EXCEPT                               = 8192
+	MSG_INFO                                 = 12
+	MSG_MORE                                 = 32768
+	MSG_NOERROR                              = 4096
+	MSG_NOSIGNAL                             = 16384
+	MSG_OOB                                  = 1
+	MSG_PEEK                                 = 2
+	MSG_STAT                                 = 11
+	MSG_TRUNC                                = 32
+	MSG_WAITALL                              = 256
+	MSG_WAITFORONE                           = 65536
+	MS_ASYNC                                 = 1
+	MS_BIND                                  = 4096
+	MS_DIRSYNC                               = 128
+	MS_INVALIDATE                            = 2
+	MS_MANDLOCK                              = 64
+	MS_MOVE                                  = 8192
+	MS_NOATIME                               = 1024
+	MS_NODEV                                 = 4
+	MS_NODIRATIME                            = 2048
+	MS_NOEXEC                                = 8
+	MS_NOSUID                                = 2
+	MS_RDONLY                                = 1
+	MS_RELATIME                              = 2097152
+	MS_REMOUNT                               = 32
+	MS_SILENT                                = 32768
+	MS_STRICTATIME                           = 16777216
+	MS_SYNC                                  = 4
+	MS_SYNCHRONOUS                           = 16
+	NDA_DST                                  = 1
+	NDA_IFINDEX                              = 8
+	NDA_LLADDR                               = 2
+	NDA_PORT                                 = 6
+	NDA_PROBES                               = 4
+	NDA_VLAN                                 = 5
+	NDA_VNI                                  = 7
+	NETLINK_ADD_MEMBERSHIP                   = 1
+	NETLINK_AUDIT                            = 9
+	NETLINK_BROADCAST_ERROR                  = 4
+	NETLINK_CAP_ACK                          = 10
+	NETLINK_CONNECTOR                        = 11
+	NETLINK_CRYPTO                           = 21
+	NETLINK_DNRTMSG                          = 14
+	NETLINK_DROP_MEMBERSHIP                  = 2
+	NETLINK_ECRYPTFS                         = 19
+	NETLINK_FIB_LOOKUP                       = 10
+	NETLINK_FIREWALL                         = 3
+	NETLINK_GENERIC                          = 16
+	NETLINK_INET_DIAG                        = 4
+	NETLINK_IP6_FW                           = 13
+	NETLINK_ISCSI                            = 8
+	NETLINK_KOBJECT_UEVENT                   = 15
+	NETLINK_LISTEN_ALL_NSID                  = 8
+	NETLINK_LIST_MEMBERSHIPS                 = 9
+	NETLINK_NETFILTER                        = 12
+	NETLINK_NFLOG                            = 5
+	NETLINK_NO_ENOBUFS                       = 5
+	NETLINK_PKTINFO                          = 3
+	NETLINK_RDMA                             = 20
+	NETLINK_ROUTE                            = 0
+	NETLINK_RX_RING                          = 6
+	NETLINK_SCSITRANSPORT                    = 18
+	NETLINK_SELINUX                          = 7
+	NETLINK_SOCK_DIAG                        = 4
+	NETLINK_TX_RING                          = 7
+	NETLINK_UNUSED                           = 1
+	NETLINK_USERSOCK                         = 2
+	NETLINK_XFRM                             = 6
+	NETROM_IDLE                              = 7
+	NETROM_N2                                = 3
+	NETROM_T1                                = 1
+	NETROM_T2                                = 2
+	NETROM_T4                                = 6
+	NFC_LLCP_MIUX                            = 1
+	NFC_LLCP_REMOTE_LTO                      = 3
+	NFC_LLCP_REMOTE_MIU                      = 2
+	NFC_LLCP_REMOTE_RW                       = 4
+	NFC_LLCP_RW                              = 0
+	NFC_PROTO_FELICA                         = 3
+	NFC_PROTO_ISO14443                       = 4
+	NFC_PROTO_ISO14443_B                     = 6
+	NFC_PROTO_ISO15693                       = 7
+	NFC_PROTO_JEWEL                          = 1
+	NFC_PROTO_MIFARE                         = 2
+	NFC_PROTO_NFC_DEP                        = 5
+	NFC_SOCKPROTO_LLCP                       = 1
+	NFC_SOCKPROTO_RAW                        = 0
+	NLM_F_ACK                                = 4
+	NLM_F_APPEND                             = 2048
+	NLM_F_ATOMIC                             = 1024
+	NLM_F_CREATE                             = 1024
+	NLM_F_DUMP                               = 768
+	NLM_F_DUMP_FILTERED                      = 32
+	NLM_F_DUMP_INTR                          = 16
+	NLM_F_ECHO                               = 8
+	NLM_F_EXCL                               = 512
+	NLM_F_MATCH                              = 512
+	NLM_F_MULTI                              = 2
+	NLM_F_REPLACE                            = 256
+	NLM_F_REQUEST                            = 1
+	NLM_F_ROOT                               = 256
+	NO_CLIENT                                = 0
+	NTF_MASTER                               = 4
+	NTF_PROXY                                = 8
+	NTF_ROUTER                               = 128
+	NTF_SELF                                 = 2
+	NTF_USE                                  = 1
+	NT_386_IOPERM                            = 513
+	NT_386_TLS                               = 512
+	NT_AUXV                                  = 6
+	NT_PRFPREG                               = 2
+	NT_PRPSINFO                              = 3
+	NT_PRSTATUS                              = 1
+	NT_TASKSTRUCT                            = 4
+	NT_X86_XSTATE                            = 514
+	NUD_DELAY                                = 8
+	NUD_FAILED                               = 32
+	NUD_INCOMPLETE                           = 1
+	NUD_NOARP                                = 64
+	NUD_NONE                                 = 0
+	NUD_PERMANENT                            = 128
+	NUD_PROBE                                = 16
+	NUD_REACHABLE                            = 2
+	NUD_STALE                                = 4
+	N_6PACK                                  = 7
+	N_AX25                                   = 5
+	N_CAIF                                   = 20
+	N_GIGASET_M101                           = 16
+	N_GSM0710                                = 21
+	N_HCI                                    = 15
+	N_HDLC                                   = 13
+	N_IRDA                                   = 11
+	N_MASC                                   = 8
+	N_MOUSE                                  = 2
+	N_PPP                                    = 3
+	N_PPS                                    = 18
+	N_PROFIBUS_FDL                           = 10
+	N_R3964                                  = 9
+	N_SLCAN                                  = 17
+	N_SLIP                                   = 1
+	N_SMSBLOCK                               = 12
+	N_STRIP                                  = 4
+	N_SYNC_PPP                               = 14
+	N_TI_WL                                  = 22
+	N_TRACEROUTER                            = 24
+	N_TRACESINK                              = 23
+	N_TTY                                    = 0
+	N_V253                                   = 19
+	N_X25                                    = 6
+	O_APPEND                                 = 1024
+	O_CLOEXEC                                = 524288
+	O_CREAT                                  = 64
+	O_DIRECT                                 = 16384
+	O_DIRECTORY                              = 65536
+	O_DSYNC                                  = 4096
+	O_EXCL                                   = 128
+	O_LARGEFILE                              = 32768
+	O_NOATIME                                = 262144
+	O_NOCTTY                                 = 256
+	O_NOFOLLOW                               = 131072
+	O_NONBLOCK                               = 2048
+	O_PATH                                   = 2097152
+	O_RDONLY                                 = 0
+	O_RDWR                                   = 2
+	O_SYNC                                   = 1052672
+	O_TRUNC                                  = 512
+	O_WRONLY                                 = 1
+	PERF_COUNT_HW_BRANCH_INSTRUCTIONS        = 4
+	PERF_COUNT_HW_BRANCH_MISSES              = 5
+	PERF_COUNT_HW_BUS_CYCLES                 = 6
+	PERF_COUNT_HW_CACHE_BPU                  = 5
+	PERF_COUNT_HW_CACHE_DTLB                 = 3
+	PERF_COUNT_HW_CACHE_ITLB                 = 4
+	PERF_COUNT_HW_CACHE_L1D                  = 0
+	PERF_COUNT_HW_CACHE_L1I                  = 1
+	PERF_COUNT_HW_CACHE_LL                   = 2
+	PERF_COUNT_HW_CACHE_MISSES               = 3
+	PERF_COUNT_HW_CACHE_NODE                 = 6
+	PERF_COUNT_HW_CACHE_OP_PREFETCH          = 2
+	PERF_COUNT_HW_CACHE_OP_READ              = 0
+	PERF_COUNT_HW_CACHE_OP_WRITE             = 1
+	PERF_COUNT_HW_CACHE_REFERENCES           = 2
+	PERF_COUNT_HW_CACHE_RESULT_ACCESS        = 0
+	PERF_COUNT_HW_CACHE_RESULT_MISS          = 1
+	PERF_COUNT_HW_CPU_CYCLES                 = 0
+	PERF_COUNT_HW_INSTRUCTIONS               = 1
+	PERF_COUNT_HW_REF_CPU_CYCLES             = 9
+	PERF_COUNT_HW_STALLED_CYCLES_BACKEND     = 8
+	PERF_COUNT_HW_STALLED_CYCLES_FRONTEND    = 7
+	PERF_COUNT_SW_ALIGNMENT_FAULTS           = 7
+	PERF_COUNT_SW_CONTEXT_SWITCHES           = 3
+	PERF_COUNT_SW_CPU_CLOCK                  = 0
+	PERF_COUNT_SW_CPU_MIGRATIONS             = 4
+	PERF_COUNT_SW_DUMMY                      = 9
+	PERF_COUNT_SW_EMULATION_FAULTS           = 8
+	PERF_COUNT_SW_PAGE_FAULTS                = 2
+	PERF_COUNT_SW_PAGE_FAULTS_MAJ            = 6
+	PERF_COUNT_SW_PAGE_FAULTS_MIN            = 5
+	PERF_COUNT_SW_TASK_CLOCK                 = 1
+	PERF_EVENT_IOC_DISABLE                   = 9217
+	PERF_EVENT_IOC_ENABLE                    = 9216
+	PERF_EVENT_IOC_ID                        = 2148017159
+	PERF_EVENT_IOC_PERIOD                    = 1074275332
+	PERF_EVENT_IOC_REFRESH                   = 9218
+	PERF_EVENT_IOC_RESET                     = 9219
+	PERF_EVENT_IOC_SET_BPF                   = 1074013192
+	PERF_EVENT_IOC_SET_FILTER                = 1074275334
+	PERF_EVENT_IOC_SET_OUTPUT                = 9221
+	PERF_FLAG_FD_CLOEXEC                     = 8
+	PERF_FLAG_FD_NO_GROUP                    = 1
+	PERF_FLAG_FD_OUTPUT                      = 2
+	PERF_FLAG_PID_CGROUP                     = 4
+	PERF_TYPE_BREAKPOINT                     = 5
+	PERF_TYPE_HARDWARE                       = 0
+	PERF_TYPE_HW_CACHE                       = 3
+	PERF_TYPE_RAW                            = 4
+	PERF_TYPE_SOFTWARE                       = 1
+	PERF_TYPE_TRACEPOINT                     = 2
+	PER_BSD                                  = 6
+	PER_HPUX                                 = 16
+	PER_IRIX32                               = 67108873
+	PER_IRIX64                               = 67108875
+	PER_IRIXN32                              = 67108874
+	PER_ISCR4                                = 67108869
+	PER_LINUX                                = 0
+	PER_LINUX32                              = 8
+	PER_OSF4                                 = 15
+	PER_OSR5                                 = 100663299
+	PER_RISCOS                               = 12
+	PER_SOLARIS                              = 67108877
+	PER_SVR3                                 = 83886082
+	PER_SVR4                                 = 68157441
+	PER_UW7                                  = 68157454
+	PER_WYSEV386                             = 83886084
+	PER_XENIX                                = 83886087
+	PIO_CMAP                                 = 19313
+	PIO_FONT                                 = 19297
+	PIO_FONTRESET                            = 19309
+	PIO_FONTX                                = 19308
+	PIO_SCRNMAP                              = 19265
+	PIO_UNIMAP                               = 19303
+	PIO_UNIMAPCLR                            = 19304
+	PIO_UNISCRNMAP                           = 19306
+	POLLERR                                  = 8
+	POLLHUP                                  = 16
+	POLLIN                                   = 1
+	POLLOUT                                  = 4
+	POLLPRI                                  = 2
+	POLLRDHUP                                = 8192
+	POSIX_FADV_DONTNEED                      = 4
+	POSIX_FADV_NOREUSE                       = 5
+	POSIX_FADV_NORMAL                        = 0
+	POSIX_FADV_RANDOM                        = 1
+	POSIX_FADV_SEQUENTIAL                    = 2
+	POSIX_FADV_WILLNEED                      = 3
+	PPPIOCGCHAN                              = 2147775543
+	PPPIOCGUNIT                              = 2147775574
+	PRIO_PGRP                                = 1
+	PRIO_PROCESS                             = 0
+	PRIO_USER                                = 2
+	PROT_EXEC                                = 4
+	PROT_READ                                = 1
+	PROT_WRITE                               = 2
+	PR_CAPBSET_DROP                          = 24
+	PR_CAPBSET_READ                          = 23
+	PR_ENDIAN_BIG                            = 0
+	PR_ENDIAN_LITTLE                         = 1
+	PR_ENDIAN_PPC_LITTLE                     = 2
+	PR_FP_EXC_ASYNC                          = 2
+	PR_FP_EXC_DISABLED                       = 0
+	PR_FP_EXC_DIV                            = 65536
+	PR_FP_EXC_INV                            = 1048576
+	PR_FP_EXC_NONRECOV                       = 1
+	PR_FP_EXC_OVF                            = 131072
+	PR_FP_EXC_PRECISE                        = 3
+	PR_FP_EXC_RES                            = 524288
+	PR_FP_EXC_SW_ENABLE                      = 128
+	PR_FP_EXC_UND                            = 262144
+	PR_GET_CHILD_SUBREAPER                   = 37
+	PR_GET_DUMPABLE                          = 3
+	PR_GET_ENDIAN                            = 19
+	PR_GET_FPEMU                             = 9
+	PR_GET_FPEXC                             = 11
+	PR_GET_KEEPCAPS                          = 7
+	PR_GET_NAME                              = 16
+	PR_GET_NO_NEW_PRIVS                      = 39
+	PR_GET_PDEATHSIG                         = 2
+	PR_GET_SECCOMP                           = 21
+	PR_GET_SECUREBITS                        = 27
+	PR_GET_TID_ADDRESS                       = 40
+	PR_GET_TIMERSLACK                        = 30
+	PR_GET_TIMING                            = 13
+	PR_GET_TSC                               = 25
+	PR_GET_UNALIGN                           = 5
+	PR_MCE_KILL                              = 33
+	PR_MCE_KILL_GET                          = 34
+	PR_SET_CHILD_SUBREAPER                   = 36
+	PR_SET_DUMPABLE                          = 4
+	PR_SET_ENDIAN                            = 20
+	PR_SET_FPEMU                             = 10
+	PR_SET_FPEXC                             = 12
+	PR_SET_KEEPCAPS                          = 8
+	PR_SET_MM                                = 35
+	PR_SET_MM_BRK                            = 7
+	PR_SET_MM_END_CODE                       = 2
+	PR_SET_MM_END_DATA                       = 4
+	PR_SET_MM_START_BRK                      = 6
+	PR_SET_MM_START_CODE                     = 1
+	PR_SET_MM_START_DATA                     = 3
+	PR_SET_MM_START_STACK                    = 5
+	PR_SET_NAME                              = 15
+	PR_SET_NO_NEW_PRIVS                      = 38
+	PR_SET_PDEATHSIG                         = 1
+	PR_SET_PTRACER                           = 1499557217
+	PR_SET_SECCOMP                           = 22
+	PR_SET_SECUREBITS                        = 28
+	PR_SET_TIMERSLACK                        = 29
+	PR_SET_TIMING                            = 14
+	PR_SET_TSC                               = 26
+	PR_SET_UNALIGN                           = 6
+	PR_TASK_PERF_EVENTS_DISABLE              = 31
+	PR_TASK_PERF_EVENTS_ENABLE               = 32
+	PTRACE_ATTACH                            = 16
+	PTRACE_CONT                              = 7
+	PTRACE_DETACH                            = 17
+	PTRACE_GETEVENTMSG                       = 16897
+	PTRACE_GETFPREGS                         = 14
+	PTRACE_GETREGS                           = 12
+	PTRACE_GETREGSET                         = 16900
+	PTRACE_GETSIGINFO                        = 16898
+	PTRACE_INTERRUPT                         = 16903
+	PTRACE_KILL                              = 8
+	PTRACE_LISTEN                            = 16904
+	PTRACE_O_EXITKILL                        = 1048576
+	PTRACE_O_TRACECLONE                      = 8
+	PTRACE_O_TRACEEXEC                       = 16
+	PTRACE_O_TRACEEXIT                       = 64
+	PTRACE_O_TRACEFORK                       = 2
+	PTRACE_O_TRACESYSGOOD                    = 1
+	PTRACE_O_TRACEVFORK                      = 4
+	PTRACE_O_TRACEVFORKDONE                  = 32
+	PTRACE_PEEKDATA                          = 2
+	PTRACE_PEEKTEXT                          = 1
+	PTRACE_PEEKUSR                           = 3
+	PTRACE_POKEDATA                          = 5
+	PTRACE_POKETEXT                          = 4
+	PTRACE_POKEUSR                           = 6
+	PTRACE_SEIZE                             = 16902
+	PTRACE_SETFPREGS                         = 15
+	PTRACE_SETOPTIONS                        = 16896
+	PTRACE_SETREGS                           = 13
+	PTRACE_SETREGSET                         = 16901
+	PTRACE_SETSIGINFO                        = 16899
+	PTRACE_SINGLESTEP                        = 9
+	PTRACE_SYSCALL                           = 24
+	PTRACE_SYSEMU                            = 31
+	PTRACE_SYSEMU_SINGLESTEP                 = 32
+	PTRACE_TRACEME                           = 0
+	P_ALL                                    = 0
+	P_PGID                                   = 2
+	P_PID                                    = 1
+	READ_IMPLIES_EXEC                        = 4194304
+	RENAME_EXCHANGE                          = 2
+	RENAME_NOREPLACE                         = 1
+	RENAME_WHITEOUT                          = 4
+	RFCOMM_CONNINFO                          = 2
+	RFCOMM_LM                                = 3
+	RLIMIT_AS                                = 9
+	RLIMIT_CORE                              = 4
+	RLIMIT_CPU                               = 0
+	RLIMIT_DATA                              = 2
+	RLIMIT_FSIZE                             = 1
+	RLIMIT_LOCKS                             = 10
+	RLIMIT_MEMLOCK                           = 8
+	RLIMIT_MSGQUEUE                          = 12
+	RLIMIT_NICE                              = 13
+	RLIMIT_NOFILE                            = 7
+	RLIMIT_NPROC                             = 6
+	RLIMIT_RSS                               = 5
+	RLIMIT_RTPRIO                            = 14
+	RLIMIT_RTTIME                            = 15
+	RLIMIT_SIGPENDING                        = 11
+	RLIMIT_STACK                             = 3
+	RNDADDENTROPY                            = 1074287107
+	RNDADDTOENTCNT                           = 1074024961
+	RNDCLEARPOOL                             = 20998
+	RNDGETENTCNT                             = 2147766784
+	RNDZAPENTCNT                             = 20996
+	RTAX_ADVMSS                              = 8
+	RTAX_FEATURES                            = 12
+	RTAX_HOPLIMIT                            = 10
+	RTAX_INITCWND                            = 11
+	RTAX_MTU                                 = 2
+	RTAX_RTT                                 = 4
+	RTAX_WINDOW                              = 3
+	RTA_DST                                  = 1
+	RTA_FLOW                                 = 11
+	RTA_GATEWAY                              = 5
+	RTA_IIF                                  = 3
+	RTA_MARK                                 = 16
+	RTA_METRICS                              = 8
+	RTA_OIF                                  = 4
+	RTA_PREFSRC                              = 7
+	RTA_PRIORITY                             = 6
+	RTA_SRC                                  = 2
+	RTA_TABLE                                = 15
+	RTM_DELADDR                              = 21
+	RTM_DELLINK                              = 17
+	RTM_DELNEIGH                             = 29
+	RTM_DELROUTE                             = 25
+	RTM_F_CLONED                             = 512
+	RTM_F_EQUALIZE                           = 1024
+	RTM_F_NOTIFY                             = 256
+	RTM_F_PREFIX                             = 2048
+	RTM_GETADDR                              = 22
+	RTM_GETLINK                              = 18
+	RTM_GETNEIGH                             = 30
+	RTM_GETROUTE                             = 26
+	RTM_NEWADDR                              = 20
+	RTM_NEWLINK                              = 16
+	RTM_NEWNEIGH                             = 28
+	RTM_NEWROUTE                             = 24
+	RTM_SETLINK                              = 19
+	RTN_ANYCAST                              = 4
+	RTN_BLACKHOLE                            = 6
+	RTN_BROADCAST                            = 3
+	RTN_LOCAL                                = 2
+	RTN_MULTICAST                            = 5
+	RTN_NAT                                  = 10
+	RTN_PROHIBIT                             = 8
+	RTN_THROW                                = 9
+	RTN_UNICAST                              = 1
+	RTN_UNREACHABLE                          = 7
+	RTN_UNSPEC                               = 0
+	RTN_XRESOLVE                             = 11
+	RTPROT_BOOT                              = 3
+	RTPROT_KERNEL                            = 2
+	RTPROT_REDIRECT                          = 1
+	RTPROT_STATIC                            = 4
+	RTPROT_UNSPEC                            = 0
+	RT_SCOPE_HOST                            = 254
+	RT_SCOPE_LINK                            = 253
+	RT_SCOPE_NOWHERE                         = 255
+	RT_SCOPE_SITE                            = 200
+	RT_SCOPE_UNIVERSE                        = 0
+	RT_TABLE_COMPAT                          = 252
+	RT_TABLE_DEFAULT                         = 253
+	RT_TABLE_LOCAL                           = 255
+	RT_TABLE_MAIN                            = 254
+	RT_TABLE_UNSPEC                          = 0
+	RUSAGE_CHILDREN                          = 18446744073709551615
+	RUSAGE_SELF                              = 0
+	RUSAGE_THREAD                            = 1
+	SA_NOCLDSTOP                             = 1
+	SA_NOCLDWAIT                             = 2
+	SA_NODEFER                               = 1073741824
+	SA_ONSTACK                               = 134217728
+	SA_RESETHAND                             = 2147483648
+	SA_RESTART                               = 268435456
+	SA_SIGINFO                               = 4
+	SCHED_BATCH                              = 3
+	SCHED_DEADLINE                           = 6
+	SCHED_FIFO                               = 1
+	SCHED_FLAG_RESET_ON_FORK                 = 1
+	SCHED_IDLE                               = 5
+	SCHED_NORMAL                             = 0
+	SCHED_RR                                 = 2
+	SCM_CREDENTIALS                          = 2
+	SCM_RIGHTS                               = 1
+	SCO_CONNINFO                             = 2
+	SCO_OPTIONS                              = 1
+	SCTP_ABORT                               = 4
+	SCTP_ADAPTATION_LAYER                    = 7
+	SCTP_ADDR_OVER                           = 2
+	SCTP_ASSOCINFO                           = 1
+	SCTP_AUTH_ACTIVE_KEY                     = 24
+	SCTP_AUTH_CHUNK                          = 21
+	SCTP_AUTH_DELETE_KEY                     = 25
+	SCTP_AUTH_KEY                            = 23
+	SCTP_AUTOCLOSE                           = 4
+	SCTP_AUTO_ASCONF                         = 30
+	SCTP_CONTEXT                             = 17
+	SCTP_DEFAULT_SEND_PARAM                  = 10
+	SCTP_DEFAULT_SNDINFO                     = 34
+	SCTP_DELAYED_SACK                        = 16
+	SCTP_DISABLE_FRAGMENTS                   = 8
+	SCTP_EOF                                 = 512
+	SCTP_EVENTS                              = 11
+	SCTP_FRAGMENT_INTERLEAVE                 = 18
+	SCTP_GET_ASSOC_ID_LIST                   = 29
+	SCTP_GET_ASSOC_NUMBER                    = 28
+	SCTP_GET_ASSOC_STATS                     = 112
+	SCTP_GET_LOCAL_ADDRS                     = 109
+	SCTP_GET_PEER_ADDRS                      = 108
+	SCTP_GET_PEER_ADDR_INFO                  = 15
+	SCTP_HMAC_IDENT                          = 22
+	SCTP_INIT                                = 0
+	SCTP_INITMSG                             = 2
+	SCTP_I_WANT_MAPPED_V4_ADDR               = 12
+	SCTP_LOCAL_AUTH_CHUNKS                   = 27
+	SCTP_MAXSEG                              = 13
+	SCTP_MAX_BURST                           = 20
+	SCTP_NODELAY                             = 3
+	SCTP_PARTIAL_DELIVERY_POINT              = 19
+	SCTP_PEER_ADDR_PARAMS                    = 9
+	SCTP_PEER_ADDR_THLDS                     = 31
+	SCTP_PEER_AUTH_CHUNKS                    = 26
+	SCTP_PRIMARY_ADDR                        = 6
+	SCTP_RECVNXTINFO                         = 33
+	SCTP_RECVRCVINFO                         = 32
+	SCTP_RTOINFO                             = 0
+	SCTP_SET_PEER_PRIMARY_ADDR               = 5
+	SCTP_SNDINFO                             = 2
+	SCTP_SNDRCV                              = 1
+	SCTP_SOCKOPT_BINDX_ADD                   = 100
+	SCTP_SOCKOPT_BINDX_REM                   = 101
+	SCTP_SOCKOPT_CONNECTX                    = 110
+	SCTP_SOCKOPT_CONNECTX3                   = 111
+	SCTP_SOCKOPT_CONNECTX_OLD                = 107
+	SCTP_SOCKOPT_PEELOFF                     = 102
+	SCTP_STATUS                              = 14
+	SCTP_UNORDERED                           = 1
+	SECCOMP_FILTER_FLAG_TSYNC                = 1
+	SECCOMP_MODE_DISABLED                    = 0
+	SECCOMP_MODE_FILTER                      = 2
+	SECCOMP_MODE_STRICT                      = 1
+	SECCOMP_RET_ALLOW                        = 2147418112
+	SECCOMP_RET_ERRNO                        = 327680
+	SECCOMP_RET_TRAP                         = 196608
+	SECCOMP_SET_MODE_FILTER                  = 1
+	SECCOMP_SET_MODE_STRICT                  = 0
+	SEEK_CUR                                 = 1
+	SEEK_DATA                                = 3
+	SEEK_END                                 = 2
+	SEEK_HOLE                                = 4
+	SEEK_SET                                 = 0
+	SEM_INFO                                 = 19
+	SEM_STAT                                 = 18
+	SEM_UNDO                                 = 4096
+	SETALL                                   = 17
+	SETVAL                                   = 16
+	SFD_CLOEXEC                              = 524288
+	SFD_NONBLOCK                             = 2048
+	SHM_HUGETLB                              = 2048
+	SHM_INFO                                 = 14
+	SHM_LOCK                                 = 11
+	SHM_NORESERVE                            = 4096
+	SHM_RDONLY                               = 4096
+	SHM_REMAP                                = 16384
+	SHM_RND                                  = 8192
+	SHM_STAT                                 = 13
+	SHM_UNLOCK                               = 12
+	SHORT_INODE                              = 16777216
+	SHUT_RD                                  = 0
+	SHUT_WR                                  = 1
+	SIGEV_NONE                               = 1
+	SIGEV_SIGNAL                             = 0
+	SIGEV_THREAD                             = 2
+	SIG_BLOCK                                = 0
+	SIG_SETMASK                              = 2
+	SIG_UNBLOCK                              = 1
+	SIOCADDRT                                = 35083
+	SIOCGIFHWADDR                            = 35111
+	SIOCGIFNAME                              = 35088
+	SIOCGSTAMP                               = 35078
+	SIOCGSTAMPNS                             = 35079
+	SIOCINQ                                  = 21531
+	SIOCKCMATTACH                            = 35296
+	SIOCKCMCLONE                             = 35298
+	SIOCKCMUNATTACH                          = 35297
+	SIOCOUTQ                                 = 21521
+	SIOCSIFHWADDR                            = 35108
+	SNDRV_CTL_ELEM_IFACE_CARD                = 0
+	SNDRV_CTL_ELEM_IFACE_HWDEP               = 1
+	SNDRV_CTL_ELEM_IFACE_MIXER               = 2
+	SNDRV_CTL_ELEM_IFACE_PCM                 = 3
+	SNDRV_CTL_ELEM_IFACE_RAWMIDI             = 4
+	SNDRV_CTL_ELEM_IFACE_SEQUENCER           = 6
+	SNDRV_CTL_ELEM_IFACE_TIMER               = 5
+	SNDRV_CTL_IOCTL_CARD_INFO                = 2172146945
+	SNDRV_CTL_IOCTL_ELEM_ADD                 = 3239073047
+	SNDRV_CTL_IOCTL_ELEM_INFO                = 3239073041
+	SNDRV_CTL_IOCTL_ELEM_LIST                = 3226490128
+	SNDRV_CTL_IOCTL_ELEM_LOCK                = 1077957908
+	SNDRV_CTL_IOCTL_ELEM_READ                = 3301463314
+	SNDRV_CTL_IOCTL_ELEM_REMOVE              = 3225441561
+	SNDRV_CTL_IOCTL_ELEM_REPLACE             = 3239073048
+	SNDRV_CTL_IOCTL_ELEM_UNLOCK              = 1077957909
+	SNDRV_CTL_IOCTL_ELEM_WRITE               = 3301463315
+	SNDRV_CTL_IOCTL_HWDEP_INFO               = 2161923361
+	SNDRV_CTL_IOCTL_HWDEP_NEXT_DEVICE        = 3221509408
+	SNDRV_CTL_IOCTL_PCM_INFO                 = 3240121649
+	SNDRV_CTL_IOCTL_PCM_NEXT_DEVICE          = 2147767600
+	SNDRV_CTL_IOCTL_PCM_PREFER_SUBDEVICE     = 1074025778
+	SNDRV_CTL_IOCTL_POWER_STATE              = 2147767761
+	SNDRV_CTL_IOCTL_PVERSION                 = 2147767552
+	SNDRV_CTL_IOCTL_RAWMIDI_INFO             = 3238810945
+	SNDRV_CTL_IOCTL_RAWMIDI_NEXT_DEVICE      = 3221509440
+	SNDRV_CTL_IOCTL_RAWMIDI_PREFER_SUBDEVICE = 1074025794
+	SNDRV_CTL_IOCTL_SUBSCRIBE_EVENTS         = 3221509398
+	SNDRV_CTL_IOCTL_TLV_COMMAND              = 3221771548
+	SNDRV_CTL_IOCTL_TLV_READ                 = 3221771546
+	SNDRV_CTL_IOCTL_TLV_WRITE                = 3221771547
+	SNDRV_SEQ_FILTER_BOUNCE                  = 4
+	SNDRV_SEQ_FILTER_BROADCAST               = 1
+	SNDRV_SEQ_FILTER_MULTICAST               = 2
+	SNDRV_SEQ_FILTER_USE_EVENT               = 18446744071562067968
+	SNDRV_SEQ_IOCTL_CLIENT_ID                = 2147767041
+	SNDRV_SEQ_IOCTL_CREATE_PORT              = 3232256800
+	SNDRV_SEQ_IOCTL_CREATE_QUEUE             = 3230421810
+	SNDRV_SEQ_IOCTL_DELETE_PORT              = 1084773153
+	SNDRV_SEQ_IOCTL_DELETE_QUEUE             = 1082938163
+	SNDRV_SEQ_IOCTL_GET_CLIENT_INFO          = 3233567504
+	SNDRV_SEQ_IOCTL_GET_CLIENT_POOL          = 3227013963
+	SNDRV_SEQ_IOCTL_GET_NAMED_QUEUE          = 3230421814
+	SNDRV_SEQ_IOCTL_GET_PORT_INFO            = 3232256802
+	SNDRV_SEQ_IOCTL_GET_QUEUE_CLIENT         = 3226227529
+	SNDRV_SEQ_IOCTL_GET_QUEUE_INFO           = 3230421812
+	SNDRV_SEQ_IOCTL_GET_QUEUE_STATUS         = 3227276096
+	SNDRV_SEQ_IOCTL_GET_QUEUE_TEMPO          = 3224130369
+	SNDRV_SEQ_IOCTL_GET_QUEUE_TIMER          = 3227538245
+	SNDRV_SEQ_IOCTL_GET_SUBSCRIPTION         = 3226489680
+	SNDRV_SEQ_IOCTL_PVERSION                 = 2147767040
+	SNDRV_SEQ_IOCTL_QUERY_NEXT_CLIENT        = 3233567569
+	SNDRV_SEQ_IOCTL_QUERY_NEXT_PORT          = 3232256850
+	SNDRV_SEQ_IOCTL_QUERY_SUBS               = 3227013967
+	SNDRV_SEQ_IOCTL_REMOVE_EVENTS            = 1077957454
+	SNDRV_SEQ_IOCTL_RUNNING_MODE             = 3222295299
+	SNDRV_SEQ_IOCTL_SET_CLIENT_INFO          = 1086083857
+	SNDRV_SEQ_IOCTL_SET_CLIENT_POOL          = 1079530316
+	SNDRV_SEQ_IOCTL_SET_PORT_INFO            = 1084773155
+	SNDRV_SEQ_IOCTL_SET_QUEUE_CLIENT         = 1078743882
+	SNDRV_SEQ_IOCTL_SET_QUEUE_INFO           = 3230421813
+	SNDRV_SEQ_IOCTL_SET_QUEUE_TEMPO          = 1076646722
+	SNDRV_SEQ_IOCTL_SET_QUEUE_TIMER          = 1080054598
+	SNDRV_SEQ_IOCTL_SUBSCRIBE_PORT           = 1079006000
+	SNDRV_SEQ_IOCTL_SYSTEM_INFO              = 3224392450
+	SNDRV_SEQ_IOCTL_UNSUBSCRIBE_PORT         = 1079006001
+	SNDRV_SEQ_PORT_CAP_DUPLEX                = 16
+	SNDRV_SEQ_PORT_CAP_NO_EXPORT             = 128
+	SNDRV_SEQ_PORT_CAP_READ                  = 1
+	SNDRV_SEQ_PORT_CAP_SUBS_READ             = 32
+	SNDRV_SEQ_PORT_CAP_SUBS_WRITE            = 64
+	SNDRV_SEQ_PORT_CAP_SYNC_READ             = 4
+	SNDRV_SEQ_PORT_CAP_SYNC_WRITE            = 8
+	SNDRV_SEQ_PORT_CAP_WRITE                 = 2
+	SNDRV_SEQ_PORT_FLG_GIVEN_PORT            = 1
+	SNDRV_SEQ_PORT_FLG_TIMESTAMP             = 2
+	SNDRV_SEQ_PORT_FLG_TIME_REAL             = 4
+	SNDRV_SEQ_PORT_SUBS_EXCLUSIVE            = 1
+	SNDRV_SEQ_PORT_SUBS_TIMESTAMP            = 2
+	SNDRV_SEQ_PORT_SUBS_TIME_REAL            = 4
+	SNDRV_SEQ_PORT_TYPE_APPLICATION          = 1048576
+	SNDRV_SEQ_PORT_TYPE_DIRECT_SAMPLE        = 2048
+	SNDRV_SEQ_PORT_TYPE_HARDWARE             = 65536
+	SNDRV_SEQ_PORT_TYPE_MIDI_GENERIC         = 2
+	SNDRV_SEQ_PORT_TYPE_MIDI_GM              = 4
+	SNDRV_SEQ_PORT_TYPE_MIDI_GM2             = 64
+	SNDRV_SEQ_PORT_TYPE_MIDI_GS              = 8
+	SNDRV_SEQ_PORT_TYPE_MIDI_MT32            = 32
+	SNDRV_SEQ_PORT_TYPE_MIDI_XG              = 16
+	SNDRV_SEQ_PORT_TYPE_PORT                 = 524288
+	SNDRV_SEQ_PORT_TYPE_SAMPLE               = 4096
+	SNDRV_SEQ_PORT_TYPE_SOFTWARE             = 131072
+	SNDRV_SEQ_PORT_TYPE_SPECIFIC             = 1
+	SNDRV_SEQ_PORT_TYPE_SYNTH                = 1024
+	SNDRV_SEQ_PORT_TYPE_SYNTHESIZER          = 262144
+	SNDRV_SEQ_QUERY_SUBS_READ                = 0
+	SNDRV_SEQ_QUERY_SUBS_WRITE               = 1
+	SNDRV_SEQ_REMOVE_DEST                    = 4
+	SNDRV_SEQ_REMOVE_DEST_CHANNEL            = 8
+	SNDRV_SEQ_REMOVE_EVENT_TYPE              = 128
+	SNDRV_SEQ_REMOVE_IGNORE_OFF              = 256
+	SNDRV_SEQ_REMOVE_INPUT                   = 1
+	SNDRV_SEQ_REMOVE_OUTPUT                  = 2
+	SNDRV_SEQ_REMOVE_TAG_MATCH               = 512
+	SNDRV_SEQ_REMOVE_TIME_AFTER              = 32
+	SNDRV_SEQ_REMOVE_TIME_BEFORE             = 16
+	SNDRV_SEQ_REMOVE_TIME_TICK               = 64
+	SNDRV_SEQ_TIMER_ALSA                     = 0
+	SNDRV_SEQ_TIMER_MIDI_CLOCK               = 1
+	SNDRV_SEQ_TIMER_MIDI_TICK                = 2
+	SNDRV_TIMER_EVENT_CONTINUE               = 4
+	SNDRV_TIMER_EVENT_EARLY                  = 6
+	SNDRV_TIMER_EVENT_MCONTINUE              = 14
+	SNDRV_TIMER_EVENT_MPAUSE                 = 15
+	SNDRV_TIMER_EVENT_MRESUME                = 18
+	SNDRV_TIMER_EVENT_MSTART                 = 12
+	SNDRV_TIMER_EVENT_MSTOP                  = 13
+	SNDRV_TIMER_EVENT_MSUSPEND               = 17
+	SNDRV_TIMER_EVENT_PAUSE                  = 5
+	SNDRV_TIMER_EVENT_RESOLUTION             = 0
+	SNDRV_TIMER_EVENT_RESUME                 = 8
+	SNDRV_TIMER_EVENT_START                  = 2
+	SNDRV_TIMER_EVENT_STOP                   = 3
+	SNDRV_TIMER_EVENT_SUSPEND                = 7
+	SNDRV_TIMER_EVENT_TICK                   = 1
+	SNDRV_TIMER_IOCTL_CONTINUE               = 21666
+	SNDRV_TIMER_IOCTL_GINFO                  = 3237499907
+	SNDRV_TIMER_IOCTL_GPARAMS                = 1078481924
+	SNDRV_TIMER_IOCTL_GSTATUS                = 3226489861
+	SNDRV_TIMER_IOCTL_INFO                   = 2162709521
+	SNDRV_TIMER_IOCTL_NEXT_DEVICE            = 3222557697
+	SNDRV_TIMER_IOCTL_PARAMS                 = 1079006226
+	SNDRV_TIMER_IOCTL_PAUSE                  = 21667
+	SNDRV_TIMER_IOCTL_PVERSION               = 2147767296
+	SNDRV_TIMER_IOCTL_SELECT                 = 1077171216
+	SNDRV_TIMER_IOCTL_START                  = 21664
+	SNDRV_TIMER_IOCTL_STATUS                 = 2153796628
+	SNDRV_TIMER_IOCTL_STOP                   = 21665
+	SNDRV_TIMER_IOCTL_TREAD                  = 1074025474
+	SNDRV_TIMER_PSFLG_AUTO                   = 1
+	SNDRV_TIMER_PSFLG_EARLY_EVENT            = 4
+	SNDRV_TIMER_PSFLG_EXCLUSIVE              = 2
+	SOCK_CLOEXEC                             = 524288
+	SOCK_DGRAM                               = 2
+	SOCK_NONBLOCK                            = 2048
+	SOCK_PACKET                              = 10
+	SOCK_RAW                                 = 3
+	SOCK_RDM                                 = 4
+	SOCK_SEQPACKET                           = 5
+	SOCK_STREAM                              = 1
+	SOF_TIMESTAMPING_OPT_CMSG                = 1024
+	SOF_TIMESTAMPING_OPT_ID                  = 128
+	SOF_TIMESTAMPING_OPT_TSONLY              = 2048
+	SOF_TIMESTAMPING_RAW_HARDWARE            = 64
+	SOF_TIMESTAMPING_RX_HARDWARE             = 4
+	SOF_TIMESTAMPING_RX_SOFTWARE             = 8
+	SOF_TIMESTAMPING_SOFTWARE                = 16
+	SOF_TIMESTAMPING_SYS_HARDWARE            = 32
+	SOF_TIMESTAMPING_TX_ACK                  = 512
+	SOF_TIMESTAMPING_TX_HARDWARE             = 1
+	SOF_TIMESTAMPING_TX_SCHED                = 256
+	SOF_TIMESTAMPING_TX_SOFTWARE             = 2
+	SOL_ALG                                  = 279
+	SOL_BLUETOOTH                            = 274
+	SOL_KCM                                  = 281
+	SOL_L2CAP                                = 6
+	SOL_NETLINK                              = 270
+	SOL_NETROM                               = 259
+	SOL_NFC                                  = 280
+	SOL_RFCOMM                               = 18
+	SOL_SCO                                  = 17
+	SOL_SCTP                                 = 132
+	SOL_SOCKET                               = 1
+	SO_ACCEPTCONN                            = 30
+	SO_ATTACH_BPF                            = 50
+	SO_ATTACH_FILTER                         = 26
+	SO_BINDTODEVICE                          = 25
+	SO_BROADCAST                             = 6
+	SO_BUSY_POLL                             = 46
+	SO_DEBUG                                 = 1
+	SO_DETACH_FILTER                         = 27
+	SO_DOMAIN                                = 39
+	SO_DONTROUTE                             = 5
+	SO_ERROR                                 = 4
+	SO_GET_FILTER                            = 26
+	SO_KEEPALIVE                             = 9
+	SO_LINGER                                = 13
+	SO_LOCK_FILTER                           = 44
+	SO_MARK                                  = 36
+	SO_MAX_PACING_RATE                       = 47
+	SO_NOFCS                                 = 43
+	SO_NO_CHECK                              = 11
+	SO_OOBINLINE                             = 10
+	SO_PASSCRED                              = 16
+	SO_PASSSEC                               = 34
+	SO_PEEK_OFF                              = 42
+	SO_PEERCRED                              = 17
+	SO_PEERNAME                              = 28
+	SO_PEERSEC                               = 31
+	SO_PRIORITY                              = 12
+	SO_PROTOCOL                              = 38
+	SO_RCVBUF                                = 8
+	SO_RCVBUFFORCE                           = 33
+	SO_RCVLOWAT                              = 18
+	SO_RCVTIMEO                              = 20
+	SO_REUSEADDR                             = 2
+	SO_REUSEPORT                             = 15
+	SO_RXQ_OVFL                              = 40
+	SO_SELECT_ERR_QUEUE                      = 45
+	SO_SNDBUF                                = 7
+	SO_SNDBUFFORCE                           = 32
+	SO_SNDLOWAT                              = 19
+	SO_SNDTIMEO                              = 21
+	SO_TIMESTAMP                             = 29
+	SO_TIMESTAMPING                          = 37
+	SO_TIMESTAMPNS                           = 35
+	SO_TYPE                                  = 3
+	SO_WIFI_STATUS                           = 41
+	SPLICE_F_GIFT                            = 8
+	SPLICE_F_MORE                            = 4
+	SPLICE_F_MOVE                            = 1
+	SPLICE_F_NONBLOCK                        = 2
+	SPP_HB_DEMAND                            = 4
+	SPP_HB_DISABLE                           = 2
+	SPP_HB_ENABLE                            = 1
+	SPP_HB_TIME_IS_ZERO                      = 128
+	SPP_PMTUD_DISABLE                        = 16
+	SPP_PMTUD_ENABLE                         = 8
+	SPP_SACKDELAY_DISABLE                    = 64
+	SPP_SACKDELAY_ENABLE                     = 32
+	STICKY_TIMEOUTS                          = 67108864
+	SYNC_FILE_RANGE_WAIT_AFTER               = 4
+	SYNC_FILE_RANGE_WAIT_BEFORE              = 1
+	SYNC_FILE_RANGE_WRITE                    = 2
+	SYSLOG_ACTION_CLEAR                      = 5
+	SYSLOG_ACTION_CLOSE                      = 0
+	SYSLOG_ACTION_CONSOLE_OFF                = 6
+	SYSLOG_ACTION_CONSOLE_ON                 = 7
+	SYSLOG_ACTION_OPEN                       = 1
+	SYSLOG_ACTION_READ                       = 2
+	SYSLOG_ACTION_READ_ALL                   = 3
+	SYSLOG_ACTION_READ_CLEAR                 = 4
+	SYSLOG_ACTION_SIZE_BUFFER                = 10
+	SYSLOG_ACTION_SIZE_UNREAD                = 9
+	S_IFBLK                                  = 24576
+	S_IFCHR                                  = 8192
+	S_IFDIR                                  = 16384
+	S_IFIFO                                  = 4096
+	S_IFLNK                                  = 40960
+	S_IFREG                                  = 32768
+	S_IFSOCK                                 = 49152
+	S_IRGRP                                  = 32
+	S_IROTH                                  = 4
+	S_IRUSR                                  = 256
+	S_IWGRP                                  = 16
+	S_IWOTH                                  = 2
+	S_IWUSR                                  = 128
+	S_IXGRP                                  = 8
+	S_IXOTH                                  = 1
+	S_IXUSR                                  = 64
+	TCFLSH                                   = 21515
+	TCGETA                                   = 21509
+	TCGETS                                   = 21505
+	TCP_CORK                                 = 3
+	TCP_DEFER_ACCEPT                         = 9
+	TCP_INFO                                 = 11
+	TCP_KEEPCNT                              = 6
+	TCP_KEEPIDLE                             = 4
+	TCP_KEEPINTVL                            = 5
+	TCP_LINGER2                              = 8
+	TCP_MAXSEG                               = 2
+	TCP_NODELAY                              = 1
+	TCP_QUICKACK                             = 12
+	TCP_SYNCNT                               = 7
+	TCP_WINDOW_CLAMP                         = 10
+	TCSBRK                                   = 21513
+	TCSBRKP                                  = 21541
+	TCSETA                                   = 21510
+	TCSETAF                                  = 21512
+	TCSETAW                                  = 21511
+	TCSETS                                   = 21506
+	TCSETSF                                  = 21508
+	TCSETSW                                  = 21507
+	TCXONC                                   = 21514
+	TFD_CLOEXEC                              = 524288
+	TFD_NONBLOCK                             = 2048
+	TFD_TIMER_ABSTIME                        = 1
+	TIMER_ABSTIME                            = 1
+	TIOCCBRK                                 = 21544
+	TIOCCONS                                 = 21533
+	TIOCEXCL                                 = 21516
+	TIOCGDEV                                 = 2147767346
+	TIOCGETD                                 = 21540
+	TIOCGEXCL                                = 2147767360
+	TIOCGLCKTRMIOS                           = 21590
+	TIOCGPGRP                                = 21519
+	TIOCGPKT                                 = 2147767352
+	TIOCGPTLCK                               = 2147767353
+	TIOCGPTN                                 = 2147767344
+	TIOCGSID                                 = 21545
+	TIOCGSOFTCAR                             = 21529
+	TIOCGWINSZ                               = 21523
+	TIOCINQ                                  = 21531
+	TIOCLINUX                                = 21532
+	TIOCMBIC                                 = 21527
+	TIOCMBIS                                 = 21526
+	TIOCMGET                                 = 21525
+	TIOCMSET                                 = 21528
+	TIOCNOTTY                                = 21538
+	TIOCNXCL                                 = 21517
+	TIOCOUTQ                                 = 21521
+	TIOCPKT                                  = 21536
+	TIOCSBRK                                 = 21543
+	TIOCSCTTY                                = 21518
+	TIOCSETD                                 = 21539
+	TIOCSIG                                  = 1074025526
+	TIOCSLCKTRMIOS                           = 21591
+	TIOCSPGRP                                = 21520
+	TIOCSPTLCK                               = 1074025521
+	TIOCSSOFTCAR                             = 21530
+	TIOCSTI                                  = 21522
+	TIOCSWINSZ                               = 21524
+	TIOCTTYGSTRUCT                           = 21542
+	TIOCVHANGUP                              = 21559
+	TUNATTACHFILTER                          = 1074812117
+	TUNDETACHFILTER                          = 1074812118
+	TUNGETFEATURES                           = 2147767503
+	TUNGETFILTER                             = 2148553947
+	TUNGETIFF                                = 2147767506
+	TUNGETSNDBUF                             = 2147767507
+	TUNGETVNETHDRSZ                          = 2147767511
+	TUNSETIFF                                = 1074025674
+	TUNSETIFINDEX                            = 1074025690
+	TUNSETLINK                               = 1074025677
+	TUNSETNOCSUM                             = 1074025672
+	TUNSETOFFLOAD                            = 1074025680
+	TUNSETOWNER                              = 1074025676
+	TUNSETPERSIST                            = 1074025675
+	TUNSETQUEUE                              = 1074025689
+	TUNSETSNDBUF                             = 1074025684
+	TUNSETTXFILTER                           = 1074025681
+	TUNSETVNETHDRSZ                          = 1074025688
+	UDP_CORK                                 = 1
+	UFFDIO_API                               = 3222841919
+	UFFDIO_COPY_MODE_DONTWAKE                = 1
+	UFFDIO_REGISTER                          = 3223366144
+	UFFDIO_REGISTER_MODE_MISSING             = 1
+	UFFDIO_REGISTER_MODE_WP                  = 2
+	UFFDIO_UNREGISTER                        = 2148575745
+	UFFDIO_WAKE                              = 2148575746
+	UFFDIO_ZEROPAGE_MODE_DONTWAKE            = 1
+	UMOUNT_NOFOLLOW                          = 8
+	USB_CLASS_APP_SPEC                       = 254
+	USB_CLASS_AUDIO                          = 1
+	USB_CLASS_CDC_DATA                       = 10
+	USB_CLASS_COMM                           = 2
+	USB_CLASS_HID                            = 3
+	USB_CLASS_HUB                            = 9
+	USB_CLASS_MASS_STORAGE                   = 8
+	USB_CLASS_PER_INTERFACE                  = 0
+	USB_CLASS_PRINTER                        = 7
+	USB_CLASS_VENDOR_SPEC                    = 255
+	USB_CLASS_VIDEO                          = 14
+	USB_CLASS_WIRELESS_CONTROLLER            = 224
+	USB_CONFIG_ATT_ONE                       = 128
+	USB_CONFIG_ATT_SELFPOWER                 = 64
+	USB_CONFIG_ATT_WAKEUP                    = 32
+	USB_DT_CONFIG                            = 2
+	USB_DT_CONFIG_SIZE                       = 9
+	USB_DT_DEVICE                            = 1
+	USB_DT_DEVICE_SIZE                       = 18
+	USB_DT_ENDPOINT                          = 5
+	USB_DT_ENDPOINT_SIZE                     = 7
+	USB_DT_INTERFACE                         = 4
+	USB_DT_INTERFACE_SIZE                    = 9
+	USB_DT_STRING                            = 3
+	USB_ENDPOINT_XFER_BULK                   = 2
+	USB_ENDPOINT_XFER_CONTROL                = 0
+	USB_ENDPOINT_XFER_INT                    = 3
+	USB_ENDPOINT_XFER_ISOC                   = 1
+	USER_CLIENT                              = 1
+	VIRTIO_NET_HDR_F_DATA_VALID              = 2
+	VIRTIO_NET_HDR_F_NEEDS_CSUM              = 1
+	VIRTIO_NET_HDR_GSO_ECN                   = 128
+	VIRTIO_NET_HDR_GSO_NONE                  = 0
+	VIRTIO_NET_HDR_GSO_TCPV4                 = 1
+	VIRTIO_NET_HDR_GSO_TCPV6                 = 4
+	VIRTIO_NET_HDR_GSO_UDP                   = 3
+	VT_ACTIVATE                              = 22022
+	VT_DISALLOCATE                           = 22024
+	VT_GETMODE                               = 22017
+	VT_GETSTATE                              = 22019
+	VT_OPENQRY                               = 22016
+	VT_RELDISP                               = 22021
+	VT_RESIZE                                = 22025
+	VT_RESIZEX                               = 22026
+	VT_SETMODE                               = 22018
+	VT_WAITACTIVE                            = 22023
+	WCONTINUED                               = 8
+	WEXITED                                  = 4
+	WHOLE_SECONDS                            = 33554432
+	WNOHANG                                  = 1
+	WNOWAIT                                  = 16777216
+	WSTOPPED                                 = 2
+	WUNTRACED                                = 2
+	XATTR_CREATE                             = 1
+	XATTR_REPLACE                            = 2
+	_DRM_AGP                                 = 3
+	_DRM_AGP_BUFFER                          = 2
+	_DRM_CONSISTENT                          = 5
+	_DRM_CONTAINS_LOCK                       = 32
+	_DRM_CONTEXT_2DONLY                      = 2
+	_DRM_CONTEXT_PRESERVED                   = 1
+	_DRM_DMA_BLOCK                           = 1
+	_DRM_DMA_LARGER_OK                       = 64
+	_DRM_DMA_PRIORITY                        = 4
+	_DRM_DMA_SMALLER_OK                      = 32
+	_DRM_DMA_WAIT                            = 16
+	_DRM_DMA_WHILE_LOCKED                    = 2
+	_DRM_DRIVER                              = 128
+	_DRM_FB_BUFFER                           = 8
+	_DRM_FRAME_BUFFER                        = 0
+	_DRM_HALT_ALL_QUEUES                     = 16
+	_DRM_HALT_CUR_QUEUES                     = 32
+	_DRM_KERNEL                              = 8
+	_DRM_LOCKED                              = 4
+	_DRM_LOCK_FLUSH                          = 4
+	_DRM_LOCK_FLUSH_ALL                      = 8
+	_DRM_LOCK_QUIESCENT                      = 2
+	_DRM_LOCK_READY                          = 1
+	_DRM_PAGE_ALIGN                          = 1
+	_DRM_PCI_BUFFER_RO                       = 16
+	_DRM_READ_ONLY                           = 2
+	_DRM_REGISTERS                           = 1
+	_DRM_REMOVABLE                           = 64
+	_DRM_RESTRICTED                          = 1
+	_DRM_SCATTER_GATHER                      = 4
+	_DRM_SG_BUFFER                           = 4
+	_DRM_SHM                                 = 2
+	_DRM_VBLANK_ABSOLUTE                     = 0
+	_DRM_VBLANK_EVENT                        = 67108864
+	_DRM_VBLANK_FLIP                         = 134217728
+	_DRM_VBLANK_HIGH_CRTC_MASK               = 62
+	_DRM_VBLANK_NEXTONMISS                   = 268435456
+	_DRM_VBLANK_RELATIVE                     = 1
+	_DRM_VBLANK_SECONDARY                    = 536870912
+	_DRM_VBLANK_SIGNAL                       = 1073741824
+	_DRM_WRITE_COMBINING                     = 16
+	__WALL                                   = 1073741824
+	__WCLONE                                 = 2147483648
+	__WNOTHREAD                              = 536870912
+)
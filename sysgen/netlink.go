@@ -0,0 +1,80 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Netlink types are expanded during parsing into auto-generated structs:
+//	nlattr[ATTR, TYPE]: netlink attribute (struct nlattr) of type ATTR with TYPE payload
+//	nlmsg[MSG, HDR, POLICY]: netlink message (struct nlmsghdr) of type MSG with HDR family header
+//		followed by an array of POLICY attributes
+// Attribute policy is a varlen union of nlattr's that can appear in a message (or nested attribute).
+// Both structs are 4-byte aligned as required by NLMSG_ALIGN/NLA_ALIGN.
+
+var (
+	netlinkStructs = make(map[string]Struct)
+	netlinkTypes   = make(map[string]string)
+	netlinkSeq     int
+)
+
+func parseNetlinkType(typ []string, unnamed map[string][]string, flags map[string][]string) []string {
+	var prefix string
+	var flds [][]string
+	switch typ[0] {
+	case "nlattr":
+		if len(typ) != 3 {
+			failf("wrong number of arguments for nlattr, want 2, got %v", len(typ)-1)
+		}
+		prefix = "nlattr_" + typ[1]
+		flds = [][]string{
+			{"nla_len", "len", "parent", "int16"},
+			{"nla_type", "const", typ[1], "int16"},
+			append([]string{"payload"}, expandUnnamed(typ[2], unnamed)...),
+		}
+	case "nlmsg":
+		if len(typ) != 4 {
+			failf("wrong number of arguments for nlmsg, want 3, got %v", len(typ)-1)
+		}
+		prefix = "nlmsg_" + typ[1]
+		flds = [][]string{
+			{"len", "len", "parent", "int32"},
+			{"type", "const", typ[1], "int16"},
+			{"flags", "flags", "netlink_msg_flags", "int16"},
+			{"seq", "int32"},
+			{"pid", "int32"},
+			append([]string{"hdr"}, expandUnnamed(typ[2], unnamed)...),
+			{"attrs", "array", typ[3]},
+		}
+	default:
+		return typ
+	}
+	key := strings.Join(typ, ",")
+	if name := netlinkTypes[key]; name != "" {
+		return []string{name}
+	}
+	name := prefix
+	if _, ok := netlinkStructs[name]; ok {
+		name = fmt.Sprintf("%v_%v", prefix, netlinkSeq)
+		netlinkSeq++
+	}
+	// Create a fake flag with the const value, see parseType1.
+	flags[fmt.Sprintf("const_flag_%v", constSeq)] = typ[1:2]
+	constSeq++
+	logf(2, "  Add %v struct %v", typ[0], name)
+	netlinkStructs[name] = Struct{Name: name, Flds: flds, Align: 4}
+	netlinkTypes[key] = name
+	return []string{name}
+}
+
+// expandUnnamed returns the full type for an unnamed type reference,
+// so that generated struct fields keep their names.
+func expandUnnamed(typ string, unnamed map[string][]string) []string {
+	if inner, ok := unnamed[typ]; ok {
+		return inner
+	}
+	return []string{typ}
+}
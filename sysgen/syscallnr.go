@@ -16,21 +16,33 @@ type Arch struct {
 	KernelHeaderArch string
 	KernelInclude    string
 	CFlags           []string
+	PtrSize          uint64
 	Numbers          []int
+	Consts           []ConstData
 }
 
+// Syscall numbers and values of all constants used in descriptions are fetched
+// from kernel headers for each of these archs and generated into sys/sys_GOARCH.go.
+// To add a new arch, add an entry here and run make generate.
 var archs = []*Arch{
-	{"amd64", []string{"__x86_64__"}, "x86", "asm/unistd.h", []string{"-m64"}, nil},
-	{"386", []string{"__i386__"}, "x86", "asm/unistd.h", []string{"-D__SYSCALL_COMPAT", "-DCONFIG_COMPAT", "-DCONFIG_X86_32"}, nil},
-	{"arm64", []string{"__aarch64__"}, "arm64", "asm/unistd.h", []string{}, nil},
-	{"ppc64le", []string{"__ppc64__", "__PPC64__", "__powerpc64__"}, "powerpc", "asm/unistd.h", []string{}, nil},
+	{"amd64", []string{"__x86_64__"}, "x86", "asm/unistd.h", []string{"-m64"}, 8, nil, nil},
+	{"386", []string{"__i386__"}, "x86", "asm/unistd.h", []string{"-D__SYSCALL_COMPAT", "-DCONFIG_COMPAT", "-DCONFIG_X86_32"}, 4, nil, nil},
+	{"arm64", []string{"__aarch64__"}, "arm64", "asm/unistd.h", []string{}, 8, nil, nil},
+	{"ppc64le", []string{"__ppc64__", "__PPC64__", "__powerpc64__"}, "powerpc", "asm/unistd.h", []string{}, 8, nil, nil},
 }
 
 var syzkalls = map[string]int{
-	"syz_open_dev":      1000001,
-	"syz_open_pts":      1000002,
-	"syz_fuse_mount":    1000003,
-	"syz_fuseblk_mount": 1000004,
+	"syz_open_dev":        1000001,
+	"syz_open_pts":        1000002,
+	"syz_fuse_mount":      1000003,
+	"syz_fuseblk_mount":   1000004,
+	"syz_unix_relay":      1000005,
+	"syz_mount_image":     1000006,
+	"syz_kvm_setup_cpu":   1000007,
+	"syz_usb_connect":     1000008,
+	"syz_usb_control_io":  1000009,
+	"syz_emit_ethernet":   1000010,
+	"syz_extract_tcp_res": 1000011,
 }
 
 func generateSyscallsNumbers(syscalls []Syscall) {
@@ -64,7 +76,7 @@ func fetchSyscallsNumbers(arch *Arch, syscalls []Syscall) {
 
 func generateSyscallsNumbersArch(arch *Arch, syscalls []Syscall) {
 	var archcode string = "sys/sys_" + arch.GOARCH + ".go"
-	logf(1, "Generate code with syscall numbers for arch=%v in %v", arch.GOARCH, archcode)
+	logf(1, "Generate code with syscall numbers and constants for arch=%v in %v", arch.GOARCH, archcode)
 	buf := new(bytes.Buffer)
 	if err := archTempl.Execute(buf, arch); err != nil {
 		failf("failed to execute arch template: %v", err)
@@ -110,6 +122,11 @@ type SyscallData struct {
 	NR   int
 }
 
+type ConstData struct {
+	Name string
+	Val  string
+}
+
 type SyscallArray []SyscallData
 
 func (a SyscallArray) Len() int           { return len(a) }
@@ -125,6 +142,11 @@ package sys
 
 // Maps internal syscall ID onto kernel syscall number.
 var numbers = []int{ {{range $nr := $.Numbers}}{{$nr}}, {{end}} }
+
+// Values of named constants used in descriptions.
+const (
+{{range $c := $.Consts}}	{{$c.Name}} = {{$c.Val}}
+{{end}})
 `))
 
 var syscallsTempl = template.Must(template.New("").Parse(
@@ -52,7 +52,7 @@ func main() {
 	}
 
 	logf(1, "Parse system call descriptions")
-	includes, defines, syscalls, structs, unnamed, flags := parse(r)
+	includes, defines, syscalls, structs, unnamed, flags, seeds := parse(r)
 	logf(1, "Build flag definitions")
 	intFlags, flagVals := compileFlags(includes, defines, flags)
 
@@ -62,6 +62,12 @@ func main() {
 	generate(syscalls, structs, unnamed, intFlags, flagVals, out)
 	writeSource(initcode, out.Bytes())
 
+	var seedcode string = "sys/seeds.go"
+	logf(1, "Generate code for seed programs in %v", seedcode)
+	out = new(bytes.Buffer)
+	generateSeeds(seeds, out)
+	writeSource(seedcode, out.Bytes())
+
 	var constcode string = "prog/consts.go"
 	logf(1, "Generate code for constant values in %v", constcode)
 	out = new(bytes.Buffer)
@@ -78,9 +84,15 @@ type Syscall struct {
 	Ret      []string
 }
 
+type Seed struct {
+	Name string
+	Prog []string
+}
+
 type Struct struct {
 	Name    string
 	Flds    [][]string
+	Tags    []string // tag values of options of tagged unions
 	IsUnion bool
 	Packed  bool
 	Varlen  bool
@@ -113,6 +125,16 @@ func generate(syscalls []Syscall, structs map[string]Struct, unnamed map[string]
 	fmt.Fprintf(out, "}\n")
 }
 
+func generateSeeds(seeds []Seed, out io.Writer) {
+	fmt.Fprintf(out, "// AUTOGENERATED FILE\n")
+	fmt.Fprintf(out, "package sys\n\n")
+	fmt.Fprintf(out, "var Seeds = []Seed{\n")
+	for _, seed := range seeds {
+		fmt.Fprintf(out, "{Name: %q, Prog: %q},\n", seed.Name, strings.Join(seed.Prog, "\n")+"\n")
+	}
+	fmt.Fprintf(out, "}\n")
+}
+
 func generateArg(name, typ string, a []string, structs map[string]Struct, unnamed map[string][]string, flags map[string][]string, flagVals map[string]string, isField bool, out io.Writer) {
 	name = "\"" + name + "\""
 	opt := false
@@ -181,6 +203,11 @@ func generateArg(name, typ string, a []string, structs map[string]Struct, unname
 			failf("wrong number of arguments for %v arg %v want %v, got %v", typ, name, want, len(a))
 		}
 		fmt.Fprintf(out, "ResourceType{%v, Kind: ResDrmCtx}", common())
+	case "tcp_seq_num":
+		if want := 0; len(a) != want {
+			failf("wrong number of arguments for %v arg %v want %v, got %v", typ, name, want, len(a))
+		}
+		fmt.Fprintf(out, "ResourceType{%v, Kind: ResTcpSeq}", common())
 	case "fileoff":
 		var size uint64
 		if isField {
@@ -230,12 +257,26 @@ func generateArg(name, typ string, a []string, structs map[string]Struct, unname
 			failf("wrong number of arguments for %v arg %v, want %v, got %v", typ, name, want, len(a))
 		}
 		fmt.Fprintf(out, "BufferType{%v, Kind: BufferAlgName}", common())
+	case "pseudofs_value":
+		if want := 0; len(a) != want {
+			failf("wrong number of arguments for %v arg %v, want %v, got %v", typ, name, want, len(a))
+		}
+		commonHdr := common()
+		opt = false
+		fmt.Fprintf(out, "PtrType{%v, Dir: %v, Type: BufferType{%v, Kind: BufferPseudofsValue}}", commonHdr, fmtDir("in"), common())
+	case "fsimage":
+		if want := 1; len(a) != want {
+			failf("wrong number of arguments for %v arg %v, want %v, got %v", typ, name, want, len(a))
+		}
+		commonHdr := common()
+		opt = false
+		fmt.Fprintf(out, "PtrType{%v, Dir: %v, Type: BufferType{%v, Kind: BufferFsImage, Fs: \"%v\"}}", commonHdr, fmtDir("in"), common(), a[0])
 	case "vma":
 		if want := 0; len(a) != want {
 			failf("wrong number of arguments for %v arg %v, want %v, got %v", typ, name, want, len(a))
 		}
 		fmt.Fprintf(out, "VmaType{%v}", common())
-	case "len", "bytesize":
+	case "len", "bytesize", "bytesize2", "bytesize4", "bytesize8", "tag":
 		var size uint64
 		if isField {
 			if want := 2; len(a) != want {
@@ -247,7 +288,14 @@ func generateArg(name, typ string, a []string, structs map[string]Struct, unname
 				failf("wrong number of arguments for %v arg %v, want %v, got %v", typ, name, want, len(a))
 			}
 		}
-		fmt.Fprintf(out, "LenType{%v, Buf: \"%v\", TypeSize: %v, ByteSize: %v}", common(), a[0], size, typ == "bytesize")
+		unit := uint64(0)
+		if strings.HasPrefix(typ, "bytesize") {
+			unit = 1
+			if typ != "bytesize" {
+				unit, _ = strconv.ParseUint(typ[len("bytesize"):], 10, 64)
+			}
+		}
+		fmt.Fprintf(out, "LenType{%v, Buf: \"%v\", TypeSize: %v, ByteSize: %v, Unit: %v, Tag: %v}", common(), a[0], size, unit != 0, unit, typ == "tag")
 	case "flags":
 		var size uint64
 		if isField {
@@ -308,12 +356,21 @@ func generateArg(name, typ string, a []string, structs map[string]Struct, unname
 		}
 		fmt.Fprintf(out, "IntType{%v, TypeSize: 2, Kind: IntInport}", common())
 	case "filename":
-		if want := 0; len(a) != want {
-			failf("wrong number of arguments for %v arg %v, want %v, got %v", typ, name, want, len(a))
+		if len(a) > 1 {
+			failf("wrong number of arguments for %v arg %v, want 0 or 1, got %v", typ, name, len(a))
+		}
+		kind := "FilenamePlain"
+		if len(a) == 1 {
+			switch a[0] {
+			case "pseudofs":
+				kind = "FilenamePseudofs"
+			default:
+				failf("bad filename kind %v for arg %v", a[0], name)
+			}
 		}
 		commonHdr := common()
 		opt = false
-		fmt.Fprintf(out, "PtrType{%v, Dir: DirIn, Type: FilenameType{%v}}", commonHdr, common())
+		fmt.Fprintf(out, "PtrType{%v, Dir: DirIn, Type: FilenameType{%v, Kind: %v}}", commonHdr, common(), kind)
 	case "array":
 		want := 1
 		if len(a) == 2 {
@@ -362,12 +419,36 @@ func generateArg(name, typ string, a []string, structs map[string]Struct, unname
 			if str.Align != 0 {
 				align = fmt.Sprintf(", align: %v", str.Align)
 			}
-			fmt.Fprintf(out, "%v{TypeCommon: TypeCommon{TypeName: \"%v\", IsOptional: %v} %v %v %v, %v: []Type{", typ, str.Name, false, packed, align, varlen, fields)
+			tags := ""
+			if str.Tags != nil {
+				var vals []string
+				for _, tag := range str.Tags {
+					val := flagVals[tag]
+					if val == "" {
+						val = tag
+					}
+					vals = append(vals, val)
+				}
+				tags = fmt.Sprintf(", Tags: []uintptr{%v}", strings.Join(vals, ", "))
+			}
+			fmt.Fprintf(out, "%v{TypeCommon: TypeCommon{TypeName: \"%v\", IsOptional: %v} %v %v %v %v, %v: []Type{", typ, str.Name, false, packed, align, varlen, tags, fields)
+			// Embed struct and union args are identified by type name rather than field name,
+			// so fix up len/tag references to such fields.
+			fieldTypes := make(map[string]string)
+			for _, a := range str.Flds {
+				if _, ok := structs[a[1]]; ok {
+					fieldTypes[a[0]] = a[1]
+				}
+			}
 			for i, a := range str.Flds {
 				if i != 0 {
 					fmt.Fprintf(out, ", ")
 				}
-				generateArg(a[0], a[1], a[2:], structs, unnamed, flags, flagVals, true, out)
+				args := a[2:]
+				if (a[1] == "len" || strings.HasPrefix(a[1], "bytesize") || a[1] == "tag") && len(args) != 0 && fieldTypes[args[0]] != "" {
+					args = append([]string{fieldTypes[args[0]]}, args[1:]...)
+				}
+				generateArg(a[0], a[1], args, structs, unnamed, flags, flagVals, true, out)
 			}
 			fmt.Fprintf(out, "}}")
 			return
@@ -474,6 +555,10 @@ func fmtFdKind(s string) string {
 		return "FdKcm"
 	case "netrom":
 		return "FdNetRom"
+	case "pseudofs":
+		return "FdPseudofs"
+	case "usb":
+		return "FdUsb"
 	default:
 		failf("bad fd type %v", s)
 		return ""
@@ -537,15 +622,19 @@ type SortedSyscall struct {
 	nr   int
 }
 
-func generateConsts(flags map[string]string, out io.Writer) {
+// generateConsts generates prog aliases for the named constants defined per-arch in package sys.
+func generateConsts(consts map[string]string, out io.Writer) {
 	var ff []F
-	for k, v := range flags {
-		ff = append(ff, F{k, v})
+	for k := range consts {
+		if isIdentifier(k) && k[0] >= 'A' && k[0] <= 'Z' {
+			ff = append(ff, F{k, "sys." + k})
+		}
 	}
 	sort.Sort(FlagArray(ff))
 
 	fmt.Fprintf(out, "// AUTOGENERATED FILE\n")
 	fmt.Fprintf(out, "package prog\n\n")
+	fmt.Fprintf(out, "import \"github.com/google/syzkaller/sys\"\n\n")
 	fmt.Fprintf(out, "const (\n")
 	for _, f := range ff {
 		fmt.Fprintf(out, "	%v = %v\n", f.name, f.val)
@@ -554,43 +643,69 @@ func generateConsts(flags map[string]string, out io.Writer) {
 	fmt.Fprintf(out, "\n")
 }
 
+// compileFlags fetches values of all constants used in descriptions for every arch
+// (stored in Arch.Consts and generated into sys/sys_ARCH.go) and returns flag values
+// and constants as Go expressions for the arch-independent sys/sys.go:
+// named constants are referenced by name, other expressions (e.g. numeric literals)
+// are replaced with their values, which must be the same on all archs.
 func compileFlags(includes []string, defines map[string]string, flags map[string][]string) (map[string][]string, map[string]string) {
-	vals := make(map[string]string)
+	vals := make(map[string]bool)
 	for _, fvals := range flags {
 		for _, v := range fvals {
-			vals[v] = ""
+			vals[v] = true
 		}
 	}
 	for k := range defines {
-		vals[k] = ""
+		vals[k] = true
 	}
 	valArray := make([]string, 0, len(vals))
 	for k := range vals {
 		valArray = append(valArray, k)
 	}
-	// TODO: should use target arch
-	flagVals := fetchValues("x86", valArray, includes, defines, []string{})
-	for i, f := range valArray {
-		vals[f] = flagVals[i]
+	sort.Strings(valArray)
+	archVals := make([][]string, len(archs))
+	for i, arch := range archs {
+		archVals[i] = fetchValues(arch.KernelHeaderArch, valArray, includes, defines, arch.CFlags)
+		if arch.PtrSize < 8 {
+			// Values are fetched by a host program, truncate them to the arch long size.
+			for j, v := range archVals[i] {
+				n, _ := strconv.ParseUint(v, 10, 64)
+				archVals[i][j] = strconv.FormatUint(n&(1<<(arch.PtrSize*8)-1), 10)
+			}
+		}
+		arch.Consts = nil
+		for j, v := range valArray {
+			if isIdentifier(v) {
+				arch.Consts = append(arch.Consts, ConstData{v, archVals[i][j]})
+			}
+		}
+	}
+	exprs := make(map[string]string)
+	for j, v := range valArray {
+		if isIdentifier(v) {
+			exprs[v] = v
+			continue
+		}
+		for i, arch := range archs {
+			if archVals[i][j] != archVals[0][j] {
+				failf("value of %v differs between %v and %v, give it a name with define",
+					v, archs[0].GOARCH, arch.GOARCH)
+			}
+		}
+		exprs[v] = archVals[0][j]
 	}
 	res := make(map[string][]string)
 	for fname, fvals := range flags {
 		var arr []string
 		for _, v := range fvals {
-			arr = append(arr, vals[v])
+			arr = append(arr, exprs[v])
 		}
 		if res[fname] != nil {
 			failf("flag %v is defined multiple times", fname)
 		}
 		res[fname] = arr
 	}
-	ids := make(map[string]string)
-	for k, v := range vals {
-		if isIdentifier(k) {
-			ids[k] = v
-		}
-	}
-	return res, ids
+	return res, exprs
 }
 
 func isIdentifier(s string) bool {
@@ -603,7 +718,7 @@ func isIdentifier(s string) bool {
 	return true
 }
 
-func parse(in io.Reader) (includes []string, defines map[string]string, syscalls []Syscall, structs map[string]Struct, unnamed map[string][]string, flags map[string][]string) {
+func parse(in io.Reader) (includes []string, defines map[string]string, syscalls []Syscall, structs map[string]Struct, unnamed map[string][]string, flags map[string][]string, seeds []Seed) {
 	p := NewParser(in)
 	defines = make(map[string]string)
 	structs = make(map[string]Struct)
@@ -643,6 +758,9 @@ func parse(in io.Reader) (includes []string, defines map[string]string, syscalls
 						}
 					}
 				}
+				if str.Tags != nil && len(str.Tags) != len(str.Flds) {
+					failf("union %v: all or none of the options must have tags", str.Name)
+				}
 				logf(2, "  Add struct %v", str.Name)
 				structs[str.Name] = *str
 				str = nil
@@ -652,6 +770,13 @@ func parse(in io.Reader) (includes []string, defines map[string]string, syscalls
 				logf(3, "    Add field %v to struct %v", fld, str.Name)
 				fld = append(fld, parseType(p, unnamed, flags)...)
 				str.Flds = append(str.Flds, fld)
+				if !p.EOF() && str.IsUnion {
+					// Tagged union option.
+					tag := p.Ident()
+					flags[fmt.Sprintf("const_flag_%v", constSeq)] = []string{tag}
+					constSeq++
+					str.Tags = append(str.Tags, tag)
+				}
 			}
 		} else {
 			name := p.Ident()
@@ -669,6 +794,24 @@ func parse(in io.Reader) (includes []string, defines map[string]string, syscalls
 				p.Parse('>')
 				logf(2, "  Add #include file %v", string(include))
 				includes = append(includes, string(include))
+			} else if name == "seed" {
+				seed := Seed{Name: p.Ident()}
+				p.Parse('{')
+				for {
+					if !p.Scan() {
+						failf("seed %v is not terminated", seed.Name)
+					}
+					line := strings.TrimSpace(p.Str())
+					if line == "}" {
+						break
+					}
+					if line != "" && line[0] != '#' {
+						seed.Prog = append(seed.Prog, line)
+					}
+				}
+				p.i = len(p.s)
+				logf(2, "  Add seed %v", seed.Name)
+				seeds = append(seeds, seed)
 			} else if name == "define" {
 				key := p.Ident()
 				var val []byte
@@ -732,6 +875,12 @@ func parse(in io.Reader) (includes []string, defines map[string]string, syscalls
 			failf("trailing data (%v)", p.Str())
 		}
 	}
+	for name, str := range netlinkStructs {
+		if _, ok := structs[name]; ok {
+			failf("%v struct is defined multiple times", name)
+		}
+		structs[name] = str
+	}
 	return
 }
 
@@ -776,7 +925,7 @@ func parseType1(p *Parser, unnamed map[string][]string, flags map[string][]strin
 		constSeq++
 		flags[id] = typ[2:3]
 	}
-	return typ
+	return parseNetlinkType(typ, unnamed, flags)
 }
 
 func writeSource(file string, data []byte) {
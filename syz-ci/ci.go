@@ -0,0 +1,326 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-ci does continuous fuzzing: it periodically pulls the configured kernel git tree,
+// and if there are new commits rebuilds the kernel (and optionally the image)
+// and restarts syz-manager on the fresh build. The manager config is generated from
+// a template config with kernel, vmlinux, image and kernel_src params replaced,
+// so every crash log and report carries the commit it was found on.
+// Builds are recorded in workdir/builds.log.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/fileutil"
+)
+
+var (
+	flagConfig = flag.String("config", "", "config file")
+)
+
+type Config struct {
+	Workdir        string
+	Kernel_Repo    string // git repo to pull, e.g. git://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git
+	Kernel_Branch  string // branch to pull (default: master)
+	Kernel_Config  string // kernel .config to build with (updated with make olddefconfig)
+	Compiler       string // C compiler to build the kernel with (default: gcc)
+	Image_Cmd      string // command that creates image (and optionally key) in the current dir, see README
+	Poll_Period    int    // period of pulling the kernel tree in minutes (default: 60)
+	Manager_Config string // syz-manager config template
+	Syzkaller      string // syzkaller checkout with built binaries (default: syzkaller param of manager config)
+}
+
+// Build is a record in workdir/builds.log.
+type Build struct {
+	Time   time.Time
+	Commit string
+	Error  string `json:",omitempty"`
+}
+
+type CI struct {
+	cfg     *Config
+	kernel  string // kernel checkout dir
+	current string // dir with the build the manager is running on
+	manager *exec.Cmd
+	exited  chan error // receives manager exit status
+}
+
+// managerStopTimeout is how long to wait for the manager to stop VMs after SIGINT.
+const managerStopTimeout = 10 * time.Minute
+
+func main() {
+	flag.Parse()
+	cfg := readConfig(*flagConfig)
+	ci := &CI{
+		cfg:     cfg,
+		kernel:  filepath.Join(cfg.Workdir, "kernel"),
+		current: filepath.Join(cfg.Workdir, "current"),
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	lastCommit := ci.currentCommit()
+	for {
+		commit, err := ci.pull()
+		if err != nil {
+			log.Printf("failed to pull kernel: %v", err)
+		} else if commit != lastCommit {
+			log.Printf("building kernel on commit %v", commit)
+			err := ci.build(commit)
+			ci.record(commit, err)
+			if err != nil {
+				log.Printf("failed to build commit %v: %v", commit, err)
+			} else {
+				log.Printf("built commit %v", commit)
+				ci.stopManager()
+				if err := ci.install(); err != nil {
+					log.Fatalf("failed to install build: %v", err)
+				}
+			}
+			// Don't retry a broken commit until the tree moves on.
+			lastCommit = commit
+		}
+		if ci.manager == nil && ci.currentCommit() != "" {
+			if err := ci.startManager(); err != nil {
+				log.Printf("failed to start manager: %v", err)
+			}
+		}
+		select {
+		case <-time.After(time.Duration(cfg.Poll_Period) * time.Minute):
+		case err := <-ci.exited:
+			log.Printf("syz-manager exited: %v", err)
+			ci.manager = nil
+			// Give a broken manager config/build some time before restarting it.
+			time.Sleep(time.Minute)
+		case <-stop:
+			log.Printf("shutting down...")
+			ci.stopManager()
+			return
+		}
+	}
+}
+
+// pull updates the kernel checkout and returns the new HEAD commit.
+func (ci *CI) pull() (string, error) {
+	if _, err := os.Stat(filepath.Join(ci.kernel, ".git")); err != nil {
+		os.RemoveAll(ci.kernel)
+		if _, err := runCmd("", "git", "clone", "--branch", ci.cfg.Kernel_Branch, ci.cfg.Kernel_Repo, ci.kernel); err != nil {
+			return "", err
+		}
+	} else {
+		if _, err := runCmd(ci.kernel, "git", "fetch", ci.cfg.Kernel_Repo, ci.cfg.Kernel_Branch); err != nil {
+			return "", err
+		}
+		if _, err := runCmd(ci.kernel, "git", "checkout", "-f", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+	out, err := runCmd(ci.kernel, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// build builds the kernel and the image into workdir/build.
+func (ci *CI) build(commit string) error {
+	build := filepath.Join(ci.cfg.Workdir, "build")
+	os.RemoveAll(build)
+	if err := os.MkdirAll(build, 0700); err != nil {
+		return fmt.Errorf("failed to create build dir: %v", err)
+	}
+	if err := fileutil.CopyFile(ci.cfg.Kernel_Config, filepath.Join(ci.kernel, ".config"), false); err != nil {
+		return fmt.Errorf("failed to copy kernel config: %v", err)
+	}
+	cc := "CC=" + ci.cfg.Compiler
+	if _, err := runCmd(ci.kernel, "make", cc, "olddefconfig"); err != nil {
+		return err
+	}
+	if _, err := runCmd(ci.kernel, "make", cc, fmt.Sprintf("-j%v", runtime.NumCPU()), "bzImage"); err != nil {
+		return err
+	}
+	// Only x86 is supported for now.
+	files := map[string]string{
+		"vmlinux":               "vmlinux",
+		"arch/x86/boot/bzImage": "bzImage",
+		".config":               "kernel.config",
+	}
+	for src, dst := range files {
+		if err := fileutil.CopyFile(filepath.Join(ci.kernel, src), filepath.Join(build, dst), false); err != nil {
+			return fmt.Errorf("failed to copy %v: %v", src, err)
+		}
+	}
+	if ci.cfg.Image_Cmd != "" {
+		cmd := fileutil.ShellCommand(ci.cfg.Image_Cmd)
+		cmd.Dir = build
+		cmd.Env = append(os.Environ(), "KERNEL="+filepath.Join(build, "bzImage"), "KERNEL_SRC="+ci.kernel)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("image_cmd failed: %v\n%s", err, tail(out))
+		}
+		if _, err := os.Stat(filepath.Join(build, "image")); err != nil {
+			return fmt.Errorf("image_cmd did not create image")
+		}
+	}
+	return ioutil.WriteFile(filepath.Join(build, "commit"), []byte(commit), 0600)
+}
+
+// install replaces the current build with the new one, the manager must be stopped.
+func (ci *CI) install() error {
+	if err := os.RemoveAll(ci.current); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(ci.cfg.Workdir, "build"), ci.current)
+}
+
+func (ci *CI) currentCommit() string {
+	data, err := ioutil.ReadFile(filepath.Join(ci.current, "commit"))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (ci *CI) record(commit string, buildErr error) {
+	b := &Build{Time: time.Now(), Commit: commit}
+	if buildErr != nil {
+		b.Error = buildErr.Error()
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		panic(err)
+	}
+	f, err := os.OpenFile(filepath.Join(ci.cfg.Workdir, "builds.log"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		log.Printf("failed to open builds log: %v", err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// startManager generates manager config for the current build and starts syz-manager.
+func (ci *CI) startManager() error {
+	// The manager takes the commit from kernel_src HEAD, which can be ahead
+	// of the current build if the last build has failed.
+	if _, err := runCmd(ci.kernel, "git", "checkout", "-f", ci.currentCommit()); err != nil {
+		return err
+	}
+	params := map[string]interface{}{
+		"kernel":     filepath.Join(ci.current, "bzImage"),
+		"vmlinux":    filepath.Join(ci.current, "vmlinux"),
+		"kernel_src": ci.kernel,
+	}
+	if _, err := os.Stat(filepath.Join(ci.current, "image")); err == nil {
+		params["image"] = filepath.Join(ci.current, "image")
+	}
+	if _, err := os.Stat(filepath.Join(ci.current, "key")); err == nil {
+		params["sshkey"] = filepath.Join(ci.current, "key")
+	}
+	data, err := config.Override(ci.cfg.Manager_Config, params)
+	if err != nil {
+		return err
+	}
+	cfgFile := filepath.Join(ci.cfg.Workdir, "manager.cfg")
+	if err := ioutil.WriteFile(cfgFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write manager config: %v", err)
+	}
+	mgrCfg, _, _, err := config.Parse(cfgFile)
+	if err != nil {
+		return err
+	}
+	syzkaller := ci.cfg.Syzkaller
+	if syzkaller == "" {
+		syzkaller = mgrCfg.Syzkaller
+	}
+	log.Printf("starting syz-manager on commit %v", ci.currentCommit())
+	cmd := exec.Command(filepath.Join(syzkaller, "bin", "syz-manager"), "-config", cfgFile)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start syz-manager: %v", err)
+	}
+	ci.manager = cmd
+	ci.exited = make(chan error, 1)
+	go func(exited chan error) {
+		exited <- cmd.Wait()
+	}(ci.exited)
+	return nil
+}
+
+func (ci *CI) stopManager() {
+	if ci.manager == nil {
+		return
+	}
+	log.Printf("stopping syz-manager")
+	ci.manager.Process.Signal(os.Interrupt)
+	select {
+	case <-ci.exited:
+	case <-time.After(managerStopTimeout):
+		ci.manager.Process.Kill()
+		<-ci.exited
+	}
+	ci.manager = nil
+}
+
+func runCmd(dir, bin string, args ...string) ([]byte, error) {
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%v %v failed: %v\n%s", bin, strings.Join(args, " "), err, tail(out))
+	}
+	return out, nil
+}
+
+// tail returns the last lines of a command output (build errors are at the end).
+func tail(out []byte) []byte {
+	const max = 16 << 10
+	if len(out) > max {
+		out = out[len(out)-max:]
+	}
+	return out
+}
+
+func readConfig(filename string) *Config {
+	cfg := new(Config)
+	if err := config.LoadFile(filename, cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Workdir == "" {
+		log.Fatalf("config param workdir is empty")
+	}
+	if cfg.Kernel_Repo == "" {
+		log.Fatalf("config param kernel_repo is empty")
+	}
+	if cfg.Kernel_Config == "" {
+		log.Fatalf("config param kernel_config is empty")
+	}
+	if cfg.Manager_Config == "" {
+		log.Fatalf("config param manager_config is empty")
+	}
+	if cfg.Kernel_Branch == "" {
+		cfg.Kernel_Branch = "master"
+	}
+	if cfg.Compiler == "" {
+		cfg.Compiler = "gcc"
+	}
+	if cfg.Poll_Period <= 0 {
+		cfg.Poll_Period = 60
+	}
+	if err := os.MkdirAll(cfg.Workdir, 0700); err != nil {
+		log.Fatalf("failed to create workdir: %v", err)
+	}
+	return cfg
+}
@@ -0,0 +1,155 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-dash is a bug dashboard for several syz-manager instances.
+// Managers upload crashes and reproducers, the dashboard de-duplicates them by title
+// and tracks bug status (open/fixed/invalid) set from the web UI by the users listed in the config
+// (HTTP basic auth, the rest of the web UI is public).
+// Bugs marked as fixed are reopened if they happen again, and bugs that stopped
+// happening while the managers kept running are highlighted.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	. "github.com/google/syzkaller/rpctype"
+	"github.com/google/syzkaller/syz-dash/state"
+)
+
+var (
+	flagConfig = flag.String("config", "", "config file")
+)
+
+type Config struct {
+	Http          string // HTTP address to serve the dashboard on
+	Rpc           string // RPC address to serve managers on
+	Workdir       string
+	Verify_Period int      // bug is considered stopped if it did not happen for that many days (default: 7)
+	Users         []string // "user:password" pairs allowed to change bug status (if empty, status is read-only)
+	Managers      []config.ManagerKey
+}
+
+type Dash struct {
+	mu           sync.Mutex
+	st           *state.State
+	keys         config.ManagerKeys
+	users        map[string]string
+	verifyPeriod time.Duration
+}
+
+func main() {
+	flag.Parse()
+	cfg := readConfig(*flagConfig)
+
+	st, err := state.Make(cfg.Workdir)
+	if err != nil {
+		log.Fatalf("failed to load state: %v", err)
+	}
+	keys, err := config.MakeManagerKeys(cfg.Managers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dash := &Dash{
+		st:           st,
+		keys:         keys,
+		users:        make(map[string]string),
+		verifyPeriod: time.Duration(cfg.Verify_Period) * 24 * time.Hour,
+	}
+	for _, u := range cfg.Users {
+		colon := strings.IndexByte(u, ':')
+		dash.users[u[:colon]] = u[colon+1:]
+	}
+
+	dash.initHttp(cfg.Http)
+
+	ln, err := net.Listen("tcp", cfg.Rpc)
+	if err != nil {
+		log.Fatalf("failed to listen on %v: %v", cfg.Rpc, err)
+	}
+	log.Printf("serving rpc on tcp://%v", ln.Addr())
+	s := rpc.NewServer()
+	s.RegisterName("Dashboard", dash)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("failed to accept an rpc connection: %v", err)
+			continue
+		}
+		go s.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+func (dash *Dash) UploadCrash(a *DashCrashArgs, r *DashCrashRes) error {
+	if err := dash.keys.Auth(a.Name, a.Key); err != nil {
+		return err
+	}
+	dash.mu.Lock()
+	defer dash.mu.Unlock()
+
+	bug, err := dash.st.AddCrash(a.Name, a.Title, a.Kernel, a.Commit, a.Report, a.Log, time.Now())
+	if err != nil {
+		log.Printf("crash from %v failed: %v", a.Name, err)
+		return err
+	}
+	log.Printf("crash from %v: '%v' (%v, %v crashes)", a.Name, a.Title, bug.Status, bug.Count)
+	r.Status = bug.Status
+	return nil
+}
+
+func (dash *Dash) UploadRepro(a *DashReproArgs, r *int) error {
+	if err := dash.keys.Auth(a.Name, a.Key); err != nil {
+		return err
+	}
+	dash.mu.Lock()
+	defer dash.mu.Unlock()
+
+	if err := dash.st.AddRepro(a.Name, a.Title, a.Opts, a.Prog, a.CProg, time.Now()); err != nil {
+		log.Printf("repro from %v failed: %v", a.Name, err)
+		return err
+	}
+	log.Printf("repro from %v: '%v'", a.Name, a.Title)
+	return nil
+}
+
+func (dash *Dash) Poll(a *DashPollArgs, r *int) error {
+	if err := dash.keys.Auth(a.Name, a.Key); err != nil {
+		return err
+	}
+	dash.mu.Lock()
+	defer dash.mu.Unlock()
+
+	return dash.st.Poll(a.Name, time.Now())
+}
+
+func readConfig(filename string) *Config {
+	cfg := new(Config)
+	if err := config.LoadFile(filename, cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Rpc == "" {
+		log.Fatalf("config param rpc is empty")
+	}
+	if cfg.Workdir == "" {
+		log.Fatalf("config param workdir is empty")
+	}
+	if cfg.Verify_Period == 0 {
+		cfg.Verify_Period = 7
+	}
+	if cfg.Verify_Period < 0 {
+		log.Fatalf("config param verify_period is negative")
+	}
+	for _, u := range cfg.Users {
+		if colon := strings.IndexByte(u, ':'); colon <= 0 || colon == len(u)-1 {
+			log.Fatalf("config param users: %q is not user:password", u)
+		}
+	}
+	return cfg
+}
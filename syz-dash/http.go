@@ -0,0 +1,278 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/syz-dash/state"
+)
+
+func (dash *Dash) initHttp(addr string) {
+	if addr == "" {
+		return
+	}
+	http.HandleFunc("/", dash.httpSummary)
+	http.HandleFunc("/bug", dash.httpBug)
+	http.HandleFunc("/bug/file", dash.httpBugFile)
+	http.HandleFunc("/bug/status", dash.httpBugStatus)
+	log.Printf("serving http on http://%v", addr)
+	go http.ListenAndServe(addr, nil)
+}
+
+func (dash *Dash) httpSummary(w http.ResponseWriter, r *http.Request) {
+	dash.mu.Lock()
+	defer dash.mu.Unlock()
+
+	now := time.Now()
+	data := new(UISummaryData)
+	for _, bug := range dash.st.Bugs {
+		data.Bugs = append(data.Bugs, dash.uiBug(bug, now))
+	}
+	sort.Sort(UIBugArray(data.Bugs))
+	for name, t := range dash.st.Managers {
+		data.Managers = append(data.Managers, UIManager{name, now.Sub(t) - now.Sub(t)%time.Second})
+	}
+	sort.Sort(UIManagerArray(data.Managers))
+	if err := summaryTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (dash *Dash) httpBug(w http.ResponseWriter, r *http.Request) {
+	dash.mu.Lock()
+	defer dash.mu.Unlock()
+
+	bug := dash.st.Bugs[r.FormValue("id")]
+	if bug == nil {
+		http.Error(w, fmt.Sprintf("unknown bug %q", r.FormValue("id")), http.StatusNotFound)
+		return
+	}
+	data := &UIBugData{
+		UIBug:    dash.uiBug(bug, time.Now()),
+		Fix:      bug.Fix,
+		Kernel:   bug.Kernel,
+		Commit:   bug.Commit,
+		Managers: strings.Join(bug.Managers, ", "),
+	}
+	if report, err := ioutil.ReadFile(dash.st.File(bug.ID, "report")); err == nil {
+		data.Report = string(report)
+	}
+	if err := bugTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+func (dash *Dash) httpBugFile(w http.ResponseWriter, r *http.Request) {
+	id, name := r.FormValue("id"), r.FormValue("name")
+	switch name {
+	case "report", "log", "repro.prog", "repro.c":
+	default:
+		http.Error(w, fmt.Sprintf("bad file name %q", name), http.StatusBadRequest)
+		return
+	}
+	dash.mu.Lock()
+	bug := dash.st.Bugs[id]
+	dash.mu.Unlock()
+	if bug == nil {
+		http.Error(w, fmt.Sprintf("unknown bug %q", id), http.StatusNotFound)
+		return
+	}
+	data, err := ioutil.ReadFile(dash.st.File(bug.ID, name))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read file: %v", err), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(data)
+}
+
+func (dash *Dash) httpBugStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "status change requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := dash.httpUser(r)
+	if !ok {
+		log.Printf("rejecting status change from %v", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Basic realm="syz-dash"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := r.FormValue("id")
+	dash.mu.Lock()
+	err := dash.st.SetStatus(id, r.FormValue("status"), r.FormValue("fix"), time.Now())
+	dash.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("bug %v: status %v by %v", id, r.FormValue("status"), user)
+	http.Redirect(w, r, "/bug?id="+id, http.StatusSeeOther)
+}
+
+// httpUser checks basic auth credentials of the request against cfg.Users.
+func (dash *Dash) httpUser(r *http.Request) (string, bool) {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+	want, known := dash.users[user]
+	if !known || subtle.ConstantTimeCompare([]byte(pass), []byte(want)) != 1 {
+		return "", false
+	}
+	return user, true
+}
+
+func (dash *Dash) uiBug(bug *state.Bug, now time.Time) UIBug {
+	return UIBug{
+		ID:        bug.ID,
+		Title:     bug.Title,
+		Status:    bug.Status,
+		Regressed: bug.Regressed,
+		Stopped:   bug.Status != state.StatusInvalid && dash.st.Stopped(bug, dash.verifyPeriod, now),
+		Count:     bug.Count,
+		First:     bug.First,
+		Last:      bug.Last,
+		Repro:     bug.Repro,
+		CRepro:    bug.CRepro,
+	}
+}
+
+type UISummaryData struct {
+	Bugs     []UIBug
+	Managers []UIManager
+}
+
+type UIBug struct {
+	ID        string
+	Title     string
+	Status    string
+	Regressed bool
+	Stopped   bool // fix is verified (for fixed bugs) or the bug was probably fixed (for open bugs)
+	Count     int
+	First     time.Time
+	Last      time.Time
+	Repro     bool
+	CRepro    bool
+}
+
+type UIBugData struct {
+	UIBug
+	Fix      string
+	Kernel   string
+	Commit   string
+	Managers string
+	Report   string
+}
+
+type UIManager struct {
+	Name     string
+	LastSeen time.Duration
+}
+
+// UIBugArray sorts bugs by status (open first) and then by last crash time, the most recent first.
+type UIBugArray []UIBug
+
+func (a UIBugArray) Len() int { return len(a) }
+func (a UIBugArray) Less(i, j int) bool {
+	if a[i].Status != a[j].Status {
+		return statusOrder[a[i].Status] < statusOrder[a[j].Status]
+	}
+	return a[i].Last.After(a[j].Last)
+}
+func (a UIBugArray) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+var statusOrder = map[string]int{
+	state.StatusOpen:    0,
+	state.StatusFixed:   1,
+	state.StatusInvalid: 2,
+}
+
+type UIManagerArray []UIManager
+
+func (a UIManagerArray) Len() int           { return len(a) }
+func (a UIManagerArray) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a UIManagerArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+var summaryTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syz-dash</title>
+</head>
+<body>
+<table>
+	<tr>
+		<th>Title</th>
+		<th>Status</th>
+		<th>Count</th>
+		<th>First</th>
+		<th>Last</th>
+		<th>Repro</th>
+	</tr>
+	{{range $b := $.Bugs}}
+	<tr>
+		<td><a href='/bug?id={{$b.ID}}'>{{$b.Title}}</a></td>
+		<td>{{$b.Status}}{{if $b.Regressed}} (regressed){{end}}{{if $b.Stopped}}{{if eq $b.Status "fixed"}} (verified){{else}} (stopped happening){{end}}{{end}}</td>
+		<td>{{$b.Count}}</td>
+		<td>{{$b.First.Format "2006/01/02 15:04"}}</td>
+		<td>{{$b.Last.Format "2006/01/02 15:04"}}</td>
+		<td>{{if $b.CRepro}}C{{else if $b.Repro}}syz{{end}}</td>
+	</tr>
+	{{end}}
+</table>
+<br>
+<table>
+	<tr>
+		<th>Manager</th>
+		<th>Last seen</th>
+	</tr>
+	{{range $m := $.Managers}}
+	<tr>
+		<td>{{$m.Name}}</td>
+		<td>{{$m.LastSeen}} ago</td>
+	</tr>
+	{{end}}
+</table>
+</body></html>
+`))
+
+var bugTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>{{.Title}}</title>
+</head>
+<body>
+<b>{{.Title}}</b> <br>
+Status: {{.Status}}{{if .Fix}} ({{.Fix}}){{end}}{{if .Regressed}}, regressed{{end}}{{if .Stopped}}, did not happen recently{{end}} <br>
+Crashes: {{.Count}}, first: {{.First.Format "2006/01/02 15:04"}}, last: {{.Last.Format "2006/01/02 15:04"}} <br>
+Managers: {{.Managers}} <br>
+Kernel: {{.Kernel}}{{if .Commit}} ({{.Commit}}){{end}} <br>
+<a href='/bug/file?id={{.ID}}&name=log'>Console log</a> <br>
+{{if .Repro}}<a href='/bug/file?id={{.ID}}&name=repro.prog'>Reproducer</a> <br>{{end}}
+{{if .CRepro}}<a href='/bug/file?id={{.ID}}&name=repro.c'>C reproducer</a> <br>{{end}}
+<br>
+<form action='/bug/status' method='post'>
+	<input type='hidden' name='id' value='{{.ID}}'>
+	<select name='status'>
+		<option value='open'>open</option>
+		<option value='fixed'>fixed</option>
+		<option value='invalid'>invalid</option>
+	</select>
+	Fix: <input type='text' name='fix'>
+	<input type='submit' value='Update'>
+</form>
+<pre>{{.Report}}</pre>
+</body></html>
+`))
@@ -0,0 +1,261 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package state implements persistent state of syz-dash:
+// de-duplicated bugs reported by managers, their status and artifacts.
+package state
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/db"
+)
+
+// Bug statuses.
+const (
+	StatusOpen    = "open"
+	StatusFixed   = "fixed"
+	StatusInvalid = "invalid"
+)
+
+// Bug is a set of crashes with the same title.
+type Bug struct {
+	ID        string
+	Title     string
+	Status    string
+	Fix       string    // free-form fix description (e.g. commit) for fixed bugs
+	FixTime   time.Time // when the bug was marked as fixed
+	Regressed bool      // the bug crashed again after it was marked as fixed
+	First     time.Time
+	Last      time.Time
+	Count     int
+	Managers  []string // managers that hit the bug
+	Kernel    string   // kernel of the last crash
+	Commit    string   // kernel commit of the last crash
+	Repro     bool     // repro.prog exists
+	CRepro    bool     // repro.c exists
+}
+
+// State describes the dir layout:
+//
+//	dir/bugs.db - bugs (JSON-encoded Bug), the key is Bug.ID (hash of the title)
+//	dir/managers.db - last time every manager contacted the dashboard (RFC3339)
+//	dir/bug/ID/ - report and log of the last crash, repro.prog and repro.c
+type State struct {
+	dir      string
+	bugsDB   *db.DB
+	mgrsDB   *db.DB
+	Bugs     map[string]*Bug
+	Managers map[string]time.Time
+}
+
+// Make creates State and initializes it from dir.
+func Make(dir string) (*State, error) {
+	st := &State{
+		dir:      dir,
+		Bugs:     make(map[string]*Bug),
+		Managers: make(map[string]time.Time),
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "bug"), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state dir: %v", err)
+	}
+	var err error
+	if st.bugsDB, err = db.Open(filepath.Join(dir, "bugs.db")); err != nil {
+		return nil, err
+	}
+	if st.mgrsDB, err = db.Open(filepath.Join(dir, "managers.db")); err != nil {
+		return nil, err
+	}
+	for key, rec := range st.bugsDB.Records {
+		bug := new(Bug)
+		if err := json.Unmarshal(rec.Val, bug); err != nil {
+			return nil, fmt.Errorf("failed to parse bug %v: %v", key, err)
+		}
+		st.Bugs[key] = bug
+	}
+	for name, rec := range st.mgrsDB.Records {
+		t, err := time.Parse(time.RFC3339, string(rec.Val))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manager %v: %v", name, err)
+		}
+		st.Managers[name] = t
+	}
+	if err := st.bugsDB.Compact(); err != nil {
+		return nil, err
+	}
+	if err := st.mgrsDB.Compact(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// AddCrash records a crash reported by manager mgr.
+// A fixed bug that crashes again is reopened and marked as regressed.
+func (st *State) AddCrash(mgr, title, kernel, commit string, report, log []byte, now time.Time) (*Bug, error) {
+	if err := st.Poll(mgr, now); err != nil {
+		return nil, err
+	}
+	id := hash(title)
+	bug := st.Bugs[id]
+	if bug == nil {
+		bug = &Bug{
+			ID:     id,
+			Title:  title,
+			Status: StatusOpen,
+			First:  now,
+		}
+		st.Bugs[id] = bug
+	}
+	if bug.Status == StatusFixed && now.After(bug.FixTime) {
+		bug.Status = StatusOpen
+		bug.Regressed = true
+	}
+	bug.Last = now
+	bug.Count++
+	bug.Kernel = kernel
+	bug.Commit = commit
+	seen := false
+	for _, name := range bug.Managers {
+		if name == mgr {
+			seen = true
+			break
+		}
+	}
+	if !seen {
+		bug.Managers = append(bug.Managers, mgr)
+		sort.Strings(bug.Managers)
+	}
+	if err := st.writeFile(id, "report", report); err != nil {
+		return nil, err
+	}
+	if err := st.writeFile(id, "log", log); err != nil {
+		return nil, err
+	}
+	if err := st.saveBug(bug); err != nil {
+		return nil, err
+	}
+	return bug, nil
+}
+
+// AddRepro saves a reproducer for the bug with the title.
+// Only the first reproducer is kept.
+func (st *State) AddRepro(mgr, title, opts string, prog, cprog []byte, now time.Time) error {
+	if err := st.Poll(mgr, now); err != nil {
+		return err
+	}
+	bug := st.Bugs[hash(title)]
+	if bug == nil {
+		return fmt.Errorf("unknown bug '%v'", title)
+	}
+	if bug.Repro {
+		return nil
+	}
+	data := append([]byte(fmt.Sprintf("# %v\n", opts)), prog...)
+	if err := st.writeFile(bug.ID, "repro.prog", data); err != nil {
+		return err
+	}
+	bug.Repro = true
+	if len(cprog) != 0 {
+		if err := st.writeFile(bug.ID, "repro.c", cprog); err != nil {
+			return err
+		}
+		bug.CRepro = true
+	}
+	return st.saveBug(bug)
+}
+
+// SetStatus changes status of the bug with the id,
+// fix is the fix description for fixed bugs.
+func (st *State) SetStatus(id, status, fix string, now time.Time) error {
+	bug := st.Bugs[id]
+	if bug == nil {
+		return fmt.Errorf("unknown bug %v", id)
+	}
+	switch status {
+	case StatusOpen, StatusInvalid:
+		bug.Fix = ""
+		bug.FixTime = time.Time{}
+	case StatusFixed:
+		bug.Fix = fix
+		bug.FixTime = now
+		bug.Regressed = false
+	default:
+		return fmt.Errorf("unknown status %q", status)
+	}
+	bug.Status = status
+	return st.saveBug(bug)
+}
+
+// Poll records that manager mgr is alive.
+func (st *State) Poll(mgr string, now time.Time) error {
+	st.Managers[mgr] = now
+	if err := st.mgrsDB.Save(mgr, db.Record{Val: []byte(now.Format(time.RFC3339))}); err != nil {
+		return err
+	}
+	if st.mgrsDB.Stale() > len(st.mgrsDB.Records)+1000 {
+		return st.mgrsDB.Compact()
+	}
+	return nil
+}
+
+// Stopped returns true if the bug has not happened for period (or since it was fixed),
+// while at least one manager that hit it has been running for period after that.
+// For fixed bugs this means that the fix is verified, for open bugs that
+// the bug was probably fixed.
+func (st *State) Stopped(bug *Bug, period time.Duration, now time.Time) bool {
+	since := bug.Last
+	if bug.Status == StatusFixed && bug.FixTime.After(since) {
+		since = bug.FixTime
+	}
+	if now.Sub(since) < period {
+		return false
+	}
+	for _, name := range bug.Managers {
+		if st.Managers[name].Sub(since) >= period {
+			return true
+		}
+	}
+	return false
+}
+
+// File returns path to the artifact file (report, log, repro.prog, repro.c) of the bug.
+func (st *State) File(id, name string) string {
+	return filepath.Join(st.dir, "bug", id, name)
+}
+
+func (st *State) writeFile(id, name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Join(st.dir, "bug", id), 0700); err != nil {
+		return fmt.Errorf("failed to create bug dir: %v", err)
+	}
+	if err := ioutil.WriteFile(st.File(id, name), data, 0600); err != nil {
+		return fmt.Errorf("failed to write bug file: %v", err)
+	}
+	return nil
+}
+
+func (st *State) saveBug(bug *Bug) error {
+	data, err := json.Marshal(bug)
+	if err != nil {
+		return err
+	}
+	if err := st.bugsDB.Save(bug.ID, db.Record{Val: data}); err != nil {
+		return err
+	}
+	if st.bugsDB.Stale() > len(st.bugsDB.Records)+1000 {
+		return st.bugsDB.Compact()
+	}
+	return nil
+}
+
+func hash(data string) string {
+	sig := sha1.Sum([]byte(data))
+	return hex.EncodeToString(sig[:])
+}
@@ -0,0 +1,110 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func tempState(t *testing.T) (*State, string, func()) {
+	dir, err := ioutil.TempDir("", "syz-dash-state")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+	st, err := Make(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to make state: %v", err)
+	}
+	return st, dir, func() { os.RemoveAll(dir) }
+}
+
+func TestBugLifecycle(t *testing.T) {
+	st, dir, cleanup := tempState(t)
+	defer cleanup()
+	now := time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+	title := "KASAN: use-after-free in foo"
+	bug, err := st.AddCrash("m0", title, "4.8", "", []byte("report"), []byte("log"), now)
+	if err != nil {
+		t.Fatalf("failed to add crash: %v", err)
+	}
+	if _, err := st.AddCrash("m1", title, "4.8", "", []byte("report"), []byte("log"), now.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to add crash: %v", err)
+	}
+	if bug.Status != StatusOpen || bug.Count != 2 || len(bug.Managers) != 2 {
+		t.Fatalf("bad bug: %+v", bug)
+	}
+	if err := st.AddRepro("m1", title, "-threaded=0", []byte("getpid()\n"), nil, now); err != nil {
+		t.Fatalf("failed to add repro: %v", err)
+	}
+	if err := st.SetStatus(bug.ID, StatusFixed, "commit 1234", now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("failed to set status: %v", err)
+	}
+
+	// Reload the state and check that everything is persisted.
+	st.bugsDB.Close()
+	st.mgrsDB.Close()
+	st, err = Make(dir)
+	if err != nil {
+		t.Fatalf("failed to make state: %v", err)
+	}
+	bug = st.Bugs[bug.ID]
+	if bug == nil || bug.Status != StatusFixed || bug.Fix != "commit 1234" || !bug.Repro || bug.CRepro {
+		t.Fatalf("bad bug after reload: %+v", bug)
+	}
+	if data, err := ioutil.ReadFile(st.File(bug.ID, "repro.prog")); err != nil || string(data) != "# -threaded=0\ngetpid()\n" {
+		t.Fatalf("bad repro: %q (%v)", data, err)
+	}
+
+	// The crash happens again after the fix.
+	if _, err := st.AddCrash("m0", title, "4.9", "", nil, nil, now.Add(3*time.Hour)); err != nil {
+		t.Fatalf("failed to add crash: %v", err)
+	}
+	if bug.Status != StatusOpen || !bug.Regressed || bug.Kernel != "4.9" {
+		t.Fatalf("bug is not reopened: %+v", bug)
+	}
+	if err := st.SetStatus(bug.ID, "foo", "", now); err == nil {
+		t.Fatalf("bad status is accepted")
+	}
+}
+
+func TestStopped(t *testing.T) {
+	st, _, cleanup := tempState(t)
+	defer cleanup()
+	now := time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	bug, err := st.AddCrash("m0", "WARNING in foo", "", "", nil, nil, now)
+	if err != nil {
+		t.Fatalf("failed to add crash: %v", err)
+	}
+	if err := st.SetStatus(bug.ID, StatusFixed, "", now.Add(day)); err != nil {
+		t.Fatalf("failed to set status: %v", err)
+	}
+	// The manager did not run after the fix, we don't know if it's fixed.
+	if st.Stopped(bug, 7*day, now.Add(10*day)) {
+		t.Fatalf("bug is stopped without running managers")
+	}
+	// Another manager that never hit the bug does not count.
+	if err := st.Poll("m1", now.Add(9*day)); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if st.Stopped(bug, 7*day, now.Add(10*day)) {
+		t.Fatalf("bug is stopped by unrelated manager")
+	}
+	if err := st.Poll("m0", now.Add(5*day)); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if st.Stopped(bug, 7*day, now.Add(10*day)) {
+		t.Fatalf("bug is stopped too early")
+	}
+	if err := st.Poll("m0", now.Add(9*day)); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if !st.Stopped(bug, 7*day, now.Add(10*day)) {
+		t.Fatalf("bug is not stopped")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Guest disk monitoring: programs create files in executor temp dirs and the kernel floods
+// system logs, so a long-running VM can run out of disk space. Then everything fails with ENOSPC
+// (executor can't create temp dirs, the fuzzer panics, etc) and manager would save bogus crashes.
+// The fuzzer checks free space every diskPeriod, and when it falls below -disk_min
+// removes stale executor temp dirs and truncates large logs. If that does not help,
+// it reports "guest disk full" to manager (Manager.DiskFull), which restarts the VM
+// and does not treat the following failures as kernel bugs.
+
+const (
+	diskPeriod  = 30 * time.Second
+	maxLogSize  = 1 << 20 // logs larger than that are truncated
+	guestLogDir = "/var/log"
+)
+
+var diskFull uint32 // set once guest disk full is reported to manager
+
+// startDiskMonitor periodically checks free disk space in the fuzzer working dir.
+func startDiskMonitor() {
+	if *flagDiskMin <= 0 {
+		return
+	}
+	go func() {
+		for range time.NewTicker(diskPeriod).C {
+			diskCheck()
+		}
+	}()
+}
+
+// diskCheck cleans up the disk if free space is low and reports disk full to manager
+// if the cleanup does not help. It returns true if the disk is full.
+func diskCheck() bool {
+	if *flagDiskMin <= 0 {
+		return false
+	}
+	if atomic.LoadUint32(&diskFull) != 0 {
+		return true
+	}
+	free, err := diskFree()
+	if err != nil || free >= uint64(*flagDiskMin)<<20 {
+		return false
+	}
+	cleaned := diskCleanup()
+	free1, err := diskFree()
+	logf(0, "low disk space: %v MB free, cleaned up %v MB, %v MB free now", free>>20, cleaned>>20, free1>>20)
+	if err != nil || free1 >= uint64(*flagDiskMin)<<20 {
+		return false
+	}
+	if !atomic.CompareAndSwapUint32(&diskFull, 0, 1) {
+		return true
+	}
+	logf(0, "guest disk full: %v MB free", free1>>20)
+	a := &DiskFullArgs{
+		Name: *flagName,
+		Key:  *flagKey,
+		Free: free1,
+	}
+	if err := manager.Call("Manager.DiskFull", a, nil); err != nil {
+		logf(0, "failed to report disk full: %v", err)
+	}
+	return true
+}
+
+func diskFree() (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(".", &st); err != nil {
+		return 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
+
+// diskCleanup removes executor temp dirs that are not used by any process (leftovers of
+// killed executors) and truncates large logs. It returns the approximate number of freed bytes.
+func diskCleanup() uint64 {
+	var freed uint64
+	inUse := processDirs()
+	dirs, _ := filepath.Glob("syzkaller-testdir*")
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil || inUse[abs] {
+			continue
+		}
+		freed += dirSize(abs)
+		os.RemoveAll(abs)
+	}
+	// Logs are opened by daemons, so they are truncated rather than removed.
+	filepath.Walk(guestLogDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() && info.Size() > maxLogSize {
+			if os.Truncate(path, 0) == nil {
+				freed += uint64(info.Size())
+			}
+		}
+		return nil
+	})
+	return freed
+}
+
+// processDirs returns top-level dirs of the fuzzer working dir
+// that are working dirs of running processes.
+func processDirs() map[string]bool {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	dirs := make(map[string]bool)
+	procs, _ := ioutil.ReadDir("/proc")
+	for _, proc := range procs {
+		cwd, err := os.Readlink(filepath.Join("/proc", proc.Name(), "cwd"))
+		if err != nil || !strings.HasPrefix(cwd, wd+"/") {
+			continue
+		}
+		rel := strings.SplitN(cwd[len(wd)+1:], "/", 2)[0]
+		dirs[filepath.Join(wd, rel)] = true
+	}
+	return dirs
+}
+
+func dirSize(dir string) uint64 {
+	var size uint64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,82 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+
+	"github.com/google/syzkaller/cover"
+	"github.com/google/syzkaller/ipc"
+	"github.com/google/syzkaller/prog"
+)
+
+// Fault injection: every new input is re-executed failing the 0th, 1st, 2nd, etc fault site
+// (slab allocation, page allocation, futex, etc) of the new call until the call runs out of
+// fault sites or -fault_max is reached, so that error-handling paths are exercised.
+// Faulty runs don't produce new inputs: corpus programs don't carry faults, so the coverage
+// is not reproducible during triage. For the same reason the coverage is not added to max cover
+// (that would make the fuzzer drop normal inputs that reach it), it is only counted in stats.
+
+// faultSettings make all fault types eligible for injection regardless of allocation flags.
+var faultSettings = map[string]string{
+	"/sys/kernel/debug/failslab/ignore-gfp-wait":           "N",
+	"/sys/kernel/debug/fail_page_alloc/ignore-gfp-wait":    "N",
+	"/sys/kernel/debug/fail_page_alloc/ignore-gfp-highmem": "N",
+	"/sys/kernel/debug/fail_page_alloc/min-order":          "0",
+	"/sys/kernel/debug/fail_futex/ignore-private":          "N",
+}
+
+func faultInit() error {
+	if _, err := os.Stat("/proc/thread-self/fail-nth"); err != nil {
+		return fmt.Errorf("fault injection is not supported by kernel (requires CONFIG_FAULT_INJECTION): %v", err)
+	}
+	for file, val := range faultSettings {
+		// Not all fault types are necessary enabled in kernel config.
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		if err := ioutil.WriteFile(file, []byte(val), 0); err != nil {
+			return fmt.Errorf("failed to write %v: %v", file, err)
+		}
+	}
+	return nil
+}
+
+// injectFaults executes p injecting faults into fault sites of call one by one.
+func injectFaults(pid int, env *ipc.Env, p *prog.Prog, call int) {
+	for nth := 0; nth < *flagFaultMax; nth++ {
+		runSetup(p)
+		idx := gate.Enter()
+		logProgram(pid, env, p, fmt.Sprintf("fault-call:%v fault-nth:%v", call, nth))
+		atomic.AddUint64(&statExecFault, 1)
+		output, rawCover, _, injected, failed, _, err := env.ExecFault(p, call, nth)
+		gate.Leave(idx)
+		if failed {
+			logf(0, "BUG: executor-detected bug:\n%s", output)
+			return
+		}
+		if err != nil {
+			logf(0, "failed to inject fault: %v", err)
+			return
+		}
+		if rawCover == nil {
+			return // the executor was restarted
+		}
+		for i, raw := range rawCover {
+			cov := cover.Cover(raw)
+			callID := p.Calls[i].Meta.CallID
+			coverMu.Lock()
+			diff := cover.Difference(cov, maxCover[callID])
+			diff = cover.Difference(diff, flakes)
+			coverMu.Unlock()
+			atomic.AddUint64(&statFaultCover, uint64(len(diff)))
+		}
+		if !injected {
+			return // the call has no more fault sites
+		}
+	}
+}
@@ -10,6 +10,7 @@ package main
 import (
 	"bytes"
 	"crypto/sha1"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
@@ -17,6 +18,7 @@ import (
 	"net/rpc"
 	"net/rpc/jsonrpc"
 	"os"
+	"path/filepath"
 	"runtime/debug"
 	"strconv"
 	"strings"
@@ -34,17 +36,37 @@ import (
 )
 
 var (
-	flagName     = flag.String("name", "", "unique name for manager")
-	flagExecutor = flag.String("executor", "", "path to executor binary")
-	flagManager  = flag.String("manager", "", "manager rpc address")
-	flagProcs    = flag.Int("procs", 1, "number of parallel test processes")
-	flagLeak     = flag.Bool("leak", false, "detect memory leaks")
-	flagV        = flag.Int("v", 0, "verbosity")
-	flagOutput   = flag.String("output", "stdout", "write programs to none/stdout/dmesg/file")
+	flagName       = flag.String("name", "", "unique name for manager")
+	flagExecutor   = flag.String("executor", "", "path to executor binary")
+	flagManager    = flag.String("manager", "", "manager rpc address")
+	flagKey        = flag.String("key", "", "manager rpc key")
+	flagProcs      = flag.Int("procs", 1, "number of parallel test processes")
+	flagLeak       = flag.Bool("leak", false, "detect memory leaks")
+	flagLeakPeriod = flag.Duration("leak_period", time.Minute, "period of kmemleak scans (with -leak)")
+	flagV          = flag.Int("v", 0, "verbosity")
+	flagOutput     = flag.String("output", "stdout", "write programs to none/stdout/dmesg/file")
+	flagStrategy   = flag.String("strategy", prog.DefaultStrategy, "program mutation strategy")
+	flagPressure   = flag.Bool("pressure", false, "run a memory hog to fuzz under memory pressure")
+	flagMemhog     = flag.Bool("memhog", false, "run as memory hog (internal)")
+	flagPm         = flag.String("pm", "none", "power management cycling between program batches: none/freezer/suspend")
+	flagPmPeriod   = flag.Duration("pm_period", 10*time.Minute, "period of power management cycles")
+	flagFlaky      = flag.Int("flaky_repeat", 0, "execute programs with nondeterministic coverage that many more times during triage and union their coverage")
+	flagErrno      = flag.Bool("errno_feedback", false, "treat the first successful execution of a call as new signal")
+	flagComps      = flag.Bool("comps", false, "mutate new inputs with operands of kernel comparisons (requires CONFIG_KCOV_ENABLE_COMPARISONS)")
+	flagConcolic   = flag.Bool("concolic", false, "send programs blocked on hard comparisons to manager (with -comps)")
+	flagFaults     = flag.Bool("faults", false, "inject faults into fault sites of new inputs one by one (requires CONFIG_FAULT_INJECTION)")
+	flagFaultMax   = flag.Int("fault_max", 100, "max number of fault sites of a call to inject faults into")
+	flagDropCaps   = flag.String("drop_caps", "", "comma-separated capability masks, programs drop a random one of them")
+	flagWeights    = flag.String("mutation_weights", "", "comma-separated relative weights of mutations, e.g. insert:20,mutate_arg:10,remove:1,splice:0")
+	flagCollide    = flag.Int("collide_percent", 100, "percent of programs re-executed in collide mode")
+	flagSeed       = flag.Int64("seed", 0, "seed for random number generators of procs, procs use seed+pid*1e12 (0 - random)")
+	flagTimeout    = flag.Duration("program_timeout", 5*time.Second, "kill programs after that time, programs that can't be killed are reported as hangs")
+	flagDiskMin    = flag.Int("disk_min", 64, "free disk space in MB below which temp files and logs are cleaned up (0 - don't monitor)")
 )
 
 const (
 	programLength = 30
+	recheckPeriod = 100 // on average every that many mutations the original program is re-executed
 )
 
 type Sig [sha1.Size]byte
@@ -53,10 +75,26 @@ func hash(data []byte) Sig {
 	return Sig(sha1.Sum(data))
 }
 
+type callStats struct {
+	execs    uint64
+	errors   uint64
+	newCover uint64
+}
+
 type Input struct {
-	p     *prog.Prog
-	call  int
-	cover cover.Cover
+	p        *prog.Prog
+	call     int
+	cover    cover.Cover
+	success  bool   // the call succeeded for the first time
+	dropCaps uint64 // capabilities dropped when the input was found, triage drops the same
+	origin   string // imported program the input descends from (hash, see manager/provenance.go)
+	errnos   []int  // errnos of calls of p in the last triage execution (see addResourceResult)
+}
+
+type Candidate struct {
+	p      *prog.Prog
+	sig    Sig
+	origin string
 }
 
 var (
@@ -67,94 +105,194 @@ var (
 	maxCover    []cover.Cover
 	flakes      cover.Cover
 
+	// Errno feedback: a call that succeeds for the first time (e.g. an ioctl that fails with EINVAL
+	// until the device is driven into the right state) is new signal even without new coverage,
+	// which helps to get through state machines where coverage plateaus. Indexed by call ID,
+	// guarded by coverMu.
+	corpusSucceeded []bool // calls that succeeded in corpus programs
+	maxSucceeded    []bool // calls that succeeded in any executed program
+
 	corpusMu     sync.RWMutex
 	corpus       []*prog.Prog
+	corpusSigs   []Sig                  // hashes of corpus programs
+	corpusOrigin = make(map[Sig]string) // origins of corpus programs that descend from imported programs
 	corpusHashes map[Sig]struct{}
 
 	triageMu   sync.RWMutex
 	triage     []Input
-	candidates []*prog.Prog
+	candidates []Candidate
+
+	resourceMu      sync.Mutex
+	resourceResults []ResourceResult // sent to manager on the next poll
 
 	gate *ipc.Gate
 
+	ctMu     sync.RWMutex
+	ct       *prog.ChoiceTable
+	disabled map[int]bool // syscalls disabled due to a crash storm
+	values   = prog.NewValuePool()
+
 	statExecGen       uint64
 	statExecFuzz      uint64
 	statExecCandidate uint64
 	statExecTriage    uint64
 	statExecMinimize  uint64
+	statExecRecheck   uint64
+	statExecFlaky     uint64
+	statExecHints     uint64
+	statExecFault     uint64
+	statFaultCover    uint64
 	statNewInput      uint64
+	statNewSuccess    uint64
+	statCalls         = make([]callStats, len(sys.Calls)) // indexed by call ID, updated atomically
 
 	allTriaged uint32
 	noCover    bool
+	dropCaps   []uint64 // rows of the capability matrix (-drop_caps)
+	weights    prog.MutationWeights
 )
 
 func main() {
 	debug.SetGCPercent(50)
 	flag.Parse()
+	if *flagMemhog {
+		runMemhog()
+		return
+	}
 	switch *flagOutput {
 	case "none", "stdout", "dmesg", "file":
 	default:
 		fmt.Fprintf(os.Stderr, "-output flag must be one of none/stdout/dmesg/file\n")
 		os.Exit(1)
 	}
+	if *flagOutput == "file" {
+		// Program files are written atomically, temp files are leftovers after a kernel crash.
+		tmps, _ := filepath.Glob(fmt.Sprintf("%v-*.prog.tmp", *flagName))
+		for _, tmp := range tmps {
+			os.Remove(tmp)
+		}
+	}
+	if err := pmCheck(*flagPm); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
 	logf(0, "fuzzer started, log level %v", *flagV)
+	if *flagSeed != 0 {
+		logf(0, "random seed %v", *flagSeed)
+	}
+	strategy, err := prog.LookupStrategy(*flagStrategy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if dropCaps, err = parseDropCaps(*flagDropCaps); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if weights, err = parseMutationWeights(*flagWeights); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
 
 	corpusCover = make([]cover.Cover, sys.CallCount)
 	maxCover = make([]cover.Cover, sys.CallCount)
+	corpusSucceeded = make([]bool, len(sys.Calls))
+	maxSucceeded = make([]bool, len(sys.Calls))
 	corpusHashes = make(map[Sig]struct{})
 
+	flags, timeout, err := ipc.DefaultFlags()
+	if err != nil {
+		panic(err)
+	}
+	noCover = flags&ipc.FlagCover == 0
+	pseudoFiles = findPseudoFiles(flags&ipc.FlagDangerous != 0)
+
 	logf(0, "dialing manager at %v", *flagManager)
 	conn, err := jsonrpc.Dial("tcp", *flagManager)
 	if err != nil {
 		panic(err)
 	}
 	manager = conn
-	a := &ConnectArgs{*flagName}
+	a := &ConnectArgs{Name: *flagName, Key: *flagKey, Version: ProtocolVersion}
 	r := &ConnectRes{}
 	if err := manager.Call("Manager.Connect", a, r); err != nil {
 		panic(err)
 	}
 	calls := buildCallList(r.EnabledCalls)
-	ct := prog.BuildChoiceTable(r.Prios, calls)
+	for key, vals := range r.Values {
+		for _, v := range vals {
+			values.Add(key, uintptr(v))
+		}
+	}
+	ct = prog.BuildChoiceTable(r.Prios, calls)
+	ct.SetValuePool(values)
+	ct.SetPseudoFiles(pseudoFiles)
+	ct.SetMutationWeights(weights)
+	ct.SetSpliceCorpus(spliceCorpus)
+	disabled = parseDisabledCalls(r.DisabledCalls)
+	initSetup(r.Setup)
+	ca := &CheckArgs{Name: *flagName, Key: *flagKey}
+	for c := range calls {
+		ca.Calls = append(ca.Calls, c.Name)
+	}
+	if err := manager.Call("Manager.Check", ca, nil); err != nil {
+		panic(err)
+	}
 
 	kmemleakInit()
-
-	flags, timeout, err := ipc.DefaultFlags()
-	if err != nil {
-		panic(err)
+	if *flagFaults {
+		if err := faultInit(); err != nil {
+			log.Fatalf("%v", err)
+		}
 	}
-	noCover = flags&ipc.FlagCover == 0
+	if *flagPressure {
+		startMemhog()
+	}
+	startDiskMonitor()
+
 	if !noCover {
 		fd, err := syscall.Open("/sys/kernel/debug/kcov", syscall.O_RDWR, 0)
 		if err != nil {
 			log.Fatalf("BUG: /sys/kernel/debug/kcov is missing (%v). Enable CONFIG_KCOV and mount debugfs.", err)
 		}
 		syscall.Close(fd)
+		if *flagComps && !host.KcovComparisonsSupported() {
+			// Otherwise the executor fails on every hints run.
+			log.Printf("kcov does not support comparisons (CONFIG_KCOV_ENABLE_COMPARISONS), disabling -comps")
+			*flagComps = false
+		}
 	}
-	leakCallback := func() {
-		if atomic.LoadUint32(&allTriaged) != 0 {
-			// Scan for leaks once in a while (it is damn slow).
-			kmemleakScan(true)
+	batchCallback := func() {
+		if *flagLeak && atomic.LoadUint32(&allTriaged) != 0 {
+			leakCycle(*flagLeakPeriod)
 		}
+		pmCycle(*flagPm, *flagPmPeriod)
 	}
-	if !*flagLeak {
-		leakCallback = nil
+	if !*flagLeak && *flagPm == "none" {
+		batchCallback = nil
 	}
-	gate = ipc.NewGate(2**flagProcs, leakCallback)
+	gate = ipc.NewGate(2**flagProcs, batchCallback)
 	envs := make([]*ipc.Env, *flagProcs)
 	for pid := 0; pid < *flagProcs; pid++ {
 		env, err := ipc.MakeEnv(*flagExecutor, timeout, flags)
 		if err != nil {
 			panic(err)
 		}
+		env.ProgramTimeout = *flagTimeout
 		envs[pid] = env
 
 		pid := pid
 		go func() {
-			rs := rand.NewSource(time.Now().UnixNano() + int64(pid)*1e12)
+			seed := time.Now().UnixNano()
+			if *flagSeed != 0 {
+				seed = *flagSeed
+			}
+			rs := rand.NewSource(seed + int64(pid)*1e12)
 			rnd := rand.New(rs)
 
 			for i := 0; ; i++ {
+				env.DropCaps = randomDropCaps(rnd)
+				env.NoCollide = rnd.Intn(100) >= *flagCollide
 				triageMu.RLock()
 				if len(triage) != 0 || len(candidates) != 0 {
 					triageMu.RUnlock()
@@ -169,10 +307,11 @@ func main() {
 						continue
 					} else if len(candidates) != 0 {
 						last := len(candidates) - 1
-						p := candidates[last]
+						cand := candidates[last]
 						candidates = candidates[:last]
 						triageMu.Unlock()
-						execute(pid, env, p, &statExecCandidate)
+						errnos := executeFrom(pid, env, cand.p, &statExecCandidate, cand.origin)
+						addResourceResult(cand.sig, cand.p, errnos)
 						continue
 					} else {
 						triageMu.Unlock()
@@ -181,22 +320,36 @@ func main() {
 					triageMu.RUnlock()
 				}
 
+				ct := choiceTable()
 				corpusMu.RLock()
 				if len(corpus) == 0 || i%10 == 0 {
 					corpusMu.RUnlock()
+					start := time.Now()
 					p := prog.Generate(rnd, programLength, ct)
+					timePhase(phaseGenerate, start)
 					logf(1, "#%v: generated: %s", i, p)
 					execute(pid, env, p, &statExecGen)
-					p.Mutate(rnd, programLength, ct)
+					start = time.Now()
+					strategy.Mutate(p, rnd, programLength, ct)
+					timePhase(phaseMutate, start)
 					logf(1, "#%v: mutated: %s", i, p)
 					execute(pid, env, p, &statExecFuzz)
 				} else {
-					p0 := corpus[rnd.Intn(len(corpus))]
+					idx := rnd.Intn(len(corpus))
+					p0, sig := corpus[idx], corpusSigs[idx]
+					origin := corpusOrigin[sig]
 					corpusMu.RUnlock()
+					if rnd.Intn(recheckPeriod) == 0 {
+						// Check that the program still works on this kernel (see addResourceResult).
+						errnos := execute(pid, env, p0, &statExecRecheck)
+						addResourceResult(sig, p0, errnos)
+					}
+					start := time.Now()
 					p := p0.Clone()
-					p.Mutate(rs, programLength, ct)
+					strategy.Mutate(p, rs, programLength, ct)
+					timePhase(phaseMutate, start)
 					logf(1, "#%v: mutated: %s <- %s", i, p, p0)
-					execute(pid, env, p, &statExecFuzz)
+					executeFrom(pid, env, p, &statExecFuzz, origin)
 				}
 			}
 		}()
@@ -220,6 +373,7 @@ func main() {
 
 			a := &PollArgs{
 				Name:  *flagName,
+				Key:   *flagKey,
 				Stats: make(map[string]uint64),
 			}
 			for _, env := range envs {
@@ -231,32 +385,85 @@ func main() {
 			a.Stats["exec candidate"] = atomic.SwapUint64(&statExecCandidate, 0)
 			a.Stats["exec triage"] = atomic.SwapUint64(&statExecTriage, 0)
 			a.Stats["exec minimize"] = atomic.SwapUint64(&statExecMinimize, 0)
+			a.Stats["exec recheck"] = atomic.SwapUint64(&statExecRecheck, 0)
+			a.Stats["exec flaky"] = atomic.SwapUint64(&statExecFlaky, 0)
+			a.Stats["exec hints"] = atomic.SwapUint64(&statExecHints, 0)
+			a.Stats["exec fault"] = atomic.SwapUint64(&statExecFault, 0)
+			phaseStats(a.Stats, envs)
+			a.Stats["fuzzer fault new cover"] = atomic.SwapUint64(&statFaultCover, 0)
 			a.Stats["fuzzer new inputs"] = atomic.SwapUint64(&statNewInput, 0)
+			a.Stats["fuzzer new success inputs"] = atomic.SwapUint64(&statNewSuccess, 0)
+			a.Stats["fuzzer hangs"] = atomic.SwapUint64(&statHangs, 0)
+			a.CallStats = make(map[string]CallStats)
+			for id := range statCalls {
+				st := &statCalls[id]
+				cs := CallStats{
+					Execs:    atomic.SwapUint64(&st.execs, 0),
+					Errors:   atomic.SwapUint64(&st.errors, 0),
+					NewCover: atomic.SwapUint64(&st.newCover, 0),
+				}
+				if cs != (CallStats{}) {
+					a.CallStats[sys.Calls[id].Name] = cs
+				}
+			}
+			resourceMu.Lock()
+			a.ResourceResults = resourceResults
+			resourceResults = nil
+			resourceMu.Unlock()
+			blockedMu.Lock()
+			a.Blocked = blocked
+			blocked = nil
+			blockedMu.Unlock()
 			r := &PollRes{}
 			if err := manager.Call("Manager.Poll", a, r); err != nil {
 				panic(err)
 			}
+			if r.Reconfigure {
+				calls := buildCallList(r.EnabledCalls)
+				ctMu.Lock()
+				ct = prog.BuildChoiceTable(r.Prios, calls)
+				ct.SetValuePool(values)
+				ct.SetPseudoFiles(pseudoFiles)
+				ct.SetMutationWeights(weights)
+				ct.SetSpliceCorpus(spliceCorpus)
+				disabled = parseDisabledCalls(r.DisabledCalls)
+				ctMu.Unlock()
+				logf(0, "reconfigured with %v enabled calls, %v disabled due to a crash storm",
+					len(calls), len(disabled))
+			}
+			if r.Profile != "" {
+				go sendProfile(r.Profile)
+			}
 			for _, inp := range r.NewInputs {
 				addInput(inp)
 			}
-			for _, data := range r.Candidates {
+			for i, data := range r.Candidates {
 				p, err := prog.Deserialize(data)
 				if err != nil {
 					panic(err)
 				}
+				origin := ""
+				if i < len(r.CandidateOrigins) {
+					origin = r.CandidateOrigins[i]
+				}
 				if noCover {
 					corpusMu.Lock()
 					corpus = append(corpus, p)
+					corpusSigs = append(corpusSigs, hash(data))
+					if origin != "" {
+						corpusOrigin[hash(data)] = origin
+					}
 					corpusMu.Unlock()
 				} else {
 					triageMu.Lock()
-					candidates = append(candidates, p)
+					candidates = append(candidates, Candidate{p, hash(data), origin})
 					triageMu.Unlock()
 				}
 			}
 			if len(r.Candidates) == 0 {
 				if atomic.LoadUint32(&allTriaged) == 0 {
 					if *flagLeak {
+						// Drop leaks that happened during boot and triage.
 						kmemleakScan(false)
 					}
 					atomic.StoreUint32(&allTriaged, 1)
@@ -269,6 +476,40 @@ func main() {
 	}
 }
 
+func choiceTable() *prog.ChoiceTable {
+	ctMu.RLock()
+	defer ctMu.RUnlock()
+	return ct
+}
+
+func parseDisabledCalls(calls string) map[int]bool {
+	res := make(map[int]bool)
+	if calls == "" {
+		return res
+	}
+	for _, id := range strings.Split(calls, ",") {
+		n, err := strconv.Atoi(id)
+		if err != nil || n < 0 || n >= len(sys.Calls) {
+			panic(fmt.Sprintf("invalid disabled syscall: '%v'", id))
+		}
+		res[n] = true
+	}
+	return res
+}
+
+// usesDisabledCalls returns whether p contains syscalls disabled due to a crash storm.
+// The choice table does not produce such calls, but corpus programs can contain them.
+func usesDisabledCalls(p *prog.Prog) bool {
+	ctMu.RLock()
+	defer ctMu.RUnlock()
+	for _, c := range p.Calls {
+		if disabled[c.Meta.ID] {
+			return true
+		}
+	}
+	return false
+}
+
 func buildCallList(enabledCalls string) map[*sys.Call]bool {
 	calls := make(map[*sys.Call]bool)
 	if enabledCalls != "" {
@@ -330,13 +571,22 @@ func addInput(inp RpcInput) {
 	cov := cover.Canonicalize(inp.Cover)
 	diff := cover.Difference(cov, maxCover[call.CallID])
 	diff = cover.Difference(diff, flakes)
-	if len(diff) == 0 {
+	success := inp.Success && !corpusSucceeded[call.ID]
+	if len(diff) == 0 && !success {
 		return
 	}
 	corpus = append(corpus, p)
+	corpusSigs = append(corpusSigs, sig)
+	if inp.Origin != "" {
+		corpusOrigin[sig] = inp.Origin
+	}
 	corpusCover[call.CallID] = cover.Union(corpusCover[call.CallID], cov)
 	maxCover[call.CallID] = cover.Union(maxCover[call.CallID], cov)
-	corpusHashes[hash(inp.Prog)] = struct{}{}
+	if inp.Success {
+		corpusSucceeded[call.ID] = true
+		maxSucceeded[call.ID] = true
+	}
+	corpusHashes[sig] = struct{}{}
 }
 
 func triageInput(pid int, env *ipc.Env, inp Input) {
@@ -344,12 +594,14 @@ func triageInput(pid int, env *ipc.Env, inp Input) {
 		panic("should not be called when coverage is disabled")
 	}
 
+	env.DropCaps = inp.dropCaps
 	call := inp.p.Calls[inp.call].Meta
 	coverMu.RLock()
 	newCover := cover.Difference(inp.cover, corpusCover[call.CallID])
 	newCover = cover.Difference(newCover, flakes)
+	success := inp.success && !corpusSucceeded[call.ID]
 	coverMu.RUnlock()
-	if len(newCover) == 0 {
+	if len(newCover) == 0 && !success {
 		return
 	}
 
@@ -361,16 +613,24 @@ func triageInput(pid int, env *ipc.Env, inp Input) {
 	corpusMu.RUnlock()
 
 	minCover := inp.cover
+	unionCover := inp.cover
+	flaky := false
 	for i := 0; i < 3; i++ {
-		allCover := execute1(pid, env, inp.p, &statExecTriage)
+		allCover, errnos := execute1(pid, env, inp.p, &statExecTriage, "")
+		if success && (len(errnos) <= inp.call || errnos[inp.call] != 0) {
+			success = false // the success is not reproducible
+		}
 		if len(allCover[inp.call]) == 0 {
 			// The call was not executed. Happens sometimes, reason unknown.
 			continue
 		}
+		inp.errnos = errnos
 		coverMu.RLock()
 		cov := allCover[inp.call]
 		diff := cover.SymmetricDifference(inp.cover, cov)
 		minCover = cover.Intersection(minCover, cov)
+		unionCover = cover.Union(unionCover, cov)
+		flaky = flaky || len(diff) != 0
 		updateFlakes := len(diff) != 0 && len(cover.Difference(diff, flakes)) != 0
 		coverMu.RUnlock()
 		if updateFlakes {
@@ -379,31 +639,122 @@ func triageInput(pid int, env *ipc.Env, inp Input) {
 			coverMu.Unlock()
 		}
 	}
+	if flaky && *flagFlaky > 0 && !success {
+		triageFlaky(pid, env, inp, unionCover)
+		return
+	}
 	stableNewCover := cover.Intersection(newCover, minCover)
-	if len(stableNewCover) == 0 {
+	if len(stableNewCover) == 0 && !success {
 		return
 	}
 	inp.p, inp.call = prog.Minimize(inp.p, inp.call, func(p1 *prog.Prog, call1 int) bool {
-		allCover := execute1(pid, env, p1, &statExecMinimize)
+		allCover, errnos := execute1(pid, env, p1, &statExecMinimize, "")
 		coverMu.RLock()
 		defer coverMu.RUnlock()
 
 		if len(allCover[call1]) == 0 {
 			return false // The call was not executed.
 		}
+		if success && errnos[call1] != 0 {
+			return false
+		}
 		cov := allCover[call1]
 		if len(cover.Intersection(stableNewCover, cov)) != len(stableNewCover) {
 			return false
 		}
 		minCover = cover.Intersection(minCover, cov)
+		inp.errnos = errnos
 		return true
 	})
 	inp.cover = minCover
+	inp.success = success
+	saveInput(inp, len(stableNewCover) != 0)
+	if *flagComps {
+		executeHints(pid, env, inp.p, inp.call)
+	}
+	if *flagFaults {
+		injectFaults(pid, env, inp.p, inp.call)
+	}
+}
+
+// triageFlaky triages an input with nondeterministic coverage ("repeat with variation" mode):
+// the program is executed *flagFlaky more times and its coverage is the union of all runs.
+// Triage decisions are based on the union, so timing-dependent paths are accounted
+// consistently instead of being dropped by the intersection and rediscovered over and over.
+// unionCover is coverage of the initial triage runs. During minimization repeated runs
+// stop as soon as the new coverage is reproduced.
+func triageFlaky(pid int, env *ipc.Env, inp Input, unionCover cover.Cover) {
+	repeat := func(p *prog.Prog, call int, cov cover.Cover, want cover.Cover, stat *uint64) cover.Cover {
+		for i := 0; i < *flagFlaky; i++ {
+			if want != nil && len(cover.Intersection(want, cov)) == len(want) {
+				break
+			}
+			allCover, _ := execute1(pid, env, p, stat, "")
+			cov = cover.Union(cov, allCover[call])
+		}
+		return cov
+	}
+	unionCover = repeat(inp.p, inp.call, unionCover, nil, &statExecFlaky)
+	call := inp.p.Calls[inp.call].Meta
+	coverMu.RLock()
+	newCover := cover.Difference(unionCover, corpusCover[call.CallID])
+	coverMu.RUnlock()
+	if len(newCover) == 0 {
+		return
+	}
+	inp.p, inp.call = prog.Minimize(inp.p, inp.call, func(p1 *prog.Prog, call1 int) bool {
+		allCover, errnos := execute1(pid, env, p1, &statExecMinimize, "")
+		if len(allCover[call1]) == 0 {
+			return false // The call was not executed.
+		}
+		cov := repeat(p1, call1, allCover[call1], newCover, &statExecFlaky)
+		if len(cover.Intersection(newCover, cov)) != len(newCover) {
+			return false
+		}
+		unionCover = cov
+		inp.errnos = errnos
+		return true
+	})
+	inp.cover = unionCover
+	saveInput(inp, true)
+}
+
+// saveInput sends a triaged input to the manager and adds it to the local corpus.
+// newCover says that the call gave new coverage (rather than just succeeded for the first time).
+func saveInput(inp Input, newCover bool) {
+	call := inp.p.Calls[inp.call].Meta
 
 	atomic.AddUint64(&statNewInput, 1)
+	if inp.success {
+		atomic.AddUint64(&statNewSuccess, 1)
+	}
 	data := inp.p.Serialize()
-	logf(2, "added new input for %v to corpus:\n%s", call.CallName, data)
-	a := &NewInputArgs{*flagName, RpcInput{call.CallName, data, inp.call, []uint32(inp.cover)}}
+	logf(2, "added new input for %v to corpus (success=%v):\n%s", call.CallName, inp.success, data)
+	a := &NewInputArgs{
+		Name: *flagName,
+		Key:  *flagKey,
+		RpcInput: RpcInput{
+			Call:      call.CallName,
+			Prog:      data,
+			CallIndex: inp.call,
+			Cover:     []uint32(inp.cover),
+			Success:   inp.success,
+			Origin:    inp.origin,
+			Resources: resourceSuccesses(inp.p, inp.errnos),
+		},
+		Values: make(map[string][]uint64),
+	}
+	if newCover {
+		// Values of the call args that gave new coverage are likely to be interesting
+		// for other programs with the same call.
+		for key, vals := range prog.CallValues(inp.p.Calls[inp.call]) {
+			for _, v := range vals {
+				if values.Add(key, v) {
+					a.Values[key] = append(a.Values[key], uint64(v))
+				}
+			}
+		}
+	}
 	if err := manager.Call("Manager.NewInput", a, nil); err != nil {
 		panic(err)
 	}
@@ -413,13 +764,66 @@ func triageInput(pid int, env *ipc.Env, inp Input) {
 	coverMu.Lock()
 	defer coverMu.Unlock()
 
-	corpusCover[call.CallID] = cover.Union(corpusCover[call.CallID], minCover)
+	corpusCover[call.CallID] = cover.Union(corpusCover[call.CallID], inp.cover)
+	if inp.success {
+		corpusSucceeded[call.ID] = true
+	}
+	sig := hash(data)
 	corpus = append(corpus, inp.p)
-	corpusHashes[hash(data)] = struct{}{}
+	corpusSigs = append(corpusSigs, sig)
+	corpusHashes[sig] = struct{}{}
+	if inp.origin != "" {
+		corpusOrigin[sig] = inp.origin
+	}
+}
+
+// addResourceResult records outcome of resource-producing calls of an unmodified
+// corpus program, manager uses them to detect programs that stopped working
+// (e.g. the kernel no longer allows to create the resource).
+func addResourceResult(sig Sig, p *prog.Prog, errnos []int) {
+	calls := p.ResourceCalls()
+	if len(calls) == 0 || len(errnos) != len(p.Calls) {
+		return
+	}
+	res := ResourceResult{
+		Sig:   hex.EncodeToString(sig[:]),
+		Calls: calls,
+	}
+	for _, idx := range calls {
+		res.Errnos = append(res.Errnos, errnos[idx])
+	}
+	resourceMu.Lock()
+	resourceResults = append(resourceResults, res)
+	resourceMu.Unlock()
 }
 
-func execute(pid int, env *ipc.Env, p *prog.Prog, stat *uint64) {
-	allCover := execute1(pid, env, p, stat)
+// resourceSuccesses returns indices of resource-producing calls of p that succeeded.
+func resourceSuccesses(p *prog.Prog, errnos []int) []int {
+	if len(errnos) != len(p.Calls) {
+		return nil
+	}
+	var res []int
+	for _, idx := range p.ResourceCalls() {
+		if errnos[idx] == 0 {
+			res = append(res, idx)
+		}
+	}
+	return res
+}
+
+func execute(pid int, env *ipc.Env, p *prog.Prog, stat *uint64) []int {
+	return executeFrom(pid, env, p, stat, "")
+}
+
+// executeFrom executes p that descends from the imported program origin (if not empty),
+// new inputs found by p inherit the origin and the program is logged with it.
+func executeFrom(pid int, env *ipc.Env, p *prog.Prog, stat *uint64, origin string) []int {
+	note := ""
+	if origin != "" {
+		note = "origin " + origin
+	}
+	allCover, errnos := execute1(pid, env, p, stat, note)
+	defer timePhase(phaseCover, time.Now())
 	coverMu.RLock()
 	defer coverMu.RUnlock()
 	for i, cov := range allCover {
@@ -429,47 +833,97 @@ func execute(pid int, env *ipc.Env, p *prog.Prog, stat *uint64) {
 		c := p.Calls[i].Meta
 		diff := cover.Difference(cov, maxCover[c.CallID])
 		diff = cover.Difference(diff, flakes)
-		if len(diff) != 0 {
+		success := *flagErrno && errnos[i] == 0 && !maxSucceeded[c.ID]
+		if len(diff) != 0 || success {
 			coverMu.RUnlock()
 			coverMu.Lock()
 			maxCover[c.CallID] = cover.Union(maxCover[c.CallID], diff)
+			if success {
+				maxSucceeded[c.ID] = true
+			}
 			coverMu.Unlock()
+			atomic.AddUint64(&statCalls[c.ID].newCover, uint64(len(diff)))
 			coverMu.RLock()
 
-			inp := Input{p.Clone(), i, cover.Copy(cov)}
+			inp := Input{p.Clone(), i, cover.Copy(cov), success, env.DropCaps, origin, nil}
 			triageMu.Lock()
 			triage = append(triage, inp)
 			triageMu.Unlock()
 		}
 	}
+	return errnos
 }
 
-var logMu sync.Mutex
+// parseDropCaps parses -drop_caps flag value, e.g. "0,0x200000,0x3000".
+func parseDropCaps(s string) ([]uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var masks []uint64
+	for _, v := range strings.Split(s, ",") {
+		mask, err := strconv.ParseUint(v, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad drop_caps flag '%v': %v", s, err)
+		}
+		masks = append(masks, mask)
+	}
+	return masks, nil
+}
 
-func execute1(pid int, env *ipc.Env, p *prog.Prog, stat *uint64) []cover.Cover {
-	if false {
-		// For debugging, this function must not be executed with locks held.
-		corpusMu.Lock()
-		corpusMu.Unlock()
-		coverMu.Lock()
-		coverMu.Unlock()
-		triageMu.Lock()
-		triageMu.Unlock()
+// randomDropCaps returns a random row of the capability matrix.
+func randomDropCaps(rnd *rand.Rand) uint64 {
+	if len(dropCaps) == 0 {
+		return 0
 	}
+	return dropCaps[rnd.Intn(len(dropCaps))]
+}
 
-	// Limit concurrency window and do leak checking once in a while.
-	idx := gate.Enter()
-	defer gate.Leave(idx)
+// parseMutationWeights parses -mutation_weights flag value, e.g. "insert:20,splice:5".
+func parseMutationWeights(s string) (prog.MutationWeights, error) {
+	named := make(map[string]int)
+	if s != "" {
+		for _, kv := range strings.Split(s, ",") {
+			parts := strings.Split(kv, ":")
+			if len(parts) != 2 {
+				return prog.MutationWeights{}, fmt.Errorf("bad mutation_weights flag '%v'", s)
+			}
+			v, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return prog.MutationWeights{}, fmt.Errorf("bad mutation_weights flag '%v': %v", s, err)
+			}
+			named[parts[0]] = v
+		}
+	}
+	return prog.ParseMutationWeights(named)
+}
+
+// spliceCorpus returns the current corpus for the splice mutation.
+func spliceCorpus() []*prog.Prog {
+	corpusMu.RLock()
+	defer corpusMu.RUnlock()
+	return corpus
+}
+
+var logMu sync.Mutex
 
-	// The following output helps to understand what program crashed kernel.
-	// It must not be intermixed.
+// logProgram outputs p before execution, the output helps to understand what program crashed kernel.
+// It must not be intermixed. note describes how the program is executed (e.g. injected fault).
+func logProgram(pid int, env *ipc.Env, p *prog.Prog, note string) {
+	if *flagLeak {
+		leakRecord(p)
+	}
+	note = execNote(env, note)
 	switch *flagOutput {
 	case "none":
 		// This case intentionally left blank.
 	case "stdout":
 		data := p.Serialize()
 		logMu.Lock()
-		log.Printf("executing program %v:\n%s", pid, data)
+		if note != "" {
+			log.Printf("executing program %v (%v):\n%s", pid, note, data)
+		} else {
+			log.Printf("executing program %v:\n%s", pid, data)
+		}
 		logMu.Unlock()
 	case "dmesg":
 		fd, err := syscall.Open("/dev/kmsg", syscall.O_WRONLY, 0)
@@ -480,26 +934,97 @@ func execute1(pid int, env *ipc.Env, p *prog.Prog, stat *uint64) []cover.Cover {
 			syscall.Close(fd)
 		}
 	case "file":
-		f, err := os.Create(fmt.Sprintf("%v-%v.prog", *flagName, pid))
-		if err == nil {
-			f.Write(p.Serialize())
-			f.Close()
+		writeProgFile(fmt.Sprintf("%v-%v.prog", *flagName, pid), p.Serialize())
+	}
+}
+
+// writeProgFile replaces file with a program for -output=file. The file must hold a complete
+// program after a kernel crash, that's the point of it, so it is replaced with a rename.
+// It is not fsynced as this happens before every execution: after a crash the file
+// can hold one of the previous programs, but never a partially written one.
+func writeProgFile(file string, data []byte) {
+	tmp := file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(tmp, file)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// execNote adds per-execution parameters of env to note.
+func execNote(env *ipc.Env, note string) string {
+	if env.DropCaps != 0 {
+		if note != "" {
+			note += ", "
+		}
+		note += fmt.Sprintf("drop caps 0x%x", env.DropCaps)
+	}
+	if env.NoCollide {
+		if note != "" {
+			note += ", "
 		}
+		note += "no collide"
 	}
+	return note
+}
+
+func execute1(pid int, env *ipc.Env, p *prog.Prog, stat *uint64, note string) ([]cover.Cover, []int) {
+	if false {
+		// For debugging, this function must not be executed with locks held.
+		corpusMu.Lock()
+		corpusMu.Unlock()
+		coverMu.Lock()
+		coverMu.Unlock()
+		triageMu.Lock()
+		triageMu.Unlock()
+	}
+
+	if usesDisabledCalls(p) {
+		return make([]cover.Cover, len(p.Calls)), nil
+	}
+	runSetup(p)
+
+	// Limit concurrency window and do leak checking once in a while.
+	idx := gate.Enter()
+	defer gate.Leave(idx)
+
+	start := time.Now()
+	logProgram(pid, env, p, note)
+	timePhase(phaseSerialize, start)
 
 	try := 0
 retry:
 	atomic.AddUint64(stat, 1)
 	output, rawCover, errnos, failed, hanged, err := env.Exec(p)
-	_ = errnos
+	if hanged {
+		reportHang(p, execNote(env, note))
+		return make([]cover.Cover, len(p.Calls)), nil
+	}
 	if failed {
+		if diskCheck() {
+			// Executor failures are expected with full disk, they are not kernel bugs.
+			logf(0, "executor failed with guest disk full:\n%s", output)
+			return make([]cover.Cover, len(p.Calls)), nil
+		}
 		// BUG in output should be recognized by manager.
 		logf(0, "BUG: executor-detected bug:\n%s", output)
 		// Don't return any cover so that the input is not added to corpus.
-		return make([]cover.Cover, len(p.Calls))
+		return make([]cover.Cover, len(p.Calls)), nil
 	}
 	if err != nil {
 		if try > 10 {
+			if diskCheck() {
+				log.Fatalf("executor failed with guest disk full: %v", err)
+			}
 			panic(err)
 		}
 		try++
@@ -509,11 +1034,21 @@ retry:
 		goto retry
 	}
 	logf(2, "result failed=%v hanged=%v:\n%v\n", failed, hanged, string(output))
+	for i, errno := range errnos {
+		if errno == -1 {
+			continue // not executed
+		}
+		st := &statCalls[p.Calls[i].Meta.ID]
+		atomic.AddUint64(&st.execs, 1)
+		if errno != 0 {
+			atomic.AddUint64(&st.errors, 1)
+		}
+	}
 	cov := make([]cover.Cover, len(p.Calls))
 	for i, c := range rawCover {
 		cov[i] = cover.Cover(c)
 	}
-	return cov
+	return cov, errnos
 }
 
 func logf(v int, msg string, args ...interface{}) {
@@ -521,76 +1056,3 @@ func logf(v int, msg string, args ...interface{}) {
 		log.Printf(msg, args...)
 	}
 }
-
-func kmemleakInit() {
-	fd, err := syscall.Open("/sys/kernel/debug/kmemleak", syscall.O_RDWR, 0)
-	if err != nil {
-		if *flagLeak {
-			log.Fatalf("BUG: /sys/kernel/debug/kmemleak is missing (%v). Enable CONFIG_KMEMLEAK and mount debugfs.", err)
-		} else {
-			return
-		}
-	}
-	defer syscall.Close(fd)
-	what := "scan=off"
-	if !*flagLeak {
-		what = "off"
-	}
-	if _, err := syscall.Write(fd, []byte(what)); err != nil {
-		// kmemleak returns EBUSY when kmemleak is already turned off.
-		if err != syscall.EBUSY {
-			panic(err)
-		}
-	}
-}
-
-var kmemleakBuf []byte
-
-func kmemleakScan(report bool) {
-	fd, err := syscall.Open("/sys/kernel/debug/kmemleak", syscall.O_RDWR, 0)
-	if err != nil {
-		panic(err)
-	}
-	defer syscall.Close(fd)
-	// Kmemleak has false positives. To mitigate most of them, it checksums
-	// potentially leaked objects, and reports them only on the next scan
-	// iff the checksum does not change. Because of that we do the following
-	// intricate dance:
-	// Scan, sleep, scan again. At this point we can get some leaks.
-	// If there are leaks, we sleep and scan again, this can remove
-	// false leaks. Then, read kmemleak again. If we get leaks now, then
-	// hopefully these are true positives during the previous testing cycle.
-	if _, err := syscall.Write(fd, []byte("scan")); err != nil {
-		panic(err)
-	}
-	time.Sleep(time.Second)
-	if _, err := syscall.Write(fd, []byte("scan")); err != nil {
-		panic(err)
-	}
-	if report {
-		if kmemleakBuf == nil {
-			kmemleakBuf = make([]byte, 128<<10)
-		}
-		n, err := syscall.Read(fd, kmemleakBuf)
-		if err != nil {
-			panic(err)
-		}
-		if n != 0 {
-			time.Sleep(time.Second)
-			if _, err := syscall.Write(fd, []byte("scan")); err != nil {
-				panic(err)
-			}
-			n, err := syscall.Read(fd, kmemleakBuf)
-			if err != nil {
-				panic(err)
-			}
-			if n != 0 {
-				// BUG in output should be recognized by manager.
-				logf(0, "BUG: memory leak:\n%s\n", kmemleakBuf[:n])
-			}
-		}
-	}
-	if _, err := syscall.Write(fd, []byte("clear")); err != nil {
-		panic(err)
-	}
-}
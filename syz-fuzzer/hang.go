@@ -0,0 +1,56 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/syzkaller/prog"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Hang detection: the executor kills every program after -program_timeout, a program that
+// does not die within a few seconds after SIGKILL is stuck in the kernel. Such program is not
+// retried (that would only wedge more processes), it is sent to manager (Manager.Hang)
+// and the fuzzer continues with a restarted executor in the same VM.
+// Every hang leaves an unkillable process behind, so the number of reports is limited.
+
+const maxHangs = 20
+
+var (
+	hangMu   sync.Mutex
+	hangSeen = make(map[Sig]bool)
+
+	statHangs uint64
+)
+
+// reportHang sends the program that hanged to manager, note describes how it was executed.
+func reportHang(p *prog.Prog, note string) {
+	atomic.AddUint64(&statHangs, 1)
+	data := p.Serialize()
+	sig := hash(data)
+	hangMu.Lock()
+	if hangSeen[sig] || len(hangSeen) >= maxHangs {
+		hangMu.Unlock()
+		return
+	}
+	hangSeen[sig] = true
+	hangMu.Unlock()
+	if note != "" {
+		note += ", "
+	}
+	note += fmt.Sprintf("timeout %v", *flagTimeout)
+	logf(0, "program hanged (%v):\n%s", note, data)
+	a := &HangArgs{
+		Name: *flagName,
+		Key:  *flagKey,
+		Prog: data,
+		Note: note,
+	}
+	if err := manager.Call("Manager.Hang", a, nil); err != nil {
+		logf(0, "failed to report hang: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/syzkaller/ipc"
+	"github.com/google/syzkaller/prog"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+const (
+	maxBlockedComps   = 100
+	maxBlockedPerPoll = 10
+)
+
+var (
+	blockedMu sync.Mutex
+	blocked   []BlockedProg // sent to manager on the next poll (with -concolic)
+)
+
+// executeHints executes p collecting operands of kernel comparisons and then executes
+// all mutants of p with the observed operands substituted into arguments of call
+// (see prog.MutateWithHints). Mutants with new coverage go to triage as usual.
+func executeHints(pid int, env *ipc.Env, p *prog.Prog, call int) {
+	runSetup(p)
+	idx := gate.Enter()
+	logProgram(pid, env, p, "comparisons")
+	atomic.AddUint64(&statExecHints, 1)
+	output, comps, failed, _, err := env.ExecComps(p)
+	gate.Leave(idx)
+	if failed {
+		logf(0, "BUG: executor-detected bug:\n%s", output)
+		return
+	}
+	if err != nil {
+		logf(0, "failed to collect comparisons: %v", err)
+		return
+	}
+	if comps == nil {
+		return // the executor was restarted
+	}
+	mutants := 0
+	p.MutateWithHints(call, comps[call], func(p1 *prog.Prog) {
+		mutants++
+		execute(pid, env, p1, &statExecHints)
+	})
+	if *flagConcolic && mutants == 0 && len(comps[call]) != 0 {
+		addBlocked(p, call, comps[call])
+	}
+}
+
+// addBlocked queues p for the concolic service: call executes comparisons,
+// but none of the operands matches its arguments.
+func addBlocked(p *prog.Prog, call int, comps prog.CompMap) {
+	b := BlockedProg{Prog: p.Serialize(), Call: call}
+	dup := make(map[[2]uint64]bool)
+	for v, others := range comps {
+		for other := range others {
+			// Most comparisons are recorded in both directions.
+			pair := [2]uint64{uint64(v), uint64(other)}
+			if pair[0] > pair[1] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			if dup[pair] || len(b.Comps) == maxBlockedComps {
+				continue
+			}
+			dup[pair] = true
+			b.Comps = append(b.Comps, pair)
+		}
+	}
+	blockedMu.Lock()
+	if len(blocked) < maxBlockedPerPoll {
+		blocked = append(blocked, b)
+	}
+	blockedMu.Unlock()
+}
@@ -0,0 +1,149 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/syzkaller/prog"
+	"github.com/google/syzkaller/report"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Leak checking: between program batches (with all executors stopped) the fuzzer
+// runs a kmemleak scan every -leak_period, splits the kmemleak report into
+// individual leaks and sends every leak not reported before to manager (Manager.Leak)
+// along with the last executed programs. Leaks don't crash the kernel, so they are not
+// printed to console: that would make manager restart the VM and lose all leaks but the first one.
+
+const maxLeakProgs = 100
+
+var (
+	lastLeakScan = time.Now()
+	leakSeen     = make(map[string]bool) // titles of reported leaks
+
+	leakMu    sync.Mutex
+	leakProgs [][]byte // last executed programs, the most recent last
+
+	kmemleakBuf []byte
+)
+
+func kmemleakInit() {
+	fd, err := syscall.Open("/sys/kernel/debug/kmemleak", syscall.O_RDWR, 0)
+	if err != nil {
+		if *flagLeak {
+			log.Fatalf("BUG: /sys/kernel/debug/kmemleak is missing (%v). Enable CONFIG_KMEMLEAK and mount debugfs.", err)
+		} else {
+			return
+		}
+	}
+	defer syscall.Close(fd)
+	what := "scan=off"
+	if !*flagLeak {
+		what = "off"
+	}
+	if _, err := syscall.Write(fd, []byte(what)); err != nil {
+		// kmemleak returns EBUSY when kmemleak is already turned off.
+		if err != syscall.EBUSY {
+			panic(err)
+		}
+	}
+}
+
+// leakRecord remembers p as one of the last executed programs.
+func leakRecord(p *prog.Prog) {
+	data := p.Serialize()
+	leakMu.Lock()
+	if len(leakProgs) == maxLeakProgs {
+		copy(leakProgs, leakProgs[1:])
+		leakProgs = leakProgs[:maxLeakProgs-1]
+	}
+	leakProgs = append(leakProgs, data)
+	leakMu.Unlock()
+}
+
+// leakCycle runs a kmemleak scan if it is due and reports new leaks.
+// It is called with all executors stopped.
+func leakCycle(period time.Duration) {
+	if time.Since(lastLeakScan) < period {
+		return
+	}
+	start := time.Now()
+	leaks := report.ParseLeaks(kmemleakScan(true))
+	leakMu.Lock()
+	progs := append([][]byte{}, leakProgs...)
+	leakMu.Unlock()
+	for _, leak := range leaks {
+		if leakSeen[leak.Title] {
+			continue
+		}
+		leakSeen[leak.Title] = true
+		logf(0, "kmemleak: %v", leak.Title)
+		a := &LeakArgs{
+			Name:   *flagName,
+			Key:    *flagKey,
+			Title:  leak.Title,
+			Report: leak.Report,
+			Progs:  progs,
+		}
+		if err := manager.Call("Manager.Leak", a, nil); err != nil {
+			logf(0, "failed to report leak: %v", err)
+		}
+	}
+	logf(1, "kmemleak: scan found %v leaks in %v", len(leaks), time.Since(start))
+	lastLeakScan = time.Now()
+}
+
+// kmemleakScan runs kmemleak scans and returns the report (if report is set),
+// the kmemleak state is cleared afterwards.
+func kmemleakScan(report bool) []byte {
+	fd, err := syscall.Open("/sys/kernel/debug/kmemleak", syscall.O_RDWR, 0)
+	if err != nil {
+		panic(err)
+	}
+	defer syscall.Close(fd)
+	// Kmemleak has false positives. To mitigate most of them, it checksums
+	// potentially leaked objects, and reports them only on the next scan
+	// iff the checksum does not change. Because of that we do the following
+	// intricate dance:
+	// Scan, sleep, scan again. At this point we can get some leaks.
+	// If there are leaks, we sleep and scan again, this can remove
+	// false leaks. Then, read kmemleak again. If we get leaks now, then
+	// hopefully these are true positives during the previous testing cycle.
+	if _, err := syscall.Write(fd, []byte("scan")); err != nil {
+		panic(err)
+	}
+	time.Sleep(time.Second)
+	if _, err := syscall.Write(fd, []byte("scan")); err != nil {
+		panic(err)
+	}
+	var res []byte
+	if report {
+		if kmemleakBuf == nil {
+			kmemleakBuf = make([]byte, 128<<10)
+		}
+		n, err := syscall.Read(fd, kmemleakBuf)
+		if err != nil {
+			panic(err)
+		}
+		if n != 0 {
+			time.Sleep(time.Second)
+			if _, err := syscall.Write(fd, []byte("scan")); err != nil {
+				panic(err)
+			}
+			n, err := syscall.Read(fd, kmemleakBuf)
+			if err != nil {
+				panic(err)
+			}
+			res = append(res, kmemleakBuf[:n]...)
+		}
+	}
+	if _, err := syscall.Write(fd, []byte("clear")); err != nil {
+		panic(err)
+	}
+	return res
+}
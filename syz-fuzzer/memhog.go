@@ -0,0 +1,102 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Memory pressure mode: a separate memory hog process keeps most of guest memory
+// allocated and constantly churns it, so that reclaim, swap, writeback and
+// OOM-killer paths are exercised concurrently with fuzzing.
+// The hog is the preferred OOM victim and is restarted when killed.
+
+const (
+	hogChunk   = 1 << 20
+	hogPercent = 90 // percent of total memory the hog tries to keep allocated
+)
+
+// startMemhog starts the memory hog process and restarts it whenever it dies.
+func startMemhog() {
+	go func() {
+		for {
+			cmd := exec.Command(os.Args[0], "-memhog")
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				logf(1, "memory hog exited: %v", err)
+			}
+			time.Sleep(time.Second)
+		}
+	}()
+}
+
+// runMemhog is the body of the memory hog process.
+func runMemhog() {
+	if err := ioutil.WriteFile("/proc/self/oom_score_adj", []byte("1000"), 0); err != nil {
+		logf(0, "memory hog: failed to adjust oom score: %v", err)
+	}
+	total, err := memTotal()
+	if err != nil {
+		logf(0, "memory hog: %v", err)
+		os.Exit(1)
+	}
+	target := int(total * hogPercent / 100 / hogChunk)
+	logf(0, "memory hog: keeping %v MB allocated", target)
+	var chunks [][]byte
+	for {
+		for len(chunks) < target {
+			mem, err := syscall.Mmap(-1, 0, hogChunk, syscall.PROT_READ|syscall.PROT_WRITE,
+				syscall.MAP_ANON|syscall.MAP_PRIVATE)
+			if err != nil {
+				break
+			}
+			for off := 0; off < hogChunk; off += 4 << 10 {
+				mem[off] = 1
+			}
+			chunks = append(chunks, mem)
+		}
+		// Touch random pages to force reclaim of other pages, then free some chunks.
+		for i := 0; len(chunks) != 0 && i < len(chunks)*16; i++ {
+			mem := chunks[rand.Intn(len(chunks))]
+			mem[rand.Intn(hogChunk)]++
+		}
+		for i := 0; i < len(chunks)/4; i++ {
+			idx := rand.Intn(len(chunks))
+			syscall.Munmap(chunks[idx])
+			chunks[idx] = chunks[len(chunks)-1]
+			chunks = chunks[:len(chunks)-1]
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func memTotal() (uint64, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open /proc/meminfo: %v", err)
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse MemTotal: %v", err)
+			}
+			return kb << 10, nil
+		}
+	}
+	return 0, fmt.Errorf("no MemTotal in /proc/meminfo")
+}
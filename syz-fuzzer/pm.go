@@ -0,0 +1,66 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// Power management cycling: between program batches the fuzzer periodically
+// freezes all tasks or suspends the whole machine and resumes it,
+// to catch races between fuzzed kernel code and power management.
+// Manager recognizes the pm log lines and does not treat the silence
+// during the cycle as a hang.
+
+var lastPmCycle = time.Now()
+
+func pmCheck(mode string) error {
+	switch mode {
+	case "none":
+		return nil
+	case "freezer", "suspend":
+		if _, err := ioutil.ReadFile("/sys/power/state"); err != nil {
+			return fmt.Errorf("power management is not supported: %v", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown pm mode '%v', want none/freezer/suspend", mode)
+	}
+}
+
+// pmCycle runs a single power management cycle if it is due.
+// It is called with all executors stopped.
+func pmCycle(mode string, period time.Duration) {
+	if mode == "none" || time.Since(lastPmCycle) < period {
+		return
+	}
+	logf(0, "pm: suspending (%v)", mode)
+	start := time.Now()
+	var err error
+	switch mode {
+	case "freezer":
+		// Freeze and thaw all tasks without actually suspending devices.
+		if err = writeFile("/sys/power/pm_test", "freezer"); err == nil {
+			err = writeFile("/sys/power/state", "freeze")
+			writeFile("/sys/power/pm_test", "none")
+		}
+	case "suspend":
+		// Suspend-to-idle, wake up by RTC alarm.
+		writeFile("/sys/class/rtc/rtc0/wakealarm", "0")
+		if err = writeFile("/sys/class/rtc/rtc0/wakealarm", "+5"); err == nil {
+			err = writeFile("/sys/power/state", "freeze")
+		}
+	}
+	if err != nil {
+		logf(0, "pm: cycle failed: %v", err)
+	}
+	logf(0, "pm: resumed after %v", time.Since(start))
+	lastPmCycle = time.Now()
+}
+
+func writeFile(file, data string) error {
+	return ioutil.WriteFile(file, []byte(data), 0)
+}
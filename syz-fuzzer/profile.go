@@ -0,0 +1,76 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/syzkaller/ipc"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// Fuzzer accounts time spent in every phase of the fuzzing loop and reports it
+// as "time PHASE" stats (in microseconds), manager shows shares of the phases,
+// which helps to understand why exec/sec is low on a particular target.
+type phase int
+
+const (
+	phaseGenerate phase = iota
+	phaseMutate
+	phaseSerialize // includes program logging
+	phaseExec      // waiting for executor
+	phaseCover     // coverage processing
+	phaseCount
+)
+
+var phaseNames = [phaseCount]string{"generate", "mutate", "serialize", "exec", "cover"}
+
+var phaseTimes [phaseCount]uint64 // nanoseconds, updated atomically
+
+// timePhase accounts time since start to phase ph.
+func timePhase(ph phase, start time.Time) {
+	atomic.AddUint64(&phaseTimes[ph], uint64(time.Since(start)))
+}
+
+// phaseStats moves accumulated phase times to stats, envs account serialization and execution.
+func phaseStats(stats map[string]uint64, envs []*ipc.Env) {
+	for _, env := range envs {
+		atomic.AddUint64(&phaseTimes[phaseSerialize], atomic.SwapUint64(&env.StatSerializeTime, 0))
+		atomic.AddUint64(&phaseTimes[phaseExec], atomic.SwapUint64(&env.StatExecTime, 0))
+	}
+	for ph, name := range phaseNames {
+		stats["time "+name] = atomic.SwapUint64(&phaseTimes[ph], 0) / 1e3
+	}
+}
+
+const cpuProfileTime = 10 * time.Second
+
+// sendProfile collects pprof profile of the given kind (cpu, heap, goroutine, threadcreate)
+// requested from the manager web UI and sends it to the manager.
+func sendProfile(kind string) {
+	a := &ProfileArgs{Name: *flagName, Key: *flagKey, Kind: kind}
+	buf := new(bytes.Buffer)
+	if kind == "cpu" {
+		if err := pprof.StartCPUProfile(buf); err != nil {
+			a.Error = err.Error()
+		} else {
+			time.Sleep(cpuProfileTime)
+			pprof.StopCPUProfile()
+		}
+	} else if prof := pprof.Lookup(kind); prof != nil {
+		if err := prof.WriteTo(buf, 0); err != nil {
+			a.Error = err.Error()
+		}
+	} else {
+		a.Error = fmt.Sprintf("unknown profile %q", kind)
+	}
+	a.Data = buf.Bytes()
+	if err := manager.Call("Manager.Profile", a, nil); err != nil {
+		logf(0, "failed to send %v profile: %v", kind, err)
+	}
+}
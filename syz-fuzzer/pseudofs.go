@@ -0,0 +1,64 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// pseudoFiles are writable procfs/sysfs/debugfs files found in the VM,
+// they are used as filename[pseudofs] arguments (see sys/pseudofs.txt).
+var pseudoFiles []string
+
+var pseudofsRoots = []string{"/proc/sys", "/sys"}
+
+const maxPseudoFiles = 20000
+
+// pseudofsDenied are knobs that kill, hang, reboot or disconnect the machine
+// (or just make it useless for fuzzing). They are not fuzzed without -dangerous.
+var pseudofsDenied = regexp.MustCompile(`^(` +
+	`/proc/sys/kernel/(panic.*|sysrq|core_pattern|core_pipe_limit|modprobe|poweroff_cmd|hotplug|usermodehelper/.*|` +
+	`printk.*|hung_task.*|watchdog.*|soft_watchdog|nmi_watchdog|softlockup.*|hardlockup.*|` +
+	`kptr_restrict|dmesg_restrict|kexec_load_disabled|ctrl-alt-del|cad_pid)|` +
+	`/proc/sys/debug/exception-trace|` +
+	`/proc/sys/vm/(panic_on_oom|overcommit.*|min_free_kbytes|nr_hugepages.*|drop_caches)|` +
+	`/proc/sys/net/ipv[46]/conf/(all|default|eth0)/.*|` +
+	`/proc/sysrq-trigger|` +
+	`/sys/power/.*|` +
+	`/sys/kernel/debug/(kcov|kmemleak|tracing/.*|fail.*|provoke-crash/.*)|` +
+	`/sys/kernel/(kexec.*|uevent_helper|reboot/.*)|` +
+	`/sys/firmware/.*|` +
+	`/sys/module/[^/]+/parameters/printk.*|` +
+	`/sys/devices/system/(cpu/cpu[0-9]+/online|memory/.*)|` +
+	`/sys/class/net/eth0/.*|/sys/devices/.*/net/eth0/.*|` +
+	`.*/(bind|unbind|new_id|remove_id|remove|rescan|delete|reset|shutdown)` +
+	`)$`)
+
+// findPseudoFiles walks pseudo filesystems and returns writable regular files.
+// Symlinks are not followed, so every file is found at most once.
+func findPseudoFiles(dangerous bool) []string {
+	var files []string
+	for _, root := range pseudofsRoots {
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if len(files) >= maxPseudoFiles {
+				return filepath.SkipDir
+			}
+			if !info.Mode().IsRegular() || info.Mode().Perm()&0222 == 0 {
+				return nil
+			}
+			if !dangerous && pseudofsDenied.MatchString(path) {
+				return nil
+			}
+			files = append(files, path)
+			return nil
+		})
+	}
+	logf(0, "found %v writable pseudo files", len(files))
+	return files
+}
@@ -0,0 +1,61 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/prog"
+	. "github.com/google/syzkaller/rpctype"
+)
+
+// setupAction is a config-defined setup command (e.g. "modprobe gadgetfs").
+// It is run lazily once per VM before the first program that needs it.
+// The result is cached, a failed action is not retried.
+type setupAction struct {
+	name    string
+	command string
+	once    sync.Once
+}
+
+// setupActions maps call ID to actions required by the call.
+var setupActions map[int][]*setupAction
+
+func initSetup(actions []SetupAction) {
+	setupActions = make(map[int][]*setupAction)
+	for _, a := range actions {
+		action := &setupAction{name: a.Name, command: a.Command}
+		for _, id := range a.Calls {
+			setupActions[id] = append(setupActions[id], action)
+		}
+	}
+}
+
+// runSetup runs all setup actions required by calls in p that were not run yet.
+func runSetup(p *prog.Prog) {
+	if len(setupActions) == 0 {
+		return
+	}
+	for _, c := range p.Calls {
+		for _, a := range setupActions[c.Meta.ID] {
+			a.once.Do(a.run)
+		}
+	}
+}
+
+func (a *setupAction) run() {
+	cmd := exec.Command("sh", "-c", a.command)
+	timer := time.AfterFunc(time.Minute, func() {
+		cmd.Process.Kill()
+	})
+	out, err := cmd.CombinedOutput()
+	timer.Stop()
+	if err != nil {
+		logf(0, "setup %v failed: %v\n%s", a.name, err, out)
+		return
+	}
+	logf(0, "setup %v done", a.name)
+}
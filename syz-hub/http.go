@@ -0,0 +1,111 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+func (hub *Hub) initHttp(addr string) {
+	if addr == "" {
+		return
+	}
+	http.HandleFunc("/", hub.httpSummary)
+	log.Printf("serving http on http://%v", addr)
+	go http.ListenAndServe(addr, nil)
+}
+
+func (hub *Hub) httpSummary(w http.ResponseWriter, r *http.Request) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	data := &UISummaryData{
+		CorpusSize: len(hub.st.Corpus.Records),
+		Repros:     len(hub.st.Repros.Records),
+	}
+	for name, mgr := range hub.st.Managers {
+		ui := UIManager{
+			Name:       name,
+			Corpus:     len(mgr.Corpus.Records),
+			Added:      mgr.Added,
+			Deleted:    mgr.Deleted,
+			New:        mgr.New,
+			SentRepros: mgr.SentRepros,
+			RecvRepros: mgr.RecvRepros,
+		}
+		if !mgr.Connected.IsZero() {
+			ui.Connected = time.Since(mgr.Connected) - time.Since(mgr.Connected)%time.Second
+		}
+		data.Managers = append(data.Managers, ui)
+	}
+	sort.Sort(UIManagerArray(data.Managers))
+	if err := summaryTemplate.Execute(w, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+	}
+}
+
+type UISummaryData struct {
+	CorpusSize int
+	Repros     int
+	Managers   []UIManager
+}
+
+type UIManager struct {
+	Name       string
+	Connected  time.Duration
+	Corpus     int
+	Added      int
+	Deleted    int
+	New        int
+	SentRepros int
+	RecvRepros int
+}
+
+type UIManagerArray []UIManager
+
+func (a UIManagerArray) Len() int           { return len(a) }
+func (a UIManagerArray) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a UIManagerArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+var summaryTemplate = template.Must(template.New("").Parse(`
+<!doctype html>
+<html>
+<head>
+    <title>syz-hub</title>
+</head>
+<body>
+Corpus: {{.CorpusSize}} <br>
+Reproducers: {{.Repros}} <br>
+<br>
+<table>
+	<tr>
+		<th>Manager</th>
+		<th>Connected</th>
+		<th>Corpus</th>
+		<th>Added</th>
+		<th>Deleted</th>
+		<th>New</th>
+		<th>Repros sent</th>
+		<th>Repros received</th>
+	</tr>
+	{{range $m := $.Managers}}
+	<tr>
+		<td>{{$m.Name}}</td>
+		<td>{{if $m.Connected}}{{$m.Connected}} ago{{else}}never{{end}}</td>
+		<td>{{$m.Corpus}}</td>
+		<td>{{$m.Added}}</td>
+		<td>{{$m.Deleted}}</td>
+		<td>{{$m.New}}</td>
+		<td>{{$m.SentRepros}}</td>
+		<td>{{$m.RecvRepros}}</td>
+	</tr>
+	{{end}}
+</table>
+</body></html>
+`))
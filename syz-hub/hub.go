@@ -0,0 +1,131 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-hub exchanges corpus programs and reproducers between several syz-manager instances
+// (e.g. fuzzing different kernels or with different configs).
+// Managers connect to the hub with a per-manager key listed in the hub config,
+// periodically upload new corpus programs and download programs found by other managers.
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+
+	"github.com/google/syzkaller/config"
+	. "github.com/google/syzkaller/rpctype"
+	"github.com/google/syzkaller/syz-hub/state"
+)
+
+var (
+	flagConfig = flag.String("config", "", "config file")
+)
+
+type Config struct {
+	Http     string // HTTP address to serve status page on
+	Rpc      string // RPC address to serve managers on
+	Workdir  string
+	Managers []config.ManagerKey
+}
+
+type Hub struct {
+	mu   sync.Mutex
+	st   *state.State
+	keys config.ManagerKeys
+}
+
+// maxSyncInputs is the maximum number of programs sent to a manager in one sync.
+const maxSyncInputs = 1000
+
+func main() {
+	flag.Parse()
+	cfg := readConfig(*flagConfig)
+
+	st, err := state.Make(cfg.Workdir)
+	if err != nil {
+		log.Fatalf("failed to load state: %v", err)
+	}
+	keys, err := config.MakeManagerKeys(cfg.Managers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	hub := &Hub{
+		st:   st,
+		keys: keys,
+	}
+
+	hub.initHttp(cfg.Http)
+
+	ln, err := net.Listen("tcp", cfg.Rpc)
+	if err != nil {
+		log.Fatalf("failed to listen on %v: %v", cfg.Rpc, err)
+	}
+	log.Printf("serving rpc on tcp://%v", ln.Addr())
+	s := rpc.NewServer()
+	s.Register(hub)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Printf("failed to accept an rpc connection: %v", err)
+			continue
+		}
+		go s.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+func (hub *Hub) Connect(a *HubConnectArgs, r *int) error {
+	if err := hub.keys.Auth(a.Name, a.Key); err != nil {
+		return err
+	}
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	log.Printf("connect from %v: fresh=%v calls=%v corpus=%v", a.Name, a.Fresh, len(a.Calls), len(a.Corpus))
+	if err := hub.st.Connect(a.Name, a.Fresh, a.Calls, a.Corpus); err != nil {
+		log.Printf("connect %v failed: %v", a.Name, err)
+		return err
+	}
+	return nil
+}
+
+func (hub *Hub) Sync(a *HubSyncArgs, r *HubSyncRes) error {
+	if err := hub.keys.Auth(a.Name, a.Key); err != nil {
+		return err
+	}
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	inputs, repros, err := hub.st.Sync(a.Name, a.Add, a.Del, a.Repros, maxSyncInputs)
+	if err != nil {
+		log.Printf("sync %v failed: %v", a.Name, err)
+		return err
+	}
+	r.Inputs = inputs
+	r.Repros = repros
+	for _, inp := range inputs {
+		r.Sources = append(r.Sources, hub.st.Source(inp))
+	}
+	for _, repro := range repros {
+		r.ReproSources = append(r.ReproSources, hub.st.Source(repro))
+	}
+	log.Printf("sync from %v: add=%v del=%v repros=%v new=%v new repros=%v",
+		a.Name, len(a.Add), len(a.Del), len(a.Repros), len(inputs), len(repros))
+	return nil
+}
+
+func readConfig(filename string) *Config {
+	cfg := new(Config)
+	if err := config.LoadFile(filename, cfg); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Rpc == "" {
+		log.Fatalf("config param rpc is empty")
+	}
+	if cfg.Workdir == "" {
+		log.Fatalf("config param workdir is empty")
+	}
+	return cfg
+}
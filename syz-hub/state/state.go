@@ -0,0 +1,358 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package state implements persistent state of syz-hub:
+// the union corpus of all managers, reproducers and per-manager sync progress.
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/db"
+	"github.com/google/syzkaller/fileutil"
+)
+
+// State describes the dir layout:
+//
+//	dir/corpus.db - union corpus of all managers, record Seq is the sync sequence number
+//	dir/repro.db - reproducers, record Desc is the name of the manager that sent it
+//	dir/manager/NAME/corpus.db - hashes of programs owned by manager NAME,
+//		db version is the last corpus sequence number sent to the manager
+//	dir/manager/NAME/repro_seq - the last repro sequence number sent to the manager
+type State struct {
+	dir      string
+	Corpus   *db.DB
+	Repros   *db.DB
+	Managers map[string]*Manager
+}
+
+// Manager represents one syz-manager instance.
+type Manager struct {
+	name       string
+	dir        string
+	Connected  time.Time
+	Added      int             // number of programs added by the manager
+	Deleted    int             // number of programs deleted by the manager
+	New        int             // number of programs sent to the manager
+	SentRepros int             // number of reproducers sent to the manager
+	RecvRepros int             // number of reproducers received from the manager
+	Calls      map[string]bool // enabled syscalls of the manager (empty means all)
+	Corpus     *db.DB
+	reproSeq   uint64
+}
+
+// Make creates State and initializes it from dir.
+func Make(dir string) (*State, error) {
+	st := &State{
+		dir:      dir,
+		Managers: make(map[string]*Manager),
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "manager"), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state dir: %v", err)
+	}
+	var err error
+	if st.Corpus, err = db.Open(filepath.Join(dir, "corpus.db")); err != nil {
+		return nil, err
+	}
+	if st.Repros, err = db.Open(filepath.Join(dir, "repro.db")); err != nil {
+		return nil, err
+	}
+	managers, err := ioutil.ReadDir(filepath.Join(dir, "manager"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manager dir: %v", err)
+	}
+	for _, fi := range managers {
+		if !fi.IsDir() {
+			continue
+		}
+		if _, err := st.createManager(fi.Name()); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// Connect registers a (re)connecting manager. If fresh is set, the manager
+// has lost its corpus and is sent the whole union corpus again.
+// corpus is the whole current corpus of the manager.
+func (st *State) Connect(name string, fresh bool, calls []string, corpus [][]byte) error {
+	mgr := st.Managers[name]
+	if mgr == nil {
+		var err error
+		mgr, err = st.createManager(name)
+		if err != nil {
+			return err
+		}
+	}
+	mgr.Connected = time.Now()
+	if fresh {
+		if err := mgr.Corpus.BumpVersion(0); err != nil {
+			return err
+		}
+		mgr.reproSeq = 0
+		if err := mgr.saveReproSeq(); err != nil {
+			return err
+		}
+	}
+	mgr.Calls = make(map[string]bool)
+	for _, c := range calls {
+		mgr.Calls[c] = true
+	}
+	// Replace the set of programs owned by the manager.
+	keep := make(map[string]bool)
+	for _, prog := range corpus {
+		sig := hash(prog)
+		keep[sig] = true
+		if err := st.addInput(mgr, sig, prog); err != nil {
+			return err
+		}
+	}
+	for key := range mgr.Corpus.Records {
+		if !keep[key] {
+			if err := mgr.Corpus.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	if err := mgr.Corpus.Compact(); err != nil {
+		return err
+	}
+	return st.purgeCorpus()
+}
+
+// Sync applies corpus changes of the manager and returns
+// new programs and reproducers from other managers.
+func (st *State) Sync(name string, add [][]byte, del []string, repros [][]byte, max int) ([][]byte, [][]byte, error) {
+	mgr := st.Managers[name]
+	if mgr == nil || mgr.Connected.IsZero() {
+		return nil, nil, fmt.Errorf("unconnected manager %v", name)
+	}
+	for _, prog := range add {
+		if err := st.addInput(mgr, hash(prog), prog); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, sig := range del {
+		if _, ok := mgr.Corpus.Records[sig]; !ok {
+			continue
+		}
+		if err := mgr.Corpus.Delete(sig); err != nil {
+			return nil, nil, err
+		}
+		mgr.Deleted++
+	}
+	for _, repro := range repros {
+		sig := hash(repro)
+		if _, ok := st.Repros.Records[sig]; ok {
+			continue
+		}
+		if err := st.Repros.BumpVersion(st.Repros.Version + 1); err != nil {
+			return nil, nil, err
+		}
+		if err := st.Repros.Save(sig, db.Record{Val: repro, Desc: name}); err != nil {
+			return nil, nil, err
+		}
+		mgr.RecvRepros++
+	}
+	progs, err := st.pendingInputs(mgr, max)
+	if err != nil {
+		return nil, nil, err
+	}
+	newRepros, err := st.pendingRepros(mgr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mgr.Corpus.Stale() > len(mgr.Corpus.Records)+1000 {
+		if err := mgr.Corpus.Compact(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return progs, newRepros, nil
+}
+
+// addInput adds prog to the union corpus (if it is not there yet)
+// and marks it as owned by mgr. Programs that are already in the union corpus
+// keep their sequence number, so they are not redistributed.
+func (st *State) addInput(mgr *Manager, sig string, prog []byte) error {
+	if _, ok := st.Corpus.Records[sig]; !ok {
+		if err := st.Corpus.BumpVersion(st.Corpus.Version + 1); err != nil {
+			return err
+		}
+		if err := st.Corpus.Save(sig, db.Record{Val: prog, Desc: mgr.name}); err != nil {
+			return err
+		}
+	}
+	if _, ok := mgr.Corpus.Records[sig]; !ok {
+		if err := mgr.Corpus.Save(sig, db.Record{}); err != nil {
+			return err
+		}
+		mgr.Added++
+	}
+	return nil
+}
+
+// pendingInputs returns up to max programs the manager has not seen yet.
+func (st *State) pendingInputs(mgr *Manager, max int) ([][]byte, error) {
+	seq := mgr.Corpus.Version
+	var progs [][]byte
+	var records []db.Record
+	for sig, rec := range st.Corpus.Records {
+		if rec.Seq <= seq {
+			continue
+		}
+		if _, ok := mgr.Corpus.Records[sig]; ok {
+			continue
+		}
+		records = append(records, rec)
+	}
+	sort.Sort(recordSeqArray(records))
+	maxSeq := st.Corpus.Version
+	for _, rec := range records {
+		if max > 0 && len(progs) >= max {
+			maxSeq = rec.Seq - 1
+			break
+		}
+		if !mgr.supports(rec.Val) {
+			continue
+		}
+		progs = append(progs, rec.Val)
+	}
+	if err := mgr.Corpus.BumpVersion(maxSeq); err != nil {
+		return nil, err
+	}
+	mgr.New += len(progs)
+	return progs, nil
+}
+
+func (st *State) pendingRepros(mgr *Manager) ([][]byte, error) {
+	var repros [][]byte
+	for _, rec := range st.Repros.Records {
+		if rec.Seq <= mgr.reproSeq || rec.Desc == mgr.name || !mgr.supports(rec.Val) {
+			continue
+		}
+		repros = append(repros, rec.Val)
+	}
+	if mgr.reproSeq != st.Repros.Version {
+		mgr.reproSeq = st.Repros.Version
+		if err := mgr.saveReproSeq(); err != nil {
+			return nil, err
+		}
+	}
+	mgr.SentRepros += len(repros)
+	return repros, nil
+}
+
+// Source returns name of the manager that added prog to the union corpus
+// or sent it as a reproducer ("" for unknown programs).
+func (st *State) Source(prog []byte) string {
+	sig := hash(prog)
+	if rec, ok := st.Corpus.Records[sig]; ok {
+		return rec.Desc
+	}
+	if rec, ok := st.Repros.Records[sig]; ok {
+		return rec.Desc
+	}
+	return ""
+}
+
+// purgeCorpus removes programs that are not owned by any manager.
+func (st *State) purgeCorpus() error {
+	used := make(map[string]bool)
+	for _, mgr := range st.Managers {
+		for sig := range mgr.Corpus.Records {
+			used[sig] = true
+		}
+	}
+	for sig := range st.Corpus.Records {
+		if !used[sig] {
+			if err := st.Corpus.Delete(sig); err != nil {
+				return err
+			}
+		}
+	}
+	return st.Corpus.Compact()
+}
+
+func (st *State) createManager(name string) (*Manager, error) {
+	dir := filepath.Join(st.dir, "manager", name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create manager dir: %v", err)
+	}
+	mgr := &Manager{
+		name: name,
+		dir:  dir,
+	}
+	var err error
+	if mgr.Corpus, err = db.Open(filepath.Join(dir, "corpus.db")); err != nil {
+		return nil, err
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "repro_seq")); err == nil {
+		mgr.reproSeq, _ = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	}
+	st.Managers[name] = mgr
+	return mgr, nil
+}
+
+func (mgr *Manager) saveReproSeq() error {
+	data := []byte(fmt.Sprintf("%v\n", mgr.reproSeq))
+	// A torn file would read as 0 and make the hub resend all repros to the manager.
+	if err := fileutil.WriteFileAtomic(filepath.Join(mgr.dir, "repro_seq"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write repro seq: %v", err)
+	}
+	return nil
+}
+
+// supports returns true if all calls of the program are enabled on the manager.
+func (mgr *Manager) supports(prog []byte) bool {
+	if len(mgr.Calls) == 0 {
+		return true
+	}
+	for _, c := range callNames(prog) {
+		if !mgr.Calls[c] {
+			return false
+		}
+	}
+	return true
+}
+
+// callNames extracts syscall names from a serialized program without deserializing it,
+// so that the hub does not depend on descriptions matching the managers' ones.
+func callNames(prog []byte) []string {
+	var calls []string
+	s := bufio.NewScanner(bytes.NewReader(prog))
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if eq := strings.Index(line, " = "); eq != -1 && eq < strings.IndexByte(line, '(') {
+			line = line[eq+3:]
+		}
+		if paren := strings.IndexByte(line, '('); paren > 0 {
+			calls = append(calls, line[:paren])
+		}
+	}
+	return calls
+}
+
+type recordSeqArray []db.Record
+
+func (a recordSeqArray) Len() int           { return len(a) }
+func (a recordSeqArray) Less(i, j int) bool { return a[i].Seq < a[j].Seq }
+func (a recordSeqArray) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+func hash(data []byte) string {
+	sig := sha1.Sum(data)
+	return hex.EncodeToString(sig[:])
+}
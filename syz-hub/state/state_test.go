@@ -0,0 +1,196 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+)
+
+func tempState(t *testing.T) (*State, string, func()) {
+	dir, err := ioutil.TempDir("", "syz-hub-state")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %v", err)
+	}
+	st, err := Make(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to make state: %v", err)
+	}
+	return st, dir, func() { os.RemoveAll(dir) }
+}
+
+func progs(data [][]byte) []string {
+	var res []string
+	for _, p := range data {
+		res = append(res, string(p))
+	}
+	sort.Strings(res)
+	return res
+}
+
+func TestSync(t *testing.T) {
+	st, dir, cleanup := tempState(t)
+	defer cleanup()
+	if err := st.Connect("m0", true, nil, [][]byte{[]byte("getpid()\n")}); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	if err := st.Connect("m1", true, []string{"getpid", "open"}, nil); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	inputs, _, err := st.Sync("m1", nil, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := progs(inputs); len(got) != 1 || got[0] != "getpid()\n" {
+		t.Fatalf("got bad inputs: %q", got)
+	}
+	// m1 sends back the same program (dedup) and adds new ones,
+	// one of which uses a syscall that is not enabled on m1 but is on m0.
+	add := [][]byte{
+		[]byte("getpid()\n"),
+		[]byte("r0 = open(&(0x7f0000000000)=\"2e00\", 0x0, 0x0)\nclose(r0)\n"),
+	}
+	if _, _, err := st.Sync("m1", add, nil, [][]byte{[]byte("open(0x0, 0x0, 0x0)\n")}, 0); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	inputs, repros, err := st.Sync("m0", nil, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := progs(inputs); len(got) != 1 || got[0] != string(add[1]) {
+		t.Fatalf("got bad inputs: %q", got)
+	}
+	if got := progs(repros); len(got) != 1 || got[0] != "open(0x0, 0x0, 0x0)\n" {
+		t.Fatalf("got bad repros: %q", got)
+	}
+	// Nothing new for anybody now.
+	for _, name := range []string{"m0", "m1"} {
+		inputs, repros, err := st.Sync(name, nil, nil, nil, 0)
+		if err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+		if len(inputs) != 0 || len(repros) != 0 {
+			t.Fatalf("%v: got %v inputs and %v repros, want none", name, len(inputs), len(repros))
+		}
+	}
+	// m2 has only getpid enabled.
+	if err := st.Connect("m2", false, []string{"getpid"}, nil); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	inputs, repros, err = st.Sync("m2", nil, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := progs(inputs); len(got) != 1 || got[0] != "getpid()\n" || len(repros) != 0 {
+		t.Fatalf("got bad inputs: %q, repros: %q", got, progs(repros))
+	}
+
+	// Sync progress must survive restart, and reconnect must not resend the corpus.
+	st.Corpus.Close()
+	st.Repros.Close()
+	for _, mgr := range st.Managers {
+		mgr.Corpus.Close()
+	}
+	st, err = Make(dir)
+	if err != nil {
+		t.Fatalf("failed to make state: %v", err)
+	}
+	if err := st.Connect("m0", false, nil, [][]byte{[]byte("getpid()\n")}); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	inputs, repros, err = st.Sync("m0", nil, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if len(inputs) != 0 || len(repros) != 0 {
+		t.Fatalf("got %v inputs and %v repros after restart, want none", len(inputs), len(repros))
+	}
+	// Fresh reconnect resends everything.
+	if err := st.Connect("m0", true, nil, nil); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	inputs, _, err = st.Sync("m0", nil, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if len(inputs) != 2 {
+		t.Fatalf("got %v inputs after fresh connect, want 2", len(inputs))
+	}
+}
+
+func TestSyncLimit(t *testing.T) {
+	st, _, cleanup := tempState(t)
+	defer cleanup()
+	var corpus [][]byte
+	for i := 0; i < 10; i++ {
+		corpus = append(corpus, []byte{'a' + byte(i), '(', ')', '\n'})
+	}
+	if err := st.Connect("m0", true, nil, corpus); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	if err := st.Connect("m1", true, nil, nil); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	var all [][]byte
+	for i := 0; i < 4; i++ {
+		inputs, _, err := st.Sync("m1", nil, nil, nil, 3)
+		if err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+		if len(inputs) > 3 {
+			t.Fatalf("got %v inputs, want at most 3", len(inputs))
+		}
+		all = append(all, inputs...)
+	}
+	if got, want := progs(all), progs(corpus); len(got) != len(want) {
+		t.Fatalf("got %v inputs in total, want %v", len(got), len(want))
+	}
+}
+
+func TestSource(t *testing.T) {
+	st, _, cleanup := tempState(t)
+	defer cleanup()
+	if err := st.Connect("m0", true, nil, [][]byte{[]byte("getpid()\n")}); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	if err := st.Connect("m1", true, nil, nil); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	if _, _, err := st.Sync("m1", [][]byte{[]byte("getpid()\n"), []byte("getuid()\n")}, nil,
+		[][]byte{[]byte("getgid()\n")}, 0); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	tests := map[string]string{
+		"getpid()\n": "m0", // m1 sent it later
+		"getuid()\n": "m1",
+		"getgid()\n": "m1",
+		"gettid()\n": "",
+	}
+	for prog, want := range tests {
+		if got := st.Source([]byte(prog)); got != want {
+			t.Errorf("Source(%q) = %q, want %q", prog, got, want)
+		}
+	}
+}
+
+func TestCallNames(t *testing.T) {
+	prog := "mmap(&(0x7f0000000000/0x1000)=nil, (0x1000), 0x3, 0x32, 0xffffffffffffffff, 0x0)\n" +
+		"r0 = socket$inet6(0xa, 0x1, 0x0)\n" +
+		"\n" +
+		"# comment\n" +
+		"write(r0, &(0x7f0000000000)=\"3d3d\", 0x2)\n"
+	got := callNames([]byte(prog))
+	want := []string{"mmap", "socket$inet6", "write"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2015 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/manager"
+)
+
+var (
+	flagConfig = flag.String("config", "", "configuration file")
+	flagV      = flag.Int("v", 0, "verbosity")
+	flagDebug  = flag.Bool("debug", false, "dump all VM output to console")
+	flagBench  = flag.String("bench", "", "write machine-readable progress records to this file")
+	flagBenchP = flag.Duration("bench_period", time.Minute, "period of -bench records")
+	flagSeed   = flag.Int64("seed", 0, "seed for fuzzer random number generators, for reproducible sessions (0 - random)")
+)
+
+func main() {
+	flag.Parse()
+	cfg, syscalls, suppressions, err := config.Parse(*flagConfig)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *flagDebug {
+		cfg.Debug = true
+		cfg.Count = 1
+	}
+	c, err := manager.Start(cfg, syscalls, suppressions, &manager.Options{
+		Verbosity:   *flagV,
+		ConfigFile:  *flagConfig,
+		Bench:       *flagBench,
+		BenchPeriod: *flagBenchP,
+		Signals:     true,
+		Reexec:      true,
+		Seed:        *flagSeed,
+	})
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := c.Wait(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-db works with corpus database files (workdir/corpus.db):
+// packs a directory of programs into a database, unpacks a database
+// into a directory, lists database contents and merges corpora
+// (e.g. a corpus_namespace corpus into the main one). Reading is safe
+// while the database is open by syz-manager, merge requires the destination
+// manager to be stopped.
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/syzkaller/db"
+)
+
+func main() {
+	if len(os.Args) != 4 && !(len(os.Args) == 3 && os.Args[1] == "list") &&
+		!(len(os.Args) > 4 && os.Args[1] == "merge") {
+		usage()
+	}
+	switch os.Args[1] {
+	case "pack":
+		pack(os.Args[2], os.Args[3])
+	case "unpack":
+		unpack(os.Args[2], os.Args[3])
+	case "list":
+		list(os.Args[2])
+	case "merge":
+		merge(os.Args[2], os.Args[3:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage:\n")
+	fmt.Fprintf(os.Stderr, "  syz-db pack dir corpus.db\n")
+	fmt.Fprintf(os.Stderr, "  syz-db unpack corpus.db dir\n")
+	fmt.Fprintf(os.Stderr, "  syz-db list corpus.db\n")
+	fmt.Fprintf(os.Stderr, "  syz-db merge corpus.db corpus-NAME.db...\n")
+	os.Exit(1)
+}
+
+func pack(dir, file string) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		fatalf("failed to read dir: %v", err)
+	}
+	records := make(map[string]db.Record)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			fatalf("failed to read file: %v", err)
+		}
+		sig := sha1.Sum(data)
+		records[hex.EncodeToString(sig[:])] = db.Record{Val: data, Time: f.ModTime()}
+	}
+	if err := db.Create(file, 0, records); err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Printf("packed %v programs\n", len(records))
+}
+
+func unpack(file, dir string) {
+	corpusDB, err := db.ReadRecords(file)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		fatalf("failed to create dir: %v", err)
+	}
+	for key, rec := range corpusDB.Records {
+		if err := ioutil.WriteFile(filepath.Join(dir, key), rec.Val, 0640); err != nil {
+			fatalf("failed to write file: %v", err)
+		}
+	}
+	fmt.Printf("unpacked %v programs\n", len(corpusDB.Records))
+}
+
+func list(file string) {
+	corpusDB, err := db.ReadRecords(file)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	var keys []string
+	for key := range corpusDB.Records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Printf("version %v, %v records, %v stale\n", corpusDB.Version, len(keys), corpusDB.Stale())
+	for _, key := range keys {
+		rec := corpusDB.Records[key]
+		fmt.Printf("%v seq=%v signal=%v size=%v time=%v\n",
+			key, rec.Seq, rec.Signal, len(rec.Val), rec.Time.Format(time.RFC3339))
+	}
+}
+
+// merge adds programs from srcs that are missing in dst to dst.
+// Merged programs are re-triaged by syz-manager on the next start,
+// so their signal is recomputed with the destination configuration.
+func merge(dst string, srcs []string) {
+	dstDB, err := db.Open(dst)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	added := 0
+	for _, src := range srcs {
+		srcDB, err := db.ReadRecords(src)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		for key, rec := range srcDB.Records {
+			if _, ok := dstDB.Records[key]; ok {
+				continue
+			}
+			if err := dstDB.Save(key, db.Record{Val: rec.Val, Time: rec.Time}); err != nil {
+				fatalf("%v", err)
+			}
+			added++
+		}
+	}
+	if err := dstDB.Close(); err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Printf("merged %v programs\n", added)
+}
+
+func fatalf(msg string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, msg+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,183 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-e2e is an end-to-end test of the whole pipeline: it runs syz-manager
+// in a fresh workdir with a seed program that deterministically crashes the kernel
+// and checks that the crash is detected, de-duplicated, reproduced and reported.
+// The kernel must be built with CONFIG_LKDTM=y, the crasher is a write of "WARNING"
+// into /sys/kernel/debug/provoke-crash/DIRECT.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/syzkaller/config"
+	"github.com/google/syzkaller/sys"
+)
+
+var (
+	flagConfig  = flag.String("config", "", "manager configuration file (workdir, seeds and reproduce are overridden)")
+	flagTimeout = flag.Duration("timeout", time.Hour, "time to wait for the crash to be reproduced")
+	flagTitle   = flag.String("title", "lkdtm", "regexp the crash title must match")
+	flagKeep    = flag.Bool("keep", false, "keep the workdir after a successful test (it's always kept on failure)")
+)
+
+// crasher opens /sys/kernel/debug/provoke-crash/DIRECT and writes "WARNING" to it.
+const crasher = `r0 = open(&(0x7f0000000000)="2f7379732f6b65726e656c2f64656275672f70726f766f6b652d63726173682f44495245435400", 0x1, 0x0)
+write(r0, &(0x7f0000001000)="5741524e494e47", 0x7)
+`
+
+func main() {
+	flag.Parse()
+	cfg, syscalls, _, err := config.Parse(*flagConfig)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	for _, name := range []string{"open", "write"} {
+		if !syscalls[sys.CallMap[name].ID] {
+			log.Fatalf("syscall %v must be enabled in config", name)
+		}
+	}
+	titleRe, err := regexp.Compile(*flagTitle)
+	if err != nil {
+		log.Fatalf("bad title regexp: %v", err)
+	}
+	workdir, err := ioutil.TempDir("", "syz-e2e")
+	if err != nil {
+		log.Fatalf("failed to create workdir: %v", err)
+	}
+	if err := run(cfg, workdir, titleRe); err != nil {
+		log.Printf("FAIL: %v", err)
+		log.Printf("manager log: %v", filepath.Join(workdir, "manager.log"))
+		os.Exit(1)
+	}
+	if !*flagKeep {
+		os.RemoveAll(workdir)
+	}
+	log.Printf("PASS")
+}
+
+func run(cfg *config.Config, workdir string, titleRe *regexp.Regexp) error {
+	seeds := filepath.Join(workdir, "seeds")
+	if err := os.Mkdir(seeds, 0700); err != nil {
+		return fmt.Errorf("failed to create seeds dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(seeds, "lkdtm-warning"), []byte(crasher), 0600); err != nil {
+		return fmt.Errorf("failed to write seed: %v", err)
+	}
+	data, err := config.Override(*flagConfig, map[string]interface{}{
+		"workdir":        filepath.Join(workdir, "workdir"),
+		"seeds":          seeds,
+		"reproduce":      true,
+		"http":           "localhost:0",
+		"hub_addr":       "",
+		"dashboard_addr": "",
+		"email_addrs":    nil,
+		"webhook":        "",
+		"export":         "",
+	})
+	if err != nil {
+		return err
+	}
+	cfgFile := filepath.Join(workdir, "manager.cfg")
+	if err := ioutil.WriteFile(cfgFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %v", err)
+	}
+	logFile, err := os.Create(filepath.Join(workdir, "manager.log"))
+	if err != nil {
+		return fmt.Errorf("failed to create manager log: %v", err)
+	}
+	defer logFile.Close()
+
+	mgr := exec.Command(filepath.Join(cfg.Syzkaller, "bin", "syz-manager"), "-config", cfgFile)
+	mgr.Stdout = logFile
+	mgr.Stderr = logFile
+	if err := mgr.Start(); err != nil {
+		return fmt.Errorf("failed to start syz-manager: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- mgr.Wait()
+	}()
+	defer func() {
+		mgr.Process.Signal(os.Interrupt)
+		select {
+		case <-done:
+		case <-time.After(time.Minute):
+			mgr.Process.Kill()
+			<-done
+		}
+	}()
+
+	crashdir := filepath.Join(workdir, "workdir", "crashes")
+	deadline := time.Now().Add(*flagTimeout)
+	res := new(result)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-done:
+			done <- err // for the deferred func
+			return fmt.Errorf("syz-manager exited: %v", err)
+		case <-time.After(10 * time.Second):
+		}
+		if res, err = check(crashdir, titleRe); err != nil {
+			return err
+		}
+		if res.repro {
+			log.Printf("crash '%v' is detected and reproduced", res.title)
+			return nil
+		}
+	}
+	if res.title == "" {
+		return fmt.Errorf("crash is not detected in %v (other crashes: %v)",
+			*flagTimeout, strings.Join(res.other, ", "))
+	}
+	return fmt.Errorf("crash '%v' is not reproduced in %v", res.title, *flagTimeout)
+}
+
+type result struct {
+	title string   // title of the matching crash
+	repro bool     // the matching crash has a reproducer
+	other []string // titles of other crashes
+}
+
+// check inspects the manager crash dir. It's an error if the crasher produced
+// several crash dirs (crashes are not de-duplicated) or a reproduced crash has no report.
+func check(crashdir string, titleRe *regexp.Regexp) (*result, error) {
+	res := new(result)
+	dirs, err := ioutil.ReadDir(crashdir)
+	if err != nil {
+		return res, nil // no crashes yet
+	}
+	for _, dir := range dirs {
+		desc, err := ioutil.ReadFile(filepath.Join(crashdir, dir.Name(), "description"))
+		if err != nil {
+			continue
+		}
+		title := strings.TrimSpace(string(desc))
+		if !titleRe.MatchString(title) {
+			res.other = append(res.other, title)
+			continue
+		}
+		if res.title != "" {
+			return nil, fmt.Errorf("crash is not de-duplicated: '%v' and '%v'", res.title, title)
+		}
+		res.title = title
+		if _, err := os.Stat(filepath.Join(crashdir, dir.Name(), "repro.prog")); err != nil {
+			continue
+		}
+		res.repro = true
+		if report, err := ioutil.ReadFile(filepath.Join(crashdir, dir.Name(), "report0")); err != nil || len(report) == 0 {
+			return nil, fmt.Errorf("crash '%v' has no report", title)
+		}
+	}
+	return res, nil
+}
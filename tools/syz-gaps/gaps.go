@@ -0,0 +1,177 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// syz-gaps compares syscalls and ioctl handlers present in a kernel
+// (according to vmlinux symbols) with syscall descriptions and prints
+// a report of missing descriptions. Entries are sorted by code size
+// of the handler, which is a rough estimate of how much code is left unfuzzed.
+// Matching of ioctl handlers to descriptions is heuristic: a handler
+// (e.g. kvm_vcpu_ioctl) is considered described if there is an ioctl$
+// description with the same prefix (e.g. ioctl$KVM_RUN).
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/syzkaller/sys"
+)
+
+var (
+	flagVmlinux = flag.String("vmlinux", "", "path to vmlinux")
+	flagNm      = flag.String("nm", "nm", "nm binary to use")
+	flagAll     = flag.Bool("all", false, "print described entries as well")
+)
+
+type Symbol struct {
+	Name string
+	Size uint64
+}
+
+// Kernel names of some syscalls differ from user-visible names.
+var syscallAliases = map[string]string{
+	"newstat":    "stat",
+	"newlstat":   "lstat",
+	"newfstat":   "fstat",
+	"newuname":   "uname",
+	"mmap_pgoff": "mmap",
+}
+
+func main() {
+	flag.Parse()
+	if *flagVmlinux == "" {
+		fmt.Fprintf(os.Stderr, "usage: syz-gaps -vmlinux=vmlinux [-nm=nm] [-all]\n")
+		os.Exit(1)
+	}
+	syms, err := readSymbols(*flagVmlinux)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	described := make(map[string]bool)
+	ioctlPrefixes := make(map[string]bool)
+	for _, c := range sys.Calls {
+		described[c.CallName] = true
+		if c.CallName == "ioctl" {
+			if pos := strings.IndexByte(c.Name, '$'); pos != -1 {
+				cmd := strings.ToLower(c.Name[pos+1:])
+				ioctlPrefixes[strings.Split(cmd, "_")[0]] = true
+			}
+		}
+	}
+
+	syscalls := make(map[string]uint64)
+	ioctls := make(map[string]uint64)
+	for _, s := range syms {
+		if name := syscallName(s.Name); name != "" {
+			if syscalls[name] < s.Size {
+				syscalls[name] = s.Size
+			}
+			continue
+		}
+		if strings.HasSuffix(s.Name, "_ioctl") && !strings.Contains(s.Name, "compat") {
+			ioctls[s.Name] = s.Size
+		}
+	}
+
+	var missingSyscalls, missingIoctls []Symbol
+	for name, size := range syscalls {
+		if *flagAll || !described[name] {
+			missingSyscalls = append(missingSyscalls, Symbol{name, size})
+		}
+	}
+	for name, size := range ioctls {
+		prefix := strings.Split(name, "_")[0]
+		if *flagAll || !ioctlPrefixes[prefix] {
+			missingIoctls = append(missingIoctls, Symbol{name, size})
+		}
+	}
+	printReport("syscalls", missingSyscalls, func(name string) bool { return described[name] })
+	printReport("ioctl handlers", missingIoctls, func(name string) bool {
+		return ioctlPrefixes[strings.Split(name, "_")[0]]
+	})
+}
+
+func printReport(what string, syms []Symbol, isDescribed func(string) bool) {
+	sort.Sort(bySize(syms))
+	var total uint64
+	for _, s := range syms {
+		if !isDescribed(s.Name) {
+			total += s.Size
+		}
+	}
+	fmt.Printf("%v without descriptions (%v bytes of code):\n", what, total)
+	for _, s := range syms {
+		mark := " "
+		if isDescribed(s.Name) {
+			mark = "+"
+		}
+		fmt.Printf("%v %-40v %8v\n", mark, s.Name, s.Size)
+	}
+	fmt.Printf("\n")
+}
+
+// syscallName returns syscall name if sym is a syscall entry point, or "".
+func syscallName(sym string) string {
+	for _, prefix := range []string{"__x64_sys_", "__do_sys_", "__se_sys_", "SyS_", "sys_"} {
+		if !strings.HasPrefix(sym, prefix) {
+			continue
+		}
+		name := sym[len(prefix):]
+		if name == "" || name == "ni_syscall" {
+			return ""
+		}
+		if alias := syscallAliases[name]; alias != "" {
+			name = alias
+		}
+		return name
+	}
+	return ""
+}
+
+func readSymbols(vmlinux string) ([]Symbol, error) {
+	out, err := exec.Command(*flagNm, "-S", vmlinux).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %v: %v", *flagNm, err)
+	}
+	var syms []Symbol
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		// Lines have "address size type name" format, only text symbols are interesting.
+		fields := strings.Fields(s.Text())
+		if len(fields) != 4 || (fields[2] != "t" && fields[2] != "T") {
+			continue
+		}
+		size, err := strconv.ParseUint(fields[1], 16, 64)
+		if err != nil {
+			continue
+		}
+		syms = append(syms, Symbol{fields[3], size})
+	}
+	if len(syms) == 0 {
+		return nil, fmt.Errorf("no symbols found in %v", vmlinux)
+	}
+	return syms, nil
+}
+
+type bySize []Symbol
+
+func (a bySize) Len() int      { return len(a) }
+func (a bySize) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a bySize) Less(i, j int) bool {
+	if a[i].Size != a[j].Size {
+		return a[i].Size > a[j].Size
+	}
+	return a[i].Name < a[j].Name
+}
+
+func fatalf(msg string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, msg+"\n", args...)
+	os.Exit(1)
+}
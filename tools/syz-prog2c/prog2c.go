@@ -16,12 +16,14 @@ import (
 var (
 	flagThreaded = flag.Bool("threaded", false, "create threaded program")
 	flagCollide  = flag.Bool("collide", false, "create collide program")
+	flagRepeat   = flag.Bool("repeat", false, "repeat program infinitely")
+	flagProcs    = flag.Int("procs", 1, "number of parallel processes to repeat the program in")
 )
 
 func main() {
 	flag.Parse()
 	if len(flag.Args()) != 1 {
-		fmt.Fprintf(os.Stderr, "usage: prog2c [-threaded [-collide]] prog_file\n")
+		fmt.Fprintf(os.Stderr, "usage: prog2c [-threaded [-collide]] [-repeat [-procs=N]] prog_file\n")
 		os.Exit(1)
 	}
 	data, err := ioutil.ReadFile(flag.Args()[0])
@@ -37,6 +39,8 @@ func main() {
 	opts := csource.Options{
 		Threaded: *flagThreaded,
 		Collide:  *flagCollide,
+		Repeat:   *flagRepeat,
+		Procs:    *flagProcs,
 	}
 	src := csource.Write(p, opts)
 	if formatted, err := csource.Format(src); err != nil {
@@ -1,44 +1,47 @@
 // Copyright 2015 syzkaller project authors. All rights reserved.
 // Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
 
+// syz-repro reproduces a crash outside of syz-manager. It takes a manager config
+// and a crash log (console output with "executing program" entries, e.g. crashes/HASH/logN),
+// replays the last programs of every proc on fresh VMs until one of them crashes the kernel,
+// minimizes it, finds the simplest execution mode (threaded/collide/procs) that still crashes
+// and checks whether a C program generated from it reproduces the crash too.
+// The results are saved as repro.prog and repro.c into the output dir.
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
-	"time"
 
 	"github.com/google/syzkaller/config"
 	"github.com/google/syzkaller/csource"
-	"github.com/google/syzkaller/fileutil"
-	"github.com/google/syzkaller/prog"
-	"github.com/google/syzkaller/vm"
+	"github.com/google/syzkaller/repro"
 	_ "github.com/google/syzkaller/vm/adb"
+	_ "github.com/google/syzkaller/vm/docker"
+	_ "github.com/google/syzkaller/vm/emulator"
 	_ "github.com/google/syzkaller/vm/kvm"
+	_ "github.com/google/syzkaller/vm/odroid"
 	_ "github.com/google/syzkaller/vm/qemu"
 )
 
 var (
 	flagConfig = flag.String("config", "", "configuration file")
 	flagCount  = flag.Int("count", 0, "number of VMs to use (overrides config count param)")
-
-	instances    chan VM
-	bootRequests chan bool
+	flagBudget = flag.Int("budget", 1, "number of the last programs of every proc to suspect")
+	flagOutput = flag.String("output", ".", "dir to save repro.prog and repro.c to")
 )
 
-type VM struct {
-	vm.Instance
-	execprogBin string
-	executorBin string
-}
-
 func main() {
 	flag.Parse()
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: syz-repro -config=config.file [-count=N] [-budget=N] [-output=dir] crash.log\n")
+		os.Exit(1)
+	}
 	cfg, _, _, err := config.Parse(*flagConfig)
 	if err != nil {
 		log.Fatalf("%v", err)
@@ -46,218 +49,49 @@ func main() {
 	if *flagCount > 0 {
 		cfg.Count = *flagCount
 	}
-	if _, err := os.Stat(filepath.Join(cfg.Syzkaller, "bin/syz-execprog")); err != nil {
-		log.Fatalf("bin/syz-execprog is missing (run 'make execprog')")
-	}
-
-	if len(flag.Args()) != 1 {
-		log.Fatalf("usage: syz-repro -config=config.file execution.log")
-	}
 	data, err := ioutil.ReadFile(flag.Args()[0])
 	if err != nil {
 		log.Fatalf("failed to open log file: %v", err)
 	}
-	entries := prog.ParseLog(data)
-	log.Printf("parsed %v programs", len(entries))
-
-	crashDesc, crashStart, _, found := vm.FindCrash(data)
-	if !found {
-		log.Fatalf("can't find crash message in the log")
-	}
-	log.Printf("target crash: '%s'", crashDesc)
-
-	instances = make(chan VM, cfg.Count)
-	bootRequests = make(chan bool, cfg.Count)
-	for i := 0; i < cfg.Count; i++ {
-		bootRequests <- true
-		go func() {
-			for range bootRequests {
-				vmCfg, err := config.CreateVMConfig(cfg)
-				if err != nil {
-					log.Fatalf("failed to create VM config: %v", err)
-				}
-				inst, err := vm.Create(cfg.Type, vmCfg)
-				if err != nil {
-					log.Fatalf("failed to create VM: %v", err)
-				}
-				execprogBin, err := inst.Copy(filepath.Join(cfg.Syzkaller, "bin/syz-execprog"))
-				if err != nil {
-					log.Fatalf("failed to copy to VM: %v", err)
-				}
-				executorBin, err := inst.Copy(filepath.Join(cfg.Syzkaller, "bin/syz-executor"))
-				if err != nil {
-					log.Fatalf("failed to copy to VM: %v", err)
-				}
-				instances <- VM{inst, execprogBin, executorBin}
-			}
-		}()
-	}
-
-	repro(cfg, entries, crashStart)
-
-	for {
-		select {
-		case inst := <-instances:
-			inst.Close()
-		default:
-			return
-		}
-	}
-}
-
-func repro(cfg *config.Config, entries []*prog.LogEntry, crashStart int) {
-	// Cut programs that were executed after crash.
-	for i, ent := range entries {
-		if ent.Start > crashStart {
-			entries = entries[:i]
-			break
-		}
-	}
-	// Extract last program on every proc.
-	procs := make(map[int]int)
-	for i, ent := range entries {
-		procs[ent.Proc] = i
-	}
-	var indices []int
-	for _, idx := range procs {
-		indices = append(indices, idx)
-	}
-	sort.Ints(indices)
-	var suspected []*prog.LogEntry
-	for i := len(indices) - 1; i >= 0; i-- {
-		suspected = append(suspected, entries[indices[i]])
-	}
-	// Execute the suspected programs.
-	log.Printf("the suspected programs are:")
-	for _, ent := range suspected {
-		log.Printf("on proc %v:\n%s\n", ent.Proc, ent.P.Serialize())
-	}
-	var p *prog.Prog
-	multiplier := 1
-	for ; p == nil && multiplier <= 100; multiplier *= 10 {
-		for _, ent := range suspected {
-			if testProg(cfg, ent.P, multiplier, true, true) {
-				p = ent.P
-				break
-			}
-		}
-	}
-	if p == nil {
-		log.Printf("no program crashed")
-		return
-	}
-	log.Printf("minimizing program")
-
-	p, _ = prog.Minimize(p, -1, func(p1 *prog.Prog, callIndex int) bool {
-		return testProg(cfg, p1, multiplier, true, true)
-	})
-
-	opts := csource.Options{
-		Threaded: true,
-		Collide:  true,
-	}
-	if testProg(cfg, p, multiplier, true, false) {
-		opts.Collide = false
-		if testProg(cfg, p, multiplier, false, false) {
-			opts.Threaded = false
-		}
+	if err := os.MkdirAll(*flagOutput, 0755); err != nil {
+		log.Fatalf("failed to create output dir: %v", err)
 	}
-
-	src := csource.Write(p, opts)
-	log.Printf("C source:\n%s\n", src)
-	srcf, err := fileutil.WriteTempFile(src)
+	res, err := repro.RunBudget(data, cfg, cfg.Count, *flagBudget, nil)
 	if err != nil {
 		log.Fatalf("%v", err)
 	}
-	bin, err := csource.Build(srcf)
-	if err != nil {
-		log.Fatalf("%v", err)
-	}
-	defer os.Remove(bin)
-	testBin(cfg, bin)
-}
-
-func returnInstance(inst VM, res bool) {
-	if res {
-		// The test crashed, discard the VM and issue another boot request.
-		bootRequests <- true
-		inst.Close()
+	if res == nil {
+		log.Printf("no reproducer found")
+		os.Exit(1)
+	}
+	log.Printf("program (threaded=%v, collide=%v, procs=%v) reproduces '%v':\n%s\n",
+		res.Opts.Threaded, res.Opts.Collide, res.Opts.Procs, res.Title, res.Prog.Serialize())
+
+	// Same format as repro.prog in manager crash dirs.
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# %v\n", res.Title)
+	fmt.Fprintf(buf, "# threaded=%v collide=%v procs=%v c_repro=%v\n",
+		res.Opts.Threaded, res.Opts.Collide, res.Opts.Procs, res.CRepro)
+	buf.Write(res.Prog.Serialize())
+	progFile := filepath.Join(*flagOutput, "repro.prog")
+	if err := ioutil.WriteFile(progFile, buf.Bytes(), 0644); err != nil {
+		log.Fatalf("failed to write reproducer: %v", err)
+	}
+
+	opts := res.Opts
+	opts.Repeat = true
+	src := csource.Write(res.Prog, opts)
+	if formatted, err := csource.Format(src); err != nil {
+		log.Printf("%v", err)
 	} else {
-		// The test did not crash, reuse the same VM in future.
-		instances <- inst
-	}
-}
-
-func testProg(cfg *config.Config, p *prog.Prog, multiplier int, threaded, collide bool) (res bool) {
-	log.Printf("booting VM")
-	inst := <-instances
-	defer func() {
-		returnInstance(inst, res)
-	}()
-
-	pstr := p.Serialize()
-	progFile, err := fileutil.WriteTempFile(pstr)
-	if err != nil {
-		log.Fatalf("%v", err)
-	}
-	defer os.Remove(progFile)
-	bin, err := inst.Copy(progFile)
-	if err != nil {
-		log.Fatalf("failed to copy to VM: %v", err)
+		src = formatted
 	}
-
-	repeat := 100
-	timeoutSec := 10 * repeat / cfg.Procs
-	if threaded {
-		repeat *= 10
-		timeoutSec *= 1
-	}
-	repeat *= multiplier
-	timeoutSec *= multiplier
-	timeout := time.Duration(timeoutSec) * time.Second
-	command := fmt.Sprintf("%v -executor %v -cover=0 -procs=%v -repeat=%v -threaded=%v -collide=%v %v",
-		inst.execprogBin, inst.executorBin, cfg.Procs, repeat, threaded, collide, bin)
-	log.Printf("testing program (threaded=%v, collide=%v, repeat=%v, timeout=%v):\n%s\n",
-		threaded, collide, repeat, timeout, pstr)
-	return testImpl(inst, command, timeout)
-}
-
-func testBin(cfg *config.Config, bin string) (res bool) {
-	log.Printf("booting VM")
-	inst := <-instances
-	defer func() {
-		returnInstance(inst, res)
-	}()
-
-	bin, err := inst.Copy(bin)
-	if err != nil {
-		log.Fatalf("failed to copy to VM: %v", err)
-	}
-	log.Printf("testing compiled C program")
-	return testImpl(inst, bin, 10*time.Second)
-}
-
-func testImpl(inst vm.Instance, command string, timeout time.Duration) (res bool) {
-	outc, errc, err := inst.Run(timeout, command)
-	if err != nil {
-		log.Fatalf("failed to run command in VM: %v", err)
+	if !res.CRepro {
+		src = append([]byte("/* WARNING: this C program did not reproduce the crash, use repro.prog with syz-execprog. */\n"), src...)
 	}
-	var output []byte
-	for {
-		select {
-		case out := <-outc:
-			output = append(output, out...)
-			if desc, _, _, found := vm.FindCrash(output); found {
-				log.Printf("program crashed with '%s'", desc)
-				return true
-			}
-		case err := <-errc:
-			if err != nil {
-				log.Printf("program crashed with result '%v'", err)
-				return true
-			}
-			log.Printf("program did not crash")
-			return false
-		}
+	cFile := filepath.Join(*flagOutput, "repro.c")
+	if err := ioutil.WriteFile(cFile, src, 0644); err != nil {
+		log.Fatalf("failed to write C reproducer: %v", err)
 	}
+	log.Printf("saved reproducers to %v and %v (C reproducer reproduces: %v)", progFile, cFile, res.CRepro)
 }
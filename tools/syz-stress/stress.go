@@ -13,10 +13,12 @@ import (
 	"os"
 	"regexp"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/google/syzkaller/db"
 	"github.com/google/syzkaller/host"
 	"github.com/google/syzkaller/ipc"
 	"github.com/google/syzkaller/prog"
@@ -24,7 +26,7 @@ import (
 )
 
 var (
-	flagCorpus   = flag.String("corpus", "", "corpus directory")
+	flagCorpus   = flag.String("corpus", "", "corpus zip archive or corpus.db file")
 	flagExecutor = flag.String("executor", "./syz-executor", "path to executor binary")
 	flagOutput   = flag.Bool("output", false, "print executor output to console")
 	flagProcs    = flag.Int("procs", 2*runtime.NumCPU(), "number of parallel processes")
@@ -115,6 +117,21 @@ func readCorpus() []*prog.Prog {
 	if *flagCorpus == "" {
 		return nil
 	}
+	if strings.HasSuffix(*flagCorpus, ".db") {
+		corpusDB, err := db.ReadRecords(*flagCorpus)
+		if err != nil {
+			failf("%v", err)
+		}
+		var progs []*prog.Prog
+		for _, rec := range corpusDB.Records {
+			p, err := prog.Deserialize(rec.Val)
+			if err != nil {
+				failf("failed to deserialize corpus program: %v", err)
+			}
+			progs = append(progs, p)
+		}
+		return progs
+	}
 	zipr, err := zip.OpenReader(*flagCorpus)
 	if err != nil {
 		failf("failed to open bin file: %v", err)
@@ -10,9 +10,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"syscall"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/syzkaller/fileutil"
 	"github.com/google/syzkaller/vm"
 )
 
@@ -22,12 +26,21 @@ func init() {
 
 type instance struct {
 	cfg    *vm.Config
+	dev    vm.Device
 	closed chan bool
 }
 
 func ctor(cfg *vm.Config) (vm.Instance, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	dev, err := acquireDevice(cfg)
+	if err != nil {
+		return nil, err
+	}
 	inst := &instance{
 		cfg:    cfg,
+		dev:    dev,
 		closed: make(chan bool),
 	}
 	closeInst := inst
@@ -36,9 +49,6 @@ func ctor(cfg *vm.Config) (vm.Instance, error) {
 			closeInst.Close()
 		}
 	}()
-	if err := validateConfig(cfg); err != nil {
-		return nil, err
-	}
 	if err := inst.repair(); err != nil {
 		return nil, err
 	}
@@ -52,12 +62,109 @@ func validateConfig(cfg *vm.Config) error {
 	if cfg.Bin == "" {
 		cfg.Bin = "adb"
 	}
-	if _, err := os.Stat(cfg.ConsoleDev); err != nil {
-		return fmt.Errorf("console device '%v' is missing: %v", cfg.ConsoleDev, err)
+	if len(cfg.Devices) == 0 {
+		// Single device without serial.
+		cfg.Devices = []vm.Device{{ConsoleDev: cfg.ConsoleDev}}
+	}
+	for _, dev := range cfg.Devices {
+		if _, err := os.Stat(dev.ConsoleDev); err != nil {
+			return fmt.Errorf("console device '%v' is missing: %v", dev.ConsoleDev, err)
+		}
+	}
+	return nil
+}
+
+// Device pool shared by all instances in the process.
+// Instances rotate between devices, so that a device that has just crashed
+// has time to reboot, and devices that are unhealthy (low battery, overheated)
+// are skipped until they recover.
+var (
+	poolMu   sync.Mutex
+	poolBusy = make(map[string]bool)
+	poolNext int
+)
+
+const (
+	minBatteryLevel = 20
+	maxTemperature  = 450 // in tenths of degree Celsius, as reported by dumpsys
+)
+
+func acquireDevice(cfg *vm.Config) (vm.Device, error) {
+	for start := time.Now(); time.Since(start) < time.Hour; time.Sleep(time.Minute) {
+		poolMu.Lock()
+		var candidates []vm.Device
+		for i := range cfg.Devices {
+			dev := cfg.Devices[(poolNext+i)%len(cfg.Devices)]
+			if !poolBusy[dev.ConsoleDev] {
+				candidates = append(candidates, dev)
+			}
+		}
+		for _, dev := range candidates {
+			poolBusy[dev.ConsoleDev] = true
+		}
+		poolNext++
+		poolMu.Unlock()
+
+		var dev vm.Device
+		found := false
+		for _, cand := range candidates {
+			if !found {
+				if err := checkHealth(cfg, cand); err != nil {
+					log.Printf("adb device %v is unhealthy: %v", cand.Serial, err)
+				} else {
+					dev = cand
+					found = true
+					continue
+				}
+			}
+			releaseDevice(cand)
+		}
+		if found {
+			return dev, nil
+		}
+	}
+	return vm.Device{}, fmt.Errorf("no healthy adb devices available")
+}
+
+func releaseDevice(dev vm.Device) {
+	poolMu.Lock()
+	delete(poolBusy, dev.ConsoleDev)
+	poolMu.Unlock()
+}
+
+var (
+	batteryLevelRe = regexp.MustCompile(`level: *([0-9]+)`)
+	temperatureRe  = regexp.MustCompile(`temperature: *([0-9]+)`)
+)
+
+// checkHealth checks battery level and temperature of the device.
+// Devices that don't respond are considered healthy here, they are repaired later.
+func checkHealth(cfg *vm.Config, dev vm.Device) error {
+	inst := &instance{cfg: cfg, dev: dev}
+	out, err := inst.adbOutput("shell", "dumpsys battery")
+	if err != nil {
+		return nil
+	}
+	if m := batteryLevelRe.FindStringSubmatch(out); m != nil {
+		if level, _ := strconv.Atoi(m[1]); level < minBatteryLevel {
+			return fmt.Errorf("battery level is %v%%", level)
+		}
+	}
+	if m := temperatureRe.FindStringSubmatch(out); m != nil {
+		if temp, _ := strconv.Atoi(m[1]); temp > maxTemperature {
+			return fmt.Errorf("temperature is %v.%v C", temp/10, temp%10)
+		}
 	}
 	return nil
 }
 
+func (inst *instance) adbArgs(args ...string) []string {
+	if inst.dev.Serial != "" {
+		args = append([]string{"-s", inst.dev.Serial}, args...)
+	}
+	return args
+}
+
 func (inst *instance) Forward(port int) (string, error) {
 	// If 35099 turns out to be busy, try to forward random ports several times.
 	devicePort := 35099
@@ -68,12 +175,18 @@ func (inst *instance) Forward(port int) (string, error) {
 }
 
 func (inst *instance) adb(args ...string) error {
+	_, err := inst.adbOutput(args...)
+	return err
+}
+
+func (inst *instance) adbOutput(args ...string) (string, error) {
+	args = inst.adbArgs(args...)
 	if inst.cfg.Debug {
 		log.Printf("executing adb %+v", args)
 	}
 	rpipe, wpipe, err := os.Pipe()
 	if err != nil {
-		return fmt.Errorf("failed to create pipe: %v", err)
+		return "", fmt.Errorf("failed to create pipe: %v", err)
 	}
 	defer wpipe.Close()
 	defer rpipe.Close()
@@ -81,9 +194,14 @@ func (inst *instance) adb(args ...string) error {
 	cmd.Stdout = wpipe
 	cmd.Stderr = wpipe
 	if err := cmd.Start(); err != nil {
-		return err
+		return "", err
 	}
 	wpipe.Close()
+	outc := make(chan []byte, 1)
+	go func() {
+		out, _ := ioutil.ReadAll(rpipe)
+		outc <- out
+	}()
 	done := make(chan bool)
 	go func() {
 		select {
@@ -95,40 +213,56 @@ func (inst *instance) adb(args ...string) error {
 		case <-done:
 		}
 	}()
-	if err := cmd.Wait(); err != nil {
-		close(done)
-		out, _ := ioutil.ReadAll(rpipe)
+	err = cmd.Wait()
+	close(done)
+	out := <-outc
+	if err != nil {
 		if inst.cfg.Debug {
 			log.Printf("adb failed: %v\n%s", err, out)
 		}
-		return fmt.Errorf("adb %+v failed: %v\n%s", args, err, out)
+		return "", fmt.Errorf("adb %+v failed: %v\n%s", args, err, out)
 	}
-	close(done)
 	if inst.cfg.Debug {
 		log.Printf("adb returned")
 	}
-	return nil
+	return string(out), nil
 }
 
 func (inst *instance) repair() error {
 	// Give the device up to 5 minutes to come up (it can be rebooting after a previous crash).
 	time.Sleep(3 * time.Second)
-	for i := 0; i < 300; i++ {
-		time.Sleep(time.Second)
-		if inst.adb("shell", "pwd") == nil {
-			return nil
-		}
+	if inst.waitForDevice() == nil {
+		return nil
 	}
 	// If it does not help, reboot.
 	// adb reboot episodically hangs, so we use a more reliable way.
 	// Ignore errors because all other adb commands hang as well
 	// and the binary can already be on the device.
 	inst.adb("push", inst.cfg.Executor, "/data/syz-executor")
-	if err := inst.adb("shell", "/data/syz-executor", "reboot"); err != nil {
-		return err
+	err := inst.adb("shell", "/data/syz-executor", "reboot")
+	if err == nil {
+		// Now give it another 5 minutes.
+		time.Sleep(10 * time.Second)
+		if err = inst.waitForDevice(); err == nil {
+			return nil
+		}
+	}
+	// Last resort: reflash the device.
+	if inst.cfg.Reflash == "" {
+		return fmt.Errorf("instance is dead and unrepairable: %v", err)
+	}
+	log.Printf("reflashing adb device %v", inst.dev.Serial)
+	command := strings.Replace(inst.cfg.Reflash, "%v", inst.dev.Serial, -1)
+	if out, err := fileutil.ShellCommand(command).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to reflash device %v: %v\n%s", inst.dev.Serial, err, out)
+	}
+	if err := inst.waitForDevice(); err != nil {
+		return fmt.Errorf("instance is dead and unrepairable after reflashing: %v", err)
 	}
-	// Now give it another 5 minutes.
-	time.Sleep(10 * time.Second)
+	return nil
+}
+
+func (inst *instance) waitForDevice() error {
 	var err error
 	for i := 0; i < 300; i++ {
 		time.Sleep(time.Second)
@@ -136,11 +270,12 @@ func (inst *instance) repair() error {
 			return nil
 		}
 	}
-	return fmt.Errorf("instance is dead and unrepairable: %v", err)
+	return err
 }
 
 func (inst *instance) Close() {
 	close(inst.closed)
+	releaseDevice(inst.dev)
 	os.RemoveAll(inst.cfg.Workdir)
 }
 
@@ -157,17 +292,15 @@ func (inst *instance) Run(timeout time.Duration, command string) (<-chan []byte,
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create pipe: %v", err)
 	}
-	for sz := 128 << 10; sz <= 2<<20; sz *= 2 {
-		syscall.Syscall(syscall.SYS_FCNTL, wpipe.Fd(), syscall.F_SETPIPE_SZ, uintptr(sz))
-	}
+	fileutil.GrowPipe(wpipe)
 
-	cat := exec.Command("cat", inst.cfg.ConsoleDev)
+	cat := exec.Command("cat", inst.dev.ConsoleDev)
 	cat.Stdout = wpipe
 	cat.Stderr = wpipe
 	if err := cat.Start(); err != nil {
 		rpipe.Close()
 		wpipe.Close()
-		return nil, nil, fmt.Errorf("failed to start cat %v: %v", inst.cfg.ConsoleDev, err)
+		return nil, nil, fmt.Errorf("failed to start cat %v: %v", inst.dev.ConsoleDev, err)
 
 	}
 	catDone := make(chan error, 1)
@@ -182,7 +315,7 @@ func (inst *instance) Run(timeout time.Duration, command string) (<-chan []byte,
 	if inst.cfg.Debug {
 		log.Printf("starting: adb shell %v", command)
 	}
-	adb := exec.Command(inst.cfg.Bin, "shell", "cd /data; "+command)
+	adb := exec.Command(inst.cfg.Bin, inst.adbArgs("shell", "cd /data; "+command)...)
 	adb.Stdout = wpipe
 	adb.Stderr = wpipe
 	if err := adb.Start(); err != nil {
@@ -0,0 +1,262 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package docker implements a vm backend that runs the fuzzer in a privileged
+// container with own pid/net/mount namespaces on the host kernel.
+// It is much faster to start than a VM and is useful for fuzzing
+// kernel-independent subsystems with sandbox=namespace.
+// Any docker-compatible CLI (e.g. podman) can be specified as bin.
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/syzkaller/vm"
+)
+
+func init() {
+	vm.Register("docker", ctor)
+}
+
+type instance struct {
+	cfg    *vm.Config
+	name   string
+	ln     net.Listener
+	closed chan bool
+
+	mu      sync.Mutex
+	outputB []byte
+	outputC chan []byte
+}
+
+func ctor(cfg *vm.Config) (vm.Instance, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	inst := &instance{
+		cfg:    cfg,
+		name:   fmt.Sprintf("syz-%v", cfg.Index),
+		closed: make(chan bool),
+	}
+	closeInst := inst
+	defer func() {
+		if closeInst != nil {
+			closeInst.Close()
+		}
+	}()
+	// Remove container left from a previous run.
+	inst.docker("rm", "-f", inst.name)
+	if _, err := inst.docker("run", "-d", "--privileged", "--name", inst.name,
+		"--hostname", inst.name, inst.cfg.Image, "sleep", "infinity"); err != nil {
+		return nil, err
+	}
+	go inst.readKmsg()
+	closeInst = nil
+	return inst, nil
+}
+
+func validateConfig(cfg *vm.Config) error {
+	if cfg.Bin == "" {
+		cfg.Bin = "docker"
+	}
+	if cfg.Image == "" {
+		return fmt.Errorf("container image is empty")
+	}
+	return nil
+}
+
+func (inst *instance) docker(args ...string) ([]byte, error) {
+	if inst.cfg.Debug {
+		log.Printf("executing %v %+v", inst.cfg.Bin, args)
+	}
+	out, err := exec.Command(inst.cfg.Bin, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%v %+v failed: %v\n%s", inst.cfg.Bin, args, err, out)
+	}
+	return out, nil
+}
+
+func (inst *instance) Close() {
+	close(inst.closed)
+	if inst.ln != nil {
+		inst.ln.Close()
+	}
+	inst.docker("rm", "-f", inst.name)
+	os.RemoveAll(inst.cfg.Workdir)
+}
+
+// Forward proxies connections from the container network gateway address to host localhost,
+// because the container has own net namespace and can't connect to host localhost.
+func (inst *instance) Forward(port int) (string, error) {
+	out, err := inst.docker("inspect", "-f", "{{.NetworkSettings.Gateway}}", inst.name)
+	if err != nil {
+		return "", err
+	}
+	gateway := strings.TrimSpace(string(out))
+	if gateway == "" {
+		return "", fmt.Errorf("container %v has no network gateway", inst.name)
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(gateway, "0"))
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %v: %v", gateway, err)
+	}
+	inst.ln = ln
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go proxy(conn, fmt.Sprintf("127.0.0.1:%v", port))
+		}
+	}()
+	return ln.Addr().String(), nil
+}
+
+func proxy(conn net.Conn, addr string) {
+	defer conn.Close()
+	host, err := net.Dial("tcp", addr)
+	if err != nil {
+		return
+	}
+	defer host.Close()
+	done := make(chan bool, 2)
+	go func() {
+		io.Copy(host, conn)
+		done <- true
+	}()
+	go func() {
+		io.Copy(conn, host)
+		done <- true
+	}()
+	<-done
+}
+
+func (inst *instance) Copy(hostSrc string) (string, error) {
+	vmDst := filepath.Join("/", filepath.Base(hostSrc))
+	if _, err := inst.docker("cp", hostSrc, inst.name+":"+vmDst); err != nil {
+		return "", err
+	}
+	return vmDst, nil
+}
+
+// readKmsg feeds new host kernel messages into instance output,
+// so that crashes of the host kernel caused by the fuzzer are detected.
+func (inst *instance) readKmsg() {
+	f, err := os.Open("/dev/kmsg")
+	if err != nil {
+		log.Printf("failed to open /dev/kmsg: %v", err)
+		return
+	}
+	go func() {
+		<-inst.closed
+		f.Close()
+	}()
+	// Skip old messages.
+	f.Seek(0, 2)
+	r := bufio.NewReader(f)
+	for {
+		rec, err := r.ReadBytes('\n')
+		if err != nil {
+			if err == syscall.EPIPE {
+				// Some messages were overwritten before we read them.
+				continue
+			}
+			return
+		}
+		// Records have "prio,seq,timestamp,flags;message" format.
+		if pos := bytes.IndexByte(rec, ';'); pos != -1 {
+			rec = rec[pos+1:]
+		}
+		inst.output(rec)
+	}
+}
+
+func (inst *instance) output(data []byte) {
+	if inst.cfg.Debug {
+		os.Stdout.Write(data)
+	}
+	inst.mu.Lock()
+	inst.outputB = append(inst.outputB, data...)
+	if inst.outputC != nil {
+		select {
+		case inst.outputC <- inst.outputB:
+			inst.outputB = nil
+		default:
+		}
+	}
+	inst.mu.Unlock()
+}
+
+type outputWriter struct {
+	inst *instance
+}
+
+func (w outputWriter) Write(data []byte) (int, error) {
+	w.inst.output(append([]byte{}, data...))
+	return len(data), nil
+}
+
+func (inst *instance) Run(timeout time.Duration, command string) (<-chan []byte, <-chan error, error) {
+	outputC := make(chan []byte, 10)
+	errorC := make(chan error, 1)
+	inst.mu.Lock()
+	inst.outputB = nil
+	inst.outputC = outputC
+	inst.mu.Unlock()
+	signal := func(err error) {
+		time.Sleep(3 * time.Second) // wait for any pending output
+		inst.mu.Lock()
+		if inst.outputC == outputC {
+			inst.outputB = nil
+			inst.outputC = nil
+		}
+		inst.mu.Unlock()
+		select {
+		case errorC <- err:
+		default:
+		}
+	}
+	if inst.cfg.Debug {
+		log.Printf("starting: %v exec %v %v", inst.cfg.Bin, inst.name, command)
+	}
+	cmd := exec.Command(inst.cfg.Bin, "exec", inst.name, "sh", "-c", command)
+	cmd.Stdout = outputWriter{inst}
+	cmd.Stderr = outputWriter{inst}
+	if err := cmd.Start(); err != nil {
+		inst.mu.Lock()
+		inst.outputC = nil
+		inst.mu.Unlock()
+		return nil, nil, err
+	}
+	done := make(chan bool)
+	go func() {
+		select {
+		case <-time.After(timeout):
+			signal(vm.TimeoutErr)
+			cmd.Process.Kill()
+		case <-inst.closed:
+			signal(fmt.Errorf("instance closed"))
+			cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+	go func() {
+		err := cmd.Wait()
+		close(done)
+		signal(err)
+	}()
+	return outputC, errorC, nil
+}
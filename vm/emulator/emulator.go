@@ -0,0 +1,288 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package emulator implements a vm backend that runs Android emulator
+// (goldfish/ranchu) instances of an AVD. Instances are started read-only
+// from the same AVD, so that any number of them can run in parallel,
+// optionally restoring a snapshot for fast boot. Kernel console is captured
+// from emulator output (-show-kernel), commands are run via adb.
+package emulator
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/syzkaller/vm"
+)
+
+func init() {
+	vm.Register("emulator", ctor)
+}
+
+type instance struct {
+	cfg    *vm.Config
+	port   int    // emulator console port, adb port is port+1
+	serial string // adb serial
+	cmd    *exec.Cmd
+	exited chan error
+	closed chan bool
+
+	mu      sync.Mutex
+	outputB []byte
+	outputC chan []byte
+}
+
+const (
+	basePort    = 5554
+	bootTimeout = 10 * time.Minute
+)
+
+func ctor(cfg *vm.Config) (vm.Instance, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	// Emulator console ports must be even and in [5554, 5682].
+	port := basePort + 2*cfg.Index
+	inst := &instance{
+		cfg:    cfg,
+		port:   port,
+		serial: fmt.Sprintf("emulator-%v", port),
+		exited: make(chan error, 1),
+		closed: make(chan bool),
+	}
+	closeInst := inst
+	defer func() {
+		if closeInst != nil {
+			closeInst.Close()
+		}
+	}()
+	if err := inst.boot(); err != nil {
+		return nil, err
+	}
+	closeInst = nil
+	return inst, nil
+}
+
+func validateConfig(cfg *vm.Config) error {
+	if cfg.Bin == "" {
+		cfg.Bin = "emulator"
+	}
+	if cfg.Avd == "" {
+		return fmt.Errorf("avd name is empty")
+	}
+	if cfg.Index >= 64 {
+		return fmt.Errorf("too many emulator instances: %v, at most 64 are supported", cfg.Index+1)
+	}
+	return nil
+}
+
+func (inst *instance) boot() error {
+	args := []string{
+		"-avd", inst.cfg.Avd,
+		"-port", strconv.Itoa(inst.port),
+		"-read-only",
+		"-no-window",
+		"-no-audio",
+		"-no-boot-anim",
+		"-show-kernel",
+		"-verbose",
+	}
+	if inst.cfg.Snapshot != "" {
+		args = append(args, "-snapshot", inst.cfg.Snapshot, "-no-snapshot-save")
+	} else {
+		args = append(args, "-no-snapshot")
+	}
+	if inst.cfg.Kernel != "" {
+		args = append(args, "-kernel", inst.cfg.Kernel)
+	}
+	if inst.cfg.Cpu != 0 {
+		args = append(args, "-cores", strconv.Itoa(inst.cfg.Cpu))
+	}
+	if inst.cfg.Mem != 0 {
+		args = append(args, "-memory", strconv.Itoa(inst.cfg.Mem))
+	}
+	if inst.cfg.Debug {
+		log.Printf("running command: %v %#v", inst.cfg.Bin, args)
+	}
+	inst.cmd = exec.Command(inst.cfg.Bin, args...)
+	inst.cmd.Stdout = outputWriter{inst}
+	inst.cmd.Stderr = outputWriter{inst}
+	if err := inst.cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %v: %v", inst.cfg.Bin, err)
+	}
+	go func() {
+		inst.exited <- inst.cmd.Wait()
+		close(inst.exited)
+	}()
+
+	// Wait for boot to complete, restart adbd as root and wait again.
+	if err := inst.waitForBoot(); err != nil {
+		return err
+	}
+	if _, err := inst.adb("root"); err != nil {
+		return err
+	}
+	if err := inst.waitForBoot(); err != nil {
+		return err
+	}
+	// Remove temp files from previous runs (there are none with -read-only,
+	// but the snapshot can contain them).
+	inst.adb("shell", "rm -Rf /data/syzkaller*")
+	return nil
+}
+
+func (inst *instance) waitForBoot() error {
+	for start := time.Now(); time.Since(start) < bootTimeout; time.Sleep(time.Second) {
+		select {
+		case err := <-inst.exited:
+			inst.mu.Lock()
+			output := inst.outputB
+			inst.mu.Unlock()
+			return fmt.Errorf("emulator exited during boot: %v\n%s", err, output)
+		default:
+		}
+		out, err := inst.adb("shell", "getprop sys.boot_completed")
+		if err == nil && strings.TrimSpace(string(out)) == "1" {
+			return nil
+		}
+	}
+	return fmt.Errorf("emulator did not boot in %v", bootTimeout)
+}
+
+func (inst *instance) adb(args ...string) ([]byte, error) {
+	args = append([]string{"-s", inst.serial}, args...)
+	if inst.cfg.Debug {
+		log.Printf("executing adb %+v", args)
+	}
+	cmd := exec.Command("adb", args...)
+	done := make(chan bool)
+	go func() {
+		select {
+		case <-time.After(time.Minute):
+			cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+	out, err := cmd.CombinedOutput()
+	close(done)
+	if err != nil {
+		return nil, fmt.Errorf("adb %+v failed: %v\n%s", args, err, out)
+	}
+	return out, nil
+}
+
+func (inst *instance) Close() {
+	close(inst.closed)
+	if inst.cmd != nil && inst.cmd.Process != nil {
+		inst.cmd.Process.Kill()
+		<-inst.exited
+	}
+	os.RemoveAll(inst.cfg.Workdir)
+}
+
+func (inst *instance) Forward(port int) (string, error) {
+	devicePort := 35099
+	if _, err := inst.adb("reverse", fmt.Sprintf("tcp:%v", devicePort), fmt.Sprintf("tcp:%v", port)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("127.0.0.1:%v", devicePort), nil
+}
+
+func (inst *instance) Copy(hostSrc string) (string, error) {
+	vmDst := filepath.Join("/data", filepath.Base(hostSrc))
+	if _, err := inst.adb("push", hostSrc, vmDst); err != nil {
+		return "", err
+	}
+	return vmDst, nil
+}
+
+func (inst *instance) output(data []byte) {
+	if inst.cfg.Debug {
+		os.Stdout.Write(data)
+	}
+	inst.mu.Lock()
+	inst.outputB = append(inst.outputB, data...)
+	if inst.outputC != nil {
+		select {
+		case inst.outputC <- inst.outputB:
+			inst.outputB = nil
+		default:
+		}
+	} else if len(inst.outputB) > 128<<10 {
+		// Nobody is reading, keep only the tail for error messages.
+		inst.outputB = append([]byte{}, inst.outputB[len(inst.outputB)-64<<10:]...)
+	}
+	inst.mu.Unlock()
+}
+
+type outputWriter struct {
+	inst *instance
+}
+
+func (w outputWriter) Write(data []byte) (int, error) {
+	// Emulator prints some messages with \r\n.
+	w.inst.output(bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1))
+	return len(data), nil
+}
+
+func (inst *instance) Run(timeout time.Duration, command string) (<-chan []byte, <-chan error, error) {
+	outputC := make(chan []byte, 10)
+	errorC := make(chan error, 1)
+	inst.mu.Lock()
+	inst.outputB = nil
+	inst.outputC = outputC
+	inst.mu.Unlock()
+	signal := func(err error) {
+		time.Sleep(3 * time.Second) // wait for any pending output
+		inst.mu.Lock()
+		if inst.outputC == outputC {
+			inst.outputB = nil
+			inst.outputC = nil
+		}
+		inst.mu.Unlock()
+		select {
+		case errorC <- err:
+		default:
+		}
+	}
+	if inst.cfg.Debug {
+		log.Printf("starting: adb -s %v shell %v", inst.serial, command)
+	}
+	cmd := exec.Command("adb", "-s", inst.serial, "shell", "cd /data; "+command)
+	cmd.Stdout = outputWriter{inst}
+	cmd.Stderr = outputWriter{inst}
+	if err := cmd.Start(); err != nil {
+		inst.mu.Lock()
+		inst.outputC = nil
+		inst.mu.Unlock()
+		return nil, nil, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	go func() {
+		select {
+		case <-time.After(timeout):
+			signal(vm.TimeoutErr)
+			cmd.Process.Kill()
+		case <-inst.closed:
+			signal(fmt.Errorf("instance closed"))
+			cmd.Process.Kill()
+		case err := <-inst.exited:
+			signal(fmt.Errorf("emulator exited: %v", err))
+			cmd.Process.Kill()
+		case err := <-done:
+			signal(err)
+		}
+	}()
+	return outputC, errorC, nil
+}
@@ -12,7 +12,6 @@ import (
 	"runtime"
 	"strconv"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/google/syzkaller/fileutil"
@@ -73,9 +72,7 @@ func ctor(cfg *vm.Config) (vm.Instance, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pipe: %v", err)
 	}
-	for sz := 128 << 10; sz <= 2<<20; sz *= 2 {
-		syscall.Syscall(syscall.SYS_FCNTL, wpipe.Fd(), syscall.F_SETPIPE_SZ, uintptr(sz))
-	}
+	fileutil.GrowPipe(wpipe)
 
 	inst.lkvm = exec.Command("taskset", "-c", strconv.Itoa(inst.cfg.Index%runtime.NumCPU()),
 		inst.cfg.Bin, "sandbox",
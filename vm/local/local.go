@@ -1,6 +1,8 @@
 // Copyright 2015 syzkaller project authors. All rights reserved.
 // Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
 
+// +build !windows
+
 package local
 
 import (
@@ -34,7 +36,7 @@ func ctor(cfg *vm.Config) (vm.Instance, error) {
 	}
 
 	// Don't write executor core files.
-	syscall.Setrlimit(syscall.RLIMIT_CORE, &syscall.Rlimit{0, 0})
+	syscall.Setrlimit(syscall.RLIMIT_CORE, &syscall.Rlimit{Cur: 0, Max: 0})
 
 	inst := &instance{
 		cfg:    cfg,
@@ -68,9 +70,7 @@ func (inst *instance) Run(timeout time.Duration, command string) (<-chan []byte,
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create pipe: %v", err)
 	}
-	for sz := 128 << 10; sz <= 2<<20; sz *= 2 {
-		syscall.Syscall(syscall.SYS_FCNTL, wpipe.Fd(), syscall.F_SETPIPE_SZ, uintptr(sz))
-	}
+	fileutil.GrowPipe(wpipe)
 	for strings.Index(command, "  ") != -1 {
 		command = strings.Replace(command, "  ", " ", -1)
 	}
@@ -0,0 +1,288 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// Package odroid implements a vm backend for physical ARM dev boards (e.g. Odroid).
+// The board is controlled over ssh, kernel output is read from a serial console,
+// and a hung board is hard power-cycled with a user-supplied relay command.
+package odroid
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/google/syzkaller/fileutil"
+	"github.com/google/syzkaller/vm"
+)
+
+func init() {
+	vm.Register("odroid", ctor)
+}
+
+type instance struct {
+	cfg     *vm.Config
+	fwdPort int // port on the board that is forwarded to host over ssh
+	hostFwd int // host port that fwdPort is forwarded to
+	closed  chan bool
+}
+
+func ctor(cfg *vm.Config) (vm.Instance, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	inst := &instance{
+		cfg:    cfg,
+		closed: make(chan bool),
+	}
+	closeInst := inst
+	defer func() {
+		if closeInst != nil {
+			closeInst.Close()
+		}
+	}()
+	if err := inst.repair(); err != nil {
+		return nil, err
+	}
+	// Remove temp files from previous runs.
+	inst.ssh("rm -Rf /syzkaller*")
+	closeInst = nil
+	return inst, nil
+}
+
+func validateConfig(cfg *vm.Config) error {
+	if cfg.Addr == "" {
+		return fmt.Errorf("board address is empty")
+	}
+	if cfg.PowerCycle == "" {
+		return fmt.Errorf("power cycle command is empty")
+	}
+	if _, err := os.Stat(cfg.ConsoleDev); err != nil {
+		return fmt.Errorf("console device '%v' is missing: %v", cfg.ConsoleDev, err)
+	}
+	if _, err := os.Stat(cfg.Sshkey); err != nil {
+		return fmt.Errorf("ssh key '%v' does not exist: %v", cfg.Sshkey, err)
+	}
+	return nil
+}
+
+func (inst *instance) repair() error {
+	// The board can be rebooting after a previous crash, give it some time.
+	if inst.waitForSsh(time.Minute) == nil {
+		return nil
+	}
+	// The kernel is most likely hung, power-cycle the board.
+	log.Printf("power cycling board %v", inst.cfg.Addr)
+	if out, err := fileutil.ShellCommand(inst.cfg.PowerCycle).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to power cycle board %v: %v\n%s", inst.cfg.Addr, err, out)
+	}
+	if err := inst.waitForSsh(10 * time.Minute); err != nil {
+		return fmt.Errorf("board is dead and unrepairable: %v", err)
+	}
+	return nil
+}
+
+func (inst *instance) waitForSsh(timeout time.Duration) error {
+	var err error
+	for start := time.Now(); time.Since(start) < timeout; {
+		if err = inst.ssh("pwd"); err == nil {
+			return nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("ssh did not come up: %v", err)
+}
+
+func (inst *instance) ssh(command string) error {
+	if inst.cfg.Debug {
+		log.Printf("executing ssh %+v", command)
+	}
+	args := append(inst.sshArgs("-p"), "root@"+inst.cfg.Addr, command)
+	return runTimeout(exec.Command("ssh", args...), time.Minute)
+}
+
+func (inst *instance) Close() {
+	close(inst.closed)
+	os.RemoveAll(inst.cfg.Workdir)
+}
+
+func (inst *instance) Forward(port int) (string, error) {
+	// The board can't reach host localhost, so Run sets up reverse ssh forwarding.
+	inst.hostFwd = port
+	inst.fwdPort = rand.Intn(10000) + 30000
+	return fmt.Sprintf("127.0.0.1:%v", inst.fwdPort), nil
+}
+
+func (inst *instance) Copy(hostSrc string) (string, error) {
+	vmDst := filepath.Join("/", filepath.Base(hostSrc))
+	args := append(inst.sshArgs("-P"), hostSrc, "root@"+inst.cfg.Addr+":"+vmDst)
+	if err := runTimeout(exec.Command("scp", args...), 3*time.Minute); err != nil {
+		return "", err
+	}
+	return vmDst, nil
+}
+
+func (inst *instance) Run(timeout time.Duration, command string) (<-chan []byte, <-chan error, error) {
+	rpipe, wpipe, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create pipe: %v", err)
+	}
+	fileutil.GrowPipe(wpipe)
+
+	cat := exec.Command("cat", inst.cfg.ConsoleDev)
+	cat.Stdout = wpipe
+	cat.Stderr = wpipe
+	if err := cat.Start(); err != nil {
+		rpipe.Close()
+		wpipe.Close()
+		return nil, nil, fmt.Errorf("failed to start cat %v: %v", inst.cfg.ConsoleDev, err)
+	}
+	catDone := make(chan error, 1)
+	go func() {
+		err := cat.Wait()
+		if inst.cfg.Debug {
+			log.Printf("cat exited: %v", err)
+		}
+		catDone <- fmt.Errorf("cat exited: %v", err)
+	}()
+
+	args := inst.sshArgs("-p")
+	if inst.fwdPort != 0 {
+		args = append(args, "-R", fmt.Sprintf("%v:127.0.0.1:%v", inst.fwdPort, inst.hostFwd))
+	}
+	args = append(args, "root@"+inst.cfg.Addr, "cd /; "+command)
+	if inst.cfg.Debug {
+		log.Printf("starting: ssh %+v", args)
+	}
+	ssh := exec.Command("ssh", args...)
+	ssh.Stdout = wpipe
+	ssh.Stderr = wpipe
+	if err := ssh.Start(); err != nil {
+		cat.Process.Kill()
+		rpipe.Close()
+		wpipe.Close()
+		return nil, nil, fmt.Errorf("failed to start ssh: %v", err)
+	}
+	sshDone := make(chan error, 1)
+	go func() {
+		err := ssh.Wait()
+		if inst.cfg.Debug {
+			log.Printf("ssh exited: %v", err)
+		}
+		sshDone <- fmt.Errorf("ssh exited: %v", err)
+	}()
+
+	wpipe.Close()
+	outc := make(chan []byte, 10)
+	errc := make(chan error, 1)
+	signal := func(err error) {
+		time.Sleep(5 * time.Second) // wait for any pending output
+		select {
+		case errc <- err:
+		default:
+		}
+	}
+
+	go func() {
+		var buf [64 << 10]byte
+		var output []byte
+		for {
+			n, err := rpipe.Read(buf[:])
+			if n != 0 {
+				if inst.cfg.Debug {
+					os.Stdout.Write(buf[:n])
+					os.Stdout.Write([]byte{'\n'})
+				}
+				output = append(output, buf[:n]...)
+				select {
+				case outc <- output:
+					output = nil
+				default:
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if err != nil {
+				rpipe.Close()
+				return
+			}
+		}
+	}()
+
+	go func() {
+		select {
+		case <-time.After(timeout):
+			signal(vm.TimeoutErr)
+			cat.Process.Kill()
+			ssh.Process.Kill()
+		case <-inst.closed:
+			if inst.cfg.Debug {
+				log.Printf("instance closed")
+			}
+			signal(fmt.Errorf("instance closed"))
+			cat.Process.Kill()
+			ssh.Process.Kill()
+		case err := <-catDone:
+			signal(err)
+			ssh.Process.Kill()
+		case err := <-sshDone:
+			signal(err)
+			cat.Process.Kill()
+		}
+	}()
+	return outc, errc, nil
+}
+
+func (inst *instance) sshArgs(portArg string) []string {
+	return []string{
+		"-i", inst.cfg.Sshkey,
+		portArg, "22",
+		"-o", "ConnectionAttempts=3",
+		"-o", "ConnectTimeout=10",
+		"-o", "BatchMode=yes",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"-o", "IdentitiesOnly=yes",
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "LogLevel=error",
+	}
+}
+
+// runTimeout runs cmd and kills it if it does not finish within timeout
+// (ssh/scp episodically hang when the board kernel is wedged).
+func runTimeout(cmd *exec.Cmd, timeout time.Duration) error {
+	rpipe, wpipe, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create pipe: %v", err)
+	}
+	defer wpipe.Close()
+	defer rpipe.Close()
+	cmd.Stdout = wpipe
+	cmd.Stderr = wpipe
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	wpipe.Close()
+	outc := make(chan []byte, 1)
+	go func() {
+		out, _ := ioutil.ReadAll(rpipe)
+		outc <- out
+	}()
+	done := make(chan bool)
+	go func() {
+		select {
+		case <-time.After(timeout):
+			cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+	err = cmd.Wait()
+	close(done)
+	out := <-outc
+	if err != nil {
+		return fmt.Errorf("%v %+v failed: %v\n%s", cmd.Path, cmd.Args[1:], err, out)
+	}
+	return nil
+}
@@ -5,22 +5,28 @@ package qemu
 
 import (
 	"fmt"
+	"io"
+	"log"
 	"math/rand"
 	"net"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
+	"github.com/google/syzkaller/fileutil"
 	"github.com/google/syzkaller/vm"
 )
 
 const (
 	hostAddr = "10.0.2.10"
+	shareTag = "syzshare"
+	shareDir = "/syzkaller"
 )
 
 func init() {
@@ -28,13 +34,14 @@ func init() {
 }
 
 type instance struct {
-	cfg     *vm.Config
-	port    int
-	rpipe   *os.File
-	wpipe   *os.File
-	qemu    *exec.Cmd
-	readerC chan error
-	waiterC chan error
+	cfg       *vm.Config
+	port      int
+	hostPorts map[int]int // guest port -> host port, see cfg.NetFwd
+	rpipe     *os.File
+	wpipe     *os.File
+	qemu      *exec.Cmd
+	readerC   chan error
+	waiterC   chan error
 
 	mu      sync.Mutex
 	outputB []byte
@@ -72,9 +79,7 @@ func ctorImpl(cfg *vm.Config) (vm.Instance, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pipe: %v", err)
 	}
-	for sz := 128 << 10; sz <= 2<<20; sz *= 2 {
-		syscall.Syscall(syscall.SYS_FCNTL, inst.wpipe.Fd(), syscall.F_SETPIPE_SZ, uintptr(sz))
-	}
+	fileutil.GrowPipe(inst.wpipe)
 
 	if err := inst.Boot(); err != nil {
 		return nil, err
@@ -88,12 +93,38 @@ func validateConfig(cfg *vm.Config) error {
 	if cfg.Bin == "" {
 		cfg.Bin = "qemu-system-x86_64"
 	}
-	if _, err := os.Stat(cfg.Image); err != nil {
-		return fmt.Errorf("image file '%v' does not exist: %v", cfg.Image, err)
+	if cfg.Image == "" && cfg.Initrd == "" {
+		return fmt.Errorf("either image or initrd must be specified")
+	}
+	if cfg.Image != "" {
+		if _, err := os.Stat(cfg.Image); err != nil {
+			return fmt.Errorf("image file '%v' does not exist: %v", cfg.Image, err)
+		}
+	}
+	if cfg.Initrd != "" {
+		if cfg.Kernel == "" {
+			return fmt.Errorf("initrd requires kernel to be specified")
+		}
+		if _, err := os.Stat(cfg.Initrd); err != nil {
+			return fmt.Errorf("initrd file '%v' does not exist: %v", cfg.Initrd, err)
+		}
 	}
 	if _, err := os.Stat(cfg.Sshkey); err != nil {
 		return fmt.Errorf("ssh key '%v' does not exist: %v", cfg.Sshkey, err)
 	}
+	for _, disk := range cfg.Disks {
+		if _, err := os.Stat(disk); err != nil {
+			return fmt.Errorf("disk image '%v' does not exist: %v", disk, err)
+		}
+	}
+	if cfg.Sharedir != "" {
+		if runtime.GOOS == "windows" {
+			return fmt.Errorf("9p shared dir (virtfs) is not supported on Windows hosts")
+		}
+		if _, err := os.Stat(cfg.Sharedir); err != nil {
+			return fmt.Errorf("shared dir '%v' does not exist: %v", cfg.Sharedir, err)
+		}
+	}
 	if cfg.Cpu <= 0 || cfg.Cpu > 1024 {
 		return fmt.Errorf("bad qemu cpu: %v, want [1-1024]", cfg.Cpu)
 	}
@@ -120,35 +151,114 @@ func (inst *instance) Close() {
 }
 
 func (inst *instance) Boot() error {
-	for {
-		// Find an unused TCP port.
-		inst.port = rand.Intn(64<<10-1<<10) + 1<<10
-		ln, err := net.Listen("tcp", fmt.Sprintf("localhost:%v", inst.port))
-		if err == nil {
-			ln.Close()
-			break
-		}
+	inst.port = freePort()
+	hostfwd := fmt.Sprintf("hostfwd=tcp::%v-:22", inst.port)
+	inst.hostPorts = make(map[int]int)
+	for _, port := range inst.cfg.NetFwd {
+		hostPort := freePort()
+		log.Printf("%v: forwarding guest port %v to localhost:%v", inst.cfg.Name, port, hostPort)
+		hostfwd += fmt.Sprintf(",hostfwd=tcp::%v-:%v", hostPort, port)
+		inst.hostPorts[port] = hostPort
+	}
+	model := inst.cfg.NetModel
+	if model == "" {
+		model = "e1000"
 	}
 	// TODO: ignores inst.cfg.Cpu
 	args := []string{
-		"-hda", inst.cfg.Image,
-		"-snapshot",
 		"-m", strconv.Itoa(inst.cfg.Mem),
-		"-net", "nic",
-		"-net", fmt.Sprintf("user,host=%v,hostfwd=tcp::%v-:22", hostAddr, inst.port),
+		"-netdev", fmt.Sprintf("user,id=net0,host=%v,%v", hostAddr, hostfwd),
+		"-device", fmt.Sprintf("%v,netdev=net0", model),
 		"-nographic",
-		"-enable-kvm",
 		"-numa", "node,nodeid=0,cpus=0-1", "-numa", "node,nodeid=1,cpus=2-3",
 		"-smp", "sockets=2,cores=2,threads=1",
 		"-usb", "-usbdevice", "mouse", "-usbdevice", "tablet",
 		"-soundhw", "all",
 	}
+	if runtime.GOOS == "linux" {
+		// On other hosts acceleration is selected with qemu_args (e.g. "-accel whpx" or "-accel hvf").
+		args = append(args, "-enable-kvm")
+	}
+	if inst.cfg.NetTap != "" {
+		// sshd is still reached over the user-mode NIC.
+		args = append(args,
+			"-netdev", fmt.Sprintf("tap,id=net1,ifname=%v,script=no,downscript=no", inst.cfg.NetTap),
+			"-device", fmt.Sprintf("%v,netdev=net1", model),
+		)
+	}
+	for i, nic := range inst.cfg.Nics {
+		id := fmt.Sprintf("net%v", i+2)
+		var netdev string
+		switch nic.Type {
+		case "user":
+			netdev = fmt.Sprintf("user,id=%v", id)
+			if nic.Net != "" {
+				netdev += ",net=" + nic.Net
+			}
+		case "tap":
+			netdev = fmt.Sprintf("tap,id=%v,ifname=%v,script=no,downscript=no", id, nic.Ifname)
+		case "bridge":
+			netdev = fmt.Sprintf("bridge,id=%v,br=%v", id, nic.Bridge)
+		case "socket":
+			netdev = fmt.Sprintf("socket,id=%v,mcast=%v", id, nic.Mcast)
+		default:
+			return fmt.Errorf("unknown NIC type %v", nic.Type)
+		}
+		nicModel := nic.Model
+		if nicModel == "" {
+			nicModel = model
+		}
+		// VMs share L2 segments, so MACs must be unique across VMs (qemu assigns the same ones).
+		mac := fmt.Sprintf("52:54:%02x:%02x:%02x:%02x", i+1, byte(inst.cfg.Index>>16), byte(inst.cfg.Index>>8), byte(inst.cfg.Index))
+		args = append(args,
+			"-netdev", netdev,
+			"-device", fmt.Sprintf("%v,netdev=%v,mac=%v", nicModel, id, mac),
+		)
+	}
+	if inst.cfg.Image != "" && inst.cfg.Overlay {
+		overlay, err := inst.createOverlay()
+		if err != nil {
+			return err
+		}
+		args = append(args, "-drive", fmt.Sprintf("file=%v,format=qcow2,if=ide,index=0,media=disk", overlay))
+	} else if inst.cfg.Image != "" {
+		args = append(args,
+			"-hda", inst.cfg.Image,
+			"-snapshot",
+		)
+	}
+	if inst.cfg.Initrd != "" {
+		args = append(args, "-initrd", inst.cfg.Initrd)
+	}
+	for i, disk := range inst.cfg.Disks {
+		// Tests can corrupt the disks, so every boot gets fresh copies.
+		file := filepath.Join(inst.cfg.Workdir, fmt.Sprintf("disk%v", i))
+		if err := fileutil.CopyFile(disk, file, true); err != nil {
+			return fmt.Errorf("failed to copy disk image '%v': %v", disk, err)
+		}
+		format, err := imageFormat(file)
+		if err != nil {
+			return fmt.Errorf("failed to detect format of disk image '%v': %v", disk, err)
+		}
+		args = append(args, "-drive", fmt.Sprintf("file=%v,if=virtio,format=%v", file, format))
+	}
+	if inst.cfg.Sharedir != "" {
+		args = append(args,
+			"-fsdev", fmt.Sprintf("local,id=fsdev0,path=%v,security_model=none,readonly", inst.cfg.Sharedir),
+			"-device", fmt.Sprintf("virtio-9p-pci,fsdev=fsdev0,mount_tag=%v", shareTag),
+		)
+	}
 	if inst.cfg.Kernel != "" {
+		cmdline := "console=ttyS0 debug earlyprintk=serial slub_debug=UZ "
+		if inst.cfg.Image != "" {
+			cmdline += "root=/dev/sda "
+		}
 		args = append(args,
 			"-kernel", inst.cfg.Kernel,
-			"-append", "console=ttyS0 root=/dev/sda debug earlyprintk=serial slub_debug=UZ "+inst.cfg.Cmdline,
+			"-append", cmdline+inst.cfg.Cmdline,
 		)
 	}
+	args = append(args, strings.Fields(inst.cfg.QemuArgs)...)
 	qemu := exec.Command(inst.cfg.Bin, args...)
 	qemu.Stdout = inst.wpipe
 	qemu.Stderr = inst.wpipe
@@ -230,6 +340,11 @@ func (inst *instance) Boot() error {
 			return fmt.Errorf("ssh server did not start:\n%v\n", string(output))
 		}
 	}
+	if inst.cfg.Sharedir != "" {
+		if err := inst.mountShare(); err != nil {
+			return err
+		}
+	}
 	// Drop boot output. It is not interesting if the VM has successfully booted.
 	inst.mu.Lock()
 	inst.outputB = nil
@@ -237,12 +352,103 @@ func (inst *instance) Boot() error {
 	return nil
 }
 
+// createOverlay creates a qcow2 overlay in the instance workdir backed by the read-only image.
+func (inst *instance) createOverlay() (string, error) {
+	base, err := filepath.Abs(inst.cfg.Image)
+	if err != nil {
+		return "", err
+	}
+	format, err := imageFormat(base)
+	if err != nil {
+		return "", err
+	}
+	overlay := filepath.Join(inst.cfg.Workdir, "overlay.qcow2")
+	qemuImg := "qemu-img"
+	if dir := filepath.Dir(inst.cfg.Bin); dir != "." {
+		qemuImg = filepath.Join(dir, qemuImg)
+	}
+	out, err := exec.Command(qemuImg, "create", "-f", "qcow2", "-F", format, "-b", base, overlay).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to create image overlay: %v\n%s", err, out)
+	}
+	return overlay, nil
+}
+
+func (inst *instance) HostPorts() map[int]int {
+	return inst.hostPorts
+}
+
+// imageFormat returns qemu format of the image file: qcow2 or raw.
+func imageFormat(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var magic [4]byte
+	if _, err := io.ReadFull(f, magic[:]); err == nil && string(magic[:]) == "QFI\xfb" {
+		return "qcow2", nil
+	}
+	return "raw", nil
+}
+
+// SaveDisk stops qemu and moves the image overlay to dst.
+// The overlay still refers to the base image by absolute path.
+func (inst *instance) SaveDisk(dst string) error {
+	if !inst.cfg.Overlay || inst.cfg.Image == "" {
+		return fmt.Errorf("instance is not booted from an image overlay")
+	}
+	if inst.qemu != nil {
+		inst.qemu.Process.Kill()
+		err := <-inst.waiterC
+		inst.waiterC <- err // repost it for Close
+	}
+	overlay := filepath.Join(inst.cfg.Workdir, "overlay.qcow2")
+	if err := os.Rename(overlay, dst); err != nil {
+		if err := fileutil.CopyFile(overlay, dst, false); err != nil {
+			return fmt.Errorf("failed to save disk: %v", err)
+		}
+	}
+	return nil
+}
+
+// freePort returns a random unused TCP port.
+func freePort() int {
+	for {
+		port := rand.Intn(64<<10-1<<10) + 1<<10
+		ln, err := net.Listen("tcp", fmt.Sprintf("localhost:%v", port))
+		if err == nil {
+			ln.Close()
+			return port
+		}
+	}
+}
+
+// mountShare mounts the 9p share with host Sharedir inside of the VM.
+func (inst *instance) mountShare() error {
+	command := fmt.Sprintf("mkdir -p %v && mount -t 9p -o trans=virtio,version=9p2000.L,ro %v %v",
+		shareDir, shareTag, shareDir)
+	args := append(inst.sshArgs("-p"), "root@localhost", command)
+	out, err := exec.Command("ssh", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to mount 9p share: %v\n%s", err, out)
+	}
+	return nil
+}
+
 func (inst *instance) Forward(port int) (string, error) {
 	return fmt.Sprintf("%v:%v", hostAddr, port), nil
 }
 
 func (inst *instance) Copy(hostSrc string) (string, error) {
-	vmDst := filepath.Join("/", filepath.Base(hostSrc))
+	if inst.cfg.Sharedir != "" {
+		// Files from the shared dir are accessible in the VM as is.
+		if rel, err := filepath.Rel(inst.cfg.Sharedir, hostSrc); err == nil && !strings.HasPrefix(rel, "..") {
+			return path.Join(shareDir, filepath.ToSlash(rel)), nil
+		}
+	}
+	// Guest paths are always slash-separated, even on Windows hosts.
+	vmDst := path.Join("/", filepath.Base(hostSrc))
 	args := append(inst.sshArgs("-P"), hostSrc, "root@localhost:"+vmDst)
 	cmd := exec.Command("scp", args...)
 	if err := cmd.Start(); err != nil {
@@ -318,7 +524,7 @@ func (inst *instance) sshArgs(portArg string) []string {
 		"-o", "ConnectionAttempts=10",
 		"-o", "ConnectTimeout=10",
 		"-o", "BatchMode=yes",
-		"-o", "UserKnownHostsFile=/dev/null",
+		"-o", "UserKnownHostsFile=" + os.DevNull,
 		"-o", "IdentitiesOnly=yes",
 		"-o", "StrictHostKeyChecking=no",
 		"-o", "LogLevel=error",
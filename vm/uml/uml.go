@@ -0,0 +1,292 @@
+// Copyright 2016 syzkaller project authors. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+// +build !windows
+
+// Package uml implements a vm backend that runs User Mode Linux:
+// the "VM" is the UML kernel binary (cfg.Kernel) running as a host process.
+// Root filesystem is a copy-on-write overlay of cfg.Image, binaries and commands
+// are passed via a hostfs-shared dir, network goes through slirp.
+// UML does not support KCOV, so it is suitable for smoke fuzzing of
+// syscall descriptions with cover=false.
+package uml
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/syzkaller/fileutil"
+	"github.com/google/syzkaller/vm"
+)
+
+const (
+	hostAddr = "10.0.2.2" // slirp alias for host loopback
+	guestDir = "/host"    // hostfs mount point in guest
+)
+
+func init() {
+	vm.Register("uml", ctor)
+}
+
+type instance struct {
+	cfg      *vm.Config
+	shareDir string
+	uml      *exec.Cmd
+	readerC  chan error
+	waiterC  chan error
+
+	mu      sync.Mutex
+	outputB []byte
+	outputC chan []byte
+}
+
+func ctor(cfg *vm.Config) (vm.Instance, error) {
+	inst := &instance{
+		cfg:      cfg,
+		shareDir: filepath.Join(cfg.Workdir, "share"),
+	}
+	closeInst := inst
+	defer func() {
+		if closeInst != nil {
+			closeInst.Close()
+		}
+	}()
+
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(inst.shareDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create share dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(inst.shareDir, "script.sh"), []byte(script), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %v", err)
+	}
+
+	rpipe, wpipe, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipe: %v", err)
+	}
+	fileutil.GrowPipe(wpipe)
+
+	args := []string{
+		fmt.Sprintf("umid=syz-%v", inst.cfg.Index),
+		fmt.Sprintf("uml_dir=%v", inst.cfg.Workdir),
+		fmt.Sprintf("ubd0=%v,%v", filepath.Join(inst.cfg.Workdir, "image.cow"), inst.cfg.Image),
+		fmt.Sprintf("hostfs=%v", inst.shareDir),
+		fmt.Sprintf("mem=%vM", inst.cfg.Mem),
+		"eth0=slirp,,slirp",
+		"con=null", "con0=fd:0,fd:1",
+		"root=/dev/ubda", "rw", "init=/bin/sh",
+		"slub_debug=UZ",
+	}
+	args = append(args, strings.Fields(inst.cfg.Cmdline)...)
+	uml := exec.Command(inst.cfg.Kernel, args...)
+	stdin, err := uml.StdinPipe()
+	if err != nil {
+		rpipe.Close()
+		wpipe.Close()
+		return nil, fmt.Errorf("failed to create pipe: %v", err)
+	}
+	uml.Stdout = wpipe
+	uml.Stderr = wpipe
+	// UML kernel threads are host processes, kill them all together.
+	uml.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := uml.Start(); err != nil {
+		rpipe.Close()
+		wpipe.Close()
+		return nil, fmt.Errorf("failed to start uml: %v", err)
+	}
+	inst.uml = uml
+
+	// Start output reading goroutine.
+	inst.readerC = make(chan error)
+	go func() {
+		var buf [64 << 10]byte
+		for {
+			n, err := rpipe.Read(buf[:])
+			if n != 0 {
+				if inst.cfg.Debug {
+					os.Stdout.Write(buf[:n])
+					os.Stdout.Write([]byte{'\n'})
+				}
+				inst.mu.Lock()
+				inst.outputB = append(inst.outputB, buf[:n]...)
+				if inst.outputC != nil {
+					select {
+					case inst.outputC <- inst.outputB:
+						inst.outputB = nil
+					default:
+					}
+				}
+				inst.mu.Unlock()
+				time.Sleep(time.Millisecond)
+			}
+			if err != nil {
+				rpipe.Close()
+				inst.readerC <- err
+				return
+			}
+		}
+	}()
+
+	// Wait for the uml asynchronously.
+	inst.waiterC = make(chan error, 1)
+	go func() {
+		err := inst.uml.Wait()
+		wpipe.Close()
+		inst.waiterC <- err
+	}()
+
+	// init is a shell on the console, setup the guest and start the command loop.
+	// The shell reads the commands once it has started, so they can be written right away.
+	if _, err := io.WriteString(stdin, setup); err != nil {
+		return nil, fmt.Errorf("failed to write to uml console: %v", err)
+	}
+
+	// Wait for the script to start serving.
+	_, errc, err := inst.Run(10*time.Minute, "true")
+	if err == nil {
+		err = <-errc
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run script: %v", err)
+	}
+
+	closeInst = nil
+	return inst, nil
+}
+
+func validateConfig(cfg *vm.Config) error {
+	if _, err := os.Stat(cfg.Kernel); err != nil {
+		return fmt.Errorf("uml kernel binary '%v' does not exist: %v", cfg.Kernel, err)
+	}
+	if _, err := os.Stat(cfg.Image); err != nil {
+		return fmt.Errorf("image file '%v' does not exist: %v", cfg.Image, err)
+	}
+	if cfg.Sshkey != "" {
+		return fmt.Errorf("uml does not need ssh key")
+	}
+	if cfg.Cpu > 1 {
+		return fmt.Errorf("uml supports only 1 cpu, got %v", cfg.Cpu)
+	}
+	if cfg.Mem < 128 || cfg.Mem > 1048576 {
+		return fmt.Errorf("bad uml mem: %v, want [128-1048576]", cfg.Mem)
+	}
+	return nil
+}
+
+func (inst *instance) Close() {
+	if inst.uml != nil {
+		syscall.Kill(-inst.uml.Process.Pid, syscall.SIGKILL)
+		err := <-inst.waiterC
+		inst.waiterC <- err // repost it for waiting goroutines
+		<-inst.readerC
+	}
+	os.RemoveAll(inst.cfg.Workdir)
+}
+
+func (inst *instance) Forward(port int) (string, error) {
+	return fmt.Sprintf("%v:%v", hostAddr, port), nil
+}
+
+func (inst *instance) Copy(hostSrc string) (string, error) {
+	base := filepath.Base(hostSrc)
+	dst := filepath.Join(inst.shareDir, base)
+	if err := fileutil.CopyFile(hostSrc, dst, false); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dst, 0777); err != nil {
+		return "", err
+	}
+	return filepath.Join(guestDir, base), nil
+}
+
+func (inst *instance) Run(timeout time.Duration, command string) (<-chan []byte, <-chan error, error) {
+	outputC := make(chan []byte, 10)
+	errorC := make(chan error, 1)
+	inst.mu.Lock()
+	inst.outputB = nil
+	inst.outputC = outputC
+	inst.mu.Unlock()
+
+	cmdFile := filepath.Join(inst.shareDir, "syz-cmd")
+	tmpFile := cmdFile + "-tmp"
+	if err := ioutil.WriteFile(tmpFile, []byte(command), 0700); err != nil {
+		return nil, nil, err
+	}
+	if err := os.Rename(tmpFile, cmdFile); err != nil {
+		return nil, nil, err
+	}
+
+	signal := func(err error) {
+		time.Sleep(3 * time.Second) // wait for any pending output
+		inst.mu.Lock()
+		if inst.outputC == outputC {
+			inst.outputB = nil
+			inst.outputC = nil
+		}
+		inst.mu.Unlock()
+		errorC <- err
+	}
+
+	go func() {
+		timeoutTicker := time.NewTicker(timeout)
+		secondTicker := time.NewTicker(time.Second)
+		var resultErr error
+	loop:
+		for {
+			select {
+			case <-timeoutTicker.C:
+				resultErr = vm.TimeoutErr
+				break loop
+			case <-secondTicker.C:
+				if _, err := os.Stat(cmdFile); err != nil {
+					resultErr = nil
+					break loop
+				}
+			case err := <-inst.waiterC:
+				inst.waiterC <- err // repost it for Close
+				resultErr = fmt.Errorf("uml exited")
+				break loop
+			}
+		}
+		signal(resultErr)
+		timeoutTicker.Stop()
+		secondTicker.Stop()
+	}()
+
+	return outputC, errorC, nil
+}
+
+const setup = `
+mount -t proc none /proc
+mount -t sysfs none /sys
+mount -t debugfs none /sys/kernel/debug
+mount -t tmpfs none /tmp
+mkdir -p ` + guestDir + `
+mount -t hostfs none ` + guestDir + `
+ifconfig lo 127.0.0.1 up
+ifconfig eth0 10.0.2.15 netmask 255.255.255.0 up
+route add default gw ` + hostAddr + `
+cd ` + guestDir + `
+exec /bin/sh ` + guestDir + `/script.sh
+`
+
+const script = `#! /bin/sh
+while true; do
+	if [ -e "syz-cmd" ]; then
+		/bin/sh ./syz-cmd
+		rm -f syz-cmd
+	else
+		sleep 1
+	fi
+done
+`
@@ -4,7 +4,6 @@
 package vm
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"time"
@@ -28,6 +27,20 @@ type Instance interface {
 	Close()
 }
 
+// DiskSaver is implemented by instances that can preserve the guest disk state
+// (e.g. qemu instances booted from an overlay).
+type DiskSaver interface {
+	// SaveDisk stops the VM and moves its disk to dst.
+	SaveDisk(dst string) error
+}
+
+// PortForwarder is implemented by instances that forward guest TCP ports (Config.NetFwd)
+// to host ports (qemu).
+type PortForwarder interface {
+	// HostPorts returns the forwarded ports: guest port -> host port.
+	HostPorts() map[int]int
+}
+
 type Config struct {
 	Name       string
 	Index      int
@@ -36,14 +49,53 @@ type Config struct {
 	Kernel     string
 	Cmdline    string
 	Image      string
+	Initrd     string
+	Disks      []string // template images of additional disks, fresh copies are attached on every boot
+	Overlay    bool     // boot from a qcow2 overlay in Workdir on top of read-only Image (qemu)
 	Sshkey     string
 	Executor   string
+	Sharedir   string // host dir exported into VM read-only (if supported by VM type)
+	NetModel   string // NIC model (qemu)
+	NetFwd     []int  // additional guest TCP ports to forward to host (qemu)
+	NetTap     string // host tap device to attach as an additional NIC (qemu)
+	Nics       []NIC  // more additional NICs (qemu)
+	QemuArgs   string // additional qemu command line arguments
 	ConsoleDev string
+	Devices    []Device // pool of physical devices (adb)
+	Reflash    string   // host command to reflash a dead device, %v is replaced with device serial
+	Avd        string   // Android virtual device name (emulator)
+	Snapshot   string   // AVD snapshot to boot from (emulator)
+	Addr       string   // network address of a physical board (odroid)
+	PowerCycle string   // host command to hard power-cycle a physical board (odroid)
 	Cpu        int
 	Mem        int
 	Debug      bool
 }
 
+// NIC is an additional network interface of a VM.
+type NIC struct {
+	Type   string // "user", "tap", "bridge" or "socket" (see NicTypes)
+	Model  string // NIC model (default: Config.NetModel)
+	Net    string // guest subnet of a user NIC (e.g. "10.0.3.0/24")
+	Ifname string // host tap device of a tap NIC
+	Bridge string // host bridge of a bridge NIC
+	Mcast  string // multicast address:port of the L2 segment of a socket NIC
+}
+
+// NicTypes describes supported NIC types.
+var NicTypes = map[string]string{
+	"user":   "a private user-mode network",
+	"tap":    "an existing host tap device",
+	"bridge": "a new tap device added to a host bridge by qemu-bridge-helper",
+	"socket": "an L2 segment shared by VMs (UDP multicast)",
+}
+
+// Device is a physical test machine (e.g. an Android phone).
+type Device struct {
+	Serial     string
+	ConsoleDev string
+}
+
 type ctorFunc func(cfg *Config) (Instance, error)
 
 var ctors = make(map[string]ctorFunc)
@@ -61,50 +113,4 @@ func Create(typ string, cfg *Config) (Instance, error) {
 	return ctor(cfg)
 }
 
-// FindCrash searches kernel console output for oops messages.
-// Desc contains a more-or-less representative description of the first oops,
-// start and end denote region of output with oops message(s).
-func FindCrash(output []byte) (desc string, start int, end int, found bool) {
-	for pos := 0; pos < len(output); {
-		next := bytes.IndexByte(output[pos:], '\n')
-		if next != -1 {
-			next += pos
-		} else {
-			next = len(output)
-		}
-		for _, oops := range oopses {
-			match := bytes.Index(output[pos:next], oops)
-			if match == -1 {
-				continue
-			}
-			if !found {
-				found = true
-				start = pos
-				desc = string(output[pos+match : next])
-				if desc[len(desc)-1] == '\r' {
-					desc = desc[:len(desc)-1]
-				}
-			}
-			end = next
-		}
-		pos = next + 1
-	}
-	return
-}
-
-var (
-	oopses = [][]byte{
-		[]byte("Kernel panic"),
-		[]byte("BUG:"),
-		[]byte("kernel BUG"),
-		[]byte("WARNING:"),
-		[]byte("INFO:"),
-		[]byte("unable to handle"),
-		[]byte("Unable to handle kernel"),
-		[]byte("general protection fault"),
-		[]byte("UBSAN:"),
-		[]byte("unreferenced object"),
-	}
-
-	TimeoutErr = errors.New("timeout")
-)
+var TimeoutErr = errors.New("timeout")